@@ -66,6 +66,12 @@ type Interface interface {
 		opts ...yarpc.CallOption,
 	) (*shared.DescribeWorkflowExecutionResponse, error)
 
+	GetDomainWorkflowStateCounts(
+		ctx context.Context,
+		CountRequest *shared.GetDomainWorkflowStateCountsRequest,
+		opts ...yarpc.CallOption,
+	) (*shared.GetDomainWorkflowStateCountsResponse, error)
+
 	GetSearchAttributes(
 		ctx context.Context,
 		opts ...yarpc.CallOption,
@@ -385,6 +391,29 @@ func (c client) DescribeWorkflowExecution(
 	return
 }
 
+func (c client) GetDomainWorkflowStateCounts(
+	ctx context.Context,
+	_CountRequest *shared.GetDomainWorkflowStateCountsRequest,
+	opts ...yarpc.CallOption,
+) (success *shared.GetDomainWorkflowStateCountsResponse, err error) {
+
+	args := cadence.WorkflowService_GetDomainWorkflowStateCounts_Helper.Args(_CountRequest)
+
+	var body wire.Value
+	body, err = c.c.Call(ctx, args, opts...)
+	if err != nil {
+		return
+	}
+
+	var result cadence.WorkflowService_GetDomainWorkflowStateCounts_Result
+	if err = result.FromWire(body); err != nil {
+		return
+	}
+
+	success, err = cadence.WorkflowService_GetDomainWorkflowStateCounts_Helper.UnwrapResponse(&result)
+	return
+}
+
 func (c client) GetSearchAttributes(
 	ctx context.Context,
 	opts ...yarpc.CallOption,
@@ -59,6 +59,11 @@ type Interface interface {
 		DescribeRequest *shared.DescribeWorkflowExecutionRequest,
 	) (*shared.DescribeWorkflowExecutionResponse, error)
 
+	GetDomainWorkflowStateCounts(
+		ctx context.Context,
+		CountRequest *shared.GetDomainWorkflowStateCountsRequest,
+	) (*shared.GetDomainWorkflowStateCountsResponse, error)
+
 	GetSearchAttributes(
 		ctx context.Context,
 	) (*shared.GetSearchAttributesResponse, error)
@@ -275,6 +280,17 @@ func New(impl Interface, opts ...thrift.RegisterOption) []transport.Procedure {
 				ThriftModule: cadence.ThriftModule,
 			},
 
+			thrift.Method{
+				Name: "GetDomainWorkflowStateCounts",
+				HandlerSpec: thrift.HandlerSpec{
+
+					Type:  transport.Unary,
+					Unary: thrift.UnaryHandler(h.GetDomainWorkflowStateCounts),
+				},
+				Signature:    "GetDomainWorkflowStateCounts(CountRequest *shared.GetDomainWorkflowStateCountsRequest) (*shared.GetDomainWorkflowStateCountsResponse)",
+				ThriftModule: cadence.ThriftModule,
+			},
+
 			thrift.Method{
 				Name: "GetSearchAttributes",
 				HandlerSpec: thrift.HandlerSpec{
@@ -607,7 +623,7 @@ func New(impl Interface, opts ...thrift.RegisterOption) []transport.Procedure {
 		},
 	}
 
-	procedures := make([]transport.Procedure, 0, 35)
+	procedures := make([]transport.Procedure, 0, 36)
 	procedures = append(procedures, thrift.BuildProcedures(service, opts...)...)
 	return procedures
 }
@@ -709,6 +725,25 @@ func (h handler) DescribeWorkflowExecution(ctx context.Context, body wire.Value)
 	return response, err
 }
 
+func (h handler) GetDomainWorkflowStateCounts(ctx context.Context, body wire.Value) (thrift.Response, error) {
+	var args cadence.WorkflowService_GetDomainWorkflowStateCounts_Args
+	if err := args.FromWire(body); err != nil {
+		return thrift.Response{}, err
+	}
+
+	success, err := h.impl.GetDomainWorkflowStateCounts(ctx, args.CountRequest)
+
+	hadError := err != nil
+	result, err := cadence.WorkflowService_GetDomainWorkflowStateCounts_Helper.WrapResponse(success, err)
+
+	var response thrift.Response
+	if err == nil {
+		response.IsApplicationError = hadError
+		response.Body = result
+	}
+	return response, err
+}
+
 func (h handler) GetSearchAttributes(ctx context.Context, body wire.Value) (thrift.Response, error) {
 	var args cadence.WorkflowService_GetSearchAttributes_Args
 	if err := args.FromWire(body); err != nil {
@@ -225,6 +225,39 @@ func (mr *_MockClientRecorder) DescribeWorkflowExecution(
 	return mr.mock.ctrl.RecordCall(mr.mock, "DescribeWorkflowExecution", args...)
 }
 
+// GetDomainWorkflowStateCounts responds to a GetDomainWorkflowStateCounts call based on the mock expectations. This
+// call will fail if the mock does not expect this call. Use EXPECT to expect
+// a call to this function.
+//
+// 	client.EXPECT().GetDomainWorkflowStateCounts(gomock.Any(), ...).Return(...)
+// 	... := client.GetDomainWorkflowStateCounts(...)
+func (m *MockClient) GetDomainWorkflowStateCounts(
+	ctx context.Context,
+	_CountRequest *shared.GetDomainWorkflowStateCountsRequest,
+	opts ...yarpc.CallOption,
+) (success *shared.GetDomainWorkflowStateCountsResponse, err error) {
+
+	args := []interface{}{ctx, _CountRequest}
+	for _, o := range opts {
+		args = append(args, o)
+	}
+	i := 0
+	ret := m.ctrl.Call(m, "GetDomainWorkflowStateCounts", args...)
+	success, _ = ret[i].(*shared.GetDomainWorkflowStateCountsResponse)
+	i++
+	err, _ = ret[i].(error)
+	return
+}
+
+func (mr *_MockClientRecorder) GetDomainWorkflowStateCounts(
+	ctx interface{},
+	_CountRequest interface{},
+	opts ...interface{},
+) *gomock.Call {
+	args := append([]interface{}{ctx, _CountRequest}, opts...)
+	return mr.mock.ctrl.RecordCall(mr.mock, "GetDomainWorkflowStateCounts", args...)
+}
+
 // GetSearchAttributes responds to a GetSearchAttributes call based on the mock expectations. This
 // call will fail if the mock does not expect this call. Use EXPECT to expect
 // a call to this function.
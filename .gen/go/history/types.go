@@ -734,9 +734,10 @@ func (v *EventAlreadyStartedError) Error() string {
 }
 
 type GetMutableStateRequest struct {
-	DomainUUID          *string                   `json:"domainUUID,omitempty"`
-	Execution           *shared.WorkflowExecution `json:"execution,omitempty"`
-	ExpectedNextEventId *int64                    `json:"expectedNextEventId,omitempty"`
+	DomainUUID            *string                   `json:"domainUUID,omitempty"`
+	Execution             *shared.WorkflowExecution `json:"execution,omitempty"`
+	ExpectedNextEventId   *int64                    `json:"expectedNextEventId,omitempty"`
+	IncludeBranchAncestry *bool                     `json:"includeBranchAncestry,omitempty"`
 }
 
 // ToWire translates a GetMutableStateRequest struct into a Thrift-level intermediate
@@ -756,7 +757,7 @@ type GetMutableStateRequest struct {
 //   }
 func (v *GetMutableStateRequest) ToWire() (wire.Value, error) {
 	var (
-		fields [3]wire.Field
+		fields [4]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -786,6 +787,14 @@ func (v *GetMutableStateRequest) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 30, Value: w}
 		i++
 	}
+	if v.IncludeBranchAncestry != nil {
+		w, err = wire.NewValueBool(*(v.IncludeBranchAncestry)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 40, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -839,6 +848,16 @@ func (v *GetMutableStateRequest) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 40:
+			if field.Value.Type() == wire.TBool {
+				var x bool
+				x, err = field.Value.GetBool(), error(nil)
+				v.IncludeBranchAncestry = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -853,7 +872,7 @@ func (v *GetMutableStateRequest) String() string {
 		return "<nil>"
 	}
 
-	var fields [3]string
+	var fields [4]string
 	i := 0
 	if v.DomainUUID != nil {
 		fields[i] = fmt.Sprintf("DomainUUID: %v", *(v.DomainUUID))
@@ -867,6 +886,10 @@ func (v *GetMutableStateRequest) String() string {
 		fields[i] = fmt.Sprintf("ExpectedNextEventId: %v", *(v.ExpectedNextEventId))
 		i++
 	}
+	if v.IncludeBranchAncestry != nil {
+		fields[i] = fmt.Sprintf("IncludeBranchAncestry: %v", *(v.IncludeBranchAncestry))
+		i++
+	}
 
 	return fmt.Sprintf("GetMutableStateRequest{%v}", strings.Join(fields[:i], ", "))
 }
@@ -900,6 +923,9 @@ func (v *GetMutableStateRequest) Equals(rhs *GetMutableStateRequest) bool {
 	if !_I64_EqualsPtr(v.ExpectedNextEventId, rhs.ExpectedNextEventId) {
 		return false
 	}
+	if !_Bool_EqualsPtr(v.IncludeBranchAncestry, rhs.IncludeBranchAncestry) {
+		return false
+	}
 
 	return true
 }
@@ -919,6 +945,9 @@ func (v *GetMutableStateRequest) MarshalLogObject(enc zapcore.ObjectEncoder) (er
 	if v.ExpectedNextEventId != nil {
 		enc.AddInt64("expectedNextEventId", *v.ExpectedNextEventId)
 	}
+	if v.IncludeBranchAncestry != nil {
+		enc.AddBool("includeBranchAncestry", *v.IncludeBranchAncestry)
+	}
 	return err
 }
 
@@ -967,6 +996,21 @@ func (v *GetMutableStateRequest) IsSetExpectedNextEventId() bool {
 	return v != nil && v.ExpectedNextEventId != nil
 }
 
+// GetIncludeBranchAncestry returns the value of IncludeBranchAncestry if it is set or its
+// zero value if it is unset.
+func (v *GetMutableStateRequest) GetIncludeBranchAncestry() (o bool) {
+	if v != nil && v.IncludeBranchAncestry != nil {
+		return *v.IncludeBranchAncestry
+	}
+
+	return
+}
+
+// IsSetIncludeBranchAncestry returns true if IncludeBranchAncestry is not nil.
+func (v *GetMutableStateRequest) IsSetIncludeBranchAncestry() bool {
+	return v != nil && v.IncludeBranchAncestry != nil
+}
+
 type GetMutableStateResponse struct {
 	Execution                            *shared.WorkflowExecution          `json:"execution,omitempty"`
 	WorkflowType                         *shared.WorkflowType               `json:"workflowType,omitempty"`
@@ -983,6 +1027,7 @@ type GetMutableStateResponse struct {
 	EventStoreVersion                    *int32                             `json:"eventStoreVersion,omitempty"`
 	BranchToken                          []byte                             `json:"branchToken,omitempty"`
 	ReplicationInfo                      map[string]*shared.ReplicationInfo `json:"replicationInfo,omitempty"`
+	BranchAncestry                       *shared.HistoryBranch              `json:"branchAncestry,omitempty"`
 }
 
 type _Map_String_ReplicationInfo_MapItemList map[string]*shared.ReplicationInfo
@@ -1040,7 +1085,7 @@ func (_Map_String_ReplicationInfo_MapItemList) Close() {}
 //   }
 func (v *GetMutableStateResponse) ToWire() (wire.Value, error) {
 	var (
-		fields [15]wire.Field
+		fields [16]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -1166,6 +1211,14 @@ func (v *GetMutableStateResponse) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 140, Value: w}
 		i++
 	}
+	if v.BranchAncestry != nil {
+		w, err = v.BranchAncestry.ToWire()
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 150, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -1216,6 +1269,12 @@ func _Map_String_ReplicationInfo_Read(m wire.MapItemList) (map[string]*shared.Re
 	return o, err
 }
 
+func _HistoryBranch_Read(w wire.Value) (*shared.HistoryBranch, error) {
+	var v shared.HistoryBranch
+	err := v.FromWire(w)
+	return &v, err
+}
+
 // FromWire deserializes a GetMutableStateResponse struct from its Thrift-level
 // representation. The Thrift-level representation may be obtained
 // from a ThriftRW protocol implementation.
@@ -1375,6 +1434,14 @@ func (v *GetMutableStateResponse) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 150:
+			if field.Value.Type() == wire.TStruct {
+				v.BranchAncestry, err = _HistoryBranch_Read(field.Value)
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -1389,7 +1456,7 @@ func (v *GetMutableStateResponse) String() string {
 		return "<nil>"
 	}
 
-	var fields [15]string
+	var fields [16]string
 	i := 0
 	if v.Execution != nil {
 		fields[i] = fmt.Sprintf("Execution: %v", v.Execution)
@@ -1451,6 +1518,10 @@ func (v *GetMutableStateResponse) String() string {
 		fields[i] = fmt.Sprintf("ReplicationInfo: %v", v.ReplicationInfo)
 		i++
 	}
+	if v.BranchAncestry != nil {
+		fields[i] = fmt.Sprintf("BranchAncestry: %v", v.BranchAncestry)
+		i++
+	}
 
 	return fmt.Sprintf("GetMutableStateResponse{%v}", strings.Join(fields[:i], ", "))
 }
@@ -1547,6 +1618,9 @@ func (v *GetMutableStateResponse) Equals(rhs *GetMutableStateResponse) bool {
 	if !((v.ReplicationInfo == nil && rhs.ReplicationInfo == nil) || (v.ReplicationInfo != nil && rhs.ReplicationInfo != nil && _Map_String_ReplicationInfo_Equals(v.ReplicationInfo, rhs.ReplicationInfo))) {
 		return false
 	}
+	if !((v.BranchAncestry == nil && rhs.BranchAncestry == nil) || (v.BranchAncestry != nil && rhs.BranchAncestry != nil && v.BranchAncestry.Equals(rhs.BranchAncestry))) {
+		return false
+	}
 
 	return true
 }
@@ -1613,6 +1687,9 @@ func (v *GetMutableStateResponse) MarshalLogObject(enc zapcore.ObjectEncoder) (e
 	if v.ReplicationInfo != nil {
 		err = multierr.Append(err, enc.AddObject("replicationInfo", (_Map_String_ReplicationInfo_Zapper)(v.ReplicationInfo)))
 	}
+	if v.BranchAncestry != nil {
+		err = multierr.Append(err, enc.AddObject("branchAncestry", v.BranchAncestry))
+	}
 	return err
 }
 
@@ -1841,6 +1918,21 @@ func (v *GetMutableStateResponse) IsSetReplicationInfo() bool {
 	return v != nil && v.ReplicationInfo != nil
 }
 
+// GetBranchAncestry returns the value of BranchAncestry if it is set or its
+// zero value if it is unset.
+func (v *GetMutableStateResponse) GetBranchAncestry() (o *shared.HistoryBranch) {
+	if v != nil && v.BranchAncestry != nil {
+		return v.BranchAncestry
+	}
+
+	return
+}
+
+// IsSetBranchAncestry returns true if BranchAncestry is not nil.
+func (v *GetMutableStateResponse) IsSetBranchAncestry() bool {
+	return v != nil && v.BranchAncestry != nil
+}
+
 type ParentExecutionInfo struct {
 	DomainUUID  *string                   `json:"domainUUID,omitempty"`
 	Domain      *string                   `json:"domain,omitempty"`
@@ -3731,19 +3823,21 @@ func (v *RecordDecisionTaskStartedRequest) IsSetPollRequest() bool {
 }
 
 type RecordDecisionTaskStartedResponse struct {
-	WorkflowType              *shared.WorkflowType          `json:"workflowType,omitempty"`
-	PreviousStartedEventId    *int64                        `json:"previousStartedEventId,omitempty"`
-	ScheduledEventId          *int64                        `json:"scheduledEventId,omitempty"`
-	StartedEventId            *int64                        `json:"startedEventId,omitempty"`
-	NextEventId               *int64                        `json:"nextEventId,omitempty"`
-	Attempt                   *int64                        `json:"attempt,omitempty"`
-	StickyExecutionEnabled    *bool                         `json:"stickyExecutionEnabled,omitempty"`
-	DecisionInfo              *shared.TransientDecisionInfo `json:"decisionInfo,omitempty"`
-	WorkflowExecutionTaskList *shared.TaskList              `json:"WorkflowExecutionTaskList,omitempty"`
-	EventStoreVersion         *int32                        `json:"eventStoreVersion,omitempty"`
-	BranchToken               []byte                        `json:"branchToken,omitempty"`
-	ScheduledTimestamp        *int64                        `json:"scheduledTimestamp,omitempty"`
-	StartedTimestamp          *int64                        `json:"startedTimestamp,omitempty"`
+	WorkflowType                        *shared.WorkflowType          `json:"workflowType,omitempty"`
+	PreviousStartedEventId              *int64                        `json:"previousStartedEventId,omitempty"`
+	ScheduledEventId                    *int64                        `json:"scheduledEventId,omitempty"`
+	StartedEventId                      *int64                        `json:"startedEventId,omitempty"`
+	NextEventId                         *int64                        `json:"nextEventId,omitempty"`
+	Attempt                             *int64                        `json:"attempt,omitempty"`
+	StickyExecutionEnabled              *bool                         `json:"stickyExecutionEnabled,omitempty"`
+	DecisionInfo                        *shared.TransientDecisionInfo `json:"decisionInfo,omitempty"`
+	WorkflowExecutionTaskList           *shared.TaskList              `json:"WorkflowExecutionTaskList,omitempty"`
+	EventStoreVersion                   *int32                        `json:"eventStoreVersion,omitempty"`
+	BranchToken                         []byte                        `json:"branchToken,omitempty"`
+	ScheduledTimestamp                  *int64                        `json:"scheduledTimestamp,omitempty"`
+	StartedTimestamp                    *int64                        `json:"startedTimestamp,omitempty"`
+	ScheduledTimestampOfThisAttempt     *int64                        `json:"scheduledTimestampOfThisAttempt,omitempty"`
+	StickyScheduleToStartTimeoutSeconds *int32                        `json:"stickyScheduleToStartTimeoutSeconds,omitempty"`
 }
 
 // ToWire translates a RecordDecisionTaskStartedResponse struct into a Thrift-level intermediate
@@ -3763,7 +3857,7 @@ type RecordDecisionTaskStartedResponse struct {
 //   }
 func (v *RecordDecisionTaskStartedResponse) ToWire() (wire.Value, error) {
 	var (
-		fields [13]wire.Field
+		fields [15]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -3873,6 +3967,22 @@ func (v *RecordDecisionTaskStartedResponse) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 130, Value: w}
 		i++
 	}
+	if v.ScheduledTimestampOfThisAttempt != nil {
+		w, err = wire.NewValueI64(*(v.ScheduledTimestampOfThisAttempt)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 140, Value: w}
+		i++
+	}
+	if v.StickyScheduleToStartTimeoutSeconds != nil {
+		w, err = wire.NewValueI32(*(v.StickyScheduleToStartTimeoutSeconds)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 150, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -4026,6 +4136,26 @@ func (v *RecordDecisionTaskStartedResponse) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 140:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.ScheduledTimestampOfThisAttempt = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 150:
+			if field.Value.Type() == wire.TI32 {
+				var x int32
+				x, err = field.Value.GetI32(), error(nil)
+				v.StickyScheduleToStartTimeoutSeconds = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -4040,7 +4170,7 @@ func (v *RecordDecisionTaskStartedResponse) String() string {
 		return "<nil>"
 	}
 
-	var fields [13]string
+	var fields [15]string
 	i := 0
 	if v.WorkflowType != nil {
 		fields[i] = fmt.Sprintf("WorkflowType: %v", v.WorkflowType)
@@ -4094,6 +4224,14 @@ func (v *RecordDecisionTaskStartedResponse) String() string {
 		fields[i] = fmt.Sprintf("StartedTimestamp: %v", *(v.StartedTimestamp))
 		i++
 	}
+	if v.ScheduledTimestampOfThisAttempt != nil {
+		fields[i] = fmt.Sprintf("ScheduledTimestampOfThisAttempt: %v", *(v.ScheduledTimestampOfThisAttempt))
+		i++
+	}
+	if v.StickyScheduleToStartTimeoutSeconds != nil {
+		fields[i] = fmt.Sprintf("StickyScheduleToStartTimeoutSeconds: %v", *(v.StickyScheduleToStartTimeoutSeconds))
+		i++
+	}
 
 	return fmt.Sprintf("RecordDecisionTaskStartedResponse{%v}", strings.Join(fields[:i], ", "))
 }
@@ -4147,6 +4285,12 @@ func (v *RecordDecisionTaskStartedResponse) Equals(rhs *RecordDecisionTaskStarte
 	if !_I64_EqualsPtr(v.StartedTimestamp, rhs.StartedTimestamp) {
 		return false
 	}
+	if !_I64_EqualsPtr(v.ScheduledTimestampOfThisAttempt, rhs.ScheduledTimestampOfThisAttempt) {
+		return false
+	}
+	if !_I32_EqualsPtr(v.StickyScheduleToStartTimeoutSeconds, rhs.StickyScheduleToStartTimeoutSeconds) {
+		return false
+	}
 
 	return true
 }
@@ -4196,6 +4340,12 @@ func (v *RecordDecisionTaskStartedResponse) MarshalLogObject(enc zapcore.ObjectE
 	if v.StartedTimestamp != nil {
 		enc.AddInt64("startedTimestamp", *v.StartedTimestamp)
 	}
+	if v.ScheduledTimestampOfThisAttempt != nil {
+		enc.AddInt64("scheduledTimestampOfThisAttempt", *v.ScheduledTimestampOfThisAttempt)
+	}
+	if v.StickyScheduleToStartTimeoutSeconds != nil {
+		enc.AddInt32("stickyScheduleToStartTimeoutSeconds", *v.StickyScheduleToStartTimeoutSeconds)
+	}
 	return err
 }
 
@@ -4394,6 +4544,36 @@ func (v *RecordDecisionTaskStartedResponse) IsSetStartedTimestamp() bool {
 	return v != nil && v.StartedTimestamp != nil
 }
 
+// GetScheduledTimestampOfThisAttempt returns the value of ScheduledTimestampOfThisAttempt if it is set or its
+// zero value if it is unset.
+func (v *RecordDecisionTaskStartedResponse) GetScheduledTimestampOfThisAttempt() (o int64) {
+	if v != nil && v.ScheduledTimestampOfThisAttempt != nil {
+		return *v.ScheduledTimestampOfThisAttempt
+	}
+
+	return
+}
+
+// IsSetScheduledTimestampOfThisAttempt returns true if ScheduledTimestampOfThisAttempt is not nil.
+func (v *RecordDecisionTaskStartedResponse) IsSetScheduledTimestampOfThisAttempt() bool {
+	return v != nil && v.ScheduledTimestampOfThisAttempt != nil
+}
+
+// GetStickyScheduleToStartTimeoutSeconds returns the value of StickyScheduleToStartTimeoutSeconds if it is set or its
+// zero value if it is unset.
+func (v *RecordDecisionTaskStartedResponse) GetStickyScheduleToStartTimeoutSeconds() (o int32) {
+	if v != nil && v.StickyScheduleToStartTimeoutSeconds != nil {
+		return *v.StickyScheduleToStartTimeoutSeconds
+	}
+
+	return
+}
+
+// IsSetStickyScheduleToStartTimeoutSeconds returns true if StickyScheduleToStartTimeoutSeconds is not nil.
+func (v *RecordDecisionTaskStartedResponse) IsSetStickyScheduleToStartTimeoutSeconds() bool {
+	return v != nil && v.StickyScheduleToStartTimeoutSeconds != nil
+}
+
 type RemoveSignalMutableStateRequest struct {
 	DomainUUID        *string                   `json:"domainUUID,omitempty"`
 	WorkflowExecution *shared.WorkflowExecution `json:"workflowExecution,omitempty"`
@@ -7200,6 +7380,7 @@ func (v *RespondDecisionTaskCompletedRequest) IsSetCompleteRequest() bool {
 
 type RespondDecisionTaskCompletedResponse struct {
 	StartedResponse *RecordDecisionTaskStartedResponse `json:"startedResponse,omitempty"`
+	EventsWritten   *int64                             `json:"eventsWritten,omitempty"`
 }
 
 // ToWire translates a RespondDecisionTaskCompletedResponse struct into a Thrift-level intermediate
@@ -7219,7 +7400,7 @@ type RespondDecisionTaskCompletedResponse struct {
 //   }
 func (v *RespondDecisionTaskCompletedResponse) ToWire() (wire.Value, error) {
 	var (
-		fields [1]wire.Field
+		fields [2]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -7233,6 +7414,14 @@ func (v *RespondDecisionTaskCompletedResponse) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 10, Value: w}
 		i++
 	}
+	if v.EventsWritten != nil {
+		w, err = wire.NewValueI64(*(v.EventsWritten)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 20, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -7272,6 +7461,16 @@ func (v *RespondDecisionTaskCompletedResponse) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 20:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.EventsWritten = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -7286,12 +7485,16 @@ func (v *RespondDecisionTaskCompletedResponse) String() string {
 		return "<nil>"
 	}
 
-	var fields [1]string
+	var fields [2]string
 	i := 0
 	if v.StartedResponse != nil {
 		fields[i] = fmt.Sprintf("StartedResponse: %v", v.StartedResponse)
 		i++
 	}
+	if v.EventsWritten != nil {
+		fields[i] = fmt.Sprintf("EventsWritten: %v", *(v.EventsWritten))
+		i++
+	}
 
 	return fmt.Sprintf("RespondDecisionTaskCompletedResponse{%v}", strings.Join(fields[:i], ", "))
 }
@@ -7309,6 +7512,9 @@ func (v *RespondDecisionTaskCompletedResponse) Equals(rhs *RespondDecisionTaskCo
 	if !((v.StartedResponse == nil && rhs.StartedResponse == nil) || (v.StartedResponse != nil && rhs.StartedResponse != nil && v.StartedResponse.Equals(rhs.StartedResponse))) {
 		return false
 	}
+	if !_I64_EqualsPtr(v.EventsWritten, rhs.EventsWritten) {
+		return false
+	}
 
 	return true
 }
@@ -7322,6 +7528,9 @@ func (v *RespondDecisionTaskCompletedResponse) MarshalLogObject(enc zapcore.Obje
 	if v.StartedResponse != nil {
 		err = multierr.Append(err, enc.AddObject("startedResponse", v.StartedResponse))
 	}
+	if v.EventsWritten != nil {
+		enc.AddInt64("eventsWritten", *v.EventsWritten)
+	}
 	return err
 }
 
@@ -7340,6 +7549,21 @@ func (v *RespondDecisionTaskCompletedResponse) IsSetStartedResponse() bool {
 	return v != nil && v.StartedResponse != nil
 }
 
+// GetEventsWritten returns the value of EventsWritten if it is set or its
+// zero value if it is unset.
+func (v *RespondDecisionTaskCompletedResponse) GetEventsWritten() (o int64) {
+	if v != nil && v.EventsWritten != nil {
+		return *v.EventsWritten
+	}
+
+	return
+}
+
+// IsSetEventsWritten returns true if EventsWritten is not nil.
+func (v *RespondDecisionTaskCompletedResponse) IsSetEventsWritten() bool {
+	return v != nil && v.EventsWritten != nil
+}
+
 type RespondDecisionTaskFailedRequest struct {
 	DomainUUID    *string                                  `json:"domainUUID,omitempty"`
 	FailedRequest *shared.RespondDecisionTaskFailedRequest `json:"failedRequest,omitempty"`
@@ -8405,6 +8629,8 @@ type StartWorkflowExecutionRequest struct {
 	ContinuedFailureDetails         []byte                                `json:"continuedFailureDetails,omitempty"`
 	LastCompletionResult            []byte                                `json:"lastCompletionResult,omitempty"`
 	FirstDecisionTaskBackoffSeconds *int32                                `json:"firstDecisionTaskBackoffSeconds,omitempty"`
+	RunId                           *string                               `json:"runId,omitempty"`
+	WaitForFirstDecisionScheduled   *bool                                 `json:"waitForFirstDecisionScheduled,omitempty"`
 }
 
 // ToWire translates a StartWorkflowExecutionRequest struct into a Thrift-level intermediate
@@ -8424,7 +8650,7 @@ type StartWorkflowExecutionRequest struct {
 //   }
 func (v *StartWorkflowExecutionRequest) ToWire() (wire.Value, error) {
 	var (
-		fields [10]wire.Field
+		fields [12]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -8510,6 +8736,22 @@ func (v *StartWorkflowExecutionRequest) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 60, Value: w}
 		i++
 	}
+	if v.RunId != nil {
+		w, err = wire.NewValueString(*(v.RunId)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 70, Value: w}
+		i++
+	}
+	if v.WaitForFirstDecisionScheduled != nil {
+		w, err = wire.NewValueBool(*(v.WaitForFirstDecisionScheduled)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 80, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -8645,6 +8887,26 @@ func (v *StartWorkflowExecutionRequest) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 70:
+			if field.Value.Type() == wire.TBinary {
+				var x string
+				x, err = field.Value.GetString(), error(nil)
+				v.RunId = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 80:
+			if field.Value.Type() == wire.TBool {
+				var x bool
+				x, err = field.Value.GetBool(), error(nil)
+				v.WaitForFirstDecisionScheduled = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -8659,7 +8921,7 @@ func (v *StartWorkflowExecutionRequest) String() string {
 		return "<nil>"
 	}
 
-	var fields [10]string
+	var fields [12]string
 	i := 0
 	if v.DomainUUID != nil {
 		fields[i] = fmt.Sprintf("DomainUUID: %v", *(v.DomainUUID))
@@ -8701,6 +8963,14 @@ func (v *StartWorkflowExecutionRequest) String() string {
 		fields[i] = fmt.Sprintf("FirstDecisionTaskBackoffSeconds: %v", *(v.FirstDecisionTaskBackoffSeconds))
 		i++
 	}
+	if v.RunId != nil {
+		fields[i] = fmt.Sprintf("RunId: %v", *(v.RunId))
+		i++
+	}
+	if v.WaitForFirstDecisionScheduled != nil {
+		fields[i] = fmt.Sprintf("WaitForFirstDecisionScheduled: %v", *(v.WaitForFirstDecisionScheduled))
+		i++
+	}
 
 	return fmt.Sprintf("StartWorkflowExecutionRequest{%v}", strings.Join(fields[:i], ", "))
 }
@@ -8755,6 +9025,12 @@ func (v *StartWorkflowExecutionRequest) Equals(rhs *StartWorkflowExecutionReques
 	if !_I32_EqualsPtr(v.FirstDecisionTaskBackoffSeconds, rhs.FirstDecisionTaskBackoffSeconds) {
 		return false
 	}
+	if !_String_EqualsPtr(v.RunId, rhs.RunId) {
+		return false
+	}
+	if !_Bool_EqualsPtr(v.WaitForFirstDecisionScheduled, rhs.WaitForFirstDecisionScheduled) {
+		return false
+	}
 
 	return true
 }
@@ -8795,6 +9071,12 @@ func (v *StartWorkflowExecutionRequest) MarshalLogObject(enc zapcore.ObjectEncod
 	if v.FirstDecisionTaskBackoffSeconds != nil {
 		enc.AddInt32("firstDecisionTaskBackoffSeconds", *v.FirstDecisionTaskBackoffSeconds)
 	}
+	if v.RunId != nil {
+		enc.AddString("runId", *v.RunId)
+	}
+	if v.WaitForFirstDecisionScheduled != nil {
+		enc.AddBool("waitForFirstDecisionScheduled", *v.WaitForFirstDecisionScheduled)
+	}
 	return err
 }
 
@@ -8948,6 +9230,36 @@ func (v *StartWorkflowExecutionRequest) IsSetFirstDecisionTaskBackoffSeconds() b
 	return v != nil && v.FirstDecisionTaskBackoffSeconds != nil
 }
 
+// GetRunId returns the value of RunId if it is set or its
+// zero value if it is unset.
+func (v *StartWorkflowExecutionRequest) GetRunId() (o string) {
+	if v != nil && v.RunId != nil {
+		return *v.RunId
+	}
+
+	return
+}
+
+// IsSetRunId returns true if RunId is not nil.
+func (v *StartWorkflowExecutionRequest) IsSetRunId() bool {
+	return v != nil && v.RunId != nil
+}
+
+// GetWaitForFirstDecisionScheduled returns the value of WaitForFirstDecisionScheduled if it is set or its
+// zero value if it is unset.
+func (v *StartWorkflowExecutionRequest) GetWaitForFirstDecisionScheduled() (o bool) {
+	if v != nil && v.WaitForFirstDecisionScheduled != nil {
+		return *v.WaitForFirstDecisionScheduled
+	}
+
+	return
+}
+
+// IsSetWaitForFirstDecisionScheduled returns true if WaitForFirstDecisionScheduled is not nil.
+func (v *StartWorkflowExecutionRequest) IsSetWaitForFirstDecisionScheduled() bool {
+	return v != nil && v.WaitForFirstDecisionScheduled != nil
+}
+
 type SyncActivityRequest struct {
 	DomainId          *string `json:"domainId,omitempty"`
 	WorkflowId        *string `json:"workflowId,omitempty"`
@@ -1655,22 +1655,24 @@ func (v *PollForDecisionTaskRequest) IsSetPollRequest() bool {
 }
 
 type PollForDecisionTaskResponse struct {
-	TaskToken                 []byte                        `json:"taskToken,omitempty"`
-	WorkflowExecution         *shared.WorkflowExecution     `json:"workflowExecution,omitempty"`
-	WorkflowType              *shared.WorkflowType          `json:"workflowType,omitempty"`
-	PreviousStartedEventId    *int64                        `json:"previousStartedEventId,omitempty"`
-	StartedEventId            *int64                        `json:"startedEventId,omitempty"`
-	Attempt                   *int64                        `json:"attempt,omitempty"`
-	NextEventId               *int64                        `json:"nextEventId,omitempty"`
-	BacklogCountHint          *int64                        `json:"backlogCountHint,omitempty"`
-	StickyExecutionEnabled    *bool                         `json:"stickyExecutionEnabled,omitempty"`
-	Query                     *shared.WorkflowQuery         `json:"query,omitempty"`
-	DecisionInfo              *shared.TransientDecisionInfo `json:"decisionInfo,omitempty"`
-	WorkflowExecutionTaskList *shared.TaskList              `json:"WorkflowExecutionTaskList,omitempty"`
-	EventStoreVersion         *int32                        `json:"eventStoreVersion,omitempty"`
-	BranchToken               []byte                        `json:"branchToken,omitempty"`
-	ScheduledTimestamp        *int64                        `json:"scheduledTimestamp,omitempty"`
-	StartedTimestamp          *int64                        `json:"startedTimestamp,omitempty"`
+	TaskToken                           []byte                        `json:"taskToken,omitempty"`
+	WorkflowExecution                   *shared.WorkflowExecution     `json:"workflowExecution,omitempty"`
+	WorkflowType                        *shared.WorkflowType          `json:"workflowType,omitempty"`
+	PreviousStartedEventId              *int64                        `json:"previousStartedEventId,omitempty"`
+	StartedEventId                      *int64                        `json:"startedEventId,omitempty"`
+	Attempt                             *int64                        `json:"attempt,omitempty"`
+	NextEventId                         *int64                        `json:"nextEventId,omitempty"`
+	BacklogCountHint                    *int64                        `json:"backlogCountHint,omitempty"`
+	StickyExecutionEnabled              *bool                         `json:"stickyExecutionEnabled,omitempty"`
+	Query                               *shared.WorkflowQuery         `json:"query,omitempty"`
+	DecisionInfo                        *shared.TransientDecisionInfo `json:"decisionInfo,omitempty"`
+	WorkflowExecutionTaskList           *shared.TaskList              `json:"WorkflowExecutionTaskList,omitempty"`
+	EventStoreVersion                   *int32                        `json:"eventStoreVersion,omitempty"`
+	BranchToken                         []byte                        `json:"branchToken,omitempty"`
+	ScheduledTimestamp                  *int64                        `json:"scheduledTimestamp,omitempty"`
+	StartedTimestamp                    *int64                        `json:"startedTimestamp,omitempty"`
+	StickyScheduleToStartTimeoutSeconds *int32                        `json:"stickyScheduleToStartTimeoutSeconds,omitempty"`
+	ScheduledTimestampOfThisAttempt     *int64                        `json:"scheduledTimestampOfThisAttempt,omitempty"`
 }
 
 // ToWire translates a PollForDecisionTaskResponse struct into a Thrift-level intermediate
@@ -1690,7 +1692,7 @@ type PollForDecisionTaskResponse struct {
 //   }
 func (v *PollForDecisionTaskResponse) ToWire() (wire.Value, error) {
 	var (
-		fields [16]wire.Field
+		fields [18]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -1824,6 +1826,22 @@ func (v *PollForDecisionTaskResponse) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 140, Value: w}
 		i++
 	}
+	if v.StickyScheduleToStartTimeoutSeconds != nil {
+		w, err = wire.NewValueI32(*(v.StickyScheduleToStartTimeoutSeconds)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 150, Value: w}
+		i++
+	}
+	if v.ScheduledTimestampOfThisAttempt != nil {
+		w, err = wire.NewValueI64(*(v.ScheduledTimestampOfThisAttempt)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 160, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -2013,6 +2031,26 @@ func (v *PollForDecisionTaskResponse) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 150:
+			if field.Value.Type() == wire.TI32 {
+				var x int32
+				x, err = field.Value.GetI32(), error(nil)
+				v.StickyScheduleToStartTimeoutSeconds = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 160:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.ScheduledTimestampOfThisAttempt = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -2027,7 +2065,7 @@ func (v *PollForDecisionTaskResponse) String() string {
 		return "<nil>"
 	}
 
-	var fields [16]string
+	var fields [18]string
 	i := 0
 	if v.TaskToken != nil {
 		fields[i] = fmt.Sprintf("TaskToken: %v", v.TaskToken)
@@ -2093,6 +2131,14 @@ func (v *PollForDecisionTaskResponse) String() string {
 		fields[i] = fmt.Sprintf("StartedTimestamp: %v", *(v.StartedTimestamp))
 		i++
 	}
+	if v.StickyScheduleToStartTimeoutSeconds != nil {
+		fields[i] = fmt.Sprintf("StickyScheduleToStartTimeoutSeconds: %v", *(v.StickyScheduleToStartTimeoutSeconds))
+		i++
+	}
+	if v.ScheduledTimestampOfThisAttempt != nil {
+		fields[i] = fmt.Sprintf("ScheduledTimestampOfThisAttempt: %v", *(v.ScheduledTimestampOfThisAttempt))
+		i++
+	}
 
 	return fmt.Sprintf("PollForDecisionTaskResponse{%v}", strings.Join(fields[:i], ", "))
 }
@@ -2165,6 +2211,12 @@ func (v *PollForDecisionTaskResponse) Equals(rhs *PollForDecisionTaskResponse) b
 	if !_I64_EqualsPtr(v.StartedTimestamp, rhs.StartedTimestamp) {
 		return false
 	}
+	if !_I32_EqualsPtr(v.StickyScheduleToStartTimeoutSeconds, rhs.StickyScheduleToStartTimeoutSeconds) {
+		return false
+	}
+	if !_I64_EqualsPtr(v.ScheduledTimestampOfThisAttempt, rhs.ScheduledTimestampOfThisAttempt) {
+		return false
+	}
 
 	return true
 }
@@ -2223,6 +2275,12 @@ func (v *PollForDecisionTaskResponse) MarshalLogObject(enc zapcore.ObjectEncoder
 	if v.StartedTimestamp != nil {
 		enc.AddInt64("startedTimestamp", *v.StartedTimestamp)
 	}
+	if v.StickyScheduleToStartTimeoutSeconds != nil {
+		enc.AddInt32("stickyScheduleToStartTimeoutSeconds", *v.StickyScheduleToStartTimeoutSeconds)
+	}
+	if v.ScheduledTimestampOfThisAttempt != nil {
+		enc.AddInt64("scheduledTimestampOfThisAttempt", *v.ScheduledTimestampOfThisAttempt)
+	}
 	return err
 }
 
@@ -2466,6 +2524,36 @@ func (v *PollForDecisionTaskResponse) IsSetStartedTimestamp() bool {
 	return v != nil && v.StartedTimestamp != nil
 }
 
+// GetStickyScheduleToStartTimeoutSeconds returns the value of StickyScheduleToStartTimeoutSeconds if it is set or its
+// zero value if it is unset.
+func (v *PollForDecisionTaskResponse) GetStickyScheduleToStartTimeoutSeconds() (o int32) {
+	if v != nil && v.StickyScheduleToStartTimeoutSeconds != nil {
+		return *v.StickyScheduleToStartTimeoutSeconds
+	}
+
+	return
+}
+
+// IsSetStickyScheduleToStartTimeoutSeconds returns true if StickyScheduleToStartTimeoutSeconds is not nil.
+func (v *PollForDecisionTaskResponse) IsSetStickyScheduleToStartTimeoutSeconds() bool {
+	return v != nil && v.StickyScheduleToStartTimeoutSeconds != nil
+}
+
+// GetScheduledTimestampOfThisAttempt returns the value of ScheduledTimestampOfThisAttempt if it is set or its
+// zero value if it is unset.
+func (v *PollForDecisionTaskResponse) GetScheduledTimestampOfThisAttempt() (o int64) {
+	if v != nil && v.ScheduledTimestampOfThisAttempt != nil {
+		return *v.ScheduledTimestampOfThisAttempt
+	}
+
+	return
+}
+
+// IsSetScheduledTimestampOfThisAttempt returns true if ScheduledTimestampOfThisAttempt is not nil.
+func (v *PollForDecisionTaskResponse) IsSetScheduledTimestampOfThisAttempt() bool {
+	return v != nil && v.ScheduledTimestampOfThisAttempt != nil
+}
+
 type QueryWorkflowRequest struct {
 	DomainUUID   *string                      `json:"domainUUID,omitempty"`
 	TaskList     *shared.TaskList             `json:"taskList,omitempty"`
@@ -1278,6 +1278,8 @@ type ActivityTaskScheduledEventAttributes struct {
 	DecisionTaskCompletedEventId  *int64        `json:"decisionTaskCompletedEventId,omitempty"`
 	RetryPolicy                   *RetryPolicy  `json:"retryPolicy,omitempty"`
 	Header                        *Header       `json:"header,omitempty"`
+	DispatchOnly                  *bool         `json:"dispatchOnly,omitempty"`
+	ScheduleDelaySeconds          *int32        `json:"scheduleDelaySeconds,omitempty"`
 }
 
 // ToWire translates a ActivityTaskScheduledEventAttributes struct into a Thrift-level intermediate
@@ -1297,7 +1299,7 @@ type ActivityTaskScheduledEventAttributes struct {
 //   }
 func (v *ActivityTaskScheduledEventAttributes) ToWire() (wire.Value, error) {
 	var (
-		fields [12]wire.Field
+		fields [14]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -1399,6 +1401,22 @@ func (v *ActivityTaskScheduledEventAttributes) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 120, Value: w}
 		i++
 	}
+	if v.DispatchOnly != nil {
+		w, err = wire.NewValueBool(*(v.DispatchOnly)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 130, Value: w}
+		i++
+	}
+	if v.ScheduleDelaySeconds != nil {
+		w, err = wire.NewValueI32(*(v.ScheduleDelaySeconds)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 140, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -1558,6 +1576,26 @@ func (v *ActivityTaskScheduledEventAttributes) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 130:
+			if field.Value.Type() == wire.TBool {
+				var x bool
+				x, err = field.Value.GetBool(), error(nil)
+				v.DispatchOnly = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 140:
+			if field.Value.Type() == wire.TI32 {
+				var x int32
+				x, err = field.Value.GetI32(), error(nil)
+				v.ScheduleDelaySeconds = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -1572,7 +1610,7 @@ func (v *ActivityTaskScheduledEventAttributes) String() string {
 		return "<nil>"
 	}
 
-	var fields [12]string
+	var fields [14]string
 	i := 0
 	if v.ActivityId != nil {
 		fields[i] = fmt.Sprintf("ActivityId: %v", *(v.ActivityId))
@@ -1622,6 +1660,14 @@ func (v *ActivityTaskScheduledEventAttributes) String() string {
 		fields[i] = fmt.Sprintf("Header: %v", v.Header)
 		i++
 	}
+	if v.DispatchOnly != nil {
+		fields[i] = fmt.Sprintf("DispatchOnly: %v", *(v.DispatchOnly))
+		i++
+	}
+	if v.ScheduleDelaySeconds != nil {
+		fields[i] = fmt.Sprintf("ScheduleDelaySeconds: %v", *(v.ScheduleDelaySeconds))
+		i++
+	}
 
 	return fmt.Sprintf("ActivityTaskScheduledEventAttributes{%v}", strings.Join(fields[:i], ", "))
 }
@@ -1636,6 +1682,16 @@ func _I32_EqualsPtr(lhs, rhs *int32) bool {
 	return lhs == nil && rhs == nil
 }
 
+func _Bool_EqualsPtr(lhs, rhs *bool) bool {
+	if lhs != nil && rhs != nil {
+
+		x := *lhs
+		y := *rhs
+		return (x == y)
+	}
+	return lhs == nil && rhs == nil
+}
+
 // Equals returns true if all the fields of this ActivityTaskScheduledEventAttributes match the
 // provided ActivityTaskScheduledEventAttributes.
 //
@@ -1682,6 +1738,12 @@ func (v *ActivityTaskScheduledEventAttributes) Equals(rhs *ActivityTaskScheduled
 	if !((v.Header == nil && rhs.Header == nil) || (v.Header != nil && rhs.Header != nil && v.Header.Equals(rhs.Header))) {
 		return false
 	}
+	if !_Bool_EqualsPtr(v.DispatchOnly, rhs.DispatchOnly) {
+		return false
+	}
+	if !_I32_EqualsPtr(v.ScheduleDelaySeconds, rhs.ScheduleDelaySeconds) {
+		return false
+	}
 
 	return true
 }
@@ -1728,6 +1790,12 @@ func (v *ActivityTaskScheduledEventAttributes) MarshalLogObject(enc zapcore.Obje
 	if v.Header != nil {
 		err = multierr.Append(err, enc.AddObject("header", v.Header))
 	}
+	if v.DispatchOnly != nil {
+		enc.AddBool("dispatchOnly", *v.DispatchOnly)
+	}
+	if v.ScheduleDelaySeconds != nil {
+		enc.AddInt32("scheduleDelaySeconds", *v.ScheduleDelaySeconds)
+	}
 	return err
 }
 
@@ -1911,6 +1979,36 @@ func (v *ActivityTaskScheduledEventAttributes) IsSetHeader() bool {
 	return v != nil && v.Header != nil
 }
 
+// GetDispatchOnly returns the value of DispatchOnly if it is set or its
+// zero value if it is unset.
+func (v *ActivityTaskScheduledEventAttributes) GetDispatchOnly() (o bool) {
+	if v != nil && v.DispatchOnly != nil {
+		return *v.DispatchOnly
+	}
+
+	return
+}
+
+// IsSetDispatchOnly returns true if DispatchOnly is not nil.
+func (v *ActivityTaskScheduledEventAttributes) IsSetDispatchOnly() bool {
+	return v != nil && v.DispatchOnly != nil
+}
+
+// GetScheduleDelaySeconds returns the value of ScheduleDelaySeconds if it is set or its
+// zero value if it is unset.
+func (v *ActivityTaskScheduledEventAttributes) GetScheduleDelaySeconds() (o int32) {
+	if v != nil && v.ScheduleDelaySeconds != nil {
+		return *v.ScheduleDelaySeconds
+	}
+
+	return
+}
+
+// IsSetScheduleDelaySeconds returns true if ScheduleDelaySeconds is not nil.
+func (v *ActivityTaskScheduledEventAttributes) IsSetScheduleDelaySeconds() bool {
+	return v != nil && v.ScheduleDelaySeconds != nil
+}
+
 type ActivityTaskStartedEventAttributes struct {
 	ScheduledEventId *int64  `json:"scheduledEventId,omitempty"`
 	Identity         *string `json:"identity,omitempty"`
@@ -7310,6 +7408,8 @@ type ContinueAsNewWorkflowExecutionDecisionAttributes struct {
 	LastCompletionResult                []byte                  `json:"lastCompletionResult,omitempty"`
 	CronSchedule                        *string                 `json:"cronSchedule,omitempty"`
 	Header                              *Header                 `json:"header,omitempty"`
+	Memo                                *Memo                   `json:"memo,omitempty"`
+	SearchAttributes                    *SearchAttributes       `json:"searchAttributes,omitempty"`
 }
 
 // ToWire translates a ContinueAsNewWorkflowExecutionDecisionAttributes struct into a Thrift-level intermediate
@@ -7329,7 +7429,7 @@ type ContinueAsNewWorkflowExecutionDecisionAttributes struct {
 //   }
 func (v *ContinueAsNewWorkflowExecutionDecisionAttributes) ToWire() (wire.Value, error) {
 	var (
-		fields [13]wire.Field
+		fields [15]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -7439,6 +7539,22 @@ func (v *ContinueAsNewWorkflowExecutionDecisionAttributes) ToWire() (wire.Value,
 		fields[i] = wire.Field{ID: 130, Value: w}
 		i++
 	}
+	if v.Memo != nil {
+		w, err = v.Memo.ToWire()
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 140, Value: w}
+		i++
+	}
+	if v.SearchAttributes != nil {
+		w, err = v.SearchAttributes.ToWire()
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 150, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -7586,6 +7702,22 @@ func (v *ContinueAsNewWorkflowExecutionDecisionAttributes) FromWire(w wire.Value
 					return err
 				}
 
+			}
+		case 140:
+			if field.Value.Type() == wire.TStruct {
+				v.Memo, err = _Memo_Read(field.Value)
+				if err != nil {
+					return err
+				}
+
+			}
+		case 150:
+			if field.Value.Type() == wire.TStruct {
+				v.SearchAttributes, err = _SearchAttributes_Read(field.Value)
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -7600,7 +7732,7 @@ func (v *ContinueAsNewWorkflowExecutionDecisionAttributes) String() string {
 		return "<nil>"
 	}
 
-	var fields [13]string
+	var fields [15]string
 	i := 0
 	if v.WorkflowType != nil {
 		fields[i] = fmt.Sprintf("WorkflowType: %v", v.WorkflowType)
@@ -7654,6 +7786,14 @@ func (v *ContinueAsNewWorkflowExecutionDecisionAttributes) String() string {
 		fields[i] = fmt.Sprintf("Header: %v", v.Header)
 		i++
 	}
+	if v.Memo != nil {
+		fields[i] = fmt.Sprintf("Memo: %v", v.Memo)
+		i++
+	}
+	if v.SearchAttributes != nil {
+		fields[i] = fmt.Sprintf("SearchAttributes: %v", v.SearchAttributes)
+		i++
+	}
 
 	return fmt.Sprintf("ContinueAsNewWorkflowExecutionDecisionAttributes{%v}", strings.Join(fields[:i], ", "))
 }
@@ -7717,6 +7857,12 @@ func (v *ContinueAsNewWorkflowExecutionDecisionAttributes) Equals(rhs *ContinueA
 	if !((v.Header == nil && rhs.Header == nil) || (v.Header != nil && rhs.Header != nil && v.Header.Equals(rhs.Header))) {
 		return false
 	}
+	if !((v.Memo == nil && rhs.Memo == nil) || (v.Memo != nil && rhs.Memo != nil && v.Memo.Equals(rhs.Memo))) {
+		return false
+	}
+	if !((v.SearchAttributes == nil && rhs.SearchAttributes == nil) || (v.SearchAttributes != nil && rhs.SearchAttributes != nil && v.SearchAttributes.Equals(rhs.SearchAttributes))) {
+		return false
+	}
 
 	return true
 }
@@ -7766,6 +7912,12 @@ func (v *ContinueAsNewWorkflowExecutionDecisionAttributes) MarshalLogObject(enc
 	if v.Header != nil {
 		err = multierr.Append(err, enc.AddObject("header", v.Header))
 	}
+	if v.Memo != nil {
+		err = multierr.Append(err, enc.AddObject("memo", v.Memo))
+	}
+	if v.SearchAttributes != nil {
+		err = multierr.Append(err, enc.AddObject("searchAttributes", v.SearchAttributes))
+	}
 	return err
 }
 
@@ -7964,6 +8116,36 @@ func (v *ContinueAsNewWorkflowExecutionDecisionAttributes) IsSetHeader() bool {
 	return v != nil && v.Header != nil
 }
 
+// GetMemo returns the value of Memo if it is set or its
+// zero value if it is unset.
+func (v *ContinueAsNewWorkflowExecutionDecisionAttributes) GetMemo() (o *Memo) {
+	if v != nil && v.Memo != nil {
+		return v.Memo
+	}
+
+	return
+}
+
+// IsSetMemo returns true if Memo is not nil.
+func (v *ContinueAsNewWorkflowExecutionDecisionAttributes) IsSetMemo() bool {
+	return v != nil && v.Memo != nil
+}
+
+// GetSearchAttributes returns the value of SearchAttributes if it is set or its
+// zero value if it is unset.
+func (v *ContinueAsNewWorkflowExecutionDecisionAttributes) GetSearchAttributes() (o *SearchAttributes) {
+	if v != nil && v.SearchAttributes != nil {
+		return v.SearchAttributes
+	}
+
+	return
+}
+
+// IsSetSearchAttributes returns true if SearchAttributes is not nil.
+func (v *ContinueAsNewWorkflowExecutionDecisionAttributes) IsSetSearchAttributes() bool {
+	return v != nil && v.SearchAttributes != nil
+}
+
 type CountWorkflowExecutionsRequest struct {
 	Domain *string `json:"domain,omitempty"`
 	Query  *string `json:"query,omitempty"`
@@ -8494,6 +8676,7 @@ type Decision struct {
 	ContinueAsNewWorkflowExecutionDecisionAttributes         *ContinueAsNewWorkflowExecutionDecisionAttributes         `json:"continueAsNewWorkflowExecutionDecisionAttributes,omitempty"`
 	StartChildWorkflowExecutionDecisionAttributes            *StartChildWorkflowExecutionDecisionAttributes            `json:"startChildWorkflowExecutionDecisionAttributes,omitempty"`
 	SignalExternalWorkflowExecutionDecisionAttributes        *SignalExternalWorkflowExecutionDecisionAttributes        `json:"signalExternalWorkflowExecutionDecisionAttributes,omitempty"`
+	UpsertWorkflowMemoDecisionAttributes                     *UpsertWorkflowMemoDecisionAttributes                     `json:"upsertWorkflowMemoDecisionAttributes,omitempty"`
 }
 
 // ToWire translates a Decision struct into a Thrift-level intermediate
@@ -8513,7 +8696,7 @@ type Decision struct {
 //   }
 func (v *Decision) ToWire() (wire.Value, error) {
 	var (
-		fields [13]wire.Field
+		fields [14]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -8623,6 +8806,14 @@ func (v *Decision) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 110, Value: w}
 		i++
 	}
+	if v.UpsertWorkflowMemoDecisionAttributes != nil {
+		w, err = v.UpsertWorkflowMemoDecisionAttributes.ToWire()
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 120, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -8705,6 +8896,12 @@ func _SignalExternalWorkflowExecutionDecisionAttributes_Read(w wire.Value) (*Sig
 	return &v, err
 }
 
+func _UpsertWorkflowMemoDecisionAttributes_Read(w wire.Value) (*UpsertWorkflowMemoDecisionAttributes, error) {
+	var v UpsertWorkflowMemoDecisionAttributes
+	err := v.FromWire(w)
+	return &v, err
+}
+
 // FromWire deserializes a Decision struct from its Thrift-level
 // representation. The Thrift-level representation may be obtained
 // from a ThriftRW protocol implementation.
@@ -8832,6 +9029,14 @@ func (v *Decision) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 120:
+			if field.Value.Type() == wire.TStruct {
+				v.UpsertWorkflowMemoDecisionAttributes, err = _UpsertWorkflowMemoDecisionAttributes_Read(field.Value)
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -8846,7 +9051,7 @@ func (v *Decision) String() string {
 		return "<nil>"
 	}
 
-	var fields [13]string
+	var fields [14]string
 	i := 0
 	if v.DecisionType != nil {
 		fields[i] = fmt.Sprintf("DecisionType: %v", *(v.DecisionType))
@@ -8900,6 +9105,10 @@ func (v *Decision) String() string {
 		fields[i] = fmt.Sprintf("SignalExternalWorkflowExecutionDecisionAttributes: %v", v.SignalExternalWorkflowExecutionDecisionAttributes)
 		i++
 	}
+	if v.UpsertWorkflowMemoDecisionAttributes != nil {
+		fields[i] = fmt.Sprintf("UpsertWorkflowMemoDecisionAttributes: %v", v.UpsertWorkflowMemoDecisionAttributes)
+		i++
+	}
 
 	return fmt.Sprintf("Decision{%v}", strings.Join(fields[:i], ", "))
 }
@@ -8963,6 +9172,9 @@ func (v *Decision) Equals(rhs *Decision) bool {
 	if !((v.SignalExternalWorkflowExecutionDecisionAttributes == nil && rhs.SignalExternalWorkflowExecutionDecisionAttributes == nil) || (v.SignalExternalWorkflowExecutionDecisionAttributes != nil && rhs.SignalExternalWorkflowExecutionDecisionAttributes != nil && v.SignalExternalWorkflowExecutionDecisionAttributes.Equals(rhs.SignalExternalWorkflowExecutionDecisionAttributes))) {
 		return false
 	}
+	if !((v.UpsertWorkflowMemoDecisionAttributes == nil && rhs.UpsertWorkflowMemoDecisionAttributes == nil) || (v.UpsertWorkflowMemoDecisionAttributes != nil && rhs.UpsertWorkflowMemoDecisionAttributes != nil && v.UpsertWorkflowMemoDecisionAttributes.Equals(rhs.UpsertWorkflowMemoDecisionAttributes))) {
+		return false
+	}
 
 	return true
 }
@@ -9012,6 +9224,9 @@ func (v *Decision) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
 	if v.SignalExternalWorkflowExecutionDecisionAttributes != nil {
 		err = multierr.Append(err, enc.AddObject("signalExternalWorkflowExecutionDecisionAttributes", v.SignalExternalWorkflowExecutionDecisionAttributes))
 	}
+	if v.UpsertWorkflowMemoDecisionAttributes != nil {
+		err = multierr.Append(err, enc.AddObject("upsertWorkflowMemoDecisionAttributes", v.UpsertWorkflowMemoDecisionAttributes))
+	}
 	return err
 }
 
@@ -9210,6 +9425,21 @@ func (v *Decision) IsSetSignalExternalWorkflowExecutionDecisionAttributes() bool
 	return v != nil && v.SignalExternalWorkflowExecutionDecisionAttributes != nil
 }
 
+// GetUpsertWorkflowMemoDecisionAttributes returns the value of UpsertWorkflowMemoDecisionAttributes if it is set or its
+// zero value if it is unset.
+func (v *Decision) GetUpsertWorkflowMemoDecisionAttributes() (o *UpsertWorkflowMemoDecisionAttributes) {
+	if v != nil && v.UpsertWorkflowMemoDecisionAttributes != nil {
+		return v.UpsertWorkflowMemoDecisionAttributes
+	}
+
+	return
+}
+
+// IsSetUpsertWorkflowMemoDecisionAttributes returns true if UpsertWorkflowMemoDecisionAttributes is not nil.
+func (v *Decision) IsSetUpsertWorkflowMemoDecisionAttributes() bool {
+	return v != nil && v.UpsertWorkflowMemoDecisionAttributes != nil
+}
+
 type DecisionTaskCompletedEventAttributes struct {
 	ExecutionContext []byte  `json:"executionContext,omitempty"`
 	ScheduledEventId *int64  `json:"scheduledEventId,omitempty"`
@@ -9547,6 +9777,9 @@ const (
 	DecisionTaskFailedCauseResetWorkflow                                       DecisionTaskFailedCause = 19
 	DecisionTaskFailedCauseBadBinary                                           DecisionTaskFailedCause = 20
 	DecisionTaskFailedCauseScheduleActivityDuplicateID                         DecisionTaskFailedCause = 21
+	DecisionTaskFailedCausePendingChildWorkflowsLimitExceeded                  DecisionTaskFailedCause = 22
+	DecisionTaskFailedCauseBadExecutionContextSize                             DecisionTaskFailedCause = 23
+	DecisionTaskFailedCauseBadUpsertWorkflowMemoAttributes                     DecisionTaskFailedCause = 24
 )
 
 // DecisionTaskFailedCause_Values returns all recognized values of DecisionTaskFailedCause.
@@ -9574,6 +9807,9 @@ func DecisionTaskFailedCause_Values() []DecisionTaskFailedCause {
 		DecisionTaskFailedCauseResetWorkflow,
 		DecisionTaskFailedCauseBadBinary,
 		DecisionTaskFailedCauseScheduleActivityDuplicateID,
+		DecisionTaskFailedCausePendingChildWorkflowsLimitExceeded,
+		DecisionTaskFailedCauseBadExecutionContextSize,
+		DecisionTaskFailedCauseBadUpsertWorkflowMemoAttributes,
 	}
 }
 
@@ -9650,6 +9886,15 @@ func (v *DecisionTaskFailedCause) UnmarshalText(value []byte) error {
 	case "SCHEDULE_ACTIVITY_DUPLICATE_ID":
 		*v = DecisionTaskFailedCauseScheduleActivityDuplicateID
 		return nil
+	case "PENDING_CHILD_WORKFLOWS_LIMIT_EXCEEDED":
+		*v = DecisionTaskFailedCausePendingChildWorkflowsLimitExceeded
+		return nil
+	case "BAD_EXECUTION_CONTEXT_SIZE":
+		*v = DecisionTaskFailedCauseBadExecutionContextSize
+		return nil
+	case "BAD_UPSERT_WORKFLOW_MEMO_ATTRIBUTES":
+		*v = DecisionTaskFailedCauseBadUpsertWorkflowMemoAttributes
+		return nil
 	default:
 		val, err := strconv.ParseInt(s, 10, 32)
 		if err != nil {
@@ -9712,6 +9957,12 @@ func (v DecisionTaskFailedCause) MarshalText() ([]byte, error) {
 		return []byte("BAD_BINARY"), nil
 	case 21:
 		return []byte("SCHEDULE_ACTIVITY_DUPLICATE_ID"), nil
+	case 22:
+		return []byte("PENDING_CHILD_WORKFLOWS_LIMIT_EXCEEDED"), nil
+	case 23:
+		return []byte("BAD_EXECUTION_CONTEXT_SIZE"), nil
+	case 24:
+		return []byte("BAD_UPSERT_WORKFLOW_MEMO_ATTRIBUTES"), nil
 	}
 	return []byte(strconv.FormatInt(int64(v), 10)), nil
 }
@@ -9767,6 +10018,12 @@ func (v DecisionTaskFailedCause) MarshalLogObject(enc zapcore.ObjectEncoder) err
 		enc.AddString("name", "BAD_BINARY")
 	case 21:
 		enc.AddString("name", "SCHEDULE_ACTIVITY_DUPLICATE_ID")
+	case 22:
+		enc.AddString("name", "PENDING_CHILD_WORKFLOWS_LIMIT_EXCEEDED")
+	case 23:
+		enc.AddString("name", "BAD_EXECUTION_CONTEXT_SIZE")
+	case 24:
+		enc.AddString("name", "BAD_UPSERT_WORKFLOW_MEMO_ATTRIBUTES")
 	}
 	return nil
 }
@@ -9851,6 +10108,12 @@ func (v DecisionTaskFailedCause) String() string {
 		return "BAD_BINARY"
 	case 21:
 		return "SCHEDULE_ACTIVITY_DUPLICATE_ID"
+	case 22:
+		return "PENDING_CHILD_WORKFLOWS_LIMIT_EXCEEDED"
+	case 23:
+		return "BAD_EXECUTION_CONTEXT_SIZE"
+	case 24:
+		return "BAD_UPSERT_WORKFLOW_MEMO_ATTRIBUTES"
 	}
 	return fmt.Sprintf("DecisionTaskFailedCause(%d)", w)
 }
@@ -9913,6 +10176,12 @@ func (v DecisionTaskFailedCause) MarshalJSON() ([]byte, error) {
 		return ([]byte)("\"BAD_BINARY\""), nil
 	case 21:
 		return ([]byte)("\"SCHEDULE_ACTIVITY_DUPLICATE_ID\""), nil
+	case 22:
+		return ([]byte)("\"PENDING_CHILD_WORKFLOWS_LIMIT_EXCEEDED\""), nil
+	case 23:
+		return ([]byte)("\"BAD_EXECUTION_CONTEXT_SIZE\""), nil
+	case 24:
+		return ([]byte)("\"BAD_UPSERT_WORKFLOW_MEMO_ATTRIBUTES\""), nil
 	}
 	return ([]byte)(strconv.FormatInt(int64(v), 10)), nil
 }
@@ -11148,6 +11417,7 @@ const (
 	DecisionTypeContinueAsNewWorkflowExecution         DecisionType = 9
 	DecisionTypeStartChildWorkflowExecution            DecisionType = 10
 	DecisionTypeSignalExternalWorkflowExecution        DecisionType = 11
+	DecisionTypeUpsertWorkflowMemo                     DecisionType = 12
 )
 
 // DecisionType_Values returns all recognized values of DecisionType.
@@ -11165,6 +11435,7 @@ func DecisionType_Values() []DecisionType {
 		DecisionTypeContinueAsNewWorkflowExecution,
 		DecisionTypeStartChildWorkflowExecution,
 		DecisionTypeSignalExternalWorkflowExecution,
+		DecisionTypeUpsertWorkflowMemo,
 	}
 }
 
@@ -11211,6 +11482,9 @@ func (v *DecisionType) UnmarshalText(value []byte) error {
 	case "SignalExternalWorkflowExecution":
 		*v = DecisionTypeSignalExternalWorkflowExecution
 		return nil
+	case "UpsertWorkflowMemo":
+		*v = DecisionTypeUpsertWorkflowMemo
+		return nil
 	default:
 		val, err := strconv.ParseInt(s, 10, 32)
 		if err != nil {
@@ -11253,6 +11527,8 @@ func (v DecisionType) MarshalText() ([]byte, error) {
 		return []byte("StartChildWorkflowExecution"), nil
 	case 11:
 		return []byte("SignalExternalWorkflowExecution"), nil
+	case 12:
+		return []byte("UpsertWorkflowMemo"), nil
 	}
 	return []byte(strconv.FormatInt(int64(v), 10)), nil
 }
@@ -11288,6 +11564,8 @@ func (v DecisionType) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 		enc.AddString("name", "StartChildWorkflowExecution")
 	case 11:
 		enc.AddString("name", "SignalExternalWorkflowExecution")
+	case 12:
+		enc.AddString("name", "UpsertWorkflowMemo")
 	}
 	return nil
 }
@@ -11352,6 +11630,8 @@ func (v DecisionType) String() string {
 		return "StartChildWorkflowExecution"
 	case 11:
 		return "SignalExternalWorkflowExecution"
+	case 12:
+		return "UpsertWorkflowMemo"
 	}
 	return fmt.Sprintf("DecisionType(%d)", w)
 }
@@ -11394,6 +11674,8 @@ func (v DecisionType) MarshalJSON() ([]byte, error) {
 		return ([]byte)("\"StartChildWorkflowExecution\""), nil
 	case 11:
 		return ([]byte)("\"SignalExternalWorkflowExecution\""), nil
+	case 12:
+		return ([]byte)("\"UpsertWorkflowMemo\""), nil
 	}
 	return ([]byte)(strconv.FormatInt(int64(v), 10)), nil
 }
@@ -11996,16 +12278,6 @@ func (v *DescribeDomainResponse) String() string {
 	return fmt.Sprintf("DescribeDomainResponse{%v}", strings.Join(fields[:i], ", "))
 }
 
-func _Bool_EqualsPtr(lhs, rhs *bool) bool {
-	if lhs != nil && rhs != nil {
-
-		x := *lhs
-		y := *rhs
-		return (x == y)
-	}
-	return lhs == nil && rhs == nil
-}
-
 // Equals returns true if all the fields of this DescribeDomainResponse match the
 // provided DescribeDomainResponse.
 //
@@ -13292,8 +13564,9 @@ func (v *DescribeTaskListResponse) IsSetTaskListStatus() bool {
 }
 
 type DescribeWorkflowExecutionRequest struct {
-	Domain    *string            `json:"domain,omitempty"`
-	Execution *WorkflowExecution `json:"execution,omitempty"`
+	Domain             *string            `json:"domain,omitempty"`
+	Execution          *WorkflowExecution `json:"execution,omitempty"`
+	IncludeParentChain *bool              `json:"includeParentChain,omitempty"`
 }
 
 // ToWire translates a DescribeWorkflowExecutionRequest struct into a Thrift-level intermediate
@@ -13313,7 +13586,7 @@ type DescribeWorkflowExecutionRequest struct {
 //   }
 func (v *DescribeWorkflowExecutionRequest) ToWire() (wire.Value, error) {
 	var (
-		fields [2]wire.Field
+		fields [3]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -13335,6 +13608,14 @@ func (v *DescribeWorkflowExecutionRequest) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 20, Value: w}
 		i++
 	}
+	if v.IncludeParentChain != nil {
+		w, err = wire.NewValueBool(*(v.IncludeParentChain)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 30, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -13378,6 +13659,16 @@ func (v *DescribeWorkflowExecutionRequest) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 30:
+			if field.Value.Type() == wire.TBool {
+				var x bool
+				x, err = field.Value.GetBool(), error(nil)
+				v.IncludeParentChain = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -13392,7 +13683,7 @@ func (v *DescribeWorkflowExecutionRequest) String() string {
 		return "<nil>"
 	}
 
-	var fields [2]string
+	var fields [3]string
 	i := 0
 	if v.Domain != nil {
 		fields[i] = fmt.Sprintf("Domain: %v", *(v.Domain))
@@ -13402,6 +13693,10 @@ func (v *DescribeWorkflowExecutionRequest) String() string {
 		fields[i] = fmt.Sprintf("Execution: %v", v.Execution)
 		i++
 	}
+	if v.IncludeParentChain != nil {
+		fields[i] = fmt.Sprintf("IncludeParentChain: %v", *(v.IncludeParentChain))
+		i++
+	}
 
 	return fmt.Sprintf("DescribeWorkflowExecutionRequest{%v}", strings.Join(fields[:i], ", "))
 }
@@ -13422,6 +13717,9 @@ func (v *DescribeWorkflowExecutionRequest) Equals(rhs *DescribeWorkflowExecution
 	if !((v.Execution == nil && rhs.Execution == nil) || (v.Execution != nil && rhs.Execution != nil && v.Execution.Equals(rhs.Execution))) {
 		return false
 	}
+	if !_Bool_EqualsPtr(v.IncludeParentChain, rhs.IncludeParentChain) {
+		return false
+	}
 
 	return true
 }
@@ -13438,6 +13736,9 @@ func (v *DescribeWorkflowExecutionRequest) MarshalLogObject(enc zapcore.ObjectEn
 	if v.Execution != nil {
 		err = multierr.Append(err, enc.AddObject("execution", v.Execution))
 	}
+	if v.IncludeParentChain != nil {
+		enc.AddBool("includeParentChain", *v.IncludeParentChain)
+	}
 	return err
 }
 
@@ -13471,10 +13772,27 @@ func (v *DescribeWorkflowExecutionRequest) IsSetExecution() bool {
 	return v != nil && v.Execution != nil
 }
 
+// GetIncludeParentChain returns the value of IncludeParentChain if it is set or its
+// zero value if it is unset.
+func (v *DescribeWorkflowExecutionRequest) GetIncludeParentChain() (o bool) {
+	if v != nil && v.IncludeParentChain != nil {
+		return *v.IncludeParentChain
+	}
+
+	return
+}
+
+// IsSetIncludeParentChain returns true if IncludeParentChain is not nil.
+func (v *DescribeWorkflowExecutionRequest) IsSetIncludeParentChain() bool {
+	return v != nil && v.IncludeParentChain != nil
+}
+
 type DescribeWorkflowExecutionResponse struct {
 	ExecutionConfiguration *WorkflowExecutionConfiguration `json:"executionConfiguration,omitempty"`
 	WorkflowExecutionInfo  *WorkflowExecutionInfo          `json:"workflowExecutionInfo,omitempty"`
 	PendingActivities      []*PendingActivityInfo          `json:"pendingActivities,omitempty"`
+	ParentChain            []*ParentExecutionInfo          `json:"parentChain,omitempty"`
+	DecisionHeartbeatCount *int32                          `json:"decisionHeartbeatCount,omitempty"`
 }
 
 type _List_PendingActivityInfo_ValueList []*PendingActivityInfo
@@ -13506,6 +13824,35 @@ func (_List_PendingActivityInfo_ValueList) ValueType() wire.Type {
 
 func (_List_PendingActivityInfo_ValueList) Close() {}
 
+type _List_ParentExecutionInfo_ValueList []*ParentExecutionInfo
+
+func (v _List_ParentExecutionInfo_ValueList) ForEach(f func(wire.Value) error) error {
+	for i, x := range v {
+		if x == nil {
+			return fmt.Errorf("invalid [%v]: value is nil", i)
+		}
+		w, err := x.ToWire()
+		if err != nil {
+			return err
+		}
+		err = f(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v _List_ParentExecutionInfo_ValueList) Size() int {
+	return len(v)
+}
+
+func (_List_ParentExecutionInfo_ValueList) ValueType() wire.Type {
+	return wire.TStruct
+}
+
+func (_List_ParentExecutionInfo_ValueList) Close() {}
+
 // ToWire translates a DescribeWorkflowExecutionResponse struct into a Thrift-level intermediate
 // representation. This intermediate representation may be serialized
 // into bytes using a ThriftRW protocol implementation.
@@ -13523,7 +13870,7 @@ func (_List_PendingActivityInfo_ValueList) Close() {}
 //   }
 func (v *DescribeWorkflowExecutionResponse) ToWire() (wire.Value, error) {
 	var (
-		fields [3]wire.Field
+		fields [5]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -13553,6 +13900,22 @@ func (v *DescribeWorkflowExecutionResponse) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 30, Value: w}
 		i++
 	}
+	if v.ParentChain != nil {
+		w, err = wire.NewValueList(_List_ParentExecutionInfo_ValueList(v.ParentChain)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 40, Value: w}
+		i++
+	}
+	if v.DecisionHeartbeatCount != nil {
+		w, err = wire.NewValueI32(*(v.DecisionHeartbeatCount)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 50, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -13593,6 +13956,30 @@ func _List_PendingActivityInfo_Read(l wire.ValueList) ([]*PendingActivityInfo, e
 	return o, err
 }
 
+func _ParentExecutionInfo_Read(w wire.Value) (*ParentExecutionInfo, error) {
+	var v ParentExecutionInfo
+	err := v.FromWire(w)
+	return &v, err
+}
+
+func _List_ParentExecutionInfo_Read(l wire.ValueList) ([]*ParentExecutionInfo, error) {
+	if l.ValueType() != wire.TStruct {
+		return nil, nil
+	}
+
+	o := make([]*ParentExecutionInfo, 0, l.Size())
+	err := l.ForEach(func(x wire.Value) error {
+		i, err := _ParentExecutionInfo_Read(x)
+		if err != nil {
+			return err
+		}
+		o = append(o, i)
+		return nil
+	})
+	l.Close()
+	return o, err
+}
+
 // FromWire deserializes a DescribeWorkflowExecutionResponse struct from its Thrift-level
 // representation. The Thrift-level representation may be obtained
 // from a ThriftRW protocol implementation.
@@ -13638,6 +14025,24 @@ func (v *DescribeWorkflowExecutionResponse) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 40:
+			if field.Value.Type() == wire.TList {
+				v.ParentChain, err = _List_ParentExecutionInfo_Read(field.Value.GetList())
+				if err != nil {
+					return err
+				}
+
+			}
+		case 50:
+			if field.Value.Type() == wire.TI32 {
+				var x int32
+				x, err = field.Value.GetI32(), error(nil)
+				v.DecisionHeartbeatCount = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -13652,7 +14057,7 @@ func (v *DescribeWorkflowExecutionResponse) String() string {
 		return "<nil>"
 	}
 
-	var fields [3]string
+	var fields [5]string
 	i := 0
 	if v.ExecutionConfiguration != nil {
 		fields[i] = fmt.Sprintf("ExecutionConfiguration: %v", v.ExecutionConfiguration)
@@ -13666,6 +14071,14 @@ func (v *DescribeWorkflowExecutionResponse) String() string {
 		fields[i] = fmt.Sprintf("PendingActivities: %v", v.PendingActivities)
 		i++
 	}
+	if v.ParentChain != nil {
+		fields[i] = fmt.Sprintf("ParentChain: %v", v.ParentChain)
+		i++
+	}
+	if v.DecisionHeartbeatCount != nil {
+		fields[i] = fmt.Sprintf("DecisionHeartbeatCount: %v", *(v.DecisionHeartbeatCount))
+		i++
+	}
 
 	return fmt.Sprintf("DescribeWorkflowExecutionResponse{%v}", strings.Join(fields[:i], ", "))
 }
@@ -13685,6 +14098,21 @@ func _List_PendingActivityInfo_Equals(lhs, rhs []*PendingActivityInfo) bool {
 	return true
 }
 
+func _List_ParentExecutionInfo_Equals(lhs, rhs []*ParentExecutionInfo) bool {
+	if len(lhs) != len(rhs) {
+		return false
+	}
+
+	for i, lv := range lhs {
+		rv := rhs[i]
+		if !lv.Equals(rv) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Equals returns true if all the fields of this DescribeWorkflowExecutionResponse match the
 // provided DescribeWorkflowExecutionResponse.
 //
@@ -13704,6 +14132,12 @@ func (v *DescribeWorkflowExecutionResponse) Equals(rhs *DescribeWorkflowExecutio
 	if !((v.PendingActivities == nil && rhs.PendingActivities == nil) || (v.PendingActivities != nil && rhs.PendingActivities != nil && _List_PendingActivityInfo_Equals(v.PendingActivities, rhs.PendingActivities))) {
 		return false
 	}
+	if !((v.ParentChain == nil && rhs.ParentChain == nil) || (v.ParentChain != nil && rhs.ParentChain != nil && _List_ParentExecutionInfo_Equals(v.ParentChain, rhs.ParentChain))) {
+		return false
+	}
+	if !_I32_EqualsPtr(v.DecisionHeartbeatCount, rhs.DecisionHeartbeatCount) {
+		return false
+	}
 
 	return true
 }
@@ -13719,6 +14153,17 @@ func (l _List_PendingActivityInfo_Zapper) MarshalLogArray(enc zapcore.ArrayEncod
 	return err
 }
 
+type _List_ParentExecutionInfo_Zapper []*ParentExecutionInfo
+
+// MarshalLogArray implements zapcore.ArrayMarshaler, enabling
+// fast logging of _List_ParentExecutionInfo_Zapper.
+func (l _List_ParentExecutionInfo_Zapper) MarshalLogArray(enc zapcore.ArrayEncoder) (err error) {
+	for _, v := range l {
+		err = multierr.Append(err, enc.AppendObject(v))
+	}
+	return err
+}
+
 // MarshalLogObject implements zapcore.ObjectMarshaler, enabling
 // fast logging of DescribeWorkflowExecutionResponse.
 func (v *DescribeWorkflowExecutionResponse) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
@@ -13734,6 +14179,12 @@ func (v *DescribeWorkflowExecutionResponse) MarshalLogObject(enc zapcore.ObjectE
 	if v.PendingActivities != nil {
 		err = multierr.Append(err, enc.AddArray("pendingActivities", (_List_PendingActivityInfo_Zapper)(v.PendingActivities)))
 	}
+	if v.ParentChain != nil {
+		err = multierr.Append(err, enc.AddArray("parentChain", (_List_ParentExecutionInfo_Zapper)(v.ParentChain)))
+	}
+	if v.DecisionHeartbeatCount != nil {
+		enc.AddInt32("decisionHeartbeatCount", *v.DecisionHeartbeatCount)
+	}
 	return err
 }
 
@@ -13782,6 +14233,36 @@ func (v *DescribeWorkflowExecutionResponse) IsSetPendingActivities() bool {
 	return v != nil && v.PendingActivities != nil
 }
 
+// GetParentChain returns the value of ParentChain if it is set or its
+// zero value if it is unset.
+func (v *DescribeWorkflowExecutionResponse) GetParentChain() (o []*ParentExecutionInfo) {
+	if v != nil && v.ParentChain != nil {
+		return v.ParentChain
+	}
+
+	return
+}
+
+// IsSetParentChain returns true if ParentChain is not nil.
+func (v *DescribeWorkflowExecutionResponse) IsSetParentChain() bool {
+	return v != nil && v.ParentChain != nil
+}
+
+// GetDecisionHeartbeatCount returns the value of DecisionHeartbeatCount if it is set or its
+// zero value if it is unset.
+func (v *DescribeWorkflowExecutionResponse) GetDecisionHeartbeatCount() (o int32) {
+	if v != nil && v.DecisionHeartbeatCount != nil {
+		return *v.DecisionHeartbeatCount
+	}
+
+	return
+}
+
+// IsSetDecisionHeartbeatCount returns true if DecisionHeartbeatCount is not nil.
+func (v *DescribeWorkflowExecutionResponse) IsSetDecisionHeartbeatCount() bool {
+	return v != nil && v.DecisionHeartbeatCount != nil
+}
+
 type DomainAlreadyExistsError struct {
 	Message string `json:"message,required"`
 }
@@ -16004,6 +16485,8 @@ const (
 	EventTypeSignalExternalWorkflowExecutionInitiated        EventType = 38
 	EventTypeSignalExternalWorkflowExecutionFailed           EventType = 39
 	EventTypeExternalWorkflowExecutionSignaled               EventType = 40
+	EventTypeUpsertWorkflowMemo                              EventType = 41
+	EventTypeWorkflowExecutionTimeoutExtended                EventType = 42
 )
 
 // EventType_Values returns all recognized values of EventType.
@@ -16050,6 +16533,8 @@ func EventType_Values() []EventType {
 		EventTypeSignalExternalWorkflowExecutionInitiated,
 		EventTypeSignalExternalWorkflowExecutionFailed,
 		EventTypeExternalWorkflowExecutionSignaled,
+		EventTypeUpsertWorkflowMemo,
+		EventTypeWorkflowExecutionTimeoutExtended,
 	}
 }
 
@@ -16183,6 +16668,12 @@ func (v *EventType) UnmarshalText(value []byte) error {
 	case "ExternalWorkflowExecutionSignaled":
 		*v = EventTypeExternalWorkflowExecutionSignaled
 		return nil
+	case "UpsertWorkflowMemo":
+		*v = EventTypeUpsertWorkflowMemo
+		return nil
+	case "WorkflowExecutionTimeoutExtended":
+		*v = EventTypeWorkflowExecutionTimeoutExtended
+		return nil
 	default:
 		val, err := strconv.ParseInt(s, 10, 32)
 		if err != nil {
@@ -16283,6 +16774,10 @@ func (v EventType) MarshalText() ([]byte, error) {
 		return []byte("SignalExternalWorkflowExecutionFailed"), nil
 	case 40:
 		return []byte("ExternalWorkflowExecutionSignaled"), nil
+	case 41:
+		return []byte("UpsertWorkflowMemo"), nil
+	case 42:
+		return []byte("WorkflowExecutionTimeoutExtended"), nil
 	}
 	return []byte(strconv.FormatInt(int64(v), 10)), nil
 }
@@ -16376,6 +16871,10 @@ func (v EventType) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 		enc.AddString("name", "SignalExternalWorkflowExecutionFailed")
 	case 40:
 		enc.AddString("name", "ExternalWorkflowExecutionSignaled")
+	case 41:
+		enc.AddString("name", "UpsertWorkflowMemo")
+	case 42:
+		enc.AddString("name", "WorkflowExecutionTimeoutExtended")
 	}
 	return nil
 }
@@ -16498,6 +16997,10 @@ func (v EventType) String() string {
 		return "SignalExternalWorkflowExecutionFailed"
 	case 40:
 		return "ExternalWorkflowExecutionSignaled"
+	case 41:
+		return "UpsertWorkflowMemo"
+	case 42:
+		return "WorkflowExecutionTimeoutExtended"
 	}
 	return fmt.Sprintf("EventType(%d)", w)
 }
@@ -16598,6 +17101,10 @@ func (v EventType) MarshalJSON() ([]byte, error) {
 		return ([]byte)("\"SignalExternalWorkflowExecutionFailed\""), nil
 	case 40:
 		return ([]byte)("\"ExternalWorkflowExecutionSignaled\""), nil
+	case 41:
+		return ([]byte)("\"UpsertWorkflowMemo\""), nil
+	case 42:
+		return ([]byte)("\"WorkflowExecutionTimeoutExtended\""), nil
 	}
 	return ([]byte)(strconv.FormatInt(int64(v), 10)), nil
 }
@@ -17308,6 +17815,502 @@ func (v *FailWorkflowExecutionDecisionAttributes) IsSetDetails() bool {
 	return v != nil && v.Details != nil
 }
 
+type GetDomainWorkflowStateCountsRequest struct {
+	Domain *string `json:"domain,omitempty"`
+}
+
+// ToWire translates a GetDomainWorkflowStateCountsRequest struct into a Thrift-level intermediate
+// representation. This intermediate representation may be serialized
+// into bytes using a ThriftRW protocol implementation.
+//
+// An error is returned if the struct or any of its fields failed to
+// validate.
+//
+//   x, err := v.ToWire()
+//   if err != nil {
+//     return err
+//   }
+//
+//   if err := binaryProtocol.Encode(x, writer); err != nil {
+//     return err
+//   }
+func (v *GetDomainWorkflowStateCountsRequest) ToWire() (wire.Value, error) {
+	var (
+		fields [1]wire.Field
+		i      int = 0
+		w      wire.Value
+		err    error
+	)
+
+	if v.Domain != nil {
+		w, err = wire.NewValueString(*(v.Domain)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 10, Value: w}
+		i++
+	}
+
+	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
+}
+
+// FromWire deserializes a GetDomainWorkflowStateCountsRequest struct from its Thrift-level
+// representation. The Thrift-level representation may be obtained
+// from a ThriftRW protocol implementation.
+//
+// An error is returned if we were unable to build a GetDomainWorkflowStateCountsRequest struct
+// from the provided intermediate representation.
+//
+//   x, err := binaryProtocol.Decode(reader, wire.TStruct)
+//   if err != nil {
+//     return nil, err
+//   }
+//
+//   var v GetDomainWorkflowStateCountsRequest
+//   if err := v.FromWire(x); err != nil {
+//     return nil, err
+//   }
+//   return &v, nil
+func (v *GetDomainWorkflowStateCountsRequest) FromWire(w wire.Value) error {
+	var err error
+
+	for _, field := range w.GetStruct().Fields {
+		switch field.ID {
+		case 10:
+			if field.Value.Type() == wire.TBinary {
+				var x string
+				x, err = field.Value.GetString(), error(nil)
+				v.Domain = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// String returns a readable string representation of a GetDomainWorkflowStateCountsRequest
+// struct.
+func (v *GetDomainWorkflowStateCountsRequest) String() string {
+	if v == nil {
+		return "<nil>"
+	}
+
+	var fields [1]string
+	i := 0
+	if v.Domain != nil {
+		fields[i] = fmt.Sprintf("Domain: %v", *(v.Domain))
+		i++
+	}
+
+	return fmt.Sprintf("GetDomainWorkflowStateCountsRequest{%v}", strings.Join(fields[:i], ", "))
+}
+
+// Equals returns true if all the fields of this GetDomainWorkflowStateCountsRequest match the
+// provided GetDomainWorkflowStateCountsRequest.
+//
+// This function performs a deep comparison.
+func (v *GetDomainWorkflowStateCountsRequest) Equals(rhs *GetDomainWorkflowStateCountsRequest) bool {
+	if v == nil {
+		return rhs == nil
+	} else if rhs == nil {
+		return false
+	}
+	if !_String_EqualsPtr(v.Domain, rhs.Domain) {
+		return false
+	}
+
+	return true
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler, enabling
+// fast logging of GetDomainWorkflowStateCountsRequest.
+func (v *GetDomainWorkflowStateCountsRequest) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
+	if v == nil {
+		return nil
+	}
+	if v.Domain != nil {
+		enc.AddString("domain", *v.Domain)
+	}
+	return err
+}
+
+// GetDomain returns the value of Domain if it is set or its
+// zero value if it is unset.
+func (v *GetDomainWorkflowStateCountsRequest) GetDomain() (o string) {
+	if v != nil && v.Domain != nil {
+		return *v.Domain
+	}
+
+	return
+}
+
+// IsSetDomain returns true if Domain is not nil.
+func (v *GetDomainWorkflowStateCountsRequest) IsSetDomain() bool {
+	return v != nil && v.Domain != nil
+}
+
+type GetDomainWorkflowStateCountsResponse struct {
+	Running    *int64 `json:"running,omitempty"`
+	Completed  *int64 `json:"completed,omitempty"`
+	Failed     *int64 `json:"failed,omitempty"`
+	TimedOut   *int64 `json:"timedOut,omitempty"`
+	Terminated *int64 `json:"terminated,omitempty"`
+	Canceled   *int64 `json:"canceled,omitempty"`
+}
+
+// ToWire translates a GetDomainWorkflowStateCountsResponse struct into a Thrift-level intermediate
+// representation. This intermediate representation may be serialized
+// into bytes using a ThriftRW protocol implementation.
+//
+// An error is returned if the struct or any of its fields failed to
+// validate.
+//
+//   x, err := v.ToWire()
+//   if err != nil {
+//     return err
+//   }
+//
+//   if err := binaryProtocol.Encode(x, writer); err != nil {
+//     return err
+//   }
+func (v *GetDomainWorkflowStateCountsResponse) ToWire() (wire.Value, error) {
+	var (
+		fields [6]wire.Field
+		i      int = 0
+		w      wire.Value
+		err    error
+	)
+
+	if v.Running != nil {
+		w, err = wire.NewValueI64(*(v.Running)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 10, Value: w}
+		i++
+	}
+	if v.Completed != nil {
+		w, err = wire.NewValueI64(*(v.Completed)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 20, Value: w}
+		i++
+	}
+	if v.Failed != nil {
+		w, err = wire.NewValueI64(*(v.Failed)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 30, Value: w}
+		i++
+	}
+	if v.TimedOut != nil {
+		w, err = wire.NewValueI64(*(v.TimedOut)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 40, Value: w}
+		i++
+	}
+	if v.Terminated != nil {
+		w, err = wire.NewValueI64(*(v.Terminated)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 50, Value: w}
+		i++
+	}
+	if v.Canceled != nil {
+		w, err = wire.NewValueI64(*(v.Canceled)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 60, Value: w}
+		i++
+	}
+
+	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
+}
+
+// FromWire deserializes a GetDomainWorkflowStateCountsResponse struct from its Thrift-level
+// representation. The Thrift-level representation may be obtained
+// from a ThriftRW protocol implementation.
+//
+// An error is returned if we were unable to build a GetDomainWorkflowStateCountsResponse struct
+// from the provided intermediate representation.
+//
+//   x, err := binaryProtocol.Decode(reader, wire.TStruct)
+//   if err != nil {
+//     return nil, err
+//   }
+//
+//   var v GetDomainWorkflowStateCountsResponse
+//   if err := v.FromWire(x); err != nil {
+//     return nil, err
+//   }
+//   return &v, nil
+func (v *GetDomainWorkflowStateCountsResponse) FromWire(w wire.Value) error {
+	var err error
+
+	for _, field := range w.GetStruct().Fields {
+		switch field.ID {
+		case 10:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.Running = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 20:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.Completed = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 30:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.Failed = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 40:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.TimedOut = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 50:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.Terminated = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 60:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.Canceled = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// String returns a readable string representation of a GetDomainWorkflowStateCountsResponse
+// struct.
+func (v *GetDomainWorkflowStateCountsResponse) String() string {
+	if v == nil {
+		return "<nil>"
+	}
+
+	var fields [6]string
+	i := 0
+	if v.Running != nil {
+		fields[i] = fmt.Sprintf("Running: %v", *(v.Running))
+		i++
+	}
+	if v.Completed != nil {
+		fields[i] = fmt.Sprintf("Completed: %v", *(v.Completed))
+		i++
+	}
+	if v.Failed != nil {
+		fields[i] = fmt.Sprintf("Failed: %v", *(v.Failed))
+		i++
+	}
+	if v.TimedOut != nil {
+		fields[i] = fmt.Sprintf("TimedOut: %v", *(v.TimedOut))
+		i++
+	}
+	if v.Terminated != nil {
+		fields[i] = fmt.Sprintf("Terminated: %v", *(v.Terminated))
+		i++
+	}
+	if v.Canceled != nil {
+		fields[i] = fmt.Sprintf("Canceled: %v", *(v.Canceled))
+		i++
+	}
+
+	return fmt.Sprintf("GetDomainWorkflowStateCountsResponse{%v}", strings.Join(fields[:i], ", "))
+}
+
+// Equals returns true if all the fields of this GetDomainWorkflowStateCountsResponse match the
+// provided GetDomainWorkflowStateCountsResponse.
+//
+// This function performs a deep comparison.
+func (v *GetDomainWorkflowStateCountsResponse) Equals(rhs *GetDomainWorkflowStateCountsResponse) bool {
+	if v == nil {
+		return rhs == nil
+	} else if rhs == nil {
+		return false
+	}
+	if !_I64_EqualsPtr(v.Running, rhs.Running) {
+		return false
+	}
+	if !_I64_EqualsPtr(v.Completed, rhs.Completed) {
+		return false
+	}
+	if !_I64_EqualsPtr(v.Failed, rhs.Failed) {
+		return false
+	}
+	if !_I64_EqualsPtr(v.TimedOut, rhs.TimedOut) {
+		return false
+	}
+	if !_I64_EqualsPtr(v.Terminated, rhs.Terminated) {
+		return false
+	}
+	if !_I64_EqualsPtr(v.Canceled, rhs.Canceled) {
+		return false
+	}
+
+	return true
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler, enabling
+// fast logging of GetDomainWorkflowStateCountsResponse.
+func (v *GetDomainWorkflowStateCountsResponse) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
+	if v == nil {
+		return nil
+	}
+	if v.Running != nil {
+		enc.AddInt64("running", *v.Running)
+	}
+	if v.Completed != nil {
+		enc.AddInt64("completed", *v.Completed)
+	}
+	if v.Failed != nil {
+		enc.AddInt64("failed", *v.Failed)
+	}
+	if v.TimedOut != nil {
+		enc.AddInt64("timedOut", *v.TimedOut)
+	}
+	if v.Terminated != nil {
+		enc.AddInt64("terminated", *v.Terminated)
+	}
+	if v.Canceled != nil {
+		enc.AddInt64("canceled", *v.Canceled)
+	}
+	return err
+}
+
+// GetRunning returns the value of Running if it is set or its
+// zero value if it is unset.
+func (v *GetDomainWorkflowStateCountsResponse) GetRunning() (o int64) {
+	if v != nil && v.Running != nil {
+		return *v.Running
+	}
+
+	return
+}
+
+// IsSetRunning returns true if Running is not nil.
+func (v *GetDomainWorkflowStateCountsResponse) IsSetRunning() bool {
+	return v != nil && v.Running != nil
+}
+
+// GetCompleted returns the value of Completed if it is set or its
+// zero value if it is unset.
+func (v *GetDomainWorkflowStateCountsResponse) GetCompleted() (o int64) {
+	if v != nil && v.Completed != nil {
+		return *v.Completed
+	}
+
+	return
+}
+
+// IsSetCompleted returns true if Completed is not nil.
+func (v *GetDomainWorkflowStateCountsResponse) IsSetCompleted() bool {
+	return v != nil && v.Completed != nil
+}
+
+// GetFailed returns the value of Failed if it is set or its
+// zero value if it is unset.
+func (v *GetDomainWorkflowStateCountsResponse) GetFailed() (o int64) {
+	if v != nil && v.Failed != nil {
+		return *v.Failed
+	}
+
+	return
+}
+
+// IsSetFailed returns true if Failed is not nil.
+func (v *GetDomainWorkflowStateCountsResponse) IsSetFailed() bool {
+	return v != nil && v.Failed != nil
+}
+
+// GetTimedOut returns the value of TimedOut if it is set or its
+// zero value if it is unset.
+func (v *GetDomainWorkflowStateCountsResponse) GetTimedOut() (o int64) {
+	if v != nil && v.TimedOut != nil {
+		return *v.TimedOut
+	}
+
+	return
+}
+
+// IsSetTimedOut returns true if TimedOut is not nil.
+func (v *GetDomainWorkflowStateCountsResponse) IsSetTimedOut() bool {
+	return v != nil && v.TimedOut != nil
+}
+
+// GetTerminated returns the value of Terminated if it is set or its
+// zero value if it is unset.
+func (v *GetDomainWorkflowStateCountsResponse) GetTerminated() (o int64) {
+	if v != nil && v.Terminated != nil {
+		return *v.Terminated
+	}
+
+	return
+}
+
+// IsSetTerminated returns true if Terminated is not nil.
+func (v *GetDomainWorkflowStateCountsResponse) IsSetTerminated() bool {
+	return v != nil && v.Terminated != nil
+}
+
+// GetCanceled returns the value of Canceled if it is set or its
+// zero value if it is unset.
+func (v *GetDomainWorkflowStateCountsResponse) GetCanceled() (o int64) {
+	if v != nil && v.Canceled != nil {
+		return *v.Canceled
+	}
+
+	return
+}
+
+// IsSetCanceled returns true if Canceled is not nil.
+func (v *GetDomainWorkflowStateCountsResponse) IsSetCanceled() bool {
+	return v != nil && v.Canceled != nil
+}
+
 type GetSearchAttributesResponse struct {
 	Keys map[string]IndexedValueType `json:"keys,omitempty"`
 }
@@ -19160,6 +20163,8 @@ type HistoryEvent struct {
 	SignalExternalWorkflowExecutionInitiatedEventAttributes        *SignalExternalWorkflowExecutionInitiatedEventAttributes        `json:"signalExternalWorkflowExecutionInitiatedEventAttributes,omitempty"`
 	SignalExternalWorkflowExecutionFailedEventAttributes           *SignalExternalWorkflowExecutionFailedEventAttributes           `json:"signalExternalWorkflowExecutionFailedEventAttributes,omitempty"`
 	ExternalWorkflowExecutionSignaledEventAttributes               *ExternalWorkflowExecutionSignaledEventAttributes               `json:"externalWorkflowExecutionSignaledEventAttributes,omitempty"`
+	UpsertWorkflowMemoEventAttributes                              *UpsertWorkflowMemoEventAttributes                              `json:"upsertWorkflowMemoEventAttributes,omitempty"`
+	WorkflowExecutionTimeoutExtendedEventAttributes                *WorkflowExecutionTimeoutExtendedEventAttributes                `json:"workflowExecutionTimeoutExtendedEventAttributes,omitempty"`
 }
 
 // ToWire translates a HistoryEvent struct into a Thrift-level intermediate
@@ -19179,7 +20184,7 @@ type HistoryEvent struct {
 //   }
 func (v *HistoryEvent) ToWire() (wire.Value, error) {
 	var (
-		fields [46]wire.Field
+		fields [48]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -19553,6 +20558,22 @@ func (v *HistoryEvent) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 440, Value: w}
 		i++
 	}
+	if v.UpsertWorkflowMemoEventAttributes != nil {
+		w, err = v.UpsertWorkflowMemoEventAttributes.ToWire()
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 450, Value: w}
+		i++
+	}
+	if v.WorkflowExecutionTimeoutExtendedEventAttributes != nil {
+		w, err = v.WorkflowExecutionTimeoutExtendedEventAttributes.ToWire()
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 460, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -19809,6 +20830,18 @@ func _ExternalWorkflowExecutionSignaledEventAttributes_Read(w wire.Value) (*Exte
 	return &v, err
 }
 
+func _UpsertWorkflowMemoEventAttributes_Read(w wire.Value) (*UpsertWorkflowMemoEventAttributes, error) {
+	var v UpsertWorkflowMemoEventAttributes
+	err := v.FromWire(w)
+	return &v, err
+}
+
+func _WorkflowExecutionTimeoutExtendedEventAttributes_Read(w wire.Value) (*WorkflowExecutionTimeoutExtendedEventAttributes, error) {
+	var v WorkflowExecutionTimeoutExtendedEventAttributes
+	err := v.FromWire(w)
+	return &v, err
+}
+
 // FromWire deserializes a HistoryEvent struct from its Thrift-level
 // representation. The Thrift-level representation may be obtained
 // from a ThriftRW protocol implementation.
@@ -20208,6 +21241,22 @@ func (v *HistoryEvent) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 450:
+			if field.Value.Type() == wire.TStruct {
+				v.UpsertWorkflowMemoEventAttributes, err = _UpsertWorkflowMemoEventAttributes_Read(field.Value)
+				if err != nil {
+					return err
+				}
+
+			}
+		case 460:
+			if field.Value.Type() == wire.TStruct {
+				v.WorkflowExecutionTimeoutExtendedEventAttributes, err = _WorkflowExecutionTimeoutExtendedEventAttributes_Read(field.Value)
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -20222,7 +21271,7 @@ func (v *HistoryEvent) String() string {
 		return "<nil>"
 	}
 
-	var fields [46]string
+	var fields [48]string
 	i := 0
 	if v.EventId != nil {
 		fields[i] = fmt.Sprintf("EventId: %v", *(v.EventId))
@@ -20408,6 +21457,14 @@ func (v *HistoryEvent) String() string {
 		fields[i] = fmt.Sprintf("ExternalWorkflowExecutionSignaledEventAttributes: %v", v.ExternalWorkflowExecutionSignaledEventAttributes)
 		i++
 	}
+	if v.UpsertWorkflowMemoEventAttributes != nil {
+		fields[i] = fmt.Sprintf("UpsertWorkflowMemoEventAttributes: %v", v.UpsertWorkflowMemoEventAttributes)
+		i++
+	}
+	if v.WorkflowExecutionTimeoutExtendedEventAttributes != nil {
+		fields[i] = fmt.Sprintf("WorkflowExecutionTimeoutExtendedEventAttributes: %v", v.WorkflowExecutionTimeoutExtendedEventAttributes)
+		i++
+	}
 
 	return fmt.Sprintf("HistoryEvent{%v}", strings.Join(fields[:i], ", "))
 }
@@ -20570,6 +21627,12 @@ func (v *HistoryEvent) Equals(rhs *HistoryEvent) bool {
 	if !((v.ExternalWorkflowExecutionSignaledEventAttributes == nil && rhs.ExternalWorkflowExecutionSignaledEventAttributes == nil) || (v.ExternalWorkflowExecutionSignaledEventAttributes != nil && rhs.ExternalWorkflowExecutionSignaledEventAttributes != nil && v.ExternalWorkflowExecutionSignaledEventAttributes.Equals(rhs.ExternalWorkflowExecutionSignaledEventAttributes))) {
 		return false
 	}
+	if !((v.UpsertWorkflowMemoEventAttributes == nil && rhs.UpsertWorkflowMemoEventAttributes == nil) || (v.UpsertWorkflowMemoEventAttributes != nil && rhs.UpsertWorkflowMemoEventAttributes != nil && v.UpsertWorkflowMemoEventAttributes.Equals(rhs.UpsertWorkflowMemoEventAttributes))) {
+		return false
+	}
+	if !((v.WorkflowExecutionTimeoutExtendedEventAttributes == nil && rhs.WorkflowExecutionTimeoutExtendedEventAttributes == nil) || (v.WorkflowExecutionTimeoutExtendedEventAttributes != nil && rhs.WorkflowExecutionTimeoutExtendedEventAttributes != nil && v.WorkflowExecutionTimeoutExtendedEventAttributes.Equals(rhs.WorkflowExecutionTimeoutExtendedEventAttributes))) {
+		return false
+	}
 
 	return true
 }
@@ -20718,6 +21781,12 @@ func (v *HistoryEvent) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
 	if v.ExternalWorkflowExecutionSignaledEventAttributes != nil {
 		err = multierr.Append(err, enc.AddObject("externalWorkflowExecutionSignaledEventAttributes", v.ExternalWorkflowExecutionSignaledEventAttributes))
 	}
+	if v.UpsertWorkflowMemoEventAttributes != nil {
+		err = multierr.Append(err, enc.AddObject("upsertWorkflowMemoEventAttributes", v.UpsertWorkflowMemoEventAttributes))
+	}
+	if v.WorkflowExecutionTimeoutExtendedEventAttributes != nil {
+		err = multierr.Append(err, enc.AddObject("workflowExecutionTimeoutExtendedEventAttributes", v.WorkflowExecutionTimeoutExtendedEventAttributes))
+	}
 	return err
 }
 
@@ -21411,6 +22480,36 @@ func (v *HistoryEvent) IsSetExternalWorkflowExecutionSignaledEventAttributes() b
 	return v != nil && v.ExternalWorkflowExecutionSignaledEventAttributes != nil
 }
 
+// GetUpsertWorkflowMemoEventAttributes returns the value of UpsertWorkflowMemoEventAttributes if it is set or its
+// zero value if it is unset.
+func (v *HistoryEvent) GetUpsertWorkflowMemoEventAttributes() (o *UpsertWorkflowMemoEventAttributes) {
+	if v != nil && v.UpsertWorkflowMemoEventAttributes != nil {
+		return v.UpsertWorkflowMemoEventAttributes
+	}
+
+	return
+}
+
+// IsSetUpsertWorkflowMemoEventAttributes returns true if UpsertWorkflowMemoEventAttributes is not nil.
+func (v *HistoryEvent) IsSetUpsertWorkflowMemoEventAttributes() bool {
+	return v != nil && v.UpsertWorkflowMemoEventAttributes != nil
+}
+
+// GetWorkflowExecutionTimeoutExtendedEventAttributes returns the value of WorkflowExecutionTimeoutExtendedEventAttributes if it is set or its
+// zero value if it is unset.
+func (v *HistoryEvent) GetWorkflowExecutionTimeoutExtendedEventAttributes() (o *WorkflowExecutionTimeoutExtendedEventAttributes) {
+	if v != nil && v.WorkflowExecutionTimeoutExtendedEventAttributes != nil {
+		return v.WorkflowExecutionTimeoutExtendedEventAttributes
+	}
+
+	return
+}
+
+// IsSetWorkflowExecutionTimeoutExtendedEventAttributes returns true if WorkflowExecutionTimeoutExtendedEventAttributes is not nil.
+func (v *HistoryEvent) IsSetWorkflowExecutionTimeoutExtendedEventAttributes() bool {
+	return v != nil && v.WorkflowExecutionTimeoutExtendedEventAttributes != nil
+}
+
 type HistoryEventFilterType int32
 
 const (
@@ -24565,6 +25664,230 @@ func (v *Memo) IsSetFields() bool {
 	return v != nil && v.Fields != nil
 }
 
+type ParentExecutionInfo struct {
+	DomainUUID *string            `json:"domainUUID,omitempty"`
+	Domain     *string            `json:"domain,omitempty"`
+	Execution  *WorkflowExecution `json:"execution,omitempty"`
+}
+
+// ToWire translates a ParentExecutionInfo struct into a Thrift-level intermediate
+// representation. This intermediate representation may be serialized
+// into bytes using a ThriftRW protocol implementation.
+//
+// An error is returned if the struct or any of its fields failed to
+// validate.
+//
+//   x, err := v.ToWire()
+//   if err != nil {
+//     return err
+//   }
+//
+//   if err := binaryProtocol.Encode(x, writer); err != nil {
+//     return err
+//   }
+func (v *ParentExecutionInfo) ToWire() (wire.Value, error) {
+	var (
+		fields [3]wire.Field
+		i      int = 0
+		w      wire.Value
+		err    error
+	)
+
+	if v.DomainUUID != nil {
+		w, err = wire.NewValueString(*(v.DomainUUID)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 10, Value: w}
+		i++
+	}
+	if v.Domain != nil {
+		w, err = wire.NewValueString(*(v.Domain)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 20, Value: w}
+		i++
+	}
+	if v.Execution != nil {
+		w, err = v.Execution.ToWire()
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 30, Value: w}
+		i++
+	}
+
+	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
+}
+
+// FromWire deserializes a ParentExecutionInfo struct from its Thrift-level
+// representation. The Thrift-level representation may be obtained
+// from a ThriftRW protocol implementation.
+//
+// An error is returned if we were unable to build a ParentExecutionInfo struct
+// from the provided intermediate representation.
+//
+//   x, err := binaryProtocol.Decode(reader, wire.TStruct)
+//   if err != nil {
+//     return nil, err
+//   }
+//
+//   var v ParentExecutionInfo
+//   if err := v.FromWire(x); err != nil {
+//     return nil, err
+//   }
+//   return &v, nil
+func (v *ParentExecutionInfo) FromWire(w wire.Value) error {
+	var err error
+
+	for _, field := range w.GetStruct().Fields {
+		switch field.ID {
+		case 10:
+			if field.Value.Type() == wire.TBinary {
+				var x string
+				x, err = field.Value.GetString(), error(nil)
+				v.DomainUUID = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 20:
+			if field.Value.Type() == wire.TBinary {
+				var x string
+				x, err = field.Value.GetString(), error(nil)
+				v.Domain = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 30:
+			if field.Value.Type() == wire.TStruct {
+				v.Execution, err = _WorkflowExecution_Read(field.Value)
+				if err != nil {
+					return err
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// String returns a readable string representation of a ParentExecutionInfo
+// struct.
+func (v *ParentExecutionInfo) String() string {
+	if v == nil {
+		return "<nil>"
+	}
+
+	var fields [3]string
+	i := 0
+	if v.DomainUUID != nil {
+		fields[i] = fmt.Sprintf("DomainUUID: %v", *(v.DomainUUID))
+		i++
+	}
+	if v.Domain != nil {
+		fields[i] = fmt.Sprintf("Domain: %v", *(v.Domain))
+		i++
+	}
+	if v.Execution != nil {
+		fields[i] = fmt.Sprintf("Execution: %v", v.Execution)
+		i++
+	}
+
+	return fmt.Sprintf("ParentExecutionInfo{%v}", strings.Join(fields[:i], ", "))
+}
+
+// Equals returns true if all the fields of this ParentExecutionInfo match the
+// provided ParentExecutionInfo.
+//
+// This function performs a deep comparison.
+func (v *ParentExecutionInfo) Equals(rhs *ParentExecutionInfo) bool {
+	if v == nil {
+		return rhs == nil
+	} else if rhs == nil {
+		return false
+	}
+	if !_String_EqualsPtr(v.DomainUUID, rhs.DomainUUID) {
+		return false
+	}
+	if !_String_EqualsPtr(v.Domain, rhs.Domain) {
+		return false
+	}
+	if !((v.Execution == nil && rhs.Execution == nil) || (v.Execution != nil && rhs.Execution != nil && v.Execution.Equals(rhs.Execution))) {
+		return false
+	}
+
+	return true
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler, enabling
+// fast logging of ParentExecutionInfo.
+func (v *ParentExecutionInfo) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
+	if v == nil {
+		return nil
+	}
+	if v.DomainUUID != nil {
+		enc.AddString("domainUUID", *v.DomainUUID)
+	}
+	if v.Domain != nil {
+		enc.AddString("domain", *v.Domain)
+	}
+	if v.Execution != nil {
+		err = multierr.Append(err, enc.AddObject("execution", v.Execution))
+	}
+	return err
+}
+
+// GetDomainUUID returns the value of DomainUUID if it is set or its
+// zero value if it is unset.
+func (v *ParentExecutionInfo) GetDomainUUID() (o string) {
+	if v != nil && v.DomainUUID != nil {
+		return *v.DomainUUID
+	}
+
+	return
+}
+
+// IsSetDomainUUID returns true if DomainUUID is not nil.
+func (v *ParentExecutionInfo) IsSetDomainUUID() bool {
+	return v != nil && v.DomainUUID != nil
+}
+
+// GetDomain returns the value of Domain if it is set or its
+// zero value if it is unset.
+func (v *ParentExecutionInfo) GetDomain() (o string) {
+	if v != nil && v.Domain != nil {
+		return *v.Domain
+	}
+
+	return
+}
+
+// IsSetDomain returns true if Domain is not nil.
+func (v *ParentExecutionInfo) IsSetDomain() bool {
+	return v != nil && v.Domain != nil
+}
+
+// GetExecution returns the value of Execution if it is set or its
+// zero value if it is unset.
+func (v *ParentExecutionInfo) GetExecution() (o *WorkflowExecution) {
+	if v != nil && v.Execution != nil {
+		return v.Execution
+	}
+
+	return
+}
+
+// IsSetExecution returns true if Execution is not nil.
+func (v *ParentExecutionInfo) IsSetExecution() bool {
+	return v != nil && v.Execution != nil
+}
+
 type PendingActivityInfo struct {
 	ActivityID             *string               `json:"activityID,omitempty"`
 	ActivityType           *ActivityType         `json:"activityType,omitempty"`
@@ -24576,6 +25899,9 @@ type PendingActivityInfo struct {
 	MaximumAttempts        *int32                `json:"maximumAttempts,omitempty"`
 	ScheduledTimestamp     *int64                `json:"scheduledTimestamp,omitempty"`
 	ExpirationTimestamp    *int64                `json:"expirationTimestamp,omitempty"`
+	LastFailureReason      *string               `json:"lastFailureReason,omitempty"`
+	RetryBackoff           *bool                 `json:"retryBackoff,omitempty"`
+	NextRetryTimestamp     *int64                `json:"nextRetryTimestamp,omitempty"`
 }
 
 // ToWire translates a PendingActivityInfo struct into a Thrift-level intermediate
@@ -24595,7 +25921,7 @@ type PendingActivityInfo struct {
 //   }
 func (v *PendingActivityInfo) ToWire() (wire.Value, error) {
 	var (
-		fields [10]wire.Field
+		fields [13]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -24681,6 +26007,30 @@ func (v *PendingActivityInfo) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 100, Value: w}
 		i++
 	}
+	if v.LastFailureReason != nil {
+		w, err = wire.NewValueString(*(v.LastFailureReason)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 110, Value: w}
+		i++
+	}
+	if v.RetryBackoff != nil {
+		w, err = wire.NewValueBool(*(v.RetryBackoff)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 120, Value: w}
+		i++
+	}
+	if v.NextRetryTimestamp != nil {
+		w, err = wire.NewValueI64(*(v.NextRetryTimestamp)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 130, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -24808,6 +26158,36 @@ func (v *PendingActivityInfo) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 110:
+			if field.Value.Type() == wire.TBinary {
+				var x string
+				x, err = field.Value.GetString(), error(nil)
+				v.LastFailureReason = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 120:
+			if field.Value.Type() == wire.TBool {
+				var x bool
+				x, err = field.Value.GetBool(), error(nil)
+				v.RetryBackoff = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 130:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.NextRetryTimestamp = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -24822,7 +26202,7 @@ func (v *PendingActivityInfo) String() string {
 		return "<nil>"
 	}
 
-	var fields [10]string
+	var fields [13]string
 	i := 0
 	if v.ActivityID != nil {
 		fields[i] = fmt.Sprintf("ActivityID: %v", *(v.ActivityID))
@@ -24864,6 +26244,18 @@ func (v *PendingActivityInfo) String() string {
 		fields[i] = fmt.Sprintf("ExpirationTimestamp: %v", *(v.ExpirationTimestamp))
 		i++
 	}
+	if v.LastFailureReason != nil {
+		fields[i] = fmt.Sprintf("LastFailureReason: %v", *(v.LastFailureReason))
+		i++
+	}
+	if v.RetryBackoff != nil {
+		fields[i] = fmt.Sprintf("RetryBackoff: %v", *(v.RetryBackoff))
+		i++
+	}
+	if v.NextRetryTimestamp != nil {
+		fields[i] = fmt.Sprintf("NextRetryTimestamp: %v", *(v.NextRetryTimestamp))
+		i++
+	}
 
 	return fmt.Sprintf("PendingActivityInfo{%v}", strings.Join(fields[:i], ", "))
 }
@@ -24918,6 +26310,15 @@ func (v *PendingActivityInfo) Equals(rhs *PendingActivityInfo) bool {
 	if !_I64_EqualsPtr(v.ExpirationTimestamp, rhs.ExpirationTimestamp) {
 		return false
 	}
+	if !_String_EqualsPtr(v.LastFailureReason, rhs.LastFailureReason) {
+		return false
+	}
+	if !_Bool_EqualsPtr(v.RetryBackoff, rhs.RetryBackoff) {
+		return false
+	}
+	if !_I64_EqualsPtr(v.NextRetryTimestamp, rhs.NextRetryTimestamp) {
+		return false
+	}
 
 	return true
 }
@@ -24958,6 +26359,15 @@ func (v *PendingActivityInfo) MarshalLogObject(enc zapcore.ObjectEncoder) (err e
 	if v.ExpirationTimestamp != nil {
 		enc.AddInt64("expirationTimestamp", *v.ExpirationTimestamp)
 	}
+	if v.LastFailureReason != nil {
+		enc.AddString("lastFailureReason", *v.LastFailureReason)
+	}
+	if v.RetryBackoff != nil {
+		enc.AddBool("retryBackoff", *v.RetryBackoff)
+	}
+	if v.NextRetryTimestamp != nil {
+		enc.AddInt64("nextRetryTimestamp", *v.NextRetryTimestamp)
+	}
 	return err
 }
 
@@ -25111,6 +26521,51 @@ func (v *PendingActivityInfo) IsSetExpirationTimestamp() bool {
 	return v != nil && v.ExpirationTimestamp != nil
 }
 
+// GetLastFailureReason returns the value of LastFailureReason if it is set or its
+// zero value if it is unset.
+func (v *PendingActivityInfo) GetLastFailureReason() (o string) {
+	if v != nil && v.LastFailureReason != nil {
+		return *v.LastFailureReason
+	}
+
+	return
+}
+
+// IsSetLastFailureReason returns true if LastFailureReason is not nil.
+func (v *PendingActivityInfo) IsSetLastFailureReason() bool {
+	return v != nil && v.LastFailureReason != nil
+}
+
+// GetRetryBackoff returns the value of RetryBackoff if it is set or its
+// zero value if it is unset.
+func (v *PendingActivityInfo) GetRetryBackoff() (o bool) {
+	if v != nil && v.RetryBackoff != nil {
+		return *v.RetryBackoff
+	}
+
+	return
+}
+
+// IsSetRetryBackoff returns true if RetryBackoff is not nil.
+func (v *PendingActivityInfo) IsSetRetryBackoff() bool {
+	return v != nil && v.RetryBackoff != nil
+}
+
+// GetNextRetryTimestamp returns the value of NextRetryTimestamp if it is set or its
+// zero value if it is unset.
+func (v *PendingActivityInfo) GetNextRetryTimestamp() (o int64) {
+	if v != nil && v.NextRetryTimestamp != nil {
+		return *v.NextRetryTimestamp
+	}
+
+	return
+}
+
+// IsSetNextRetryTimestamp returns true if NextRetryTimestamp is not nil.
+func (v *PendingActivityInfo) IsSetNextRetryTimestamp() bool {
+	return v != nil && v.NextRetryTimestamp != nil
+}
+
 type PendingActivityState int32
 
 const (
@@ -26620,19 +28075,21 @@ func (v *PollForDecisionTaskRequest) IsSetBinaryChecksum() bool {
 }
 
 type PollForDecisionTaskResponse struct {
-	TaskToken                 []byte             `json:"taskToken,omitempty"`
-	WorkflowExecution         *WorkflowExecution `json:"workflowExecution,omitempty"`
-	WorkflowType              *WorkflowType      `json:"workflowType,omitempty"`
-	PreviousStartedEventId    *int64             `json:"previousStartedEventId,omitempty"`
-	StartedEventId            *int64             `json:"startedEventId,omitempty"`
-	Attempt                   *int64             `json:"attempt,omitempty"`
-	BacklogCountHint          *int64             `json:"backlogCountHint,omitempty"`
-	History                   *History           `json:"history,omitempty"`
-	NextPageToken             []byte             `json:"nextPageToken,omitempty"`
-	Query                     *WorkflowQuery     `json:"query,omitempty"`
-	WorkflowExecutionTaskList *TaskList          `json:"WorkflowExecutionTaskList,omitempty"`
-	ScheduledTimestamp        *int64             `json:"scheduledTimestamp,omitempty"`
-	StartedTimestamp          *int64             `json:"startedTimestamp,omitempty"`
+	TaskToken                           []byte             `json:"taskToken,omitempty"`
+	WorkflowExecution                   *WorkflowExecution `json:"workflowExecution,omitempty"`
+	WorkflowType                        *WorkflowType      `json:"workflowType,omitempty"`
+	PreviousStartedEventId              *int64             `json:"previousStartedEventId,omitempty"`
+	StartedEventId                      *int64             `json:"startedEventId,omitempty"`
+	Attempt                             *int64             `json:"attempt,omitempty"`
+	BacklogCountHint                    *int64             `json:"backlogCountHint,omitempty"`
+	History                             *History           `json:"history,omitempty"`
+	NextPageToken                       []byte             `json:"nextPageToken,omitempty"`
+	Query                               *WorkflowQuery     `json:"query,omitempty"`
+	WorkflowExecutionTaskList           *TaskList          `json:"WorkflowExecutionTaskList,omitempty"`
+	ScheduledTimestamp                  *int64             `json:"scheduledTimestamp,omitempty"`
+	StartedTimestamp                    *int64             `json:"startedTimestamp,omitempty"`
+	StickyScheduleToStartTimeoutSeconds *int32             `json:"stickyScheduleToStartTimeoutSeconds,omitempty"`
+	ScheduledTimestampOfThisAttempt     *int64             `json:"scheduledTimestampOfThisAttempt,omitempty"`
 }
 
 // ToWire translates a PollForDecisionTaskResponse struct into a Thrift-level intermediate
@@ -26652,7 +28109,7 @@ type PollForDecisionTaskResponse struct {
 //   }
 func (v *PollForDecisionTaskResponse) ToWire() (wire.Value, error) {
 	var (
-		fields [13]wire.Field
+		fields [15]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -26762,6 +28219,22 @@ func (v *PollForDecisionTaskResponse) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 110, Value: w}
 		i++
 	}
+	if v.StickyScheduleToStartTimeoutSeconds != nil {
+		w, err = wire.NewValueI32(*(v.StickyScheduleToStartTimeoutSeconds)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 120, Value: w}
+		i++
+	}
+	if v.ScheduledTimestampOfThisAttempt != nil {
+		w, err = wire.NewValueI64(*(v.ScheduledTimestampOfThisAttempt)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 130, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -26909,6 +28382,26 @@ func (v *PollForDecisionTaskResponse) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 120:
+			if field.Value.Type() == wire.TI32 {
+				var x int32
+				x, err = field.Value.GetI32(), error(nil)
+				v.StickyScheduleToStartTimeoutSeconds = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 130:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.ScheduledTimestampOfThisAttempt = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -26923,7 +28416,7 @@ func (v *PollForDecisionTaskResponse) String() string {
 		return "<nil>"
 	}
 
-	var fields [13]string
+	var fields [15]string
 	i := 0
 	if v.TaskToken != nil {
 		fields[i] = fmt.Sprintf("TaskToken: %v", v.TaskToken)
@@ -26977,6 +28470,14 @@ func (v *PollForDecisionTaskResponse) String() string {
 		fields[i] = fmt.Sprintf("StartedTimestamp: %v", *(v.StartedTimestamp))
 		i++
 	}
+	if v.StickyScheduleToStartTimeoutSeconds != nil {
+		fields[i] = fmt.Sprintf("StickyScheduleToStartTimeoutSeconds: %v", *(v.StickyScheduleToStartTimeoutSeconds))
+		i++
+	}
+	if v.ScheduledTimestampOfThisAttempt != nil {
+		fields[i] = fmt.Sprintf("ScheduledTimestampOfThisAttempt: %v", *(v.ScheduledTimestampOfThisAttempt))
+		i++
+	}
 
 	return fmt.Sprintf("PollForDecisionTaskResponse{%v}", strings.Join(fields[:i], ", "))
 }
@@ -27030,6 +28531,12 @@ func (v *PollForDecisionTaskResponse) Equals(rhs *PollForDecisionTaskResponse) b
 	if !_I64_EqualsPtr(v.StartedTimestamp, rhs.StartedTimestamp) {
 		return false
 	}
+	if !_I32_EqualsPtr(v.StickyScheduleToStartTimeoutSeconds, rhs.StickyScheduleToStartTimeoutSeconds) {
+		return false
+	}
+	if !_I64_EqualsPtr(v.ScheduledTimestampOfThisAttempt, rhs.ScheduledTimestampOfThisAttempt) {
+		return false
+	}
 
 	return true
 }
@@ -27079,6 +28586,12 @@ func (v *PollForDecisionTaskResponse) MarshalLogObject(enc zapcore.ObjectEncoder
 	if v.StartedTimestamp != nil {
 		enc.AddInt64("startedTimestamp", *v.StartedTimestamp)
 	}
+	if v.StickyScheduleToStartTimeoutSeconds != nil {
+		enc.AddInt32("stickyScheduleToStartTimeoutSeconds", *v.StickyScheduleToStartTimeoutSeconds)
+	}
+	if v.ScheduledTimestampOfThisAttempt != nil {
+		enc.AddInt64("scheduledTimestampOfThisAttempt", *v.ScheduledTimestampOfThisAttempt)
+	}
 	return err
 }
 
@@ -27277,6 +28790,36 @@ func (v *PollForDecisionTaskResponse) IsSetStartedTimestamp() bool {
 	return v != nil && v.StartedTimestamp != nil
 }
 
+// GetStickyScheduleToStartTimeoutSeconds returns the value of StickyScheduleToStartTimeoutSeconds if it is set or its
+// zero value if it is unset.
+func (v *PollForDecisionTaskResponse) GetStickyScheduleToStartTimeoutSeconds() (o int32) {
+	if v != nil && v.StickyScheduleToStartTimeoutSeconds != nil {
+		return *v.StickyScheduleToStartTimeoutSeconds
+	}
+
+	return
+}
+
+// IsSetStickyScheduleToStartTimeoutSeconds returns true if StickyScheduleToStartTimeoutSeconds is not nil.
+func (v *PollForDecisionTaskResponse) IsSetStickyScheduleToStartTimeoutSeconds() bool {
+	return v != nil && v.StickyScheduleToStartTimeoutSeconds != nil
+}
+
+// GetScheduledTimestampOfThisAttempt returns the value of ScheduledTimestampOfThisAttempt if it is set or its
+// zero value if it is unset.
+func (v *PollForDecisionTaskResponse) GetScheduledTimestampOfThisAttempt() (o int64) {
+	if v != nil && v.ScheduledTimestampOfThisAttempt != nil {
+		return *v.ScheduledTimestampOfThisAttempt
+	}
+
+	return
+}
+
+// IsSetScheduledTimestampOfThisAttempt returns true if ScheduledTimestampOfThisAttempt is not nil.
+func (v *PollForDecisionTaskResponse) IsSetScheduledTimestampOfThisAttempt() bool {
+	return v != nil && v.ScheduledTimestampOfThisAttempt != nil
+}
+
 type PollerInfo struct {
 	LastAccessTime *int64   `json:"lastAccessTime,omitempty"`
 	Identity       *string  `json:"identity,omitempty"`
@@ -34662,6 +36205,7 @@ type RespondDecisionTaskCompletedRequest struct {
 	ReturnNewDecisionTask      *bool                      `json:"returnNewDecisionTask,omitempty"`
 	ForceCreateNewDecisionTask *bool                      `json:"forceCreateNewDecisionTask,omitempty"`
 	BinaryChecksum             *string                    `json:"binaryChecksum,omitempty"`
+	ResetStickyForNextDecision *bool                      `json:"resetStickyForNextDecision,omitempty"`
 }
 
 type _List_Decision_ValueList []*Decision
@@ -34710,7 +36254,7 @@ func (_List_Decision_ValueList) Close() {}
 //   }
 func (v *RespondDecisionTaskCompletedRequest) ToWire() (wire.Value, error) {
 	var (
-		fields [8]wire.Field
+		fields [9]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -34780,6 +36324,14 @@ func (v *RespondDecisionTaskCompletedRequest) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 80, Value: w}
 		i++
 	}
+	if v.ResetStickyForNextDecision != nil {
+		w, err = wire.NewValueBool(*(v.ResetStickyForNextDecision)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 90, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -34907,6 +36459,16 @@ func (v *RespondDecisionTaskCompletedRequest) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 90:
+			if field.Value.Type() == wire.TBool {
+				var x bool
+				x, err = field.Value.GetBool(), error(nil)
+				v.ResetStickyForNextDecision = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -34921,7 +36483,7 @@ func (v *RespondDecisionTaskCompletedRequest) String() string {
 		return "<nil>"
 	}
 
-	var fields [8]string
+	var fields [9]string
 	i := 0
 	if v.TaskToken != nil {
 		fields[i] = fmt.Sprintf("TaskToken: %v", v.TaskToken)
@@ -34955,6 +36517,10 @@ func (v *RespondDecisionTaskCompletedRequest) String() string {
 		fields[i] = fmt.Sprintf("BinaryChecksum: %v", *(v.BinaryChecksum))
 		i++
 	}
+	if v.ResetStickyForNextDecision != nil {
+		fields[i] = fmt.Sprintf("ResetStickyForNextDecision: %v", *(v.ResetStickyForNextDecision))
+		i++
+	}
 
 	return fmt.Sprintf("RespondDecisionTaskCompletedRequest{%v}", strings.Join(fields[:i], ", "))
 }
@@ -35008,6 +36574,9 @@ func (v *RespondDecisionTaskCompletedRequest) Equals(rhs *RespondDecisionTaskCom
 	if !_String_EqualsPtr(v.BinaryChecksum, rhs.BinaryChecksum) {
 		return false
 	}
+	if !_Bool_EqualsPtr(v.ResetStickyForNextDecision, rhs.ResetStickyForNextDecision) {
+		return false
+	}
 
 	return true
 }
@@ -35053,6 +36622,9 @@ func (v *RespondDecisionTaskCompletedRequest) MarshalLogObject(enc zapcore.Objec
 	if v.BinaryChecksum != nil {
 		enc.AddString("binaryChecksum", *v.BinaryChecksum)
 	}
+	if v.ResetStickyForNextDecision != nil {
+		enc.AddBool("resetStickyForNextDecision", *v.ResetStickyForNextDecision)
+	}
 	return err
 }
 
@@ -35176,8 +36748,24 @@ func (v *RespondDecisionTaskCompletedRequest) IsSetBinaryChecksum() bool {
 	return v != nil && v.BinaryChecksum != nil
 }
 
+// GetResetStickyForNextDecision returns the value of ResetStickyForNextDecision if it is set or its
+// zero value if it is unset.
+func (v *RespondDecisionTaskCompletedRequest) GetResetStickyForNextDecision() (o bool) {
+	if v != nil && v.ResetStickyForNextDecision != nil {
+		return *v.ResetStickyForNextDecision
+	}
+
+	return
+}
+
+// IsSetResetStickyForNextDecision returns true if ResetStickyForNextDecision is not nil.
+func (v *RespondDecisionTaskCompletedRequest) IsSetResetStickyForNextDecision() bool {
+	return v != nil && v.ResetStickyForNextDecision != nil
+}
+
 type RespondDecisionTaskCompletedResponse struct {
-	DecisionTask *PollForDecisionTaskResponse `json:"decisionTask,omitempty"`
+	DecisionTask  *PollForDecisionTaskResponse `json:"decisionTask,omitempty"`
+	EventsWritten *int64                       `json:"eventsWritten,omitempty"`
 }
 
 // ToWire translates a RespondDecisionTaskCompletedResponse struct into a Thrift-level intermediate
@@ -35197,7 +36785,7 @@ type RespondDecisionTaskCompletedResponse struct {
 //   }
 func (v *RespondDecisionTaskCompletedResponse) ToWire() (wire.Value, error) {
 	var (
-		fields [1]wire.Field
+		fields [2]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -35211,6 +36799,14 @@ func (v *RespondDecisionTaskCompletedResponse) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 10, Value: w}
 		i++
 	}
+	if v.EventsWritten != nil {
+		w, err = wire.NewValueI64(*(v.EventsWritten)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 20, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -35250,6 +36846,16 @@ func (v *RespondDecisionTaskCompletedResponse) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 20:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.EventsWritten = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -35264,12 +36870,16 @@ func (v *RespondDecisionTaskCompletedResponse) String() string {
 		return "<nil>"
 	}
 
-	var fields [1]string
+	var fields [2]string
 	i := 0
 	if v.DecisionTask != nil {
 		fields[i] = fmt.Sprintf("DecisionTask: %v", v.DecisionTask)
 		i++
 	}
+	if v.EventsWritten != nil {
+		fields[i] = fmt.Sprintf("EventsWritten: %v", *(v.EventsWritten))
+		i++
+	}
 
 	return fmt.Sprintf("RespondDecisionTaskCompletedResponse{%v}", strings.Join(fields[:i], ", "))
 }
@@ -35287,6 +36897,9 @@ func (v *RespondDecisionTaskCompletedResponse) Equals(rhs *RespondDecisionTaskCo
 	if !((v.DecisionTask == nil && rhs.DecisionTask == nil) || (v.DecisionTask != nil && rhs.DecisionTask != nil && v.DecisionTask.Equals(rhs.DecisionTask))) {
 		return false
 	}
+	if !_I64_EqualsPtr(v.EventsWritten, rhs.EventsWritten) {
+		return false
+	}
 
 	return true
 }
@@ -35300,6 +36913,9 @@ func (v *RespondDecisionTaskCompletedResponse) MarshalLogObject(enc zapcore.Obje
 	if v.DecisionTask != nil {
 		err = multierr.Append(err, enc.AddObject("decisionTask", v.DecisionTask))
 	}
+	if v.EventsWritten != nil {
+		enc.AddInt64("eventsWritten", *v.EventsWritten)
+	}
 	return err
 }
 
@@ -35318,6 +36934,21 @@ func (v *RespondDecisionTaskCompletedResponse) IsSetDecisionTask() bool {
 	return v != nil && v.DecisionTask != nil
 }
 
+// GetEventsWritten returns the value of EventsWritten if it is set or its
+// zero value if it is unset.
+func (v *RespondDecisionTaskCompletedResponse) GetEventsWritten() (o int64) {
+	if v != nil && v.EventsWritten != nil {
+		return *v.EventsWritten
+	}
+
+	return
+}
+
+// IsSetEventsWritten returns true if EventsWritten is not nil.
+func (v *RespondDecisionTaskCompletedResponse) IsSetEventsWritten() bool {
+	return v != nil && v.EventsWritten != nil
+}
+
 type RespondDecisionTaskFailedRequest struct {
 	TaskToken []byte                   `json:"taskToken,omitempty"`
 	Cause     *DecisionTaskFailedCause `json:"cause,omitempty"`
@@ -36614,6 +38245,8 @@ type ScheduleActivityTaskDecisionAttributes struct {
 	HeartbeatTimeoutSeconds       *int32        `json:"heartbeatTimeoutSeconds,omitempty"`
 	RetryPolicy                   *RetryPolicy  `json:"retryPolicy,omitempty"`
 	Header                        *Header       `json:"header,omitempty"`
+	DispatchOnly                  *bool         `json:"dispatchOnly,omitempty"`
+	ScheduleDelaySeconds          *int32        `json:"scheduleDelaySeconds,omitempty"`
 }
 
 // ToWire translates a ScheduleActivityTaskDecisionAttributes struct into a Thrift-level intermediate
@@ -36633,7 +38266,7 @@ type ScheduleActivityTaskDecisionAttributes struct {
 //   }
 func (v *ScheduleActivityTaskDecisionAttributes) ToWire() (wire.Value, error) {
 	var (
-		fields [11]wire.Field
+		fields [13]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -36727,6 +38360,22 @@ func (v *ScheduleActivityTaskDecisionAttributes) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 80, Value: w}
 		i++
 	}
+	if v.DispatchOnly != nil {
+		w, err = wire.NewValueBool(*(v.DispatchOnly)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 90, Value: w}
+		i++
+	}
+	if v.ScheduleDelaySeconds != nil {
+		w, err = wire.NewValueI32(*(v.ScheduleDelaySeconds)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 100, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -36852,6 +38501,26 @@ func (v *ScheduleActivityTaskDecisionAttributes) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 90:
+			if field.Value.Type() == wire.TBool {
+				var x bool
+				x, err = field.Value.GetBool(), error(nil)
+				v.DispatchOnly = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 100:
+			if field.Value.Type() == wire.TI32 {
+				var x int32
+				x, err = field.Value.GetI32(), error(nil)
+				v.ScheduleDelaySeconds = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -36866,7 +38535,7 @@ func (v *ScheduleActivityTaskDecisionAttributes) String() string {
 		return "<nil>"
 	}
 
-	var fields [11]string
+	var fields [13]string
 	i := 0
 	if v.ActivityId != nil {
 		fields[i] = fmt.Sprintf("ActivityId: %v", *(v.ActivityId))
@@ -36912,6 +38581,14 @@ func (v *ScheduleActivityTaskDecisionAttributes) String() string {
 		fields[i] = fmt.Sprintf("Header: %v", v.Header)
 		i++
 	}
+	if v.DispatchOnly != nil {
+		fields[i] = fmt.Sprintf("DispatchOnly: %v", *(v.DispatchOnly))
+		i++
+	}
+	if v.ScheduleDelaySeconds != nil {
+		fields[i] = fmt.Sprintf("ScheduleDelaySeconds: %v", *(v.ScheduleDelaySeconds))
+		i++
+	}
 
 	return fmt.Sprintf("ScheduleActivityTaskDecisionAttributes{%v}", strings.Join(fields[:i], ", "))
 }
@@ -36959,6 +38636,12 @@ func (v *ScheduleActivityTaskDecisionAttributes) Equals(rhs *ScheduleActivityTas
 	if !((v.Header == nil && rhs.Header == nil) || (v.Header != nil && rhs.Header != nil && v.Header.Equals(rhs.Header))) {
 		return false
 	}
+	if !_Bool_EqualsPtr(v.DispatchOnly, rhs.DispatchOnly) {
+		return false
+	}
+	if !_I32_EqualsPtr(v.ScheduleDelaySeconds, rhs.ScheduleDelaySeconds) {
+		return false
+	}
 
 	return true
 }
@@ -37002,6 +38685,12 @@ func (v *ScheduleActivityTaskDecisionAttributes) MarshalLogObject(enc zapcore.Ob
 	if v.Header != nil {
 		err = multierr.Append(err, enc.AddObject("header", v.Header))
 	}
+	if v.DispatchOnly != nil {
+		enc.AddBool("dispatchOnly", *v.DispatchOnly)
+	}
+	if v.ScheduleDelaySeconds != nil {
+		enc.AddInt32("scheduleDelaySeconds", *v.ScheduleDelaySeconds)
+	}
 	return err
 }
 
@@ -37170,6 +38859,36 @@ func (v *ScheduleActivityTaskDecisionAttributes) IsSetHeader() bool {
 	return v != nil && v.Header != nil
 }
 
+// GetDispatchOnly returns the value of DispatchOnly if it is set or its
+// zero value if it is unset.
+func (v *ScheduleActivityTaskDecisionAttributes) GetDispatchOnly() (o bool) {
+	if v != nil && v.DispatchOnly != nil {
+		return *v.DispatchOnly
+	}
+
+	return
+}
+
+// IsSetDispatchOnly returns true if DispatchOnly is not nil.
+func (v *ScheduleActivityTaskDecisionAttributes) IsSetDispatchOnly() bool {
+	return v != nil && v.DispatchOnly != nil
+}
+
+// GetScheduleDelaySeconds returns the value of ScheduleDelaySeconds if it is set or its
+// zero value if it is unset.
+func (v *ScheduleActivityTaskDecisionAttributes) GetScheduleDelaySeconds() (o int32) {
+	if v != nil && v.ScheduleDelaySeconds != nil {
+		return *v.ScheduleDelaySeconds
+	}
+
+	return
+}
+
+// IsSetScheduleDelaySeconds returns true if ScheduleDelaySeconds is not nil.
+func (v *ScheduleActivityTaskDecisionAttributes) IsSetScheduleDelaySeconds() bool {
+	return v != nil && v.ScheduleDelaySeconds != nil
+}
+
 type SearchAttributes struct {
 	IndexedFields map[string][]byte `json:"indexedFields,omitempty"`
 }
@@ -38735,6 +40454,7 @@ type SignalWithStartWorkflowExecutionRequest struct {
 	Memo                                *Memo                  `json:"memo,omitempty"`
 	SearchAttributes                    *SearchAttributes      `json:"searchAttributes,omitempty"`
 	Header                              *Header                `json:"header,omitempty"`
+	TtlSeconds                          *int32                 `json:"ttlSeconds,omitempty"`
 }
 
 // ToWire translates a SignalWithStartWorkflowExecutionRequest struct into a Thrift-level intermediate
@@ -38754,7 +40474,7 @@ type SignalWithStartWorkflowExecutionRequest struct {
 //   }
 func (v *SignalWithStartWorkflowExecutionRequest) ToWire() (wire.Value, error) {
 	var (
-		fields [18]wire.Field
+		fields [19]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -38904,6 +40624,14 @@ func (v *SignalWithStartWorkflowExecutionRequest) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 170, Value: w}
 		i++
 	}
+	if v.TtlSeconds != nil {
+		w, err = wire.NewValueI32(*(v.TtlSeconds)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 180, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -39109,6 +40837,16 @@ func (v *SignalWithStartWorkflowExecutionRequest) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 180:
+			if field.Value.Type() == wire.TI32 {
+				var x int32
+				x, err = field.Value.GetI32(), error(nil)
+				v.TtlSeconds = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -39123,7 +40861,7 @@ func (v *SignalWithStartWorkflowExecutionRequest) String() string {
 		return "<nil>"
 	}
 
-	var fields [18]string
+	var fields [19]string
 	i := 0
 	if v.Domain != nil {
 		fields[i] = fmt.Sprintf("Domain: %v", *(v.Domain))
@@ -39197,6 +40935,10 @@ func (v *SignalWithStartWorkflowExecutionRequest) String() string {
 		fields[i] = fmt.Sprintf("Header: %v", v.Header)
 		i++
 	}
+	if v.TtlSeconds != nil {
+		fields[i] = fmt.Sprintf("TtlSeconds: %v", *(v.TtlSeconds))
+		i++
+	}
 
 	return fmt.Sprintf("SignalWithStartWorkflowExecutionRequest{%v}", strings.Join(fields[:i], ", "))
 }
@@ -39275,6 +41017,9 @@ func (v *SignalWithStartWorkflowExecutionRequest) Equals(rhs *SignalWithStartWor
 	if !((v.Header == nil && rhs.Header == nil) || (v.Header != nil && rhs.Header != nil && v.Header.Equals(rhs.Header))) {
 		return false
 	}
+	if !_I32_EqualsPtr(v.TtlSeconds, rhs.TtlSeconds) {
+		return false
+	}
 
 	return true
 }
@@ -39339,6 +41084,9 @@ func (v *SignalWithStartWorkflowExecutionRequest) MarshalLogObject(enc zapcore.O
 	if v.Header != nil {
 		err = multierr.Append(err, enc.AddObject("header", v.Header))
 	}
+	if v.TtlSeconds != nil {
+		enc.AddInt32("ttlSeconds", *v.TtlSeconds)
+	}
 	return err
 }
 
@@ -39612,6 +41360,21 @@ func (v *SignalWithStartWorkflowExecutionRequest) IsSetHeader() bool {
 	return v != nil && v.Header != nil
 }
 
+// GetTtlSeconds returns the value of TtlSeconds if it is set or its
+// zero value if it is unset.
+func (v *SignalWithStartWorkflowExecutionRequest) GetTtlSeconds() (o int32) {
+	if v != nil && v.TtlSeconds != nil {
+		return *v.TtlSeconds
+	}
+
+	return
+}
+
+// IsSetTtlSeconds returns true if TtlSeconds is not nil.
+func (v *SignalWithStartWorkflowExecutionRequest) IsSetTtlSeconds() bool {
+	return v != nil && v.TtlSeconds != nil
+}
+
 type SignalWorkflowExecutionRequest struct {
 	Domain            *string            `json:"domain,omitempty"`
 	WorkflowExecution *WorkflowExecution `json:"workflowExecution,omitempty"`
@@ -39620,6 +41383,8 @@ type SignalWorkflowExecutionRequest struct {
 	Identity          *string            `json:"identity,omitempty"`
 	RequestId         *string            `json:"requestId,omitempty"`
 	Control           []byte             `json:"control,omitempty"`
+	Header            *Header            `json:"header,omitempty"`
+	TtlSeconds        *int32             `json:"ttlSeconds,omitempty"`
 }
 
 // ToWire translates a SignalWorkflowExecutionRequest struct into a Thrift-level intermediate
@@ -39639,7 +41404,7 @@ type SignalWorkflowExecutionRequest struct {
 //   }
 func (v *SignalWorkflowExecutionRequest) ToWire() (wire.Value, error) {
 	var (
-		fields [7]wire.Field
+		fields [9]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -39701,6 +41466,22 @@ func (v *SignalWorkflowExecutionRequest) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 70, Value: w}
 		i++
 	}
+	if v.Header != nil {
+		w, err = v.Header.ToWire()
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 80, Value: w}
+		i++
+	}
+	if v.TtlSeconds != nil {
+		w, err = wire.NewValueI32(*(v.TtlSeconds)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 90, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -39790,6 +41571,24 @@ func (v *SignalWorkflowExecutionRequest) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 80:
+			if field.Value.Type() == wire.TStruct {
+				v.Header, err = _Header_Read(field.Value)
+				if err != nil {
+					return err
+				}
+
+			}
+		case 90:
+			if field.Value.Type() == wire.TI32 {
+				var x int32
+				x, err = field.Value.GetI32(), error(nil)
+				v.TtlSeconds = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -39804,7 +41603,7 @@ func (v *SignalWorkflowExecutionRequest) String() string {
 		return "<nil>"
 	}
 
-	var fields [7]string
+	var fields [9]string
 	i := 0
 	if v.Domain != nil {
 		fields[i] = fmt.Sprintf("Domain: %v", *(v.Domain))
@@ -39834,6 +41633,14 @@ func (v *SignalWorkflowExecutionRequest) String() string {
 		fields[i] = fmt.Sprintf("Control: %v", v.Control)
 		i++
 	}
+	if v.Header != nil {
+		fields[i] = fmt.Sprintf("Header: %v", v.Header)
+		i++
+	}
+	if v.TtlSeconds != nil {
+		fields[i] = fmt.Sprintf("TtlSeconds: %v", *(v.TtlSeconds))
+		i++
+	}
 
 	return fmt.Sprintf("SignalWorkflowExecutionRequest{%v}", strings.Join(fields[:i], ", "))
 }
@@ -39869,6 +41676,12 @@ func (v *SignalWorkflowExecutionRequest) Equals(rhs *SignalWorkflowExecutionRequ
 	if !((v.Control == nil && rhs.Control == nil) || (v.Control != nil && rhs.Control != nil && bytes.Equal(v.Control, rhs.Control))) {
 		return false
 	}
+	if !((v.Header == nil && rhs.Header == nil) || (v.Header != nil && rhs.Header != nil && v.Header.Equals(rhs.Header))) {
+		return false
+	}
+	if !_I32_EqualsPtr(v.TtlSeconds, rhs.TtlSeconds) {
+		return false
+	}
 
 	return true
 }
@@ -39900,6 +41713,12 @@ func (v *SignalWorkflowExecutionRequest) MarshalLogObject(enc zapcore.ObjectEnco
 	if v.Control != nil {
 		enc.AddString("control", base64.StdEncoding.EncodeToString(v.Control))
 	}
+	if v.Header != nil {
+		err = multierr.Append(err, enc.AddObject("header", v.Header))
+	}
+	if v.TtlSeconds != nil {
+		enc.AddInt32("ttlSeconds", *v.TtlSeconds)
+	}
 	return err
 }
 
@@ -40008,6 +41827,36 @@ func (v *SignalWorkflowExecutionRequest) IsSetControl() bool {
 	return v != nil && v.Control != nil
 }
 
+// GetHeader returns the value of Header if it is set or its
+// zero value if it is unset.
+func (v *SignalWorkflowExecutionRequest) GetHeader() (o *Header) {
+	if v != nil && v.Header != nil {
+		return v.Header
+	}
+
+	return
+}
+
+// IsSetHeader returns true if Header is not nil.
+func (v *SignalWorkflowExecutionRequest) IsSetHeader() bool {
+	return v != nil && v.Header != nil
+}
+
+// GetTtlSeconds returns the value of TtlSeconds if it is set or its
+// zero value if it is unset.
+func (v *SignalWorkflowExecutionRequest) GetTtlSeconds() (o int32) {
+	if v != nil && v.TtlSeconds != nil {
+		return *v.TtlSeconds
+	}
+
+	return
+}
+
+// IsSetTtlSeconds returns true if TtlSeconds is not nil.
+func (v *SignalWorkflowExecutionRequest) IsSetTtlSeconds() bool {
+	return v != nil && v.TtlSeconds != nil
+}
+
 type StartChildWorkflowExecutionDecisionAttributes struct {
 	Domain                              *string                `json:"domain,omitempty"`
 	WorkflowId                          *string                `json:"workflowId,omitempty"`
@@ -42155,22 +44004,24 @@ func (v *StartTimerDecisionAttributes) IsSetStartToFireTimeoutSeconds() bool {
 }
 
 type StartWorkflowExecutionRequest struct {
-	Domain                              *string                `json:"domain,omitempty"`
-	WorkflowId                          *string                `json:"workflowId,omitempty"`
-	WorkflowType                        *WorkflowType          `json:"workflowType,omitempty"`
-	TaskList                            *TaskList              `json:"taskList,omitempty"`
-	Input                               []byte                 `json:"input,omitempty"`
-	ExecutionStartToCloseTimeoutSeconds *int32                 `json:"executionStartToCloseTimeoutSeconds,omitempty"`
-	TaskStartToCloseTimeoutSeconds      *int32                 `json:"taskStartToCloseTimeoutSeconds,omitempty"`
-	Identity                            *string                `json:"identity,omitempty"`
-	RequestId                           *string                `json:"requestId,omitempty"`
-	WorkflowIdReusePolicy               *WorkflowIdReusePolicy `json:"workflowIdReusePolicy,omitempty"`
-	ChildPolicy                         *ChildPolicy           `json:"childPolicy,omitempty"`
-	RetryPolicy                         *RetryPolicy           `json:"retryPolicy,omitempty"`
-	CronSchedule                        *string                `json:"cronSchedule,omitempty"`
-	Memo                                *Memo                  `json:"memo,omitempty"`
-	SearchAttributes                    *SearchAttributes      `json:"searchAttributes,omitempty"`
-	Header                              *Header                `json:"header,omitempty"`
+	Domain                                      *string                `json:"domain,omitempty"`
+	WorkflowId                                  *string                `json:"workflowId,omitempty"`
+	WorkflowType                                *WorkflowType          `json:"workflowType,omitempty"`
+	TaskList                                    *TaskList              `json:"taskList,omitempty"`
+	Input                                       []byte                 `json:"input,omitempty"`
+	ExecutionStartToCloseTimeoutSeconds         *int32                 `json:"executionStartToCloseTimeoutSeconds,omitempty"`
+	TaskStartToCloseTimeoutSeconds              *int32                 `json:"taskStartToCloseTimeoutSeconds,omitempty"`
+	Identity                                    *string                `json:"identity,omitempty"`
+	RequestId                                   *string                `json:"requestId,omitempty"`
+	WorkflowIdReusePolicy                       *WorkflowIdReusePolicy `json:"workflowIdReusePolicy,omitempty"`
+	ChildPolicy                                 *ChildPolicy           `json:"childPolicy,omitempty"`
+	RetryPolicy                                 *RetryPolicy           `json:"retryPolicy,omitempty"`
+	CronSchedule                                *string                `json:"cronSchedule,omitempty"`
+	Memo                                        *Memo                  `json:"memo,omitempty"`
+	SearchAttributes                            *SearchAttributes      `json:"searchAttributes,omitempty"`
+	Header                                      *Header                `json:"header,omitempty"`
+	FirstDecisionTaskStartToCloseTimeoutSeconds *int32                 `json:"firstDecisionTaskStartToCloseTimeoutSeconds,omitempty"`
+	WaitForFirstDecisionScheduled               *bool                  `json:"waitForFirstDecisionScheduled,omitempty"`
 }
 
 // ToWire translates a StartWorkflowExecutionRequest struct into a Thrift-level intermediate
@@ -42190,7 +44041,7 @@ type StartWorkflowExecutionRequest struct {
 //   }
 func (v *StartWorkflowExecutionRequest) ToWire() (wire.Value, error) {
 	var (
-		fields [16]wire.Field
+		fields [18]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -42324,6 +44175,22 @@ func (v *StartWorkflowExecutionRequest) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 150, Value: w}
 		i++
 	}
+	if v.FirstDecisionTaskStartToCloseTimeoutSeconds != nil {
+		w, err = wire.NewValueI32(*(v.FirstDecisionTaskStartToCloseTimeoutSeconds)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 160, Value: w}
+		i++
+	}
+	if v.WaitForFirstDecisionScheduled != nil {
+		w, err = wire.NewValueBool(*(v.WaitForFirstDecisionScheduled)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 170, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -42495,6 +44362,26 @@ func (v *StartWorkflowExecutionRequest) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 160:
+			if field.Value.Type() == wire.TI32 {
+				var x int32
+				x, err = field.Value.GetI32(), error(nil)
+				v.FirstDecisionTaskStartToCloseTimeoutSeconds = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 170:
+			if field.Value.Type() == wire.TBool {
+				var x bool
+				x, err = field.Value.GetBool(), error(nil)
+				v.WaitForFirstDecisionScheduled = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -42509,7 +44396,7 @@ func (v *StartWorkflowExecutionRequest) String() string {
 		return "<nil>"
 	}
 
-	var fields [16]string
+	var fields [18]string
 	i := 0
 	if v.Domain != nil {
 		fields[i] = fmt.Sprintf("Domain: %v", *(v.Domain))
@@ -42575,6 +44462,14 @@ func (v *StartWorkflowExecutionRequest) String() string {
 		fields[i] = fmt.Sprintf("Header: %v", v.Header)
 		i++
 	}
+	if v.FirstDecisionTaskStartToCloseTimeoutSeconds != nil {
+		fields[i] = fmt.Sprintf("FirstDecisionTaskStartToCloseTimeoutSeconds: %v", *(v.FirstDecisionTaskStartToCloseTimeoutSeconds))
+		i++
+	}
+	if v.WaitForFirstDecisionScheduled != nil {
+		fields[i] = fmt.Sprintf("WaitForFirstDecisionScheduled: %v", *(v.WaitForFirstDecisionScheduled))
+		i++
+	}
 
 	return fmt.Sprintf("StartWorkflowExecutionRequest{%v}", strings.Join(fields[:i], ", "))
 }
@@ -42637,6 +44532,12 @@ func (v *StartWorkflowExecutionRequest) Equals(rhs *StartWorkflowExecutionReques
 	if !((v.Header == nil && rhs.Header == nil) || (v.Header != nil && rhs.Header != nil && v.Header.Equals(rhs.Header))) {
 		return false
 	}
+	if !_I32_EqualsPtr(v.FirstDecisionTaskStartToCloseTimeoutSeconds, rhs.FirstDecisionTaskStartToCloseTimeoutSeconds) {
+		return false
+	}
+	if !_Bool_EqualsPtr(v.WaitForFirstDecisionScheduled, rhs.WaitForFirstDecisionScheduled) {
+		return false
+	}
 
 	return true
 }
@@ -42695,6 +44596,12 @@ func (v *StartWorkflowExecutionRequest) MarshalLogObject(enc zapcore.ObjectEncod
 	if v.Header != nil {
 		err = multierr.Append(err, enc.AddObject("header", v.Header))
 	}
+	if v.FirstDecisionTaskStartToCloseTimeoutSeconds != nil {
+		enc.AddInt32("firstDecisionTaskStartToCloseTimeoutSeconds", *v.FirstDecisionTaskStartToCloseTimeoutSeconds)
+	}
+	if v.WaitForFirstDecisionScheduled != nil {
+		enc.AddBool("waitForFirstDecisionScheduled", *v.WaitForFirstDecisionScheduled)
+	}
 	return err
 }
 
@@ -42938,8 +44845,39 @@ func (v *StartWorkflowExecutionRequest) IsSetHeader() bool {
 	return v != nil && v.Header != nil
 }
 
+// GetFirstDecisionTaskStartToCloseTimeoutSeconds returns the value of FirstDecisionTaskStartToCloseTimeoutSeconds if it is set or its
+// zero value if it is unset.
+func (v *StartWorkflowExecutionRequest) GetFirstDecisionTaskStartToCloseTimeoutSeconds() (o int32) {
+	if v != nil && v.FirstDecisionTaskStartToCloseTimeoutSeconds != nil {
+		return *v.FirstDecisionTaskStartToCloseTimeoutSeconds
+	}
+
+	return
+}
+
+// IsSetFirstDecisionTaskStartToCloseTimeoutSeconds returns true if FirstDecisionTaskStartToCloseTimeoutSeconds is not nil.
+func (v *StartWorkflowExecutionRequest) IsSetFirstDecisionTaskStartToCloseTimeoutSeconds() bool {
+	return v != nil && v.FirstDecisionTaskStartToCloseTimeoutSeconds != nil
+}
+
+// GetWaitForFirstDecisionScheduled returns the value of WaitForFirstDecisionScheduled if it is set or its
+// zero value if it is unset.
+func (v *StartWorkflowExecutionRequest) GetWaitForFirstDecisionScheduled() (o bool) {
+	if v != nil && v.WaitForFirstDecisionScheduled != nil {
+		return *v.WaitForFirstDecisionScheduled
+	}
+
+	return
+}
+
+// IsSetWaitForFirstDecisionScheduled returns true if WaitForFirstDecisionScheduled is not nil.
+func (v *StartWorkflowExecutionRequest) IsSetWaitForFirstDecisionScheduled() bool {
+	return v != nil && v.WaitForFirstDecisionScheduled != nil
+}
+
 type StartWorkflowExecutionResponse struct {
-	RunId *string `json:"runId,omitempty"`
+	RunId       *string `json:"runId,omitempty"`
+	NextEventId *int64  `json:"nextEventId,omitempty"`
 }
 
 // ToWire translates a StartWorkflowExecutionResponse struct into a Thrift-level intermediate
@@ -42959,7 +44897,7 @@ type StartWorkflowExecutionResponse struct {
 //   }
 func (v *StartWorkflowExecutionResponse) ToWire() (wire.Value, error) {
 	var (
-		fields [1]wire.Field
+		fields [2]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -42973,6 +44911,14 @@ func (v *StartWorkflowExecutionResponse) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 10, Value: w}
 		i++
 	}
+	if v.NextEventId != nil {
+		w, err = wire.NewValueI64(*(v.NextEventId)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 20, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -43008,6 +44954,16 @@ func (v *StartWorkflowExecutionResponse) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 20:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.NextEventId = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -43022,12 +44978,16 @@ func (v *StartWorkflowExecutionResponse) String() string {
 		return "<nil>"
 	}
 
-	var fields [1]string
+	var fields [2]string
 	i := 0
 	if v.RunId != nil {
 		fields[i] = fmt.Sprintf("RunId: %v", *(v.RunId))
 		i++
 	}
+	if v.NextEventId != nil {
+		fields[i] = fmt.Sprintf("NextEventId: %v", *(v.NextEventId))
+		i++
+	}
 
 	return fmt.Sprintf("StartWorkflowExecutionResponse{%v}", strings.Join(fields[:i], ", "))
 }
@@ -43045,6 +45005,9 @@ func (v *StartWorkflowExecutionResponse) Equals(rhs *StartWorkflowExecutionRespo
 	if !_String_EqualsPtr(v.RunId, rhs.RunId) {
 		return false
 	}
+	if !_I64_EqualsPtr(v.NextEventId, rhs.NextEventId) {
+		return false
+	}
 
 	return true
 }
@@ -43058,6 +45021,9 @@ func (v *StartWorkflowExecutionResponse) MarshalLogObject(enc zapcore.ObjectEnco
 	if v.RunId != nil {
 		enc.AddString("runId", *v.RunId)
 	}
+	if v.NextEventId != nil {
+		enc.AddInt64("nextEventId", *v.NextEventId)
+	}
 	return err
 }
 
@@ -43076,6 +45042,21 @@ func (v *StartWorkflowExecutionResponse) IsSetRunId() bool {
 	return v != nil && v.RunId != nil
 }
 
+// GetNextEventId returns the value of NextEventId if it is set or its
+// zero value if it is unset.
+func (v *StartWorkflowExecutionResponse) GetNextEventId() (o int64) {
+	if v != nil && v.NextEventId != nil {
+		return *v.NextEventId
+	}
+
+	return
+}
+
+// IsSetNextEventId returns true if NextEventId is not nil.
+func (v *StartWorkflowExecutionResponse) IsSetNextEventId() bool {
+	return v != nil && v.NextEventId != nil
+}
+
 type StickyExecutionAttributes struct {
 	WorkerTaskList                *TaskList `json:"workerTaskList,omitempty"`
 	ScheduleToStartTimeoutSeconds *int32    `json:"scheduleToStartTimeoutSeconds,omitempty"`
@@ -46687,6 +48668,322 @@ func (v *UpdateDomainResponse) IsSetIsGlobalDomain() bool {
 	return v != nil && v.IsGlobalDomain != nil
 }
 
+type UpsertWorkflowMemoDecisionAttributes struct {
+	Memo *Memo `json:"memo,omitempty"`
+}
+
+// ToWire translates a UpsertWorkflowMemoDecisionAttributes struct into a Thrift-level intermediate
+// representation. This intermediate representation may be serialized
+// into bytes using a ThriftRW protocol implementation.
+//
+// An error is returned if the struct or any of its fields failed to
+// validate.
+//
+//   x, err := v.ToWire()
+//   if err != nil {
+//     return err
+//   }
+//
+//   if err := binaryProtocol.Encode(x, writer); err != nil {
+//     return err
+//   }
+func (v *UpsertWorkflowMemoDecisionAttributes) ToWire() (wire.Value, error) {
+	var (
+		fields [1]wire.Field
+		i      int = 0
+		w      wire.Value
+		err    error
+	)
+
+	if v.Memo != nil {
+		w, err = v.Memo.ToWire()
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 10, Value: w}
+		i++
+	}
+
+	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
+}
+
+// FromWire deserializes a UpsertWorkflowMemoDecisionAttributes struct from its Thrift-level
+// representation. The Thrift-level representation may be obtained
+// from a ThriftRW protocol implementation.
+//
+// An error is returned if we were unable to build a UpsertWorkflowMemoDecisionAttributes struct
+// from the provided intermediate representation.
+//
+//   x, err := binaryProtocol.Decode(reader, wire.TStruct)
+//   if err != nil {
+//     return nil, err
+//   }
+//
+//   var v UpsertWorkflowMemoDecisionAttributes
+//   if err := v.FromWire(x); err != nil {
+//     return nil, err
+//   }
+//   return &v, nil
+func (v *UpsertWorkflowMemoDecisionAttributes) FromWire(w wire.Value) error {
+	var err error
+
+	for _, field := range w.GetStruct().Fields {
+		switch field.ID {
+		case 10:
+			if field.Value.Type() == wire.TStruct {
+				v.Memo, err = _Memo_Read(field.Value)
+				if err != nil {
+					return err
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// String returns a readable string representation of a UpsertWorkflowMemoDecisionAttributes
+// struct.
+func (v *UpsertWorkflowMemoDecisionAttributes) String() string {
+	if v == nil {
+		return "<nil>"
+	}
+
+	var fields [1]string
+	i := 0
+	if v.Memo != nil {
+		fields[i] = fmt.Sprintf("Memo: %v", v.Memo)
+		i++
+	}
+
+	return fmt.Sprintf("UpsertWorkflowMemoDecisionAttributes{%v}", strings.Join(fields[:i], ", "))
+}
+
+// Equals returns true if all the fields of this UpsertWorkflowMemoDecisionAttributes match the
+// provided UpsertWorkflowMemoDecisionAttributes.
+//
+// This function performs a deep comparison.
+func (v *UpsertWorkflowMemoDecisionAttributes) Equals(rhs *UpsertWorkflowMemoDecisionAttributes) bool {
+	if v == nil {
+		return rhs == nil
+	} else if rhs == nil {
+		return false
+	}
+	if !((v.Memo == nil && rhs.Memo == nil) || (v.Memo != nil && rhs.Memo != nil && v.Memo.Equals(rhs.Memo))) {
+		return false
+	}
+
+	return true
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler, enabling
+// fast logging of UpsertWorkflowMemoDecisionAttributes.
+func (v *UpsertWorkflowMemoDecisionAttributes) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
+	if v == nil {
+		return nil
+	}
+	if v.Memo != nil {
+		err = multierr.Append(err, enc.AddObject("memo", v.Memo))
+	}
+	return err
+}
+
+// GetMemo returns the value of Memo if it is set or its
+// zero value if it is unset.
+func (v *UpsertWorkflowMemoDecisionAttributes) GetMemo() (o *Memo) {
+	if v != nil && v.Memo != nil {
+		return v.Memo
+	}
+
+	return
+}
+
+// IsSetMemo returns true if Memo is not nil.
+func (v *UpsertWorkflowMemoDecisionAttributes) IsSetMemo() bool {
+	return v != nil && v.Memo != nil
+}
+
+type UpsertWorkflowMemoEventAttributes struct {
+	DecisionTaskCompletedEventId *int64 `json:"decisionTaskCompletedEventId,omitempty"`
+	Memo                         *Memo  `json:"memo,omitempty"`
+}
+
+// ToWire translates a UpsertWorkflowMemoEventAttributes struct into a Thrift-level intermediate
+// representation. This intermediate representation may be serialized
+// into bytes using a ThriftRW protocol implementation.
+//
+// An error is returned if the struct or any of its fields failed to
+// validate.
+//
+//   x, err := v.ToWire()
+//   if err != nil {
+//     return err
+//   }
+//
+//   if err := binaryProtocol.Encode(x, writer); err != nil {
+//     return err
+//   }
+func (v *UpsertWorkflowMemoEventAttributes) ToWire() (wire.Value, error) {
+	var (
+		fields [2]wire.Field
+		i      int = 0
+		w      wire.Value
+		err    error
+	)
+
+	if v.DecisionTaskCompletedEventId != nil {
+		w, err = wire.NewValueI64(*(v.DecisionTaskCompletedEventId)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 10, Value: w}
+		i++
+	}
+	if v.Memo != nil {
+		w, err = v.Memo.ToWire()
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 20, Value: w}
+		i++
+	}
+
+	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
+}
+
+// FromWire deserializes a UpsertWorkflowMemoEventAttributes struct from its Thrift-level
+// representation. The Thrift-level representation may be obtained
+// from a ThriftRW protocol implementation.
+//
+// An error is returned if we were unable to build a UpsertWorkflowMemoEventAttributes struct
+// from the provided intermediate representation.
+//
+//   x, err := binaryProtocol.Decode(reader, wire.TStruct)
+//   if err != nil {
+//     return nil, err
+//   }
+//
+//   var v UpsertWorkflowMemoEventAttributes
+//   if err := v.FromWire(x); err != nil {
+//     return nil, err
+//   }
+//   return &v, nil
+func (v *UpsertWorkflowMemoEventAttributes) FromWire(w wire.Value) error {
+	var err error
+
+	for _, field := range w.GetStruct().Fields {
+		switch field.ID {
+		case 10:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.DecisionTaskCompletedEventId = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 20:
+			if field.Value.Type() == wire.TStruct {
+				v.Memo, err = _Memo_Read(field.Value)
+				if err != nil {
+					return err
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// String returns a readable string representation of a UpsertWorkflowMemoEventAttributes
+// struct.
+func (v *UpsertWorkflowMemoEventAttributes) String() string {
+	if v == nil {
+		return "<nil>"
+	}
+
+	var fields [2]string
+	i := 0
+	if v.DecisionTaskCompletedEventId != nil {
+		fields[i] = fmt.Sprintf("DecisionTaskCompletedEventId: %v", *(v.DecisionTaskCompletedEventId))
+		i++
+	}
+	if v.Memo != nil {
+		fields[i] = fmt.Sprintf("Memo: %v", v.Memo)
+		i++
+	}
+
+	return fmt.Sprintf("UpsertWorkflowMemoEventAttributes{%v}", strings.Join(fields[:i], ", "))
+}
+
+// Equals returns true if all the fields of this UpsertWorkflowMemoEventAttributes match the
+// provided UpsertWorkflowMemoEventAttributes.
+//
+// This function performs a deep comparison.
+func (v *UpsertWorkflowMemoEventAttributes) Equals(rhs *UpsertWorkflowMemoEventAttributes) bool {
+	if v == nil {
+		return rhs == nil
+	} else if rhs == nil {
+		return false
+	}
+	if !_I64_EqualsPtr(v.DecisionTaskCompletedEventId, rhs.DecisionTaskCompletedEventId) {
+		return false
+	}
+	if !((v.Memo == nil && rhs.Memo == nil) || (v.Memo != nil && rhs.Memo != nil && v.Memo.Equals(rhs.Memo))) {
+		return false
+	}
+
+	return true
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler, enabling
+// fast logging of UpsertWorkflowMemoEventAttributes.
+func (v *UpsertWorkflowMemoEventAttributes) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
+	if v == nil {
+		return nil
+	}
+	if v.DecisionTaskCompletedEventId != nil {
+		enc.AddInt64("decisionTaskCompletedEventId", *v.DecisionTaskCompletedEventId)
+	}
+	if v.Memo != nil {
+		err = multierr.Append(err, enc.AddObject("memo", v.Memo))
+	}
+	return err
+}
+
+// GetDecisionTaskCompletedEventId returns the value of DecisionTaskCompletedEventId if it is set or its
+// zero value if it is unset.
+func (v *UpsertWorkflowMemoEventAttributes) GetDecisionTaskCompletedEventId() (o int64) {
+	if v != nil && v.DecisionTaskCompletedEventId != nil {
+		return *v.DecisionTaskCompletedEventId
+	}
+
+	return
+}
+
+// IsSetDecisionTaskCompletedEventId returns true if DecisionTaskCompletedEventId is not nil.
+func (v *UpsertWorkflowMemoEventAttributes) IsSetDecisionTaskCompletedEventId() bool {
+	return v != nil && v.DecisionTaskCompletedEventId != nil
+}
+
+// GetMemo returns the value of Memo if it is set or its
+// zero value if it is unset.
+func (v *UpsertWorkflowMemoEventAttributes) GetMemo() (o *Memo) {
+	if v != nil && v.Memo != nil {
+		return v.Memo
+	}
+
+	return
+}
+
+// IsSetMemo returns true if Memo is not nil.
+func (v *UpsertWorkflowMemoEventAttributes) IsSetMemo() bool {
+	return v != nil && v.Memo != nil
+}
+
 type WorkflowExecution struct {
 	WorkflowId *string `json:"workflowId,omitempty"`
 	RunId      *string `json:"runId,omitempty"`
@@ -49851,9 +52148,11 @@ func (v *WorkflowExecutionInfo) IsSetAutoResetPoints() bool {
 }
 
 type WorkflowExecutionSignaledEventAttributes struct {
-	SignalName *string `json:"signalName,omitempty"`
-	Input      []byte  `json:"input,omitempty"`
-	Identity   *string `json:"identity,omitempty"`
+	SignalName          *string `json:"signalName,omitempty"`
+	Input               []byte  `json:"input,omitempty"`
+	Identity            *string `json:"identity,omitempty"`
+	Header              *Header `json:"header,omitempty"`
+	ExpirationTimestamp *int64  `json:"expirationTimestamp,omitempty"`
 }
 
 // ToWire translates a WorkflowExecutionSignaledEventAttributes struct into a Thrift-level intermediate
@@ -49873,7 +52172,7 @@ type WorkflowExecutionSignaledEventAttributes struct {
 //   }
 func (v *WorkflowExecutionSignaledEventAttributes) ToWire() (wire.Value, error) {
 	var (
-		fields [3]wire.Field
+		fields [5]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -49903,6 +52202,22 @@ func (v *WorkflowExecutionSignaledEventAttributes) ToWire() (wire.Value, error)
 		fields[i] = wire.Field{ID: 30, Value: w}
 		i++
 	}
+	if v.Header != nil {
+		w, err = v.Header.ToWire()
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 40, Value: w}
+		i++
+	}
+	if v.ExpirationTimestamp != nil {
+		w, err = wire.NewValueI64(*(v.ExpirationTimestamp)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 50, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -49956,6 +52271,24 @@ func (v *WorkflowExecutionSignaledEventAttributes) FromWire(w wire.Value) error
 					return err
 				}
 
+			}
+		case 40:
+			if field.Value.Type() == wire.TStruct {
+				v.Header, err = _Header_Read(field.Value)
+				if err != nil {
+					return err
+				}
+
+			}
+		case 50:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.ExpirationTimestamp = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -49970,7 +52303,7 @@ func (v *WorkflowExecutionSignaledEventAttributes) String() string {
 		return "<nil>"
 	}
 
-	var fields [3]string
+	var fields [5]string
 	i := 0
 	if v.SignalName != nil {
 		fields[i] = fmt.Sprintf("SignalName: %v", *(v.SignalName))
@@ -49984,6 +52317,14 @@ func (v *WorkflowExecutionSignaledEventAttributes) String() string {
 		fields[i] = fmt.Sprintf("Identity: %v", *(v.Identity))
 		i++
 	}
+	if v.Header != nil {
+		fields[i] = fmt.Sprintf("Header: %v", v.Header)
+		i++
+	}
+	if v.ExpirationTimestamp != nil {
+		fields[i] = fmt.Sprintf("ExpirationTimestamp: %v", *(v.ExpirationTimestamp))
+		i++
+	}
 
 	return fmt.Sprintf("WorkflowExecutionSignaledEventAttributes{%v}", strings.Join(fields[:i], ", "))
 }
@@ -50007,6 +52348,12 @@ func (v *WorkflowExecutionSignaledEventAttributes) Equals(rhs *WorkflowExecution
 	if !_String_EqualsPtr(v.Identity, rhs.Identity) {
 		return false
 	}
+	if !((v.Header == nil && rhs.Header == nil) || (v.Header != nil && rhs.Header != nil && v.Header.Equals(rhs.Header))) {
+		return false
+	}
+	if !_I64_EqualsPtr(v.ExpirationTimestamp, rhs.ExpirationTimestamp) {
+		return false
+	}
 
 	return true
 }
@@ -50026,6 +52373,12 @@ func (v *WorkflowExecutionSignaledEventAttributes) MarshalLogObject(enc zapcore.
 	if v.Identity != nil {
 		enc.AddString("identity", *v.Identity)
 	}
+	if v.Header != nil {
+		err = multierr.Append(err, enc.AddObject("header", v.Header))
+	}
+	if v.ExpirationTimestamp != nil {
+		enc.AddInt64("expirationTimestamp", *v.ExpirationTimestamp)
+	}
 	return err
 }
 
@@ -50074,6 +52427,36 @@ func (v *WorkflowExecutionSignaledEventAttributes) IsSetIdentity() bool {
 	return v != nil && v.Identity != nil
 }
 
+// GetHeader returns the value of Header if it is set or its
+// zero value if it is unset.
+func (v *WorkflowExecutionSignaledEventAttributes) GetHeader() (o *Header) {
+	if v != nil && v.Header != nil {
+		return v.Header
+	}
+
+	return
+}
+
+// IsSetHeader returns true if Header is not nil.
+func (v *WorkflowExecutionSignaledEventAttributes) IsSetHeader() bool {
+	return v != nil && v.Header != nil
+}
+
+// GetExpirationTimestamp returns the value of ExpirationTimestamp if it is set or its
+// zero value if it is unset.
+func (v *WorkflowExecutionSignaledEventAttributes) GetExpirationTimestamp() (o int64) {
+	if v != nil && v.ExpirationTimestamp != nil {
+		return *v.ExpirationTimestamp
+	}
+
+	return
+}
+
+// IsSetExpirationTimestamp returns true if ExpirationTimestamp is not nil.
+func (v *WorkflowExecutionSignaledEventAttributes) IsSetExpirationTimestamp() bool {
+	return v != nil && v.ExpirationTimestamp != nil
+}
+
 type WorkflowExecutionStartedEventAttributes struct {
 	WorkflowType                        *WorkflowType           `json:"workflowType,omitempty"`
 	ParentWorkflowDomain                *string                 `json:"parentWorkflowDomain,omitempty"`
@@ -51836,6 +54219,188 @@ func (v *WorkflowIdReusePolicy) UnmarshalJSON(text []byte) error {
 	}
 }
 
+type WorkflowExecutionTimeoutExtendedEventAttributes struct {
+	ExecutionStartToCloseTimeoutSeconds *int32  `json:"executionStartToCloseTimeoutSeconds,omitempty"`
+	Identity                            *string `json:"identity,omitempty"`
+}
+
+// ToWire translates a WorkflowExecutionTimeoutExtendedEventAttributes struct into a Thrift-level intermediate
+// representation. This intermediate representation may be serialized
+// into bytes using a ThriftRW protocol implementation.
+//
+// An error is returned if the struct or any of its fields failed to
+// validate.
+//
+//   x, err := v.ToWire()
+//   if err != nil {
+//     return err
+//   }
+//
+//   if err := binaryProtocol.Encode(x, writer); err != nil {
+//     return err
+//   }
+func (v *WorkflowExecutionTimeoutExtendedEventAttributes) ToWire() (wire.Value, error) {
+	var (
+		fields [2]wire.Field
+		i      int = 0
+		w      wire.Value
+		err    error
+	)
+
+	if v.ExecutionStartToCloseTimeoutSeconds != nil {
+		w, err = wire.NewValueI32(*(v.ExecutionStartToCloseTimeoutSeconds)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 10, Value: w}
+		i++
+	}
+	if v.Identity != nil {
+		w, err = wire.NewValueString(*(v.Identity)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 20, Value: w}
+		i++
+	}
+
+	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
+}
+
+// FromWire deserializes a WorkflowExecutionTimeoutExtendedEventAttributes struct from its Thrift-level
+// representation. The Thrift-level representation may be obtained
+// from a ThriftRW protocol implementation.
+//
+// An error is returned if we were unable to build a WorkflowExecutionTimeoutExtendedEventAttributes struct
+// from the provided intermediate representation.
+//
+//   x, err := binaryProtocol.Decode(reader, wire.TStruct)
+//   if err != nil {
+//     return nil, err
+//   }
+//
+//   var v WorkflowExecutionTimeoutExtendedEventAttributes
+//   if err := v.FromWire(x); err != nil {
+//     return nil, err
+//   }
+//   return &v, nil
+func (v *WorkflowExecutionTimeoutExtendedEventAttributes) FromWire(w wire.Value) error {
+	var err error
+
+	for _, field := range w.GetStruct().Fields {
+		switch field.ID {
+		case 10:
+			if field.Value.Type() == wire.TI32 {
+				var x int32
+				x, err = field.Value.GetI32(), error(nil)
+				v.ExecutionStartToCloseTimeoutSeconds = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 20:
+			if field.Value.Type() == wire.TBinary {
+				var x string
+				x, err = field.Value.GetString(), error(nil)
+				v.Identity = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// String returns a readable string representation of a WorkflowExecutionTimeoutExtendedEventAttributes
+// struct.
+func (v *WorkflowExecutionTimeoutExtendedEventAttributes) String() string {
+	if v == nil {
+		return "<nil>"
+	}
+
+	var fields [2]string
+	i := 0
+	if v.ExecutionStartToCloseTimeoutSeconds != nil {
+		fields[i] = fmt.Sprintf("ExecutionStartToCloseTimeoutSeconds: %v", *(v.ExecutionStartToCloseTimeoutSeconds))
+		i++
+	}
+	if v.Identity != nil {
+		fields[i] = fmt.Sprintf("Identity: %v", *(v.Identity))
+		i++
+	}
+
+	return fmt.Sprintf("WorkflowExecutionTimeoutExtendedEventAttributes{%v}", strings.Join(fields[:i], ", "))
+}
+
+// Equals returns true if all the fields of this WorkflowExecutionTimeoutExtendedEventAttributes match the
+// provided WorkflowExecutionTimeoutExtendedEventAttributes.
+//
+// This function performs a deep comparison.
+func (v *WorkflowExecutionTimeoutExtendedEventAttributes) Equals(rhs *WorkflowExecutionTimeoutExtendedEventAttributes) bool {
+	if v == nil {
+		return rhs == nil
+	} else if rhs == nil {
+		return false
+	}
+	if !_I32_EqualsPtr(v.ExecutionStartToCloseTimeoutSeconds, rhs.ExecutionStartToCloseTimeoutSeconds) {
+		return false
+	}
+	if !_String_EqualsPtr(v.Identity, rhs.Identity) {
+		return false
+	}
+
+	return true
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler, enabling
+// fast logging of WorkflowExecutionTimeoutExtendedEventAttributes.
+func (v *WorkflowExecutionTimeoutExtendedEventAttributes) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
+	if v == nil {
+		return nil
+	}
+	if v.ExecutionStartToCloseTimeoutSeconds != nil {
+		enc.AddInt32("executionStartToCloseTimeoutSeconds", *v.ExecutionStartToCloseTimeoutSeconds)
+	}
+	if v.Identity != nil {
+		enc.AddString("identity", *v.Identity)
+	}
+	return err
+}
+
+// GetExecutionStartToCloseTimeoutSeconds returns the value of ExecutionStartToCloseTimeoutSeconds if it is set or its
+// zero value if it is unset.
+func (v *WorkflowExecutionTimeoutExtendedEventAttributes) GetExecutionStartToCloseTimeoutSeconds() (o int32) {
+	if v != nil && v.ExecutionStartToCloseTimeoutSeconds != nil {
+		return *v.ExecutionStartToCloseTimeoutSeconds
+	}
+
+	return
+}
+
+// IsSetExecutionStartToCloseTimeoutSeconds returns true if ExecutionStartToCloseTimeoutSeconds is not nil.
+func (v *WorkflowExecutionTimeoutExtendedEventAttributes) IsSetExecutionStartToCloseTimeoutSeconds() bool {
+	return v != nil && v.ExecutionStartToCloseTimeoutSeconds != nil
+}
+
+// GetIdentity returns the value of Identity if it is set or its
+// zero value if it is unset.
+func (v *WorkflowExecutionTimeoutExtendedEventAttributes) GetIdentity() (o string) {
+	if v != nil && v.Identity != nil {
+		return *v.Identity
+	}
+
+	return
+}
+
+// IsSetIdentity returns true if Identity is not nil.
+func (v *WorkflowExecutionTimeoutExtendedEventAttributes) IsSetIdentity() bool {
+	return v != nil && v.Identity != nil
+}
+
 type WorkflowQuery struct {
 	QueryType *string `json:"queryType,omitempty"`
 	QueryArgs []byte  `json:"queryArgs,omitempty"`
@@ -63,6 +63,7 @@ type ActivityInfo struct {
 	RetryExpirationTimeNanos      *int64   `json:"retryExpirationTimeNanos,omitempty"`
 	RetryBackoffCoefficient       *float64 `json:"retryBackoffCoefficient,omitempty"`
 	RetryNonRetryableErrors       []string `json:"retryNonRetryableErrors,omitempty"`
+	LastFailureReason             *string  `json:"lastFailureReason,omitempty"`
 }
 
 type _List_String_ValueList []string
@@ -108,7 +109,7 @@ func (_List_String_ValueList) Close() {}
 //   }
 func (v *ActivityInfo) ToWire() (wire.Value, error) {
 	var (
-		fields [28]wire.Field
+		fields [29]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -338,6 +339,14 @@ func (v *ActivityInfo) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 64, Value: w}
 		i++
 	}
+	if v.LastFailureReason != nil {
+		w, err = wire.NewValueString(*(v.LastFailureReason)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 66, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -655,6 +664,16 @@ func (v *ActivityInfo) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 66:
+			if field.Value.Type() == wire.TBinary {
+				var x string
+				x, err = field.Value.GetString(), error(nil)
+				v.LastFailureReason = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -669,7 +688,7 @@ func (v *ActivityInfo) String() string {
 		return "<nil>"
 	}
 
-	var fields [28]string
+	var fields [29]string
 	i := 0
 	if v.Version != nil {
 		fields[i] = fmt.Sprintf("Version: %v", *(v.Version))
@@ -783,6 +802,10 @@ func (v *ActivityInfo) String() string {
 		fields[i] = fmt.Sprintf("RetryNonRetryableErrors: %v", v.RetryNonRetryableErrors)
 		i++
 	}
+	if v.LastFailureReason != nil {
+		fields[i] = fmt.Sprintf("LastFailureReason: %v", *(v.LastFailureReason))
+		i++
+	}
 
 	return fmt.Sprintf("ActivityInfo{%v}", strings.Join(fields[:i], ", "))
 }
@@ -946,6 +969,9 @@ func (v *ActivityInfo) Equals(rhs *ActivityInfo) bool {
 	if !((v.RetryNonRetryableErrors == nil && rhs.RetryNonRetryableErrors == nil) || (v.RetryNonRetryableErrors != nil && rhs.RetryNonRetryableErrors != nil && _List_String_Equals(v.RetryNonRetryableErrors, rhs.RetryNonRetryableErrors))) {
 		return false
 	}
+	if !_String_EqualsPtr(v.LastFailureReason, rhs.LastFailureReason) {
+		return false
+	}
 
 	return true
 }
@@ -1051,6 +1077,9 @@ func (v *ActivityInfo) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
 	if v.RetryNonRetryableErrors != nil {
 		err = multierr.Append(err, enc.AddArray("retryNonRetryableErrors", (_List_String_Zapper)(v.RetryNonRetryableErrors)))
 	}
+	if v.LastFailureReason != nil {
+		enc.AddString("lastFailureReason", *v.LastFailureReason)
+	}
 	return err
 }
 
@@ -1474,6 +1503,21 @@ func (v *ActivityInfo) IsSetRetryNonRetryableErrors() bool {
 	return v != nil && v.RetryNonRetryableErrors != nil
 }
 
+// GetLastFailureReason returns the value of LastFailureReason if it is set or its
+// zero value if it is unset.
+func (v *ActivityInfo) GetLastFailureReason() (o string) {
+	if v != nil && v.LastFailureReason != nil {
+		return *v.LastFailureReason
+	}
+
+	return
+}
+
+// IsSetLastFailureReason returns true if LastFailureReason is not nil.
+func (v *ActivityInfo) IsSetLastFailureReason() bool {
+	return v != nil && v.LastFailureReason != nil
+}
+
 type ChildExecutionInfo struct {
 	Version                *int64  `json:"version,omitempty"`
 	InitiatedEventBatchID  *int64  `json:"initiatedEventBatchID,omitempty"`
@@ -7290,62 +7334,66 @@ func (v *TransferTaskInfo) IsSetVisibilityTimestampNanos() bool {
 }
 
 type WorkflowExecutionInfo struct {
-	ParentDomainID                  []byte                      `json:"parentDomainID,omitempty"`
-	ParentWorkflowID                *string                     `json:"parentWorkflowID,omitempty"`
-	ParentRunID                     []byte                      `json:"parentRunID,omitempty"`
-	InitiatedID                     *int64                      `json:"initiatedID,omitempty"`
-	CompletionEventBatchID          *int64                      `json:"completionEventBatchID,omitempty"`
-	CompletionEvent                 []byte                      `json:"completionEvent,omitempty"`
-	CompletionEventEncoding         *string                     `json:"completionEventEncoding,omitempty"`
-	TaskList                        *string                     `json:"taskList,omitempty"`
-	WorkflowTypeName                *string                     `json:"workflowTypeName,omitempty"`
-	WorkflowTimeoutSeconds          *int32                      `json:"workflowTimeoutSeconds,omitempty"`
-	DecisionTaskTimeoutSeconds      *int32                      `json:"decisionTaskTimeoutSeconds,omitempty"`
-	ExecutionContext                []byte                      `json:"executionContext,omitempty"`
-	State                           *int32                      `json:"state,omitempty"`
-	CloseStatus                     *int32                      `json:"closeStatus,omitempty"`
-	StartVersion                    *int64                      `json:"startVersion,omitempty"`
-	CurrentVersion                  *int64                      `json:"currentVersion,omitempty"`
-	LastWriteEventID                *int64                      `json:"lastWriteEventID,omitempty"`
-	LastReplicationInfo             map[string]*ReplicationInfo `json:"lastReplicationInfo,omitempty"`
-	LastEventTaskID                 *int64                      `json:"lastEventTaskID,omitempty"`
-	LastFirstEventID                *int64                      `json:"lastFirstEventID,omitempty"`
-	LastProcessedEvent              *int64                      `json:"lastProcessedEvent,omitempty"`
-	StartTimeNanos                  *int64                      `json:"startTimeNanos,omitempty"`
-	LastUpdatedTimeNanos            *int64                      `json:"lastUpdatedTimeNanos,omitempty"`
-	DecisionVersion                 *int64                      `json:"decisionVersion,omitempty"`
-	DecisionScheduleID              *int64                      `json:"decisionScheduleID,omitempty"`
-	DecisionStartedID               *int64                      `json:"decisionStartedID,omitempty"`
-	DecisionTimeout                 *int32                      `json:"decisionTimeout,omitempty"`
-	DecisionAttempt                 *int64                      `json:"decisionAttempt,omitempty"`
-	DecisionStartedTimestampNanos   *int64                      `json:"decisionStartedTimestampNanos,omitempty"`
-	DecisionScheduledTimestampNanos *int64                      `json:"decisionScheduledTimestampNanos,omitempty"`
-	CancelRequested                 *bool                       `json:"cancelRequested,omitempty"`
-	CreateRequestID                 *string                     `json:"createRequestID,omitempty"`
-	DecisionRequestID               *string                     `json:"decisionRequestID,omitempty"`
-	CancelRequestID                 *string                     `json:"cancelRequestID,omitempty"`
-	StickyTaskList                  *string                     `json:"stickyTaskList,omitempty"`
-	StickyScheduleToStartTimeout    *int64                      `json:"stickyScheduleToStartTimeout,omitempty"`
-	RetryAttempt                    *int64                      `json:"retryAttempt,omitempty"`
-	RetryInitialIntervalSeconds     *int32                      `json:"retryInitialIntervalSeconds,omitempty"`
-	RetryMaximumIntervalSeconds     *int32                      `json:"retryMaximumIntervalSeconds,omitempty"`
-	RetryMaximumAttempts            *int32                      `json:"retryMaximumAttempts,omitempty"`
-	RetryExpirationSeconds          *int32                      `json:"retryExpirationSeconds,omitempty"`
-	RetryBackoffCoefficient         *float64                    `json:"retryBackoffCoefficient,omitempty"`
-	RetryExpirationTimeNanos        *int64                      `json:"retryExpirationTimeNanos,omitempty"`
-	RetryNonRetryableErrors         []string                    `json:"retryNonRetryableErrors,omitempty"`
-	HasRetryPolicy                  *bool                       `json:"hasRetryPolicy,omitempty"`
-	CronSchedule                    *string                     `json:"cronSchedule,omitempty"`
-	EventStoreVersion               *int32                      `json:"eventStoreVersion,omitempty"`
-	EventBranchToken                []byte                      `json:"eventBranchToken,omitempty"`
-	SignalCount                     *int64                      `json:"signalCount,omitempty"`
-	HistorySize                     *int64                      `json:"historySize,omitempty"`
-	ClientLibraryVersion            *string                     `json:"clientLibraryVersion,omitempty"`
-	ClientFeatureVersion            *string                     `json:"clientFeatureVersion,omitempty"`
-	ClientImpl                      *string                     `json:"clientImpl,omitempty"`
-	AutoResetPoints                 []byte                      `json:"autoResetPoints,omitempty"`
-	AutoResetPointsEncoding         *string                     `json:"autoResetPointsEncoding,omitempty"`
-	SearchAttributes                map[string][]byte           `json:"searchAttributes,omitempty"`
+	ParentDomainID                          []byte                      `json:"parentDomainID,omitempty"`
+	ParentWorkflowID                        *string                     `json:"parentWorkflowID,omitempty"`
+	ParentRunID                             []byte                      `json:"parentRunID,omitempty"`
+	InitiatedID                             *int64                      `json:"initiatedID,omitempty"`
+	CompletionEventBatchID                  *int64                      `json:"completionEventBatchID,omitempty"`
+	CompletionEvent                         []byte                      `json:"completionEvent,omitempty"`
+	CompletionEventEncoding                 *string                     `json:"completionEventEncoding,omitempty"`
+	TaskList                                *string                     `json:"taskList,omitempty"`
+	WorkflowTypeName                        *string                     `json:"workflowTypeName,omitempty"`
+	WorkflowTimeoutSeconds                  *int32                      `json:"workflowTimeoutSeconds,omitempty"`
+	DecisionTaskTimeoutSeconds              *int32                      `json:"decisionTaskTimeoutSeconds,omitempty"`
+	ExecutionContext                        []byte                      `json:"executionContext,omitempty"`
+	State                                   *int32                      `json:"state,omitempty"`
+	CloseStatus                             *int32                      `json:"closeStatus,omitempty"`
+	StartVersion                            *int64                      `json:"startVersion,omitempty"`
+	CurrentVersion                          *int64                      `json:"currentVersion,omitempty"`
+	LastWriteEventID                        *int64                      `json:"lastWriteEventID,omitempty"`
+	LastReplicationInfo                     map[string]*ReplicationInfo `json:"lastReplicationInfo,omitempty"`
+	LastEventTaskID                         *int64                      `json:"lastEventTaskID,omitempty"`
+	LastFirstEventID                        *int64                      `json:"lastFirstEventID,omitempty"`
+	LastProcessedEvent                      *int64                      `json:"lastProcessedEvent,omitempty"`
+	StartTimeNanos                          *int64                      `json:"startTimeNanos,omitempty"`
+	LastUpdatedTimeNanos                    *int64                      `json:"lastUpdatedTimeNanos,omitempty"`
+	DecisionVersion                         *int64                      `json:"decisionVersion,omitempty"`
+	DecisionScheduleID                      *int64                      `json:"decisionScheduleID,omitempty"`
+	DecisionStartedID                       *int64                      `json:"decisionStartedID,omitempty"`
+	DecisionTimeout                         *int32                      `json:"decisionTimeout,omitempty"`
+	DecisionAttempt                         *int64                      `json:"decisionAttempt,omitempty"`
+	DecisionStartedTimestampNanos           *int64                      `json:"decisionStartedTimestampNanos,omitempty"`
+	DecisionScheduledTimestampNanos         *int64                      `json:"decisionScheduledTimestampNanos,omitempty"`
+	CancelRequested                         *bool                       `json:"cancelRequested,omitempty"`
+	CreateRequestID                         *string                     `json:"createRequestID,omitempty"`
+	DecisionRequestID                       *string                     `json:"decisionRequestID,omitempty"`
+	CancelRequestID                         *string                     `json:"cancelRequestID,omitempty"`
+	StickyTaskList                          *string                     `json:"stickyTaskList,omitempty"`
+	StickyScheduleToStartTimeout            *int64                      `json:"stickyScheduleToStartTimeout,omitempty"`
+	RetryAttempt                            *int64                      `json:"retryAttempt,omitempty"`
+	RetryInitialIntervalSeconds             *int32                      `json:"retryInitialIntervalSeconds,omitempty"`
+	RetryMaximumIntervalSeconds             *int32                      `json:"retryMaximumIntervalSeconds,omitempty"`
+	RetryMaximumAttempts                    *int32                      `json:"retryMaximumAttempts,omitempty"`
+	RetryExpirationSeconds                  *int32                      `json:"retryExpirationSeconds,omitempty"`
+	RetryBackoffCoefficient                 *float64                    `json:"retryBackoffCoefficient,omitempty"`
+	RetryExpirationTimeNanos                *int64                      `json:"retryExpirationTimeNanos,omitempty"`
+	RetryNonRetryableErrors                 []string                    `json:"retryNonRetryableErrors,omitempty"`
+	HasRetryPolicy                          *bool                       `json:"hasRetryPolicy,omitempty"`
+	CronSchedule                            *string                     `json:"cronSchedule,omitempty"`
+	EventStoreVersion                       *int32                      `json:"eventStoreVersion,omitempty"`
+	EventBranchToken                        []byte                      `json:"eventBranchToken,omitempty"`
+	SignalCount                             *int64                      `json:"signalCount,omitempty"`
+	HistorySize                             *int64                      `json:"historySize,omitempty"`
+	ClientLibraryVersion                    *string                     `json:"clientLibraryVersion,omitempty"`
+	ClientFeatureVersion                    *string                     `json:"clientFeatureVersion,omitempty"`
+	ClientImpl                              *string                     `json:"clientImpl,omitempty"`
+	AutoResetPoints                         []byte                      `json:"autoResetPoints,omitempty"`
+	AutoResetPointsEncoding                 *string                     `json:"autoResetPointsEncoding,omitempty"`
+	SearchAttributes                        map[string][]byte           `json:"searchAttributes,omitempty"`
+	CloseTimestampNanos                     *int64                      `json:"closeTimestampNanos,omitempty"`
+	Memo                                    map[string][]byte           `json:"memo,omitempty"`
+	SignalRequestedTimestamps               map[string]int64            `json:"signalRequestedTimestamps,omitempty"`
+	DecisionOriginalScheduledTimestampNanos *int64                      `json:"decisionOriginalScheduledTimestampNanos,omitempty"`
 }
 
 type _Map_String_Binary_MapItemList map[string][]byte
@@ -7403,7 +7451,7 @@ func (_Map_String_Binary_MapItemList) Close() {}
 //   }
 func (v *WorkflowExecutionInfo) ToWire() (wire.Value, error) {
 	var (
-		fields [56]wire.Field
+		fields [60]wire.Field
 		i      int = 0
 		w      wire.Value
 		err    error
@@ -7857,6 +7905,38 @@ func (v *WorkflowExecutionInfo) ToWire() (wire.Value, error) {
 		fields[i] = wire.Field{ID: 118, Value: w}
 		i++
 	}
+	if v.CloseTimestampNanos != nil {
+		w, err = wire.NewValueI64(*(v.CloseTimestampNanos)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 120, Value: w}
+		i++
+	}
+	if v.Memo != nil {
+		w, err = wire.NewValueMap(_Map_String_Binary_MapItemList(v.Memo)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 122, Value: w}
+		i++
+	}
+	if v.SignalRequestedTimestamps != nil {
+		w, err = wire.NewValueMap(_Map_String_I64_MapItemList(v.SignalRequestedTimestamps)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 124, Value: w}
+		i++
+	}
+	if v.DecisionOriginalScheduledTimestampNanos != nil {
+		w, err = wire.NewValueI64(*(v.DecisionOriginalScheduledTimestampNanos)), error(nil)
+		if err != nil {
+			return w, err
+		}
+		fields[i] = wire.Field{ID: 126, Value: w}
+		i++
+	}
 
 	return wire.NewValueStruct(wire.Struct{Fields: fields[:i]}), nil
 }
@@ -8452,6 +8532,42 @@ func (v *WorkflowExecutionInfo) FromWire(w wire.Value) error {
 					return err
 				}
 
+			}
+		case 120:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.CloseTimestampNanos = &x
+				if err != nil {
+					return err
+				}
+
+			}
+		case 122:
+			if field.Value.Type() == wire.TMap {
+				v.Memo, err = _Map_String_Binary_Read(field.Value.GetMap())
+				if err != nil {
+					return err
+				}
+
+			}
+		case 124:
+			if field.Value.Type() == wire.TMap {
+				v.SignalRequestedTimestamps, err = _Map_String_I64_Read(field.Value.GetMap())
+				if err != nil {
+					return err
+				}
+
+			}
+		case 126:
+			if field.Value.Type() == wire.TI64 {
+				var x int64
+				x, err = field.Value.GetI64(), error(nil)
+				v.DecisionOriginalScheduledTimestampNanos = &x
+				if err != nil {
+					return err
+				}
+
 			}
 		}
 	}
@@ -8466,7 +8582,7 @@ func (v *WorkflowExecutionInfo) String() string {
 		return "<nil>"
 	}
 
-	var fields [56]string
+	var fields [60]string
 	i := 0
 	if v.ParentDomainID != nil {
 		fields[i] = fmt.Sprintf("ParentDomainID: %v", v.ParentDomainID)
@@ -8692,6 +8808,22 @@ func (v *WorkflowExecutionInfo) String() string {
 		fields[i] = fmt.Sprintf("SearchAttributes: %v", v.SearchAttributes)
 		i++
 	}
+	if v.CloseTimestampNanos != nil {
+		fields[i] = fmt.Sprintf("CloseTimestampNanos: %v", *(v.CloseTimestampNanos))
+		i++
+	}
+	if v.Memo != nil {
+		fields[i] = fmt.Sprintf("Memo: %v", v.Memo)
+		i++
+	}
+	if v.SignalRequestedTimestamps != nil {
+		fields[i] = fmt.Sprintf("SignalRequestedTimestamps: %v", v.SignalRequestedTimestamps)
+		i++
+	}
+	if v.DecisionOriginalScheduledTimestampNanos != nil {
+		fields[i] = fmt.Sprintf("DecisionOriginalScheduledTimestampNanos: %v", *(v.DecisionOriginalScheduledTimestampNanos))
+		i++
+	}
 
 	return fmt.Sprintf("WorkflowExecutionInfo{%v}", strings.Join(fields[:i], ", "))
 }
@@ -8891,6 +9023,18 @@ func (v *WorkflowExecutionInfo) Equals(rhs *WorkflowExecutionInfo) bool {
 	if !((v.SearchAttributes == nil && rhs.SearchAttributes == nil) || (v.SearchAttributes != nil && rhs.SearchAttributes != nil && _Map_String_Binary_Equals(v.SearchAttributes, rhs.SearchAttributes))) {
 		return false
 	}
+	if !_I64_EqualsPtr(v.CloseTimestampNanos, rhs.CloseTimestampNanos) {
+		return false
+	}
+	if !((v.Memo == nil && rhs.Memo == nil) || (v.Memo != nil && rhs.Memo != nil && _Map_String_Binary_Equals(v.Memo, rhs.Memo))) {
+		return false
+	}
+	if !((v.SignalRequestedTimestamps == nil && rhs.SignalRequestedTimestamps == nil) || (v.SignalRequestedTimestamps != nil && rhs.SignalRequestedTimestamps != nil && _Map_String_I64_Equals(v.SignalRequestedTimestamps, rhs.SignalRequestedTimestamps))) {
+		return false
+	}
+	if !_I64_EqualsPtr(v.DecisionOriginalScheduledTimestampNanos, rhs.DecisionOriginalScheduledTimestampNanos) {
+		return false
+	}
 
 	return true
 }
@@ -9080,6 +9224,18 @@ func (v *WorkflowExecutionInfo) MarshalLogObject(enc zapcore.ObjectEncoder) (err
 	if v.SearchAttributes != nil {
 		err = multierr.Append(err, enc.AddObject("searchAttributes", (_Map_String_Binary_Zapper)(v.SearchAttributes)))
 	}
+	if v.CloseTimestampNanos != nil {
+		enc.AddInt64("closeTimestampNanos", *v.CloseTimestampNanos)
+	}
+	if v.Memo != nil {
+		err = multierr.Append(err, enc.AddObject("memo", (_Map_String_Binary_Zapper)(v.Memo)))
+	}
+	if v.SignalRequestedTimestamps != nil {
+		err = multierr.Append(err, enc.AddObject("signalRequestedTimestamps", (_Map_String_I64_Zapper)(v.SignalRequestedTimestamps)))
+	}
+	if v.DecisionOriginalScheduledTimestampNanos != nil {
+		enc.AddInt64("decisionOriginalScheduledTimestampNanos", *v.DecisionOriginalScheduledTimestampNanos)
+	}
 	return err
 }
 
@@ -9922,3 +10078,63 @@ func (v *WorkflowExecutionInfo) GetSearchAttributes() (o map[string][]byte) {
 func (v *WorkflowExecutionInfo) IsSetSearchAttributes() bool {
 	return v != nil && v.SearchAttributes != nil
 }
+
+// GetCloseTimestampNanos returns the value of CloseTimestampNanos if it is set or its
+// zero value if it is unset.
+func (v *WorkflowExecutionInfo) GetCloseTimestampNanos() (o int64) {
+	if v != nil && v.CloseTimestampNanos != nil {
+		return *v.CloseTimestampNanos
+	}
+
+	return
+}
+
+// IsSetCloseTimestampNanos returns true if CloseTimestampNanos is not nil.
+func (v *WorkflowExecutionInfo) IsSetCloseTimestampNanos() bool {
+	return v != nil && v.CloseTimestampNanos != nil
+}
+
+// GetMemo returns the value of Memo if it is set or its
+// zero value if it is unset.
+func (v *WorkflowExecutionInfo) GetMemo() (o map[string][]byte) {
+	if v != nil && v.Memo != nil {
+		return v.Memo
+	}
+
+	return
+}
+
+// IsSetMemo returns true if Memo is not nil.
+func (v *WorkflowExecutionInfo) IsSetMemo() bool {
+	return v != nil && v.Memo != nil
+}
+
+// GetSignalRequestedTimestamps returns the value of SignalRequestedTimestamps if it is set or its
+// zero value if it is unset.
+func (v *WorkflowExecutionInfo) GetSignalRequestedTimestamps() (o map[string]int64) {
+	if v != nil && v.SignalRequestedTimestamps != nil {
+		return v.SignalRequestedTimestamps
+	}
+
+	return
+}
+
+// IsSetSignalRequestedTimestamps returns true if SignalRequestedTimestamps is not nil.
+func (v *WorkflowExecutionInfo) IsSetSignalRequestedTimestamps() bool {
+	return v != nil && v.SignalRequestedTimestamps != nil
+}
+
+// GetDecisionOriginalScheduledTimestampNanos returns the value of DecisionOriginalScheduledTimestampNanos if it is set or its
+// zero value if it is unset.
+func (v *WorkflowExecutionInfo) GetDecisionOriginalScheduledTimestampNanos() (o int64) {
+	if v != nil && v.DecisionOriginalScheduledTimestampNanos != nil {
+		return *v.DecisionOriginalScheduledTimestampNanos
+	}
+
+	return
+}
+
+// IsSetDecisionOriginalScheduledTimestampNanos returns true if DecisionOriginalScheduledTimestampNanos is not nil.
+func (v *WorkflowExecutionInfo) IsSetDecisionOriginalScheduledTimestampNanos() bool {
+	return v != nil && v.DecisionOriginalScheduledTimestampNanos != nil
+}
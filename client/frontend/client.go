@@ -236,6 +236,22 @@ func (c *clientImpl) CountWorkflowExecutions(
 	return client.CountWorkflowExecutions(ctx, request, opts...)
 }
 
+func (c *clientImpl) GetDomainWorkflowStateCounts(
+	ctx context.Context,
+	request *shared.GetDomainWorkflowStateCountsRequest,
+	opts ...yarpc.CallOption,
+) (*shared.GetDomainWorkflowStateCountsResponse, error) {
+
+	opts = common.AggregateYarpcOptions(ctx, opts...)
+	client, err := c.getRandomClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := c.createContext(ctx)
+	defer cancel()
+	return client.GetDomainWorkflowStateCounts(ctx, request, opts...)
+}
+
 func (c *clientImpl) GetSearchAttributes(
 	ctx context.Context,
 	opts ...yarpc.CallOption,
@@ -240,6 +240,24 @@ func (c *metricClient) CountWorkflowExecutions(
 	return resp, err
 }
 
+func (c *metricClient) GetDomainWorkflowStateCounts(
+	ctx context.Context,
+	request *shared.GetDomainWorkflowStateCountsRequest,
+	opts ...yarpc.CallOption,
+) (*shared.GetDomainWorkflowStateCountsResponse, error) {
+
+	c.metricsClient.IncCounter(metrics.FrontendGetDomainWorkflowStateCountsScope, metrics.CadenceClientRequests)
+
+	sw := c.metricsClient.StartTimer(metrics.FrontendGetDomainWorkflowStateCountsScope, metrics.CadenceClientLatency)
+	resp, err := c.client.GetDomainWorkflowStateCounts(ctx, request, opts...)
+	sw.Stop()
+
+	if err != nil {
+		c.metricsClient.IncCounter(metrics.FrontendGetDomainWorkflowStateCountsScope, metrics.CadenceClientFailures)
+	}
+	return resp, err
+}
+
 func (c *metricClient) GetSearchAttributes(
 	ctx context.Context,
 	opts ...yarpc.CallOption,
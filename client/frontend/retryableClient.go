@@ -217,6 +217,22 @@ func (c *retryableClient) CountWorkflowExecutions(
 	return resp, err
 }
 
+func (c *retryableClient) GetDomainWorkflowStateCounts(
+	ctx context.Context,
+	request *shared.GetDomainWorkflowStateCountsRequest,
+	opts ...yarpc.CallOption,
+) (*shared.GetDomainWorkflowStateCountsResponse, error) {
+
+	var resp *shared.GetDomainWorkflowStateCountsResponse
+	op := func() error {
+		var err error
+		resp, err = c.client.GetDomainWorkflowStateCounts(ctx, request, opts...)
+		return err
+	}
+	err := backoff.Retry(op, c.policy, c.isRetryable)
+	return resp, err
+}
+
 func (c *retryableClient) GetSearchAttributes(
 	ctx context.Context,
 	opts ...yarpc.CallOption,
@@ -31,6 +31,9 @@ import (
 // NoBackoff is used to represent backoff when no cron backoff is needed
 const NoBackoff = time.Duration(-1)
 
+// cronIntervalSampleSize is the number of consecutive runs checked when validating the interval of a cron schedule
+const cronIntervalSampleSize = 10
+
 // ValidateSchedule validates a cron schedule spec
 func ValidateSchedule(cronSchedule string) error {
 	if cronSchedule == "" {
@@ -42,6 +45,59 @@ func ValidateSchedule(cronSchedule string) error {
 	return nil
 }
 
+// ValidateScheduleInterval validates a cron schedule spec and rejects schedules whose consecutive runs
+// would be less than minInterval apart
+func ValidateScheduleInterval(cronSchedule string, minInterval time.Duration) error {
+	if err := ValidateSchedule(cronSchedule); err != nil || cronSchedule == "" || minInterval <= 0 {
+		return err
+	}
+
+	if interval := getScheduleInterval(cronSchedule); interval > 0 && interval < minInterval {
+		return &workflow.BadRequestError{Message: "CronSchedule interval is shorter than the minimum allowed."}
+	}
+	return nil
+}
+
+// ValidateScheduleWithRetryPolicy rejects a cron schedule combined with a retry policy whose expiration interval
+// is longer than the interval between consecutive cron runs. A failed cron workflow is retried according to its
+// retry policy before falling back to the cron schedule, so such retries would run past the next scheduled run.
+func ValidateScheduleWithRetryPolicy(cronSchedule string, policy *workflow.RetryPolicy) error {
+	if cronSchedule == "" || policy == nil || policy.GetExpirationIntervalInSeconds() <= 0 {
+		return nil
+	}
+
+	expiration := time.Duration(policy.GetExpirationIntervalInSeconds()) * time.Second
+	if interval := getScheduleInterval(cronSchedule); interval > 0 && expiration > interval {
+		return &workflow.BadRequestError{
+			Message: "ExpirationIntervalInSeconds on retry policy cannot be longer than the CronSchedule interval.",
+		}
+	}
+	return nil
+}
+
+// getScheduleInterval returns the shortest interval between the upcoming consecutive runs of a cron schedule,
+// or 0 if the schedule cannot be used by GetBackoffForNextSchedule
+func getScheduleInterval(cronSchedule string) time.Duration {
+	schedule, err := cron.ParseStandard(cronSchedule)
+	if err != nil {
+		return 0
+	}
+
+	interval := time.Duration(0)
+	next := schedule.Next(time.Now().In(time.UTC))
+	for i := 0; i < cronIntervalSampleSize && !next.IsZero(); i++ {
+		following := schedule.Next(next)
+		if following.IsZero() {
+			break
+		}
+		if current := following.Sub(next); interval == 0 || current < interval {
+			interval = current
+		}
+		next = following
+	}
+	return interval
+}
+
 // GetBackoffForNextSchedule calculates the backoff time for the next run given
 // a cronSchedule and current time
 func GetBackoffForNextSchedule(cronSchedule string, nowTime time.Time) time.Duration {
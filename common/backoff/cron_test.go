@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	workflow "github.com/uber/cadence/.gen/go/shared"
 )
 
 func Test_NextCronSchedule(t *testing.T) {
@@ -59,3 +60,32 @@ func Test_NextCronSchedule(t *testing.T) {
 	backoff = GetBackoffForNextSchedule(cronSpec, now)
 	a.Equal(NoBackoff, backoff)
 }
+
+func Test_ValidateScheduleInterval(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(ValidateScheduleInterval("", time.Minute))
+	a.NoError(ValidateScheduleInterval("* * * * *", time.Minute))
+	a.NoError(ValidateScheduleInterval("@every 1h", time.Minute))
+	a.NoError(ValidateScheduleInterval("@every 1s", 0))
+	a.Error(ValidateScheduleInterval("@every 30s", time.Minute))
+	a.Error(ValidateScheduleInterval("@every 0s", 5*time.Second))
+	a.Error(ValidateScheduleInterval("* * * * *", 2*time.Minute))
+	a.Error(ValidateScheduleInterval("invalid-cron-spec", time.Minute))
+}
+
+func Test_ValidateScheduleWithRetryPolicy(t *testing.T) {
+	a := assert.New(t)
+
+	policy := func(expirationInSeconds int32) *workflow.RetryPolicy {
+		return &workflow.RetryPolicy{ExpirationIntervalInSeconds: &expirationInSeconds}
+	}
+
+	a.NoError(ValidateScheduleWithRetryPolicy("", policy(3600)))
+	a.NoError(ValidateScheduleWithRetryPolicy("@every 1h", nil))
+	a.NoError(ValidateScheduleWithRetryPolicy("@every 1h", policy(0)))
+	a.NoError(ValidateScheduleWithRetryPolicy("@every 1h", policy(3600)))
+	a.NoError(ValidateScheduleWithRetryPolicy("0 * * * *", policy(60)))
+	a.Error(ValidateScheduleWithRetryPolicy("@every 1h", policy(3601)))
+	a.Error(ValidateScheduleWithRetryPolicy("* * * * *", policy(120)))
+}
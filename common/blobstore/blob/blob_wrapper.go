@@ -40,6 +40,8 @@ const (
 const (
 	// JSONEncoding indicates blob was encoded as JSON
 	JSONEncoding = "json"
+	// GzipJSONEncoding indicates blob was encoded as JSON and is expected to also carry a gzip compression layer
+	GzipJSONEncoding = "json-gzip"
 )
 
 // the following constants are all compressions that have ever been used, names should describe the package used
@@ -62,12 +64,22 @@ type (
 
 // JSONEncoded returns a WrapFn used to indicate that at the encoding layer json was used
 func JSONEncoded() WrapFn {
+	return encoded(JSONEncoding)
+}
+
+// GzipJSONEncoded returns a WrapFn used to indicate that at the encoding layer json was used for a blob that is gzip compressed.
+// It only tags the encoding layer, GzipCompressed must also be applied to actually compress the body.
+func GzipJSONEncoded() WrapFn {
+	return encoded(GzipJSONEncoding)
+}
+
+func encoded(encodingFormat string) WrapFn {
 	return func(b *Blob) error {
 		wrappers := common.StringPtr(b.Tags[wrappersTag])
 		if exists(wrappers, encodingKey) {
 			return errors.New("encoding layer already specified")
 		}
-		push(wrappers, encodingKey, JSONEncoding)
+		push(wrappers, encodingKey, encodingFormat)
 		b.Tags[wrappersTag] = *wrappers
 		return nil
 	}
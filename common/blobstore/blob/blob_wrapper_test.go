@@ -90,6 +90,25 @@ func (s *BlobWrapperSuite) TestJSONEncodedWrapFn() {
 	}
 }
 
+func (s *BlobWrapperSuite) TestGzipJSONEncodedWrapFn() {
+	wrapFn := GzipJSONEncoded()
+	blob := NewBlob([]byte("test-body"), map[string]string{wrappersTag: "encoding:exists,"})
+	s.Error(wrapFn(blob))
+
+	blob = NewBlob([]byte("test-body"), map[string]string{})
+	s.NoError(wrapFn(blob))
+	s.Equal(map[string]string{wrappersTag: "encoding:json-gzip,"}, blob.Tags)
+	s.Equal([]byte("test-body"), blob.Body)
+
+	wrappedBlob, err := Wrap(NewBlob([]byte("test-body"), map[string]string{}), GzipJSONEncoded(), GzipCompressed())
+	s.NoError(err)
+	unwrappedBlob, wrappingLayers, err := Unwrap(wrappedBlob)
+	s.NoError(err)
+	s.Equal([]byte("test-body"), unwrappedBlob.Body)
+	s.Equal(GzipJSONEncoding, *wrappingLayers.EncodingFormat)
+	s.Equal(GzipCompression, *wrappingLayers.Compression)
+}
+
 func (s *BlobWrapperSuite) TestGzipCompressedWrapFn() {
 	testCases := []struct {
 		inputTags   map[string]string
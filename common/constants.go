@@ -98,6 +98,12 @@ const (
 	DefaultAdminOperationToken = "CadenceTeamONLY"
 )
 
+const (
+	// ArchivalRequestedMemoKey is the memo key a workflow sets to true to have its history archived
+	// when its domain only archives workflows on request
+	ArchivalRequestedMemoKey = "CadenceArchivalRequested"
+)
+
 const (
 	// MinLongPollTimeout is the minimum context timeout for long poll API, below which
 	// the request won't be processed
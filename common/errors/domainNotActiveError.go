@@ -40,3 +40,18 @@ func NewDomainNotActiveError(domainName string, currentCluster string, activeClu
 		ActiveCluster:  activeCluster,
 	}
 }
+
+// NewDomainDrainingError return a domain draining error, returned when a domain being decommissioned rejects
+// starting new workflows
+func NewDomainDrainingError(domainName string, currentCluster string) *workflow.DomainNotActiveError {
+	return &workflow.DomainNotActiveError{
+		Message: fmt.Sprintf(
+			"Domain: %s is draining in cluster: %s, new workflows are not accepted.",
+			domainName,
+			currentCluster,
+		),
+		DomainName:     domainName,
+		CurrentCluster: currentCluster,
+		ActiveCluster:  currentCluster,
+	}
+}
@@ -325,6 +325,16 @@ func Address(ad string) Tag {
 	return newStringTag("address", ad)
 }
 
+// Operator returns tag for Operator
+func Operator(operator string) Tag {
+	return newStringTag("operator", operator)
+}
+
+// Operation returns tag for Operation
+func Operation(operation string) Tag {
+	return newStringTag("operation", operation)
+}
+
 // Key returns tag for Key
 func Key(k string) Tag {
 	return newStringTag("key", k)
@@ -584,6 +594,11 @@ func SourceCluster(sourceCluster string) Tag {
 	return newStringTag("xdc-source-cluster", sourceCluster)
 }
 
+// ClusterClockSkew returns tag for ClusterClockSkew
+func ClusterClockSkew(skew time.Duration) Tag {
+	return newDurationTag("xdc-cluster-clock-skew", skew)
+}
+
 // PrevActiveCluster returns tag for PrevActiveCluster
 func PrevActiveCluster(prevActiveCluster string) Tag {
 	return newStringTag("xdc-prev-active-cluster", prevActiveCluster)
@@ -30,11 +30,13 @@ var (
 	WorkflowActionWorkflowTimeout       = workflowAction("add-workflow-timeout-event")
 	WorkflowActionWorkflowTerminated    = workflowAction("add-workflow-terminated-event")
 	WorkflowActionWorkflowContinueAsNew = workflowAction("add-workflow-continue-as-new-event")
+	WorkflowActionWorkflowTimeoutExtend = workflowAction("add-workflow-timeout-extended-event")
 
 	// workflow cancellation / sign
 	WorkflowActionWorkflowCancelRequested = workflowAction("add-workflow-cancel-requested-event")
 	WorkflowActionWorkflowSignaled        = workflowAction("add-workflow-signaled-event")
 	WorkflowActionWorkflowRecordMarker    = workflowAction("add-workflow-marker-record-event")
+	WorkflowActionUpsertWorkflowMemo      = workflowAction("add-workflow-upsert-memo-event")
 
 	// decision
 	WorkflowActionDecisionTaskScheduled = workflowAction("add-decisiontask-scheduled-event")
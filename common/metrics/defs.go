@@ -381,6 +381,8 @@ const (
 	DCRedirectionScanWorkflowExecutionsScope
 	// DCRedirectionCountWorkflowExecutionsScope tracks RPC calls for dc redirection
 	DCRedirectionCountWorkflowExecutionsScope
+	// DCRedirectionGetDomainWorkflowStateCountsScope tracks RPC calls for dc redirection
+	DCRedirectionGetDomainWorkflowStateCountsScope
 	// DCRedirectionGetSearchAttributesScope tracks RPC calls for dc redirection
 	DCRedirectionGetSearchAttributesScope
 	// DCRedirectionPollForActivityTaskScope tracks RPC calls for dc redirection
@@ -576,6 +578,8 @@ const (
 	FrontendScanWorkflowExecutionsScope
 	// FrontendCountWorkflowExecutionsScope is the metric scope for frontend.CountWorkflowExecutions
 	FrontendCountWorkflowExecutionsScope
+	// FrontendGetDomainWorkflowStateCountsScope is the metric scope for frontend.GetDomainWorkflowStateCounts
+	FrontendGetDomainWorkflowStateCountsScope
 	// FrontendRegisterDomainScope is the metric scope for frontend.RegisterDomain
 	FrontendRegisterDomainScope
 	// FrontendDescribeDomainScope is the metric scope for frontend.DescribeDomain
@@ -678,6 +682,18 @@ const (
 	TransferActiveTaskResetWorkflowScope
 	// TransferStandbyTaskResetWorkflowScope is the scope used for record workflow started task processing by transfer queue processor
 	TransferStandbyTaskResetWorkflowScope
+	// TransferActiveTaskUpsertWorkflowMemoScope is the scope used for upsert workflow memo task processing by transfer queue processor
+	TransferActiveTaskUpsertWorkflowMemoScope
+	// TransferStandbyTaskUpsertWorkflowMemoScope is the scope used for upsert workflow memo task processing by transfer queue processor
+	TransferStandbyTaskUpsertWorkflowMemoScope
+	// TransferActiveTaskTerminateChildScope is the scope used for terminate child execution task processing by transfer queue processor
+	TransferActiveTaskTerminateChildScope
+	// TransferStandbyTaskTerminateChildScope is the scope used for terminate child execution task processing by transfer queue processor
+	TransferStandbyTaskTerminateChildScope
+	// TransferActiveTaskCancelChildScope is the scope used for cancel child execution task processing by transfer queue processor
+	TransferActiveTaskCancelChildScope
+	// TransferStandbyTaskCancelChildScope is the scope used for cancel child execution task processing by transfer queue processor
+	TransferStandbyTaskCancelChildScope
 	// TransferStandbyTaskActivityScope is the scope used for activity task processing by transfer queue processor
 	TransferStandbyTaskActivityScope
 	// TransferStandbyTaskDecisionScope is the scope used for decision task processing by transfer queue processor
@@ -770,6 +786,14 @@ const (
 	WorkflowCompletionStatsScope
 	// ArchiverClientScope is scope used by all metrics emitted by archiver.Client
 	ArchiverClientScope
+	// HistoryAuditSinkScope is the scope used by the audit sink of administrative operations
+	HistoryAuditSinkScope
+	// HistoryForceCompleteActivityScope tracks ForceCompleteActivity admin calls received by service
+	HistoryForceCompleteActivityScope
+	// HistoryExtendWorkflowTimeoutScope tracks ExtendWorkflowTimeout admin calls received by service
+	HistoryExtendWorkflowTimeoutScope
+	// HistoryMarkBinaryBadScope tracks MarkBinaryBad admin calls received by service
+	HistoryMarkBinaryBadScope
 
 	NumHistoryScopes
 )
@@ -822,6 +846,10 @@ const (
 	ArchiverDeleteHistoryActivityScope
 	// ArchiverDeleteBlobActivityScope is scope used by all metrics emitted by archiver.DeleteBlobActivity
 	ArchiverDeleteBlobActivityScope
+	// ArchiverUploadMutableStateActivityScope is scope used by all metrics emitted by archiver.UploadMutableStateActivity
+	ArchiverUploadMutableStateActivityScope
+	// ArchiverDeleteOrphanedHistoryActivityScope is scope used by all metrics emitted by archiver.DeleteOrphanedHistoryActivity
+	ArchiverDeleteOrphanedHistoryActivityScope
 	// ArchiverScope is scope used by all metrics emitted by archiver.Archiver
 	ArchiverScope
 	// ArchiverPumpScope is scope used by all metrics emitted by archiver.Pump
@@ -1004,6 +1032,7 @@ var ScopeDefs = map[ServiceIdx]map[int]scopeDefinition{
 		DCRedirectionListWorkflowExecutionsScope:            {operation: "DCRedirectionListWorkflowExecutions", tags: map[string]string{CadenceRoleTagName: DCRedirectionRoleTagValue}},
 		DCRedirectionScanWorkflowExecutionsScope:            {operation: "DCRedirectionScanWorkflowExecutions", tags: map[string]string{CadenceRoleTagName: DCRedirectionRoleTagValue}},
 		DCRedirectionCountWorkflowExecutionsScope:           {operation: "DCRedirectionCountWorkflowExecutions", tags: map[string]string{CadenceRoleTagName: DCRedirectionRoleTagValue}},
+		DCRedirectionGetDomainWorkflowStateCountsScope:      {operation: "DCRedirectionGetDomainWorkflowStateCounts", tags: map[string]string{CadenceRoleTagName: DCRedirectionRoleTagValue}},
 		DCRedirectionGetSearchAttributesScope:               {operation: "DCRedirectionGetSearchAttributes", tags: map[string]string{CadenceRoleTagName: DCRedirectionRoleTagValue}},
 		DCRedirectionPollForActivityTaskScope:               {operation: "DCRedirectionPollForActivityTask", tags: map[string]string{CadenceRoleTagName: DCRedirectionRoleTagValue}},
 		DCRedirectionPollForDecisionTaskScope:               {operation: "DCRedirectionPollForDecisionTask", tags: map[string]string{CadenceRoleTagName: DCRedirectionRoleTagValue}},
@@ -1086,6 +1115,7 @@ var ScopeDefs = map[ServiceIdx]map[int]scopeDefinition{
 		FrontendListWorkflowExecutionsScope:           {operation: "ListWorkflowExecutions"},
 		FrontendScanWorkflowExecutionsScope:           {operation: "ScanWorkflowExecutions"},
 		FrontendCountWorkflowExecutionsScope:          {operation: "CountWorkflowExecutions"},
+		FrontendGetDomainWorkflowStateCountsScope:     {operation: "GetDomainWorkflowStateCounts"},
 		FrontendRegisterDomainScope:                   {operation: "RegisterDomain"},
 		FrontendDescribeDomainScope:                   {operation: "DescribeDomain"},
 		FrontendListDomainsScope:                      {operation: "ListDomain"},
@@ -1145,6 +1175,12 @@ var ScopeDefs = map[ServiceIdx]map[int]scopeDefinition{
 		TransferStandbyTaskStartChildExecutionScope:   {operation: "TransferStandbyTaskStartChildExecution"},
 		TransferStandbyTaskRecordWorkflowStartedScope: {operation: "TransferStandbyTaskRecordWorkflowStarted"},
 		TransferStandbyTaskResetWorkflowScope:         {operation: "TransferStandbyTaskResetWorkflow"},
+		TransferActiveTaskUpsertWorkflowMemoScope:     {operation: "TransferActiveTaskUpsertWorkflowMemo"},
+		TransferStandbyTaskUpsertWorkflowMemoScope:    {operation: "TransferStandbyTaskUpsertWorkflowMemo"},
+		TransferActiveTaskTerminateChildScope:         {operation: "TransferActiveTaskTerminateChild"},
+		TransferStandbyTaskTerminateChildScope:        {operation: "TransferStandbyTaskTerminateChild"},
+		TransferActiveTaskCancelChildScope:            {operation: "TransferActiveTaskCancelChild"},
+		TransferStandbyTaskCancelChildScope:           {operation: "TransferStandbyTaskCancelChild"},
 		TimerQueueProcessorScope:                      {operation: "TimerQueueProcessor"},
 		TimerActiveQueueProcessorScope:                {operation: "TimerActiveQueueProcessor"},
 		TimerStandbyQueueProcessorScope:               {operation: "TimerStandbyQueueProcessor"},
@@ -1182,6 +1218,10 @@ var ScopeDefs = map[ServiceIdx]map[int]scopeDefinition{
 		SessionCountStatsScope:                        {operation: "SessionStats", tags: map[string]string{StatsTypeTagName: CountStatsTypeTagValue}},
 		WorkflowCompletionStatsScope:                  {operation: "CompletionStats", tags: map[string]string{StatsTypeTagName: CountStatsTypeTagValue}},
 		ArchiverClientScope:                           {operation: "ArchiverClient"},
+		HistoryAuditSinkScope:                         {operation: "AuditSink"},
+		HistoryForceCompleteActivityScope:             {operation: "ForceCompleteActivity"},
+		HistoryExtendWorkflowTimeoutScope:             {operation: "ExtendWorkflowTimeout"},
+		HistoryMarkBinaryBadScope:                     {operation: "MarkBinaryBad"},
 	},
 	// Matching Scope Names
 	Matching: {
@@ -1197,21 +1237,23 @@ var ScopeDefs = map[ServiceIdx]map[int]scopeDefinition{
 	},
 	// Worker Scope Names
 	Worker: {
-		ReplicatorScope:                     {operation: "Replicator"},
-		DomainReplicationTaskScope:          {operation: "DomainReplicationTask"},
-		HistoryReplicationTaskScope:         {operation: "HistoryReplicationTask"},
-		HistoryMetadataReplicationTaskScope: {operation: "HistoryMetadataReplicationTask"},
-		SyncShardTaskScope:                  {operation: "SyncShardTask"},
-		SyncActivityTaskScope:               {operation: "SyncActivityTask"},
-		ESProcessorScope:                    {operation: "ESProcessor"},
-		IndexProcessorScope:                 {operation: "IndexProcessor"},
-		ArchiverUploadHistoryActivityScope:  {operation: "ArchiverUploadHistoryActivity"},
-		ArchiverDeleteHistoryActivityScope:  {operation: "ArchiverDeleteHistoryActivity"},
-		ArchiverDeleteBlobActivityScope:     {operation: "ArchiverDeleteBlobActivity"},
-		ArchiverScope:                       {operation: "Archiver"},
-		ArchiverPumpScope:                   {operation: "ArchiverPump"},
-		ArchiverArchivalWorkflowScope:       {operation: "ArchiverArchivalWorkflow"},
-		TaskListScavengerScope:              {operation: "tasklistscavenger"},
+		ReplicatorScope:                            {operation: "Replicator"},
+		DomainReplicationTaskScope:                 {operation: "DomainReplicationTask"},
+		HistoryReplicationTaskScope:                {operation: "HistoryReplicationTask"},
+		HistoryMetadataReplicationTaskScope:        {operation: "HistoryMetadataReplicationTask"},
+		SyncShardTaskScope:                         {operation: "SyncShardTask"},
+		SyncActivityTaskScope:                      {operation: "SyncActivityTask"},
+		ESProcessorScope:                           {operation: "ESProcessor"},
+		IndexProcessorScope:                        {operation: "IndexProcessor"},
+		ArchiverUploadHistoryActivityScope:         {operation: "ArchiverUploadHistoryActivity"},
+		ArchiverDeleteHistoryActivityScope:         {operation: "ArchiverDeleteHistoryActivity"},
+		ArchiverDeleteBlobActivityScope:            {operation: "ArchiverDeleteBlobActivity"},
+		ArchiverUploadMutableStateActivityScope:    {operation: "ArchiverUploadMutableStateActivity"},
+		ArchiverDeleteOrphanedHistoryActivityScope: {operation: "ArchiverDeleteOrphanedHistoryActivity"},
+		ArchiverScope:                              {operation: "Archiver"},
+		ArchiverPumpScope:                          {operation: "ArchiverPump"},
+		ArchiverArchivalWorkflowScope:              {operation: "ArchiverArchivalWorkflow"},
+		TaskListScavengerScope:                     {operation: "tasklistscavenger"},
 	},
 	// Blobstore Scope Names
 	Blobstore: {
@@ -1298,12 +1340,14 @@ const (
 	TaskStandbyRetryCounter
 	TaskNotActiveCounter
 	TaskLimitExceededCounter
+	TaskMatchingCircuitBreakerOpenCounter
 	TaskBatchCompleteCounter
 	TaskProcessingLatency
 	TaskQueueLatency
 
 	AckLevelUpdateCounter
 	AckLevelUpdateFailedCounter
+	MatchingCircuitBreakerStateGauge
 	DecisionTypeScheduleActivityCounter
 	DecisionTypeCompleteWorkflowCounter
 	DecisionTypeFailWorkflowCounter
@@ -1312,16 +1356,43 @@ const (
 	DecisionTypeCancelActivityCounter
 	DecisionTypeCancelTimerCounter
 	DecisionTypeRecordMarkerCounter
+	LocalActivityMarkerRecordedCounter
+	ActivityScheduleToCloseTimeoutClampedCounter
+	ActivityRetryExpirationClampedCounter
+	ActivityTypeScheduledCounter
+	ActivityTypeStartedCounter
+	ActivityTypeCompletedCounter
+	ActivityTypeFailedCounter
+	ActivityTypeCanceledCounter
+	ActivityRetryBudgetExhaustedCounter
+	ActivityGlobalNonRetryableErrorCounter
 	DecisionTypeCancelExternalWorkflowCounter
 	DecisionTypeChildWorkflowCounter
 	DecisionTypeContinueAsNewCounter
 	DecisionTypeSignalExternalWorkflowCounter
+	DecisionTypeUpsertWorkflowMemoCounter
 	MultipleCompletionDecisionsCounter
+	CronBackoffFloorAppliedCounter
+	CronMisfireCounter
 	FailedDecisionsCounter
 	StaleMutableStateCounter
 	AutoResetPointsLimitExceededCounter
+	PendingChildWorkflowsLimitExceededCounter
+	DecisionAttemptsLimitExceededCounter
+	HistoryLengthLimitExceededCounter
+	WorkflowHistorySizeWarnCounter
+	ShardBackpressureRejectedCounter
 	AutoResetPointCorruptionCounter
-	ConcurrencyUpdateFailureCounter
+	BadBinaryResetWorkflowsCounter
+	WorkflowTimeoutTaskRepairedCounter
+	DuplicateDecisionSuppressedCounter
+	AuditEntryDroppedCounter
+	ConditionalUpdateFailureCounter
+	SignalRequestedIDsSize
+	ClusterClockSkewDetected
+	DecisionReferencedCompletedActivityCounter
+	DomainDrainingRejectedCounter
+	ResetWorkflowReplayLatency
 	CadenceErrEventAlreadyStartedCounter
 	CadenceErrShardOwnershipLostCounter
 	HeartbeatTimeoutCounter
@@ -1356,6 +1427,9 @@ const (
 	RemoveEngineForShardLatency
 	CompleteDecisionWithStickyEnabledCounter
 	CompleteDecisionWithStickyDisabledCounter
+	CompleteDecisionWithStickyResetCounter
+	EmptyDecisionLoopCounter
+	DecisionHeartbeatCounter
 	HistoryEventNotificationQueueingLatency
 	HistoryEventNotificationFanoutLatency
 	HistoryEventNotificationInFlightMessageGauge
@@ -1372,6 +1446,8 @@ const (
 	CacheFailures
 	CacheLatency
 	CacheMissCounter
+	CacheHitCounter
+	CacheSize
 	AcquireLockFailedCounter
 	WorkflowContextCleared
 	MutableStateSize
@@ -1398,12 +1474,14 @@ const (
 	WorkflowCronBackoffTimerCount
 	WorkflowCleanupDeleteCount
 	WorkflowCleanupArchiveCount
+	WorkflowCleanupArchiveSkippedCount
 	WorkflowCleanupNopCount
 	WorkflowSuccessCount
 	WorkflowCancelCount
 	WorkflowFailedCount
 	WorkflowTimeoutCount
 	WorkflowTerminateCount
+	WorkflowStartToFirstDecisionLatency
 
 	NumHistoryMetrics
 )
@@ -1444,6 +1522,8 @@ const (
 	ArchiverHistoryMutatedCount
 	ArchiverBlobSize
 	ArchiverTotalUploadSize
+	ArchiverUncompressedBlobSize
+	ArchiverTotalUncompressedUploadSize
 	ArchiverRunningDeterministicConstructionCheckCount
 	ArchiverDeterministicConstructionCheckFailedCount
 	ArchiverCouldNotRunDeterministicConstructionCheckCount
@@ -1455,6 +1535,8 @@ const (
 	ArchiverCoroutineStartedCount
 	ArchiverCoroutineStoppedCount
 	ArchiverHandleRequestLatency
+	ArchiverHandleNormalPriorityRequestLatency
+	ArchiverHandleHighPriorityRequestLatency
 	ArchiverUploadWithRetriesLatency
 	ArchiverDeleteBlobWithRetriesLatency
 	ArchiverDeleteWithRetriesLatency
@@ -1462,10 +1544,15 @@ const (
 	ArchiverUploadSuccessCount
 	ArchiverDeleteBlobFailedAllRetriesCount
 	ArchiverDeleteBlobSuccessCount
+	ArchiverUploadMutableStateFailedAllRetriesCount
+	ArchiverUploadMutableStateSuccessCount
 	ArchiverDeleteLocalFailedAllRetriesCount
+	ArchiverDeleteLocalTimeoutCount
 	ArchiverDeleteLocalSuccessCount
 	ArchiverDeleteFailedAllRetriesCount
 	ArchiverDeleteSuccessCount
+	ArchiverDeleteOrphanedHistoryFailedAllRetriesCount
+	ArchiverDeleteOrphanedHistorySuccessCount
 	ArchiverBacklogSizeGauge
 	ArchiverPumpTimeoutCount
 	ArchiverPumpSignalThresholdCount
@@ -1475,6 +1562,7 @@ const (
 	ArchiverNumPumpedRequestsCount
 	ArchiverNumHandledRequestsCount
 	ArchiverPumpedNotEqualHandledCount
+	ArchiverDuplicateRequestCount
 	ArchiverHandleAllRequestsLatency
 	ArchiverWorkflowStoppingCount
 	ArchiverClientSendSignalFailureCount
@@ -1555,11 +1643,13 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		TaskStandbyRetryCounter:                      {metricName: "task_errors_standby_retry_counter", metricType: Counter},
 		TaskNotActiveCounter:                         {metricName: "task_errors_not_active_counter", metricType: Counter},
 		TaskLimitExceededCounter:                     {metricName: "task_errors_limit_exceeded_counter", metricType: Counter},
+		TaskMatchingCircuitBreakerOpenCounter:        {metricName: "task_errors_matching_circuit_breaker_open_counter", metricType: Counter},
 		TaskProcessingLatency:                        {metricName: "task_latency_processing", metricType: Timer},
 		TaskQueueLatency:                             {metricName: "task_latency_queue", metricType: Timer},
 		TaskBatchCompleteCounter:                     {metricName: "task_batch_complete_counter", metricType: Counter},
 		AckLevelUpdateCounter:                        {metricName: "ack_level_update", metricType: Counter},
 		AckLevelUpdateFailedCounter:                  {metricName: "ack_level_update_failed", metricType: Counter},
+		MatchingCircuitBreakerStateGauge:             {metricName: "matching_circuit_breaker_state", metricType: Gauge},
 		DecisionTypeScheduleActivityCounter:          {metricName: "schedule_activity_decision", metricType: Counter},
 		DecisionTypeCompleteWorkflowCounter:          {metricName: "complete_workflow_decision", metricType: Counter},
 		DecisionTypeFailWorkflowCounter:              {metricName: "fail_workflow_decision", metricType: Counter},
@@ -1568,16 +1658,44 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		DecisionTypeCancelActivityCounter:            {metricName: "cancel_activity_decision", metricType: Counter},
 		DecisionTypeCancelTimerCounter:               {metricName: "cancel_timer_decision", metricType: Counter},
 		DecisionTypeRecordMarkerCounter:              {metricName: "record_marker_decision", metricType: Counter},
+		LocalActivityMarkerRecordedCounter:           {metricName: "local_activity_marker_recorded", metricType: Counter},
+		ActivityScheduleToCloseTimeoutClampedCounter: {metricName: "activity_schedule_to_close_timeout_clamped", metricType: Counter},
+		ActivityRetryExpirationClampedCounter:        {metricName: "activity_retry_expiration_clamped", metricType: Counter},
+		ActivityTypeScheduledCounter:                 {metricName: "activity_type_scheduled", metricType: Counter},
+		ActivityTypeStartedCounter:                   {metricName: "activity_type_started", metricType: Counter},
+		ActivityTypeCompletedCounter:                 {metricName: "activity_type_completed", metricType: Counter},
+		ActivityTypeFailedCounter:                    {metricName: "activity_type_failed", metricType: Counter},
+		ActivityTypeCanceledCounter:                  {metricName: "activity_type_canceled", metricType: Counter},
+		ActivityRetryBudgetExhaustedCounter:          {metricName: "activity_retry_budget_exhausted", metricType: Counter},
+		ActivityGlobalNonRetryableErrorCounter:       {metricName: "activity_global_non_retryable_error", metricType: Counter},
 		DecisionTypeCancelExternalWorkflowCounter:    {metricName: "cancel_external_workflow_decision", metricType: Counter},
 		DecisionTypeContinueAsNewCounter:             {metricName: "continue_as_new_decision", metricType: Counter},
 		DecisionTypeSignalExternalWorkflowCounter:    {metricName: "signal_external_workflow_decision", metricType: Counter},
 		DecisionTypeChildWorkflowCounter:             {metricName: "child_workflow_decision", metricType: Counter},
+		DecisionTypeUpsertWorkflowMemoCounter:        {metricName: "upsert_workflow_memo_decision", metricType: Counter},
 		MultipleCompletionDecisionsCounter:           {metricName: "multiple_completion_decisions", metricType: Counter},
+		CronBackoffFloorAppliedCounter:               {metricName: "cron_backoff_floor_applied", metricType: Counter},
+		CronMisfireCounter:                           {metricName: "cron_misfire", metricType: Counter},
 		FailedDecisionsCounter:                       {metricName: "failed_decisions", metricType: Counter},
 		StaleMutableStateCounter:                     {metricName: "stale_mutable_state", metricType: Counter},
 		AutoResetPointsLimitExceededCounter:          {metricName: "auto_reset_points_exceed_limit", metricType: Counter},
+		PendingChildWorkflowsLimitExceededCounter:    {metricName: "pending_child_workflows_exceed_limit", metricType: Counter},
+		DecisionAttemptsLimitExceededCounter:         {metricName: "decision_attempts_exceed_limit", metricType: Counter},
+		HistoryLengthLimitExceededCounter:            {metricName: "history_length_exceed_limit", metricType: Counter},
+		WorkflowHistorySizeWarnCounter:               {metricName: "workflow_history_size_warn", metricType: Counter},
+		ShardBackpressureRejectedCounter:             {metricName: "shard_backpressure_rejected", metricType: Counter},
 		AutoResetPointCorruptionCounter:              {metricName: "auto_reset_point_corruption", metricType: Counter},
 		ConcurrencyUpdateFailureCounter:              {metricName: "concurrency_update_failure", metricType: Counter},
+		BadBinaryResetWorkflowsCounter:               {metricName: "bad_binary_reset_workflows", metricType: Counter},
+		WorkflowTimeoutTaskRepairedCounter:           {metricName: "workflow_timeout_task_repaired", metricType: Counter},
+		DuplicateDecisionSuppressedCounter:           {metricName: "duplicate_decision_suppressed", metricType: Counter},
+		AuditEntryDroppedCounter:                     {metricName: "audit_entry_dropped", metricType: Counter},
+		ConditionalUpdateFailureCounter:              {metricName: "conditional_update_failure", metricType: Counter},
+		SignalRequestedIDsSize:                       {metricName: "signal_requested_ids_size", metricType: Gauge},
+		ClusterClockSkewDetected:                     {metricName: "cluster_clock_skew_detected", metricType: Counter},
+		DecisionReferencedCompletedActivityCounter:   {metricName: "decision_referenced_completed_activity", metricType: Counter},
+		DomainDrainingRejectedCounter:                {metricName: "domain_draining_rejected", metricType: Counter},
+		ResetWorkflowReplayLatency:                   {metricName: "reset_workflow_replay_latency", metricType: Timer},
 		CadenceErrShardOwnershipLostCounter:          {metricName: "cadence_errors_shard_ownership_lost", metricType: Counter},
 		CadenceErrEventAlreadyStartedCounter:         {metricName: "cadence_errors_event_already_started", metricType: Counter},
 		HeartbeatTimeoutCounter:                      {metricName: "heartbeat_timeout", metricType: Counter},
@@ -1612,6 +1730,9 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		RemoveEngineForShardLatency:                  {metricName: "remove_engine_for_shard_latency", metricType: Timer},
 		CompleteDecisionWithStickyEnabledCounter:     {metricName: "complete_decision_sticky_enabled_count", metricType: Counter},
 		CompleteDecisionWithStickyDisabledCounter:    {metricName: "complete_decision_sticky_disabled_count", metricType: Counter},
+		CompleteDecisionWithStickyResetCounter:       {metricName: "complete_decision_sticky_reset_count", metricType: Counter},
+		EmptyDecisionLoopCounter:                     {metricName: "empty_decision_loop_count", metricType: Counter},
+		DecisionHeartbeatCounter:                     {metricName: "decision_heartbeat_count", metricType: Counter},
 		HistoryEventNotificationQueueingLatency:      {metricName: "history_event_notification_queueing_latency", metricType: Timer},
 		HistoryEventNotificationFanoutLatency:        {metricName: "history_event_notification_fanout_latency", metricType: Timer},
 		HistoryEventNotificationInFlightMessageGauge: {metricName: "history_event_notification_inflight_message_gauge", metricType: Gauge},
@@ -1628,6 +1749,8 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		CacheFailures:                                {metricName: "cache_errors", metricType: Counter},
 		CacheLatency:                                 {metricName: "cache_latency", metricType: Timer},
 		CacheMissCounter:                             {metricName: "cache_miss", metricType: Counter},
+		CacheHitCounter:                              {metricName: "cache_hit", metricType: Counter},
+		CacheSize:                                    {metricName: "cache_size", metricType: Gauge},
 		AcquireLockFailedCounter:                     {metricName: "acquire_lock_failed", metricType: Counter},
 		WorkflowContextCleared:                       {metricName: "workflow_context_cleared", metricType: Counter},
 		MutableStateSize:                             {metricName: "mutable_state_size", metricType: Timer},
@@ -1654,12 +1777,14 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		WorkflowCronBackoffTimerCount:                {metricName: "workflow_cron_backoff_timer", metricType: Counter},
 		WorkflowCleanupDeleteCount:                   {metricName: "workflow_cleanup_delete", metricType: Counter},
 		WorkflowCleanupArchiveCount:                  {metricName: "workflow_cleanup_archive", metricType: Counter},
+		WorkflowCleanupArchiveSkippedCount:           {metricName: "workflow_cleanup_archive_skipped", metricType: Counter},
 		WorkflowCleanupNopCount:                      {metricName: "workflow_cleanup_nop", metricType: Counter},
 		WorkflowSuccessCount:                         {metricName: "workflow_success", metricType: Counter},
 		WorkflowCancelCount:                          {metricName: "workflow_cancel", metricType: Counter},
 		WorkflowFailedCount:                          {metricName: "workflow_failed", metricType: Counter},
 		WorkflowTimeoutCount:                         {metricName: "workflow_timeout", metricType: Counter},
 		WorkflowTerminateCount:                       {metricName: "workflow_terminate", metricType: Counter},
+		WorkflowStartToFirstDecisionLatency:          {metricName: "workflow_start_to_first_decision_latency", metricType: Timer},
 	},
 	Matching: {
 		PollSuccessCounter:            {metricName: "poll_success"},
@@ -1692,6 +1817,8 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		ArchiverHistoryMutatedCount:                            {metricName: "archiver_history_mutated"},
 		ArchiverBlobSize:                                       {metricName: "archiver_blob_size", metricType: Timer},
 		ArchiverTotalUploadSize:                                {metricName: "archiver_total_upload_size", metricType: Timer},
+		ArchiverUncompressedBlobSize:                           {metricName: "archiver_uncompressed_blob_size", metricType: Timer},
+		ArchiverTotalUncompressedUploadSize:                    {metricName: "archiver_total_uncompressed_upload_size", metricType: Timer},
 		ArchiverRunningDeterministicConstructionCheckCount:     {metricName: "archiver_running_deterministic_construction_check"},
 		ArchiverDeterministicConstructionCheckFailedCount:      {metricName: "archiver_deterministic_construction_check_failed"},
 		ArchiverCouldNotRunDeterministicConstructionCheckCount: {metricName: "archiver_could_not_run_deterministic_construction_check"},
@@ -1703,6 +1830,8 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		ArchiverCoroutineStartedCount:                          {metricName: "archiver_coroutine_started"},
 		ArchiverCoroutineStoppedCount:                          {metricName: "archiver_coroutine_stopped"},
 		ArchiverHandleRequestLatency:                           {metricName: "archiver_handle_request_latency"},
+		ArchiverHandleNormalPriorityRequestLatency:             {metricName: "archiver_handle_normal_priority_request_latency"},
+		ArchiverHandleHighPriorityRequestLatency:               {metricName: "archiver_handle_high_priority_request_latency"},
 		ArchiverUploadWithRetriesLatency:                       {metricName: "archiver_upload_with_retries_latency"},
 		ArchiverDeleteBlobWithRetriesLatency:                   {metricName: "archiver_delete_blob_with_retries_latency"},
 		ArchiverDeleteWithRetriesLatency:                       {metricName: "archiver_delete_with_retries_latency"},
@@ -1710,10 +1839,15 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		ArchiverUploadSuccessCount:                             {metricName: "archiver_upload_success"},
 		ArchiverDeleteBlobFailedAllRetriesCount:                {metricName: "archiver_delete_blob_failed_all_retries"},
 		ArchiverDeleteBlobSuccessCount:                         {metricName: "archiver_delete_blob_success"},
+		ArchiverUploadMutableStateFailedAllRetriesCount:        {metricName: "archiver_upload_mutable_state_failed_all_retries"},
+		ArchiverUploadMutableStateSuccessCount:                 {metricName: "archiver_upload_mutable_state_success"},
 		ArchiverDeleteLocalFailedAllRetriesCount:               {metricName: "archiver_delete_local_failed_all_retries"},
+		ArchiverDeleteLocalTimeoutCount:                        {metricName: "archiver_delete_local_timeout"},
 		ArchiverDeleteLocalSuccessCount:                        {metricName: "archiver_delete_local_success"},
 		ArchiverDeleteFailedAllRetriesCount:                    {metricName: "archiver_delete_failed_all_retries"},
 		ArchiverDeleteSuccessCount:                             {metricName: "archiver_delete_success"},
+		ArchiverDeleteOrphanedHistoryFailedAllRetriesCount:     {metricName: "archiver_delete_orphaned_history_failed_all_retries"},
+		ArchiverDeleteOrphanedHistorySuccessCount:              {metricName: "archiver_delete_orphaned_history_success"},
 		ArchiverBacklogSizeGauge:                               {metricName: "archiver_backlog_size"},
 		ArchiverPumpTimeoutCount:                               {metricName: "archiver_pump_timeout"},
 		ArchiverPumpSignalThresholdCount:                       {metricName: "archiver_pump_signal_threshold"},
@@ -1723,6 +1857,7 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		ArchiverNumPumpedRequestsCount:                         {metricName: "archiver_num_pumped_requests"},
 		ArchiverNumHandledRequestsCount:                        {metricName: "archiver_num_handled_requests"},
 		ArchiverPumpedNotEqualHandledCount:                     {metricName: "archiver_pumped_not_equal_handled"},
+		ArchiverDuplicateRequestCount:                          {metricName: "archiver_duplicate_request"},
 		ArchiverHandleAllRequestsLatency:                       {metricName: "archiver_handle_all_requests_latency"},
 		ArchiverWorkflowStoppingCount:                          {metricName: "archiver_workflow_stopping"},
 		ArchiverClientSendSignalFailureCount:                   {metricName: "archiver_client_send_signal_error"},
@@ -30,6 +30,11 @@ const (
 	instance      = "instance"
 	domain        = "domain"
 	targetCluster = "target_cluster"
+	taskList      = "tasklist"
+	shard         = "shard"
+	activityType  = "activity_type"
+	workflowType  = "workflow_type"
+	updateFailure = "update_failure_cause"
 
 	domainAllValue = "all"
 	unknownValue   = "_unknown_"
@@ -55,6 +60,26 @@ type (
 	targetClusterTag struct {
 		value string
 	}
+
+	taskListTag struct {
+		value string
+	}
+
+	shardTag struct {
+		value string
+	}
+
+	activityTypeTag struct {
+		value string
+	}
+
+	workflowTypeTag struct {
+		value string
+	}
+
+	updateFailureCauseTag struct {
+		value string
+	}
 )
 
 // DomainTag returns a new domain tag. For timers, this also ensures that we
@@ -124,3 +149,93 @@ func (d targetClusterTag) Key() string {
 func (d targetClusterTag) Value() string {
 	return d.value
 }
+
+// TaskListTag returns a new task list tag.
+func TaskListTag(value string) Tag {
+	if len(value) == 0 {
+		value = unknownValue
+	}
+	return taskListTag{value}
+}
+
+// Key returns the key of the task list tag
+func (d taskListTag) Key() string {
+	return taskList
+}
+
+// Value returns the value of a task list tag
+func (d taskListTag) Value() string {
+	return d.value
+}
+
+// ShardTag returns a new shard tag
+func ShardTag(value string) Tag {
+	return shardTag{value}
+}
+
+// Key returns the key of the shard tag
+func (s shardTag) Key() string {
+	return shard
+}
+
+// Value returns the value of the shard tag
+func (s shardTag) Value() string {
+	return s.value
+}
+
+// ActivityTypeTag returns a new activity type tag. If a blank activity type is
+// provided then this converts that to an unknown activity type.
+func ActivityTypeTag(value string) Tag {
+	if len(value) == 0 {
+		value = unknownValue
+	}
+	return activityTypeTag{value}
+}
+
+// Key returns the key of the activity type tag
+func (a activityTypeTag) Key() string {
+	return activityType
+}
+
+// Value returns the value of the activity type tag
+func (a activityTypeTag) Value() string {
+	return a.value
+}
+
+// WorkflowTypeTag returns a new workflow type tag. If a blank workflow type is
+// provided then this converts that to an unknown workflow type.
+func WorkflowTypeTag(value string) Tag {
+	if len(value) == 0 {
+		value = unknownValue
+	}
+	return workflowTypeTag{value}
+}
+
+// Key returns the key of the workflow type tag
+func (w workflowTypeTag) Key() string {
+	return workflowType
+}
+
+// Value returns the value of the workflow type tag
+func (w workflowTypeTag) Value() string {
+	return w.value
+}
+
+// UpdateFailureCauseTag returns a new conditional update failure cause tag. If a blank
+// cause is provided then this converts that to an unknown cause.
+func UpdateFailureCauseTag(value string) Tag {
+	if len(value) == 0 {
+		value = unknownValue
+	}
+	return updateFailureCauseTag{value}
+}
+
+// Key returns the key of the update failure cause tag
+func (u updateFailureCauseTag) Key() string {
+	return updateFailure
+}
+
+// Value returns the value of the update failure cause tag
+func (u updateFailureCauseTag) Value() string {
+	return u.value
+}
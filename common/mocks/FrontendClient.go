@@ -359,6 +359,36 @@ func (_m *FrontendClient) CountWorkflowExecutions(ctx context.Context, CountRequ
 	return r0, r1
 }
 
+// GetDomainWorkflowStateCounts provides a mock function with given fields: ctx, CountRequest, opts
+func (_m *FrontendClient) GetDomainWorkflowStateCounts(ctx context.Context, CountRequest *shared.GetDomainWorkflowStateCountsRequest, opts ...yarpc.CallOption) (*shared.GetDomainWorkflowStateCountsResponse, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, CountRequest)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *shared.GetDomainWorkflowStateCountsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *shared.GetDomainWorkflowStateCountsRequest, ...yarpc.CallOption) *shared.GetDomainWorkflowStateCountsResponse); ok {
+		r0 = rf(ctx, CountRequest, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*shared.GetDomainWorkflowStateCountsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *shared.GetDomainWorkflowStateCountsRequest, ...yarpc.CallOption) error); ok {
+		r1 = rf(ctx, CountRequest, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSearchAttributes provides a mock function with given fields: ctx, opts
 func (_m *FrontendClient) GetSearchAttributes(ctx context.Context, opts ...yarpc.CallOption) (*shared.GetSearchAttributesResponse, error) {
 	_va := make([]interface{}, len(opts))
@@ -128,6 +128,7 @@ const (
 		`execution_context: ?, ` +
 		`state: ?, ` +
 		`close_status: ?, ` +
+		`close_timestamp: ?, ` +
 		`last_first_event_id: ?, ` +
 		`last_event_task_id: ?, ` +
 		`next_event_id: ?, ` +
@@ -145,6 +146,7 @@ const (
 		`decision_attempt: ?, ` +
 		`decision_timestamp: ?, ` +
 		`decision_scheduled_timestamp: ?, ` +
+		`decision_original_scheduled_timestamp: ?, ` +
 		`cancel_requested: ?, ` +
 		`cancel_request_id: ?, ` +
 		`sticky_task_list: ?, ` +
@@ -166,7 +168,9 @@ const (
 		`branch_token: ?, ` +
 		`cron_schedule: ?, ` +
 		`expiration_seconds: ?, ` +
-		`search_attributes: ? ` +
+		`search_attributes: ?, ` +
+		`memo: ?, ` +
+		`signal_requested_timestamps: ? ` +
 		`}`
 
 	templateReplicationStateType = `{` +
@@ -255,6 +259,7 @@ const (
 		`expiration_time: ?, ` +
 		`max_attempts: ?, ` +
 		`non_retriable_errors: ?, ` +
+		`last_failure_reason: ?, ` +
 		`event_data_encoding: ?` +
 		`}`
 
@@ -1366,6 +1371,7 @@ func (d *cassandraPersistence) CreateWorkflowExecutionWithinBatch(request *p.Int
 			request.ExecutionContext,
 			request.State,
 			request.CloseStatus,
+			0, // close timestamp
 			common.FirstEventID,
 			request.LastEventTaskID,
 			request.NextEventID,
@@ -1383,6 +1389,7 @@ func (d *cassandraPersistence) CreateWorkflowExecutionWithinBatch(request *p.Int
 			0,     // decision attempts
 			0,     // decision timestamp(started time)
 			0,     // decision scheduled timestamp
+			0,     // decision original scheduled timestamp
 			false, // cancel_requested
 			"",    // cancel_request_id
 			"",    // sticky_task_list (no sticky tasklist for new workflow execution)
@@ -1405,6 +1412,8 @@ func (d *cassandraPersistence) CreateWorkflowExecutionWithinBatch(request *p.Int
 			request.CronSchedule,
 			request.ExpirationSeconds,
 			request.SearchAttributes,
+			request.Memo,
+			nil, // signal requested timestamps
 			request.NextEventID,
 			defaultVisibilityTimestamp,
 			rowTypeExecutionTaskID)
@@ -1437,6 +1446,7 @@ func (d *cassandraPersistence) CreateWorkflowExecutionWithinBatch(request *p.Int
 			request.ExecutionContext,
 			request.State,
 			request.CloseStatus,
+			0, // close timestamp
 			common.FirstEventID,
 			request.LastEventTaskID,
 			request.NextEventID,
@@ -1454,6 +1464,7 @@ func (d *cassandraPersistence) CreateWorkflowExecutionWithinBatch(request *p.Int
 			0,     // decision attempts
 			0,     // decision timestamp(started time)
 			0,     // decision scheduled timestamp
+			0,     // decision original scheduled timestamp
 			false, // cancel_requested
 			"",    // cancel_request_id
 			"",    // sticky_task_list (no sticky tasklist for new workflow execution)
@@ -1476,6 +1487,8 @@ func (d *cassandraPersistence) CreateWorkflowExecutionWithinBatch(request *p.Int
 			request.CronSchedule,
 			request.ExpirationSeconds,
 			request.SearchAttributes,
+			request.Memo,
+			nil, // signal requested timestamps
 			request.ReplicationState.CurrentVersion,
 			request.ReplicationState.StartVersion,
 			request.ReplicationState.LastWriteVersion,
@@ -1630,6 +1643,7 @@ func (d *cassandraPersistence) updateMutableState(batch *gocql.Batch, executionI
 			executionInfo.ExecutionContext,
 			executionInfo.State,
 			executionInfo.CloseStatus,
+			executionInfo.CloseTimestamp,
 			executionInfo.LastFirstEventID,
 			executionInfo.LastEventTaskID,
 			executionInfo.NextEventID,
@@ -1647,6 +1661,7 @@ func (d *cassandraPersistence) updateMutableState(batch *gocql.Batch, executionI
 			executionInfo.DecisionAttempt,
 			executionInfo.DecisionStartedTimestamp,
 			executionInfo.DecisionScheduledTimestamp,
+			executionInfo.DecisionOriginalScheduledTimestamp,
 			executionInfo.CancelRequested,
 			executionInfo.CancelRequestID,
 			executionInfo.StickyTaskList,
@@ -1669,6 +1684,8 @@ func (d *cassandraPersistence) updateMutableState(batch *gocql.Batch, executionI
 			executionInfo.CronSchedule,
 			executionInfo.ExpirationSeconds,
 			executionInfo.SearchAttributes,
+			executionInfo.Memo,
+			executionInfo.SignalRequestedTimestamps,
 			executionInfo.NextEventID,
 			d.shardID,
 			rowTypeExecution,
@@ -1701,6 +1718,7 @@ func (d *cassandraPersistence) updateMutableState(batch *gocql.Batch, executionI
 			executionInfo.ExecutionContext,
 			executionInfo.State,
 			executionInfo.CloseStatus,
+			executionInfo.CloseTimestamp,
 			executionInfo.LastFirstEventID,
 			executionInfo.LastEventTaskID,
 			executionInfo.NextEventID,
@@ -1718,6 +1736,7 @@ func (d *cassandraPersistence) updateMutableState(batch *gocql.Batch, executionI
 			executionInfo.DecisionAttempt,
 			executionInfo.DecisionStartedTimestamp,
 			executionInfo.DecisionScheduledTimestamp,
+			executionInfo.DecisionOriginalScheduledTimestamp,
 			executionInfo.CancelRequested,
 			executionInfo.CancelRequestID,
 			executionInfo.StickyTaskList,
@@ -1740,6 +1759,8 @@ func (d *cassandraPersistence) updateMutableState(batch *gocql.Batch, executionI
 			executionInfo.CronSchedule,
 			executionInfo.ExpirationSeconds,
 			executionInfo.SearchAttributes,
+			executionInfo.Memo,
+			executionInfo.SignalRequestedTimestamps,
 			replicationState.CurrentVersion,
 			replicationState.StartVersion,
 			replicationState.LastWriteVersion,
@@ -3073,9 +3094,22 @@ func (d *cassandraPersistence) createTransferTasks(
 			targetWorkflowID = task.(*p.StartChildExecutionTask).TargetWorkflowID
 			scheduleID = task.(*p.StartChildExecutionTask).InitiatedID
 
+		case p.TransferTaskTypeTerminateChildExecution:
+			targetDomainID = task.(*p.TerminateChildExecutionTask).TargetDomainID
+			targetWorkflowID = task.(*p.TerminateChildExecutionTask).TargetWorkflowID
+			targetRunID = task.(*p.TerminateChildExecutionTask).TargetRunID
+			scheduleID = task.(*p.TerminateChildExecutionTask).InitiatedID
+
+		case p.TransferTaskTypeCancelChildExecution:
+			targetDomainID = task.(*p.CancelChildExecutionTask).TargetDomainID
+			targetWorkflowID = task.(*p.CancelChildExecutionTask).TargetWorkflowID
+			targetRunID = task.(*p.CancelChildExecutionTask).TargetRunID
+			scheduleID = task.(*p.CancelChildExecutionTask).InitiatedID
+
 		case p.TransferTaskTypeCloseExecution,
 			p.TransferTaskTypeRecordWorkflowStarted,
-			p.TransferTaskTypeResetWorkflow:
+			p.TransferTaskTypeResetWorkflow,
+			p.TransferTaskTypeUpsertWorkflowMemo:
 			// No explicit property needs to be set
 
 		default:
@@ -3268,6 +3302,7 @@ func (d *cassandraPersistence) updateActivityInfos(batch *gocql.Batch, activityI
 			a.ExpirationTime,
 			a.MaximumAttempts,
 			a.NonRetriableErrors,
+			a.LastFailureReason,
 			encoding,
 			d.shardID,
 			rowTypeExecution,
@@ -3791,6 +3826,8 @@ func createWorkflowExecutionInfo(result map[string]interface{}) *p.InternalWorkf
 			info.State = v.(int)
 		case "close_status":
 			info.CloseStatus = v.(int)
+		case "close_timestamp":
+			info.CloseTimestamp = v.(int64)
 		case "last_first_event_id":
 			info.LastFirstEventID = v.(int64)
 		case "last_event_task_id":
@@ -3825,6 +3862,8 @@ func createWorkflowExecutionInfo(result map[string]interface{}) *p.InternalWorkf
 			info.DecisionStartedTimestamp = v.(int64)
 		case "decision_scheduled_timestamp":
 			info.DecisionScheduledTimestamp = v.(int64)
+		case "decision_original_scheduled_timestamp":
+			info.DecisionOriginalScheduledTimestamp = v.(int64)
 		case "cancel_requested":
 			info.CancelRequested = v.(bool)
 		case "cancel_request_id":
@@ -3865,6 +3904,10 @@ func createWorkflowExecutionInfo(result map[string]interface{}) *p.InternalWorkf
 			info.ExpirationSeconds = int32(v.(int))
 		case "search_attributes":
 			info.SearchAttributes = v.(map[string][]byte)
+		case "memo":
+			info.Memo = v.(map[string][]byte)
+		case "signal_requested_timestamps":
+			info.SignalRequestedTimestamps = v.(map[string]int64)
 		}
 	}
 	info.CompletionEvent = p.NewDataBlob(completionEventData, completionEventEncoding)
@@ -4049,6 +4092,8 @@ func createActivityInfo(domainID string, result map[string]interface{}) *p.Inter
 			info.ExpirationTime = v.(time.Time)
 		case "non_retriable_errors":
 			info.NonRetriableErrors = v.([]string)
+		case "last_failure_reason":
+			info.LastFailureReason = v.(string)
 		case "event_data_encoding":
 			sharedEncoding = common.EncodingType(v.(string))
 		}
@@ -4224,6 +4269,7 @@ func resetActivityInfoMap(activityInfos []*p.InternalActivityInfo) (map[int64]ma
 		aInfo["expiration_time"] = a.ExpirationTime
 		aInfo["max_attempts"] = a.MaximumAttempts
 		aInfo["non_retriable_errors"] = a.NonRetriableErrors
+		aInfo["last_failure_reason"] = a.LastFailureReason
 
 		aMap[a.ScheduleID] = aInfo
 	}
@@ -101,6 +101,9 @@ const (
 	TransferTaskTypeSignalExecution
 	TransferTaskTypeRecordWorkflowStarted
 	TransferTaskTypeResetWorkflow
+	TransferTaskTypeUpsertWorkflowMemo
+	TransferTaskTypeTerminateChildExecution
+	TransferTaskTypeCancelChildExecution
 )
 
 // Types of replication tasks
@@ -225,48 +228,54 @@ type (
 
 	// WorkflowExecutionInfo describes a workflow execution
 	WorkflowExecutionInfo struct {
-		DomainID                     string
-		WorkflowID                   string
-		RunID                        string
-		ParentDomainID               string
-		ParentWorkflowID             string
-		ParentRunID                  string
-		InitiatedID                  int64
-		CompletionEventBatchID       int64
-		CompletionEvent              *workflow.HistoryEvent
-		TaskList                     string
-		WorkflowTypeName             string
-		WorkflowTimeout              int32
-		DecisionTimeoutValue         int32
-		ExecutionContext             []byte
-		State                        int
-		CloseStatus                  int
-		LastFirstEventID             int64
-		LastEventTaskID              int64
-		NextEventID                  int64
-		LastProcessedEvent           int64
-		StartTimestamp               time.Time
-		LastUpdatedTimestamp         time.Time
-		CreateRequestID              string
-		SignalCount                  int32
-		HistorySize                  int64
-		DecisionVersion              int64
-		DecisionScheduleID           int64
-		DecisionStartedID            int64
-		DecisionRequestID            string
-		DecisionTimeout              int32
-		DecisionAttempt              int64
-		DecisionStartedTimestamp     int64
-		DecisionScheduledTimestamp   int64
-		CancelRequested              bool
-		CancelRequestID              string
-		StickyTaskList               string
-		StickyScheduleToStartTimeout int32
-		ClientLibraryVersion         string
-		ClientFeatureVersion         string
-		ClientImpl                   string
-		AutoResetPoints              *workflow.ResetPoints
-		SearchAttributes             map[string][]byte
+		DomainID                           string
+		WorkflowID                         string
+		RunID                              string
+		ParentDomainID                     string
+		ParentWorkflowID                   string
+		ParentRunID                        string
+		InitiatedID                        int64
+		CompletionEventBatchID             int64
+		CompletionEvent                    *workflow.HistoryEvent
+		TaskList                           string
+		WorkflowTypeName                   string
+		WorkflowTimeout                    int32
+		DecisionTimeoutValue               int32
+		ExecutionContext                   []byte
+		State                              int
+		CloseStatus                        int
+		CloseTimestamp                     int64
+		LastFirstEventID                   int64
+		LastEventTaskID                    int64
+		NextEventID                        int64
+		LastProcessedEvent                 int64
+		StartTimestamp                     time.Time
+		LastUpdatedTimestamp               time.Time
+		CreateRequestID                    string
+		SignalCount                        int32
+		HistorySize                        int64
+		DecisionVersion                    int64
+		DecisionScheduleID                 int64
+		DecisionStartedID                  int64
+		DecisionRequestID                  string
+		DecisionTimeout                    int32
+		DecisionAttempt                    int64
+		DecisionStartedTimestamp           int64
+		DecisionScheduledTimestamp         int64
+		DecisionOriginalScheduledTimestamp int64
+		CancelRequested                    bool
+		CancelRequestID                    string
+		StickyTaskList                     string
+		StickyScheduleToStartTimeout       int32
+		ClientLibraryVersion               string
+		ClientFeatureVersion               string
+		ClientImpl                         string
+		AutoResetPoints                    *workflow.ResetPoints
+		SearchAttributes                   map[string][]byte
+		Memo                               map[string][]byte
+		// SignalRequestedTimestamps is the time in unix nanoseconds each signaled requestId was added, it orders the
+		// eviction of the signaled requestIds
+		SignalRequestedTimestamps map[string]int64
 		// for retry
 		Attempt            int32
 		HasRetryPolicy     bool
@@ -415,6 +424,13 @@ type (
 		Version             int64
 	}
 
+	// UpsertWorkflowMemoTask identifites a transfer task for updating the memo of the visibility open execution record
+	UpsertWorkflowMemoTask struct {
+		VisibilityTimestamp time.Time
+		TaskID              int64
+		Version             int64
+	}
+
 	// CloseExecutionTask identifies a transfer task for deletion of execution
 	CloseExecutionTask struct {
 		VisibilityTimestamp time.Time
@@ -480,6 +496,30 @@ type (
 		Version             int64
 	}
 
+	// TerminateChildExecutionTask identifies a transfer task for terminating a child execution
+	// according to its child policy once the parent is terminated
+	TerminateChildExecutionTask struct {
+		VisibilityTimestamp time.Time
+		TaskID              int64
+		TargetDomainID      string
+		TargetWorkflowID    string
+		TargetRunID         string
+		InitiatedID         int64
+		Version             int64
+	}
+
+	// CancelChildExecutionTask identifies a transfer task for requesting cancellation of a child execution
+	// according to its child policy once the parent is terminated
+	CancelChildExecutionTask struct {
+		VisibilityTimestamp time.Time
+		TaskID              int64
+		TargetDomainID      string
+		TargetWorkflowID    string
+		TargetRunID         string
+		InitiatedID         int64
+		Version             int64
+	}
+
 	// ActivityTimeoutTask identifies a timeout task.
 	ActivityTimeoutTask struct {
 		VisibilityTimestamp time.Time
@@ -592,6 +632,7 @@ type (
 		ExpirationTime     time.Time
 		MaximumAttempts    int32
 		NonRetriableErrors []string
+		LastFailureReason  string
 		// Not written to database - This is used only for deduping heartbeat timer creation
 		LastHeartbeatTimeoutVisibility int64
 	}
@@ -717,6 +758,7 @@ type (
 		CronSchedule      string
 		ExpirationSeconds int32
 		SearchAttributes  map[string][]byte
+		Memo              map[string][]byte
 	}
 
 	// CreateWorkflowExecutionResponse is the response to CreateWorkflowExecutionRequest
@@ -1689,6 +1731,41 @@ func (a *ResetWorkflowTask) SetVisibilityTimestamp(timestamp time.Time) {
 	a.VisibilityTimestamp = timestamp
 }
 
+// GetType returns the type of the UpsertWorkflowMemoTask
+func (a *UpsertWorkflowMemoTask) GetType() int {
+	return TransferTaskTypeUpsertWorkflowMemo
+}
+
+// GetVersion returns the version of the UpsertWorkflowMemoTask
+func (a *UpsertWorkflowMemoTask) GetVersion() int64 {
+	return a.Version
+}
+
+// SetVersion returns the version of the UpsertWorkflowMemoTask
+func (a *UpsertWorkflowMemoTask) SetVersion(version int64) {
+	a.Version = version
+}
+
+// GetTaskID returns the sequence ID of the UpsertWorkflowMemoTask
+func (a *UpsertWorkflowMemoTask) GetTaskID() int64 {
+	return a.TaskID
+}
+
+// SetTaskID sets the sequence ID of the UpsertWorkflowMemoTask
+func (a *UpsertWorkflowMemoTask) SetTaskID(id int64) {
+	a.TaskID = id
+}
+
+// GetVisibilityTimestamp get the visibility timestamp
+func (a *UpsertWorkflowMemoTask) GetVisibilityTimestamp() time.Time {
+	return a.VisibilityTimestamp
+}
+
+// SetVisibilityTimestamp set the visibility timestamp
+func (a *UpsertWorkflowMemoTask) SetVisibilityTimestamp(timestamp time.Time) {
+	a.VisibilityTimestamp = timestamp
+}
+
 // GetType returns the type of the close execution task
 func (a *CloseExecutionTask) GetType() int {
 	return TransferTaskTypeCloseExecution
@@ -2074,6 +2151,76 @@ func (u *StartChildExecutionTask) SetVisibilityTimestamp(timestamp time.Time) {
 	u.VisibilityTimestamp = timestamp
 }
 
+// GetType returns the type of the terminate child transfer task
+func (u *TerminateChildExecutionTask) GetType() int {
+	return TransferTaskTypeTerminateChildExecution
+}
+
+// GetVersion returns the version of the terminate child transfer task
+func (u *TerminateChildExecutionTask) GetVersion() int64 {
+	return u.Version
+}
+
+// SetVersion returns the version of the terminate child transfer task
+func (u *TerminateChildExecutionTask) SetVersion(version int64) {
+	u.Version = version
+}
+
+// GetTaskID returns the sequence ID of the terminate child transfer task
+func (u *TerminateChildExecutionTask) GetTaskID() int64 {
+	return u.TaskID
+}
+
+// SetTaskID sets the sequence ID of the terminate child transfer task
+func (u *TerminateChildExecutionTask) SetTaskID(id int64) {
+	u.TaskID = id
+}
+
+// GetVisibilityTimestamp get the visibility timestamp
+func (u *TerminateChildExecutionTask) GetVisibilityTimestamp() time.Time {
+	return u.VisibilityTimestamp
+}
+
+// SetVisibilityTimestamp set the visibility timestamp
+func (u *TerminateChildExecutionTask) SetVisibilityTimestamp(timestamp time.Time) {
+	u.VisibilityTimestamp = timestamp
+}
+
+// GetType returns the type of the cancel child transfer task
+func (u *CancelChildExecutionTask) GetType() int {
+	return TransferTaskTypeCancelChildExecution
+}
+
+// GetVersion returns the version of the cancel child transfer task
+func (u *CancelChildExecutionTask) GetVersion() int64 {
+	return u.Version
+}
+
+// SetVersion returns the version of the cancel child transfer task
+func (u *CancelChildExecutionTask) SetVersion(version int64) {
+	u.Version = version
+}
+
+// GetTaskID returns the sequence ID of the cancel child transfer task
+func (u *CancelChildExecutionTask) GetTaskID() int64 {
+	return u.TaskID
+}
+
+// SetTaskID sets the sequence ID of the cancel child transfer task
+func (u *CancelChildExecutionTask) SetTaskID(id int64) {
+	u.TaskID = id
+}
+
+// GetVisibilityTimestamp get the visibility timestamp
+func (u *CancelChildExecutionTask) GetVisibilityTimestamp() time.Time {
+	return u.VisibilityTimestamp
+}
+
+// SetVisibilityTimestamp set the visibility timestamp
+func (u *CancelChildExecutionTask) SetVisibilityTimestamp(timestamp time.Time) {
+	u.VisibilityTimestamp = timestamp
+}
+
 // GetType returns the type of the history replication task
 func (a *HistoryReplicationTask) GetType() int {
 	return ReplicationTaskTypeHistory
@@ -2328,3 +2475,13 @@ func NewHistoryBranchTokenFromAnother(branchID string, anotherToken []byte) ([]b
 	}
 	return token, nil
 }
+
+// DeserializeHistoryBranch decodes a branchToken into the history branch it identifies
+func DeserializeHistoryBranch(branchToken []byte) (*workflow.HistoryBranch, error) {
+	var branch workflow.HistoryBranch
+	err := internalThriftEncoder.Decode(branchToken, &branch)
+	if err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
@@ -56,7 +56,7 @@ func (m *executionManagerImpl) GetShardID() int {
 	return m.persistence.GetShardID()
 }
 
-//The below three APIs are related to serialization/deserialization
+// The below three APIs are related to serialization/deserialization
 func (m *executionManagerImpl) GetWorkflowExecution(request *GetWorkflowExecutionRequest) (*GetWorkflowExecutionResponse, error) {
 	response, err := m.persistence.GetWorkflowExecution(request)
 	if err != nil {
@@ -111,59 +111,63 @@ func (m *executionManagerImpl) DeserializeExecutionInfo(info *InternalWorkflowEx
 	newInfo := &WorkflowExecutionInfo{
 		CompletionEvent: completionEvent,
 
-		DomainID:                     info.DomainID,
-		WorkflowID:                   info.WorkflowID,
-		RunID:                        info.RunID,
-		ParentDomainID:               info.ParentDomainID,
-		ParentWorkflowID:             info.ParentWorkflowID,
-		ParentRunID:                  info.ParentRunID,
-		InitiatedID:                  info.InitiatedID,
-		CompletionEventBatchID:       info.CompletionEventBatchID,
-		TaskList:                     info.TaskList,
-		WorkflowTypeName:             info.WorkflowTypeName,
-		WorkflowTimeout:              info.WorkflowTimeout,
-		DecisionTimeoutValue:         info.DecisionTimeoutValue,
-		ExecutionContext:             info.ExecutionContext,
-		State:                        info.State,
-		CloseStatus:                  info.CloseStatus,
-		LastFirstEventID:             info.LastFirstEventID,
-		LastEventTaskID:              info.LastEventTaskID,
-		NextEventID:                  info.NextEventID,
-		LastProcessedEvent:           info.LastProcessedEvent,
-		StartTimestamp:               info.StartTimestamp,
-		LastUpdatedTimestamp:         info.LastUpdatedTimestamp,
-		CreateRequestID:              info.CreateRequestID,
-		SignalCount:                  info.SignalCount,
-		HistorySize:                  info.HistorySize,
-		DecisionVersion:              info.DecisionVersion,
-		DecisionScheduleID:           info.DecisionScheduleID,
-		DecisionStartedID:            info.DecisionStartedID,
-		DecisionRequestID:            info.DecisionRequestID,
-		DecisionTimeout:              info.DecisionTimeout,
-		DecisionAttempt:              info.DecisionAttempt,
-		DecisionStartedTimestamp:     info.DecisionStartedTimestamp,
-		DecisionScheduledTimestamp:   info.DecisionScheduledTimestamp,
-		CancelRequested:              info.CancelRequested,
-		CancelRequestID:              info.CancelRequestID,
-		StickyTaskList:               info.StickyTaskList,
-		StickyScheduleToStartTimeout: info.StickyScheduleToStartTimeout,
-		ClientLibraryVersion:         info.ClientLibraryVersion,
-		ClientFeatureVersion:         info.ClientFeatureVersion,
-		ClientImpl:                   info.ClientImpl,
-		Attempt:                      info.Attempt,
-		HasRetryPolicy:               info.HasRetryPolicy,
-		InitialInterval:              info.InitialInterval,
-		BackoffCoefficient:           info.BackoffCoefficient,
-		MaximumInterval:              info.MaximumInterval,
-		ExpirationTime:               info.ExpirationTime,
-		MaximumAttempts:              info.MaximumAttempts,
-		NonRetriableErrors:           info.NonRetriableErrors,
-		EventStoreVersion:            info.EventStoreVersion,
-		BranchToken:                  info.BranchToken,
-		CronSchedule:                 info.CronSchedule,
-		ExpirationSeconds:            info.ExpirationSeconds,
-		AutoResetPoints:              autoResetPoints,
-		SearchAttributes:             info.SearchAttributes,
+		DomainID:                           info.DomainID,
+		WorkflowID:                         info.WorkflowID,
+		RunID:                              info.RunID,
+		ParentDomainID:                     info.ParentDomainID,
+		ParentWorkflowID:                   info.ParentWorkflowID,
+		ParentRunID:                        info.ParentRunID,
+		InitiatedID:                        info.InitiatedID,
+		CompletionEventBatchID:             info.CompletionEventBatchID,
+		TaskList:                           info.TaskList,
+		WorkflowTypeName:                   info.WorkflowTypeName,
+		WorkflowTimeout:                    info.WorkflowTimeout,
+		DecisionTimeoutValue:               info.DecisionTimeoutValue,
+		ExecutionContext:                   info.ExecutionContext,
+		State:                              info.State,
+		CloseStatus:                        info.CloseStatus,
+		CloseTimestamp:                     info.CloseTimestamp,
+		LastFirstEventID:                   info.LastFirstEventID,
+		LastEventTaskID:                    info.LastEventTaskID,
+		NextEventID:                        info.NextEventID,
+		LastProcessedEvent:                 info.LastProcessedEvent,
+		StartTimestamp:                     info.StartTimestamp,
+		LastUpdatedTimestamp:               info.LastUpdatedTimestamp,
+		CreateRequestID:                    info.CreateRequestID,
+		SignalCount:                        info.SignalCount,
+		HistorySize:                        info.HistorySize,
+		DecisionVersion:                    info.DecisionVersion,
+		DecisionScheduleID:                 info.DecisionScheduleID,
+		DecisionStartedID:                  info.DecisionStartedID,
+		DecisionRequestID:                  info.DecisionRequestID,
+		DecisionTimeout:                    info.DecisionTimeout,
+		DecisionAttempt:                    info.DecisionAttempt,
+		DecisionStartedTimestamp:           info.DecisionStartedTimestamp,
+		DecisionScheduledTimestamp:         info.DecisionScheduledTimestamp,
+		DecisionOriginalScheduledTimestamp: info.DecisionOriginalScheduledTimestamp,
+		CancelRequested:                    info.CancelRequested,
+		CancelRequestID:                    info.CancelRequestID,
+		StickyTaskList:                     info.StickyTaskList,
+		StickyScheduleToStartTimeout:       info.StickyScheduleToStartTimeout,
+		ClientLibraryVersion:               info.ClientLibraryVersion,
+		ClientFeatureVersion:               info.ClientFeatureVersion,
+		ClientImpl:                         info.ClientImpl,
+		Attempt:                            info.Attempt,
+		HasRetryPolicy:                     info.HasRetryPolicy,
+		InitialInterval:                    info.InitialInterval,
+		BackoffCoefficient:                 info.BackoffCoefficient,
+		MaximumInterval:                    info.MaximumInterval,
+		ExpirationTime:                     info.ExpirationTime,
+		MaximumAttempts:                    info.MaximumAttempts,
+		NonRetriableErrors:                 info.NonRetriableErrors,
+		EventStoreVersion:                  info.EventStoreVersion,
+		BranchToken:                        info.BranchToken,
+		CronSchedule:                       info.CronSchedule,
+		ExpirationSeconds:                  info.ExpirationSeconds,
+		AutoResetPoints:                    autoResetPoints,
+		SearchAttributes:                   info.SearchAttributes,
+		Memo:                               info.Memo,
+		SignalRequestedTimestamps:          info.SignalRequestedTimestamps,
 	}
 	return newInfo, nil
 }
@@ -291,6 +295,7 @@ func (m *executionManagerImpl) DeserializeActivityInfos(infos map[int64]*Interna
 			ExpirationTime:                 v.ExpirationTime,
 			MaximumAttempts:                v.MaximumAttempts,
 			NonRetriableErrors:             v.NonRetriableErrors,
+			LastFailureReason:              v.LastFailureReason,
 			LastHeartbeatTimeoutVisibility: v.LastHeartbeatTimeoutVisibility,
 		}
 		newInfos[k] = a
@@ -464,6 +469,7 @@ func (m *executionManagerImpl) SerializeUpsertActivityInfos(infos []*ActivityInf
 			ExpirationTime:                 v.ExpirationTime,
 			MaximumAttempts:                v.MaximumAttempts,
 			NonRetriableErrors:             v.NonRetriableErrors,
+			LastFailureReason:              v.LastFailureReason,
 			LastHeartbeatTimeoutVisibility: v.LastHeartbeatTimeoutVisibility,
 		}
 		newInfos = append(newInfos, i)
@@ -486,60 +492,64 @@ func (m *executionManagerImpl) SerializeExecutionInfo(info *WorkflowExecutionInf
 	}
 
 	return &InternalWorkflowExecutionInfo{
-		DomainID:                     info.DomainID,
-		WorkflowID:                   info.WorkflowID,
-		RunID:                        info.RunID,
-		ParentDomainID:               info.ParentDomainID,
-		ParentWorkflowID:             info.ParentWorkflowID,
-		ParentRunID:                  info.ParentRunID,
-		InitiatedID:                  info.InitiatedID,
-		CompletionEventBatchID:       info.CompletionEventBatchID,
-		CompletionEvent:              completionEvent,
-		TaskList:                     info.TaskList,
-		WorkflowTypeName:             info.WorkflowTypeName,
-		WorkflowTimeout:              info.WorkflowTimeout,
-		DecisionTimeoutValue:         info.DecisionTimeoutValue,
-		ExecutionContext:             info.ExecutionContext,
-		State:                        info.State,
-		CloseStatus:                  info.CloseStatus,
-		LastFirstEventID:             info.LastFirstEventID,
-		LastEventTaskID:              info.LastEventTaskID,
-		NextEventID:                  info.NextEventID,
-		LastProcessedEvent:           info.LastProcessedEvent,
-		StartTimestamp:               info.StartTimestamp,
-		LastUpdatedTimestamp:         info.LastUpdatedTimestamp,
-		CreateRequestID:              info.CreateRequestID,
-		SignalCount:                  info.SignalCount,
-		HistorySize:                  info.HistorySize,
-		DecisionVersion:              info.DecisionVersion,
-		DecisionScheduleID:           info.DecisionScheduleID,
-		DecisionStartedID:            info.DecisionStartedID,
-		DecisionRequestID:            info.DecisionRequestID,
-		DecisionTimeout:              info.DecisionTimeout,
-		DecisionAttempt:              info.DecisionAttempt,
-		DecisionStartedTimestamp:     info.DecisionStartedTimestamp,
-		DecisionScheduledTimestamp:   info.DecisionScheduledTimestamp,
-		CancelRequested:              info.CancelRequested,
-		CancelRequestID:              info.CancelRequestID,
-		StickyTaskList:               info.StickyTaskList,
-		StickyScheduleToStartTimeout: info.StickyScheduleToStartTimeout,
-		ClientLibraryVersion:         info.ClientLibraryVersion,
-		ClientFeatureVersion:         info.ClientFeatureVersion,
-		ClientImpl:                   info.ClientImpl,
-		AutoResetPoints:              resetPoints,
-		Attempt:                      info.Attempt,
-		HasRetryPolicy:               info.HasRetryPolicy,
-		InitialInterval:              info.InitialInterval,
-		BackoffCoefficient:           info.BackoffCoefficient,
-		MaximumInterval:              info.MaximumInterval,
-		ExpirationTime:               info.ExpirationTime,
-		MaximumAttempts:              info.MaximumAttempts,
-		NonRetriableErrors:           info.NonRetriableErrors,
-		EventStoreVersion:            info.EventStoreVersion,
-		BranchToken:                  info.BranchToken,
-		CronSchedule:                 info.CronSchedule,
-		ExpirationSeconds:            info.ExpirationSeconds,
-		SearchAttributes:             info.SearchAttributes,
+		DomainID:                           info.DomainID,
+		WorkflowID:                         info.WorkflowID,
+		RunID:                              info.RunID,
+		ParentDomainID:                     info.ParentDomainID,
+		ParentWorkflowID:                   info.ParentWorkflowID,
+		ParentRunID:                        info.ParentRunID,
+		InitiatedID:                        info.InitiatedID,
+		CompletionEventBatchID:             info.CompletionEventBatchID,
+		CompletionEvent:                    completionEvent,
+		TaskList:                           info.TaskList,
+		WorkflowTypeName:                   info.WorkflowTypeName,
+		WorkflowTimeout:                    info.WorkflowTimeout,
+		DecisionTimeoutValue:               info.DecisionTimeoutValue,
+		ExecutionContext:                   info.ExecutionContext,
+		State:                              info.State,
+		CloseStatus:                        info.CloseStatus,
+		CloseTimestamp:                     info.CloseTimestamp,
+		LastFirstEventID:                   info.LastFirstEventID,
+		LastEventTaskID:                    info.LastEventTaskID,
+		NextEventID:                        info.NextEventID,
+		LastProcessedEvent:                 info.LastProcessedEvent,
+		StartTimestamp:                     info.StartTimestamp,
+		LastUpdatedTimestamp:               info.LastUpdatedTimestamp,
+		CreateRequestID:                    info.CreateRequestID,
+		SignalCount:                        info.SignalCount,
+		HistorySize:                        info.HistorySize,
+		DecisionVersion:                    info.DecisionVersion,
+		DecisionScheduleID:                 info.DecisionScheduleID,
+		DecisionStartedID:                  info.DecisionStartedID,
+		DecisionRequestID:                  info.DecisionRequestID,
+		DecisionTimeout:                    info.DecisionTimeout,
+		DecisionAttempt:                    info.DecisionAttempt,
+		DecisionStartedTimestamp:           info.DecisionStartedTimestamp,
+		DecisionScheduledTimestamp:         info.DecisionScheduledTimestamp,
+		DecisionOriginalScheduledTimestamp: info.DecisionOriginalScheduledTimestamp,
+		CancelRequested:                    info.CancelRequested,
+		CancelRequestID:                    info.CancelRequestID,
+		StickyTaskList:                     info.StickyTaskList,
+		StickyScheduleToStartTimeout:       info.StickyScheduleToStartTimeout,
+		ClientLibraryVersion:               info.ClientLibraryVersion,
+		ClientFeatureVersion:               info.ClientFeatureVersion,
+		ClientImpl:                         info.ClientImpl,
+		AutoResetPoints:                    resetPoints,
+		Attempt:                            info.Attempt,
+		HasRetryPolicy:                     info.HasRetryPolicy,
+		InitialInterval:                    info.InitialInterval,
+		BackoffCoefficient:                 info.BackoffCoefficient,
+		MaximumInterval:                    info.MaximumInterval,
+		ExpirationTime:                     info.ExpirationTime,
+		MaximumAttempts:                    info.MaximumAttempts,
+		NonRetriableErrors:                 info.NonRetriableErrors,
+		EventStoreVersion:                  info.EventStoreVersion,
+		BranchToken:                        info.BranchToken,
+		CronSchedule:                       info.CronSchedule,
+		ExpirationSeconds:                  info.ExpirationSeconds,
+		SearchAttributes:                   info.SearchAttributes,
+		Memo:                               info.Memo,
+		SignalRequestedTimestamps:          info.SignalRequestedTimestamps,
 	}, nil
 }
 
@@ -696,6 +706,7 @@ func (m *executionManagerImpl) SerializeCreateWorkflowExecutionRequest(request *
 		CronSchedule:                request.CronSchedule,
 		ExpirationSeconds:           request.ExpirationSeconds,
 		SearchAttributes:            request.SearchAttributes,
+		Memo:                        request.Memo,
 	}, nil
 }
 
@@ -885,6 +885,9 @@ func (s *ExecutionManagerSuite) TestUpdateWorkflow() {
 	updatedInfo.DecisionAttempt = int64(123)
 	updatedInfo.DecisionStartedTimestamp = int64(321)
 	updatedInfo.DecisionScheduledTimestamp = int64(654)
+	updatedInfo.DecisionOriginalScheduledTimestamp = int64(655)
+	updatedInfo.CloseTimestamp = int64(987)
+	updatedInfo.SignalRequestedTimestamps = map[string]int64{uuid.New(): int64(789)}
 	updatedInfo.StickyTaskList = "random sticky tasklist"
 	updatedInfo.StickyScheduleToStartTimeout = 876
 	updatedInfo.ClientLibraryVersion = "random client library version"
@@ -931,6 +934,9 @@ func (s *ExecutionManagerSuite) TestUpdateWorkflow() {
 	s.Equal(int64(123), info1.DecisionAttempt)
 	s.Equal(int64(321), info1.DecisionStartedTimestamp)
 	s.Equal(int64(654), info1.DecisionScheduledTimestamp)
+	s.Equal(int64(655), info1.DecisionOriginalScheduledTimestamp)
+	s.Equal(int64(987), info1.CloseTimestamp)
+	s.Equal(updatedInfo.SignalRequestedTimestamps, info1.SignalRequestedTimestamps)
 	s.Equal(updatedInfo.StickyTaskList, info1.StickyTaskList)
 	s.Equal(updatedInfo.StickyScheduleToStartTimeout, info1.StickyScheduleToStartTimeout)
 	s.Equal(updatedInfo.ClientLibraryVersion, info1.ClientLibraryVersion)
@@ -2006,6 +2012,7 @@ func (s *ExecutionManagerSuite) TestWorkflowMutableStateActivities() {
 		BackoffCoefficient:       5.55,
 		ExpirationTime:           currentTime,
 		NonRetriableErrors:       []string{"accessDenied", "badRequest"},
+		LastFailureReason:        "some random reason",
 	}}
 	err2 := s.UpdateWorkflowExecution(updatedInfo, []int64{int64(4)}, nil, int64(3), nil, activityInfos, nil, nil, nil)
 	s.NoError(err2)
@@ -2048,6 +2055,7 @@ func (s *ExecutionManagerSuite) TestWorkflowMutableStateActivities() {
 	s.Equal(activityInfos[0].BackoffCoefficient, ai.BackoffCoefficient)
 	s.EqualTimes(activityInfos[0].ExpirationTime, ai.ExpirationTime)
 	s.Equal(activityInfos[0].NonRetriableErrors, ai.NonRetriableErrors)
+	s.Equal(activityInfos[0].LastFailureReason, ai.LastFailureReason)
 
 	err2 = s.UpdateWorkflowExecution(updatedInfo, nil, nil, int64(5), nil, nil, []int64{1}, nil, nil)
 	s.NoError(err2)
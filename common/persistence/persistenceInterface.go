@@ -197,51 +197,54 @@ type (
 		CronSchedule      string
 		ExpirationSeconds int32
 		SearchAttributes  map[string][]byte
+		Memo              map[string][]byte
 	}
 
 	// InternalWorkflowExecutionInfo describes a workflow execution for Persistence Interface
 	InternalWorkflowExecutionInfo struct {
-		DomainID                     string
-		WorkflowID                   string
-		RunID                        string
-		ParentDomainID               string
-		ParentWorkflowID             string
-		ParentRunID                  string
-		InitiatedID                  int64
-		CompletionEventBatchID       int64
-		CompletionEvent              *DataBlob
-		TaskList                     string
-		WorkflowTypeName             string
-		WorkflowTimeout              int32
-		DecisionTimeoutValue         int32
-		ExecutionContext             []byte
-		State                        int
-		CloseStatus                  int
-		LastFirstEventID             int64
-		LastEventTaskID              int64
-		NextEventID                  int64
-		LastProcessedEvent           int64
-		StartTimestamp               time.Time
-		LastUpdatedTimestamp         time.Time
-		CreateRequestID              string
-		SignalCount                  int32
-		HistorySize                  int64
-		DecisionVersion              int64
-		DecisionScheduleID           int64
-		DecisionStartedID            int64
-		DecisionRequestID            string
-		DecisionTimeout              int32
-		DecisionAttempt              int64
-		DecisionStartedTimestamp     int64
-		DecisionScheduledTimestamp   int64
-		CancelRequested              bool
-		CancelRequestID              string
-		StickyTaskList               string
-		StickyScheduleToStartTimeout int32
-		ClientLibraryVersion         string
-		ClientFeatureVersion         string
-		ClientImpl                   string
-		AutoResetPoints              *DataBlob
+		DomainID                           string
+		WorkflowID                         string
+		RunID                              string
+		ParentDomainID                     string
+		ParentWorkflowID                   string
+		ParentRunID                        string
+		InitiatedID                        int64
+		CompletionEventBatchID             int64
+		CompletionEvent                    *DataBlob
+		TaskList                           string
+		WorkflowTypeName                   string
+		WorkflowTimeout                    int32
+		DecisionTimeoutValue               int32
+		ExecutionContext                   []byte
+		State                              int
+		CloseStatus                        int
+		CloseTimestamp                     int64
+		LastFirstEventID                   int64
+		LastEventTaskID                    int64
+		NextEventID                        int64
+		LastProcessedEvent                 int64
+		StartTimestamp                     time.Time
+		LastUpdatedTimestamp               time.Time
+		CreateRequestID                    string
+		SignalCount                        int32
+		HistorySize                        int64
+		DecisionVersion                    int64
+		DecisionScheduleID                 int64
+		DecisionStartedID                  int64
+		DecisionRequestID                  string
+		DecisionTimeout                    int32
+		DecisionAttempt                    int64
+		DecisionStartedTimestamp           int64
+		DecisionScheduledTimestamp         int64
+		DecisionOriginalScheduledTimestamp int64
+		CancelRequested                    bool
+		CancelRequestID                    string
+		StickyTaskList                     string
+		StickyScheduleToStartTimeout       int32
+		ClientLibraryVersion               string
+		ClientFeatureVersion               string
+		ClientImpl                         string
+		AutoResetPoints                    *DataBlob
 		// for retry
 		Attempt            int32
 		HasRetryPolicy     bool
@@ -257,6 +260,9 @@ type (
 		CronSchedule      string
 		ExpirationSeconds int32
 		SearchAttributes  map[string][]byte
+		Memo              map[string][]byte
+
+		SignalRequestedTimestamps map[string]int64
 	}
 
 	// InternalWorkflowMutableState indicates workflow related state for Persistence Interface
@@ -306,6 +312,7 @@ type (
 		ExpirationTime     time.Time
 		MaximumAttempts    int32
 		NonRetriableErrors []string
+		LastFailureReason  string
 		// Not written to database - This is used only for deduping heartbeat timer creation
 		LastHeartbeatTimeoutVisibility int64
 	}
@@ -205,51 +205,55 @@ func (m *sqlExecutionManager) GetWorkflowExecution(request *p.GetWorkflowExecuti
 
 	var state p.InternalWorkflowMutableState
 	state.ExecutionInfo = &p.InternalWorkflowExecutionInfo{
-		DomainID:                     execution.DomainID.String(),
-		WorkflowID:                   execution.WorkflowID,
-		RunID:                        execution.RunID.String(),
-		NextEventID:                  execution.NextEventID,
-		TaskList:                     info.GetTaskList(),
-		WorkflowTypeName:             info.GetWorkflowTypeName(),
-		WorkflowTimeout:              info.GetWorkflowTimeoutSeconds(),
-		DecisionTimeoutValue:         info.GetDecisionTaskTimeoutSeconds(),
-		State:                        int(info.GetState()),
-		CloseStatus:                  int(info.GetCloseStatus()),
-		LastFirstEventID:             info.GetLastFirstEventID(),
-		LastProcessedEvent:           info.GetLastProcessedEvent(),
-		StartTimestamp:               time.Unix(0, info.GetStartTimeNanos()),
-		LastUpdatedTimestamp:         time.Unix(0, info.GetLastUpdatedTimeNanos()),
-		CreateRequestID:              info.GetCreateRequestID(),
-		DecisionVersion:              info.GetDecisionVersion(),
-		DecisionScheduleID:           info.GetDecisionScheduleID(),
-		DecisionStartedID:            info.GetDecisionStartedID(),
-		DecisionRequestID:            info.GetDecisionRequestID(),
-		DecisionTimeout:              info.GetDecisionTimeout(),
-		DecisionAttempt:              info.GetDecisionAttempt(),
-		DecisionStartedTimestamp:     info.GetDecisionStartedTimestampNanos(),
-		DecisionScheduledTimestamp:   info.GetDecisionScheduledTimestampNanos(),
-		StickyTaskList:               info.GetStickyTaskList(),
-		StickyScheduleToStartTimeout: int32(info.GetStickyScheduleToStartTimeout()),
-		ClientLibraryVersion:         info.GetClientLibraryVersion(),
-		ClientFeatureVersion:         info.GetClientFeatureVersion(),
-		ClientImpl:                   info.GetClientImpl(),
-		SignalCount:                  int32(info.GetSignalCount()),
-		HistorySize:                  info.GetHistorySize(),
-		CronSchedule:                 info.GetCronSchedule(),
-		CompletionEventBatchID:       common.EmptyEventID,
-		HasRetryPolicy:               info.GetHasRetryPolicy(),
-		Attempt:                      int32(info.GetRetryAttempt()),
-		InitialInterval:              info.GetRetryInitialIntervalSeconds(),
-		BackoffCoefficient:           info.GetRetryBackoffCoefficient(),
-		MaximumInterval:              info.GetRetryMaximumIntervalSeconds(),
-		MaximumAttempts:              info.GetRetryMaximumAttempts(),
-		ExpirationSeconds:            info.GetRetryExpirationSeconds(),
-		ExpirationTime:               time.Unix(0, info.GetRetryExpirationTimeNanos()),
-		EventStoreVersion:            info.GetEventStoreVersion(),
-		BranchToken:                  info.GetEventBranchToken(),
-		ExecutionContext:             info.GetExecutionContext(),
-		NonRetriableErrors:           info.GetRetryNonRetryableErrors(),
-		SearchAttributes:             info.GetSearchAttributes(),
+		DomainID:                           execution.DomainID.String(),
+		WorkflowID:                         execution.WorkflowID,
+		RunID:                              execution.RunID.String(),
+		NextEventID:                        execution.NextEventID,
+		TaskList:                           info.GetTaskList(),
+		WorkflowTypeName:                   info.GetWorkflowTypeName(),
+		WorkflowTimeout:                    info.GetWorkflowTimeoutSeconds(),
+		DecisionTimeoutValue:               info.GetDecisionTaskTimeoutSeconds(),
+		State:                              int(info.GetState()),
+		CloseStatus:                        int(info.GetCloseStatus()),
+		CloseTimestamp:                     info.GetCloseTimestampNanos(),
+		LastFirstEventID:                   info.GetLastFirstEventID(),
+		LastProcessedEvent:                 info.GetLastProcessedEvent(),
+		StartTimestamp:                     time.Unix(0, info.GetStartTimeNanos()),
+		LastUpdatedTimestamp:               time.Unix(0, info.GetLastUpdatedTimeNanos()),
+		CreateRequestID:                    info.GetCreateRequestID(),
+		DecisionVersion:                    info.GetDecisionVersion(),
+		DecisionScheduleID:                 info.GetDecisionScheduleID(),
+		DecisionStartedID:                  info.GetDecisionStartedID(),
+		DecisionRequestID:                  info.GetDecisionRequestID(),
+		DecisionTimeout:                    info.GetDecisionTimeout(),
+		DecisionAttempt:                    info.GetDecisionAttempt(),
+		DecisionStartedTimestamp:           info.GetDecisionStartedTimestampNanos(),
+		DecisionScheduledTimestamp:         info.GetDecisionScheduledTimestampNanos(),
+		DecisionOriginalScheduledTimestamp: info.GetDecisionOriginalScheduledTimestampNanos(),
+		StickyTaskList:                     info.GetStickyTaskList(),
+		StickyScheduleToStartTimeout:       int32(info.GetStickyScheduleToStartTimeout()),
+		ClientLibraryVersion:               info.GetClientLibraryVersion(),
+		ClientFeatureVersion:               info.GetClientFeatureVersion(),
+		ClientImpl:                         info.GetClientImpl(),
+		SignalCount:                        int32(info.GetSignalCount()),
+		HistorySize:                        info.GetHistorySize(),
+		CronSchedule:                       info.GetCronSchedule(),
+		CompletionEventBatchID:             common.EmptyEventID,
+		HasRetryPolicy:                     info.GetHasRetryPolicy(),
+		Attempt:                            int32(info.GetRetryAttempt()),
+		InitialInterval:                    info.GetRetryInitialIntervalSeconds(),
+		BackoffCoefficient:                 info.GetRetryBackoffCoefficient(),
+		MaximumInterval:                    info.GetRetryMaximumIntervalSeconds(),
+		MaximumAttempts:                    info.GetRetryMaximumAttempts(),
+		ExpirationSeconds:                  info.GetRetryExpirationSeconds(),
+		ExpirationTime:                     time.Unix(0, info.GetRetryExpirationTimeNanos()),
+		EventStoreVersion:                  info.GetEventStoreVersion(),
+		BranchToken:                        info.GetEventBranchToken(),
+		ExecutionContext:                   info.GetExecutionContext(),
+		NonRetriableErrors:                 info.GetRetryNonRetryableErrors(),
+		SearchAttributes:                   info.GetSearchAttributes(),
+		Memo:                               info.GetMemo(),
+		SignalRequestedTimestamps:          info.GetSignalRequestedTimestamps(),
 	}
 
 	if info.LastWriteEventID != nil {
@@ -1444,46 +1448,49 @@ func createExecutionFromRequest(
 	zeroPtr := common.Int64Ptr(0)
 	lastWriteVersion := common.EmptyVersion
 	info := &sqlblobs.WorkflowExecutionInfo{
-		TaskList:                        &request.TaskList,
-		WorkflowTypeName:                &request.WorkflowTypeName,
-		WorkflowTimeoutSeconds:          &request.WorkflowTimeout,
-		DecisionTaskTimeoutSeconds:      &request.DecisionTimeoutValue,
-		State:                           common.Int32Ptr(int32(request.State)),
-		CloseStatus:                     common.Int32Ptr(int32(request.CloseStatus)),
-		LastFirstEventID:                common.Int64Ptr(common.FirstEventID),
-		LastEventTaskID:                 &request.LastEventTaskID,
-		LastProcessedEvent:              &request.LastProcessedEvent,
-		StartTimeNanos:                  common.Int64Ptr(nowTimestamp.UnixNano()),
-		LastUpdatedTimeNanos:            common.Int64Ptr(nowTimestamp.UnixNano()),
-		CreateRequestID:                 &request.RequestID,
-		DecisionVersion:                 &request.DecisionVersion,
-		DecisionScheduleID:              &request.DecisionScheduleID,
-		DecisionStartedID:               &request.DecisionStartedID,
-		DecisionTimeout:                 &request.DecisionStartToCloseTimeout,
-		DecisionAttempt:                 zeroPtr,
-		DecisionStartedTimestampNanos:   zeroPtr,
-		DecisionScheduledTimestampNanos: zeroPtr,
-		StickyTaskList:                  &emptyStr,
-		StickyScheduleToStartTimeout:    zeroPtr,
-		ClientLibraryVersion:            &emptyStr,
-		ClientFeatureVersion:            &emptyStr,
-		ClientImpl:                      &emptyStr,
-		SignalCount:                     common.Int64Ptr(int64(request.SignalCount)),
-		HistorySize:                     &request.HistorySize,
-		CronSchedule:                    &request.CronSchedule,
-		HasRetryPolicy:                  common.BoolPtr(request.HasRetryPolicy),
-		RetryAttempt:                    common.Int64Ptr(int64(request.Attempt)),
-		RetryInitialIntervalSeconds:     &request.InitialInterval,
-		RetryBackoffCoefficient:         &request.BackoffCoefficient,
-		RetryMaximumIntervalSeconds:     &request.MaximumInterval,
-		RetryMaximumAttempts:            &request.MaximumAttempts,
-		RetryExpirationSeconds:          &request.ExpirationSeconds,
-		RetryExpirationTimeNanos:        common.Int64Ptr(request.ExpirationTime.UnixNano()),
-		RetryNonRetryableErrors:         request.NonRetriableErrors,
-		ExecutionContext:                request.ExecutionContext,
-		AutoResetPoints:                 request.PreviousAutoResetPoints.Data,
-		AutoResetPointsEncoding:         common.StringPtr(string(request.PreviousAutoResetPoints.GetEncoding())),
-		SearchAttributes:                request.SearchAttributes,
+		TaskList:                                &request.TaskList,
+		WorkflowTypeName:                        &request.WorkflowTypeName,
+		WorkflowTimeoutSeconds:                  &request.WorkflowTimeout,
+		DecisionTaskTimeoutSeconds:              &request.DecisionTimeoutValue,
+		State:                                   common.Int32Ptr(int32(request.State)),
+		CloseStatus:                             common.Int32Ptr(int32(request.CloseStatus)),
+		CloseTimestampNanos:                     zeroPtr,
+		LastFirstEventID:                        common.Int64Ptr(common.FirstEventID),
+		LastEventTaskID:                         &request.LastEventTaskID,
+		LastProcessedEvent:                      &request.LastProcessedEvent,
+		StartTimeNanos:                          common.Int64Ptr(nowTimestamp.UnixNano()),
+		LastUpdatedTimeNanos:                    common.Int64Ptr(nowTimestamp.UnixNano()),
+		CreateRequestID:                         &request.RequestID,
+		DecisionVersion:                         &request.DecisionVersion,
+		DecisionScheduleID:                      &request.DecisionScheduleID,
+		DecisionStartedID:                       &request.DecisionStartedID,
+		DecisionTimeout:                         &request.DecisionStartToCloseTimeout,
+		DecisionAttempt:                         zeroPtr,
+		DecisionStartedTimestampNanos:           zeroPtr,
+		DecisionScheduledTimestampNanos:         zeroPtr,
+		DecisionOriginalScheduledTimestampNanos: zeroPtr,
+		StickyTaskList:                          &emptyStr,
+		StickyScheduleToStartTimeout:            zeroPtr,
+		ClientLibraryVersion:                    &emptyStr,
+		ClientFeatureVersion:                    &emptyStr,
+		ClientImpl:                              &emptyStr,
+		SignalCount:                             common.Int64Ptr(int64(request.SignalCount)),
+		HistorySize:                             &request.HistorySize,
+		CronSchedule:                            &request.CronSchedule,
+		HasRetryPolicy:                          common.BoolPtr(request.HasRetryPolicy),
+		RetryAttempt:                            common.Int64Ptr(int64(request.Attempt)),
+		RetryInitialIntervalSeconds:             &request.InitialInterval,
+		RetryBackoffCoefficient:                 &request.BackoffCoefficient,
+		RetryMaximumIntervalSeconds:             &request.MaximumInterval,
+		RetryMaximumAttempts:                    &request.MaximumAttempts,
+		RetryExpirationSeconds:                  &request.ExpirationSeconds,
+		RetryExpirationTimeNanos:                common.Int64Ptr(request.ExpirationTime.UnixNano()),
+		RetryNonRetryableErrors:                 request.NonRetriableErrors,
+		ExecutionContext:                        request.ExecutionContext,
+		AutoResetPoints:                         request.PreviousAutoResetPoints.Data,
+		AutoResetPointsEncoding:                 common.StringPtr(string(request.PreviousAutoResetPoints.GetEncoding())),
+		SearchAttributes:                        request.SearchAttributes,
+		Memo:                                    request.Memo,
 	}
 	if request.ReplicationState != nil {
 		lastWriteVersion = request.ReplicationState.LastWriteVersion
@@ -1675,9 +1682,22 @@ func createTransferTasks(tx sqldb.Tx, transferTasks []p.Task, shardID int, domai
 			info.TargetWorkflowID = &task.(*p.StartChildExecutionTask).TargetWorkflowID
 			info.ScheduleID = &task.(*p.StartChildExecutionTask).InitiatedID
 
+		case p.TransferTaskTypeTerminateChildExecution:
+			info.TargetDomainID = sqldb.MustParseUUID(task.(*p.TerminateChildExecutionTask).TargetDomainID)
+			info.TargetWorkflowID = &task.(*p.TerminateChildExecutionTask).TargetWorkflowID
+			info.TargetRunID = sqldb.MustParseUUID(task.(*p.TerminateChildExecutionTask).TargetRunID)
+			info.ScheduleID = &task.(*p.TerminateChildExecutionTask).InitiatedID
+
+		case p.TransferTaskTypeCancelChildExecution:
+			info.TargetDomainID = sqldb.MustParseUUID(task.(*p.CancelChildExecutionTask).TargetDomainID)
+			info.TargetWorkflowID = &task.(*p.CancelChildExecutionTask).TargetWorkflowID
+			info.TargetRunID = sqldb.MustParseUUID(task.(*p.CancelChildExecutionTask).TargetRunID)
+			info.ScheduleID = &task.(*p.CancelChildExecutionTask).InitiatedID
+
 		case p.TransferTaskTypeCloseExecution,
 			p.TransferTaskTypeRecordWorkflowStarted,
-			p.TransferTaskTypeResetWorkflow:
+			p.TransferTaskTypeResetWorkflow,
+			p.TransferTaskTypeUpsertWorkflowMemo:
 			// No explicit property needs to be set
 
 		default:
@@ -1993,50 +2013,54 @@ func buildExecutionRow(executionInfo *p.InternalWorkflowExecutionInfo,
 	shardID int) (row *sqldb.ExecutionsRow, err error) {
 	lastWriteVersion := common.EmptyVersion
 	info := &sqlblobs.WorkflowExecutionInfo{
-		TaskList:                        &executionInfo.TaskList,
-		WorkflowTypeName:                &executionInfo.WorkflowTypeName,
-		WorkflowTimeoutSeconds:          &executionInfo.WorkflowTimeout,
-		DecisionTaskTimeoutSeconds:      &executionInfo.DecisionTimeoutValue,
-		ExecutionContext:                executionInfo.ExecutionContext,
-		State:                           common.Int32Ptr(int32(executionInfo.State)),
-		CloseStatus:                     common.Int32Ptr(int32(executionInfo.CloseStatus)),
-		LastFirstEventID:                &executionInfo.LastFirstEventID,
-		LastProcessedEvent:              &executionInfo.LastProcessedEvent,
-		StartTimeNanos:                  common.Int64Ptr(executionInfo.StartTimestamp.UnixNano()),
-		LastUpdatedTimeNanos:            common.TimeNowNanosPtr(),
-		CreateRequestID:                 &executionInfo.CreateRequestID,
-		DecisionVersion:                 &executionInfo.DecisionVersion,
-		DecisionScheduleID:              &executionInfo.DecisionScheduleID,
-		DecisionStartedID:               &executionInfo.DecisionStartedID,
-		DecisionRequestID:               &executionInfo.DecisionRequestID,
-		DecisionTimeout:                 &executionInfo.DecisionTimeout,
-		DecisionAttempt:                 &executionInfo.DecisionAttempt,
-		DecisionStartedTimestampNanos:   &executionInfo.DecisionStartedTimestamp,
-		DecisionScheduledTimestampNanos: &executionInfo.DecisionScheduledTimestamp,
-		StickyTaskList:                  &executionInfo.StickyTaskList,
-		StickyScheduleToStartTimeout:    common.Int64Ptr(int64(executionInfo.StickyScheduleToStartTimeout)),
-		ClientLibraryVersion:            &executionInfo.ClientLibraryVersion,
-		ClientFeatureVersion:            &executionInfo.ClientFeatureVersion,
-		ClientImpl:                      &executionInfo.ClientImpl,
-		CurrentVersion:                  common.Int64Ptr(common.EmptyVersion),
-		SignalCount:                     common.Int64Ptr(int64(executionInfo.SignalCount)),
-		HistorySize:                     &executionInfo.HistorySize,
-		CronSchedule:                    &executionInfo.CronSchedule,
-		CompletionEventBatchID:          &executionInfo.CompletionEventBatchID,
-		HasRetryPolicy:                  &executionInfo.HasRetryPolicy,
-		RetryAttempt:                    common.Int64Ptr(int64(executionInfo.Attempt)),
-		RetryInitialIntervalSeconds:     &executionInfo.InitialInterval,
-		RetryBackoffCoefficient:         &executionInfo.BackoffCoefficient,
-		RetryMaximumIntervalSeconds:     &executionInfo.MaximumInterval,
-		RetryMaximumAttempts:            &executionInfo.MaximumAttempts,
-		RetryExpirationSeconds:          &executionInfo.ExpirationSeconds,
-		RetryExpirationTimeNanos:        common.Int64Ptr(executionInfo.ExpirationTime.UnixNano()),
-		RetryNonRetryableErrors:         executionInfo.NonRetriableErrors,
-		EventStoreVersion:               &executionInfo.EventStoreVersion,
-		EventBranchToken:                executionInfo.BranchToken,
-		AutoResetPoints:                 executionInfo.AutoResetPoints.Data,
-		AutoResetPointsEncoding:         common.StringPtr(string(executionInfo.AutoResetPoints.GetEncoding())),
-		SearchAttributes:                executionInfo.SearchAttributes,
+		TaskList:                                &executionInfo.TaskList,
+		WorkflowTypeName:                        &executionInfo.WorkflowTypeName,
+		WorkflowTimeoutSeconds:                  &executionInfo.WorkflowTimeout,
+		DecisionTaskTimeoutSeconds:              &executionInfo.DecisionTimeoutValue,
+		ExecutionContext:                        executionInfo.ExecutionContext,
+		State:                                   common.Int32Ptr(int32(executionInfo.State)),
+		CloseStatus:                             common.Int32Ptr(int32(executionInfo.CloseStatus)),
+		CloseTimestampNanos:                     &executionInfo.CloseTimestamp,
+		LastFirstEventID:                        &executionInfo.LastFirstEventID,
+		LastProcessedEvent:                      &executionInfo.LastProcessedEvent,
+		StartTimeNanos:                          common.Int64Ptr(executionInfo.StartTimestamp.UnixNano()),
+		LastUpdatedTimeNanos:                    common.TimeNowNanosPtr(),
+		CreateRequestID:                         &executionInfo.CreateRequestID,
+		DecisionVersion:                         &executionInfo.DecisionVersion,
+		DecisionScheduleID:                      &executionInfo.DecisionScheduleID,
+		DecisionStartedID:                       &executionInfo.DecisionStartedID,
+		DecisionRequestID:                       &executionInfo.DecisionRequestID,
+		DecisionTimeout:                         &executionInfo.DecisionTimeout,
+		DecisionAttempt:                         &executionInfo.DecisionAttempt,
+		DecisionStartedTimestampNanos:           &executionInfo.DecisionStartedTimestamp,
+		DecisionScheduledTimestampNanos:         &executionInfo.DecisionScheduledTimestamp,
+		DecisionOriginalScheduledTimestampNanos: &executionInfo.DecisionOriginalScheduledTimestamp,
+		StickyTaskList:                          &executionInfo.StickyTaskList,
+		StickyScheduleToStartTimeout:            common.Int64Ptr(int64(executionInfo.StickyScheduleToStartTimeout)),
+		ClientLibraryVersion:                    &executionInfo.ClientLibraryVersion,
+		ClientFeatureVersion:                    &executionInfo.ClientFeatureVersion,
+		ClientImpl:                              &executionInfo.ClientImpl,
+		CurrentVersion:                          common.Int64Ptr(common.EmptyVersion),
+		SignalCount:                             common.Int64Ptr(int64(executionInfo.SignalCount)),
+		HistorySize:                             &executionInfo.HistorySize,
+		CronSchedule:                            &executionInfo.CronSchedule,
+		CompletionEventBatchID:                  &executionInfo.CompletionEventBatchID,
+		HasRetryPolicy:                          &executionInfo.HasRetryPolicy,
+		RetryAttempt:                            common.Int64Ptr(int64(executionInfo.Attempt)),
+		RetryInitialIntervalSeconds:             &executionInfo.InitialInterval,
+		RetryBackoffCoefficient:                 &executionInfo.BackoffCoefficient,
+		RetryMaximumIntervalSeconds:             &executionInfo.MaximumInterval,
+		RetryMaximumAttempts:                    &executionInfo.MaximumAttempts,
+		RetryExpirationSeconds:                  &executionInfo.ExpirationSeconds,
+		RetryExpirationTimeNanos:                common.Int64Ptr(executionInfo.ExpirationTime.UnixNano()),
+		RetryNonRetryableErrors:                 executionInfo.NonRetriableErrors,
+		EventStoreVersion:                       &executionInfo.EventStoreVersion,
+		EventBranchToken:                        executionInfo.BranchToken,
+		AutoResetPoints:                         executionInfo.AutoResetPoints.Data,
+		AutoResetPointsEncoding:                 common.StringPtr(string(executionInfo.AutoResetPoints.GetEncoding())),
+		SearchAttributes:                        executionInfo.SearchAttributes,
+		Memo:                                    executionInfo.Memo,
+		SignalRequestedTimestamps:               executionInfo.SignalRequestedTimestamps,
 	}
 
 	completionEvent := executionInfo.CompletionEvent
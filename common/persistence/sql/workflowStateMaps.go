@@ -68,6 +68,7 @@ func updateActivityInfos(tx sqldb.Tx,
 				RetryExpirationTimeNanos:      common.Int64Ptr(v.ExpirationTime.UnixNano()),
 				RetryMaximumAttempts:          &v.MaximumAttempts,
 				RetryNonRetryableErrors:       v.NonRetriableErrors,
+				LastFailureReason:             &v.LastFailureReason,
 			}
 			if v.StartedEvent != nil {
 				info.StartedEvent = v.StartedEvent.Data
@@ -185,6 +186,7 @@ func getActivityInfoMap(db sqldb.Interface,
 			ExpirationTime:           time.Unix(0, decoded.GetRetryExpirationTimeNanos()),
 			MaximumAttempts:          decoded.GetRetryMaximumAttempts(),
 			NonRetriableErrors:       decoded.GetRetryNonRetryableErrors(),
+			LastFailureReason:        decoded.GetLastFailureReason(),
 		}
 		if decoded.StartedEvent != nil {
 			info.StartedEvent = persistence.NewDataBlob(decoded.StartedEvent, common.EncodingType(decoded.GetStartedEventEncoding()))
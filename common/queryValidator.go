@@ -22,6 +22,8 @@ package common
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
 
 	workflow "github.com/uber/cadence/.gen/go/shared"
@@ -216,3 +218,26 @@ func (qv *VisibilityQueryValidator) IsValidSearchAttributes(key string) bool {
 	_, isValidKey := validAttr[key]
 	return isValidKey
 }
+
+// ValidateSearchAttributeKeys returns a BadRequestError naming every key of the given search attributes
+// that is not registered
+func (qv *VisibilityQueryValidator) ValidateSearchAttributeKeys(input *workflow.SearchAttributes) error {
+	if input == nil {
+		return nil
+	}
+
+	var unknownKeys []string
+	for key := range input.GetIndexedFields() {
+		if !qv.IsValidSearchAttributes(key) {
+			unknownKeys = append(unknownKeys, key)
+		}
+	}
+	if len(unknownKeys) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknownKeys)
+	return &workflow.BadRequestError{
+		Message: fmt.Sprintf("Unknown search attribute keys: %v.", strings.Join(unknownKeys, ", ")),
+	}
+}
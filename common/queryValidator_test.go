@@ -116,3 +116,25 @@ func (s *queryValidatorSuite) TestValidateListRequestForQuery() {
 	listRequest.Query = StringPtr(query)
 	s.Equal("BadRequestError{Message: invalid order by expression}", qv.ValidateListRequestForQuery(listRequest).Error())
 }
+
+func (s *queryValidatorSuite) TestValidateSearchAttributeKeys() {
+	validSearchAttr := dynamicconfig.GetMapPropertyFn(definition.GetDefaultIndexedKeys())
+	qv := NewQueryValidator(validSearchAttr)
+
+	s.Nil(qv.ValidateSearchAttributeKeys(nil))
+	s.Nil(qv.ValidateSearchAttributeKeys(&shared.SearchAttributes{}))
+
+	attr := &shared.SearchAttributes{
+		IndexedFields: map[string][]byte{
+			"CustomStringField": []byte(`"custom"`),
+			"CustomIntField":    []byte("1"),
+		},
+	}
+	s.Nil(qv.ValidateSearchAttributeKeys(attr))
+
+	attr.IndexedFields["UnknownField"] = []byte("1")
+	attr.IndexedFields["AnotherUnknownField"] = []byte("2")
+	err := qv.ValidateSearchAttributeKeys(attr)
+	s.IsType(&shared.BadRequestError{}, err)
+	s.Equal("Unknown search attribute keys: AnotherUnknownField, UnknownField.", err.(*shared.BadRequestError).Message)
+}
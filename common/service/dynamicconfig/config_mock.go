@@ -44,6 +44,11 @@ func GetFloatPropertyFn(value float64) func(opts ...FilterOption) float64 {
 	return func(...FilterOption) float64 { return value }
 }
 
+// GetFloatPropertyFilteredByDomain returns value as FloatPropertyFnWithDomainFilter
+func GetFloatPropertyFilteredByDomain(value float64) func(domain string) float64 {
+	return func(domain string) float64 { return value }
+}
+
 // GetBoolPropertyFn returns value as BoolPropertyFn
 func GetBoolPropertyFn(value bool) func(opts ...FilterOption) bool {
 	return func(...FilterOption) bool { return value }
@@ -59,6 +64,11 @@ func GetDurationPropertyFn(value time.Duration) func(opts ...FilterOption) time.
 	return func(...FilterOption) time.Duration { return value }
 }
 
+// GetDurationPropertyFnFilteredByDomain returns value as DurationPropertyFnWithDomainFilter
+func GetDurationPropertyFnFilteredByDomain(value time.Duration) func(domain string) time.Duration {
+	return func(domain string) time.Duration { return value }
+}
+
 // GetDurationPropertyFnFilteredByTaskListInfo returns value as DurationPropertyFnWithTaskListInfoFilters
 func GetDurationPropertyFnFilteredByTaskListInfo(value time.Duration) func(domain string, taskList string, taskType int) time.Duration {
 	return func(domain string, taskList string, taskType int) time.Duration { return value }
@@ -183,6 +183,15 @@ func (s *configSuite) TestGetFloat64Property() {
 	s.Equal(0.01, value())
 }
 
+func (s *configSuite) TestGetFloat64PropertyFilteredByDomain() {
+	key := testGetFloat64PropertyFilteredByDomainKey
+	domain := "testDomain"
+	value := s.cln.GetFloat64PropertyFilteredByDomain(key, 0.1)
+	s.Equal(0.1, value(domain))
+	s.client.SetValue(key, 0.01)
+	s.Equal(0.01, value(domain))
+}
+
 func (s *configSuite) TestGetBoolProperty() {
 	key := testGetBoolPropertyKey
 	value := s.cln.GetBoolProperty(key, true)
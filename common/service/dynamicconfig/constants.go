@@ -45,6 +45,7 @@ var keys = map[Key]string{
 	testGetMapPropertyKey:                            "testGetMapPropertyKey",
 	testGetIntPropertyFilteredByDomainKey:            "testGetIntPropertyFilteredByDomainKey",
 	testGetDurationPropertyFilteredByDomainKey:       "testGetDurationPropertyFilteredByDomainKey",
+	testGetFloat64PropertyFilteredByDomainKey:        "testGetFloat64PropertyFilteredByDomainKey",
 	testGetIntPropertyFilteredByTaskListInfoKey:      "testGetIntPropertyFilteredByTaskListInfoKey",
 	testGetDurationPropertyFilteredByTaskListInfoKey: "testGetDurationPropertyFilteredByTaskListInfoKey",
 	testGetBoolPropertyFilteredByTaskListInfoKey:     "testGetBoolPropertyFilteredByTaskListInfoKey",
@@ -71,6 +72,10 @@ var keys = map[Key]string{
 	HistoryCountLimitWarn:  "limit.historyCount.warn",
 	MaxIDLengthLimit:       "limit.maxIDLength",
 
+	MaxNonRetriableErrorReasonsCount:  "limit.maxNonRetriableErrorReasonsCount",
+	MaxNonRetriableErrorReasonsLength: "limit.maxNonRetriableErrorReasonsLength",
+	ActivityTypeBlobSizeLimitError:    "limit.activityTypeBlobSize.error",
+
 	// frontend settings
 	FrontendPersistenceMaxQPS:         "frontend.persistenceMaxQPS",
 	FrontendVisibilityMaxPageSize:     "frontend.visibilityMaxPageSize",
@@ -89,6 +94,7 @@ var keys = map[Key]string{
 	SearchAttributesNumberOfKeysLimit: "frontend.searchAttributesNumberOfKeysLimit",
 	SearchAttributesSizeOfValueLimit:  "frontend.searchAttributesSizeOfValueLimit",
 	SearchAttributesTotalSizeLimit:    "frontend.searchAttributesTotalSizeLimit",
+	MaxParentChainDepth:               "frontend.maxParentChainDepth",
 
 	// matching settings
 	MatchingRPS:                             "matching.rps",
@@ -113,6 +119,27 @@ var keys = map[Key]string{
 	HistoryLongPollExpirationInterval:                     "history.longPollExpirationInterval",
 	HistoryCacheInitialSize:                               "history.cacheInitialSize",
 	HistoryMaxAutoResetPoints:                             "history.historyMaxAutoResetPoints",
+	EnableAutoResetPoints:                                 "history.enableAutoResetPoints",
+	EnableWorkflowTimeoutRepair:                           "history.enableWorkflowTimeoutRepair",
+	ResetWorkflowMaxReplayDuration:                        "history.resetWorkflowMaxReplayDuration",
+	EnableShardBackpressure:                               "history.enableShardBackpressure",
+	ShardBackpressureMaxTransferLag:                       "history.shardBackpressureMaxTransferLag",
+	ShardBackpressureMaxTimerLag:                          "history.shardBackpressureMaxTimerLag",
+	ActivityRetryKeepHeartbeatDetails:                     "history.activityRetryKeepHeartbeatDetails",
+	HistoryPageSize:                                       "history.historyPageSize",
+	CronMinBackoffInterval:                                "history.cronMinBackoffInterval",
+	CronMisfireFireImmediately:                            "history.cronMisfireFireImmediately",
+	DecisionTypeMetricsSampleRate:                         "history.decisionTypeMetricsSampleRate",
+	GlobalNonRetryableActivityErrors:                      "history.globalNonRetryableActivityErrors",
+	MutableStateExportSizeLimit:                           "history.mutableStateExportSizeLimit",
+	DescribeWorkflowLoadCompletionEvent:                   "history.describeWorkflowLoadCompletionEvent",
+	MaxWorkflowExecutionTimeout:                           "history.maxWorkflowExecutionTimeout",
+	ContinueAsNewInheritSearchAttributesAndMemo:           "history.continueAsNewInheritSearchAttributesAndMemo",
+	EnableCallerProvidedRunID:                             "history.enableCallerProvidedRunID",
+	MaxSignalRequestedIDs:                                 "history.maxSignalRequestedIDs",
+	MaxClusterClockSkew:                                   "history.maxClusterClockSkew",
+	DomainDraining:                                        "history.domainDraining",
+	ReturnNewDecisionTaskAlwaysSticky:                     "history.returnNewDecisionTaskAlwaysSticky",
 	HistoryCacheMaxSize:                                   "history.cacheMaxSize",
 	HistoryCacheTTL:                                       "history.cacheTTL",
 	EventsCacheInitialSize:                                "history.eventsCacheInitialSize",
@@ -150,6 +177,8 @@ var keys = map[Key]string{
 	TransferProcessorUpdateAckInterval:                    "history.transferProcessorUpdateAckInterval",
 	TransferProcessorUpdateAckIntervalJitterCoefficient:   "history.transferProcessorUpdateAckIntervalJitterCoefficient",
 	TransferProcessorCompleteTransferInterval:             "history.transferProcessorCompleteTransferInterval",
+	TransferProcessorMatchingCircuitBreakerThreshold:      "history.transferProcessorMatchingCircuitBreakerThreshold",
+	TransferProcessorMatchingCircuitBreakerOpenDuration:   "history.transferProcessorMatchingCircuitBreakerOpenDuration",
 	ReplicatorTaskBatchSize:                               "history.replicatorTaskBatchSize",
 	ReplicatorTaskWorkerCount:                             "history.replicatorTaskWorkerCount",
 	ReplicatorTaskMaxRetryCount:                           "history.replicatorTaskMaxRetryCount",
@@ -164,6 +193,12 @@ var keys = map[Key]string{
 	HistoryMgrNumConns:                                    "history.historyMgrNumConns",
 	MaximumBufferedEventsBatch:                            "history.maximumBufferedEventsBatch",
 	MaximumSignalsPerExecution:                            "history.maximumSignalsPerExecution",
+	MaximumSignalsBeforeFirstDecision:                     "history.maximumSignalsBeforeFirstDecision",
+	MaximumChildWorkflowsPerExecution:                     "history.maximumChildWorkflowsPerExecution",
+	MaximumDecisionTaskAttempts:                           "history.maximumDecisionTaskAttempts",
+	MaximumHistoryLength:                                  "history.maximumHistoryLength",
+	EmptyDecisionLoopLimit:                                "history.emptyDecisionLoopLimit",
+	EmptyDecisionLoopMinInterval:                          "history.emptyDecisionLoopMinInterval",
 	ShardUpdateMinInterval:                                "history.shardUpdateMinInterval",
 	ShardSyncMinInterval:                                  "history.shardSyncMinInterval",
 	DefaultEventEncoding:                                  "history.defaultEventEncoding",
@@ -172,6 +207,9 @@ var keys = map[Key]string{
 	EnableEventsV2:                                        "history.enableEventsV2",
 	NumArchiveSystemWorkflows:                             "history.numArchiveSystemWorkflows",
 	ArchiveRequestRPS:                                     "history.archiveRequestRPS",
+	ArchivalOnRequestOnly:                                 "history.archivalOnRequestOnly",
+	ArchiveMutableStateSnapshot:                           "history.archiveMutableStateSnapshot",
+	ArchiveMutableStateSnapshotSizeLimit:                  "history.archiveMutableStateSnapshotSizeLimit",
 	EmitShardDiffLog:                                      "history.emitShardDiffLog",
 	HistoryThrottledLogRPS:                                "history.throttledLogRPS",
 
@@ -213,6 +251,7 @@ const (
 	testGetMapPropertyKey
 	testGetIntPropertyFilteredByDomainKey
 	testGetDurationPropertyFilteredByDomainKey
+	testGetFloat64PropertyFilteredByDomainKey
 	testGetIntPropertyFilteredByTaskListInfoKey
 	testGetDurationPropertyFilteredByTaskListInfoKey
 	testGetBoolPropertyFilteredByTaskListInfoKey
@@ -259,6 +298,13 @@ const (
 	// MaxIDLengthLimit is the length limit for various IDs, including: Domain, TaskList, WorkflowID, ActivityID, TimerID,
 	// WorkflowType, ActivityType, SignalName, MarkerName, ErrorReason/FailureReason/CancelCause, Identity, RequestID
 	MaxIDLengthLimit
+	// MaxNonRetriableErrorReasonsCount is the max number of NonRetriableErrorReasons allowed on a retry policy
+	MaxNonRetriableErrorReasonsCount
+	// MaxNonRetriableErrorReasonsLength is the max total length of NonRetriableErrorReasons allowed on a retry policy
+	MaxNonRetriableErrorReasonsLength
+	// ActivityTypeBlobSizeLimitError is the map of activity type to the size limit of the input of its scheduled
+	// activities, overriding BlobSizeLimitError for the activity types it contains
+	ActivityTypeBlobSizeLimitError
 
 	// key for frontend
 
@@ -294,6 +340,9 @@ const (
 	SearchAttributesSizeOfValueLimit
 	// SearchAttributesTotalSizeLimit is the size limit of the whole map
 	SearchAttributesTotalSizeLimit
+	// MaxParentChainDepth is the max number of ancestors DescribeWorkflowExecution returns when asked for the
+	// parent chain, every ancestor costs a mutable state load
+	MaxParentChainDepth
 
 	// key for matching
 
@@ -412,6 +461,12 @@ const (
 	TransferProcessorUpdateAckIntervalJitterCoefficient
 	// TransferProcessorCompleteTransferInterval is complete timer interval for transferQueueProcessor
 	TransferProcessorCompleteTransferInterval
+	// TransferProcessorMatchingCircuitBreakerThreshold is the number of consecutive matching failures after which
+	// transferQueueProcessor stops dispatching decision and activity tasks to matching, 0 disables the circuit breaker
+	TransferProcessorMatchingCircuitBreakerThreshold
+	// TransferProcessorMatchingCircuitBreakerOpenDuration is how long the matching circuit breaker stays open
+	// before a single probe call is let through
+	TransferProcessorMatchingCircuitBreakerOpenDuration
 	// ReplicatorTaskBatchSize is batch size for ReplicatorProcessor
 	ReplicatorTaskBatchSize
 	// ReplicatorTaskWorkerCount is number of worker for ReplicatorProcessor
@@ -440,6 +495,21 @@ const (
 	MaximumBufferedEventsBatch
 	// MaximumSignalsPerExecution is max number of signals supported by single execution
 	MaximumSignalsPerExecution
+	// MaximumSignalsBeforeFirstDecision is max number of signals a cron workflow accepts before its first decision is scheduled
+	MaximumSignalsBeforeFirstDecision
+	// MaximumChildWorkflowsPerExecution is max number of pending child workflows supported by single execution
+	MaximumChildWorkflowsPerExecution
+	// MaximumDecisionTaskAttempts is max number of decision task attempts before the workflow is terminated
+	MaximumDecisionTaskAttempts
+	// MaximumHistoryLength is max number of history events before the workflow is terminated on decision completion
+	MaximumHistoryLength
+	// EmptyDecisionLoopLimit is max number of consecutive decisions which only force a new decision before the workflow is failed.
+	// SDKs heartbeat decisions running long local activities the same way, see EmptyDecisionLoopMinInterval. The count is kept
+	// in the workflow cache only and starts over when the workflow is evicted or its shard moves.
+	EmptyDecisionLoopLimit
+	// EmptyDecisionLoopMinInterval is the time from the start of a decision within which it has to be completed to count toward
+	// EmptyDecisionLoopLimit. It only applies to the loop guard, decision heartbeats are reported whatever their duration
+	EmptyDecisionLoopMinInterval
 	// ShardUpdateMinInterval is the minimal time interval which the shard info can be updated
 	ShardUpdateMinInterval
 	// ShardSyncMinInterval is the minimal time interval which the shard info should be sync to remote
@@ -450,6 +520,15 @@ const (
 	NumArchiveSystemWorkflows
 	// ArchiveRequestRPS is the rate limit on the number of archive request per second
 	ArchiveRequestRPS
+	// ArchivalOnRequestOnly is whether a domain only archives workflows which requested archival through their memo,
+	// history of other closed workflows is deleted without being archived
+	ArchivalOnRequestOnly
+	// ArchiveMutableStateSnapshot is whether the mutable state of a closed workflow is archived alongside its history
+	ArchiveMutableStateSnapshot
+	// ArchiveMutableStateSnapshotSizeLimit is the max size in bytes of the serialized mutable state sent with an archive
+	// request, larger snapshots are not archived. The request is signalled to the archiver workflow so this must stay
+	// well below the blob size limit
+	ArchiveMutableStateSnapshotSizeLimit
 
 	// EnableAdminProtection is whether to enable admin checking
 	EnableAdminProtection
@@ -457,6 +536,72 @@ const (
 	AdminOperationToken
 	// HistoryMaxAutoResetPoints is the key for max number of auto reset points stored in mutableState
 	HistoryMaxAutoResetPoints
+	// EnableAutoResetPoints is whether to record auto reset points in mutableState on decision completion.
+	// Disabling it for a domain means its workflows can no longer be auto reset by bad binary checksum
+	EnableAutoResetPoints
+	// EnableWorkflowTimeoutRepair is whether to check the timer queue for a missing workflow timeout timer when a
+	// running workflow is loaded into the history cache and schedule it again with its next update, this costs one
+	// extra timer queue read per cache miss
+	EnableWorkflowTimeoutRepair
+	// ResetWorkflowMaxReplayDuration is the max time a reset can spend replaying the base run's history, 0 means
+	// the replay is only bounded by the request context
+	ResetWorkflowMaxReplayDuration
+	// EnableShardBackpressure is whether to reject starting new workflows with ServiceBusyError while the shard is
+	// behind on processing its transfer or timer tasks
+	EnableShardBackpressure
+	// ShardBackpressureMaxTransferLag is the number of transfer tasks read by the shard's transfer processor but not
+	// yet completed before new workflow starts are rejected, it should be set below the transfer batch size plus
+	// the transfer worker count as the processor does not read more tasks than that ahead
+	ShardBackpressureMaxTransferLag
+	// ShardBackpressureMaxTimerLag is how far the oldest fired but not yet completed timer task of the shard can be
+	// behind the current time before new workflow starts are rejected
+	ShardBackpressureMaxTimerLag
+	// ActivityRetryKeepHeartbeatDetails is whether a retried activity attempt is started with the heartbeat details
+	// recorded by the previous attempt, so that a checkpointing activity can resume instead of starting over
+	ActivityRetryKeepHeartbeatDetails
+	// HistoryPageSize is the page size used when the history service reads a workflow's own history internally,
+	// e.g. when replaying a run for reset
+	HistoryPageSize
+	// CronMinBackoffInterval is the minimum time between two runs of a cron workflow, cron schedules with a shorter
+	// interval are rejected and a shorter backoff computed on continue-as-new is raised to it
+	CronMinBackoffInterval
+	// CronMisfireFireImmediately is the policy applied when a cron run overran the slot following its own scheduled
+	// time. If true the missed slot is fired right away, otherwise (the default) it is skipped and the next run is
+	// scheduled at the first slot after now, so a slow cron workflow never runs back to back to catch up
+	CronMisfireFireImmediately
+	// DecisionTypeMetricsSampleRate is the fraction of RespondDecisionTaskCompleted calls that emit the per decision
+	// type counters, sampled counters are scaled up so totals stay unbiased but get noisier as the rate goes down
+	DecisionTypeMetricsSampleRate
+	// GlobalNonRetryableActivityErrors is a comma separated list of activity failure reasons that are never retried
+	// in a domain, regardless of the retry policy of the activity
+	GlobalNonRetryableActivityErrors
+	// MutableStateExportSizeLimit is the max size in bytes of the serialized, and possibly compressed, mutable state
+	// returned by ExportMutableState
+	MutableStateExportSizeLimit
+	// DescribeWorkflowLoadCompletionEvent is whether DescribeWorkflowExecution always reads the close time of a closed
+	// workflow from its completion event instead of the close timestamp cached in mutable state
+	DescribeWorkflowLoadCompletionEvent
+	// MaxWorkflowExecutionTimeout is the upper bound of the execution timeout an operator can extend a running
+	// workflow to through ExtendWorkflowTimeout
+	MaxWorkflowExecutionTimeout
+	// ContinueAsNewInheritSearchAttributesAndMemo is whether the next run of a workflow that continued as new starts
+	// with the search attributes and memo of the previous run when the continue as new does not specify new ones
+	ContinueAsNewInheritSearchAttributesAndMemo
+	// EnableCallerProvidedRunID is whether a start request sent to the history service may carry the run ID of the
+	// new run, this is only meant for deterministic integration tests and replays
+	EnableCallerProvidedRunID
+	// MaxSignalRequestedIDs is the max number of signal request IDs a workflow keeps for signal deduplication, the
+	// oldest request IDs are evicted first so only a very old duplicate signal could be applied twice
+	MaxSignalRequestedIDs
+	// MaxClusterClockSkew is the max difference between the time of a remote cluster received in a shard status sync
+	// and the local time, a remote time further ahead than that is clamped to the local time
+	MaxClusterClockSkew
+	// DomainDraining is whether a domain being decommissioned rejects starting new workflows, while its existing
+	// workflows keep running to completion
+	DomainDraining
+	// ReturnNewDecisionTaskAlwaysSticky is whether the decision task returned by RespondDecisionTaskCompleted is always
+	// marked as sticky execution enabled, otherwise it is only if the workflow has a sticky task list
+	ReturnNewDecisionTaskAlwaysSticky
 
 	// EnableEventsV2 is whether to use eventsV2
 	EnableEventsV2
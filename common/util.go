@@ -23,6 +23,7 @@ package common
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/rand"
 	"strings"
 	"sync"
@@ -67,8 +68,14 @@ const (
 	retryKafkaOperationMaxInterval        = 10 * time.Second
 	retryKafkaOperationExpirationInterval = 30 * time.Second
 
+	retryBlobstoreOperationInitialInterval    = 100 * time.Millisecond
+	retryBlobstoreOperationMaxInterval        = 2 * time.Second
+	retryBlobstoreOperationExpirationInterval = 10 * time.Second
+
 	// FailureReasonCompleteResultExceedsLimit is failureReason for complete result exceeds limit
 	FailureReasonCompleteResultExceedsLimit = "COMPLETE_RESULT_EXCEEDS_LIMIT"
+	// FailureReasonActivityResultSizeExceedsLimit is failureReason for activity result exceeds blob size limit
+	FailureReasonActivityResultSizeExceedsLimit = "ACTIVITY_RESULT_SIZE_EXCEEDS_LIMIT"
 	// FailureReasonFailureDetailsExceedsLimit is failureReason for failure details exceeds limit
 	FailureReasonFailureDetailsExceedsLimit = "FAILURE_DETAILS_EXCEEDS_LIMIT"
 	// FailureReasonCancelDetailsExceedsLimit is failureReason for cancel details exceeds limit
@@ -77,10 +84,19 @@ const (
 	FailureReasonHeartbeatExceedsLimit = "HEARTBEAT_EXCEEDS_LIMIT"
 	// FailureReasonDecisionBlobSizeExceedsLimit is the failureReason for decision blob exceeds size limit
 	FailureReasonDecisionBlobSizeExceedsLimit = "DECISION_BLOB_SIZE_EXCEEDS_LIMIT"
+	// FailureReasonDecisionAttemptsExceedsLimit is reason to terminate workflow when decision task attempts exceed limit
+	FailureReasonDecisionAttemptsExceedsLimit = "DECISION_ATTEMPTS_EXCEEDS_LIMIT"
+	// FailureReasonHistoryLengthExceedsLimit is reason to terminate workflow when history length exceeds limit
+	FailureReasonHistoryLengthExceedsLimit = "HISTORY_LENGTH_EXCEEDS_LIMIT"
+	// FailureReasonEmptyDecisionLoop is the failureReason when consecutive decisions only force new decisions
+	FailureReasonEmptyDecisionLoop = "EMPTY_DECISION_LOOP"
 	// TerminateReasonSizeExceedsLimit is reason to terminate workflow when history size or count exceed limit
 	TerminateReasonSizeExceedsLimit = "HISTORY_EXCEEDS_LIMIT"
 	// FailureReasonTransactionSizeExceedsLimit is the failureReason for when transaction cannot be committed because it exceeds size limit
 	FailureReasonTransactionSizeExceedsLimit = "TRANSACTION_SIZE_EXCEEDS_LIMIT"
+	// FailureReasonChildFirstDecisionUnschedulable is the failureReason reported to the parent when the first decision
+	// of a child workflow can never be scheduled
+	FailureReasonChildFirstDecisionUnschedulable = "CHILD_FIRST_DECISION_UNSCHEDULABLE"
 )
 
 var (
@@ -172,6 +188,15 @@ func CreateKafkaOperationRetryPolicy() backoff.RetryPolicy {
 	return policy
 }
 
+// CreateBlobstoreOperationRetryPolicy creates a retry policy for blobstore reads
+func CreateBlobstoreOperationRetryPolicy() backoff.RetryPolicy {
+	policy := backoff.NewExponentialRetryPolicy(retryBlobstoreOperationInitialInterval)
+	policy.SetMaximumInterval(retryBlobstoreOperationMaxInterval)
+	policy.SetExpirationInterval(retryBlobstoreOperationExpirationInterval)
+
+	return policy
+}
+
 // IsPersistenceTransientError checks if the error is a transient persistence error
 func IsPersistenceTransientError(err error) bool {
 	switch err.(type) {
@@ -294,19 +319,21 @@ func GenerateRandomString(n int) string {
 // CreateMatchingPollForDecisionTaskResponse create response for matching's PollForDecisionTask
 func CreateMatchingPollForDecisionTaskResponse(historyResponse *h.RecordDecisionTaskStartedResponse, workflowExecution *workflow.WorkflowExecution, token []byte) *m.PollForDecisionTaskResponse {
 	matchingResp := &m.PollForDecisionTaskResponse{
-		WorkflowExecution:         workflowExecution,
-		TaskToken:                 token,
-		Attempt:                   Int64Ptr(historyResponse.GetAttempt()),
-		WorkflowType:              historyResponse.WorkflowType,
-		StartedEventId:            historyResponse.StartedEventId,
-		StickyExecutionEnabled:    historyResponse.StickyExecutionEnabled,
-		NextEventId:               historyResponse.NextEventId,
-		DecisionInfo:              historyResponse.DecisionInfo,
-		WorkflowExecutionTaskList: historyResponse.WorkflowExecutionTaskList,
-		EventStoreVersion:         historyResponse.EventStoreVersion,
-		BranchToken:               historyResponse.BranchToken,
-		ScheduledTimestamp:        historyResponse.ScheduledTimestamp,
-		StartedTimestamp:          historyResponse.StartedTimestamp,
+		WorkflowExecution:                   workflowExecution,
+		TaskToken:                           token,
+		Attempt:                             Int64Ptr(historyResponse.GetAttempt()),
+		WorkflowType:                        historyResponse.WorkflowType,
+		StartedEventId:                      historyResponse.StartedEventId,
+		StickyExecutionEnabled:              historyResponse.StickyExecutionEnabled,
+		NextEventId:                         historyResponse.NextEventId,
+		DecisionInfo:                        historyResponse.DecisionInfo,
+		WorkflowExecutionTaskList:           historyResponse.WorkflowExecutionTaskList,
+		EventStoreVersion:                   historyResponse.EventStoreVersion,
+		BranchToken:                         historyResponse.BranchToken,
+		ScheduledTimestamp:                  historyResponse.ScheduledTimestamp,
+		StartedTimestamp:                    historyResponse.StartedTimestamp,
+		StickyScheduleToStartTimeoutSeconds: historyResponse.StickyScheduleToStartTimeoutSeconds,
+		ScheduledTimestampOfThisAttempt:     historyResponse.ScheduledTimestampOfThisAttempt,
 	}
 	if historyResponse.GetPreviousStartedEventId() != EmptyEventID {
 		matchingResp.PreviousStartedEventId = historyResponse.PreviousStartedEventId
@@ -322,6 +349,14 @@ func MinInt64(a, b int64) int64 {
 	return b
 }
 
+// MaxInt64 returns the greater of two given int64
+func MaxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 // MinInt32 return smaller one of two inputs int32
 func MinInt32(a, b int32) int32 {
 	if a < b {
@@ -347,9 +382,13 @@ func ValidateRetryPolicy(policy *workflow.RetryPolicy) error {
 	if policy.GetInitialIntervalInSeconds() <= 0 {
 		return &workflow.BadRequestError{Message: "InitialIntervalInSeconds must be greater than 0 on retry policy."}
 	}
-	if policy.GetBackoffCoefficient() < 1 {
+	// negated so a NaN coefficient is rejected as well
+	if !(policy.GetBackoffCoefficient() >= 1) {
 		return &workflow.BadRequestError{Message: "BackoffCoefficient cannot be less than 1 on retry policy."}
 	}
+	if math.IsInf(policy.GetBackoffCoefficient(), 1) {
+		return &workflow.BadRequestError{Message: "BackoffCoefficient must be finite on retry policy."}
+	}
 	if policy.GetMaximumIntervalInSeconds() < 0 {
 		return &workflow.BadRequestError{Message: "MaximumIntervalInSeconds cannot be less than 0 on retry policy."}
 	}
@@ -368,11 +407,33 @@ func ValidateRetryPolicy(policy *workflow.RetryPolicy) error {
 	return nil
 }
 
+// ValidateRetryPolicyNonRetriableErrorReasons validates the number and the total length of the
+// non retriable error reasons on a retry policy
+func ValidateRetryPolicyNonRetriableErrorReasons(policy *workflow.RetryPolicy, maxCount int, maxTotalLength int) error {
+	if policy == nil {
+		return nil
+	}
+	if len(policy.NonRetriableErrorReasons) > maxCount {
+		return &workflow.BadRequestError{Message: fmt.Sprintf(
+			"NonRetriableErrorReasons on retry policy exceeds count limit of %v.", maxCount)}
+	}
+	totalLength := 0
+	for _, reason := range policy.NonRetriableErrorReasons {
+		totalLength += len(reason)
+	}
+	if totalLength > maxTotalLength {
+		return &workflow.BadRequestError{Message: fmt.Sprintf(
+			"NonRetriableErrorReasons on retry policy exceeds total length limit of %v.", maxTotalLength)}
+	}
+	return nil
+}
+
 // CreateHistoryStartWorkflowRequest create a start workflow request for history
 func CreateHistoryStartWorkflowRequest(domainID string, startRequest *workflow.StartWorkflowExecutionRequest) *h.StartWorkflowExecutionRequest {
 	histRequest := &h.StartWorkflowExecutionRequest{
-		DomainUUID:   StringPtr(domainID),
-		StartRequest: startRequest,
+		DomainUUID:                    StringPtr(domainID),
+		StartRequest:                  startRequest,
+		WaitForFirstDecisionScheduled: startRequest.WaitForFirstDecisionScheduled,
 	}
 	if startRequest.RetryPolicy != nil && startRequest.RetryPolicy.GetExpirationIntervalInSeconds() > 0 {
 		expirationInSeconds := startRequest.RetryPolicy.GetExpirationIntervalInSeconds()
@@ -0,0 +1,47 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package common
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	workflow "github.com/uber/cadence/.gen/go/shared"
+)
+
+func TestValidateRetryPolicy_BackoffCoefficient(t *testing.T) {
+	a := assert.New(t)
+	newPolicy := func(backoffCoefficient float64) *workflow.RetryPolicy {
+		return &workflow.RetryPolicy{
+			InitialIntervalInSeconds: Int32Ptr(1),
+			BackoffCoefficient:       Float64Ptr(backoffCoefficient),
+			MaximumAttempts:          Int32Ptr(3),
+		}
+	}
+
+	for _, backoffCoefficient := range []float64{0, 0.5, -1, math.NaN(), math.Inf(1)} {
+		err := ValidateRetryPolicy(newPolicy(backoffCoefficient))
+		a.IsType(&workflow.BadRequestError{}, err, "coefficient %v", backoffCoefficient)
+	}
+	a.Nil(ValidateRetryPolicy(newPolicy(1.0)))
+	a.Nil(ValidateRetryPolicy(newPolicy(2.0)))
+}
@@ -862,12 +862,11 @@ func (s *integrationSuite) TestCronWorkflow() {
 		Identity:                            common.StringPtr(identity),
 		CronSchedule:                        common.StringPtr("@every 3s"), //minimum interval by standard spec is 1m (* * * * *), use non-standard descriptor for short interval for test
 		RetryPolicy: &workflow.RetryPolicy{
-			InitialIntervalInSeconds:    common.Int32Ptr(1),
-			MaximumAttempts:             common.Int32Ptr(5),
-			MaximumIntervalInSeconds:    common.Int32Ptr(1),
-			NonRetriableErrorReasons:    []string{"cron-test-error"},
-			BackoffCoefficient:          common.Float64Ptr(1),
-			ExpirationIntervalInSeconds: common.Int32Ptr(100),
+			InitialIntervalInSeconds: common.Int32Ptr(1),
+			MaximumAttempts:          common.Int32Ptr(5),
+			MaximumIntervalInSeconds: common.Int32Ptr(1),
+			NonRetriableErrorReasons: []string{"cron-test-error"},
+			BackoffCoefficient:       common.Float64Ptr(1),
 		},
 	}
 
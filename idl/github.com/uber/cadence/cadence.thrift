@@ -490,6 +490,18 @@ service WorkflowService {
       5: shared.ClientVersionNotSupportedError clientVersionNotSupportedError,
     )
 
+  /**
+  * GetDomainWorkflowStateCounts is a visibility API to count the workflow executions of a domain in each state.
+  **/
+  shared.GetDomainWorkflowStateCountsResponse GetDomainWorkflowStateCounts(1: shared.GetDomainWorkflowStateCountsRequest countRequest)
+    throws (
+      1: shared.BadRequestError badRequestError,
+      2: shared.InternalServiceError internalServiceError,
+      3: shared.EntityNotExistsError entityNotExistError,
+      4: shared.ServiceBusyError serviceBusyError,
+      5: shared.ClientVersionNotSupportedError clientVersionNotSupportedError,
+    )
+
   /**
   * GetSearchAttributes is a visibility API to get all legal keys that could be used in list APIs
   **/
@@ -49,6 +49,11 @@ struct StartWorkflowExecutionRequest {
   57: optional binary continuedFailureDetails
   58: optional binary lastCompletionResult
   60: optional i32 firstDecisionTaskBackoffSeconds
+  70: optional string runId
+  // waitForFirstDecisionScheduled makes the start return the next event ID of the workflow, confirming its first
+  // decision is scheduled, also when the start is deduplicated by request ID at the cost of an extra mutable state
+  // load. It is rejected for starts whose first decision is delayed, i.e. cron and child workflows
+  80: optional bool waitForFirstDecisionScheduled
 }
 
 struct DescribeMutableStateRequest{
@@ -65,6 +70,7 @@ struct GetMutableStateRequest {
   10: optional string domainUUID
   20: optional shared.WorkflowExecution execution
   30: optional i64 (js.type = "Long") expectedNextEventId
+  40: optional bool includeBranchAncestry
 }
 
 struct GetMutableStateResponse {
@@ -83,6 +89,8 @@ struct GetMutableStateResponse {
   120: optional i32 eventStoreVersion
   130: optional binary branchToken
   140: optional map<string, shared.ReplicationInfo> replicationInfo
+  // only populated for event store V2 when includeBranchAncestry is set on the request
+  150: optional shared.HistoryBranch branchAncestry
 }
 
 struct ResetStickyTaskListRequest {
@@ -102,6 +110,7 @@ struct RespondDecisionTaskCompletedRequest {
 
 struct RespondDecisionTaskCompletedResponse {
   10: optional RecordDecisionTaskStartedResponse startedResponse
+  20: optional i64 eventsWritten
 }
 
 struct RespondDecisionTaskFailedRequest {
@@ -171,6 +180,8 @@ struct RecordDecisionTaskStartedResponse {
   110: optional binary branchToken
   120:  optional i64 (js.type = "Long") scheduledTimestamp
   130:  optional i64 (js.type = "Long") startedTimestamp
+  140:  optional i64 (js.type = "Long") scheduledTimestampOfThisAttempt
+  150: optional i32 stickyScheduleToStartTimeoutSeconds
 }
 
 struct SignalWorkflowExecutionRequest {
@@ -45,6 +45,8 @@ struct PollForDecisionTaskResponse {
   120: optional binary branchToken
   130:  optional i64 (js.type = "Long") scheduledTimestamp
   140:  optional i64 (js.type = "Long") startedTimestamp
+  150: optional i32 stickyScheduleToStartTimeoutSeconds
+  160: optional i64 (js.type = "Long") scheduledTimestampOfThisAttempt
 }
 
 struct PollForActivityTaskRequest {
@@ -131,6 +131,7 @@ enum DecisionType {
   ContinueAsNewWorkflowExecution,
   StartChildWorkflowExecution,
   SignalExternalWorkflowExecution,
+  UpsertWorkflowMemo,
 }
 
 enum EventType {
@@ -175,6 +176,10 @@ enum EventType {
   SignalExternalWorkflowExecutionInitiated,
   SignalExternalWorkflowExecutionFailed,
   ExternalWorkflowExecutionSignaled,
+  UpsertWorkflowMemo,
+  // only written by the admin ExtendWorkflowTimeout operation, workers must run a client built against this IDL
+  // or later to replay a history carrying it, older clients fail the decision task on the unknown event type
+  WorkflowExecutionTimeoutExtended,
 }
 
 enum DecisionTaskFailedCause {
@@ -200,6 +205,9 @@ enum DecisionTaskFailedCause {
   RESET_WORKFLOW,
   BAD_BINARY,
   SCHEDULE_ACTIVITY_DUPLICATE_ID,
+  PENDING_CHILD_WORKFLOWS_LIMIT_EXCEEDED,
+  BAD_EXECUTION_CONTEXT_SIZE,
+  BAD_UPSERT_WORKFLOW_MEMO_ATTRIBUTES,
 }
 
 enum CancelExternalWorkflowExecutionFailedCause {
@@ -351,6 +359,12 @@ struct ScheduleActivityTaskDecisionAttributes {
   60: optional i32 heartbeatTimeoutSeconds
   70: optional RetryPolicy retryPolicy
   80: optional Header header
+  // dispatch only activities are tracked until they are started, only scheduleToStartTimeoutSeconds is honored
+  // and no completion timeout is ever fired, the activity is expected to report back to the workflow on its own.
+  // startToCloseTimeoutSeconds is only the deadline given to the worker, it defaults to the workflow timeout
+  90: optional bool dispatchOnly
+  // the activity is not dispatched to matching until the delay elapses, timeouts still count from scheduling
+  100: optional i32 scheduleDelaySeconds
 }
 
 struct RequestCancelActivityTaskDecisionAttributes {
@@ -416,6 +430,8 @@ struct ContinueAsNewWorkflowExecutionDecisionAttributes {
   110: optional binary lastCompletionResult
   120: optional string cronSchedule
   130: optional Header header
+  140: optional Memo memo
+  150: optional SearchAttributes searchAttributes
 }
 
 struct StartChildWorkflowExecutionDecisionAttributes {
@@ -434,6 +450,10 @@ struct StartChildWorkflowExecutionDecisionAttributes {
   130: optional Header header
 }
 
+struct UpsertWorkflowMemoDecisionAttributes {
+  10: optional Memo memo
+}
+
 struct Decision {
   10:  optional DecisionType decisionType
   20:  optional ScheduleActivityTaskDecisionAttributes scheduleActivityTaskDecisionAttributes
@@ -448,6 +468,7 @@ struct Decision {
   90:  optional ContinueAsNewWorkflowExecutionDecisionAttributes continueAsNewWorkflowExecutionDecisionAttributes
   100: optional StartChildWorkflowExecutionDecisionAttributes startChildWorkflowExecutionDecisionAttributes
   110: optional SignalExternalWorkflowExecutionDecisionAttributes signalExternalWorkflowExecutionDecisionAttributes
+  120: optional UpsertWorkflowMemoDecisionAttributes upsertWorkflowMemoDecisionAttributes
 }
 
 struct WorkflowExecutionStartedEventAttributes {
@@ -581,6 +602,8 @@ struct ActivityTaskScheduledEventAttributes {
   90: optional i64 (js.type = "Long") decisionTaskCompletedEventId
   110: optional RetryPolicy retryPolicy
   120: optional Header header
+  130: optional bool dispatchOnly
+  140: optional i32 scheduleDelaySeconds
 }
 
 struct ActivityTaskStartedEventAttributes {
@@ -679,6 +702,10 @@ struct WorkflowExecutionSignaledEventAttributes {
   10: optional string signalName
   20: optional binary input
   30: optional string identity
+  40: optional Header header
+  // expirationTimestamp is set for signals sent with a ttlSeconds, deciders should not apply the signal when the
+  // decision task processing it started after this time
+  50: optional i64 (js.type = "Long") expirationTimestamp
 }
 
 struct WorkflowExecutionTerminatedEventAttributes {
@@ -816,6 +843,16 @@ struct ChildWorkflowExecutionTerminatedEventAttributes {
   50: optional i64 (js.type = "Long") startedEventId
 }
 
+struct UpsertWorkflowMemoEventAttributes {
+  10: optional i64 (js.type = "Long") decisionTaskCompletedEventId
+  20: optional Memo memo
+}
+
+struct WorkflowExecutionTimeoutExtendedEventAttributes {
+  10: optional i32 executionStartToCloseTimeoutSeconds
+  20: optional string identity
+}
+
 struct HistoryEvent {
   10:  optional i64 (js.type = "Long") eventId
   20:  optional i64 (js.type = "Long") timestamp
@@ -863,6 +900,8 @@ struct HistoryEvent {
   420: optional SignalExternalWorkflowExecutionInitiatedEventAttributes signalExternalWorkflowExecutionInitiatedEventAttributes
   430: optional SignalExternalWorkflowExecutionFailedEventAttributes signalExternalWorkflowExecutionFailedEventAttributes
   440: optional ExternalWorkflowExecutionSignaledEventAttributes externalWorkflowExecutionSignaledEventAttributes
+  450: optional UpsertWorkflowMemoEventAttributes upsertWorkflowMemoEventAttributes
+  460: optional WorkflowExecutionTimeoutExtendedEventAttributes workflowExecutionTimeoutExtendedEventAttributes
 }
 
 struct History {
@@ -1006,10 +1045,19 @@ struct StartWorkflowExecutionRequest {
   140: optional Memo memo
   141: optional SearchAttributes searchAttributes
   150: optional Header header
+  // firstDecisionTaskStartToCloseTimeoutSeconds only applies to a first decision scheduled by the start itself. It is
+  // ignored for cron workflows and workflows started with a first decision backoff, whose first decision is scheduled
+  // later by a timer, and is not carried over to later runs of the workflow
+  160: optional i32 firstDecisionTaskStartToCloseTimeoutSeconds
+  // waitForFirstDecisionScheduled makes the start confirm the first decision of the workflow is scheduled before it
+  // returns, which costs an extra mutable state load when the start is deduplicated by request ID. It is rejected for
+  // cron workflows, whose first decision is delayed
+  170: optional bool waitForFirstDecisionScheduled
 }
 
 struct StartWorkflowExecutionResponse {
   10: optional string runId
+  20: optional i64 (js.type = "Long") nextEventId
 }
 
 struct PollForDecisionTaskRequest {
@@ -1033,6 +1081,8 @@ struct PollForDecisionTaskResponse {
   90: optional TaskList WorkflowExecutionTaskList
   100:  optional i64 (js.type = "Long") scheduledTimestamp
   110:  optional i64 (js.type = "Long") startedTimestamp
+  120: optional i32 stickyScheduleToStartTimeoutSeconds
+  130: optional i64 (js.type = "Long") scheduledTimestampOfThisAttempt
 }
 
 struct StickyExecutionAttributes {
@@ -1049,10 +1099,14 @@ struct RespondDecisionTaskCompletedRequest {
   60: optional bool returnNewDecisionTask
   70: optional bool forceCreateNewDecisionTask
   80: optional string binaryChecksum
+  // resetStickyForNextDecision schedules the next decision on the normal task list, e.g. after the worker evicted
+  // the workflow from its cache, stickiness resumes with the stickyAttributes of the following completion
+  90: optional bool resetStickyForNextDecision
 }
 
 struct RespondDecisionTaskCompletedResponse {
   10: optional PollForDecisionTaskResponse decisionTask
+  20: optional i64 eventsWritten
 }
 
 struct RespondDecisionTaskFailedRequest {
@@ -1184,6 +1238,14 @@ struct SignalWorkflowExecutionRequest {
   50: optional string identity
   60: optional string requestId
   70: optional binary control
+  80: optional Header header
+  // ttlSeconds drops the signal when it is not handled within the given window, measured from the time the server
+  // records the signal. A signal which arrived while a decision was in flight and expired before that decision
+  // completed is dropped by the server, any other expired signal is recorded and left to the decider to skip. A
+  // dropped signal neither counts toward the signals limit of the execution nor schedules a new decision. The
+  // signal is still delivered at least once: a client retry with the same requestId is deduplicated and keeps the
+  // original expiry, while a retry without a requestId records the signal again with a new expiry.
+  90: optional i32 ttlSeconds
 }
 
 struct SignalWithStartWorkflowExecutionRequest {
@@ -1205,6 +1267,8 @@ struct SignalWithStartWorkflowExecutionRequest {
   160: optional Memo memo
   161: optional SearchAttributes searchAttributes
   170: optional Header header
+  // ttlSeconds drops the signal when it is not handled within the given window, as for SignalWorkflowExecution
+  180: optional i32 ttlSeconds
 }
 
 struct TerminateWorkflowExecutionRequest {
@@ -1277,6 +1341,19 @@ struct CountWorkflowExecutionsResponse {
   10: optional i64 count
 }
 
+struct GetDomainWorkflowStateCountsRequest {
+  10: optional string domain
+}
+
+struct GetDomainWorkflowStateCountsResponse {
+  10: optional i64 running
+  20: optional i64 completed
+  30: optional i64 failed
+  40: optional i64 timedOut
+  50: optional i64 terminated
+  60: optional i64 canceled
+}
+
 struct GetSearchAttributesResponse {
   10: optional map<string, IndexedValueType> keys
 }
@@ -1316,6 +1393,7 @@ struct RespondQueryTaskCompletedRequest {
 struct DescribeWorkflowExecutionRequest {
   10: optional string domain
   20: optional WorkflowExecution execution
+  30: optional bool includeParentChain
 }
 
 struct PendingActivityInfo {
@@ -1329,12 +1407,26 @@ struct PendingActivityInfo {
   80: optional i32 maximumAttempts
   90: optional i64 (js.type = "Long") scheduledTimestamp
   100: optional i64 (js.type = "Long") expirationTimestamp
+  110: optional string lastFailureReason
+  120: optional bool retryBackoff
+  130: optional i64 (js.type = "Long") nextRetryTimestamp
 }
 
 struct DescribeWorkflowExecutionResponse {
   10: optional WorkflowExecutionConfiguration executionConfiguration
   20: optional WorkflowExecutionInfo workflowExecutionInfo
   30: optional list<PendingActivityInfo> pendingActivities
+  40: optional list<ParentExecutionInfo> parentChain
+  // decisionHeartbeatCount is the number of consecutive decisions which only forced a new decision. It is a cache
+  // only counter which is not persisted, it starts over when the workflow is evicted from the history cache or
+  // loaded on another host
+  50: optional i32 decisionHeartbeatCount
+}
+
+struct ParentExecutionInfo {
+  10: optional string domainUUID
+  20: optional string domain
+  30: optional WorkflowExecution execution
 }
 
 struct DescribeTaskListRequest {
@@ -122,6 +122,10 @@ struct WorkflowExecutionInfo {
   115: optional binary autoResetPoints
   116: optional string autoResetPointsEncoding
   118: optional map<string, binary> searchAttributes
+  120: optional i64 (js.type = "Long") closeTimestampNanos
+  122: optional map<string, binary> memo
+  124: optional map<string, i64> signalRequestedTimestamps
+  126: optional i64 (js.type = "Long") decisionOriginalScheduledTimestampNanos
 }
 
 struct ActivityInfo {
@@ -153,6 +157,7 @@ struct ActivityInfo {
   60: optional i64 (js.type = "Long") retryExpirationTimeNanos
   62: optional double retryBackoffCoefficient
   64: optional list<string> retryNonRetryableErrors
+  66: optional string lastFailureReason
 }
 
 struct ChildExecutionInfo {
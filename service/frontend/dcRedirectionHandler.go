@@ -429,6 +429,36 @@ func (handler *DCRedirectionHandlerImpl) CountWorkflowExecutions(
 	return resp, err
 }
 
+// GetDomainWorkflowStateCounts API call
+func (handler *DCRedirectionHandlerImpl) GetDomainWorkflowStateCounts(
+	ctx context.Context,
+	request *shared.GetDomainWorkflowStateCountsRequest,
+) (resp *shared.GetDomainWorkflowStateCountsResponse, retError error) {
+
+	var apiName = "GetDomainWorkflowStateCounts"
+	var err error
+	var cluster string
+
+	scope, startTime := handler.beforeCall(metrics.DCRedirectionGetDomainWorkflowStateCountsScope)
+	defer func() {
+		handler.afterCall(scope, startTime, cluster, &retError)
+	}()
+
+	err = handler.redirectionPolicy.WithDomainNameRedirect(request.GetDomain(), apiName, func(targetDC string) error {
+		cluster = targetDC
+		switch {
+		case targetDC == handler.currentClusterName:
+			resp, err = handler.frontendHandler.GetDomainWorkflowStateCounts(ctx, request)
+		default:
+			remoteClient := handler.clientBeanProvider().GetRemoteFrontendClient(targetDC)
+			resp, err = remoteClient.GetDomainWorkflowStateCounts(ctx, request)
+		}
+		return err
+	})
+
+	return resp, err
+}
+
 // GetSearchAttributes API call
 func (handler *DCRedirectionHandlerImpl) GetSearchAttributes(
 	ctx context.Context,
@@ -301,6 +301,29 @@ func (s *dcRedirectionHandlerSuite) TestCountWorkflowExecutions() {
 	s.Nil(err)
 }
 
+func (s *dcRedirectionHandlerSuite) TestGetDomainWorkflowStateCounts() {
+	apiName := "GetDomainWorkflowStateCounts"
+
+	s.mockDCRedirectionPolicy.On("WithDomainNameRedirect",
+		s.domainName, apiName, mock.Anything).Return(nil).Once()
+
+	req := &shared.GetDomainWorkflowStateCountsRequest{
+		Domain: common.StringPtr(s.domainName),
+	}
+	resp, err := s.handler.GetDomainWorkflowStateCounts(context.Background(), req)
+	s.Nil(err)
+	// the resp is initialized to nil, since inner function is not called
+	s.Nil(resp)
+
+	callFn := s.mockDCRedirectionPolicy.Calls[0].Arguments[2].(func(string) error)
+	s.mockFrontendHandler.On(apiName, mock.Anything, req).Return(&shared.GetDomainWorkflowStateCountsResponse{}, nil).Once()
+	err = callFn(s.currentClusterName)
+	s.Nil(err)
+	s.mockRemoteFrontendClient.On(apiName, mock.Anything, req).Return(&shared.GetDomainWorkflowStateCountsResponse{}, nil).Once()
+	err = callFn(s.alternativeClusterName)
+	s.Nil(err)
+}
+
 func (s *dcRedirectionHandlerSuite) TestPollForActivityTask() {
 	apiName := "PollForActivityTask"
 
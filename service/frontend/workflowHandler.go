@@ -172,7 +172,7 @@ func NewWorkflowHandler(sVice service.Service, config *Config, metadataMgr persi
 			NewDomainReplicator(kafkaProducer, sVice.GetLogger()),
 		),
 		visibilityQueryValidator: common.NewQueryValidator(config.ValidSearchAttributes),
-		historyBlobDownloader:    archiver.NewHistoryBlobDownloader(blobstoreClient),
+		historyBlobDownloader:    archiver.NewHistoryBlobDownloader(blobstoreClient, common.CreateBlobstoreOperationRetryPolicy(), sVice.GetLogger()),
 	}
 	// prevent us from trying to serve requests before handler's Start() is complete
 	handler.startWG.Add(1)
@@ -1312,6 +1312,9 @@ func (wh *WorkflowHandler) RespondDecisionTaskCompleted(
 	}
 
 	completedResp := &gen.RespondDecisionTaskCompletedResponse{}
+	if histResp != nil {
+		completedResp.EventsWritten = histResp.EventsWritten
+	}
 	if completeRequest.GetReturnNewDecisionTask() && histResp != nil && histResp.StartedResponse != nil {
 		taskToken := &common.TaskToken{
 			DomainID:        taskToken.DomainID,
@@ -1507,6 +1510,10 @@ func (wh *WorkflowHandler) StartWorkflowExecution(
 		return nil, wh.error(err, scope)
 	}
 
+	if err := backoff.ValidateScheduleWithRetryPolicy(startRequest.GetCronSchedule(), startRequest.RetryPolicy); err != nil {
+		return nil, wh.error(err, scope)
+	}
+
 	wh.Service.GetLogger().Debug(
 		"Received StartWorkflowExecution. WorkflowID",
 		tag.WorkflowID(startRequest.GetWorkflowId()))
@@ -1566,6 +1573,12 @@ func (wh *WorkflowHandler) StartWorkflowExecution(
 		return nil, wh.error(&gen.BadRequestError{
 			Message: fmt.Sprintf("TaskStartToCloseTimeoutSeconds is larger than ExecutionStartToCloseTimeout or MaxDecisionStartToCloseTimeout (%ds).", maxDecisionTimeout)}, scope)
 	}
+	if startRequest.FirstDecisionTaskStartToCloseTimeoutSeconds != nil &&
+		(startRequest.GetFirstDecisionTaskStartToCloseTimeoutSeconds() <= 0 ||
+			startRequest.GetFirstDecisionTaskStartToCloseTimeoutSeconds() > startRequest.GetExecutionStartToCloseTimeoutSeconds()) {
+		return nil, wh.error(&gen.BadRequestError{
+			Message: "FirstDecisionTaskStartToCloseTimeoutSeconds must be greater than 0 and not larger than ExecutionStartToCloseTimeout."}, scope)
+	}
 
 	wh.Service.GetLogger().Debug("Start workflow execution request domain", tag.WorkflowDomainName(domainName))
 	domainID, err := wh.domainCache.GetDomainID(domainName)
@@ -1851,6 +1864,10 @@ func (wh *WorkflowHandler) SignalWorkflowExecution(ctx context.Context,
 		return wh.error(errRequestIDTooLong, scope)
 	}
 
+	if signalRequest.GetTtlSeconds() < 0 {
+		return wh.error(&gen.BadRequestError{Message: "TtlSeconds cannot be negative."}, scope)
+	}
+
 	domainID, err := wh.domainCache.GetDomainID(signalRequest.GetDomain())
 	if err != nil {
 		return wh.error(err, scope)
@@ -1947,6 +1964,10 @@ func (wh *WorkflowHandler) SignalWithStartWorkflowExecution(ctx context.Context,
 		return nil, wh.error(errRequestIDTooLong, scope)
 	}
 
+	if signalWithStartRequest.GetTtlSeconds() < 0 {
+		return nil, wh.error(&gen.BadRequestError{Message: "TtlSeconds cannot be negative."}, scope)
+	}
+
 	if signalWithStartRequest.GetExecutionStartToCloseTimeoutSeconds() <= 0 {
 		return nil, wh.error(&gen.BadRequestError{
 			Message: "A valid ExecutionStartToCloseTimeoutSeconds is not set on request."}, scope)
@@ -1965,6 +1986,10 @@ func (wh *WorkflowHandler) SignalWithStartWorkflowExecution(ctx context.Context,
 		return nil, wh.error(err, scope)
 	}
 
+	if err := backoff.ValidateScheduleWithRetryPolicy(signalWithStartRequest.GetCronSchedule(), signalWithStartRequest.RetryPolicy); err != nil {
+		return nil, wh.error(err, scope)
+	}
+
 	if err := wh.validateSearchAttributes(signalWithStartRequest.SearchAttributes, domainName); err != nil {
 		return nil, wh.error(&gen.BadRequestError{Message: err.Error()}, scope)
 	}
@@ -2580,6 +2605,60 @@ func (wh *WorkflowHandler) CountWorkflowExecutions(ctx context.Context, countReq
 	return resp, nil
 }
 
+// GetDomainWorkflowStateCounts counts the workflow executions of a domain in each state. Visibility has no aggregation
+// query, so it takes one count query per state. The counts are only as fresh as the visibility records and are only
+// available when the domain is served by advanced visibility, the other visibility stores do not support count queries.
+func (wh *WorkflowHandler) GetDomainWorkflowStateCounts(ctx context.Context,
+	countRequest *gen.GetDomainWorkflowStateCountsRequest) (resp *gen.GetDomainWorkflowStateCountsResponse, retError error) {
+	defer log.CapturePanic(wh.GetLogger(), &retError)
+
+	scope, sw := wh.startRequestProfileWithDomain(metrics.FrontendGetDomainWorkflowStateCountsScope, countRequest)
+	defer sw.Stop()
+
+	if err := wh.versionChecker.checkClientVersion(ctx); err != nil {
+		return nil, wh.error(err, scope)
+	}
+
+	if countRequest == nil {
+		return nil, wh.error(errRequestNotSet, scope)
+	}
+
+	if ok, _ := wh.rateLimiter.TryConsume(1); !ok {
+		return nil, wh.error(createServiceBusyError(), scope)
+	}
+
+	if countRequest.GetDomain() == "" {
+		return nil, wh.error(errDomainNotSet, scope)
+	}
+
+	domain := countRequest.GetDomain()
+	domainID, err := wh.domainCache.GetDomainID(domain)
+	if err != nil {
+		return nil, wh.error(err, scope)
+	}
+
+	resp = &gen.GetDomainWorkflowStateCountsResponse{}
+	for query, count := range map[string]**int64{
+		"CloseTime = missing": &resp.Running,
+		fmt.Sprintf("CloseStatus = %v", int32(gen.WorkflowExecutionCloseStatusCompleted)):  &resp.Completed,
+		fmt.Sprintf("CloseStatus = %v", int32(gen.WorkflowExecutionCloseStatusFailed)):     &resp.Failed,
+		fmt.Sprintf("CloseStatus = %v", int32(gen.WorkflowExecutionCloseStatusTimedOut)):   &resp.TimedOut,
+		fmt.Sprintf("CloseStatus = %v", int32(gen.WorkflowExecutionCloseStatusTerminated)): &resp.Terminated,
+		fmt.Sprintf("CloseStatus = %v", int32(gen.WorkflowExecutionCloseStatusCanceled)):   &resp.Canceled,
+	} {
+		persistenceResp, err := wh.visibilityMgr.CountWorkflowExecutions(&persistence.CountWorkflowExecutionsRequest{
+			DomainUUID: domainID,
+			Domain:     domain,
+			Query:      query,
+		})
+		if err != nil {
+			return nil, wh.error(err, scope)
+		}
+		*count = common.Int64Ptr(persistenceResp.Count)
+	}
+	return resp, nil
+}
+
 // GetSearchAttributes return valid indexed keys
 func (wh *WorkflowHandler) GetSearchAttributes(ctx context.Context) (resp *gen.GetSearchAttributesResponse, retError error) {
 	defer log.CapturePanic(wh.GetLogger(), &retError)
@@ -2686,6 +2765,15 @@ func (wh *WorkflowHandler) QueryWorkflow(ctx context.Context,
 		DomainUUID: common.StringPtr(domainID),
 		Execution:  queryRequest.Execution,
 	})
+	if _, ok := err.(*gen.EntityNotExistsError); ok {
+		archivedResp, archivedErr := wh.queryFromArchival(ctx, queryRequest, domainID)
+		if archivedErr != nil {
+			return nil, wh.error(archivedErr, scope)
+		}
+		if archivedResp != nil {
+			return archivedResp, nil
+		}
+	}
 	if err != nil {
 		return nil, wh.error(err, scope)
 	}
@@ -2782,9 +2870,73 @@ func (wh *WorkflowHandler) DescribeWorkflowExecution(ctx context.Context, reques
 		return nil, wh.error(err, scope)
 	}
 
+	if request.GetIncludeParentChain() {
+		response.ParentChain, err = wh.getParentChain(ctx, request.GetDomain(), domainID, request.Execution, response.WorkflowExecutionInfo)
+		if err != nil {
+			return nil, wh.error(err, scope)
+		}
+	}
+
 	return response, nil
 }
 
+// getParentChain walks up the ancestors of a workflow starting with its immediate parent, each ancestor is described
+// by the history service. The walk stops at a workflow without parent, at a parent which no longer exists, when a
+// workflow is visited twice or after MaxParentChainDepth ancestors.
+func (wh *WorkflowHandler) getParentChain(
+	ctx context.Context,
+	domainName string,
+	domainID string,
+	execution *gen.WorkflowExecution,
+	executionInfo *gen.WorkflowExecutionInfo,
+) ([]*gen.ParentExecutionInfo, error) {
+
+	maxDepth := wh.config.MaxParentChainDepth(domainName)
+	visited := map[string]struct{}{
+		domainID + execution.GetWorkflowId() + execution.GetRunId(): {},
+	}
+	var chain []*gen.ParentExecutionInfo
+	for len(chain) < maxDepth && executionInfo.IsSetParentExecution() {
+		parentDomainID := executionInfo.GetParentDomainId()
+		parentExecution := executionInfo.ParentExecution
+		key := parentDomainID + parentExecution.GetWorkflowId() + parentExecution.GetRunId()
+		if _, ok := visited[key]; ok {
+			break
+		}
+		visited[key] = struct{}{}
+
+		parentDomainEntry, err := wh.domainCache.GetDomainByID(parentDomainID)
+		if err != nil {
+			return nil, err
+		}
+		parentDomainName := parentDomainEntry.GetInfo().Name
+		chain = append(chain, &gen.ParentExecutionInfo{
+			DomainUUID: common.StringPtr(parentDomainID),
+			Domain:     common.StringPtr(parentDomainName),
+			Execution:  parentExecution,
+		})
+		if len(chain) >= maxDepth {
+			break
+		}
+
+		response, err := wh.history.DescribeWorkflowExecution(ctx, &h.DescribeWorkflowExecutionRequest{
+			DomainUUID: common.StringPtr(parentDomainID),
+			Request: &gen.DescribeWorkflowExecutionRequest{
+				Domain:    common.StringPtr(parentDomainName),
+				Execution: parentExecution,
+			},
+		})
+		if _, ok := err.(*gen.EntityNotExistsError); ok {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		executionInfo = response.WorkflowExecutionInfo
+	}
+	return chain, nil
+}
+
 // DescribeTaskList returns information about the target tasklist, right now this API returns the
 // pollers which polled this tasklist in last few minutes. If includeTaskListStatus field is true,
 // it will also return status of tasklist's ackManager (readLevel, ackLevel, backlogCountHint and taskIDBlock).
@@ -3125,19 +3277,21 @@ func (wh *WorkflowHandler) createPollForDecisionTaskResponse(
 	}
 
 	resp := &gen.PollForDecisionTaskResponse{
-		TaskToken:                 matchingResp.TaskToken,
-		WorkflowExecution:         matchingResp.WorkflowExecution,
-		WorkflowType:              matchingResp.WorkflowType,
-		PreviousStartedEventId:    matchingResp.PreviousStartedEventId,
-		StartedEventId:            matchingResp.StartedEventId,
-		Query:                     matchingResp.Query,
-		BacklogCountHint:          matchingResp.BacklogCountHint,
-		Attempt:                   matchingResp.Attempt,
-		History:                   history,
-		NextPageToken:             continuation,
-		WorkflowExecutionTaskList: matchingResp.WorkflowExecutionTaskList,
-		ScheduledTimestamp:        matchingResp.ScheduledTimestamp,
-		StartedTimestamp:          matchingResp.StartedTimestamp,
+		TaskToken:                           matchingResp.TaskToken,
+		WorkflowExecution:                   matchingResp.WorkflowExecution,
+		WorkflowType:                        matchingResp.WorkflowType,
+		PreviousStartedEventId:              matchingResp.PreviousStartedEventId,
+		StartedEventId:                      matchingResp.StartedEventId,
+		Query:                               matchingResp.Query,
+		BacklogCountHint:                    matchingResp.BacklogCountHint,
+		Attempt:                             matchingResp.Attempt,
+		History:                             history,
+		NextPageToken:                       continuation,
+		WorkflowExecutionTaskList:           matchingResp.WorkflowExecutionTaskList,
+		ScheduledTimestamp:                  matchingResp.ScheduledTimestamp,
+		StartedTimestamp:                    matchingResp.StartedTimestamp,
+		StickyScheduleToStartTimeoutSeconds: matchingResp.StickyScheduleToStartTimeoutSeconds,
+		ScheduledTimestampOfThisAttempt:     matchingResp.ScheduledTimestampOfThisAttempt,
 	}
 
 	return resp, nil
@@ -3220,6 +3374,68 @@ func (wh *WorkflowHandler) getArchivedHistory(
 	}, nil
 }
 
+// queryFromArchival answers a query to a workflow run which no longer exists in persistence from its archived history.
+// The workflow code is not available to replay the history, so the final state of the run is returned instead of the
+// result of a query handler: the result of a completed run whatever the query type, or a query failed error naming
+// the event which closed the run otherwise. A nil response and error mean the history of the run cannot be read from
+// archival, e.g. the bucket or the history blob is missing, and the caller keeps its original error.
+func (wh *WorkflowHandler) queryFromArchival(
+	ctx context.Context,
+	queryRequest *gen.QueryWorkflowRequest,
+	domainID string,
+) (*gen.QueryWorkflowResponse, error) {
+	archivalConfig := wh.GetClusterMetadata().ArchivalConfig()
+	if !archivalConfig.ConfiguredForArchival() || !archivalConfig.EnableReadFromArchival() {
+		return nil, nil
+	}
+	if queryRequest.GetExecution().GetRunId() == "" {
+		return nil, nil
+	}
+	entry, err := wh.domainCache.GetDomainByID(domainID)
+	if err != nil || entry.GetConfig().ArchivalBucket == "" {
+		return nil, nil
+	}
+
+	downloadReq := &archiver.DownloadBlobRequest{
+		ArchivalBucket: entry.GetConfig().ArchivalBucket,
+		DomainID:       domainID,
+		WorkflowID:     queryRequest.GetExecution().GetWorkflowId(),
+		RunID:          queryRequest.GetExecution().GetRunId(),
+	}
+	// the event closing the run is the last event of the last page
+	var closeEvent *gen.HistoryEvent
+	for {
+		resp, err := wh.historyBlobDownloader.DownloadBlob(ctx, downloadReq)
+		if err != nil {
+			wh.GetLogger().Info("QueryWorkflow could not read archived history.",
+				tag.WorkflowDomainID(domainID),
+				tag.WorkflowID(queryRequest.GetExecution().GetWorkflowId()),
+				tag.WorkflowRunID(queryRequest.GetExecution().GetRunId()),
+				tag.Error(err))
+			return nil, nil
+		}
+		if events := resp.HistoryBlob.Body.GetEvents(); len(events) != 0 {
+			closeEvent = events[len(events)-1]
+		}
+		if len(resp.NextPageToken) == 0 {
+			break
+		}
+		downloadReq.NextPageToken = resp.NextPageToken
+	}
+	if closeEvent == nil {
+		return nil, nil
+	}
+
+	if closeEvent.GetEventType() != gen.EventTypeWorkflowExecutionCompleted {
+		return nil, &gen.QueryFailedError{Message: fmt.Sprintf(
+			"Workflow run was closed by %v and its history has been archived, only a completed run can be queried.",
+			closeEvent.GetEventType())}
+	}
+	return &gen.QueryWorkflowResponse{
+		QueryResult: closeEvent.WorkflowExecutionCompletedEventAttributes.Result,
+	}, nil
+}
+
 func (wh *WorkflowHandler) convertIndexedKeyToThrift(keys map[string]interface{}) map[string]gen.IndexedValueType {
 	converted := make(map[string]gen.IndexedValueType)
 	for k, v := range keys {
@@ -3256,13 +3472,14 @@ func (wh *WorkflowHandler) validateSearchAttributes(input *gen.SearchAttributes,
 		return fmt.Errorf("number of keys %d exceed limit", lengthOfFields)
 	}
 
+	if err := wh.visibilityQueryValidator.ValidateSearchAttributeKeys(input); err != nil {
+		wh.GetLogger().WithTags(tag.WorkflowDomainName(domain), tag.Error(err)).
+			Error("invalid search attribute")
+		return err
+	}
+
 	totalSize := 0
 	for key, val := range fields {
-		if !wh.visibilityQueryValidator.IsValidSearchAttributes(key) {
-			wh.GetLogger().WithTags(tag.ESKey(key), tag.WorkflowDomainName(domain)).
-				Error("invalid search attribute")
-			return fmt.Errorf("%s is not valid search attribute", key)
-		}
 		if definition.IsSystemIndexedKey(key) {
 			wh.GetLogger().WithTags(tag.ESKey(key), tag.WorkflowDomainName(domain)).
 				Error("illegal update of system reserved attribute")
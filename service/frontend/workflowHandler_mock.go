@@ -138,6 +138,29 @@ func (_m *MockWorkflowHandler) DescribeWorkflowExecution(ctx context.Context, De
 	return r0, r1
 }
 
+// GetDomainWorkflowStateCounts provides a mock function with given fields: ctx, CountRequest
+func (_m *MockWorkflowHandler) GetDomainWorkflowStateCounts(ctx context.Context, CountRequest *shared.GetDomainWorkflowStateCountsRequest) (*shared.GetDomainWorkflowStateCountsResponse, error) {
+	ret := _m.Called(ctx, CountRequest)
+
+	var r0 *shared.GetDomainWorkflowStateCountsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *shared.GetDomainWorkflowStateCountsRequest) *shared.GetDomainWorkflowStateCountsResponse); ok {
+		r0 = rf(ctx, CountRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*shared.GetDomainWorkflowStateCountsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *shared.GetDomainWorkflowStateCountsRequest) error); ok {
+		r1 = rf(ctx, CountRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSearchAttributes provides a mock function with given fields: ctx
 func (_m *MockWorkflowHandler) GetSearchAttributes(ctx context.Context) (*shared.GetSearchAttributesResponse, error) {
 	ret := _m.Called(ctx)
@@ -32,6 +32,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
+	h "github.com/uber/cadence/.gen/go/history"
 	"github.com/uber/cadence/.gen/go/shared"
 	gen "github.com/uber/cadence/.gen/go/shared"
 	workflow "github.com/uber/cadence/.gen/go/shared"
@@ -946,6 +947,101 @@ func (s *workflowHandlerSuite) TestHistoryArchived() {
 	s.False(wh.historyArchived(context.Background(), getHistoryRequest, "test-domain"))
 }
 
+func (s *workflowHandlerSuite) TestGetParentChain() {
+	config := s.newConfig()
+	mockHistoryClient := &mocks.HistoryClient{}
+	wh := &WorkflowHandler{
+		history:     mockHistoryClient,
+		domainCache: s.mockDomainCache,
+		config:      config,
+	}
+	domainEntry := cache.NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{ID: s.testDomainID, Name: s.testDomain},
+		&persistence.DomainConfig{},
+		"",
+		nil,
+	)
+	s.mockDomainCache.On("GetDomainByID", s.testDomainID).Return(domainEntry, nil)
+
+	child := &shared.WorkflowExecution{WorkflowId: common.StringPtr("child"), RunId: common.StringPtr(uuid.New())}
+	parent := &shared.WorkflowExecution{WorkflowId: common.StringPtr("parent"), RunId: common.StringPtr(uuid.New())}
+	grandparent := &shared.WorkflowExecution{WorkflowId: common.StringPtr("grandparent"), RunId: common.StringPtr(uuid.New())}
+	describeRequestFor := func(execution *shared.WorkflowExecution) interface{} {
+		return mock.MatchedBy(func(request *h.DescribeWorkflowExecutionRequest) bool {
+			return request.GetDomainUUID() == s.testDomainID &&
+				request.Request.Execution.GetRunId() == execution.GetRunId()
+		})
+	}
+	mockHistoryClient.On("DescribeWorkflowExecution", mock.Anything, describeRequestFor(parent)).Return(&shared.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &shared.WorkflowExecutionInfo{
+			Execution:       parent,
+			ParentDomainId:  common.StringPtr(s.testDomainID),
+			ParentExecution: grandparent,
+		},
+	}, nil)
+	mockHistoryClient.On("DescribeWorkflowExecution", mock.Anything, describeRequestFor(grandparent)).Return(&shared.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &shared.WorkflowExecutionInfo{
+			Execution: grandparent,
+		},
+	}, nil)
+	childInfo := &shared.WorkflowExecutionInfo{
+		Execution:       child,
+		ParentDomainId:  common.StringPtr(s.testDomainID),
+		ParentExecution: parent,
+	}
+
+	chain, err := wh.getParentChain(context.Background(), s.testDomain, s.testDomainID, child, childInfo)
+	s.NoError(err)
+	s.Equal([]*shared.ParentExecutionInfo{
+		{DomainUUID: common.StringPtr(s.testDomainID), Domain: common.StringPtr(s.testDomain), Execution: parent},
+		{DomainUUID: common.StringPtr(s.testDomainID), Domain: common.StringPtr(s.testDomain), Execution: grandparent},
+	}, chain)
+
+	config.MaxParentChainDepth = dc.GetIntPropertyFilteredByDomain(1)
+	chain, err = wh.getParentChain(context.Background(), s.testDomain, s.testDomainID, child, childInfo)
+	s.NoError(err)
+	s.Equal([]*shared.ParentExecutionInfo{
+		{DomainUUID: common.StringPtr(s.testDomainID), Domain: common.StringPtr(s.testDomain), Execution: parent},
+	}, chain)
+}
+
+func (s *workflowHandlerSuite) TestGetParentChain_Cycle() {
+	mockHistoryClient := &mocks.HistoryClient{}
+	wh := &WorkflowHandler{
+		history:     mockHistoryClient,
+		domainCache: s.mockDomainCache,
+		config:      s.newConfig(),
+	}
+	domainEntry := cache.NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{ID: s.testDomainID, Name: s.testDomain},
+		&persistence.DomainConfig{},
+		"",
+		nil,
+	)
+	s.mockDomainCache.On("GetDomainByID", s.testDomainID).Return(domainEntry, nil)
+
+	child := &shared.WorkflowExecution{WorkflowId: common.StringPtr("child"), RunId: common.StringPtr(uuid.New())}
+	parent := &shared.WorkflowExecution{WorkflowId: common.StringPtr("parent"), RunId: common.StringPtr(uuid.New())}
+	mockHistoryClient.On("DescribeWorkflowExecution", mock.Anything, mock.Anything).Return(&shared.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &shared.WorkflowExecutionInfo{
+			Execution:       parent,
+			ParentDomainId:  common.StringPtr(s.testDomainID),
+			ParentExecution: child,
+		},
+	}, nil).Once()
+
+	chain, err := wh.getParentChain(context.Background(), s.testDomain, s.testDomainID, child, &shared.WorkflowExecutionInfo{
+		Execution:       child,
+		ParentDomainId:  common.StringPtr(s.testDomainID),
+		ParentExecution: parent,
+	})
+	s.NoError(err)
+	s.Equal([]*shared.ParentExecutionInfo{
+		{DomainUUID: common.StringPtr(s.testDomainID), Domain: common.StringPtr(s.testDomain), Execution: parent},
+	}, chain)
+	mockHistoryClient.AssertExpectations(s.T())
+}
+
 func (s *workflowHandlerSuite) TestGetArchivedHistory_Failure_DomainCacheEntryError() {
 	config := s.newConfig()
 	mMetadataManager := &mocks.MetadataManager{}
@@ -1073,6 +1169,86 @@ func (s *workflowHandlerSuite) TestGetArchivedHistory_Success_GetFirstPage() {
 	s.True(resp.GetArchived())
 }
 
+func (s *workflowHandlerSuite) TestQueryFromArchival_Failure_HistoryNotArchived() {
+	config := s.newConfig()
+	mMetadataManager := &mocks.MetadataManager{}
+	mMetadataManager.On("GetDomain", mock.Anything).Return(persistenceGetDomainResponse(testArchivalBucket, shared.ArchivalStatusEnabled), nil)
+	clusterMetadata := &mocks.ClusterMetadata{}
+	clusterMetadata.On("IsGlobalDomainEnabled").Return(false)
+	clusterMetadata.On("ArchivalConfig").Return(cluster.NewArchivalConfig(cluster.ArchivalEnabled, testArchivalBucket, true))
+	mService := cs.NewTestService(clusterMetadata, s.mockMessagingClient, s.mockMetricClient, s.mockClientBean)
+	mBlobstore := &mocks.BlobstoreClient{}
+	mBlobstore.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(nil, blobstore.ErrBlobNotExists)
+	wh := s.getWorkflowHandlerWithParams(mService, config, mMetadataManager, mBlobstore)
+	wh.metricsClient = wh.Service.GetMetricsClient()
+	wh.startWG.Done()
+	resp, err := wh.queryFromArchival(context.Background(), queryRequest(), s.testDomainID)
+	s.NoError(err)
+	s.Nil(resp)
+}
+
+func (s *workflowHandlerSuite) TestQueryFromArchival_Failure_ArchivalBucketEmpty() {
+	config := s.newConfig()
+	mMetadataManager := &mocks.MetadataManager{}
+	mMetadataManager.On("GetDomain", mock.Anything).Return(persistenceGetDomainResponse("", shared.ArchivalStatusDisabled), nil)
+	clusterMetadata := &mocks.ClusterMetadata{}
+	clusterMetadata.On("IsGlobalDomainEnabled").Return(false)
+	clusterMetadata.On("ArchivalConfig").Return(cluster.NewArchivalConfig(cluster.ArchivalEnabled, testArchivalBucket, true))
+	mService := cs.NewTestService(clusterMetadata, s.mockMessagingClient, s.mockMetricClient, s.mockClientBean)
+	mBlobstore := &mocks.BlobstoreClient{}
+	wh := s.getWorkflowHandlerWithParams(mService, config, mMetadataManager, mBlobstore)
+	wh.metricsClient = wh.Service.GetMetricsClient()
+	wh.startWG.Done()
+	resp, err := wh.queryFromArchival(context.Background(), queryRequest(), s.testDomainID)
+	s.NoError(err)
+	s.Nil(resp)
+}
+
+func (s *workflowHandlerSuite) TestQueryFromArchival_Success() {
+	config := s.newConfig()
+	mMetadataManager := &mocks.MetadataManager{}
+	mMetadataManager.On("GetDomain", mock.Anything).Return(persistenceGetDomainResponse(testArchivalBucket, shared.ArchivalStatusEnabled), nil)
+	clusterMetadata := &mocks.ClusterMetadata{}
+	clusterMetadata.On("IsGlobalDomainEnabled").Return(false)
+	clusterMetadata.On("ArchivalConfig").Return(cluster.NewArchivalConfig(cluster.ArchivalEnabled, testArchivalBucket, true))
+	mService := cs.NewTestService(clusterMetadata, s.mockMessagingClient, s.mockMetricClient, s.mockClientBean)
+	mBlobstore := &mocks.BlobstoreClient{}
+	unwrappedBlob := &archiver.HistoryBlob{
+		Header: &archiver.HistoryBlobHeader{
+			CurrentPageToken: common.IntPtr(common.FirstBlobPageToken),
+			IsLast:           common.BoolPtr(true),
+		},
+		Body: &shared.History{
+			Events: []*shared.HistoryEvent{
+				{
+					EventId:   common.Int64Ptr(1),
+					EventType: shared.EventTypeWorkflowExecutionStarted.Ptr(),
+				},
+				{
+					EventId:   common.Int64Ptr(2),
+					EventType: shared.EventTypeWorkflowExecutionCompleted.Ptr(),
+					WorkflowExecutionCompletedEventAttributes: &shared.WorkflowExecutionCompletedEventAttributes{
+						Result: []byte("result"),
+					},
+				},
+			},
+		},
+	}
+	bytes, err := json.Marshal(unwrappedBlob)
+	s.NoError(err)
+	historyBlob, err := blob.Wrap(blob.NewBlob(bytes, map[string]string{}), blob.JSONEncoded())
+	s.NoError(err)
+	mBlobstore.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(map[string]string{"10": ""}, nil)
+	historyKey, _ := archiver.NewHistoryBlobKey(s.testDomainID, testWorkflowID, testRunID, 10, common.FirstBlobPageToken)
+	mBlobstore.On("Download", mock.Anything, mock.Anything, historyKey).Return(historyBlob, nil)
+	wh := s.getWorkflowHandlerWithParams(mService, config, mMetadataManager, mBlobstore)
+	wh.metricsClient = wh.Service.GetMetricsClient()
+	wh.startWG.Done()
+	resp, err := wh.queryFromArchival(context.Background(), queryRequest(), s.testDomainID)
+	s.NoError(err)
+	s.Equal([]byte("result"), resp.QueryResult)
+}
+
 func (s *workflowHandlerSuite) TestGetHistory() {
 	config := s.newConfig()
 	domainID := uuid.New()
@@ -1202,6 +1378,54 @@ func (s *workflowHandlerSuite) TestCountWorkflowExecutions() {
 	s.NotNil(err)
 }
 
+func (s *workflowHandlerSuite) TestGetDomainWorkflowStateCounts() {
+	wh := s.getWorkflowHandlerHelper()
+
+	s.mockDomainCache.On("GetDomainID", s.testDomain).Return(s.testDomainID, nil)
+	for query, count := range map[string]int64{
+		"CloseTime = missing": 6,
+		"CloseStatus = 0":     5,
+		"CloseStatus = 1":     4,
+		"CloseStatus = 5":     3,
+		"CloseStatus = 3":     2,
+		"CloseStatus = 2":     1,
+	} {
+		s.mockVisibilityMgr.On("CountWorkflowExecutions", &persistence.CountWorkflowExecutionsRequest{
+			DomainUUID: s.testDomainID,
+			Domain:     s.testDomain,
+			Query:      query,
+		}).Return(&persistence.CountWorkflowExecutionsResponse{Count: count}, nil).Once()
+	}
+
+	counts, err := wh.GetDomainWorkflowStateCounts(context.Background(), &shared.GetDomainWorkflowStateCountsRequest{
+		Domain: common.StringPtr(s.testDomain),
+	})
+	s.NoError(err)
+	s.Equal(&shared.GetDomainWorkflowStateCountsResponse{
+		Running:    common.Int64Ptr(6),
+		Completed:  common.Int64Ptr(5),
+		Failed:     common.Int64Ptr(4),
+		TimedOut:   common.Int64Ptr(3),
+		Terminated: common.Int64Ptr(2),
+		Canceled:   common.Int64Ptr(1),
+	}, counts)
+}
+
+func (s *workflowHandlerSuite) TestGetDomainWorkflowStateCounts_InvalidDomain() {
+	wh := s.getWorkflowHandlerHelper()
+
+	_, err := wh.GetDomainWorkflowStateCounts(context.Background(), &shared.GetDomainWorkflowStateCountsRequest{})
+	s.Equal(errDomainNotSet, err)
+
+	// an unknown domain is rejected before visibility is queried
+	s.mockDomainCache.On("GetDomainID", "unknown-domain").Return("", &shared.EntityNotExistsError{}).Once()
+	_, err = wh.GetDomainWorkflowStateCounts(context.Background(), &shared.GetDomainWorkflowStateCountsRequest{
+		Domain: common.StringPtr("unknown-domain"),
+	})
+	s.IsType(&shared.EntityNotExistsError{}, err)
+	s.mockVisibilityMgr.AssertNotCalled(s.T(), "CountWorkflowExecutions", mock.Anything)
+}
+
 func (s *workflowHandlerSuite) newConfig() *Config {
 	return NewConfig(dc.NewCollection(dc.NewNopClient(), s.logger), numHistoryShards, false)
 }
@@ -1284,6 +1508,16 @@ func registerDomainRequest(archivalStatus *shared.ArchivalStatus, bucketName *st
 	}
 }
 
+func queryRequest() *shared.QueryWorkflowRequest {
+	return &shared.QueryWorkflowRequest{
+		Execution: &shared.WorkflowExecution{
+			WorkflowId: common.StringPtr(testWorkflowID),
+			RunId:      common.StringPtr(testRunID),
+		},
+		Query: &shared.WorkflowQuery{QueryType: common.StringPtr("test-query")},
+	}
+}
+
 func getHistoryRequest(nextPageToken []byte) *shared.GetWorkflowExecutionHistoryRequest {
 	return &shared.GetWorkflowExecutionHistoryRequest{
 		Execution: &shared.WorkflowExecution{
@@ -22,6 +22,7 @@ package history
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	gohistory "github.com/uber/cadence/.gen/go/history"
@@ -89,6 +90,127 @@ func (_m *MockHistoryEngine) DescribeMutableState(ctx context.Context, request *
 	return r0, r1
 }
 
+// ExportMutableState is mock implementation for ExportMutableState of HistoryEngine
+func (_m *MockHistoryEngine) ExportMutableState(ctx context.Context, domainUUID string, execution shared.WorkflowExecution, compress bool) (*MutableStateSnapshot, error) {
+	ret := _m.Called(ctx, domainUUID, execution, compress)
+
+	var r0 *MutableStateSnapshot
+	if rf, ok := ret.Get(0).(func(string, shared.WorkflowExecution, bool) *MutableStateSnapshot); ok {
+		r0 = rf(domainUUID, execution, compress)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*MutableStateSnapshot)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, shared.WorkflowExecution, bool) error); ok {
+		r1 = rf(domainUUID, execution, compress)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCurrentBranch is mock implementation for GetCurrentBranch of HistoryEngine
+func (_m *MockHistoryEngine) GetCurrentBranch(ctx context.Context, domainUUID string, execution shared.WorkflowExecution) ([]byte, int32, int64, error) {
+	ret := _m.Called(ctx, domainUUID, execution)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string, shared.WorkflowExecution) []byte); ok {
+		r0 = rf(domainUUID, execution)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 int32
+	if rf, ok := ret.Get(1).(func(string, shared.WorkflowExecution) int32); ok {
+		r1 = rf(domainUUID, execution)
+	} else {
+		r1 = ret.Get(1).(int32)
+	}
+
+	var r2 int64
+	if rf, ok := ret.Get(2).(func(string, shared.WorkflowExecution) int64); ok {
+		r2 = rf(domainUUID, execution)
+	} else {
+		r2 = ret.Get(2).(int64)
+	}
+
+	var r3 error
+	if rf, ok := ret.Get(3).(func(string, shared.WorkflowExecution) error); ok {
+		r3 = rf(domainUUID, execution)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// GetCurrentRunID is mock implementation for GetCurrentRunID of HistoryEngine
+func (_m *MockHistoryEngine) GetCurrentRunID(ctx context.Context, domainUUID string, workflowID string) (string, bool, error) {
+	ret := _m.Called(ctx, domainUUID, workflowID)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, domainUUID, workflowID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) bool); ok {
+		r1 = rf(ctx, domainUUID, workflowID)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, domainUUID, workflowID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetRawHistory is mock implementation for GetRawHistory of HistoryEngine
+func (_m *MockHistoryEngine) GetRawHistory(ctx context.Context, domainUUID string, execution shared.WorkflowExecution, firstEventID int64,
+	nextEventID int64, pageSize int, nextPageToken []byte) ([]*shared.HistoryEvent, []byte, error) {
+	ret := _m.Called(ctx, domainUUID, execution, firstEventID, nextEventID, pageSize, nextPageToken)
+
+	var r0 []*shared.HistoryEvent
+	if rf, ok := ret.Get(0).(func(context.Context, string, shared.WorkflowExecution, int64, int64, int, []byte) []*shared.HistoryEvent); ok {
+		r0 = rf(ctx, domainUUID, execution, firstEventID, nextEventID, pageSize, nextPageToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*shared.HistoryEvent)
+		}
+	}
+
+	var r1 []byte
+	if rf, ok := ret.Get(1).(func(context.Context, string, shared.WorkflowExecution, int64, int64, int, []byte) []byte); ok {
+		r1 = rf(ctx, domainUUID, execution, firstEventID, nextEventID, pageSize, nextPageToken)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]byte)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, shared.WorkflowExecution, int64, int64, int, []byte) error); ok {
+		r2 = rf(ctx, domainUUID, execution, firstEventID, nextEventID, pageSize, nextPageToken)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetMutableState is mock implementation for GetMutableState of HistoryEngine
 func (_m *MockHistoryEngine) GetMutableState(ctx context.Context, request *gohistory.GetMutableStateRequest) (*gohistory.GetMutableStateResponse, error) {
 	ret := _m.Called(ctx, request)
@@ -158,6 +280,30 @@ func (_m *MockHistoryEngine) DescribeWorkflowExecution(ctx context.Context, requ
 	return r0, r1
 }
 
+// GetWorkflowExecutionResult is mock implementation for GetWorkflowExecutionResult of HistoryEngine
+func (_m *MockHistoryEngine) GetWorkflowExecutionResult(ctx context.Context, domainUUID string,
+	execution shared.WorkflowExecution) (*WorkflowExecutionResult, error) {
+	ret := _m.Called(ctx, domainUUID, execution)
+
+	var r0 *WorkflowExecutionResult
+	if rf, ok := ret.Get(0).(func(context.Context, string, shared.WorkflowExecution) *WorkflowExecutionResult); ok {
+		r0 = rf(ctx, domainUUID, execution)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*WorkflowExecutionResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, shared.WorkflowExecution) error); ok {
+		r1 = rf(ctx, domainUUID, execution)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RecordDecisionTaskStarted is mock implementation for RecordDecisionTaskStarted of HistoryEngine
 func (_m *MockHistoryEngine) RecordDecisionTaskStarted(ctx context.Context, request *gohistory.RecordDecisionTaskStartedRequest) (*gohistory.RecordDecisionTaskStartedResponse, error) {
 	ret := _m.Called(request)
@@ -241,6 +387,29 @@ func (_m *MockHistoryEngine) RespondDecisionTaskFailed(ctx context.Context, requ
 	return r0
 }
 
+// ReplayDecision is mock implementation for ReplayDecision of HistoryEngine
+func (_m *MockHistoryEngine) ReplayDecision(ctx context.Context, domainUUID string, execution shared.WorkflowExecution, decisionScheduleID int64) (*DecisionReplayResult, error) {
+	ret := _m.Called(ctx, domainUUID, execution, decisionScheduleID)
+
+	var r0 *DecisionReplayResult
+	if rf, ok := ret.Get(0).(func(string, shared.WorkflowExecution, int64) *DecisionReplayResult); ok {
+		r0 = rf(domainUUID, execution, decisionScheduleID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DecisionReplayResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, shared.WorkflowExecution, int64) error); ok {
+		r1 = rf(domainUUID, execution, decisionScheduleID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RespondActivityTaskCompleted is mock implementation for RespondActivityTaskCompleted of HistoryEngine
 func (_m *MockHistoryEngine) RespondActivityTaskCompleted(ctx context.Context, request *gohistory.RespondActivityTaskCompletedRequest) error {
 	ret := _m.Called(request)
@@ -283,6 +452,36 @@ func (_m *MockHistoryEngine) RespondActivityTaskCanceled(ctx context.Context, re
 	return r0
 }
 
+// ForceCompleteActivity is mock implementation for ForceCompleteActivity of HistoryEngine
+func (_m *MockHistoryEngine) ForceCompleteActivity(ctx context.Context, domainUUID string, execution shared.WorkflowExecution,
+	scheduleID int64, result []byte) error {
+	ret := _m.Called(ctx, domainUUID, execution, scheduleID, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, shared.WorkflowExecution, int64, []byte) error); ok {
+		r0 = rf(ctx, domainUUID, execution, scheduleID, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExtendWorkflowTimeout is mock implementation for ExtendWorkflowTimeout of HistoryEngine
+func (_m *MockHistoryEngine) ExtendWorkflowTimeout(ctx context.Context, domainUUID string, execution shared.WorkflowExecution,
+	additionalSeconds int32) error {
+	ret := _m.Called(ctx, domainUUID, execution, additionalSeconds)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, shared.WorkflowExecution, int32) error); ok {
+		r0 = rf(ctx, domainUUID, execution, additionalSeconds)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RecordActivityTaskHeartbeat is mock implementation for RecordActivityTaskHeartbeat of HistoryEngine
 func (_m *MockHistoryEngine) RecordActivityTaskHeartbeat(ctx context.Context, request *gohistory.RecordActivityTaskHeartbeatRequest) (*shared.RecordActivityTaskHeartbeatResponse, error) {
 	ret := _m.Called(request)
@@ -386,6 +585,119 @@ func (_m *MockHistoryEngine) TerminateWorkflowExecution(ctx context.Context, req
 	return r0
 }
 
+// DeleteWorkflowExecution is mock implementation for DeleteWorkflowExecution of HistoryEngine
+func (_m *MockHistoryEngine) DeleteWorkflowExecution(ctx context.Context, domainUUID string, execution shared.WorkflowExecution) error {
+	ret := _m.Called(ctx, domainUUID, execution)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, shared.WorkflowExecution) error); ok {
+		r0 = rf(domainUUID, execution)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReemitOpenVisibility is mock implementation for ReemitOpenVisibility of HistoryEngine
+func (_m *MockHistoryEngine) ReemitOpenVisibility(ctx context.Context, domainUUID string, execution shared.WorkflowExecution) error {
+	ret := _m.Called(ctx, domainUUID, execution)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, shared.WorkflowExecution) error); ok {
+		r0 = rf(domainUUID, execution)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListWorkflowsWithStaleDecision is mock implementation for ListWorkflowsWithStaleDecision of HistoryEngine
+func (_m *MockHistoryEngine) ListWorkflowsWithStaleDecision(ctx context.Context, domainUUID string, threshold time.Duration,
+	pageSize int, nextPageToken []byte) (*StaleDecisionWorkflows, error) {
+	ret := _m.Called(ctx, domainUUID, threshold, pageSize, nextPageToken)
+
+	var r0 *StaleDecisionWorkflows
+	if rf, ok := ret.Get(0).(func(string, time.Duration, int, []byte) *StaleDecisionWorkflows); ok {
+		r0 = rf(domainUUID, threshold, pageSize, nextPageToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*StaleDecisionWorkflows)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, time.Duration, int, []byte) error); ok {
+		r1 = rf(domainUUID, threshold, pageSize, nextPageToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkBinaryBad is mock implementation for MarkBinaryBad of HistoryEngine
+func (_m *MockHistoryEngine) MarkBinaryBad(ctx context.Context, domainUUID string, checksum string, reason string,
+	operator string, resetWorkflows bool) (int, error) {
+	ret := _m.Called(ctx, domainUUID, checksum, reason, operator, resetWorkflows)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(string, string, string, string, bool) int); ok {
+		r0 = rf(domainUUID, checksum, reason, operator, resetWorkflows)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, string, string, bool) error); ok {
+		r1 = rf(domainUUID, checksum, reason, operator, resetWorkflows)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReplicationStatus is mock implementation for GetReplicationStatus of HistoryEngine
+func (_m *MockHistoryEngine) GetReplicationStatus(ctx context.Context, domainUUID string,
+	execution shared.WorkflowExecution) (*WorkflowReplicationStatus, error) {
+	ret := _m.Called(ctx, domainUUID, execution)
+
+	var r0 *WorkflowReplicationStatus
+	if rf, ok := ret.Get(0).(func(string, shared.WorkflowExecution) *WorkflowReplicationStatus); ok {
+		r0 = rf(domainUUID, execution)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*WorkflowReplicationStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, shared.WorkflowExecution) error); ok {
+		r1 = rf(domainUUID, execution)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CleanupOrphanedHistoryBranch is mock implementation for CleanupOrphanedHistoryBranch of HistoryEngine
+func (_m *MockHistoryEngine) CleanupOrphanedHistoryBranch(ctx context.Context, domainUUID string, execution shared.WorkflowExecution,
+	branchToken []byte) error {
+	ret := _m.Called(ctx, domainUUID, execution, branchToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, shared.WorkflowExecution, []byte) error); ok {
+		r0 = rf(domainUUID, execution, branchToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResetWorkflowExecution is mock implementation for TerminateWorkflowExecution of HistoryEngine
 func (_m *MockHistoryEngine) ResetWorkflowExecution(ctx context.Context, request *gohistory.ResetWorkflowExecutionRequest) (*shared.ResetWorkflowExecutionResponse, error) {
 	ret := _m.Called(request)
@@ -493,4 +805,43 @@ func (_m *MockHistoryEngine) SyncActivity(ctx context.Context, request *gohistor
 	return r0
 }
 
+// DescribeQueueProcessorStatus is mock implementation for DescribeQueueProcessorStatus of HistoryEngine
+func (_m *MockHistoryEngine) DescribeQueueProcessorStatus() *ShardQueueProcessorStatus {
+	ret := _m.Called()
+
+	var r0 *ShardQueueProcessorStatus
+	if rf, ok := ret.Get(0).(func() *ShardQueueProcessorStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ShardQueueProcessorStatus)
+		}
+	}
+
+	return r0
+}
+
+// DescribeArchivalBacklog is mock implementation for DescribeArchivalBacklog of HistoryEngine
+func (_m *MockHistoryEngine) DescribeArchivalBacklog() (*ShardArchivalBacklog, error) {
+	ret := _m.Called()
+
+	var r0 *ShardArchivalBacklog
+	if rf, ok := ret.Get(0).(func() *ShardArchivalBacklog); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ShardArchivalBacklog)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 var _ Engine = (*MockHistoryEngine)(nil)
@@ -524,6 +524,29 @@ func (_m *mockMutableState) AddDecisionTaskScheduledEvent() (*decisionInfo, erro
 	return r0, r1
 }
 
+// AddDecisionTaskScheduledEventWithTimeout provides a mock function with given fields: startToCloseTimeoutSeconds
+func (_m *mockMutableState) AddDecisionTaskScheduledEventWithTimeout(startToCloseTimeoutSeconds int32) (*decisionInfo, error) {
+	ret := _m.Called(startToCloseTimeoutSeconds)
+
+	var r0 *decisionInfo
+	if rf, ok := ret.Get(0).(func(int32) *decisionInfo); ok {
+		r0 = rf(startToCloseTimeoutSeconds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*decisionInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32) error); ok {
+		r1 = rf(startToCloseTimeoutSeconds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AddDecisionTaskStartedEvent provides a mock function with given fields: _a0, _a1, _a2
 func (_m *mockMutableState) AddDecisionTaskStartedEvent(_a0 int64, _a1 string, _a2 *shared.PollForDecisionTaskRequest) (*shared.HistoryEvent, *decisionInfo, error) {
 	ret := _m.Called(_a0, _a1, _a2)
@@ -965,6 +988,29 @@ func (_m *mockMutableState) AddTimerStartedEvent(_a0 int64, _a1 *shared.StartTim
 	return r0, r1, r2
 }
 
+// AddUpsertWorkflowMemoEvent provides a mock function with given fields: _a0, _a1
+func (_m *mockMutableState) AddUpsertWorkflowMemoEvent(_a0 int64, _a1 *shared.UpsertWorkflowMemoDecisionAttributes) (*shared.HistoryEvent, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *shared.HistoryEvent
+	if rf, ok := ret.Get(0).(func(int64, *shared.UpsertWorkflowMemoDecisionAttributes) *shared.HistoryEvent); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*shared.HistoryEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, *shared.UpsertWorkflowMemoDecisionAttributes) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AddWorkflowExecutionCancelRequestedEvent provides a mock function with given fields: _a0, _a1
 func (_m *mockMutableState) AddWorkflowExecutionCancelRequestedEvent(_a0 string, _a1 *h.RequestCancelWorkflowExecutionRequest) (*shared.HistoryEvent, error) {
 	ret := _m.Called(_a0, _a1)
@@ -1011,13 +1057,13 @@ func (_m *mockMutableState) AddWorkflowExecutionCanceledEvent(_a0 int64, _a1 *sh
 	return r0, r1
 }
 
-// AddWorkflowExecutionSignaled provides a mock function with given fields: signalName, input, identity
-func (_m *mockMutableState) AddWorkflowExecutionSignaled(signalName string, input []byte, identity string) (*shared.HistoryEvent, error) {
-	ret := _m.Called(signalName, input, identity)
+// AddWorkflowExecutionSignaled provides a mock function with given fields: signalName, input, identity, header, expirationTimestamp
+func (_m *mockMutableState) AddWorkflowExecutionSignaled(signalName string, input []byte, identity string, header *shared.Header, expirationTimestamp int64) (*shared.HistoryEvent, error) {
+	ret := _m.Called(signalName, input, identity, header, expirationTimestamp)
 
 	var r0 *shared.HistoryEvent
-	if rf, ok := ret.Get(0).(func(string, []byte, string) *shared.HistoryEvent); ok {
-		r0 = rf(signalName, input, identity)
+	if rf, ok := ret.Get(0).(func(string, []byte, string, *shared.Header, int64) *shared.HistoryEvent); ok {
+		r0 = rf(signalName, input, identity, header, expirationTimestamp)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*shared.HistoryEvent)
@@ -1025,8 +1071,8 @@ func (_m *mockMutableState) AddWorkflowExecutionSignaled(signalName string, inpu
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, []byte, string) error); ok {
-		r1 = rf(signalName, input, identity)
+	if rf, ok := ret.Get(1).(func(string, []byte, string, *shared.Header, int64) error); ok {
+		r1 = rf(signalName, input, identity, header, expirationTimestamp)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1080,6 +1126,29 @@ func (_m *mockMutableState) AddWorkflowExecutionTerminatedEvent(_a0 string, _a1
 	return r0, r1
 }
 
+// AddWorkflowExecutionTimeoutExtendedEvent provides a mock function with given fields: timeoutSeconds, identity
+func (_m *mockMutableState) AddWorkflowExecutionTimeoutExtendedEvent(timeoutSeconds int32, identity string) (*shared.HistoryEvent, error) {
+	ret := _m.Called(timeoutSeconds, identity)
+
+	var r0 *shared.HistoryEvent
+	if rf, ok := ret.Get(0).(func(int32, string) *shared.HistoryEvent); ok {
+		r0 = rf(timeoutSeconds, identity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*shared.HistoryEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32, string) error); ok {
+		r1 = rf(timeoutSeconds, identity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CheckResettable provides a mock function with given fields:
 func (_m *mockMutableState) CheckResettable() error {
 	ret := _m.Called()
@@ -1396,6 +1465,22 @@ func (_m *mockMutableState) GetAllSignalsToSend() map[int64]*persistence.SignalI
 	return r0
 }
 
+// GetBufferedEvents provides a mock function with given fields:
+func (_m *mockMutableState) GetBufferedEvents() []*shared.HistoryEvent {
+	ret := _m.Called()
+
+	var r0 []*shared.HistoryEvent
+	if rf, ok := ret.Get(0).(func() []*shared.HistoryEvent); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*shared.HistoryEvent)
+		}
+	}
+
+	return r0
+}
+
 // GetChildExecutionInfo provides a mock function with given fields: _a0
 func (_m *mockMutableState) GetChildExecutionInfo(_a0 int64) (*persistence.ChildExecutionInfo, bool) {
 	ret := _m.Called(_a0)
@@ -1922,6 +2007,20 @@ func (_m *mockMutableState) HasBufferedEvents() bool {
 	return r0
 }
 
+// HasUnhandledBufferedEvents provides a mock function with given fields:
+func (_m *mockMutableState) HasUnhandledBufferedEvents() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // HasBufferedReplicationTasks provides a mock function with given fields:
 func (_m *mockMutableState) HasBufferedReplicationTasks() bool {
 	ret := _m.Called()
@@ -2571,6 +2670,20 @@ func (_m *mockMutableState) ReplicateTransientDecisionTaskScheduled() (*decision
 	return r0, r1
 }
 
+// ReplicateUpsertWorkflowMemoEvent provides a mock function with given fields: _a0
+func (_m *mockMutableState) ReplicateUpsertWorkflowMemoEvent(_a0 *shared.HistoryEvent) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*shared.HistoryEvent) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ReplicateWorkflowExecutionCancelRequestedEvent provides a mock function with given fields: _a0
 func (_m *mockMutableState) ReplicateWorkflowExecutionCancelRequestedEvent(_a0 *shared.HistoryEvent) error {
 	ret := _m.Called(_a0)
@@ -2697,6 +2810,20 @@ func (_m *mockMutableState) ReplicateWorkflowExecutionTimedoutEvent(_a0 int64, _
 	return r0
 }
 
+// ReplicateWorkflowExecutionTimeoutExtendedEvent provides a mock function with given fields: _a0
+func (_m *mockMutableState) ReplicateWorkflowExecutionTimeoutExtendedEvent(_a0 *shared.HistoryEvent) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*shared.HistoryEvent) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResetSnapshot provides a mock function with given fields: _a0, _a1, _a2
 func (_m *mockMutableState) ResetSnapshot(_a0 string, _a1 int64, _a2 int, _a3 []persistence.Task, _a4 []persistence.Task, _a5 []persistence.Task) *persistence.ResetMutableStateRequest {
 	ret := _m.Called(_a0, _a1, _a2, _a3, _a4, _a5)
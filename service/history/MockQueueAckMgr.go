@@ -109,6 +109,21 @@ func (_m *MockQueueAckMgr) getQueueReadLevel() int64 {
 	return r0
 }
 
+// getPendingTaskCount is mock implementation for getPendingTaskCount of QueueAckMgr
+func (_m *MockQueueAckMgr) getPendingTaskCount() int64 {
+	ret := _m.Called()
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(int64)
+		}
+	}
+	return r0
+}
+
 // updateQueueAckLevel is mock implementation for updateQueueAckLevel of QueueAckMgr
 func (_m *MockQueueAckMgr) updateQueueAckLevel() {
 	_m.Called()
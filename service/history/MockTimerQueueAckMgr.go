@@ -120,6 +120,41 @@ func (_m *MockTimerQueueAckMgr) getReadLevel() TimerSequenceID {
 	return r0
 }
 
+func (_m *MockTimerQueueAckMgr) getOldestPendingTask() (TimerSequenceID, bool) {
+	ret := _m.Called()
+
+	var r0 TimerSequenceID
+	if rf, ok := ret.Get(0).(func() TimerSequenceID); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(TimerSequenceID)
+		}
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func() bool); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+	return r0, r1
+}
+
+func (_m *MockTimerQueueAckMgr) getPendingTaskCount() int64 {
+	ret := _m.Called()
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(int64)
+		}
+	}
+	return r0
+}
+
 func (_m *MockTimerQueueAckMgr) updateAckLevel() {
 	_m.Called()
 }
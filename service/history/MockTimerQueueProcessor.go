@@ -61,3 +61,19 @@ func (_m *MockTimerQueueProcessor) LockTaskPrrocessing() {
 func (_m *MockTimerQueueProcessor) UnlockTaskPrrocessing() {
 	_m.Called()
 }
+
+// describeStatus is mock implementation for describeStatus of Processor
+func (_m *MockTimerQueueProcessor) describeStatus() *QueueProcessorStatus {
+	ret := _m.Called()
+
+	var r0 *QueueProcessorStatus
+	if rf, ok := ret.Get(0).(func() *QueueProcessorStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*QueueProcessorStatus)
+		}
+	}
+
+	return r0
+}
@@ -59,3 +59,19 @@ func (_m *MockTransferQueueProcessor) LockTaskPrrocessing() {
 func (_m *MockTransferQueueProcessor) UnlockTaskPrrocessing() {
 	_m.Called()
 }
+
+// describeStatus is mock implementation for describeStatus of Processor
+func (_m *MockTransferQueueProcessor) describeStatus() *QueueProcessorStatus {
+	ret := _m.Called()
+
+	var r0 *QueueProcessorStatus
+	if rf, ok := ret.Get(0).(func() *QueueProcessorStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*QueueProcessorStatus)
+		}
+	}
+
+	return r0
+}
@@ -143,6 +143,40 @@ func (_m *mockWorkflowExecutionContext) getDomainID() string {
 	return r0
 }
 
+func (_m *mockWorkflowExecutionContext) getDecisionHeartbeatCount() int {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+func (_m *mockWorkflowExecutionContext) setDecisionHeartbeatCount(_a0 int) {
+	_m.Called(_a0)
+}
+
+func (_m *mockWorkflowExecutionContext) getEmptyDecisionCount() int {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+func (_m *mockWorkflowExecutionContext) setEmptyDecisionCount(_a0 int) {
+	_m.Called(_a0)
+}
+
 func (_m *mockWorkflowExecutionContext) getExecution() *workflow.WorkflowExecution {
 	ret := _m.Called()
 
@@ -0,0 +1,145 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
+)
+
+const (
+	auditEntriesChanSize = 1000
+
+	// AuditOperationTerminateWorkflowExecution is the audited operation of terminating a workflow
+	AuditOperationTerminateWorkflowExecution = "TerminateWorkflowExecution"
+	// AuditOperationResetWorkflowExecution is the audited operation of resetting a workflow
+	AuditOperationResetWorkflowExecution = "ResetWorkflowExecution"
+	// AuditOperationForceCompleteActivity is the audited operation of force completing an activity
+	AuditOperationForceCompleteActivity = "ForceCompleteActivity"
+	// AuditOperationExtendWorkflowTimeout is the audited operation of extending a workflow timeout
+	AuditOperationExtendWorkflowTimeout = "ExtendWorkflowTimeout"
+	// AuditOperationMarkBinaryBad is the audited operation of marking a binary of a domain as bad, it has no workflow
+	AuditOperationMarkBinaryBad = "MarkBinaryBad"
+)
+
+type (
+	// AuditEntry is the record of an administrative operation on a workflow execution
+	AuditEntry struct {
+		Operation  string
+		DomainID   string
+		WorkflowID string
+		RunID      string
+		// Operator is the admin operator of the request, or the identity on the request when there is none
+		Operator  string
+		Timestamp time.Time
+	}
+
+	// AuditSink receives the audit entries of administrative operations, e.g. to forward them to a SIEM.
+	// Record is called from a single goroutine and never on the path of the audited operation.
+	AuditSink interface {
+		Record(entry *AuditEntry)
+	}
+
+	loggingAuditSink struct {
+		logger log.Logger
+	}
+
+	// asyncAuditSink hands the entries over to the wrapped sink on its own goroutine, entries are dropped
+	// rather than blocking the caller when the wrapped sink falls behind
+	asyncAuditSink struct {
+		sink    AuditSink
+		metrics metrics.Client
+		// internal status indicator
+		status int32
+		// stop signal channel
+		closeChan chan bool
+		// this channel will never close
+		entriesChan chan *AuditEntry
+	}
+)
+
+var _ AuditSink = (*loggingAuditSink)(nil)
+var _ AuditSink = (*asyncAuditSink)(nil)
+
+// NewLoggingAuditSink returns an AuditSink writing the entries to the logger, this is the default sink
+func NewLoggingAuditSink(logger log.Logger) AuditSink {
+	return &loggingAuditSink{logger: logger}
+}
+
+func (s *loggingAuditSink) Record(entry *AuditEntry) {
+	s.logger.Info("Administrative operation audit.",
+		tag.Operation(entry.Operation),
+		tag.Operator(entry.Operator),
+		tag.WorkflowDomainID(entry.DomainID),
+		tag.WorkflowID(entry.WorkflowID),
+		tag.WorkflowRunID(entry.RunID),
+		tag.Timestamp(entry.Timestamp))
+}
+
+func newAsyncAuditSink(sink AuditSink, metrics metrics.Client) *asyncAuditSink {
+	return &asyncAuditSink{
+		sink:        sink,
+		metrics:     metrics,
+		status:      common.DaemonStatusInitialized,
+		closeChan:   make(chan bool),
+		entriesChan: make(chan *AuditEntry, auditEntriesChanSize),
+	}
+}
+
+func (s *asyncAuditSink) Start() {
+	if !atomic.CompareAndSwapInt32(&s.status, common.DaemonStatusInitialized, common.DaemonStatusStarted) {
+		return
+	}
+	go s.dequeueAuditEntries()
+}
+
+func (s *asyncAuditSink) Stop() {
+	if !atomic.CompareAndSwapInt32(&s.status, common.DaemonStatusStarted, common.DaemonStatusStopped) {
+		return
+	}
+	close(s.closeChan)
+}
+
+func (s *asyncAuditSink) Record(entry *AuditEntry) {
+	select {
+	case s.entriesChan <- entry:
+	default:
+		// the wrapped sink is behind, auditing never delays the operation
+		s.metrics.IncCounter(metrics.HistoryAuditSinkScope, metrics.AuditEntryDroppedCounter)
+	}
+}
+
+func (s *asyncAuditSink) dequeueAuditEntries() {
+	for {
+		select {
+		case entry := <-s.entriesChan:
+			s.sink.Record(entry)
+		case <-s.closeChan:
+			// shutdown
+			return
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"github.com/uber/cadence/common/metrics"
+)
+
+type (
+	asyncAuditSinkSuite struct {
+		suite.Suite
+		metrics metrics.Client
+	}
+
+	// channelAuditSink is an AuditSink handing the recorded entries to a channel
+	channelAuditSink struct {
+		entries chan *AuditEntry
+	}
+)
+
+func TestAsyncAuditSinkSuite(t *testing.T) {
+	s := new(asyncAuditSinkSuite)
+	suite.Run(t, s)
+}
+
+func (s *asyncAuditSinkSuite) SetupTest() {
+	s.metrics = metrics.NewClient(tally.NoopScope, metrics.History)
+}
+
+func newChannelAuditSink(size int) *channelAuditSink {
+	return &channelAuditSink{entries: make(chan *AuditEntry, size)}
+}
+
+func (c *channelAuditSink) Record(entry *AuditEntry) {
+	c.entries <- entry
+}
+
+func (s *asyncAuditSinkSuite) TestRecord() {
+	sink := newChannelAuditSink(1)
+	asyncSink := newAsyncAuditSink(sink, s.metrics)
+	asyncSink.Start()
+	defer asyncSink.Stop()
+
+	entry := &AuditEntry{
+		Operation:  AuditOperationTerminateWorkflowExecution,
+		DomainID:   "domain ID",
+		WorkflowID: "workflow ID",
+		RunID:      "run ID",
+		Operator:   "operator",
+		Timestamp:  time.Now(),
+	}
+	asyncSink.Record(entry)
+
+	select {
+	case recorded := <-sink.entries:
+		s.Equal(entry, recorded)
+	case <-time.After(time.Second):
+		s.Fail("audit entry is not recorded")
+	}
+}
+
+func (s *asyncAuditSinkSuite) TestRecord_SinkBehind() {
+	// the wrapped sink never returns, so only the buffered entries are accepted and the rest are dropped
+	sink := newChannelAuditSink(0)
+	asyncSink := newAsyncAuditSink(sink, s.metrics)
+	asyncSink.Start()
+	defer asyncSink.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 2*auditEntriesChanSize; i++ {
+			asyncSink.Record(&AuditEntry{Operation: AuditOperationResetWorkflowExecution})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		s.Fail("recording audit entries blocks")
+	}
+}
@@ -22,6 +22,7 @@ package history
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pborman/uuid"
 	workflow "github.com/uber/cadence/.gen/go/shared"
@@ -35,45 +36,61 @@ import (
 
 type (
 	decisionAttrValidator struct {
-		domainCache      cache.DomainCache
-		maxIDLengthLimit int
+		domainCache                       cache.DomainCache
+		maxIDLengthLimit                  int
+		maxNonRetriableErrorReasonsCount  int
+		maxNonRetriableErrorReasonsLength int
+		cronMinBackoffInterval            time.Duration
+		searchAttributesValidator         *common.VisibilityQueryValidator
 	}
 
 	decisionBlobSizeChecker struct {
 		sizeLimitWarn  int
 		sizeLimitError int
-		completedID    int64
-		mutableState   mutableState
-		metricsClient  metrics.Client
-		logger         log.Logger
+		// activityTypeSizeLimitError overrides sizeLimitError for the input of the activity types it contains
+		activityTypeSizeLimitError map[string]interface{}
+		completedID                int64
+		mutableState               mutableState
+		metricsClient              metrics.Client
+		logger                     log.Logger
 	}
 )
 
 func newDecisionAttrValidator(
 	domainCache cache.DomainCache,
 	maxIDLengthLimit int,
+	maxNonRetriableErrorReasonsCount int,
+	maxNonRetriableErrorReasonsLength int,
+	cronMinBackoffInterval time.Duration,
+	searchAttributesValidator *common.VisibilityQueryValidator,
 ) *decisionAttrValidator {
 	return &decisionAttrValidator{
-		domainCache:      domainCache,
-		maxIDLengthLimit: maxIDLengthLimit,
+		domainCache:                       domainCache,
+		maxIDLengthLimit:                  maxIDLengthLimit,
+		maxNonRetriableErrorReasonsCount:  maxNonRetriableErrorReasonsCount,
+		maxNonRetriableErrorReasonsLength: maxNonRetriableErrorReasonsLength,
+		cronMinBackoffInterval:            cronMinBackoffInterval,
+		searchAttributesValidator:         searchAttributesValidator,
 	}
 }
 
 func newDecisionBlobSizeChecker(
 	sizeLimitWarn int,
 	sizeLimitError int,
+	activityTypeSizeLimitError map[string]interface{},
 	completedID int64,
 	mutableState mutableState,
 	metricsClient metrics.Client,
 	logger log.Logger,
 ) *decisionBlobSizeChecker {
 	return &decisionBlobSizeChecker{
-		sizeLimitWarn:  sizeLimitWarn,
-		sizeLimitError: sizeLimitError,
-		completedID:    completedID,
-		mutableState:   mutableState,
-		metricsClient:  metricsClient,
-		logger:         logger,
+		sizeLimitWarn:              sizeLimitWarn,
+		sizeLimitError:             sizeLimitError,
+		activityTypeSizeLimitError: activityTypeSizeLimitError,
+		completedID:                completedID,
+		mutableState:               mutableState,
+		metricsClient:              metricsClient,
+		logger:                     logger,
 	}
 }
 
@@ -82,11 +99,39 @@ func (c *decisionBlobSizeChecker) failWorkflowIfBlobSizeExceedsLimit(
 	message string,
 ) (bool, error) {
 
+	return c.failWorkflowIfSizeExceedsLimit(blob, c.sizeLimitError, message)
+}
+
+// failWorkflowIfActivityInputSizeExceedsLimit checks the input of a scheduled activity against the size limit of its
+// activity type, falling back to the domain size limit for activity types without an override
+func (c *decisionBlobSizeChecker) failWorkflowIfActivityInputSizeExceedsLimit(
+	activityType string,
+	input []byte,
+	message string,
+) (bool, error) {
+
+	sizeLimitError := c.sizeLimitError
+	switch limit := c.activityTypeSizeLimitError[activityType].(type) {
+	case int:
+		sizeLimitError = limit
+	case float64:
+		// limits read from a JSON config are decoded as float64
+		sizeLimitError = int(limit)
+	}
+	return c.failWorkflowIfSizeExceedsLimit(input, sizeLimitError, message)
+}
+
+func (c *decisionBlobSizeChecker) failWorkflowIfSizeExceedsLimit(
+	blob []byte,
+	sizeLimitError int,
+	message string,
+) (bool, error) {
+
 	executionInfo := c.mutableState.GetExecutionInfo()
 	err := common.CheckEventBlobSizeLimit(
 		len(blob),
 		c.sizeLimitWarn,
-		c.sizeLimitError,
+		sizeLimitError,
 		executionInfo.DomainID,
 		executionInfo.WorkflowID,
 		executionInfo.RunID,
@@ -139,10 +184,22 @@ func (v *decisionAttrValidator) validateActivityScheduleAttributes(
 		return &workflow.BadRequestError{Message: "ActivityType is not set on decision."}
 	}
 
+	if wfTimeout <= 0 {
+		return &workflow.BadRequestError{Message: "Workflow execution is about to time out, cannot schedule activity."}
+	}
+
 	if err := common.ValidateRetryPolicy(attributes.RetryPolicy); err != nil {
 		return err
 	}
 
+	if err := common.ValidateRetryPolicyNonRetriableErrorReasons(
+		attributes.RetryPolicy,
+		v.maxNonRetriableErrorReasonsCount,
+		v.maxNonRetriableErrorReasonsLength,
+	); err != nil {
+		return err
+	}
+
 	if len(attributes.GetActivityId()) > v.maxIDLengthLimit {
 		return &workflow.BadRequestError{Message: "ActivityID exceeds length limit."}
 	}
@@ -155,11 +212,25 @@ func (v *decisionAttrValidator) validateActivityScheduleAttributes(
 		return &workflow.BadRequestError{Message: "Domain exceeds length limit."}
 	}
 
+	if len(attributes.TaskList.GetName()) > v.maxIDLengthLimit {
+		return &workflow.BadRequestError{Message: "TaskList exceeds length limit."}
+	}
+
 	// Only attempt to deduce and fill in unspecified timeouts only when all timeouts are non-negative.
 	if attributes.GetScheduleToCloseTimeoutSeconds() < 0 || attributes.GetScheduleToStartTimeoutSeconds() < 0 ||
 		attributes.GetStartToCloseTimeoutSeconds() < 0 || attributes.GetHeartbeatTimeoutSeconds() < 0 {
 		return &workflow.BadRequestError{Message: "A valid timeout may not be negative."}
 	}
+	if attributes.GetScheduleDelaySeconds() < 0 {
+		return &workflow.BadRequestError{Message: "A valid ScheduleDelaySeconds may not be negative."}
+	}
+
+	if attributes.GetDispatchOnly() {
+		if err := v.validateDispatchOnlyActivityScheduleAttributes(attributes, wfTimeout); err != nil {
+			return err
+		}
+		return validateActivityScheduleDelay(attributes)
+	}
 
 	// ensure activity timeout never larger than workflow timeout
 	if attributes.GetScheduleToCloseTimeoutSeconds() > wfTimeout {
@@ -198,6 +269,13 @@ func (v *decisionAttrValidator) validateActivityScheduleAttributes(
 	// ensure activity's SCHEDULE_TO_START and SCHEDULE_TO_CLOSE is as long as expiration on retry policy
 	p := attributes.RetryPolicy
 	if p != nil {
+		if p.GetExpirationIntervalInSeconds() > 0 && p.GetExpirationIntervalInSeconds() < p.GetInitialIntervalInSeconds() {
+			return &workflow.BadRequestError{Message: "ExpirationIntervalInSeconds cannot be less than InitialIntervalInSeconds on retry policy."}
+		}
+		// retries past the workflow timeout can never happen
+		if p.GetExpirationIntervalInSeconds() > wfTimeout {
+			p.ExpirationIntervalInSeconds = common.Int32Ptr(wfTimeout)
+		}
 		expiration := p.GetExpirationIntervalInSeconds()
 		if expiration == 0 {
 			expiration = wfTimeout
@@ -209,6 +287,50 @@ func (v *decisionAttrValidator) validateActivityScheduleAttributes(
 			attributes.ScheduleToCloseTimeoutSeconds = common.Int32Ptr(expiration)
 		}
 	}
+	return validateActivityScheduleDelay(attributes)
+}
+
+// validateActivityScheduleDelay ensures a delayed activity can still be started once dispatched, as its timeouts
+// count from when it is scheduled rather than from when it is dispatched
+func validateActivityScheduleDelay(
+	attributes *workflow.ScheduleActivityTaskDecisionAttributes,
+) error {
+
+	delay := attributes.GetScheduleDelaySeconds()
+	if delay == 0 {
+		return nil
+	}
+	if delay >= attributes.GetScheduleToStartTimeoutSeconds() {
+		return &workflow.BadRequestError{Message: "ScheduleDelaySeconds must be less than ScheduleToStartTimeout."}
+	}
+	if attributes.GetScheduleToCloseTimeoutSeconds() > 0 && delay >= attributes.GetScheduleToCloseTimeoutSeconds() {
+		return &workflow.BadRequestError{Message: "ScheduleDelaySeconds must be less than ScheduleToCloseTimeout."}
+	}
+	return nil
+}
+
+// validateDispatchOnlyActivityScheduleAttributes only keeps the schedule to start timeout of a dispatch only activity,
+// once started such an activity stays pending until the worker responds or the workflow closes. The start to close
+// timeout is not tracked by the server, it is only the deadline given to the worker and defaults to the workflow timeout
+func (v *decisionAttrValidator) validateDispatchOnlyActivityScheduleAttributes(
+	attributes *workflow.ScheduleActivityTaskDecisionAttributes,
+	wfTimeout int32,
+) error {
+
+	if attributes.RetryPolicy != nil {
+		return &workflow.BadRequestError{Message: "RetryPolicy is not supported on dispatch only activity."}
+	}
+	if attributes.GetScheduleToStartTimeoutSeconds() <= 0 {
+		return &workflow.BadRequestError{Message: "A valid ScheduleToStartTimeout is not set on dispatch only activity."}
+	}
+	if attributes.GetScheduleToStartTimeoutSeconds() > wfTimeout {
+		attributes.ScheduleToStartTimeoutSeconds = common.Int32Ptr(wfTimeout)
+	}
+	if attributes.GetStartToCloseTimeoutSeconds() <= 0 || attributes.GetStartToCloseTimeoutSeconds() > wfTimeout {
+		attributes.StartToCloseTimeoutSeconds = common.Int32Ptr(wfTimeout)
+	}
+	attributes.ScheduleToCloseTimeoutSeconds = common.Int32Ptr(0)
+	attributes.HeartbeatTimeoutSeconds = common.Int32Ptr(0)
 	return nil
 }
 
@@ -280,6 +402,35 @@ func (v *decisionAttrValidator) validateRecordMarkerAttributes(
 	return nil
 }
 
+func (v *decisionAttrValidator) validateUpsertWorkflowMemoAttributes(
+	attributes *workflow.UpsertWorkflowMemoDecisionAttributes,
+	memo map[string][]byte,
+	sizeLimitError int,
+) error {
+
+	if attributes == nil {
+		return &workflow.BadRequestError{Message: "UpsertWorkflowMemoDecisionAttributes is not set on decision."}
+	}
+	if len(attributes.Memo.GetFields()) == 0 {
+		return &workflow.BadRequestError{Message: "Memo is not set on decision."}
+	}
+	// the limit applies to the memo of the workflow after the upserted fields are merged into it
+	mergedMemo := make(map[string][]byte, len(memo)+len(attributes.Memo.GetFields()))
+	for k, v := range memo {
+		mergedMemo[k] = v
+	}
+	for k, v := range attributes.Memo.GetFields() {
+		mergedMemo[k] = v
+	}
+	if size := common.GetSizeOfMapStringToByteArray(mergedMemo); size > sizeLimitError {
+		return &workflow.BadRequestError{
+			Message: fmt.Sprintf("Workflow memo size %v after UpsertWorkflowMemoDecisionAttributes exceeds limit %v.", size, sizeLimitError),
+		}
+	}
+
+	return nil
+}
+
 func (v *decisionAttrValidator) validateCompleteWorkflowExecutionAttributes(
 	attributes *workflow.CompleteWorkflowExecutionDecisionAttributes,
 ) error {
@@ -382,6 +533,9 @@ func (v *decisionAttrValidator) validateSignalExternalWorkflowExecutionAttribute
 	if attributes.SignalName == nil {
 		return &workflow.BadRequestError{Message: "SignalName is not set on decision."}
 	}
+	if err := validateSignalName(attributes.GetSignalName(), v.maxIDLengthLimit); err != nil {
+		return err
+	}
 	if attributes.Input == nil {
 		return &workflow.BadRequestError{Message: "Input is not set on decision."}
 	}
@@ -424,7 +578,11 @@ func (v *decisionAttrValidator) validateContinueAsNewWorkflowExecutionAttributes
 		attributes.TaskStartToCloseTimeoutSeconds = common.Int32Ptr(executionInfo.DecisionTimeoutValue)
 	}
 
-	return nil
+	if err := backoff.ValidateScheduleInterval(attributes.GetCronSchedule(), v.cronMinBackoffInterval); err != nil {
+		return err
+	}
+
+	return v.searchAttributesValidator.ValidateSearchAttributeKeys(attributes.SearchAttributes)
 }
 
 func (v *decisionAttrValidator) validateStartChildExecutionAttributes(
@@ -473,7 +631,11 @@ func (v *decisionAttrValidator) validateStartChildExecutionAttributes(
 		return err
 	}
 
-	if err := backoff.ValidateSchedule(attributes.GetCronSchedule()); err != nil {
+	if err := backoff.ValidateScheduleInterval(attributes.GetCronSchedule(), v.cronMinBackoffInterval); err != nil {
+		return err
+	}
+
+	if err := backoff.ValidateScheduleWithRetryPolicy(attributes.GetCronSchedule(), attributes.RetryPolicy); err != nil {
 		return err
 	}
 
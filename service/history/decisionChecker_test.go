@@ -21,14 +21,18 @@
 package history
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	workflow "github.com/uber/cadence/.gen/go/shared"
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/cluster"
+	"github.com/uber/cadence/common/definition"
 	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/service/dynamicconfig"
 )
 
 type (
@@ -37,8 +41,10 @@ type (
 
 		mockDomainCache *cache.DomainCacheMock
 
-		maxIDLengthLimit int
-		validator        *decisionAttrValidator
+		maxIDLengthLimit                  int
+		maxNonRetriableErrorReasonsCount  int
+		maxNonRetriableErrorReasonsLength int
+		validator                         *decisionAttrValidator
 	}
 )
 
@@ -57,9 +63,15 @@ func (s *decisionAttrValidatorSuite) TearDownSuite() {
 func (s *decisionAttrValidatorSuite) SetupTest() {
 	s.mockDomainCache = &cache.DomainCacheMock{}
 	s.maxIDLengthLimit = 1000
+	s.maxNonRetriableErrorReasonsCount = 2
+	s.maxNonRetriableErrorReasonsLength = 20
 	s.validator = newDecisionAttrValidator(
 		s.mockDomainCache,
 		s.maxIDLengthLimit,
+		s.maxNonRetriableErrorReasonsCount,
+		s.maxNonRetriableErrorReasonsLength,
+		time.Minute,
+		common.NewQueryValidator(dynamicconfig.GetMapPropertyFn(definition.GetDefaultIndexedKeys())),
 	)
 }
 
@@ -105,6 +117,14 @@ func (s *decisionAttrValidatorSuite) TestValidateSignalExternalWorkflowExecution
 	s.EqualError(err, "BadRequestError{Message: Invalid RunId set on decision.}")
 	attributes.Execution.RunId = common.StringPtr(validRunID)
 
+	attributes.SignalName = common.StringPtr("")
+	err = s.validator.validateSignalExternalWorkflowExecutionAttributes(domainID, targetDomainID, attributes)
+	s.EqualError(err, "BadRequestError{Message: Missing SignalName.}")
+
+	attributes.SignalName = common.StringPtr(strings.Repeat("s", s.maxIDLengthLimit+1))
+	err = s.validator.validateSignalExternalWorkflowExecutionAttributes(domainID, targetDomainID, attributes)
+	s.EqualError(err, "BadRequestError{Message: SignalName exceeds length limit.}")
+
 	attributes.SignalName = common.StringPtr("my signal name")
 	err = s.validator.validateSignalExternalWorkflowExecutionAttributes(domainID, targetDomainID, attributes)
 	s.EqualError(err, "BadRequestError{Message: Input is not set on decision.}")
@@ -114,6 +134,235 @@ func (s *decisionAttrValidatorSuite) TestValidateSignalExternalWorkflowExecution
 	s.Nil(err)
 }
 
+func (s *decisionAttrValidatorSuite) TestValidateActivityScheduleAttributes_NonRetriableErrorReasons() {
+	domainID := "some random domain ID"
+	newAttributes := func(reasons ...string) *workflow.ScheduleActivityTaskDecisionAttributes {
+		return &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr("some random activity ID"),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("some random activity type")},
+			TaskList:                      &workflow.TaskList{Name: common.StringPtr("some random task list")},
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(10),
+			RetryPolicy: &workflow.RetryPolicy{
+				InitialIntervalInSeconds: common.Int32Ptr(1),
+				BackoffCoefficient:       common.Float64Ptr(2),
+				MaximumAttempts:          common.Int32Ptr(3),
+				NonRetriableErrorReasons: reasons,
+			},
+		}
+	}
+
+	err := s.validator.validateActivityScheduleAttributes(domainID, domainID, newAttributes("reason 1", "reason 2"), 100)
+	s.Nil(err)
+
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, newAttributes("1", "2", "3"), 100)
+	s.IsType(&workflow.BadRequestError{}, err)
+
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, newAttributes("some long reason 1", "reason 2"), 100)
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *decisionAttrValidatorSuite) TestValidateActivityScheduleAttributes_WorkflowTimeout() {
+	domainID := "some random domain ID"
+	newAttributes := func() *workflow.ScheduleActivityTaskDecisionAttributes {
+		return &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr("some random activity ID"),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("some random activity type")},
+			TaskList:                      &workflow.TaskList{Name: common.StringPtr("some random task list")},
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
+		}
+	}
+
+	attributes := newAttributes()
+	err := s.validator.validateActivityScheduleAttributes(domainID, domainID, attributes, 10)
+	s.Nil(err)
+	s.Equal(int32(10), attributes.GetScheduleToCloseTimeoutSeconds())
+
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, newAttributes(), 0)
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *decisionAttrValidatorSuite) TestValidateActivityScheduleAttributes_TaskListLength() {
+	domainID := "some random domain ID"
+	newAttributes := func(taskList string) *workflow.ScheduleActivityTaskDecisionAttributes {
+		return &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr("some random activity ID"),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("some random activity type")},
+			TaskList:                      &workflow.TaskList{Name: common.StringPtr(taskList)},
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(10),
+		}
+	}
+
+	err := s.validator.validateActivityScheduleAttributes(domainID, domainID, newAttributes(strings.Repeat("t", s.maxIDLengthLimit)), 100)
+	s.Nil(err)
+
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, newAttributes(strings.Repeat("t", s.maxIDLengthLimit+1)), 100)
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *decisionAttrValidatorSuite) TestValidateActivityScheduleAttributes_RetryExpiration() {
+	domainID := "some random domain ID"
+	newAttributes := func(initialInterval, expiration int32) *workflow.ScheduleActivityTaskDecisionAttributes {
+		return &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr("some random activity ID"),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("some random activity type")},
+			TaskList:                      &workflow.TaskList{Name: common.StringPtr("some random task list")},
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(10),
+			RetryPolicy: &workflow.RetryPolicy{
+				InitialIntervalInSeconds:    common.Int32Ptr(initialInterval),
+				BackoffCoefficient:          common.Float64Ptr(2),
+				ExpirationIntervalInSeconds: common.Int32Ptr(expiration),
+			},
+		}
+	}
+
+	attributes := newAttributes(1, 50)
+	err := s.validator.validateActivityScheduleAttributes(domainID, domainID, attributes, 100)
+	s.Nil(err)
+	s.Equal(int32(50), attributes.RetryPolicy.GetExpirationIntervalInSeconds())
+	s.Equal(int32(50), attributes.GetScheduleToCloseTimeoutSeconds())
+
+	attributes = newAttributes(1, 500)
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, attributes, 100)
+	s.Nil(err)
+	s.Equal(int32(100), attributes.RetryPolicy.GetExpirationIntervalInSeconds())
+	s.Equal(int32(100), attributes.GetScheduleToCloseTimeoutSeconds())
+	s.Equal(int32(100), attributes.GetScheduleToStartTimeoutSeconds())
+
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, newAttributes(20, 10), 100)
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *decisionAttrValidatorSuite) TestValidateActivityScheduleAttributes_DispatchOnly() {
+	domainID := "some random domain ID"
+	newAttributes := func(scheduleToStart int32) *workflow.ScheduleActivityTaskDecisionAttributes {
+		return &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr("some random activity ID"),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("some random activity type")},
+			TaskList:                      &workflow.TaskList{Name: common.StringPtr("some random task list")},
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(50),
+			ScheduleToStartTimeoutSeconds: common.Int32Ptr(scheduleToStart),
+			StartToCloseTimeoutSeconds:    common.Int32Ptr(40),
+			HeartbeatTimeoutSeconds:       common.Int32Ptr(5),
+			DispatchOnly:                  common.BoolPtr(true),
+		}
+	}
+
+	attributes := newAttributes(10)
+	err := s.validator.validateActivityScheduleAttributes(domainID, domainID, attributes, 100)
+	s.Nil(err)
+	s.Equal(int32(10), attributes.GetScheduleToStartTimeoutSeconds())
+	s.Equal(int32(0), attributes.GetScheduleToCloseTimeoutSeconds())
+	s.Equal(int32(40), attributes.GetStartToCloseTimeoutSeconds())
+	s.Equal(int32(0), attributes.GetHeartbeatTimeoutSeconds())
+
+	// the start to close timeout defaults to the workflow timeout
+	attributes = newAttributes(10)
+	attributes.StartToCloseTimeoutSeconds = nil
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, attributes, 100)
+	s.Nil(err)
+	s.Equal(int32(100), attributes.GetStartToCloseTimeoutSeconds())
+
+	attributes = newAttributes(10)
+	attributes.StartToCloseTimeoutSeconds = common.Int32Ptr(500)
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, attributes, 100)
+	s.Nil(err)
+	s.Equal(int32(100), attributes.GetStartToCloseTimeoutSeconds())
+
+	attributes = newAttributes(500)
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, attributes, 100)
+	s.Nil(err)
+	s.Equal(int32(100), attributes.GetScheduleToStartTimeoutSeconds())
+
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, newAttributes(0), 100)
+	s.IsType(&workflow.BadRequestError{}, err)
+
+	attributes = newAttributes(10)
+	attributes.RetryPolicy = &workflow.RetryPolicy{
+		InitialIntervalInSeconds: common.Int32Ptr(1),
+		BackoffCoefficient:       common.Float64Ptr(2),
+	}
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, attributes, 100)
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *decisionAttrValidatorSuite) TestValidateContinueAsNewWorkflowExecutionAttributes_CronInterval() {
+	executionInfo := &persistence.WorkflowExecutionInfo{
+		WorkflowTypeName:     "some random workflow type",
+		TaskList:             "some random task list",
+		WorkflowTimeout:      100,
+		DecisionTimeoutValue: 10,
+	}
+
+	attributes := &workflow.ContinueAsNewWorkflowExecutionDecisionAttributes{
+		CronSchedule: common.StringPtr("@every 10s"),
+	}
+	err := s.validator.validateContinueAsNewWorkflowExecutionAttributes(attributes, executionInfo)
+	s.IsType(&workflow.BadRequestError{}, err)
+
+	attributes.CronSchedule = common.StringPtr("@every 1h")
+	err = s.validator.validateContinueAsNewWorkflowExecutionAttributes(attributes, executionInfo)
+	s.Nil(err)
+}
+
+func (s *decisionAttrValidatorSuite) TestValidateContinueAsNewWorkflowExecutionAttributes_SearchAttributes() {
+	executionInfo := &persistence.WorkflowExecutionInfo{
+		WorkflowTypeName:     "some random workflow type",
+		TaskList:             "some random task list",
+		WorkflowTimeout:      100,
+		DecisionTimeoutValue: 10,
+	}
+
+	attributes := &workflow.ContinueAsNewWorkflowExecutionDecisionAttributes{
+		SearchAttributes: &workflow.SearchAttributes{
+			IndexedFields: map[string][]byte{
+				definition.CustomKeywordField: []byte(`"keyword"`),
+				"some random unknown key":     []byte(`"value"`),
+			},
+		},
+	}
+	err := s.validator.validateContinueAsNewWorkflowExecutionAttributes(attributes, executionInfo)
+	s.IsType(&workflow.BadRequestError{}, err)
+	s.Equal("Unknown search attribute keys: some random unknown key.", err.(*workflow.BadRequestError).Message)
+
+	delete(attributes.SearchAttributes.IndexedFields, "some random unknown key")
+	err = s.validator.validateContinueAsNewWorkflowExecutionAttributes(attributes, executionInfo)
+	s.Nil(err)
+}
+
+func (s *decisionAttrValidatorSuite) TestValidateActivityScheduleAttributes_ScheduleDelay() {
+	domainID := "some random domain ID"
+	newAttributes := func(delay int32) *workflow.ScheduleActivityTaskDecisionAttributes {
+		return &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr("some random activity ID"),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("some random activity type")},
+			TaskList:                      &workflow.TaskList{Name: common.StringPtr("some random task list")},
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(50),
+			ScheduleToStartTimeoutSeconds: common.Int32Ptr(20),
+			StartToCloseTimeoutSeconds:    common.Int32Ptr(30),
+			ScheduleDelaySeconds:          common.Int32Ptr(delay),
+		}
+	}
+
+	s.Nil(s.validator.validateActivityScheduleAttributes(domainID, domainID, newAttributes(0), 100))
+	s.Nil(s.validator.validateActivityScheduleAttributes(domainID, domainID, newAttributes(19), 100))
+
+	err := s.validator.validateActivityScheduleAttributes(domainID, domainID, newAttributes(-1), 100)
+	s.IsType(&workflow.BadRequestError{}, err)
+	// the activity would time out before it is dispatched
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, newAttributes(20), 100)
+	s.IsType(&workflow.BadRequestError{}, err)
+
+	attributes := newAttributes(15)
+	attributes.ScheduleToStartTimeoutSeconds = common.Int32Ptr(60)
+	attributes.ScheduleToCloseTimeoutSeconds = common.Int32Ptr(10)
+	err = s.validator.validateActivityScheduleAttributes(domainID, domainID, attributes, 100)
+	s.IsType(&workflow.BadRequestError{}, err)
+
+	attributes = newAttributes(15)
+	attributes.DispatchOnly = common.BoolPtr(true)
+	s.Nil(s.validator.validateActivityScheduleAttributes(domainID, domainID, attributes, 100))
+}
+
 func (s *decisionAttrValidatorSuite) TestValidateCrossDomainCall_LocalToLocal() {
 	domainID := "some random domain ID"
 	targetDomainID := "some random target domain ID"
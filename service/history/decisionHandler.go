@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/pborman/uuid"
 	h "github.com/uber/cadence/.gen/go/history"
 	workflow "github.com/uber/cadence/.gen/go/shared"
 	"github.com/uber/cadence/common"
@@ -33,6 +34,7 @@ import (
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/metrics"
 	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/service/dynamicconfig"
 	"go.uber.org/yarpc"
 )
 
@@ -46,6 +48,7 @@ type (
 			*h.RespondDecisionTaskFailedRequest) error
 		handleDecisionTaskCompleted(ctx.Context,
 			*h.RespondDecisionTaskCompletedRequest) (*h.RespondDecisionTaskCompletedResponse, error)
+		handleDecisionTaskReplay(ctx.Context, string, workflow.WorkflowExecution, int64) (*DecisionReplayResult, error)
 		// TODO also include the handle of decision timeout here
 	}
 
@@ -98,7 +101,7 @@ func (handler *decisionHandlerImpl) handleDecisionTaskScheduled(
 		RunId:      req.WorkflowExecution.RunId,
 	}
 
-	return handler.historyEngine.updateWorkflowExecutionWithAction(ctx, domainID, execution,
+	return handler.historyEngine.updateWorkflowExecutionWithAction(ctx, metrics.HistoryScheduleDecisionTaskScope, domainID, execution,
 		func(msBuilder mutableState, tBuilder *timerBuilder) (*updateWorkflowAction, error) {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return nil, ErrWorkflowCompleted
@@ -146,8 +149,11 @@ func (handler *decisionHandlerImpl) handleDecisionTaskStarted(
 	requestID := req.GetRequestId()
 
 	var resp *h.RecordDecisionTaskStartedResponse
-	err = handler.historyEngine.updateWorkflowExecutionWithAction(ctx, domainID, execution,
+	var firstDecisionLatency time.Duration
+	var taskList string
+	err = handler.historyEngine.updateWorkflowExecutionWithAction(ctx, metrics.HistoryRecordDecisionTaskStartedScope, domainID, execution,
 		func(msBuilder mutableState, tBuilder *timerBuilder) (*updateWorkflowAction, error) {
+			firstDecisionLatency = 0
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return nil, ErrWorkflowCompleted
 			}
@@ -182,7 +188,8 @@ func (handler *decisionHandlerImpl) handleDecisionTaskStarted(
 			if di.StartedID != common.EmptyEventID {
 				// If decision is started as part of the current request scope then return a positive response
 				if di.RequestID == requestID {
-					resp = handler.createRecordDecisionTaskStartedResponse(domainID, msBuilder, di, req.PollRequest.GetIdentity())
+					resp = handler.createRecordDecisionTaskStartedResponse(domainID, msBuilder, di,
+						req.PollRequest.TaskList.GetName(), req.PollRequest.GetIdentity())
 					updateAction.noop = true
 					return updateAction, nil
 				}
@@ -192,13 +199,21 @@ func (handler *decisionHandlerImpl) handleDecisionTaskStarted(
 				return nil, &h.EventAlreadyStartedError{Message: "Decision task already started."}
 			}
 
+			isFirstDecision := msBuilder.GetPreviousStartedEventID() == common.EmptyEventID && di.Attempt == 0
 			_, di, err = msBuilder.AddDecisionTaskStartedEvent(scheduleID, requestID, req.PollRequest)
 			if err != nil {
 				// Unable to add DecisionTaskStarted event to history
 				return nil, &workflow.InternalServiceError{Message: "Unable to add DecisionTaskStarted event to history."}
 			}
 
-			resp = handler.createRecordDecisionTaskStartedResponse(domainID, msBuilder, di, req.PollRequest.GetIdentity())
+			executionInfo := msBuilder.GetExecutionInfo()
+			if isFirstDecision && !executionInfo.StartTimestamp.IsZero() {
+				firstDecisionLatency = handler.shard.GetTimeSource().Now().Sub(executionInfo.StartTimestamp)
+				taskList = executionInfo.TaskList
+			}
+
+			resp = handler.createRecordDecisionTaskStartedResponse(domainID, msBuilder, di,
+				req.PollRequest.TaskList.GetName(), req.PollRequest.GetIdentity())
 			updateAction.timerTasks = []persistence.Task{tBuilder.AddStartToCloseDecisionTimoutTask(
 				di.ScheduleID,
 				di.Attempt,
@@ -210,6 +225,14 @@ func (handler *decisionHandlerImpl) handleDecisionTaskStarted(
 	if err != nil {
 		return nil, err
 	}
+
+	if firstDecisionLatency > 0 {
+		handler.metricsClient.Scope(
+			metrics.HistoryRecordDecisionTaskStartedScope,
+			metrics.DomainTag(domainEntry.GetInfo().Name),
+			metrics.TaskListTag(taskList),
+		).RecordTimer(metrics.WorkflowStartToFirstDecisionLatency, firstDecisionLatency)
+	}
 	return resp, nil
 }
 
@@ -235,8 +258,8 @@ func (handler *decisionHandlerImpl) handleDecisionTaskFailed(
 		RunId:      common.StringPtr(token.RunID),
 	}
 
-	return handler.historyEngine.updateWorkflowExecution(ctx, domainID, workflowExecution, false, true,
-		func(msBuilder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error) {
+	return handler.historyEngine.updateWorkflowExecutionWithAction(ctx, metrics.HistoryRespondDecisionTaskFailedScope, domainID, workflowExecution,
+		func(msBuilder mutableState, tBuilder *timerBuilder) (*updateWorkflowAction, error) {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return nil, ErrWorkflowCompleted
 			}
@@ -247,9 +270,21 @@ func (handler *decisionHandlerImpl) handleDecisionTaskFailed(
 				return nil, &workflow.EntityNotExistsError{Message: "Decision task not found."}
 			}
 
-			_, err := msBuilder.AddDecisionTaskFailedEvent(di.ScheduleID, di.StartedID, request.GetCause(), request.Details,
-				request.GetIdentity(), "", "", "", 0)
-			return nil, err
+			if _, err := msBuilder.AddDecisionTaskFailedEvent(di.ScheduleID, di.StartedID, request.GetCause(), request.Details,
+				request.GetIdentity(), "", "", "", 0); err != nil {
+				return nil, err
+			}
+
+			terminated, err := handler.historyEngine.terminateIfDecisionAttemptsExceedLimit(
+				msBuilder, token.ScheduleAttempt, metrics.HistoryRespondDecisionTaskFailedScope,
+			)
+			if err != nil {
+				return nil, err
+			}
+			if terminated {
+				return &updateWorkflowAction{deleteWorkflow: true}, nil
+			}
+			return &updateWorkflowAction{createDecision: true}, nil
 		})
 }
 
@@ -274,11 +309,28 @@ func (handler *decisionHandlerImpl) handleDecisionTaskCompleted(
 		return nil, ErrDeserializingToken
 	}
 
+	// the sticky task list of the worker is where the following decisions are scheduled
+	if len(request.StickyAttributes.GetWorkerTaskList().GetName()) > handler.config.MaxIDLengthLimit() {
+		return nil, &workflow.BadRequestError{Message: "StickyAttributes.WorkerTaskList exceeds length limit."}
+	}
+
 	workflowExecution := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr(token.WorkflowID),
 		RunId:      common.StringPtr(token.RunID),
 	}
 
+	// an oversized execution context fails the decision up front rather than the whole update later on
+	executionContextSizeErr := common.CheckEventBlobSizeLimit(
+		len(request.ExecutionContext),
+		handler.config.BlobSizeLimitWarn(domainEntry.GetInfo().Name),
+		handler.config.BlobSizeLimitError(domainEntry.GetInfo().Name),
+		domainID,
+		token.WorkflowID,
+		token.RunID,
+		handler.metricsClient.Scope(metrics.HistoryRespondDecisionTaskCompletedScope, metrics.DomainTag(domainEntry.GetInfo().Name)),
+		handler.throttledLogger,
+	)
+
 	call := yarpc.CallFromContext(ctx)
 	clientLibVersion := call.Header(common.LibraryVersionHeaderName)
 	clientFeatureVersion := call.Header(common.FeatureVersionHeaderName)
@@ -301,6 +353,11 @@ Update_History_Loop:
 		}
 
 		executionInfo := msBuilder.GetExecutionInfo()
+		executionContext := request.ExecutionContext
+		if executionContextSizeErr != nil {
+			// keep the execution context of the last successfully completed decision
+			executionContext = executionInfo.ExecutionContext
+		}
 		timerBuilderProvider := func() *timerBuilder {
 			return handler.historyEngine.getTimerBuilder(context.getExecution())
 		}
@@ -312,6 +369,7 @@ Update_History_Loop:
 		// some extreme cassandra failure cases.
 		if !isRunning && scheduleID >= msBuilder.GetNextEventID() {
 			handler.metricsClient.IncCounter(metrics.HistoryRespondDecisionTaskCompletedScope, metrics.StaleMutableStateCounter)
+			emitUpdateFailure(handler.metricsClient, metrics.HistoryRespondDecisionTaskCompletedScope, updateFailureCauseStaleState)
 			// Reload workflow execution history
 			context.clear()
 			continue Update_History_Loop
@@ -323,8 +381,13 @@ Update_History_Loop:
 		}
 
 		startedID := di.StartedID
-		maxResetPoints := handler.config.MaxAutoResetPoints(domainEntry.GetInfo().Name)
-		if msBuilder.GetExecutionInfo().AutoResetPoints != nil && maxResetPoints == len(msBuilder.GetExecutionInfo().AutoResetPoints.Points) {
+		// a max of 0 reset points tells mutable state to skip recording them
+		maxResetPoints := 0
+		if handler.config.EnableAutoResetPoints(domainEntry.GetInfo().Name) {
+			maxResetPoints = handler.config.MaxAutoResetPoints(domainEntry.GetInfo().Name)
+		}
+		if maxResetPoints > 0 && msBuilder.GetExecutionInfo().AutoResetPoints != nil &&
+			maxResetPoints == len(msBuilder.GetExecutionInfo().AutoResetPoints.Points) {
 			handler.metricsClient.IncCounter(metrics.HistoryRespondDecisionTaskCompletedScope, metrics.AutoResetPointsLimitExceededCounter)
 			handler.throttledLogger.Warn("the number of auto-reset points is exceeding the limit, will do rotating.",
 				tag.WorkflowDomainName(domainEntry.GetInfo().Name),
@@ -333,6 +396,7 @@ Update_History_Loop:
 				tag.WorkflowRunID(workflowExecution.GetRunId()),
 				tag.Number(int64(maxResetPoints)))
 		}
+		nextEventIDBeforeDecision := msBuilder.GetNextEventID()
 		completedEvent, err := msBuilder.AddDecisionTaskCompletedEvent(scheduleID, startedID, request, maxResetPoints)
 		if err != nil {
 			return nil, &workflow.InternalServiceError{Message: "Unable to add DecisionTaskCompleted event to history."}
@@ -344,7 +408,9 @@ Update_History_Loop:
 			failMessage                 string
 			isComplete                  bool
 			activityNotStartedCancelled bool
-			transferTasks               []persistence.Task
+			decisionTransferTasks       []persistence.Task
+			newDecisionTransferTasks    []persistence.Task
+			closeTransferTasks          []persistence.Task
 			timerTasks                  []persistence.Task
 			continueAsNewBuilder        mutableState
 			continueAsNewTimerTasks     []persistence.Task
@@ -353,12 +419,21 @@ Update_History_Loop:
 			hasUnhandledEvents bool
 		)
 		tBuilder = timerBuilderProvider()
-		hasUnhandledEvents = msBuilder.HasBufferedEvents()
+		hasUnhandledEvents = msBuilder.HasUnhandledBufferedEvents()
 
+		var resetStickyAttributes *workflow.StickyExecutionAttributes
 		if request.StickyAttributes == nil || request.StickyAttributes.WorkerTaskList == nil {
 			handler.metricsClient.IncCounter(metrics.HistoryRespondDecisionTaskCompletedScope, metrics.CompleteDecisionWithStickyDisabledCounter)
 			executionInfo.StickyTaskList = ""
 			executionInfo.StickyScheduleToStartTimeout = 0
+		} else if request.GetResetStickyForNextDecision() {
+			// the worker keeps sticky enabled, only the next decision is scheduled on the normal task list and the
+			// sticky attributes are restored once it is scheduled. If no decision is scheduled by this completion,
+			// the sticky attributes of the completion of the next decision enable sticky again
+			handler.metricsClient.IncCounter(metrics.HistoryRespondDecisionTaskCompletedScope, metrics.CompleteDecisionWithStickyResetCounter)
+			executionInfo.StickyTaskList = ""
+			executionInfo.StickyScheduleToStartTimeout = 0
+			resetStickyAttributes = request.StickyAttributes
 		} else {
 			handler.metricsClient.IncCounter(metrics.HistoryRespondDecisionTaskCompletedScope, metrics.CompleteDecisionWithStickyEnabledCounter)
 			executionInfo.StickyTaskList = request.StickyAttributes.WorkerTaskList.GetName()
@@ -373,32 +448,19 @@ Update_History_Loop:
 			failDecision = true
 			failCause = workflow.DecisionTaskFailedCauseBadBinary
 			failMessage = fmt.Sprintf("binary %v is already marked as bad deployment", binChecksum)
+		} else if executionContextSizeErr != nil {
+			failDecision = true
+			failCause = workflow.DecisionTaskFailedCauseBadExecutionContextSize
+			failMessage = fmt.Sprintf("execution context size %v exceeds the limit", len(request.ExecutionContext))
 		} else {
 
-			decisionAttrValidator := newDecisionAttrValidator(
-				handler.domainCache,
-				handler.config.MaxIDLengthLimit(),
-			)
-			decisionBlobSizeChecker := newDecisionBlobSizeChecker(
-				handler.config.BlobSizeLimitWarn(domainEntry.GetInfo().Name),
-				handler.config.BlobSizeLimitError(domainEntry.GetInfo().Name),
-				completedEvent.GetEventId(),
-				msBuilder,
-				handler.metricsClient,
-				handler.throttledLogger,
-			)
-
-			decisionTaskHandler := newDecisionTaskHandler(
+			decisionTaskHandler := handler.createDecisionTaskHandler(
 				request.GetIdentity(),
 				completedEvent.GetEventId(),
 				eventStoreVersion,
 				domainEntry,
 				msBuilder,
-				decisionAttrValidator,
-				decisionBlobSizeChecker,
-				handler.logger,
 				timerBuilderProvider,
-				handler.domainCache,
 				handler.metricsClient,
 			)
 
@@ -420,7 +482,7 @@ Update_History_Loop:
 			activityNotStartedCancelled = decisionTaskHandler.activityNotStartedCancelled
 			// continueAsNewTimerTasks is not used by decisionTaskHandler
 
-			transferTasks = append(transferTasks, decisionTaskHandler.transferTasks...)
+			decisionTransferTasks = append(decisionTransferTasks, decisionTaskHandler.transferTasks...)
 			timerTasks = append(timerTasks, decisionTaskHandler.timerTasks...)
 
 			continueAsNewBuilder = decisionTaskHandler.continueAsNewBuilder
@@ -440,11 +502,61 @@ Update_History_Loop:
 				return nil, err
 			}
 			tBuilder = handler.historyEngine.getTimerBuilder(context.getExecution())
-			isComplete = false
-			hasUnhandledEvents = true
+			// failDecision terminates the workflow once the decision attempts exceed the limit
+			isComplete = !msBuilder.IsWorkflowExecutionRunning()
+			hasUnhandledEvents = !isComplete
 			continueAsNewBuilder = nil
 		}
 
+		// a decision which adds no events but forces a new decision makes no progress, workers use it to
+		// heartbeat a long decision, e.g. while running local activities, and every such heartbeat is counted
+		// for describe. A decider doing so over and over again keeps the workflow busy forever though, so the
+		// ones completed within the min interval are counted separately and fail the workflow at the limit.
+		isDecisionHeartbeat := !isComplete && !hasUnhandledEvents && !activityNotStartedCancelled &&
+			request.GetForceCreateNewDecisionTask() && msBuilder.GetNextEventID() == completedEvent.GetEventId()+1
+		decisionHeartbeatCount := 0
+		if isDecisionHeartbeat {
+			handler.metricsClient.IncCounter(metrics.HistoryRespondDecisionTaskCompletedScope, metrics.DecisionHeartbeatCounter)
+			decisionHeartbeatCount = context.getDecisionHeartbeatCount() + 1
+		}
+		emptyDecisionCount := 0
+		if isDecisionHeartbeat && handler.shard.GetTimeSource().Now().Sub(time.Unix(0, di.StartedTimestamp)) <
+			handler.config.EmptyDecisionLoopMinInterval(domainEntry.GetInfo().Name) {
+			handler.metricsClient.IncCounter(metrics.HistoryRespondDecisionTaskCompletedScope, metrics.EmptyDecisionLoopCounter)
+			emptyDecisionCount = context.getEmptyDecisionCount() + 1
+			emptyDecisionLoopLimit := handler.config.EmptyDecisionLoopLimit(domainEntry.GetInfo().Name)
+			if emptyDecisionLoopLimit > 0 && emptyDecisionCount >= emptyDecisionLoopLimit {
+				handler.logger.Warn("Failing the workflow stuck in an empty decision loop.",
+					tag.WorkflowID(token.WorkflowID),
+					tag.WorkflowRunID(token.RunID),
+					tag.WorkflowDomainID(domainID),
+					tag.Number(int64(emptyDecisionCount)))
+				attributes := &workflow.FailWorkflowExecutionDecisionAttributes{
+					Reason: common.StringPtr(common.FailureReasonEmptyDecisionLoop),
+					Details: []byte(fmt.Sprintf("%v consecutive decisions added no events but forced a new decision",
+						emptyDecisionCount)),
+				}
+				if _, err := msBuilder.AddFailWorkflowEvent(completedEvent.GetEventId(), attributes); err != nil {
+					return nil, &workflow.InternalServiceError{Message: "Unable to add fail workflow event."}
+				}
+				isComplete = true
+				emptyDecisionCount = 0
+				decisionHeartbeatCount = 0
+			}
+		}
+
+		if !isComplete {
+			terminated, err := handler.historyEngine.terminateIfHistoryLengthExceedsLimit(
+				msBuilder, metrics.HistoryRespondDecisionTaskCompletedScope)
+			if err != nil {
+				return nil, err
+			}
+			if terminated {
+				isComplete = true
+				hasUnhandledEvents = false
+			}
+		}
+
 		if tt := tBuilder.GetUserTimerTaskIfNeeded(msBuilder); tt != nil {
 			timerTasks = append(timerTasks, tt)
 		}
@@ -456,15 +568,17 @@ Update_History_Loop:
 		createNewDecisionTask := !isComplete && (hasUnhandledEvents ||
 			request.GetForceCreateNewDecisionTask() || activityNotStartedCancelled)
 		var newDecisionTaskScheduledID int64
+		var newDecisionTaskList string
 		if createNewDecisionTask {
 			di, err := msBuilder.AddDecisionTaskScheduledEvent()
 			if err != nil {
 				return nil, &workflow.InternalServiceError{Message: "Failed to add decision scheduled event."}
 			}
 			newDecisionTaskScheduledID = di.ScheduleID
+			newDecisionTaskList = di.TaskList
 			// skip transfer task for decision if request asking to return new decision task
 			if !request.GetReturnNewDecisionTask() {
-				transferTasks = append(transferTasks, &persistence.DecisionTask{
+				newDecisionTransferTasks = append(newDecisionTransferTasks, &persistence.DecisionTask{
 					DomainID:   domainID,
 					TaskList:   di.TaskList,
 					ScheduleID: di.ScheduleID,
@@ -476,9 +590,8 @@ Update_History_Loop:
 					timerTasks = append(timerTasks, stickyTaskTimeoutTimer)
 				}
 			} else {
-				// start the new decision task if request asked to do so
-				// TODO: replace the poll request
-				_, _, err := msBuilder.AddDecisionTaskStartedEvent(di.ScheduleID, "request-from-RespondDecisionTaskCompleted", &workflow.PollForDecisionTaskRequest{
+				// start the new decision task if request asked to do so, on behalf of the worker completing this one
+				_, _, err := msBuilder.AddDecisionTaskStartedEvent(di.ScheduleID, uuid.New(), &workflow.PollForDecisionTaskRequest{
 					TaskList: &workflow.TaskList{Name: common.StringPtr(di.TaskList)},
 					Identity: request.Identity,
 				})
@@ -488,6 +601,11 @@ Update_History_Loop:
 				timeOutTask := tBuilder.AddStartToCloseDecisionTimoutTask(di.ScheduleID, di.Attempt, di.DecisionTimeout)
 				timerTasks = append(timerTasks, timeOutTask)
 			}
+
+			if resetStickyAttributes != nil {
+				executionInfo.StickyTaskList = resetStickyAttributes.WorkerTaskList.GetName()
+				executionInfo.StickyScheduleToStartTimeout = resetStickyAttributes.GetScheduleToStartTimeoutSeconds()
+			}
 		}
 
 		if isComplete {
@@ -498,10 +616,23 @@ Update_History_Loop:
 			if err != nil {
 				return nil, err
 			}
-			transferTasks = append(transferTasks, tranT)
+			closeTransferTasks = append(closeTransferTasks, tranT)
 			timerTasks = append(timerTasks, timerT)
 		}
 
+		// transfer tasks are committed in a well defined order: the tasks generated by the decisions in the order
+		// the decisions were made, followed by the task for the new decision and finally the close execution task
+		transferTasks := make([]persistence.Task, 0, len(decisionTransferTasks)+len(newDecisionTransferTasks)+len(closeTransferTasks))
+		transferTasks = append(transferTasks, decisionTransferTasks...)
+		transferTasks = append(transferTasks, newDecisionTransferTasks...)
+		transferTasks = append(transferTasks, closeTransferTasks...)
+
+		// the domain could be deleted or failed over while the decision was being processed,
+		// make sure it is still there and active before committing the update
+		if err := handler.checkDomainActive(req.DomainUUID); err != nil {
+			return nil, err
+		}
+
 		// Generate a transaction ID for appending events to history
 		transactionID, err := handler.shard.GetNextTransferTaskID()
 		if err != nil {
@@ -513,22 +644,24 @@ Update_History_Loop:
 		var updateErr error
 		if continueAsNewBuilder != nil {
 			continueAsNewTimerTasks = msBuilder.GetContinueAsNew().TimerTasks
-			updateErr = context.continueAsNewWorkflowExecution(request.ExecutionContext, continueAsNewBuilder,
+			updateErr = context.continueAsNewWorkflowExecution(executionContext, continueAsNewBuilder,
 				transferTasks, timerTasks, transactionID)
 		} else {
-			updateErr = context.updateWorkflowExecutionWithContext(request.ExecutionContext, transferTasks, timerTasks,
+			updateErr = context.updateWorkflowExecutionWithContext(executionContext, transferTasks, timerTasks,
 				transactionID)
 		}
 
 		if updateErr != nil {
 			if updateErr == ErrConflict {
 				handler.metricsClient.IncCounter(metrics.HistoryRespondDecisionTaskCompletedScope, metrics.ConcurrencyUpdateFailureCounter)
+				emitUpdateFailure(handler.metricsClient, metrics.HistoryRespondDecisionTaskCompletedScope, updateFailureCauseConflict)
 				continue Update_History_Loop
 			}
 
 			// if updateErr resulted in TransactionSizeLimitError then fail workflow
 			switch updateErr.(type) {
 			case *persistence.TransactionSizeLimitError:
+				emitUpdateFailure(handler.metricsClient, metrics.HistoryRespondDecisionTaskCompletedScope, updateFailureCauseTransactionSize)
 				// must reload mutable state because the first call to updateWorkflowExecutionWithContext or continueAsNewWorkflowExecution
 				// clears mutable state if error is returned
 				msBuilder, err = context.loadWorkflowExecution()
@@ -554,7 +687,7 @@ Update_History_Loop:
 				if err != nil {
 					return nil, err
 				}
-				if err := context.updateWorkflowExecutionWithContext(request.ExecutionContext, transferTasks, timerTasks, transactionID); err != nil {
+				if err := context.updateWorkflowExecutionWithContext(executionContext, transferTasks, timerTasks, transactionID); err != nil {
 					return nil, err
 				}
 				handler.timerProcessor.NotifyNewTimers(handler.currentClusterName, handler.shard.GetCurrentTime(handler.currentClusterName), timerTasks)
@@ -562,29 +695,54 @@ Update_History_Loop:
 			return nil, updateErr
 		}
 
+		context.setEmptyDecisionCount(emptyDecisionCount)
+		context.setDecisionHeartbeatCount(decisionHeartbeatCount)
+
 		// add continueAsNewTimerTask
 		timerTasks = append(timerTasks, continueAsNewTimerTasks...)
 		// Inform timer about the new ones.
 		handler.timerProcessor.NotifyNewTimers(handler.currentClusterName, handler.shard.GetCurrentTime(handler.currentClusterName), timerTasks)
 
-		resp = &h.RespondDecisionTaskCompletedResponse{}
+		resp = &h.RespondDecisionTaskCompletedResponse{
+			EventsWritten: common.Int64Ptr(msBuilder.GetNextEventID() - nextEventIDBeforeDecision),
+		}
 		if request.GetReturnNewDecisionTask() && createNewDecisionTask {
 			di, _ := msBuilder.GetPendingDecision(newDecisionTaskScheduledID)
-			resp.StartedResponse = handler.createRecordDecisionTaskStartedResponse(domainID, msBuilder, di, request.GetIdentity())
-			// sticky is always enabled when worker request for new decision task from RespondDecisionTaskCompleted
-			resp.StartedResponse.StickyExecutionEnabled = common.BoolPtr(true)
+			resp.StartedResponse = handler.createRecordDecisionTaskStartedResponse(domainID, msBuilder, di,
+				newDecisionTaskList, request.GetIdentity())
+			// a worker asking for the new decision task on completion is assumed to cache the workflow, unless
+			// configured otherwise in which case the new decision task is only sticky if the workflow is
+			if handler.config.ReturnNewDecisionTaskAlwaysSticky(domainEntry.GetInfo().Name) && resetStickyAttributes == nil {
+				resp.StartedResponse.StickyExecutionEnabled = common.BoolPtr(true)
+			}
 		}
 
 		return resp, nil
 	}
 
+	emitUpdateFailure(handler.metricsClient, metrics.HistoryRespondDecisionTaskCompletedScope, updateFailureCauseMaxAttempts)
 	return nil, ErrMaxAttemptsExceeded
 }
 
+func (handler *decisionHandlerImpl) checkDomainActive(
+	domainUUID *string,
+) error {
+
+	domainEntry, err := handler.historyEngine.getActiveDomainEntry(domainUUID)
+	if err != nil {
+		return err
+	}
+	if domainEntry.GetInfo().Status == persistence.DomainStatusDeleted {
+		return ErrDomainDeleted
+	}
+	return nil
+}
+
 func (handler *decisionHandlerImpl) createRecordDecisionTaskStartedResponse(
 	domainID string,
 	msBuilder mutableState,
 	di *decisionInfo,
+	taskList string,
 	identity string,
 ) *h.RecordDecisionTaskStartedResponse {
 
@@ -599,14 +757,23 @@ func (handler *decisionHandlerImpl) createRecordDecisionTaskStartedResponse(
 	// before it was started.
 	response.ScheduledEventId = common.Int64Ptr(di.ScheduleID)
 	response.StartedEventId = common.Int64Ptr(di.StartedID)
-	response.StickyExecutionEnabled = common.BoolPtr(msBuilder.IsStickyTaskListEnabled())
+	// the decision is only sticky if it was dispatched on the sticky task list, the sticky attributes may have been
+	// set again after it was scheduled on the normal task list
+	taskListKind := getWorkflowExecutionTaskListKind(msBuilder, taskList)
+	stickyExecutionEnabled := taskListKind == workflow.TaskListKindSticky
+	response.StickyExecutionEnabled = common.BoolPtr(stickyExecutionEnabled)
 	response.NextEventId = common.Int64Ptr(msBuilder.GetNextEventID())
 	response.Attempt = common.Int64Ptr(di.Attempt)
 	response.WorkflowExecutionTaskList = common.TaskListPtr(workflow.TaskList{
 		Name: &executionInfo.TaskList,
-		Kind: common.TaskListKindPtr(workflow.TaskListKindNormal),
+		Kind: common.TaskListKindPtr(taskListKind),
 	})
-	response.ScheduledTimestamp = common.Int64Ptr(di.ScheduledTimestamp)
+	if stickyExecutionEnabled {
+		response.StickyScheduleToStartTimeoutSeconds = common.Int32Ptr(executionInfo.StickyScheduleToStartTimeout)
+	}
+	// the scheduled time of a retried decision is the one of its first attempt, mirroring activities
+	response.ScheduledTimestamp = common.Int64Ptr(di.OriginalScheduledTimestamp)
+	response.ScheduledTimestampOfThisAttempt = common.Int64Ptr(di.ScheduledTimestamp)
 	response.StartedTimestamp = common.Int64Ptr(di.StartedTimestamp)
 
 	if di.Attempt > 0 {
@@ -622,3 +789,63 @@ func (handler *decisionHandlerImpl) createRecordDecisionTaskStartedResponse(
 
 	return response
 }
+
+// getWorkflowExecutionTaskListKind returns the kind of the task list the decision was dispatched on, which is only
+// sticky if it is the sticky task list currently in use by the workflow
+func getWorkflowExecutionTaskListKind(msBuilder mutableState, taskList string) workflow.TaskListKind {
+	if msBuilder.IsStickyTaskListEnabled() && taskList == msBuilder.GetExecutionInfo().StickyTaskList {
+		return workflow.TaskListKindSticky
+	}
+	return workflow.TaskListKindNormal
+}
+
+// createDecisionTaskHandler creates the handler applying the decisions of the decision task completed by the given
+// event to the mutable state, with the validation and size limits configured for the domain
+func (handler *decisionHandlerImpl) createDecisionTaskHandler(
+	identity string,
+	completedEventID int64,
+	eventStoreVersion int32,
+	domainEntry *cache.DomainCacheEntry,
+	msBuilder mutableState,
+	timerBuilderProvider timerBuilderProvider,
+	metricsClient metrics.Client,
+) *decisionTaskHandlerImpl {
+
+	domainName := domainEntry.GetInfo().Name
+	decisionAttrValidator := newDecisionAttrValidator(
+		handler.domainCache,
+		handler.config.MaxIDLengthLimit(),
+		handler.config.MaxNonRetriableErrorReasonsCount(),
+		handler.config.MaxNonRetriableErrorReasonsLength(),
+		handler.config.CronMinBackoffInterval(domainName),
+		common.NewQueryValidator(handler.config.ValidSearchAttributes),
+	)
+	decisionBlobSizeChecker := newDecisionBlobSizeChecker(
+		handler.config.BlobSizeLimitWarn(domainName),
+		handler.config.BlobSizeLimitError(domainName),
+		handler.config.ActivityTypeBlobSizeLimitError(dynamicconfig.DomainFilter(domainName)),
+		completedEventID,
+		msBuilder,
+		metricsClient,
+		handler.throttledLogger,
+	)
+
+	return newDecisionTaskHandler(
+		identity,
+		completedEventID,
+		eventStoreVersion,
+		domainEntry,
+		msBuilder,
+		decisionAttrValidator,
+		decisionBlobSizeChecker,
+		handler.config.MaximumChildWorkflowsPerExecution(domainName),
+		handler.config.CronMinBackoffInterval(domainName),
+		handler.config.CronMisfireFireImmediately(domainName),
+		handler.shard.GetTimeSource(),
+		handler.config.DecisionTypeMetricsSampleRate(domainName),
+		handler.logger,
+		timerBuilderProvider,
+		handler.domainCache,
+		metricsClient,
+	)
+}
@@ -0,0 +1,486 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	ctx "context"
+	"fmt"
+
+	"github.com/uber-go/tally"
+	workflow "github.com/uber/cadence/.gen/go/shared"
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+)
+
+// handleDecisionTaskReplay rebuilds the mutable state of the run up to the start of the given decision task in memory
+// and processes the decisions recovered from the events recorded with its completion again, nothing is persisted
+func (handler *decisionHandlerImpl) handleDecisionTaskReplay(
+	ctx ctx.Context,
+	domainUUID string,
+	execution workflow.WorkflowExecution,
+	scheduleID int64,
+) (*DecisionReplayResult, error) {
+
+	domainID, err := validateDomainUUID(common.StringPtr(domainUUID))
+	if err != nil {
+		return nil, err
+	}
+	domainEntry, err := handler.domainCache.GetDomainByID(domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	context, release, err := handler.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
+	if err != nil {
+		return nil, err
+	}
+	msBuilder, err := context.loadWorkflowExecution()
+	if err != nil {
+		release(err)
+		return nil, err
+	}
+	execution.RunId = common.StringPtr(context.getExecution().GetRunId())
+	eventStoreVersion := msBuilder.GetEventStoreVersion()
+	branchToken := msBuilder.GetCurrentBranch()
+	nextEventID := msBuilder.GetNextEventID()
+	hasReplicationState := msBuilder.GetReplicationState() != nil
+	// release the context lock since the rest of logic is only reading the history
+	release(nil)
+
+	if eventStoreVersion != persistence.EventStoreVersionV2 {
+		return nil, &workflow.BadRequestError{Message: "Decision replay is only supported for the V2 event store."}
+	}
+	if scheduleID < common.FirstEventID || scheduleID >= nextEventID {
+		return nil, &workflow.BadRequestError{Message: fmt.Sprintf("Decision schedule ID %v is out of range.", scheduleID)}
+	}
+
+	replayMutableState, startedID, completedEvent, completedBatch, err := handler.rebuildMutableStateForReplay(
+		ctx, domainEntry, execution, branchToken, nextEventID, scheduleID, hasReplicationState)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DecisionReplayResult{}
+	var decisions []*workflow.Decision
+	for _, event := range completedBatch {
+		if getDecisionTaskCompletedEventID(event) != completedEvent.GetEventId() {
+			continue
+		}
+		decision, err := getDecisionFromEvent(event)
+		if err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, decision)
+		result.RecordedEvents = append(result.RecordedEvents, event)
+	}
+
+	attributes := completedEvent.DecisionTaskCompletedEventAttributes
+	request := &workflow.RespondDecisionTaskCompletedRequest{
+		Decisions:        decisions,
+		ExecutionContext: attributes.ExecutionContext,
+		Identity:         attributes.Identity,
+		BinaryChecksum:   attributes.BinaryChecksum,
+	}
+	// a max of 0 reset points tells mutable state to skip recording them
+	replayedCompletedEvent, err := replayMutableState.AddDecisionTaskCompletedEvent(scheduleID, startedID, request, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// the replayed decisions must not show up in the metrics of the live decisions
+	decisionTaskHandler := handler.createDecisionTaskHandler(
+		request.GetIdentity(),
+		replayedCompletedEvent.GetEventId(),
+		persistence.EventStoreVersionV2,
+		domainEntry,
+		replayMutableState,
+		func() *timerBuilder {
+			return handler.historyEngine.getTimerBuilder(&execution)
+		},
+		metrics.NewClient(tally.NoopScope, metrics.History),
+	)
+	if err := decisionTaskHandler.handleDecisions(decisions); err != nil {
+		return nil, err
+	}
+	if decisionTaskHandler.failDecision {
+		result.FailCause = decisionTaskHandler.failDecisionCause
+	}
+	for _, event := range replayMutableState.GetHistoryBuilder().GetHistory().Events {
+		if getDecisionTaskCompletedEventID(event) == replayedCompletedEvent.GetEventId() {
+			result.ReplayedEvents = append(result.ReplayedEvents, event)
+		}
+	}
+	return result, nil
+}
+
+// rebuildMutableStateForReplay applies the history up to the started event of the given decision task to a new
+// mutable state, and returns it along with the completed event of the decision task and the events recorded after it
+// in the same batch
+func (handler *decisionHandlerImpl) rebuildMutableStateForReplay(
+	ctx ctx.Context,
+	domainEntry *cache.DomainCacheEntry,
+	execution workflow.WorkflowExecution,
+	branchToken []byte,
+	nextEventID int64,
+	scheduleID int64,
+	hasReplicationState bool,
+) (*mutableStateBuilder, int64, *workflow.HistoryEvent, []*workflow.HistoryEvent, error) {
+
+	domainID := domainEntry.GetInfo().ID
+	readReq := &persistence.ReadHistoryBranchRequest{
+		BranchToken: branchToken,
+		MinEventID:  common.FirstEventID,
+		MaxEventID:  nextEventID,
+		PageSize:    handler.config.HistoryPageSize(domainEntry.GetInfo().Name),
+		ShardID:     common.IntPtr(handler.shard.GetShardID()),
+	}
+
+	var replayMutableState *mutableStateBuilder
+	var sBuilder stateBuilder
+	var completedEvent *workflow.HistoryEvent
+	var completedBatch []*workflow.HistoryEvent
+	startedID := common.EmptyEventID
+
+Read_History_Loop:
+	for {
+		if ctx.Err() != nil {
+			return nil, 0, nil, nil, ctx.Err()
+		}
+		readResp, err := handler.shard.GetHistoryV2Manager().ReadHistoryBranchByBatch(readReq)
+		if err != nil {
+			return nil, 0, nil, nil, err
+		}
+
+		for _, batch := range readResp.History {
+			history := batch.Events
+			if startedID == common.EmptyEventID {
+				var remaining []*workflow.HistoryEvent
+				for i, event := range history {
+					if event.GetEventType() == workflow.EventTypeDecisionTaskStarted &&
+						event.DecisionTaskStartedEventAttributes.GetScheduledEventId() == scheduleID {
+						startedID = event.GetEventId()
+						history, remaining = history[:i+1], history[i+1:]
+						break
+					}
+				}
+
+				firstEvent := history[0]
+				if firstEvent.GetEventId() == common.FirstEventID {
+					// a separate events cache keeps the replayed events away from the cached events of the run
+					if hasReplicationState {
+						replayMutableState = newMutableStateBuilderWithReplicationState(
+							handler.currentClusterName,
+							handler.shard,
+							newEventsCache(handler.shard),
+							handler.logger,
+							firstEvent.GetVersion(),
+						)
+					} else {
+						replayMutableState = newMutableStateBuilder(handler.currentClusterName, handler.shard,
+							newEventsCache(handler.shard), handler.logger)
+					}
+					replayMutableState.executionInfo.EventStoreVersion = persistence.EventStoreVersionV2
+					sBuilder = newStateBuilder(handler.shard, replayMutableState, handler.logger)
+				}
+
+				// NOTE: passing 0 as newRunEventStoreVersion is safe here, since no events past the decision are applied
+				_, _, _, err = sBuilder.applyEvents(domainID, "", execution, history, nil, persistence.EventStoreVersionV2, 0)
+				if err != nil {
+					return nil, 0, nil, nil, err
+				}
+				history = remaining
+			}
+
+			// the decision task completed event is the first event after the started event, and is written in one
+			// batch with the events of the decisions
+			if startedID != common.EmptyEventID && len(history) > 0 {
+				completedEvent, completedBatch = history[0], history[1:]
+				break Read_History_Loop
+			}
+		}
+
+		if len(readResp.NextPageToken) == 0 {
+			break
+		}
+		readReq.NextPageToken = readResp.NextPageToken
+	}
+
+	if completedEvent == nil || completedEvent.GetEventType() != workflow.EventTypeDecisionTaskCompleted {
+		return nil, 0, nil, nil, &workflow.BadRequestError{
+			Message: fmt.Sprintf("Decision task %v is not completed.", scheduleID),
+		}
+	}
+
+	// the branch token and run ID are set from the start event, which does not hold the ones of a reset run
+	replayMutableState.executionInfo.BranchToken = branchToken
+	replayMutableState.executionInfo.RunID = execution.GetRunId()
+	// applying events to mutable state does not move the next event ID
+	replayMutableState.executionInfo.SetNextEventID(startedID + 1)
+	return replayMutableState, startedID, completedEvent, completedBatch, nil
+}
+
+// getDecisionTaskCompletedEventID returns the ID of the decision task completed event of the decision which recorded
+// the event, or EmptyEventID if the event was not recorded by a decision
+func getDecisionTaskCompletedEventID(event *workflow.HistoryEvent) int64 {
+	var completedEventID *int64
+	switch event.GetEventType() {
+	case workflow.EventTypeActivityTaskScheduled:
+		completedEventID = event.ActivityTaskScheduledEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeActivityTaskCancelRequested:
+		completedEventID = event.ActivityTaskCancelRequestedEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeRequestCancelActivityTaskFailed:
+		completedEventID = event.RequestCancelActivityTaskFailedEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeTimerStarted:
+		completedEventID = event.TimerStartedEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeTimerCanceled:
+		completedEventID = event.TimerCanceledEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeCancelTimerFailed:
+		completedEventID = event.CancelTimerFailedEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeMarkerRecorded:
+		completedEventID = event.MarkerRecordedEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeWorkflowExecutionCompleted:
+		completedEventID = event.WorkflowExecutionCompletedEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeWorkflowExecutionFailed:
+		completedEventID = event.WorkflowExecutionFailedEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeWorkflowExecutionCanceled:
+		completedEventID = event.WorkflowExecutionCanceledEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeWorkflowExecutionContinuedAsNew:
+		completedEventID = event.WorkflowExecutionContinuedAsNewEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeStartChildWorkflowExecutionInitiated:
+		completedEventID = event.StartChildWorkflowExecutionInitiatedEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeRequestCancelExternalWorkflowExecutionInitiated:
+		completedEventID = event.RequestCancelExternalWorkflowExecutionInitiatedEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeSignalExternalWorkflowExecutionInitiated:
+		completedEventID = event.SignalExternalWorkflowExecutionInitiatedEventAttributes.DecisionTaskCompletedEventId
+	case workflow.EventTypeUpsertWorkflowMemo:
+		completedEventID = event.UpsertWorkflowMemoEventAttributes.DecisionTaskCompletedEventId
+	}
+	if completedEventID == nil {
+		return common.EmptyEventID
+	}
+	return *completedEventID
+}
+
+// getDecisionFromEvent recovers the decision which recorded the event, decision attributes which are not recorded on
+// the event, like the memo of a continue as new decision, are not recovered
+func getDecisionFromEvent(event *workflow.HistoryEvent) (*workflow.Decision, error) {
+	switch event.GetEventType() {
+	case workflow.EventTypeActivityTaskScheduled:
+		attr := event.ActivityTaskScheduledEventAttributes
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeScheduleActivityTask.Ptr(),
+			ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
+				ActivityId:                    attr.ActivityId,
+				ActivityType:                  attr.ActivityType,
+				Domain:                        attr.Domain,
+				TaskList:                      attr.TaskList,
+				Input:                         attr.Input,
+				ScheduleToCloseTimeoutSeconds: attr.ScheduleToCloseTimeoutSeconds,
+				ScheduleToStartTimeoutSeconds: attr.ScheduleToStartTimeoutSeconds,
+				StartToCloseTimeoutSeconds:    attr.StartToCloseTimeoutSeconds,
+				HeartbeatTimeoutSeconds:       attr.HeartbeatTimeoutSeconds,
+				RetryPolicy:                   attr.RetryPolicy,
+				Header:                        attr.Header,
+				DispatchOnly:                  attr.DispatchOnly,
+				ScheduleDelaySeconds:          attr.ScheduleDelaySeconds,
+			},
+		}, nil
+
+	case workflow.EventTypeActivityTaskCancelRequested:
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeRequestCancelActivityTask.Ptr(),
+			RequestCancelActivityTaskDecisionAttributes: &workflow.RequestCancelActivityTaskDecisionAttributes{
+				ActivityId: event.ActivityTaskCancelRequestedEventAttributes.ActivityId,
+			},
+		}, nil
+
+	case workflow.EventTypeRequestCancelActivityTaskFailed:
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeRequestCancelActivityTask.Ptr(),
+			RequestCancelActivityTaskDecisionAttributes: &workflow.RequestCancelActivityTaskDecisionAttributes{
+				ActivityId: event.RequestCancelActivityTaskFailedEventAttributes.ActivityId,
+			},
+		}, nil
+
+	case workflow.EventTypeTimerStarted:
+		attr := event.TimerStartedEventAttributes
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeStartTimer.Ptr(),
+			StartTimerDecisionAttributes: &workflow.StartTimerDecisionAttributes{
+				TimerId:                   attr.TimerId,
+				StartToFireTimeoutSeconds: attr.StartToFireTimeoutSeconds,
+			},
+		}, nil
+
+	case workflow.EventTypeTimerCanceled:
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeCancelTimer.Ptr(),
+			CancelTimerDecisionAttributes: &workflow.CancelTimerDecisionAttributes{
+				TimerId: event.TimerCanceledEventAttributes.TimerId,
+			},
+		}, nil
+
+	case workflow.EventTypeCancelTimerFailed:
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeCancelTimer.Ptr(),
+			CancelTimerDecisionAttributes: &workflow.CancelTimerDecisionAttributes{
+				TimerId: event.CancelTimerFailedEventAttributes.TimerId,
+			},
+		}, nil
+
+	case workflow.EventTypeMarkerRecorded:
+		attr := event.MarkerRecordedEventAttributes
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeRecordMarker.Ptr(),
+			RecordMarkerDecisionAttributes: &workflow.RecordMarkerDecisionAttributes{
+				MarkerName: attr.MarkerName,
+				Details:    attr.Details,
+				Header:     attr.Header,
+			},
+		}, nil
+
+	case workflow.EventTypeWorkflowExecutionCompleted:
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeCompleteWorkflowExecution.Ptr(),
+			CompleteWorkflowExecutionDecisionAttributes: &workflow.CompleteWorkflowExecutionDecisionAttributes{
+				Result: event.WorkflowExecutionCompletedEventAttributes.Result,
+			},
+		}, nil
+
+	case workflow.EventTypeWorkflowExecutionFailed:
+		attr := event.WorkflowExecutionFailedEventAttributes
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeFailWorkflowExecution.Ptr(),
+			FailWorkflowExecutionDecisionAttributes: &workflow.FailWorkflowExecutionDecisionAttributes{
+				Reason:  attr.Reason,
+				Details: attr.Details,
+			},
+		}, nil
+
+	case workflow.EventTypeWorkflowExecutionCanceled:
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeCancelWorkflowExecution.Ptr(),
+			CancelWorkflowExecutionDecisionAttributes: &workflow.CancelWorkflowExecutionDecisionAttributes{
+				Details: event.WorkflowExecutionCanceledEventAttributes.Details,
+			},
+		}, nil
+
+	case workflow.EventTypeWorkflowExecutionContinuedAsNew:
+		return getDecisionFromContinuedAsNewEvent(event.WorkflowExecutionContinuedAsNewEventAttributes), nil
+
+	case workflow.EventTypeStartChildWorkflowExecutionInitiated:
+		attr := event.StartChildWorkflowExecutionInitiatedEventAttributes
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeStartChildWorkflowExecution.Ptr(),
+			StartChildWorkflowExecutionDecisionAttributes: &workflow.StartChildWorkflowExecutionDecisionAttributes{
+				Domain:                              attr.Domain,
+				WorkflowId:                          attr.WorkflowId,
+				WorkflowType:                        attr.WorkflowType,
+				TaskList:                            attr.TaskList,
+				Input:                               attr.Input,
+				ExecutionStartToCloseTimeoutSeconds: attr.ExecutionStartToCloseTimeoutSeconds,
+				TaskStartToCloseTimeoutSeconds:      attr.TaskStartToCloseTimeoutSeconds,
+				ChildPolicy:                         attr.ChildPolicy,
+				Control:                             attr.Control,
+				WorkflowIdReusePolicy:               attr.WorkflowIdReusePolicy,
+				RetryPolicy:                         attr.RetryPolicy,
+				CronSchedule:                        attr.CronSchedule,
+				Header:                              attr.Header,
+			},
+		}, nil
+
+	case workflow.EventTypeRequestCancelExternalWorkflowExecutionInitiated:
+		attr := event.RequestCancelExternalWorkflowExecutionInitiatedEventAttributes
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeRequestCancelExternalWorkflowExecution.Ptr(),
+			RequestCancelExternalWorkflowExecutionDecisionAttributes: &workflow.RequestCancelExternalWorkflowExecutionDecisionAttributes{
+				Domain:            attr.Domain,
+				WorkflowId:        attr.WorkflowExecution.WorkflowId,
+				RunId:             attr.WorkflowExecution.RunId,
+				Control:           attr.Control,
+				ChildWorkflowOnly: attr.ChildWorkflowOnly,
+			},
+		}, nil
+
+	case workflow.EventTypeSignalExternalWorkflowExecutionInitiated:
+		attr := event.SignalExternalWorkflowExecutionInitiatedEventAttributes
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeSignalExternalWorkflowExecution.Ptr(),
+			SignalExternalWorkflowExecutionDecisionAttributes: &workflow.SignalExternalWorkflowExecutionDecisionAttributes{
+				Domain:            attr.Domain,
+				Execution:         attr.WorkflowExecution,
+				SignalName:        attr.SignalName,
+				Input:             attr.Input,
+				Control:           attr.Control,
+				ChildWorkflowOnly: attr.ChildWorkflowOnly,
+			},
+		}, nil
+
+	case workflow.EventTypeUpsertWorkflowMemo:
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeUpsertWorkflowMemo.Ptr(),
+			UpsertWorkflowMemoDecisionAttributes: &workflow.UpsertWorkflowMemoDecisionAttributes{
+				Memo: event.UpsertWorkflowMemoEventAttributes.Memo,
+			},
+		}, nil
+
+	default:
+		return nil, &workflow.BadRequestError{
+			Message: fmt.Sprintf("Cannot recover decision from event type: %v", event.GetEventType()),
+		}
+	}
+}
+
+// getDecisionFromContinuedAsNewEvent recovers the decision which continued the workflow as new, which is a complete
+// or fail workflow decision unless the decider initiated it
+func getDecisionFromContinuedAsNewEvent(attr *workflow.WorkflowExecutionContinuedAsNewEventAttributes) *workflow.Decision {
+	if attr.GetInitiator() != workflow.ContinueAsNewInitiatorDecider {
+		if attr.FailureReason != nil {
+			return &workflow.Decision{
+				DecisionType: workflow.DecisionTypeFailWorkflowExecution.Ptr(),
+				FailWorkflowExecutionDecisionAttributes: &workflow.FailWorkflowExecutionDecisionAttributes{
+					Reason:  attr.FailureReason,
+					Details: attr.FailureDetails,
+				},
+			}
+		}
+		return &workflow.Decision{
+			DecisionType: workflow.DecisionTypeCompleteWorkflowExecution.Ptr(),
+			CompleteWorkflowExecutionDecisionAttributes: &workflow.CompleteWorkflowExecutionDecisionAttributes{
+				Result: attr.LastCompletionResult,
+			},
+		}
+	}
+	return &workflow.Decision{
+		DecisionType: workflow.DecisionTypeContinueAsNewWorkflowExecution.Ptr(),
+		ContinueAsNewWorkflowExecutionDecisionAttributes: &workflow.ContinueAsNewWorkflowExecutionDecisionAttributes{
+			WorkflowType:                        attr.WorkflowType,
+			TaskList:                            attr.TaskList,
+			Input:                               attr.Input,
+			ExecutionStartToCloseTimeoutSeconds: attr.ExecutionStartToCloseTimeoutSeconds,
+			TaskStartToCloseTimeoutSeconds:      attr.TaskStartToCloseTimeoutSeconds,
+			BackoffStartIntervalInSeconds:       attr.BackoffStartIntervalInSeconds,
+			Header:                              attr.Header,
+		},
+	}
+}
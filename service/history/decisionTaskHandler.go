@@ -22,18 +22,26 @@ package history
 
 import (
 	"fmt"
+	"math/rand"
+	"time"
 
 	"github.com/pborman/uuid"
 	workflow "github.com/uber/cadence/.gen/go/shared"
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/backoff"
 	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/metrics"
 	"github.com/uber/cadence/common/persistence"
 )
 
+const (
+	// localActivityMarkerName is the marker name used by the client to record local activity results
+	localActivityMarkerName = "LocalActivity"
+)
+
 type (
 	timerBuilderProvider func() *timerBuilder
 
@@ -61,11 +69,21 @@ type (
 		// validation
 		attrValidator    *decisionAttrValidator
 		sizeLimitChecker *decisionBlobSizeChecker
+		// maxPendingChildWorkflows is the max number of pending child workflows, 0 means unlimited
+		maxPendingChildWorkflows int
+		// cronMinBackoffInterval is the minimum backoff before the next run of a cron workflow
+		cronMinBackoffInterval time.Duration
+		// cronMisfireFireImmediately is whether a misfired cron slot is run right away instead of skipped
+		cronMisfireFireImmediately bool
+		// timeSource is the clock of the shard, misfired cron slots are detected against it
+		timeSource clock.TimeSource
 
 		logger               log.Logger
 		timerBuilderProvider timerBuilderProvider
 		domainCache          cache.DomainCache
 		metricsClient        metrics.Client
+		// decisionTypeMetricsWeight is added to the per decision type counters, 0 if this call is not sampled
+		decisionTypeMetricsWeight int64
 	}
 )
 
@@ -77,6 +95,11 @@ func newDecisionTaskHandler(
 	mutableState mutableState,
 	attrValidator *decisionAttrValidator,
 	sizeLimitChecker *decisionBlobSizeChecker,
+	maxPendingChildWorkflows int,
+	cronMinBackoffInterval time.Duration,
+	cronMisfireFireImmediately bool,
+	timeSource clock.TimeSource,
+	decisionTypeMetricsSampleRate float64,
 	logger log.Logger,
 	timerBuilderProvider timerBuilderProvider,
 	domainCache cache.DomainCache,
@@ -90,7 +113,7 @@ func newDecisionTaskHandler(
 		domainEntry:             domainEntry,
 
 		// internal state
-		hasUnhandledEventsBeforeDecisions: mutableState.HasBufferedEvents(),
+		hasUnhandledEventsBeforeDecisions: mutableState.HasUnhandledBufferedEvents(),
 		transferTasks:                     nil,
 		timerTasks:                        nil,
 		failDecision:                      false,
@@ -102,14 +125,20 @@ func newDecisionTaskHandler(
 		mutableState:                      mutableState,
 
 		// validation
-		attrValidator:    attrValidator,
-		sizeLimitChecker: sizeLimitChecker,
+		attrValidator:              attrValidator,
+		sizeLimitChecker:           sizeLimitChecker,
+		maxPendingChildWorkflows:   maxPendingChildWorkflows,
+		cronMinBackoffInterval:     cronMinBackoffInterval,
+		cronMisfireFireImmediately: cronMisfireFireImmediately,
+		timeSource:                 timeSource,
 
 		logger:               logger,
 		timerBuilder:         timerBuilderProvider(),
 		timerBuilderProvider: timerBuilderProvider,
 		domainCache:          domainCache,
 		metricsClient:        metricsClient,
+
+		decisionTypeMetricsWeight: getDecisionTypeMetricsWeight(decisionTypeMetricsSampleRate),
 	}
 }
 
@@ -165,6 +194,9 @@ func (handler *decisionTaskHandlerImpl) handleDecision(decision *workflow.Decisi
 	case workflow.DecisionTypeStartChildWorkflowExecution:
 		return handler.handleDecisionStartChildWorkflow(decision.StartChildWorkflowExecutionDecisionAttributes)
 
+	case workflow.DecisionTypeUpsertWorkflowMemo:
+		return handler.handleDecisionUpsertWorkflowMemo(decision.UpsertWorkflowMemoDecisionAttributes)
+
 	default:
 		return &workflow.BadRequestError{Message: fmt.Sprintf("Unknown decision type: %v", decision.GetDecisionType())}
 	}
@@ -174,10 +206,7 @@ func (handler *decisionTaskHandlerImpl) handleDecisionScheduleActivity(
 	attr *workflow.ScheduleActivityTaskDecisionAttributes,
 ) error {
 
-	handler.metricsClient.IncCounter(
-		metrics.HistoryRespondDecisionTaskCompletedScope,
-		metrics.DecisionTypeScheduleActivityCounter,
-	)
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeScheduleActivityCounter)
 
 	executionInfo := handler.mutableState.GetExecutionInfo()
 	domainID := executionInfo.DomainID
@@ -192,21 +221,36 @@ func (handler *decisionTaskHandlerImpl) handleDecisionScheduleActivity(
 		targetDomainID = targetDomainEntry.GetInfo().ID
 	}
 
+	scheduleToCloseTimeout := attr.GetScheduleToCloseTimeoutSeconds()
+	retryExpiration := attr.GetRetryPolicy().GetExpirationIntervalInSeconds()
 	if err := handler.validateDecisionAttr(
 		func() error {
 			return handler.attrValidator.validateActivityScheduleAttributes(
 				domainID,
 				targetDomainID,
 				attr,
-				executionInfo.WorkflowTimeout,
+				handler.remainingWorkflowTimeoutSeconds(),
 			)
 		},
 		workflow.DecisionTaskFailedCauseBadScheduleActivityAttributes,
 	); err != nil || handler.stopProcessing {
 		return err
 	}
+	if !attr.GetDispatchOnly() && attr.GetScheduleToCloseTimeoutSeconds() < scheduleToCloseTimeout {
+		handler.metricsClient.Scope(
+			metrics.HistoryRespondDecisionTaskCompletedScope,
+			metrics.DomainTag(handler.domainEntry.GetInfo().Name),
+		).IncCounter(metrics.ActivityScheduleToCloseTimeoutClampedCounter)
+	}
+	if attr.GetRetryPolicy().GetExpirationIntervalInSeconds() < retryExpiration {
+		handler.metricsClient.Scope(
+			metrics.HistoryRespondDecisionTaskCompletedScope,
+			metrics.DomainTag(handler.domainEntry.GetInfo().Name),
+		).IncCounter(metrics.ActivityRetryExpirationClampedCounter)
+	}
 
-	failWorkflow, err := handler.sizeLimitChecker.failWorkflowIfBlobSizeExceedsLimit(
+	failWorkflow, err := handler.sizeLimitChecker.failWorkflowIfActivityInputSizeExceedsLimit(
+		attr.ActivityType.GetName(),
 		attr.Input,
 		"ScheduleActivityTaskDecisionAttributes.Input exceeds size limit.",
 	)
@@ -215,9 +259,24 @@ func (handler *decisionTaskHandlerImpl) handleDecisionScheduleActivity(
 		return err
 	}
 
-	scheduleEvent, _, err := handler.mutableState.AddActivityTaskScheduledEvent(handler.decisionTaskCompletedID, attr)
+	scheduleEvent, ai, err := handler.mutableState.AddActivityTaskScheduledEvent(handler.decisionTaskCompletedID, attr)
 	switch err.(type) {
 	case nil:
+		handler.metricsClient.Scope(
+			metrics.HistoryRespondDecisionTaskCompletedScope,
+			metrics.DomainTag(handler.domainEntry.GetInfo().Name),
+			metrics.ActivityTypeTag(attr.ActivityType.GetName()),
+		).IncCounter(metrics.ActivityTypeScheduledCounter)
+		if delay := attr.GetScheduleDelaySeconds(); delay > 0 {
+			// a delayed activity is dispatched to matching by the activity retry timer once the delay elapses
+			handler.timerTasks = append(handler.timerTasks, &persistence.ActivityRetryTimerTask{
+				Version:             ai.Version,
+				VisibilityTimestamp: ai.ScheduledTime.Add(time.Duration(delay) * time.Second),
+				EventID:             ai.ScheduleID,
+				Attempt:             ai.Attempt,
+			})
+			return nil
+		}
 		handler.transferTasks = append(handler.transferTasks, &persistence.ActivityTask{
 			DomainID:   targetDomainID,
 			TaskList:   attr.TaskList.GetName(),
@@ -233,14 +292,32 @@ func (handler *decisionTaskHandlerImpl) handleDecisionScheduleActivity(
 	}
 }
 
+// remainingWorkflowTimeoutSeconds returns the time left before the workflow times out, activity timeouts are
+// capped with it so that an activity is never promised more time than the workflow itself has
+func (handler *decisionTaskHandlerImpl) remainingWorkflowTimeoutSeconds() int32 {
+	executionInfo := handler.mutableState.GetExecutionInfo()
+	executionStartTime := executionInfo.StartTimestamp
+	// for cron and retry runs the workflow timeout only starts after the first decision backoff
+	if executionInfo.CronSchedule != "" || executionInfo.Attempt > 0 {
+		if startEvent, ok := handler.mutableState.GetStartEvent(); ok {
+			backoffSeconds := startEvent.WorkflowExecutionStartedEventAttributes.GetFirstDecisionTaskBackoffSeconds()
+			executionStartTime = executionStartTime.Add(time.Duration(backoffSeconds) * time.Second)
+		}
+	}
+
+	remaining := time.Duration(executionInfo.WorkflowTimeout)*time.Second - handler.timeSource.Now().Sub(executionStartTime)
+	if remaining <= 0 {
+		return 0
+	}
+	// round up, a partial second left is still enough to schedule an activity
+	return int32((remaining + time.Second - 1) / time.Second)
+}
+
 func (handler *decisionTaskHandlerImpl) handleDecisionRequestCancelActivity(
 	attr *workflow.RequestCancelActivityTaskDecisionAttributes,
 ) error {
 
-	handler.metricsClient.IncCounter(
-		metrics.HistoryRespondDecisionTaskCompletedScope,
-		metrics.DecisionTypeCancelActivityCounter,
-	)
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeCancelActivityCounter)
 
 	if err := handler.validateDecisionAttr(
 		func() error {
@@ -276,6 +353,20 @@ func (handler *decisionTaskHandlerImpl) handleDecisionRequestCancelActivity(
 		}
 		return nil
 	case *workflow.BadRequestError:
+		// the activity is no longer pending, usually it already completed, which often
+		// indicates a decider that is not deterministic
+		handler.metricsClient.Scope(
+			metrics.HistoryRespondDecisionTaskCompletedScope,
+			metrics.DomainTag(handler.domainEntry.GetInfo().Name),
+		).IncCounter(metrics.DecisionReferencedCompletedActivityCounter)
+		handler.logger.Warn(
+			"Decision requested cancellation of an activity which is not pending",
+			tag.WorkflowDomainName(handler.domainEntry.GetInfo().Name),
+			tag.WorkflowID(handler.mutableState.GetExecutionInfo().WorkflowID),
+			tag.WorkflowRunID(handler.mutableState.GetExecutionInfo().RunID),
+			tag.WorkflowActivityID(activityID),
+			tag.WorkflowEventID(handler.decisionTaskCompletedID),
+		)
 		_, err = handler.mutableState.AddRequestCancelActivityTaskFailedEvent(
 			handler.decisionTaskCompletedID,
 			activityID,
@@ -291,10 +382,7 @@ func (handler *decisionTaskHandlerImpl) handleDecisionStartTimer(
 	attr *workflow.StartTimerDecisionAttributes,
 ) error {
 
-	handler.metricsClient.IncCounter(
-		metrics.HistoryRespondDecisionTaskCompletedScope,
-		metrics.DecisionTypeStartTimerCounter,
-	)
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeStartTimerCounter)
 
 	if err := handler.validateDecisionAttr(
 		func() error {
@@ -323,10 +411,7 @@ func (handler *decisionTaskHandlerImpl) handleDecisionCompleteWorkflow(
 	attr *workflow.CompleteWorkflowExecutionDecisionAttributes,
 ) error {
 
-	handler.metricsClient.IncCounter(
-		metrics.HistoryRespondDecisionTaskCompletedScope,
-		metrics.DecisionTypeCompleteWorkflowCounter,
-	)
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeCompleteWorkflowCounter)
 
 	if handler.hasUnhandledEventsBeforeDecisions {
 		return handler.handlerFailDecision(workflow.DecisionTaskFailedCauseUnhandledDecision, "")
@@ -365,7 +450,7 @@ func (handler *decisionTaskHandlerImpl) handleDecisionCompleteWorkflow(
 	}
 
 	// check if this is a cron workflow
-	cronBackoff := handler.mutableState.GetCronBackoffDuration()
+	cronBackoff := handler.getCronBackoff()
 	if cronBackoff == backoff.NoBackoff {
 		// not cron, so complete this workflow execution
 		if _, err := handler.mutableState.AddCompletedWorkflowEvent(handler.decisionTaskCompletedID, attr); err != nil {
@@ -390,14 +475,92 @@ func (handler *decisionTaskHandlerImpl) handleDecisionCompleteWorkflow(
 	)
 }
 
+// getDecisionTypeMetricsWeight samples a call with the given probability, a sampled call is weighted by the number
+// of calls it stands for so the per decision type counters remain unbiased
+func getDecisionTypeMetricsWeight(probability float64) int64 {
+	if probability <= 0 {
+		return 0
+	}
+	if probability >= 1.0 {
+		return 1
+	}
+	period := int(1.0 / probability)
+	if rand.Intn(period) != 0 {
+		return 0
+	}
+	return int64(period)
+}
+
+func (handler *decisionTaskHandlerImpl) emitDecisionTypeCounter(counter int) {
+	if handler.decisionTypeMetricsWeight == 0 {
+		return
+	}
+	handler.metricsClient.AddCounter(
+		metrics.HistoryRespondDecisionTaskCompletedScope,
+		counter,
+		handler.decisionTypeMetricsWeight,
+	)
+}
+
+// applyCronBackoffFloor raises the backoff before the next cron run to the configured minimum, so a cron workflow
+// that completes instantly cannot continue-as-new in a tight loop
+func (handler *decisionTaskHandlerImpl) applyCronBackoffFloor(cronBackoff time.Duration) time.Duration {
+	if cronBackoff == backoff.NoBackoff || cronBackoff >= handler.cronMinBackoffInterval {
+		return cronBackoff
+	}
+	handler.metricsClient.Scope(
+		metrics.HistoryRespondDecisionTaskCompletedScope,
+		metrics.DomainTag(handler.domainEntry.GetInfo().Name),
+	).IncCounter(metrics.CronBackoffFloorAppliedCounter)
+	return handler.cronMinBackoffInterval
+}
+
+// getCronBackoff returns the backoff before the next run of a cron workflow, or backoff.NoBackoff if it is not one.
+// The run was scheduled at the first slot after its start, if the slot following that one is already in the past
+// the run overran it and the slot is misfired: depending on the misfire policy it is either fired right away or
+// skipped, in which case the next run is scheduled at the first slot after now.
+func (handler *decisionTaskHandlerImpl) getCronBackoff() time.Duration {
+	cronBackoff := handler.mutableState.GetCronBackoffDuration()
+	if cronBackoff == backoff.NoBackoff {
+		return cronBackoff
+	}
+
+	executionInfo := handler.mutableState.GetExecutionInfo()
+	scheduledTime := executionInfo.StartTimestamp.Add(
+		backoff.GetBackoffForNextSchedule(executionInfo.CronSchedule, executionInfo.StartTimestamp),
+	)
+	nextScheduledTime := scheduledTime.Add(backoff.GetBackoffForNextSchedule(executionInfo.CronSchedule, scheduledTime))
+	if nextScheduledTime.Before(handler.timeSource.Now()) {
+		handler.metricsClient.Scope(
+			metrics.HistoryRespondDecisionTaskCompletedScope,
+			metrics.DomainTag(handler.domainEntry.GetInfo().Name),
+		).IncCounter(metrics.CronMisfireCounter)
+		if handler.cronMisfireFireImmediately {
+			cronBackoff = 0
+		}
+	}
+	return handler.applyCronBackoffFloor(cronBackoff)
+}
+
+// getFailWorkflowBackoff returns the backoff before the next run of a failed workflow and what initiated it.
+// The retry policy takes precedence over the cron schedule: as long as the retry policy allows another attempt
+// the workflow is retried after the retry backoff, and only once retries are exhausted (or the failure reason is
+// not retriable) is the next run scheduled by the cron schedule. Returns backoff.NoBackoff if neither applies.
+func (handler *decisionTaskHandlerImpl) getFailWorkflowBackoff(
+	failureReason string,
+) (time.Duration, workflow.ContinueAsNewInitiator) {
+
+	if backoffInterval := handler.mutableState.GetRetryBackoffDuration(failureReason); backoffInterval != backoff.NoBackoff {
+		return backoffInterval, workflow.ContinueAsNewInitiatorRetryPolicy
+	}
+	return handler.getCronBackoff(), workflow.ContinueAsNewInitiatorCronSchedule
+}
+
 func (handler *decisionTaskHandlerImpl) handleDecisionFailWorkflow(
 	attr *workflow.FailWorkflowExecutionDecisionAttributes,
 ) error {
 
-	handler.metricsClient.IncCounter(
-		metrics.HistoryRespondDecisionTaskCompletedScope,
-		metrics.DecisionTypeFailWorkflowCounter,
-	)
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeFailWorkflowCounter)
 
 	if handler.hasUnhandledEventsBeforeDecisions {
 		return handler.handlerFailDecision(workflow.DecisionTaskFailedCauseUnhandledDecision, "")
@@ -436,15 +599,7 @@ func (handler *decisionTaskHandlerImpl) handleDecisionFailWorkflow(
 	}
 
 	// below will check whether to do continue as new based on backoff & backoff or cron
-	backoffInterval := handler.mutableState.GetRetryBackoffDuration(attr.GetReason())
-	continueAsNewInitiator := workflow.ContinueAsNewInitiatorRetryPolicy
-	// first check the backoff retry
-	if backoffInterval == backoff.NoBackoff {
-		// if no backoff retry, set the backoffInterval using cron schedule
-		backoffInterval = handler.mutableState.GetCronBackoffDuration()
-		continueAsNewInitiator = workflow.ContinueAsNewInitiatorCronSchedule
-	}
-	// second check the backoff / cron schedule
+	backoffInterval, continueAsNewInitiator := handler.getFailWorkflowBackoff(attr.GetReason())
 	if backoffInterval == backoff.NoBackoff {
 		// no retry or cron
 		if _, err := handler.mutableState.AddFailWorkflowEvent(handler.decisionTaskCompletedID, attr); err != nil {
@@ -459,6 +614,18 @@ func (handler *decisionTaskHandlerImpl) handleDecisionFailWorkflow(
 		return &workflow.InternalServiceError{Message: "Failed to load start event."}
 	}
 	startAttributes := startEvent.WorkflowExecutionStartedEventAttributes
+
+	// the failure details are checked above, the last completion result of a previous run is carried forward to the
+	// start event of every following run, so it must not be able to bloat each run once the limit is lowered
+	failWorkflow, err = handler.sizeLimitChecker.failWorkflowIfBlobSizeExceedsLimit(
+		startAttributes.LastCompletionResult,
+		"ContinueAsNew LastCompletionResult carried forward to the next run exceeds size limit.",
+	)
+	if err != nil || failWorkflow {
+		handler.stopProcessing = true
+		return err
+	}
+
 	return handler.retryCronContinueAsNew(
 		startAttributes,
 		int32(backoffInterval.Seconds()),
@@ -473,10 +640,7 @@ func (handler *decisionTaskHandlerImpl) handleDecisionCancelTimer(
 	attr *workflow.CancelTimerDecisionAttributes,
 ) error {
 
-	handler.metricsClient.IncCounter(
-		metrics.HistoryRespondDecisionTaskCompletedScope,
-		metrics.DecisionTypeCancelTimerCounter,
-	)
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeCancelTimerCounter)
 
 	if err := handler.validateDecisionAttr(
 		func() error {
@@ -520,8 +684,7 @@ func (handler *decisionTaskHandlerImpl) handleDecisionCancelWorkflow(
 	attr *workflow.CancelWorkflowExecutionDecisionAttributes,
 ) error {
 
-	handler.metricsClient.IncCounter(metrics.HistoryRespondDecisionTaskCompletedScope,
-		metrics.DecisionTypeCancelWorkflowCounter)
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeCancelWorkflowCounter)
 
 	if handler.hasUnhandledEventsBeforeDecisions {
 		return handler.handlerFailDecision(workflow.DecisionTaskFailedCauseUnhandledDecision, "")
@@ -558,10 +721,7 @@ func (handler *decisionTaskHandlerImpl) handleDecisionRequestCancelExternalWorkf
 	attr *workflow.RequestCancelExternalWorkflowExecutionDecisionAttributes,
 ) error {
 
-	handler.metricsClient.IncCounter(
-		metrics.HistoryRespondDecisionTaskCompletedScope,
-		metrics.DecisionTypeCancelExternalWorkflowCounter,
-	)
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeCancelExternalWorkflowCounter)
 
 	executionInfo := handler.mutableState.GetExecutionInfo()
 	domainID := executionInfo.DomainID
@@ -611,10 +771,7 @@ func (handler *decisionTaskHandlerImpl) handleDecisionRecordMarker(
 	attr *workflow.RecordMarkerDecisionAttributes,
 ) error {
 
-	handler.metricsClient.IncCounter(
-		metrics.HistoryRespondDecisionTaskCompletedScope,
-		metrics.DecisionTypeRecordMarkerCounter,
-	)
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeRecordMarkerCounter)
 
 	if err := handler.validateDecisionAttr(
 		func() error {
@@ -625,9 +782,18 @@ func (handler *decisionTaskHandlerImpl) handleDecisionRecordMarker(
 		return err
 	}
 
+	message := "RecordMarkerDecisionAttributes.Details exceeds size limit."
+	if attr.GetMarkerName() == localActivityMarkerName {
+		handler.metricsClient.Scope(
+			metrics.HistoryRespondDecisionTaskCompletedScope,
+			metrics.DomainTag(handler.domainEntry.GetInfo().Name),
+		).IncCounter(metrics.LocalActivityMarkerRecordedCounter)
+		message = "Local activity marker details exceeds size limit."
+	}
+
 	failWorkflow, err := handler.sizeLimitChecker.failWorkflowIfBlobSizeExceedsLimit(
 		attr.Details,
-		"RecordMarkerDecisionAttributes.Details exceeds size limit.",
+		message,
 	)
 	if err != nil || failWorkflow {
 		handler.stopProcessing = true
@@ -642,10 +808,7 @@ func (handler *decisionTaskHandlerImpl) handleDecisionContinueAsNewWorkflow(
 	attr *workflow.ContinueAsNewWorkflowExecutionDecisionAttributes,
 ) error {
 
-	handler.metricsClient.IncCounter(
-		metrics.HistoryRespondDecisionTaskCompletedScope,
-		metrics.DecisionTypeContinueAsNewCounter,
-	)
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeContinueAsNewCounter)
 
 	if handler.hasUnhandledEventsBeforeDecisions {
 		return handler.handlerFailDecision(workflow.DecisionTaskFailedCauseUnhandledDecision, "")
@@ -719,10 +882,7 @@ func (handler *decisionTaskHandlerImpl) handleDecisionStartChildWorkflow(
 	attr *workflow.StartChildWorkflowExecutionDecisionAttributes,
 ) error {
 
-	handler.metricsClient.IncCounter(
-		metrics.HistoryRespondDecisionTaskCompletedScope,
-		metrics.DecisionTypeChildWorkflowCounter,
-	)
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeChildWorkflowCounter)
 
 	executionInfo := handler.mutableState.GetExecutionInfo()
 	domainID := executionInfo.DomainID
@@ -760,6 +920,19 @@ func (handler *decisionTaskHandlerImpl) handleDecisionStartChildWorkflow(
 		return err
 	}
 
+	pendingChildWorkflows := len(handler.mutableState.GetPendingChildExecutionInfos())
+	if handler.maxPendingChildWorkflows > 0 && pendingChildWorkflows >= handler.maxPendingChildWorkflows {
+		handler.metricsClient.IncCounter(
+			metrics.HistoryRespondDecisionTaskCompletedScope,
+			metrics.PendingChildWorkflowsLimitExceededCounter,
+		)
+		return handler.handlerFailDecision(
+			workflow.DecisionTaskFailedCausePendingChildWorkflowsLimitExceeded,
+			fmt.Sprintf("Workflow already has %v pending child workflows, limit is %v.",
+				pendingChildWorkflows, handler.maxPendingChildWorkflows),
+		)
+	}
+
 	requestID := uuid.New()
 	initiatedEvent, _, err := handler.mutableState.AddStartChildWorkflowExecutionInitiatedEvent(
 		handler.decisionTaskCompletedID, requestID, attr,
@@ -779,10 +952,7 @@ func (handler *decisionTaskHandlerImpl) handleDecisionSignalExternalWorkflow(
 	attr *workflow.SignalExternalWorkflowExecutionDecisionAttributes,
 ) error {
 
-	handler.metricsClient.IncCounter(
-		metrics.HistoryRespondDecisionTaskCompletedScope,
-		metrics.DecisionTypeSignalExternalWorkflowCounter,
-	)
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeSignalExternalWorkflowCounter)
 
 	executionInfo := handler.mutableState.GetExecutionInfo()
 	domainID := executionInfo.DomainID
@@ -837,6 +1007,41 @@ func (handler *decisionTaskHandlerImpl) handleDecisionSignalExternalWorkflow(
 	return nil
 }
 
+func (handler *decisionTaskHandlerImpl) handleDecisionUpsertWorkflowMemo(
+	attr *workflow.UpsertWorkflowMemoDecisionAttributes,
+) error {
+
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeUpsertWorkflowMemoCounter)
+
+	memo := handler.mutableState.GetExecutionInfo().Memo
+	if memo == nil {
+		// workflows started before the memo was kept in execution info only have it in their start event
+		if startEvent, ok := handler.mutableState.GetStartEvent(); ok {
+			memo = startEvent.WorkflowExecutionStartedEventAttributes.Memo.GetFields()
+		}
+	}
+
+	if err := handler.validateDecisionAttr(
+		func() error {
+			return handler.attrValidator.validateUpsertWorkflowMemoAttributes(
+				attr,
+				memo,
+				handler.sizeLimitChecker.sizeLimitError,
+			)
+		},
+		workflow.DecisionTaskFailedCauseBadUpsertWorkflowMemoAttributes,
+	); err != nil || handler.stopProcessing {
+		return err
+	}
+
+	if _, err := handler.mutableState.AddUpsertWorkflowMemoEvent(handler.decisionTaskCompletedID, attr); err != nil {
+		return &workflow.InternalServiceError{Message: "Unable to add upsert workflow memo event."}
+	}
+
+	handler.transferTasks = append(handler.transferTasks, &persistence.UpsertWorkflowMemoTask{})
+	return nil
+}
+
 func (handler *decisionTaskHandlerImpl) retryCronContinueAsNew(
 	attr *workflow.WorkflowExecutionStartedEventAttributes,
 	backoffInterval int32,
@@ -0,0 +1,200 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
+	workflow "github.com/uber/cadence/.gen/go/shared"
+	"github.com/uber/cadence/common/backoff"
+	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+)
+
+func Test_ApplyCronBackoffFloor(t *testing.T) {
+	a := assert.New(t)
+	scope := tally.NewTestScope("test", nil)
+	handler := &decisionTaskHandlerImpl{
+		domainEntry: cache.NewLocalDomainCacheEntryForTest(
+			&persistence.DomainInfo{Name: "testDomain"}, &persistence.DomainConfig{}, "", nil,
+		),
+		cronMinBackoffInterval: 5 * time.Second,
+		metricsClient:          metrics.NewClient(scope, metrics.History),
+	}
+	counterKey := "test.cron_backoff_floor_applied+domain=testDomain,operation=RespondDecisionTaskCompleted"
+
+	// not a cron workflow
+	a.Equal(backoff.NoBackoff, handler.applyCronBackoffFloor(backoff.NoBackoff))
+	// backoff already above the floor
+	a.Equal(time.Minute, handler.applyCronBackoffFloor(time.Minute))
+	a.Equal(5*time.Second, handler.applyCronBackoffFloor(5*time.Second))
+	a.NotContains(scope.Snapshot().Counters(), counterKey)
+
+	// backoff below the floor is raised to it
+	a.Equal(5*time.Second, handler.applyCronBackoffFloor(0))
+	a.Equal(5*time.Second, handler.applyCronBackoffFloor(time.Second))
+	a.Equal(int64(2), scope.Snapshot().Counters()[counterKey].Value())
+}
+
+func Test_GetFailWorkflowBackoff(t *testing.T) {
+	a := assert.New(t)
+	executionInfo := &persistence.WorkflowExecutionInfo{
+		CronSchedule:       "@every 1h",
+		HasRetryPolicy:     true,
+		Attempt:            0,
+		MaximumAttempts:    3,
+		InitialInterval:    10,
+		BackoffCoefficient: 2,
+		NonRetriableErrors: []string{"non retriable reason"},
+	}
+	timeSource := clock.NewRealTimeSource()
+	handler := &decisionTaskHandlerImpl{
+		domainEntry: cache.NewLocalDomainCacheEntryForTest(
+			&persistence.DomainInfo{Name: "testDomain"}, &persistence.DomainConfig{}, "", nil,
+		),
+		cronMinBackoffInterval: 5 * time.Second,
+		timeSource:             timeSource,
+		metricsClient:          metrics.NewClient(tally.NoopScope, metrics.History),
+		mutableState: &mutableStateBuilder{
+			executionInfo: executionInfo,
+			shard:         &shardContextImpl{timeSource: timeSource},
+		},
+	}
+
+	// retry policy takes precedence over cron schedule while attempts remain
+	backoffInterval, initiator := handler.getFailWorkflowBackoff("retriable reason")
+	a.Equal(10*time.Second, backoffInterval)
+	a.Equal(workflow.ContinueAsNewInitiatorRetryPolicy, initiator)
+
+	executionInfo.Attempt = 1
+	backoffInterval, initiator = handler.getFailWorkflowBackoff("retriable reason")
+	a.Equal(20*time.Second, backoffInterval)
+	a.Equal(workflow.ContinueAsNewInitiatorRetryPolicy, initiator)
+
+	// cron schedule is used once retries are exhausted
+	executionInfo.Attempt = 2
+	backoffInterval, initiator = handler.getFailWorkflowBackoff("retriable reason")
+	a.InDelta(time.Hour, backoffInterval, float64(time.Second))
+	a.Equal(workflow.ContinueAsNewInitiatorCronSchedule, initiator)
+
+	// cron schedule is used for non retriable failures
+	executionInfo.Attempt = 0
+	backoffInterval, initiator = handler.getFailWorkflowBackoff("non retriable reason")
+	a.InDelta(time.Hour, backoffInterval, float64(time.Second))
+	a.Equal(workflow.ContinueAsNewInitiatorCronSchedule, initiator)
+
+	// neither retry nor cron
+	executionInfo.CronSchedule = ""
+	backoffInterval, _ = handler.getFailWorkflowBackoff("non retriable reason")
+	a.Equal(backoff.NoBackoff, backoffInterval)
+}
+
+func Test_GetCronBackoff_Misfire(t *testing.T) {
+	a := assert.New(t)
+	scope := tally.NewTestScope("test", nil)
+	now := time.Now()
+	timeSource := clock.NewEventTimeSource().Update(now)
+	executionInfo := &persistence.WorkflowExecutionInfo{
+		CronSchedule:   "@every 1h",
+		StartTimestamp: now,
+	}
+	handler := &decisionTaskHandlerImpl{
+		domainEntry: cache.NewLocalDomainCacheEntryForTest(
+			&persistence.DomainInfo{Name: "testDomain"}, &persistence.DomainConfig{}, "", nil,
+		),
+		cronMinBackoffInterval: 5 * time.Second,
+		timeSource:             timeSource,
+		metricsClient:          metrics.NewClient(scope, metrics.History),
+		mutableState: &mutableStateBuilder{
+			executionInfo: executionInfo,
+			shard:         &shardContextImpl{timeSource: timeSource},
+		},
+	}
+	counterKey := "test.cron_misfire+domain=testDomain,operation=RespondDecisionTaskCompleted"
+
+	// the run completed within its slot
+	a.InDelta(time.Hour, handler.getCronBackoff(), float64(time.Second))
+	a.NotContains(scope.Snapshot().Counters(), counterKey)
+
+	// the run was scheduled an hour after its start and took over two hours, so the following slot is misfired
+	// and skipped by default
+	executionInfo.StartTimestamp = now.Add(-3 * time.Hour)
+	a.InDelta(time.Hour, handler.getCronBackoff(), float64(time.Second))
+	a.Equal(int64(1), scope.Snapshot().Counters()[counterKey].Value())
+
+	// the slot is misfired against the shard clock rather than the real time
+	executionInfo.StartTimestamp = now
+	timeSource.Update(now.Add(3 * time.Hour))
+	a.InDelta(time.Hour, handler.getCronBackoff(), float64(time.Second))
+	a.Equal(int64(2), scope.Snapshot().Counters()[counterKey].Value())
+
+	// or fired right away, subject to the backoff floor
+	handler.cronMisfireFireImmediately = true
+	a.Equal(5*time.Second, handler.getCronBackoff())
+	a.Equal(int64(3), scope.Snapshot().Counters()[counterKey].Value())
+
+	// not a cron workflow
+	executionInfo.CronSchedule = ""
+	a.Equal(backoff.NoBackoff, handler.getCronBackoff())
+	a.Equal(int64(3), scope.Snapshot().Counters()[counterKey].Value())
+}
+
+func Test_GetDecisionTypeMetricsWeight(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(int64(1), getDecisionTypeMetricsWeight(1.0))
+	a.Equal(int64(1), getDecisionTypeMetricsWeight(2.0))
+	a.Equal(int64(0), getDecisionTypeMetricsWeight(0))
+	a.Equal(int64(0), getDecisionTypeMetricsWeight(-1))
+
+	// sampled calls are weighted by the inverse of the sample rate
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		weight := getDecisionTypeMetricsWeight(0.1)
+		a.Contains([]int64{0, 10}, weight)
+		if weight != 0 {
+			sampled++
+		}
+	}
+	a.True(sampled > 0 && sampled < 1000)
+}
+
+func Test_EmitDecisionTypeCounter(t *testing.T) {
+	a := assert.New(t)
+	scope := tally.NewTestScope("test", nil)
+	handler := &decisionTaskHandlerImpl{
+		metricsClient:             metrics.NewClient(scope, metrics.History),
+		decisionTypeMetricsWeight: 0,
+	}
+	counterKey := "test.start_timer_decision+operation=RespondDecisionTaskCompleted"
+
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeStartTimerCounter)
+	a.NotContains(scope.Snapshot().Counters(), counterKey)
+
+	handler.decisionTypeMetricsWeight = 10
+	handler.emitDecisionTypeCounter(metrics.DecisionTypeStartTimerCounter)
+	a.Equal(int64(10), scope.Snapshot().Counters()[counterKey].Value())
+}
@@ -70,6 +70,9 @@ type (
 		historyEventNotifier  historyEventNotifier
 		publisher             messaging.Producer
 		rateLimiter           tokenbucket.TokenBucket
+		auditSink             AuditSink
+		asyncAuditSink        *asyncAuditSink
+		timeSource            clock.TimeSource
 		service.Service
 	}
 )
@@ -106,6 +109,7 @@ func NewHandler(sVice service.Service, config *Config, shardManager persistence.
 		tokenSerializer:     common.NewJSONTaskTokenSerializer(),
 		rateLimiter:         tokenbucket.NewDynamicTokenBucket(config.RPS, clock.NewRealTimeSource()),
 		publicClient:        publicClient,
+		timeSource:          clock.NewRealTimeSource(),
 	}
 
 	// prevent us from trying to serve requests before shard controller is started and ready
@@ -113,6 +117,18 @@ func NewHandler(sVice service.Service, config *Config, shardManager persistence.
 	return handler
 }
 
+// SetAuditSink sets the sink receiving the audit entries of administrative operations in place of the default
+// logging sink, must be called before Start()
+func (h *Handler) SetAuditSink(sink AuditSink) {
+	h.auditSink = sink
+}
+
+// SetTimeSource sets the clock of the shards owned by this host in place of the real time, must be called before
+// Start()
+func (h *Handler) SetTimeSource(timeSource clock.TimeSource) {
+	h.timeSource = timeSource
+}
+
 // RegisterHandler register this handler, must be called before Start()
 func (h *Handler) RegisterHandler() {
 	h.Service.GetDispatcher().Register(historyserviceserver.New(h))
@@ -153,11 +169,17 @@ func (h *Handler) Start() error {
 	h.domainCache = cache.NewDomainCache(h.metadataMgr, h.GetClusterMetadata(), h.GetMetricsClient(), h.GetLogger())
 	h.domainCache.Start()
 	h.controller = newShardController(h.Service, h.GetHostInfo(), hServiceResolver, h.shardManager, h.historyMgr, h.historyV2Mgr,
-		h.domainCache, h.executionMgrFactory, h, h.config, h.GetLogger(), h.GetMetricsClient())
+		h.domainCache, h.executionMgrFactory, h, h.config, h.GetLogger(), h.GetMetricsClient(), h.timeSource)
 	h.metricsClient = h.GetMetricsClient()
 	h.historyEventNotifier = newHistoryEventNotifier(h.GetMetricsClient(), h.config.GetShardID)
 	// events notifier must starts before controller
 	h.historyEventNotifier.Start()
+	auditSink := h.auditSink
+	if auditSink == nil {
+		auditSink = NewLoggingAuditSink(h.GetLogger())
+	}
+	h.asyncAuditSink = newAsyncAuditSink(auditSink, h.GetMetricsClient())
+	h.asyncAuditSink.Start()
 	h.controller.Start()
 	h.startWG.Done()
 	return nil
@@ -177,12 +199,13 @@ func (h *Handler) Stop() {
 	h.visibilityMgr.Close()
 	h.Service.Stop()
 	h.historyEventNotifier.Stop()
+	h.asyncAuditSink.Stop()
 }
 
 // CreateEngine is implementation for HistoryEngineFactory used for creating the engine instance for shard
 func (h *Handler) CreateEngine(context ShardContext) Engine {
-	return NewEngineWithShardContext(context, h.visibilityMgr, h.matchingServiceClient, h.historyServiceClient,
-		h.publicClient, h.historyEventNotifier, h.publisher, h.config)
+	return NewEngineWithShardContext(context, h.metadataMgr, h.visibilityMgr, h.matchingServiceClient, h.historyServiceClient,
+		h.publicClient, h.historyEventNotifier, h.publisher, h.config, h.asyncAuditSink)
 }
 
 // Health is for health check
@@ -638,6 +661,14 @@ func (h *Handler) DescribeHistoryHost(ctx context.Context,
 	return resp, nil
 }
 
+// DescribeArchivalBacklog returns per domain the number of closed workflows pending archival on the shards owned by
+// this host, for operational dashboards. It is read only and shards which are not started yet are skipped.
+func (h *Handler) DescribeArchivalBacklog(ctx context.Context) (*HostArchivalBacklog, error) {
+	h.startWG.Wait()
+
+	return h.controller.describeArchivalBacklog(), nil
+}
+
 // DescribeMutableState - returns the internal analysis of workflow execution state
 func (h *Handler) DescribeMutableState(ctx context.Context,
 	request *hist.DescribeMutableStateRequest) (resp *hist.DescribeMutableStateResponse, retError error) {
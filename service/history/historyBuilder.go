@@ -367,9 +367,23 @@ func (b *historyBuilder) AddMarkerRecordedEvent(decisionCompletedEventID int64,
 	return b.addEventToHistory(event)
 }
 
+func (b *historyBuilder) AddUpsertWorkflowMemoEvent(decisionCompletedEventID int64,
+	attributes *workflow.UpsertWorkflowMemoDecisionAttributes) *workflow.HistoryEvent {
+	event := b.newUpsertWorkflowMemoEvent(decisionCompletedEventID, attributes)
+
+	return b.addEventToHistory(event)
+}
+
 func (b *historyBuilder) AddWorkflowExecutionSignaledEvent(
-	signalName string, input []byte, identity string) *workflow.HistoryEvent {
-	event := b.newWorkflowExecutionSignaledEvent(signalName, input, identity)
+	signalName string, input []byte, identity string, header *workflow.Header, expirationTimestamp int64) *workflow.HistoryEvent {
+	event := b.newWorkflowExecutionSignaledEvent(signalName, input, identity, header, expirationTimestamp)
+
+	return b.addEventToHistory(event)
+}
+
+func (b *historyBuilder) AddWorkflowExecutionTimeoutExtendedEvent(
+	timeoutSeconds int32, identity string) *workflow.HistoryEvent {
+	event := b.newWorkflowExecutionTimeoutExtendedEvent(timeoutSeconds, identity)
 
 	return b.addEventToHistory(event)
 }
@@ -576,6 +590,8 @@ func (b *historyBuilder) newActivityTaskScheduledEvent(decisionTaskCompletedEven
 	attributes.HeartbeatTimeoutSeconds = common.Int32Ptr(common.Int32Default(scheduleAttributes.HeartbeatTimeoutSeconds))
 	attributes.DecisionTaskCompletedEventId = common.Int64Ptr(decisionTaskCompletedEventID)
 	attributes.RetryPolicy = scheduleAttributes.RetryPolicy
+	attributes.DispatchOnly = scheduleAttributes.DispatchOnly
+	attributes.ScheduleDelaySeconds = scheduleAttributes.ScheduleDelaySeconds
 	historyEvent.ActivityTaskScheduledEventAttributes = attributes
 
 	return historyEvent
@@ -667,17 +683,32 @@ func (b *historyBuilder) newTimeoutWorkflowExecutionEvent() *workflow.HistoryEve
 }
 
 func (b *historyBuilder) newWorkflowExecutionSignaledEvent(
-	signalName string, input []byte, identity string) *workflow.HistoryEvent {
+	signalName string, input []byte, identity string, header *workflow.Header, expirationTimestamp int64) *workflow.HistoryEvent {
 	historyEvent := b.msBuilder.CreateNewHistoryEvent(workflow.EventTypeWorkflowExecutionSignaled)
 	attributes := &workflow.WorkflowExecutionSignaledEventAttributes{}
 	attributes.SignalName = common.StringPtr(signalName)
 	attributes.Input = input
 	attributes.Identity = common.StringPtr(identity)
+	attributes.Header = header
+	if expirationTimestamp > 0 {
+		attributes.ExpirationTimestamp = common.Int64Ptr(expirationTimestamp)
+	}
 	historyEvent.WorkflowExecutionSignaledEventAttributes = attributes
 
 	return historyEvent
 }
 
+func (b *historyBuilder) newWorkflowExecutionTimeoutExtendedEvent(
+	timeoutSeconds int32, identity string) *workflow.HistoryEvent {
+	historyEvent := b.msBuilder.CreateNewHistoryEvent(workflow.EventTypeWorkflowExecutionTimeoutExtended)
+	attributes := &workflow.WorkflowExecutionTimeoutExtendedEventAttributes{}
+	attributes.ExecutionStartToCloseTimeoutSeconds = common.Int32Ptr(timeoutSeconds)
+	attributes.Identity = common.StringPtr(identity)
+	historyEvent.WorkflowExecutionTimeoutExtendedEventAttributes = attributes
+
+	return historyEvent
+}
+
 func (b *historyBuilder) newWorkflowExecutionTerminatedEvent(
 	reason string, details []byte, identity string) *workflow.HistoryEvent {
 	historyEvent := b.msBuilder.CreateNewHistoryEvent(workflow.EventTypeWorkflowExecutionTerminated)
@@ -703,6 +734,17 @@ func (b *historyBuilder) newMarkerRecordedEventAttributes(decisionTaskCompletedE
 	return historyEvent
 }
 
+func (b *historyBuilder) newUpsertWorkflowMemoEvent(decisionTaskCompletedEventID int64,
+	request *workflow.UpsertWorkflowMemoDecisionAttributes) *workflow.HistoryEvent {
+	historyEvent := b.msBuilder.CreateNewHistoryEvent(workflow.EventTypeUpsertWorkflowMemo)
+	attributes := &workflow.UpsertWorkflowMemoEventAttributes{}
+	attributes.DecisionTaskCompletedEventId = common.Int64Ptr(decisionTaskCompletedEventID)
+	attributes.Memo = request.Memo
+	historyEvent.UpsertWorkflowMemoEventAttributes = attributes
+
+	return historyEvent
+}
+
 func (b *historyBuilder) newWorkflowExecutionCancelRequestedEvent(cause string,
 	request *h.RequestCancelWorkflowExecutionRequest) *workflow.HistoryEvent {
 	event := b.msBuilder.CreateNewHistoryEvent(workflow.EventTypeWorkflowExecutionCancelRequested)
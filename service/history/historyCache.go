@@ -22,6 +22,7 @@ package history
 
 import (
 	"context"
+	"strconv"
 	"sync/atomic"
 
 	"github.com/pborman/uuid"
@@ -126,9 +127,8 @@ func (c *historyCache) getAndCreateWorkflowExecutionWithTimeout(ctx context.Cont
 			return nil, nil, nil, false, err
 		}
 		releaseFunc = c.makeReleaseFunc(key, cacheNotReleased, contextFromCache)
-	} else {
-		c.metricsClient.IncCounter(metrics.HistoryCacheGetAndCreateScope, metrics.CacheMissCounter)
 	}
+	c.emitCacheAccessMetrics(metrics.HistoryCacheGetAndCreateScope, domainID, cacheHit)
 
 	// Note, the one loaded from DB is not put into cache and don't affect any behavior
 	contextFromDB := newWorkflowExecutionContext(domainID, execution, c.shard, c.executionManager, c.logger)
@@ -154,8 +154,8 @@ func (c *historyCache) getOrCreateWorkflowExecutionWithTimeout(ctx context.Conte
 
 	key := definition.NewWorkflowIdentifier(domainID, execution.GetWorkflowId(), execution.GetRunId())
 	workflowCtx, cacheHit := c.Get(key).(workflowExecutionContext)
+	c.emitCacheAccessMetrics(metrics.HistoryCacheGetOrCreateScope, domainID, cacheHit)
 	if !cacheHit {
-		c.metricsClient.IncCounter(metrics.HistoryCacheGetOrCreateScope, metrics.CacheMissCounter)
 		// Let's create the workflow execution workflowCtx
 		workflowCtx = newWorkflowExecutionContext(domainID, execution, c.shard, c.executionManager, c.logger)
 		elem, err := c.PutIfNotExist(key, workflowCtx)
@@ -180,6 +180,24 @@ func (c *historyCache) getOrCreateWorkflowExecutionWithTimeout(ctx context.Conte
 	return workflowCtx, releaseFunc, nil
 }
 
+// emitCacheAccessMetrics reports the cache hit or miss tagged by shard and domain, along with the current number
+// of entries in the cache, so the hit ratio can be tracked per shard when sizing the cache
+func (c *historyCache) emitCacheAccessMetrics(scope int, domainID string, cacheHit bool) {
+	shardTag := metrics.ShardTag(strconv.Itoa(c.shard.GetShardID()))
+	domainTag := metrics.DomainUnknownTag()
+	if entry, err := c.shard.GetDomainCache().GetDomainByID(domainID); err == nil && entry != nil && entry.GetInfo() != nil {
+		domainTag = metrics.DomainTag(entry.GetInfo().Name)
+	}
+	scopeWithDomain := c.metricsClient.Scope(scope, shardTag, domainTag)
+	if cacheHit {
+		scopeWithDomain.IncCounter(metrics.CacheHitCounter)
+	} else {
+		scopeWithDomain.IncCounter(metrics.CacheMissCounter)
+	}
+	// the cache is shared by all domains of the shard
+	c.metricsClient.Scope(scope, shardTag).UpdateGauge(metrics.CacheSize, float64(c.Size()))
+}
+
 func (c *historyCache) makeReleaseFunc(key definition.WorkflowIdentifier, status int32, context workflowExecutionContext) func(error) {
 	return func(err error) {
 		if atomic.CompareAndSwapInt32(&status, cacheNotReleased, cacheReleased) {
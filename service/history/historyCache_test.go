@@ -26,12 +26,15 @@ import (
 	"testing"
 
 	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
 	workflow "github.com/uber/cadence/.gen/go/shared"
 	"github.com/uber/cadence/client"
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/loggerimpl"
 	"github.com/uber/cadence/common/messaging"
@@ -54,6 +57,7 @@ type (
 		mockProducer        *mocks.KafkaProducer
 		mockMessagingClient messaging.Client
 		mockClientBean      *client.MockClientBean
+		mockDomainCache     *cache.DomainCacheMock
 		mockService         service.Service
 		mockShard           *shardContextImpl
 		cache               *historyCache
@@ -83,6 +87,10 @@ func (s *historyCacheSuite) SetupTest() {
 	s.mockMessagingClient = mocks.NewMockMessagingClient(s.mockProducer, nil)
 	metricsClient := metrics.NewClient(tally.NoopScope, metrics.History)
 	s.mockClientBean = &client.MockClientBean{}
+	s.mockDomainCache = &cache.DomainCacheMock{}
+	s.mockDomainCache.On("GetDomainByID", mock.Anything).Return(
+		cache.NewLocalDomainCacheEntryForTest(&persistence.DomainInfo{Name: "test_domain"}, nil, cluster.TestCurrentClusterName, nil), nil,
+	).Maybe()
 	s.mockService = service.NewTestService(s.mockClusterMetadata, s.mockMessagingClient, metricsClient, s.mockClientBean)
 	s.mockShard = &shardContextImpl{
 		service:                   s.mockService,
@@ -95,6 +103,7 @@ func (s *historyCacheSuite) SetupTest() {
 		config:                    NewDynamicConfigForTest(),
 		logger:                    s.logger,
 		metricsClient:             metrics.NewClient(tally.NoopScope, metrics.History),
+		domainCache:               s.mockDomainCache,
 	}
 	s.cache = newHistoryCache(s.mockShard)
 
@@ -135,6 +144,31 @@ func (s *historyCacheSuite) TestHistoryCacheBasic() {
 	release(nil)
 }
 
+func (s *historyCacheSuite) TestHistoryCacheHitMissMetrics() {
+	scope := tally.NewTestScope("test", nil)
+	s.mockShard.metricsClient = metrics.NewClient(scope, metrics.History)
+	s.cache = newHistoryCache(s.mockShard)
+
+	domainID := "test_domain_id"
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("some random workflow ID"),
+		RunId:      common.StringPtr(uuid.New()),
+	}
+	_, release, err := s.cache.getOrCreateWorkflowExecution(domainID, execution)
+	s.Nil(err)
+	release(nil)
+	_, release, err = s.cache.getOrCreateWorkflowExecution(domainID, execution)
+	s.Nil(err)
+	release(nil)
+
+	tags := "+cache_type=mutablestate,operation=HistoryCacheGetOrCreate,shard=0"
+	domainTags := "+cache_type=mutablestate,domain=test_domain,operation=HistoryCacheGetOrCreate,shard=0"
+	snapshot := scope.Snapshot()
+	s.Equal(int64(1), snapshot.Counters()["test.cache_miss"+domainTags].Value())
+	s.Equal(int64(1), snapshot.Counters()["test.cache_hit"+domainTags].Value())
+	s.Equal(float64(1), snapshot.Gauges()["test.cache_size"+tags].Value())
+}
+
 func (s *historyCacheSuite) TestHistoryCachePinning() {
 	s.mockShard.GetConfig().HistoryCacheMaxSize = dynamicconfig.GetIntPropertyFn(2)
 	domainID := "test_domain_id"
@@ -21,11 +21,13 @@
 package history
 
 import (
+	"bytes"
+	"compress/gzip"
 	ctx "context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
+	"strconv"
 	"time"
 
 	"github.com/pborman/uuid"
@@ -54,6 +56,11 @@ const (
 	activityCancellationMsgActivityIDUnknown  = "ACTIVITY_ID_UNKNOWN"
 	activityCancellationMsgActivityNotStarted = "ACTIVITY_ID_NOT_STARTED"
 	timerCancellationMsgTimerIDUnknown        = "TIMER_ID_UNKNOWN"
+	workflowTimeoutRepairWindow               = time.Minute
+	archivalBacklogMaxPages                   = 10
+	staleDecisionScanMaxPageSize              = 1000
+	badBinaryResetPageSize                    = 1000
+	badBinaryResetMaxPages                    = 10
 )
 
 type (
@@ -64,6 +71,7 @@ type (
 		historyMgr           persistence.HistoryManager
 		historyV2Mgr         persistence.HistoryV2Manager
 		executionManager     persistence.ExecutionManager
+		metadataMgr          persistence.MetadataManager
 		visibilityMgr        persistence.VisibilityManager
 		txProcessor          transferQueueProcessor
 		timerProcessor       timerQueueProcessor
@@ -79,6 +87,7 @@ type (
 		config               *Config
 		archivalClient       archiver.Client
 		resetor              workflowResetor
+		auditSink            AuditSink
 	}
 
 	// shardContextWrapper wraps ShardContext to notify transferQueueProcessor on new tasks.
@@ -90,15 +99,29 @@ type (
 		replicatorProcessor  queueProcessor
 		historyEventNotifier historyEventNotifier
 	}
+
+	adminOperatorCtxKey string
 )
 
 var _ Engine = (*historyEngineImpl)(nil)
 
+const adminOperatorKey adminOperatorCtxKey = "adminOperator"
+
+// Causes of a failed conditional update attempt in an Update_History_Loop, used to tag ConditionalUpdateFailureCounter
+const (
+	updateFailureCauseConflict        = "conflict"
+	updateFailureCauseStaleState      = "stale_state"
+	updateFailureCauseMaxAttempts     = "max_attempts"
+	updateFailureCauseTransactionSize = "transaction_size"
+)
+
 var (
 	// ErrTaskDiscarded is the error indicating that the timer / transfer task is pending for too long and discarded.
 	ErrTaskDiscarded = errors.New("passive task pending for too long")
 	// ErrTaskRetry is the error indicating that the timer / transfer task should be retried.
 	ErrTaskRetry = errors.New("passive task should retry due to condition in mutable state is not met")
+	// ErrMatchingCircuitBreakerOpen is the error indicating that the transfer task should be retried since matching is unhealthy.
+	ErrMatchingCircuitBreakerOpen = errors.New("matching circuit breaker is open, task should retry later")
 	// ErrDuplicate is exported temporarily for integration test
 	ErrDuplicate = errors.New("Duplicate task, completing it")
 	// ErrConflict is exported temporarily for integration test
@@ -123,6 +146,33 @@ var (
 	ErrBufferedEventsLimitExceeded = &workflow.LimitExceededError{Message: "Exceeded workflow execution limit for buffered events"}
 	// ErrSignalsLimitExceeded is the error indicating limit reached for maximum number of signal events
 	ErrSignalsLimitExceeded = &workflow.LimitExceededError{Message: "Exceeded workflow execution limit for signal events"}
+	// ErrNoAdminPermission is error indicating the operation is only allowed through the admin API
+	ErrNoAdminPermission = &workflow.BadRequestError{Message: "Operation is only allowed through admin API."}
+	// ErrActivityTaskNotStarted is error indicating the activity has not been picked up by a worker yet
+	ErrActivityTaskNotStarted = &workflow.BadRequestError{Message: "Activity task is not started."}
+	// ErrDomainDeleted is error indicating the domain of the workflow has been deleted
+	ErrDomainDeleted = &workflow.EntityNotExistsError{Message: "Domain has been deleted."}
+	// ErrWorkflowNotCompleted is error indicating the workflow has no result yet as it is still running
+	ErrWorkflowNotCompleted = &workflow.BadRequestError{Message: "Workflow execution is still running."}
+	// ErrWorkflowResultExceedsSizeLimit is error indicating the workflow result is too large to be returned, it has
+	// to be read from the workflow history instead
+	ErrWorkflowResultExceedsSizeLimit = &workflow.BadRequestError{Message: "Workflow execution result exceeds size limit."}
+	// ErrMutableStateExportExceedsSizeLimit is error indicating the serialized mutable state is too large to be exported
+	ErrMutableStateExportExceedsSizeLimit = &workflow.BadRequestError{Message: "Serialized mutable state exceeds export size limit."}
+	// ErrSignalWithStartInputExceedsSizeLimit is error indicating the start input and the signal input of a signal with
+	// start request are together too large to be written in the first batch of events
+	ErrSignalWithStartInputExceedsSizeLimit = &workflow.BadRequestError{Message: "Combined size of start input and signal input exceeds size limit."}
+	// ErrGlobalDomainBadBinary is error indicating the bad binaries of a global domain have to be updated through the
+	// frontend, which replicates the domain change to the other clusters
+	ErrGlobalDomainBadBinary = &workflow.BadRequestError{Message: "Bad binaries of a global domain can only be updated through the frontend."}
+	// ErrResetReplayTimeout is error indicating reset workflow gave up replaying history before its deadline
+	ErrResetReplayTimeout = &workflow.ServiceBusyError{Message: "Reset workflow did not finish replaying history in time."}
+	// ErrShardBackpressure is error indicating the shard is too far behind on task processing to start new workflows
+	ErrShardBackpressure = &workflow.ServiceBusyError{Message: "Shard is behind on task processing, please retry later."}
+	// ErrFirstDecisionDelayed is error indicating a start cannot wait for a first decision which is not scheduled on start
+	ErrFirstDecisionDelayed = &workflow.BadRequestError{Message: "Cannot wait for the first decision of a cron or child workflow."}
+	// ErrHistoryBranchInUse is error indicating a history branch cannot be cleaned up as its workflow execution exists
+	ErrHistoryBranchInUse = &workflow.BadRequestError{Message: "History branch is still referenced by a workflow execution."}
 	// ErrEventsAterWorkflowFinish is the error indicating server error trying to write events after workflow finish event
 	ErrEventsAterWorkflowFinish = &workflow.InternalServiceError{Message: "error validating last event being workflow finish event."}
 
@@ -139,6 +189,7 @@ var (
 // NewEngineWithShardContext creates an instance of history engine
 func NewEngineWithShardContext(
 	shard ShardContext,
+	metadataMgr persistence.MetadataManager,
 	visibilityMgr persistence.VisibilityManager,
 	matching matching.Client,
 	historyClient hc.Client,
@@ -146,6 +197,7 @@ func NewEngineWithShardContext(
 	historyEventNotifier historyEventNotifier,
 	publisher messaging.Producer,
 	config *Config,
+	auditSink AuditSink,
 ) Engine {
 	currentClusterName := shard.GetService().GetClusterMetadata().GetCurrentClusterName()
 	shardWrapper := &shardContextWrapper{
@@ -165,6 +217,7 @@ func NewEngineWithShardContext(
 		historyMgr:           historyManager,
 		historyV2Mgr:         historyV2Manager,
 		executionManager:     executionManager,
+		metadataMgr:          metadataMgr,
 		visibilityMgr:        visibilityMgr,
 		tokenSerializer:      common.NewJSONTaskTokenSerializer(),
 		historyCache:         historyCache,
@@ -174,6 +227,7 @@ func NewEngineWithShardContext(
 		historyEventNotifier: historyEventNotifier,
 		config:               config,
 		archivalClient:       archiver.NewClient(shard.GetMetricsClient(), shard.GetLogger(), publicClient, shard.GetConfig().NumArchiveSystemWorkflows, shard.GetConfig().ArchiveRequestRPS),
+		auditSink:            auditSink,
 	}
 
 	txProcessor := newTransferQueueProcessor(shard, historyEngImpl, visibilityMgr, matching, historyClient, logger)
@@ -329,8 +383,12 @@ func (e *historyEngineImpl) createMutableState(clusterMetadata cluster.Metadata,
 	return msBuilder
 }
 
+// generateFirstDecisionTask schedules the first decision of a workflow unless it is a child workflow or its first
+// decision is backed off. The first decision timeout only applies to the decision scheduled here, the first decision
+// of a child workflow (scheduled by the parent) or of a backed off workflow (scheduled by the backoff timer) uses the
+// decision timeout of the workflow
 func (e *historyEngineImpl) generateFirstDecisionTask(domainID string, msBuilder mutableState, parentInfo *h.ParentExecutionInfo,
-	taskListName string, cronBackoffSeconds int32) ([]persistence.Task, *decisionInfo, error) {
+	taskListName string, cronBackoffSeconds int32, firstDecisionTimeoutSeconds int32) ([]persistence.Task, *decisionInfo, error) {
 	di := &decisionInfo{
 		TaskList:        taskListName,
 		Version:         common.EmptyVersion,
@@ -345,7 +403,11 @@ func (e *historyEngineImpl) generateFirstDecisionTask(domainID string, msBuilder
 		transferTasks = append(transferTasks, &persistence.RecordWorkflowStartedTask{})
 		if cronBackoffSeconds == 0 {
 			// DecisionTask is only created when it is not a Child Workflow and no backoff is needed
-			di, err = msBuilder.AddDecisionTaskScheduledEvent()
+			if firstDecisionTimeoutSeconds > 0 {
+				di, err = msBuilder.AddDecisionTaskScheduledEventWithTimeout(firstDecisionTimeoutSeconds)
+			} else {
+				di, err = msBuilder.AddDecisionTaskScheduledEvent()
+			}
 			if err != nil {
 				return nil, nil, &workflow.InternalServiceError{Message: "Failed to add decision scheduled event."}
 			}
@@ -358,6 +420,34 @@ func (e *historyEngineImpl) generateFirstDecisionTask(domainID string, msBuilder
 	return transferTasks, di, nil
 }
 
+// getStartRunID returns the run ID of the workflow being started, a run ID given on the start request is only
+// honored when caller provided run IDs are enabled, which is meant for deterministic tests and replays.
+// The run ID is also the tree ID of the events v2 history, so a run ID which already has a history in the
+// shard is rejected regardless of its workflow ID
+func (e *historyEngineImpl) getStartRunID(domainName string, startRequest *h.StartWorkflowExecutionRequest) (string, error) {
+	if startRequest.RunId == nil {
+		return uuid.New(), nil
+	}
+	if !e.config.EnableCallerProvidedRunID(domainName) {
+		return "", &workflow.BadRequestError{Message: "RunId is not allowed on the start request."}
+	}
+	runID := startRequest.GetRunId()
+	if uuid.Parse(runID) == nil {
+		return "", &workflow.BadRequestError{Message: "Invalid RunId."}
+	}
+	resp, err := e.historyV2Mgr.GetHistoryTree(&persistence.GetHistoryTreeRequest{
+		TreeID:  runID,
+		ShardID: common.IntPtr(e.shard.GetShardID()),
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Branches) != 0 || len(resp.ForkingInProgressBranches) != 0 {
+		return "", &workflow.BadRequestError{Message: "RunId already exists."}
+	}
+	return runID, nil
+}
+
 // StartWorkflowExecution starts a workflow execution
 func (e *historyEngineImpl) StartWorkflowExecution(
 	ctx ctx.Context,
@@ -371,14 +461,36 @@ func (e *historyEngineImpl) StartWorkflowExecution(
 	domainID := domainEntry.GetInfo().ID
 
 	request := startRequest.StartRequest
-	retError = validateStartWorkflowExecutionRequest(request, e.config.MaxIDLengthLimit())
+	retError = validateStartWorkflowExecutionRequest(request, e.config.MaxIDLengthLimit(),
+		e.config.MaxNonRetriableErrorReasonsCount(), e.config.MaxNonRetriableErrorReasonsLength(),
+		common.NewQueryValidator(e.config.ValidSearchAttributes))
 	if retError != nil {
 		return
 	}
+	runID, retError := e.getStartRunID(domainEntry.GetInfo().Name, startRequest)
+	if retError != nil {
+		return
+	}
+	// the first decision of a cron or child workflow is scheduled later, so there is nothing to wait for
+	waitForFirstDecision := startRequest.GetWaitForFirstDecisionScheduled()
+	if waitForFirstDecision && (startRequest.ParentExecutionInfo != nil || startRequest.GetFirstDecisionTaskBackoffSeconds() != 0) {
+		retError = ErrFirstDecisionDelayed
+		return
+	}
+	// a child start is a decision of an existing workflow, rejecting it would make the transfer processor retry
+	// the start child task forever
+	if startRequest.ParentExecutionInfo == nil {
+		if retError = e.checkDomainDraining(domainEntry, metrics.HistoryStartWorkflowExecutionScope); retError != nil {
+			return
+		}
+	}
+	if retError = e.checkShardBackpressure(domainEntry.GetInfo().Name, metrics.HistoryStartWorkflowExecutionScope); retError != nil {
+		return
+	}
 
 	execution := workflow.WorkflowExecution{
 		WorkflowId: request.WorkflowId,
-		RunId:      common.StringPtr(uuid.New()),
+		RunId:      common.StringPtr(runID),
 	}
 	clusterMetadata := e.shard.GetService().GetClusterMetadata()
 	msBuilder := e.createMutableState(clusterMetadata, domainEntry)
@@ -402,7 +514,8 @@ func (e *historyEngineImpl) StartWorkflowExecution(
 	taskList := request.TaskList.GetName()
 	cronBackoffSeconds := startRequest.GetFirstDecisionTaskBackoffSeconds()
 	// Generate first decision task event if not child WF and no first decision task backoff
-	transferTasks, _, retError := e.generateFirstDecisionTask(domainID, msBuilder, startRequest.ParentExecutionInfo, taskList, cronBackoffSeconds)
+	transferTasks, _, retError := e.generateFirstDecisionTask(domainID, msBuilder, startRequest.ParentExecutionInfo, taskList,
+		cronBackoffSeconds, request.GetFirstDecisionTaskStartToCloseTimeoutSeconds())
 	if retError != nil {
 		return
 	}
@@ -447,7 +560,7 @@ func (e *historyEngineImpl) StartWorkflowExecution(
 	prevRunID := ""
 	prevLastWriteVersion := int64(0)
 	retError = context.createWorkflowExecution(
-		msBuilder, e.currentClusterName, createReplicationTask, time.Now(),
+		msBuilder, e.currentClusterName, createReplicationTask, e.shard.GetTimeSource().Now(),
 		transferTasks, replicationTasks, timerTasks,
 		createMode, prevRunID, prevLastWriteVersion,
 	)
@@ -455,10 +568,8 @@ func (e *historyEngineImpl) StartWorkflowExecution(
 		t, ok := retError.(*persistence.WorkflowExecutionAlreadyStartedError)
 		if ok {
 			if t.StartRequestID == *request.RequestId {
-				return &workflow.StartWorkflowExecutionResponse{
-					RunId: common.StringPtr(t.RunID),
-				}, nil
 				// delete history is expected here because duplicate start request will create history with different rid
+				return e.getDuplicateStartResponse(ctx, domainID, request.GetWorkflowId(), t.RunID, waitForFirstDecision)
 			}
 
 			if msBuilder.GetCurrentVersion() < t.LastWriteVersion {
@@ -479,7 +590,7 @@ func (e *historyEngineImpl) StartWorkflowExecution(
 				return
 			}
 			retError = context.createWorkflowExecution(
-				msBuilder, e.currentClusterName, createReplicationTask, time.Now(),
+				msBuilder, e.currentClusterName, createReplicationTask, e.shard.GetTimeSource().Now(),
 				transferTasks, replicationTasks, timerTasks,
 				createMode, prevRunID, prevLastWriteVersion,
 			)
@@ -489,13 +600,39 @@ func (e *historyEngineImpl) StartWorkflowExecution(
 	if retError == nil || persistence.IsTimeoutError(retError) {
 		shouldDeleteHistory = false
 		e.timerProcessor.NotifyNewTimers(e.currentClusterName, e.shard.GetCurrentTime(e.currentClusterName), timerTasks)
+		// the first decision is scheduled within the same batch which creates the workflow, so the next event ID is
+		// known without waiting, also when the create timed out and the caller has to correlate the outcome
 		return &workflow.StartWorkflowExecutionResponse{
-			RunId: execution.RunId,
+			RunId:       execution.RunId,
+			NextEventId: common.Int64Ptr(msBuilder.GetNextEventID()),
 		}, retError
 	}
 	return
 }
 
+// getDuplicateStartResponse returns the response of a start deduplicated by its request ID, the next event ID is only
+// loaded when the caller waits for the first decision to be scheduled
+func (e *historyEngineImpl) getDuplicateStartResponse(ctx ctx.Context, domainID string, workflowID string, runID string,
+	waitForFirstDecision bool) (*workflow.StartWorkflowExecutionResponse, error) {
+
+	resp := &workflow.StartWorkflowExecutionResponse{
+		RunId: common.StringPtr(runID),
+	}
+	if !waitForFirstDecision {
+		return resp, nil
+	}
+
+	mutableStateResp, err := e.getMutableState(ctx, domainID, workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr(workflowID),
+		RunId:      common.StringPtr(runID),
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	resp.NextEventId = mutableStateResp.NextEventId
+	return resp, nil
+}
+
 // GetMutableState retrieves the mutable state of the workflow execution
 func (e *historyEngineImpl) GetMutableState(ctx ctx.Context,
 	request *h.GetMutableStateRequest) (*h.GetMutableStateResponse, error) {
@@ -510,7 +647,7 @@ func (e *historyEngineImpl) GetMutableState(ctx ctx.Context,
 		RunId:      request.Execution.RunId,
 	}
 
-	response, err := e.getMutableState(ctx, domainID, execution)
+	response, err := e.getMutableState(ctx, domainID, execution, request.GetIncludeBranchAncestry())
 	if err != nil {
 		return nil, err
 	}
@@ -533,7 +670,7 @@ func (e *historyEngineImpl) GetMutableState(ctx ctx.Context,
 		defer e.historyEventNotifier.UnwatchHistoryEvent(definition.NewWorkflowIdentifier(domainID, execution.GetWorkflowId(), execution.GetRunId()), subscriberID)
 
 		// check again in case the next event ID is updated
-		response, err = e.getMutableState(ctx, domainID, execution)
+		response, err = e.getMutableState(ctx, domainID, execution, request.GetIncludeBranchAncestry())
 		if err != nil {
 			return nil, err
 		}
@@ -569,8 +706,8 @@ func (e *historyEngineImpl) GetMutableState(ctx ctx.Context,
 	return response, nil
 }
 
-func (e *historyEngineImpl) getMutableState(ctx ctx.Context,
-	domainID string, execution workflow.WorkflowExecution) (retResp *h.GetMutableStateResponse, retError error) {
+func (e *historyEngineImpl) getMutableState(ctx ctx.Context, domainID string, execution workflow.WorkflowExecution,
+	includeBranchAncestry bool) (retResp *h.GetMutableStateResponse, retError error) {
 
 	context, release, retError := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
 	if retError != nil {
@@ -613,9 +750,139 @@ func (e *historyEngineImpl) getMutableState(ctx ctx.Context,
 		}
 	}
 
+	if includeBranchAncestry && msBuilder.GetEventStoreVersion() == persistence.EventStoreVersionV2 {
+		retResp.BranchAncestry, retError = persistence.DeserializeHistoryBranch(msBuilder.GetCurrentBranch())
+		if retError != nil {
+			return
+		}
+	}
+
 	return
 }
 
+// GetCurrentBranch retrieves only the current branch token, event store version and next event ID
+// of the workflow execution, skipping the assembly of the full mutable state response
+func (e *historyEngineImpl) GetCurrentBranch(ctx ctx.Context, domainUUID string,
+	execution workflow.WorkflowExecution) (branchToken []byte, eventStoreVersion int32, nextEventID int64, retError error) {
+
+	domainID, retError := validateDomainUUID(common.StringPtr(domainUUID))
+	if retError != nil {
+		return
+	}
+
+	context, release, retError := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
+	if retError != nil {
+		return
+	}
+	defer func() { release(retError) }()
+
+	msBuilder, retError := context.loadWorkflowExecution()
+	if retError != nil {
+		return
+	}
+
+	return msBuilder.GetCurrentBranch(), msBuilder.GetEventStoreVersion(), msBuilder.GetNextEventID(), nil
+}
+
+// GetCurrentRunID returns the run ID of the current run of a workflow and whether that run is still running,
+// it only reads the current execution record so the mutable state of the run is not loaded
+func (e *historyEngineImpl) GetCurrentRunID(ctx ctx.Context, domainUUID string,
+	workflowID string) (runID string, isRunning bool, retError error) {
+
+	domainID, retError := validateDomainUUID(common.StringPtr(domainUUID))
+	if retError != nil {
+		return
+	}
+	if workflowID == "" {
+		retError = &workflow.BadRequestError{Message: "Missing WorkflowID."}
+		return
+	}
+
+	resp, retError := e.executionManager.GetCurrentExecution(&persistence.GetCurrentExecutionRequest{
+		DomainID:   domainID,
+		WorkflowID: workflowID,
+	})
+	if retError != nil {
+		return
+	}
+
+	isRunning = resp.State == persistence.WorkflowStateCreated || resp.State == persistence.WorkflowStateRunning
+	return resp.RunID, isRunning, nil
+}
+
+// GetRawHistory reads the persisted history events of a run in the range [firstEventID, nextEventID),
+// straight from the history store the run was written to. It is meant for debugging mismatches
+// between the mutable state and the history, a nextEventID of 0 reads up to the end of the history.
+func (e *historyEngineImpl) GetRawHistory(ctx ctx.Context, domainUUID string, execution workflow.WorkflowExecution,
+	firstEventID int64, nextEventID int64, pageSize int, nextPageToken []byte) ([]*workflow.HistoryEvent, []byte, error) {
+
+	domainID, err := validateDomainUUID(common.StringPtr(domainUUID))
+	if err != nil {
+		return nil, nil, err
+	}
+	if pageSize <= 0 || pageSize > defaultHistoryPageSize {
+		pageSize = defaultHistoryPageSize
+	}
+	if firstEventID < common.FirstEventID {
+		firstEventID = common.FirstEventID
+	}
+
+	context, release, err := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
+	if err != nil {
+		return nil, nil, err
+	}
+	msBuilder, err := context.loadWorkflowExecution()
+	if err != nil {
+		release(err)
+		return nil, nil, err
+	}
+	execution.RunId = common.StringPtr(context.getExecution().GetRunId())
+	eventStoreVersion := msBuilder.GetEventStoreVersion()
+	branchToken := msBuilder.GetCurrentBranch()
+	if nextEventID <= 0 || nextEventID > msBuilder.GetNextEventID() {
+		nextEventID = msBuilder.GetNextEventID()
+	}
+	// release the context lock since the rest of logic is only reading the history
+	release(nil)
+
+	return e.readHistoryPage(domainID, execution, eventStoreVersion, branchToken, firstEventID, nextEventID,
+		pageSize, nextPageToken)
+}
+
+// readHistoryPage reads a page of the persisted history events of a run in the range [firstEventID, nextEventID) from
+// the history store the run was written to
+func (e *historyEngineImpl) readHistoryPage(domainID string, execution workflow.WorkflowExecution,
+	eventStoreVersion int32, branchToken []byte, firstEventID int64, nextEventID int64, pageSize int,
+	nextPageToken []byte) ([]*workflow.HistoryEvent, []byte, error) {
+
+	if eventStoreVersion == persistence.EventStoreVersionV2 {
+		response, err := e.historyV2Mgr.ReadHistoryBranch(&persistence.ReadHistoryBranchRequest{
+			BranchToken:   branchToken,
+			MinEventID:    firstEventID,
+			MaxEventID:    nextEventID,
+			PageSize:      pageSize,
+			NextPageToken: nextPageToken,
+			ShardID:       common.IntPtr(e.shard.GetShardID()),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return response.HistoryEvents, response.NextPageToken, nil
+	}
+	response, err := e.historyMgr.GetWorkflowExecutionHistory(&persistence.GetWorkflowExecutionHistoryRequest{
+		DomainID:      domainID,
+		Execution:     execution,
+		FirstEventID:  firstEventID,
+		NextEventID:   nextEventID,
+		PageSize:      pageSize,
+		NextPageToken: nextPageToken,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return response.History.Events, response.NextPageToken, nil
+}
+
 func (e *historyEngineImpl) DescribeMutableState(ctx ctx.Context,
 	request *h.DescribeMutableStateRequest) (retResp *h.DescribeMutableStateResponse, retError error) {
 
@@ -634,6 +901,7 @@ func (e *historyEngineImpl) DescribeMutableState(ctx ctx.Context,
 		return nil, err
 	}
 	defer func() { release(retError) }()
+	e.emitDescribeMutableStateCacheMetrics(domainID, cacheHit)
 	retResp = &h.DescribeMutableStateResponse{}
 
 	if cacheHit && cacheCtx.(*workflowExecutionContextImpl).msBuilder != nil {
@@ -647,6 +915,88 @@ func (e *historyEngineImpl) DescribeMutableState(ctx ctx.Context,
 	return
 }
 
+func (e *historyEngineImpl) emitDescribeMutableStateCacheMetrics(domainID string, cacheHit bool) {
+	domainTag := metrics.DomainUnknownTag()
+	if domainEntry, err := e.shard.GetDomainCache().GetDomainByID(domainID); err == nil {
+		domainTag = metrics.DomainTag(domainEntry.GetInfo().Name)
+	}
+	scope := e.metricsClient.Scope(metrics.HistoryDescribeMutableStateScope, domainTag,
+		metrics.ShardTag(strconv.Itoa(e.shard.GetShardID())))
+	if cacheHit {
+		scope.IncCounter(metrics.CacheHitCounter)
+	} else {
+		scope.IncCounter(metrics.CacheMissCounter)
+	}
+}
+
+// shouldLoadCompletionEventOnDescribe returns whether the close time of a closed workflow has to be read from its
+// completion event, which is the case for workflows closed before the close timestamp was cached in mutable state
+func (e *historyEngineImpl) shouldLoadCompletionEventOnDescribe(
+	domainID string,
+	executionInfo *persistence.WorkflowExecutionInfo,
+) (bool, error) {
+
+	if executionInfo.CloseTimestamp == 0 {
+		return true, nil
+	}
+	domainEntry, err := e.shard.GetDomainCache().GetDomainByID(domainID)
+	if err != nil {
+		return false, err
+	}
+	return e.config.DescribeWorkflowLoadCompletionEvent(domainEntry.GetInfo().Name), nil
+}
+
+// ExportMutableState returns the JSON encoded persistence form of the mutable state of a workflow execution along with
+// its current branch token, optionally gzip compressed, for offline analysis. The export is rejected if the encoded
+// mutable state is larger than the configured export size limit.
+func (e *historyEngineImpl) ExportMutableState(ctx ctx.Context, domainUUID string, execution workflow.WorkflowExecution,
+	compress bool) (retSnapshot *MutableStateSnapshot, retError error) {
+
+	domainID, err := validateDomainUUID(common.StringPtr(domainUUID))
+	if err != nil {
+		return nil, err
+	}
+	domainEntry, err := e.shard.GetDomainCache().GetDomainByID(domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	context, release, err0 := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
+	if err0 != nil {
+		return nil, err0
+	}
+	defer func() { release(retError) }()
+
+	msBuilder, err1 := context.loadWorkflowExecution()
+	if err1 != nil {
+		return nil, err1
+	}
+	data, err := json.Marshal(msBuilder.CopyToPersistence())
+	if err != nil {
+		return nil, err
+	}
+	if compress {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+	}
+
+	if len(data) > e.config.MutableStateExportSizeLimit(domainEntry.GetInfo().Name) {
+		return nil, ErrMutableStateExportExceedsSizeLimit
+	}
+	return &MutableStateSnapshot{
+		MutableState: data,
+		Compressed:   compress,
+		BranchToken:  msBuilder.GetCurrentBranch(),
+	}, nil
+}
+
 func (e *historyEngineImpl) toMutableStateJSON(msb mutableState) (*string, error) {
 	ms := msb.CopyToPersistence()
 
@@ -670,7 +1020,7 @@ func (e *historyEngineImpl) ResetStickyTaskList(ctx ctx.Context, resetRequest *h
 		return nil, err
 	}
 
-	err = e.updateWorkflowExecution(ctx, domainID, *resetRequest.Execution, false, false,
+	err = e.updateWorkflowExecution(ctx, metrics.HistoryResetStickyTaskListScope, domainID, *resetRequest.Execution, false, false,
 		func(msBuilder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error) {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return nil, ErrWorkflowCompleted
@@ -732,7 +1082,8 @@ func (e *historyEngineImpl) DescribeWorkflowExecution(ctx ctx.Context,
 	// each time DescribeWorkflowExecution is called.
 	backoffDuration := time.Duration(0)
 	if executionInfo.HasRetryPolicy && (executionInfo.Attempt > 0) {
-		backoffDuration = time.Duration(float64(executionInfo.InitialInterval)*math.Pow(executionInfo.BackoffCoefficient, float64(executionInfo.Attempt-1))) * time.Second
+		backoffDuration = getWorkflowRetryBackoff(executionInfo.Attempt, executionInfo.InitialInterval,
+			executionInfo.MaximumInterval, executionInfo.BackoffCoefficient)
 	} else if len(executionInfo.CronSchedule) != 0 {
 		backoffDuration = backoff.GetBackoffForNextSchedule(executionInfo.CronSchedule, executionInfo.StartTimestamp)
 	}
@@ -749,11 +1100,22 @@ func (e *historyEngineImpl) DescribeWorkflowExecution(ctx ctx.Context,
 		// for closed workflow
 		closeStatus := getWorkflowExecutionCloseStatus(executionInfo.CloseStatus)
 		result.WorkflowExecutionInfo.CloseStatus = &closeStatus
-		completionEvent, ok := msBuilder.GetCompletionEvent()
-		if !ok {
-			return nil, &workflow.InternalServiceError{Message: "Unable to get workflow completion event."}
+		loadCompletionEvent, err := e.shouldLoadCompletionEventOnDescribe(domainID, executionInfo)
+		if err != nil {
+			return nil, err
+		}
+		if loadCompletionEvent {
+			completionEvent, ok := msBuilder.GetCompletionEvent()
+			if !ok {
+				return nil, &workflow.InternalServiceError{Message: "Unable to get workflow completion event."}
+			}
+			result.WorkflowExecutionInfo.CloseTime = common.Int64Ptr(completionEvent.GetTimestamp())
+		} else {
+			result.WorkflowExecutionInfo.CloseTime = common.Int64Ptr(executionInfo.CloseTimestamp)
 		}
-		result.WorkflowExecutionInfo.CloseTime = common.Int64Ptr(completionEvent.GetTimestamp())
+	}
+	if decisionHeartbeatCount := context.getDecisionHeartbeatCount(); decisionHeartbeatCount > 0 {
+		result.DecisionHeartbeatCount = common.Int32Ptr(int32(decisionHeartbeatCount))
 	}
 
 	if len(msBuilder.GetPendingActivityInfos()) > 0 {
@@ -790,6 +1152,17 @@ func (e *historyEngineImpl) DescribeWorkflowExecution(ctx ctx.Context,
 				if ai.MaximumAttempts != 0 {
 					p.MaximumAttempts = common.Int32Ptr(ai.MaximumAttempts)
 				}
+				if ai.LastFailureReason != "" {
+					p.LastFailureReason = common.StringPtr(ai.LastFailureReason)
+				}
+				// a scheduled activity past its first attempt whose schedule time is still ahead is waiting
+				// for the retry timer, as opposed to one which has never been started
+				retryBackoff := state == workflow.PendingActivityStateScheduled && ai.Attempt > 0 &&
+					ai.ScheduledTime.After(e.shard.GetTimeSource().Now())
+				p.RetryBackoff = common.BoolPtr(retryBackoff)
+				if retryBackoff {
+					p.NextRetryTimestamp = common.Int64Ptr(ai.ScheduledTime.UnixNano())
+				}
 			}
 			result.PendingActivities = append(result.PendingActivities, p)
 		}
@@ -798,6 +1171,64 @@ func (e *historyEngineImpl) DescribeWorkflowExecution(ctx ctx.Context,
 	return result, nil
 }
 
+// GetWorkflowExecutionResult returns the close status of a closed workflow along with the result or failure
+// recorded on its completion event
+func (e *historyEngineImpl) GetWorkflowExecutionResult(ctx ctx.Context, domainUUID string,
+	execution workflow.WorkflowExecution) (retResult *WorkflowExecutionResult, retError error) {
+
+	domainID, err := validateDomainUUID(common.StringPtr(domainUUID))
+	if err != nil {
+		return nil, err
+	}
+	domainEntry, err := e.shard.GetDomainCache().GetDomainByID(domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	context, release, err0 := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
+	if err0 != nil {
+		return nil, err0
+	}
+	defer func() { release(retError) }()
+
+	msBuilder, err1 := context.loadWorkflowExecution()
+	if err1 != nil {
+		return nil, err1
+	}
+	executionInfo := msBuilder.GetExecutionInfo()
+	if executionInfo.State != persistence.WorkflowStateCompleted {
+		return nil, ErrWorkflowNotCompleted
+	}
+
+	completionEvent, ok := msBuilder.GetCompletionEvent()
+	if !ok {
+		return nil, &workflow.InternalServiceError{Message: "Unable to get workflow completion event."}
+	}
+	result := &WorkflowExecutionResult{
+		CloseStatus: getWorkflowExecutionCloseStatus(executionInfo.CloseStatus),
+		CloseTime:   completionEvent.GetTimestamp(),
+	}
+	switch completionEvent.GetEventType() {
+	case workflow.EventTypeWorkflowExecutionCompleted:
+		result.Result = completionEvent.WorkflowExecutionCompletedEventAttributes.Result
+	case workflow.EventTypeWorkflowExecutionFailed:
+		attributes := completionEvent.WorkflowExecutionFailedEventAttributes
+		result.Reason = attributes.Reason
+		result.Details = attributes.Details
+	case workflow.EventTypeWorkflowExecutionTerminated:
+		attributes := completionEvent.WorkflowExecutionTerminatedEventAttributes
+		result.Reason = attributes.Reason
+		result.Details = attributes.Details
+	case workflow.EventTypeWorkflowExecutionCanceled:
+		result.Details = completionEvent.WorkflowExecutionCanceledEventAttributes.Details
+	}
+
+	if len(result.Result)+len(result.Details) > e.config.BlobSizeLimitError(domainEntry.GetInfo().Name) {
+		return nil, ErrWorkflowResultExceedsSizeLimit
+	}
+	return result, nil
+}
+
 func (e *historyEngineImpl) RecordActivityTaskStarted(ctx ctx.Context,
 	request *h.RecordActivityTaskStartedRequest) (*h.RecordActivityTaskStartedResponse, error) {
 
@@ -817,7 +1248,8 @@ func (e *historyEngineImpl) RecordActivityTaskStarted(ctx ctx.Context,
 	}
 
 	response := &h.RecordActivityTaskStartedResponse{}
-	err = e.updateWorkflowExecution(ctx, domainID, execution, false, false,
+	activityTypeName := ""
+	err = e.updateWorkflowExecution(ctx, metrics.HistoryRecordActivityTaskStartedScope, domainID, execution, false, false,
 		func(msBuilder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error) {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return nil, ErrWorkflowCompleted
@@ -869,6 +1301,7 @@ func (e *historyEngineImpl) RecordActivityTaskStarted(ctx ctx.Context,
 			); err != nil {
 				return nil, err
 			}
+			activityTypeName = scheduledEvent.ActivityTaskScheduledEventAttributes.ActivityType.GetName()
 
 			response.StartedTimestamp = common.Int64Ptr(ai.StartedTime.UnixNano())
 			response.Attempt = common.Int64Ptr(int64(ai.Attempt))
@@ -889,6 +1322,10 @@ func (e *historyEngineImpl) RecordActivityTaskStarted(ctx ctx.Context,
 	if err != nil {
 		return nil, err
 	}
+	if activityTypeName != "" {
+		e.emitActivityTypeCounter(metrics.HistoryRecordActivityTaskStartedScope, domainName, activityTypeName,
+			metrics.ActivityTypeStartedCounter)
+	}
 
 	return response, err
 }
@@ -917,6 +1354,19 @@ func (e *historyEngineImpl) RespondDecisionTaskCompleted(
 	return e.decisionHandler.handleDecisionTaskCompleted(ctx, req)
 }
 
+// ReplayDecision replays the decisions of a completed decision task against the mutable state rebuilt from the
+// history preceding it and returns the events they produce next to the recorded ones, for debugging non-determinism.
+// Nothing is persisted. The decisions are recovered from the events they recorded, so the decision task must have been
+// completed and only the decision attributes that are recorded on the events are replayed.
+func (e *historyEngineImpl) ReplayDecision(
+	ctx ctx.Context,
+	domainUUID string,
+	execution workflow.WorkflowExecution,
+	decisionScheduleID int64,
+) (*DecisionReplayResult, error) {
+	return e.decisionHandler.handleDecisionTaskReplay(ctx, domainUUID, execution, decisionScheduleID)
+}
+
 // RespondDecisionTaskFailed fails a decision
 func (e *historyEngineImpl) RespondDecisionTaskFailed(
 	ctx ctx.Context,
@@ -933,6 +1383,7 @@ func (e *historyEngineImpl) RespondActivityTaskCompleted(ctx ctx.Context, req *h
 		return err
 	}
 	domainID := domainEntry.GetInfo().ID
+	domainName := domainEntry.GetInfo().Name
 
 	request := req.CompleteRequest
 	token, err0 := e.tokenSerializer.Deserialize(request.TaskToken)
@@ -945,7 +1396,21 @@ func (e *historyEngineImpl) RespondActivityTaskCompleted(ctx ctx.Context, req *h
 		RunId:      common.StringPtr(token.RunID),
 	}
 
-	return e.updateWorkflowExecution(ctx, domainID, workflowExecution, false, true,
+	sizeLimitError := e.config.BlobSizeLimitError(domainName)
+	sizeLimitErr := common.CheckEventBlobSizeLimit(
+		len(request.Result),
+		e.config.BlobSizeLimitWarn(domainName),
+		sizeLimitError,
+		domainID,
+		token.WorkflowID,
+		token.RunID,
+		e.metricsClient.Scope(metrics.HistoryRespondActivityTaskCompletedScope, metrics.DomainTag(domainName)),
+		e.throttledLogger,
+	)
+
+	activityTypeName := ""
+	activityTypeCounter := metrics.ActivityTypeCompletedCounter
+	err = e.updateWorkflowExecution(ctx, metrics.HistoryRespondActivityTaskCompletedScope, domainID, workflowExecution, false, true,
 		func(msBuilder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error) {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return nil, ErrWorkflowCompleted
@@ -971,13 +1436,39 @@ func (e *historyEngineImpl) RespondActivityTaskCompleted(ctx ctx.Context, req *h
 				(token.ScheduleID != common.EmptyEventID && token.ScheduleAttempt != int64(ai.Attempt)) {
 				return nil, ErrActivityTaskNotFound
 			}
+			// the activity info is gone once the activity is closed, so look up the activity type beforehand
+			activityTypeName = getActivityTypeName(msBuilder, scheduleID)
+
+			if sizeLimitErr != nil {
+				// result exceeds blob size limit, record it as a non retryable failure instead
+				failRequest := &workflow.RespondActivityTaskFailedRequest{
+					TaskToken: request.TaskToken,
+					Reason:    common.StringPtr(common.FailureReasonActivityResultSizeExceedsLimit),
+					Details:   request.Result[0:sizeLimitError],
+					Identity:  request.Identity,
+				}
+				if _, err := msBuilder.AddActivityTaskFailedEvent(scheduleID, ai.StartedID, failRequest); err != nil {
+					// Unable to add ActivityTaskFailed event to history
+					return nil, &workflow.InternalServiceError{Message: "Unable to add ActivityTaskFailed event to history."}
+				}
+				activityTypeCounter = metrics.ActivityTypeFailedCounter
+				return nil, nil
+			}
 
 			if _, err := msBuilder.AddActivityTaskCompletedEvent(scheduleID, ai.StartedID, request); err != nil {
 				// Unable to add ActivityTaskCompleted event to history
 				return nil, &workflow.InternalServiceError{Message: "Unable to add ActivityTaskCompleted event to history."}
 			}
+			activityTypeCounter = metrics.ActivityTypeCompletedCounter
 			return nil, nil
 		})
+	if err != nil {
+		return err
+	}
+
+	e.emitActivityTypeCounter(metrics.HistoryRespondActivityTaskCompletedScope, domainName, activityTypeName,
+		activityTypeCounter)
+	return nil
 }
 
 // RespondActivityTaskFailed completes an activity task failure.
@@ -988,6 +1479,7 @@ func (e *historyEngineImpl) RespondActivityTaskFailed(ctx ctx.Context, req *h.Re
 		return err
 	}
 	domainID := domainEntry.GetInfo().ID
+	domainName := domainEntry.GetInfo().Name
 
 	request := req.FailedRequest
 	token, err0 := e.tokenSerializer.Deserialize(request.TaskToken)
@@ -1000,7 +1492,26 @@ func (e *historyEngineImpl) RespondActivityTaskFailed(ctx ctx.Context, req *h.Re
 		RunId:      common.StringPtr(token.RunID),
 	}
 
-	return e.updateWorkflowExecutionWithAction(ctx, domainID, workflowExecution,
+	sizeLimitError := e.config.BlobSizeLimitError(domainName)
+	if err := common.CheckEventBlobSizeLimit(
+		len(request.Details),
+		e.config.BlobSizeLimitWarn(domainName),
+		sizeLimitError,
+		domainID,
+		token.WorkflowID,
+		token.RunID,
+		e.metricsClient.Scope(metrics.HistoryRespondActivityTaskFailedScope, metrics.DomainTag(domainName)),
+		e.throttledLogger,
+	); err != nil {
+		// details exceeds blob size limit, truncate the details and put a specific error reason
+		request.Reason = common.StringPtr(common.FailureReasonFailureDetailsExceedsLimit)
+		request.Details = request.Details[0:sizeLimitError]
+	}
+
+	activityTypeName := ""
+	retryBudgetExhausted := false
+	globalNonRetryable := false
+	err = e.updateWorkflowExecutionWithAction(ctx, metrics.HistoryRespondActivityTaskFailedScope, domainID, workflowExecution,
 		func(msBuilder mutableState, tBuilder *timerBuilder) (*updateWorkflowAction, error) {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return nil, ErrWorkflowCompleted
@@ -1026,9 +1537,20 @@ func (e *historyEngineImpl) RespondActivityTaskFailed(ctx ctx.Context, req *h.Re
 				(token.ScheduleID != common.EmptyEventID && token.ScheduleAttempt != int64(ai.Attempt)) {
 				return nil, ErrActivityTaskNotFound
 			}
+			activityTypeName = getActivityTypeName(msBuilder, scheduleID)
 
 			postActions := &updateWorkflowAction{}
-			retryTask := msBuilder.CreateActivityRetryTimer(ai, req.FailedRequest.GetReason())
+			var retryTask persistence.Task
+			globalNonRetryable = ai.HasRetryPolicy && isGlobalNonRetryableActivityError(
+				e.config.GlobalNonRetryableActivityErrors(domainName),
+				req.FailedRequest.GetReason(),
+			)
+			if !globalNonRetryable {
+				retryBudgetExhausted = activityRetryBudgetExhausted(ai, req.FailedRequest.GetReason(), e.shard.GetTimeSource().Now())
+			}
+			if !globalNonRetryable && !retryBudgetExhausted {
+				retryTask = msBuilder.CreateActivityRetryTimer(ai, req.FailedRequest.GetReason())
+			}
 			if retryTask != nil {
 				// need retry
 				postActions.timerTasks = append(postActions.timerTasks, retryTask)
@@ -1043,6 +1565,25 @@ func (e *historyEngineImpl) RespondActivityTaskFailed(ctx ctx.Context, req *h.Re
 
 			return postActions, nil
 		})
+	if err != nil {
+		return err
+	}
+
+	e.emitActivityTypeCounter(metrics.HistoryRespondActivityTaskFailedScope, domainName, activityTypeName,
+		metrics.ActivityTypeFailedCounter)
+	if retryBudgetExhausted {
+		e.metricsClient.Scope(
+			metrics.HistoryRespondActivityTaskFailedScope,
+			metrics.DomainTag(domainName),
+		).IncCounter(metrics.ActivityRetryBudgetExhaustedCounter)
+	}
+	if globalNonRetryable {
+		e.metricsClient.Scope(
+			metrics.HistoryRespondActivityTaskFailedScope,
+			metrics.DomainTag(domainName),
+		).IncCounter(metrics.ActivityGlobalNonRetryableErrorCounter)
+	}
+	return nil
 }
 
 // RespondActivityTaskCanceled completes an activity task failure.
@@ -1053,6 +1594,7 @@ func (e *historyEngineImpl) RespondActivityTaskCanceled(ctx ctx.Context, req *h.
 		return err
 	}
 	domainID := domainEntry.GetInfo().ID
+	domainName := domainEntry.GetInfo().Name
 
 	request := req.CancelRequest
 	token, err0 := e.tokenSerializer.Deserialize(request.TaskToken)
@@ -1065,7 +1607,8 @@ func (e *historyEngineImpl) RespondActivityTaskCanceled(ctx ctx.Context, req *h.
 		RunId:      common.StringPtr(token.RunID),
 	}
 
-	return e.updateWorkflowExecution(ctx, domainID, workflowExecution, false, true,
+	activityTypeName := ""
+	err = e.updateWorkflowExecution(ctx, metrics.HistoryRespondActivityTaskCanceledScope, domainID, workflowExecution, false, true,
 		func(msBuilder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error) {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return nil, ErrWorkflowCompleted
@@ -1091,6 +1634,7 @@ func (e *historyEngineImpl) RespondActivityTaskCanceled(ctx ctx.Context, req *h.
 				(token.ScheduleID != common.EmptyEventID && token.ScheduleAttempt != int64(ai.Attempt)) {
 				return nil, ErrActivityTaskNotFound
 			}
+			activityTypeName = getActivityTypeName(msBuilder, scheduleID)
 
 			if _, err := msBuilder.AddActivityTaskCanceledEvent(
 				scheduleID,
@@ -1104,61 +1648,210 @@ func (e *historyEngineImpl) RespondActivityTaskCanceled(ctx ctx.Context, req *h.
 
 			return nil, nil
 		})
+	if err != nil {
+		return err
+	}
 
+	e.emitActivityTypeCounter(metrics.HistoryRespondActivityTaskCanceledScope, domainName, activityTypeName,
+		metrics.ActivityTypeCanceledCounter)
+	return nil
 }
 
-// RecordActivityTaskHeartbeat records an hearbeat for a task.
-// This method can be used for two purposes.
-// - For reporting liveness of the activity.
-// - For reporting progress of the activity, this can be done even if the liveness is not configured.
-func (e *historyEngineImpl) RecordActivityTaskHeartbeat(ctx ctx.Context,
-	req *h.RecordActivityTaskHeartbeatRequest) (*workflow.RecordActivityTaskHeartbeatResponse, error) {
+// NewAdminOperationContext returns a context marking the call as an admin operation done by the given operator,
+// it is required by engine APIs which bypass the normal workflow checks
+func NewAdminOperationContext(parent ctx.Context, operator string) ctx.Context {
+	return ctx.WithValue(parent, adminOperatorKey, operator)
+}
 
-	domainEntry, err := e.getActiveDomainEntry(req.DomainUUID)
-	if err != nil {
-		return nil, err
-	}
-	domainID := domainEntry.GetInfo().ID
+func getAdminOperator(context ctx.Context) (string, bool) {
+	operator, ok := context.Value(adminOperatorKey).(string)
+	return operator, ok && operator != ""
+}
 
-	request := req.HeartbeatRequest
-	token, err0 := e.tokenSerializer.Deserialize(request.TaskToken)
-	if err0 != nil {
-		return nil, ErrDeserializingToken
+// ForceCompleteActivity completes a started activity on behalf of its worker, this is meant for operators
+// to unblock workflows whose activity worker died and the activity will not time out any time soon.
+func (e *historyEngineImpl) ForceCompleteActivity(ctx ctx.Context, domainUUID string,
+	execution workflow.WorkflowExecution, scheduleID int64, result []byte) error {
+
+	operator, ok := getAdminOperator(ctx)
+	if !ok {
+		return ErrNoAdminPermission
 	}
 
-	workflowExecution := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr(token.WorkflowID),
-		RunId:      common.StringPtr(token.RunID),
+	domainEntry, err := e.getActiveDomainEntry(common.StringPtr(domainUUID))
+	if err != nil {
+		return err
 	}
+	domainID := domainEntry.GetInfo().ID
 
-	var cancelRequested bool
-	err = e.updateWorkflowExecution(ctx, domainID, workflowExecution, false, false,
+	// the update may be retried on conflicts, so the operation is only logged and audited once it succeeded
+	startedID := common.EmptyEventID
+	err = e.updateWorkflowExecution(ctx, metrics.HistoryForceCompleteActivityScope, domainID, execution, false, true,
 		func(msBuilder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error) {
 			if !msBuilder.IsWorkflowExecutionRunning() {
-				e.logger.Debug("Heartbeat failed")
 				return nil, ErrWorkflowCompleted
 			}
 
-			scheduleID := token.ScheduleID
-			if scheduleID == common.EmptyEventID { // client call RecordActivityHeartbeatByID, so get scheduleID by activityID
-				scheduleID, err0 = getScheduleID(token.ActivityID, msBuilder)
-				if err0 != nil {
-					return nil, err0
-				}
-			}
 			ai, isRunning := msBuilder.GetActivityInfo(scheduleID)
-
-			// First check to see if cache needs to be refreshed as we could potentially have stale workflow execution in
-			// some extreme cassandra failure cases.
 			if !isRunning && scheduleID >= msBuilder.GetNextEventID() {
-				e.metricsClient.IncCounter(metrics.HistoryRecordActivityTaskHeartbeatScope, metrics.StaleMutableStateCounter)
 				return nil, ErrStaleState
 			}
-
-			if !isRunning || ai.StartedID == common.EmptyEventID ||
-				(token.ScheduleID != common.EmptyEventID && token.ScheduleAttempt != int64(ai.Attempt)) {
-				e.logger.Debug(fmt.Sprintf("Activity HeartBeat: scheduleEventID: %v, ActivityInfo: %+v, Exist: %v", scheduleID, ai,
-					isRunning))
+			if !isRunning {
+				return nil, ErrActivityTaskNotFound
+			}
+			if ai.StartedID == common.EmptyEventID {
+				return nil, ErrActivityTaskNotStarted
+			}
+
+			if _, err := msBuilder.AddActivityTaskCompletedEvent(scheduleID, ai.StartedID, &workflow.RespondActivityTaskCompletedRequest{
+				Result:   result,
+				Identity: common.StringPtr(operator),
+			}); err != nil {
+				// Unable to add ActivityTaskCompleted event to history
+				return nil, &workflow.InternalServiceError{Message: "Unable to add ActivityTaskCompleted event to history."}
+			}
+			startedID = ai.StartedID
+			return nil, nil
+		})
+	if err != nil {
+		return err
+	}
+
+	e.logger.Info("Activity force completed by admin operation.",
+		tag.Operator(operator),
+		tag.WorkflowDomainID(domainID),
+		tag.WorkflowID(execution.GetWorkflowId()),
+		tag.WorkflowRunID(execution.GetRunId()),
+		tag.WorkflowScheduleID(scheduleID),
+		tag.WorkflowStartedID(startedID))
+	e.recordAudit(ctx, AuditOperationForceCompleteActivity, domainID, execution, "")
+	return nil
+}
+
+// ExtendWorkflowTimeout extends the execution timeout of a running workflow by additionalSeconds, this is meant for
+// operators to keep a workflow alive past its original deadline. The new timeout is capped by
+// MaxWorkflowExecutionTimeout, extending a workflow already at the cap is a noop. The extension is recorded as a
+// WorkflowExecutionTimeoutExtended event so that it is replicated to the standby clusters. The event is part of the
+// decider visible history, the workers of the domain must run a client release which knows this event type before
+// the operation is used, older clients fail every decision task of the workflow while replaying it.
+func (e *historyEngineImpl) ExtendWorkflowTimeout(ctx ctx.Context, domainUUID string,
+	execution workflow.WorkflowExecution, additionalSeconds int32) error {
+
+	operator, ok := getAdminOperator(ctx)
+	if !ok {
+		return ErrNoAdminPermission
+	}
+	if additionalSeconds <= 0 {
+		return &workflow.BadRequestError{Message: "AdditionalSeconds must be positive."}
+	}
+
+	domainEntry, err := e.getActiveDomainEntry(common.StringPtr(domainUUID))
+	if err != nil {
+		return err
+	}
+	domainID := domainEntry.GetInfo().ID
+	maxTimeoutSeconds := int64(e.config.MaxWorkflowExecutionTimeout(domainEntry.GetInfo().Name) / time.Second)
+
+	var timeoutTask *persistence.WorkflowTimeoutTask
+	err = e.updateWorkflowExecutionWithAction(ctx, metrics.HistoryExtendWorkflowTimeoutScope, domainID, execution,
+		func(msBuilder mutableState, tBuilder *timerBuilder) (*updateWorkflowAction, error) {
+			timeoutTask = nil
+			if !msBuilder.IsWorkflowExecutionRunning() {
+				return nil, ErrWorkflowCompleted
+			}
+
+			executionInfo := msBuilder.GetExecutionInfo()
+			if !executionInfo.ExpirationTime.IsZero() && !getWorkflowTimeoutTime(msBuilder).Before(executionInfo.ExpirationTime) {
+				// a larger timeout would not move the deadline
+				return nil, &workflow.BadRequestError{Message: "Workflow timeout is capped by its retry expiration time."}
+			}
+
+			oldTimeout := executionInfo.WorkflowTimeout
+			newTimeout := int64(oldTimeout) + int64(additionalSeconds)
+			if newTimeout > maxTimeoutSeconds {
+				newTimeout = maxTimeoutSeconds
+			}
+			if newTimeout <= int64(oldTimeout) {
+				return &updateWorkflowAction{noop: true}, nil
+			}
+
+			if _, err := msBuilder.AddWorkflowExecutionTimeoutExtendedEvent(int32(newTimeout), operator); err != nil {
+				return nil, &workflow.InternalServiceError{Message: "Unable to extend workflow timeout."}
+			}
+
+			// the timer scheduled for the old timeout stays in the queue and is dropped when it fires before the new one
+			timeoutTask = &persistence.WorkflowTimeoutTask{VisibilityTimestamp: getWorkflowTimeoutTime(msBuilder)}
+			return &updateWorkflowAction{timerTasks: []persistence.Task{timeoutTask}}, nil
+		})
+	if err != nil {
+		return err
+	}
+	if timeoutTask == nil {
+		return nil
+	}
+
+	e.logger.Info("Workflow timeout extended by admin operation.",
+		tag.Operator(operator),
+		tag.WorkflowDomainID(domainID),
+		tag.WorkflowID(execution.GetWorkflowId()),
+		tag.WorkflowRunID(execution.GetRunId()),
+		tag.Timestamp(timeoutTask.VisibilityTimestamp))
+	e.recordAudit(ctx, AuditOperationExtendWorkflowTimeout, domainID, execution, "")
+	return nil
+}
+
+// RecordActivityTaskHeartbeat records an hearbeat for a task.
+// This method can be used for two purposes.
+// - For reporting liveness of the activity.
+// - For reporting progress of the activity, this can be done even if the liveness is not configured.
+func (e *historyEngineImpl) RecordActivityTaskHeartbeat(ctx ctx.Context,
+	req *h.RecordActivityTaskHeartbeatRequest) (*workflow.RecordActivityTaskHeartbeatResponse, error) {
+
+	domainEntry, err := e.getActiveDomainEntry(req.DomainUUID)
+	if err != nil {
+		return nil, err
+	}
+	domainID := domainEntry.GetInfo().ID
+
+	request := req.HeartbeatRequest
+	token, err0 := e.tokenSerializer.Deserialize(request.TaskToken)
+	if err0 != nil {
+		return nil, ErrDeserializingToken
+	}
+
+	workflowExecution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr(token.WorkflowID),
+		RunId:      common.StringPtr(token.RunID),
+	}
+
+	var cancelRequested bool
+	err = e.updateWorkflowExecution(ctx, metrics.HistoryRecordActivityTaskHeartbeatScope, domainID, workflowExecution, false, false,
+		func(msBuilder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error) {
+			if !msBuilder.IsWorkflowExecutionRunning() {
+				e.logger.Debug("Heartbeat failed")
+				return nil, ErrWorkflowCompleted
+			}
+
+			scheduleID := token.ScheduleID
+			if scheduleID == common.EmptyEventID { // client call RecordActivityHeartbeatByID, so get scheduleID by activityID
+				scheduleID, err0 = getScheduleID(token.ActivityID, msBuilder)
+				if err0 != nil {
+					return nil, err0
+				}
+			}
+			ai, isRunning := msBuilder.GetActivityInfo(scheduleID)
+
+			// First check to see if cache needs to be refreshed as we could potentially have stale workflow execution in
+			// some extreme cassandra failure cases.
+			if !isRunning && scheduleID >= msBuilder.GetNextEventID() {
+				e.metricsClient.IncCounter(metrics.HistoryRecordActivityTaskHeartbeatScope, metrics.StaleMutableStateCounter)
+				return nil, ErrStaleState
+			}
+
+			if !isRunning || ai.StartedID == common.EmptyEventID ||
+				(token.ScheduleID != common.EmptyEventID && token.ScheduleAttempt != int64(ai.Attempt)) {
+				e.logger.Debug(fmt.Sprintf("Activity HeartBeat: scheduleEventID: %v, ActivityInfo: %+v, Exist: %v", scheduleID, ai,
+					isRunning))
 				return nil, ErrActivityTaskNotFound
 			}
 
@@ -1198,7 +1891,7 @@ func (e *historyEngineImpl) RequestCancelWorkflowExecution(ctx ctx.Context,
 		RunId:      request.WorkflowExecution.RunId,
 	}
 
-	return e.updateWorkflowExecution(ctx, domainID, execution, false, true,
+	return e.updateWorkflowExecution(ctx, metrics.HistoryRequestCancelWorkflowExecutionScope, domainID, execution, false, true,
 		func(msBuilder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error) {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return nil, ErrWorkflowCompleted
@@ -1245,6 +1938,9 @@ func (e *historyEngineImpl) SignalWorkflowExecution(ctx ctx.Context, signalReque
 	domainID := domainEntry.GetInfo().ID
 
 	request := signalRequest.SignalRequest
+	if err := validateSignalName(request.GetSignalName(), e.config.MaxIDLengthLimit()); err != nil {
+		return err
+	}
 	parentExecution := signalRequest.ExternalWorkflowExecution
 	childWorkflowOnly := signalRequest.GetChildWorkflowOnly()
 	execution := workflow.WorkflowExecution{
@@ -1252,7 +1948,7 @@ func (e *historyEngineImpl) SignalWorkflowExecution(ctx ctx.Context, signalReque
 		RunId:      request.WorkflowExecution.RunId,
 	}
 
-	return e.updateWorkflowExecutionWithAction(ctx, domainID, execution, func(msBuilder mutableState, tBuilder *timerBuilder) (*updateWorkflowAction, error) {
+	return e.updateWorkflowExecutionWithAction(ctx, metrics.HistorySignalWorkflowExecutionScope, domainID, execution, func(msBuilder mutableState, tBuilder *timerBuilder) (*updateWorkflowAction, error) {
 		executionInfo := msBuilder.GetExecutionInfo()
 		createDecisionTask := true
 		// Do not create decision task when the workflow is cron and the cron has not been started yet
@@ -1277,6 +1973,11 @@ func (e *historyEngineImpl) SignalWorkflowExecution(ctx ctx.Context, signalReque
 				tag.WorkflowDomainID(domainID))
 			return nil, ErrSignalsLimitExceeded
 		}
+		if !createDecisionTask {
+			if err := e.checkSignalsBeforeFirstDecision(domainEntry, msBuilder); err != nil {
+				return nil, err
+			}
+		}
 
 		if childWorkflowOnly {
 			parentWorkflowID := executionInfo.ParentWorkflowID
@@ -1298,7 +1999,9 @@ func (e *historyEngineImpl) SignalWorkflowExecution(ctx ctx.Context, signalReque
 		if _, err := msBuilder.AddWorkflowExecutionSignaled(
 			request.GetSignalName(),
 			request.GetInput(),
-			request.GetIdentity()); err != nil {
+			request.GetIdentity(),
+			request.Header,
+			e.getSignalExpirationTimestamp(request.GetTtlSeconds())); err != nil {
 			return nil, &workflow.InternalServiceError{Message: "Unable to signal workflow execution."}
 		}
 
@@ -1306,6 +2009,14 @@ func (e *historyEngineImpl) SignalWorkflowExecution(ctx ctx.Context, signalReque
 	})
 }
 
+// getSignalExpirationTimestamp returns the expiration timestamp of a signal sent with the given ttl, 0 if it never expires
+func (e *historyEngineImpl) getSignalExpirationTimestamp(ttlSeconds int32) int64 {
+	if ttlSeconds <= 0 {
+		return 0
+	}
+	return e.shard.GetTimeSource().Now().Add(time.Duration(ttlSeconds) * time.Second).UnixNano()
+}
+
 func (e *historyEngineImpl) SignalWithStartWorkflowExecution(ctx ctx.Context, signalWithStartRequest *h.SignalWithStartWorkflowExecutionRequest) (
 	retResp *workflow.StartWorkflowExecutionResponse, retError error) {
 
@@ -1316,6 +2027,20 @@ func (e *historyEngineImpl) SignalWithStartWorkflowExecution(ctx ctx.Context, si
 	domainID := domainEntry.GetInfo().ID
 
 	sRequest := signalWithStartRequest.SignalWithStartRequest
+	// validate the signal upfront, so neither the signal nor the start branch accepts a bad signal name
+	if retError = validateSignalName(sRequest.GetSignalName(), e.config.MaxIDLengthLimit()); retError != nil {
+		return
+	}
+	// validate the start request upfront as well, a request which could never start the workflow is rejected before
+	// the workflow is loaded or signaled
+	startRequest := getStartRequest(domainID, sRequest)
+	request := startRequest.StartRequest
+	retError = validateStartWorkflowExecutionRequest(request, e.config.MaxIDLengthLimit(),
+		e.config.MaxNonRetriableErrorReasonsCount(), e.config.MaxNonRetriableErrorReasonsLength(),
+		common.NewQueryValidator(e.config.ValidSearchAttributes))
+	if retError != nil {
+		return
+	}
 	execution := workflow.WorkflowExecution{
 		WorkflowId: sRequest.WorkflowId,
 	}
@@ -1352,18 +2077,25 @@ func (e *historyEngineImpl) SignalWithStartWorkflowExecution(ctx ctx.Context, si
 					tag.WorkflowDomainID(domainID))
 				return nil, ErrSignalsLimitExceeded
 			}
+			if executionInfo.CronSchedule != "" && !msBuilder.HasProcessedOrPendingDecisionTask() {
+				if err := e.checkSignalsBeforeFirstDecision(domainEntry, msBuilder); err != nil {
+					return nil, err
+				}
+			}
 
 			if _, err := msBuilder.AddWorkflowExecutionSignaled(
 				sRequest.GetSignalName(),
 				sRequest.GetSignalInput(),
-				sRequest.GetIdentity()); err != nil {
+				sRequest.GetIdentity(),
+				sRequest.Header,
+				e.getSignalExpirationTimestamp(sRequest.GetTtlSeconds())); err != nil {
 				return nil, &workflow.InternalServiceError{Message: "Unable to signal workflow execution."}
 			}
 
 			var transferTasks []persistence.Task
 			var timerTasks []persistence.Task
 			// Create a transfer task to schedule a decision task
-			if !msBuilder.HasPendingDecisionTask() {
+			if canScheduleDecisionTask(msBuilder, e.shard, e.metricsClient, metrics.HistorySignalWithStartWorkflowExecutionScope) {
 				di, err := msBuilder.AddDecisionTaskScheduledEvent()
 				if err != nil {
 					return nil, &workflow.InternalServiceError{Message: "Failed to add decision scheduled event."}
@@ -1390,6 +2122,7 @@ func (e *historyEngineImpl) SignalWithStartWorkflowExecution(ctx ctx.Context, si
 			// We apply the update to execution using optimistic concurrency.  If it fails due to a conflict then reload
 			// the history and try the operation again.
 			if err := context.updateWorkflowExecution(transferTasks, timerTasks, transactionID); err != nil {
+				emitUpdateError(e.metricsClient, metrics.HistorySignalWithStartWorkflowExecutionScope, err)
 				if err == ErrConflict {
 					continue Just_Signal_Loop
 				}
@@ -1399,6 +2132,7 @@ func (e *historyEngineImpl) SignalWithStartWorkflowExecution(ctx ctx.Context, si
 			return &workflow.StartWorkflowExecutionResponse{RunId: context.getExecution().RunId}, nil
 		} // end for Just_Signal_Loop
 		if attempt == conditionalRetryCount {
+			emitUpdateFailure(e.metricsClient, metrics.HistorySignalWithStartWorkflowExecutionScope, updateFailureCauseMaxAttempts)
 			return nil, ErrMaxAttemptsExceeded
 		}
 	} else {
@@ -1409,12 +2143,16 @@ func (e *historyEngineImpl) SignalWithStartWorkflowExecution(ctx ctx.Context, si
 	}
 
 	// Start workflow and signal
-	startRequest := getStartRequest(domainID, sRequest)
-	request := startRequest.StartRequest
-	retError = validateStartWorkflowExecutionRequest(request, e.config.MaxIDLengthLimit())
-	if retError != nil {
+	if retError = e.checkDomainDraining(domainEntry, metrics.HistorySignalWithStartWorkflowExecutionScope); retError != nil {
 		return
 	}
+	if retError = e.checkShardBackpressure(domainEntry.GetInfo().Name, metrics.HistorySignalWithStartWorkflowExecutionScope); retError != nil {
+		return
+	}
+	// both inputs end up in the first batch of events, so they are checked together before creating the workflow
+	if len(request.Input)+len(sRequest.SignalInput) > e.config.BlobSizeLimitError(domainEntry.GetInfo().Name) {
+		return nil, ErrSignalWithStartInputExceedsSizeLimit
+	}
 
 	execution = workflow.WorkflowExecution{
 		WorkflowId: request.WorkflowId,
@@ -1463,12 +2201,14 @@ func (e *historyEngineImpl) SignalWithStartWorkflowExecution(ctx ctx.Context, si
 	if _, err := msBuilder.AddWorkflowExecutionSignaled(
 		sRequest.GetSignalName(),
 		sRequest.GetSignalInput(),
-		sRequest.GetIdentity()); err != nil {
+		sRequest.GetIdentity(),
+		sRequest.Header,
+		e.getSignalExpirationTimestamp(sRequest.GetTtlSeconds())); err != nil {
 		return nil, &workflow.InternalServiceError{Message: "Failed to add workflow execution signaled event."}
 	}
 	// first decision task
 	var transferTasks []persistence.Task
-	transferTasks, _, retError = e.generateFirstDecisionTask(domainID, msBuilder, startRequest.ParentExecutionInfo, taskList, 0)
+	transferTasks, _, retError = e.generateFirstDecisionTask(domainID, msBuilder, startRequest.ParentExecutionInfo, taskList, 0, 0)
 	if retError != nil {
 		return
 	}
@@ -1508,7 +2248,7 @@ func (e *historyEngineImpl) SignalWithStartWorkflowExecution(ctx ctx.Context, si
 		prevLastWriteVersion = prevMutableState.GetLastWriteVersion()
 	}
 	retError = context.createWorkflowExecution(
-		msBuilder, e.currentClusterName, createReplicationTask, time.Now(),
+		msBuilder, e.currentClusterName, createReplicationTask, e.shard.GetTimeSource().Now(),
 		transferTasks, replicationTasks, timerTasks,
 		createMode, prevRunID, prevLastWriteVersion,
 	)
@@ -1548,7 +2288,7 @@ func (e *historyEngineImpl) RemoveSignalMutableState(ctx ctx.Context, request *h
 		RunId:      request.WorkflowExecution.RunId,
 	}
 
-	return e.updateWorkflowExecution(ctx, domainID, execution, false, false,
+	return e.updateWorkflowExecution(ctx, metrics.HistoryRemoveSignalMutableStateScope, domainID, execution, false, false,
 		func(msBuilder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error) {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return nil, ErrWorkflowCompleted
@@ -1574,8 +2314,10 @@ func (e *historyEngineImpl) TerminateWorkflowExecution(ctx ctx.Context, terminat
 		RunId:      request.WorkflowExecution.RunId,
 	}
 
-	return e.updateWorkflowExecution(ctx, domainID, execution, true, false,
-		func(msBuilder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error) {
+	// the run ID is resolved from mutable state, the request may target the current run without one
+	var runID string
+	err = e.updateWorkflowExecutionWithAction(ctx, metrics.HistoryTerminateWorkflowExecutionScope, domainID, execution,
+		func(msBuilder mutableState, tBuilder *timerBuilder) (*updateWorkflowAction, error) {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return nil, ErrWorkflowCompleted
 			}
@@ -1588,8 +2330,74 @@ func (e *historyEngineImpl) TerminateWorkflowExecution(ctx ctx.Context, terminat
 				return nil, &workflow.InternalServiceError{Message: "Unable to terminate workflow execution."}
 			}
 
-			return nil, nil
+			transferTasks, err := e.getChildPolicyTransferTasks(domainID, msBuilder)
+			if err != nil {
+				return nil, err
+			}
+
+			runID = msBuilder.GetExecutionInfo().RunID
+			return &updateWorkflowAction{
+				deleteWorkflow: true,
+				transferTasks:  transferTasks,
+			}, nil
 		})
+	if err != nil {
+		return err
+	}
+
+	e.recordAudit(ctx, AuditOperationTerminateWorkflowExecution, domainID, workflow.WorkflowExecution{
+		WorkflowId: execution.WorkflowId,
+		RunId:      common.StringPtr(runID),
+	}, request.GetIdentity())
+	return nil
+}
+
+// getChildPolicyTransferTasks returns the transfer tasks enforcing the child policy recorded on each started and
+// still pending child execution of a terminated workflow, abandoned children are left running
+func (e *historyEngineImpl) getChildPolicyTransferTasks(domainID string, msBuilder mutableState) ([]persistence.Task, error) {
+	var transferTasks []persistence.Task
+	for initiatedID, ci := range msBuilder.GetPendingChildExecutionInfos() {
+		if ci.StartedID == common.EmptyEventID {
+			// the child is never started once the parent is closed
+			continue
+		}
+		initiatedEvent, ok := msBuilder.GetChildExecutionInitiatedEvent(initiatedID)
+		if !ok {
+			return nil, &workflow.InternalServiceError{Message: "Unable to load child execution initiated event."}
+		}
+
+		childPolicy := initiatedEvent.StartChildWorkflowExecutionInitiatedEventAttributes.GetChildPolicy()
+		if childPolicy == workflow.ChildPolicyAbandon {
+			continue
+		}
+
+		targetDomainID := domainID
+		if ci.DomainName != "" {
+			domainEntry, err := e.shard.GetDomainCache().GetDomain(ci.DomainName)
+			if err != nil {
+				return nil, err
+			}
+			targetDomainID = domainEntry.GetInfo().ID
+		}
+
+		switch childPolicy {
+		case workflow.ChildPolicyTerminate:
+			transferTasks = append(transferTasks, &persistence.TerminateChildExecutionTask{
+				TargetDomainID:   targetDomainID,
+				TargetWorkflowID: ci.StartedWorkflowID,
+				TargetRunID:      ci.StartedRunID,
+				InitiatedID:      initiatedID,
+			})
+		case workflow.ChildPolicyRequestCancel:
+			transferTasks = append(transferTasks, &persistence.CancelChildExecutionTask{
+				TargetDomainID:   targetDomainID,
+				TargetWorkflowID: ci.StartedWorkflowID,
+				TargetRunID:      ci.StartedRunID,
+				InitiatedID:      initiatedID,
+			})
+		}
+	}
+	return transferTasks, nil
 }
 
 // RecordChildExecutionCompleted records the completion of child execution into parent execution history
@@ -1606,8 +2414,8 @@ func (e *historyEngineImpl) RecordChildExecutionCompleted(ctx ctx.Context, compl
 		RunId:      completionRequest.WorkflowExecution.RunId,
 	}
 
-	return e.updateWorkflowExecution(ctx, domainID, execution, false, true,
-		func(msBuilder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error) {
+	return e.updateWorkflowExecutionWithAction(ctx, metrics.HistoryRecordChildExecutionCompletedScope, domainID, execution,
+		func(msBuilder mutableState, tBuilder *timerBuilder) (*updateWorkflowAction, error) {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return nil, ErrWorkflowCompleted
 			}
@@ -1618,7 +2426,18 @@ func (e *historyEngineImpl) RecordChildExecutionCompleted(ctx ctx.Context, compl
 
 			// Check mutable state to make sure child execution is in pending child executions
 			ci, isRunning := msBuilder.GetChildExecutionInfo(initiatedID)
-			if !isRunning || ci.StartedID == common.EmptyEventID {
+			if !isRunning {
+				// child info is removed once its completion is recorded, so this may be a duplicate delivery
+				recorded, err := e.isChildCompletionRecorded(domainID, msBuilder, initiatedID, completedExecution)
+				if err != nil {
+					return nil, err
+				}
+				if recorded {
+					return &updateWorkflowAction{noop: true}, nil
+				}
+				return nil, &workflow.EntityNotExistsError{Message: "Pending child execution not found."}
+			}
+			if ci.StartedID == common.EmptyEventID {
 				return nil, &workflow.EntityNotExistsError{Message: "Pending child execution not found."}
 			}
 
@@ -1640,11 +2459,83 @@ func (e *historyEngineImpl) RecordChildExecutionCompleted(ctx ctx.Context, compl
 				attributes := completionEvent.WorkflowExecutionTimedOutEventAttributes
 				_, err = msBuilder.AddChildWorkflowExecutionTimedOutEvent(initiatedID, completedExecution, attributes)
 			}
+			if err != nil {
+				return nil, err
+			}
 
-			return nil, err
+			return &updateWorkflowAction{createDecision: true}, nil
 		})
 }
 
+// isChildCompletionRecorded returns whether the completion of a child workflow was already recorded in the parent, that
+// is the event initiatedID is the StartChildWorkflowExecutionInitiated event of the child and a closed event of the
+// child either follows it in the history or is buffered
+func (e *historyEngineImpl) isChildCompletionRecorded(domainID string, msBuilder mutableState, initiatedID int64,
+	childExecution *workflow.WorkflowExecution) (bool, error) {
+
+	if initiatedID < common.FirstEventID || initiatedID >= msBuilder.GetNextEventID() {
+		return false, nil
+	}
+	isChildClosedEvent := func(event *workflow.HistoryEvent) bool {
+		var initiatedEventID int64
+		var execution *workflow.WorkflowExecution
+		switch event.GetEventType() {
+		case workflow.EventTypeChildWorkflowExecutionCompleted:
+			attributes := event.ChildWorkflowExecutionCompletedEventAttributes
+			initiatedEventID, execution = attributes.GetInitiatedEventId(), attributes.WorkflowExecution
+		case workflow.EventTypeChildWorkflowExecutionFailed:
+			attributes := event.ChildWorkflowExecutionFailedEventAttributes
+			initiatedEventID, execution = attributes.GetInitiatedEventId(), attributes.WorkflowExecution
+		case workflow.EventTypeChildWorkflowExecutionCanceled:
+			attributes := event.ChildWorkflowExecutionCanceledEventAttributes
+			initiatedEventID, execution = attributes.GetInitiatedEventId(), attributes.WorkflowExecution
+		case workflow.EventTypeChildWorkflowExecutionTerminated:
+			attributes := event.ChildWorkflowExecutionTerminatedEventAttributes
+			initiatedEventID, execution = attributes.GetInitiatedEventId(), attributes.WorkflowExecution
+		case workflow.EventTypeChildWorkflowExecutionTimedOut:
+			attributes := event.ChildWorkflowExecutionTimedOutEventAttributes
+			initiatedEventID, execution = attributes.GetInitiatedEventId(), attributes.WorkflowExecution
+		default:
+			return false
+		}
+		return initiatedEventID == initiatedID && execution.GetWorkflowId() == childExecution.GetWorkflowId()
+	}
+
+	executionInfo := msBuilder.GetExecutionInfo()
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr(executionInfo.WorkflowID),
+		RunId:      common.StringPtr(executionInfo.RunID),
+	}
+	var nextPageToken []byte
+	for {
+		events, token, err := e.readHistoryPage(domainID, execution, msBuilder.GetEventStoreVersion(),
+			msBuilder.GetCurrentBranch(), initiatedID, msBuilder.GetNextEventID(), defaultHistoryPageSize, nextPageToken)
+		if err != nil {
+			return false, err
+		}
+		for _, event := range events {
+			if event.GetEventId() == initiatedID &&
+				event.GetEventType() != workflow.EventTypeStartChildWorkflowExecutionInitiated {
+				return false, nil
+			}
+			if isChildClosedEvent(event) {
+				return true, nil
+			}
+		}
+		if len(token) == 0 {
+			break
+		}
+		nextPageToken = token
+	}
+
+	for _, event := range msBuilder.GetBufferedEvents() {
+		if isChildClosedEvent(event) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (e *historyEngineImpl) ReplicateEvents(ctx ctx.Context, replicateRequest *h.ReplicateEventsRequest) error {
 	return e.replicator.ApplyEvents(ctx, replicateRequest)
 }
@@ -1655,7 +2546,7 @@ func (e *historyEngineImpl) ReplicateRawEvents(ctx ctx.Context, replicateRequest
 
 func (e *historyEngineImpl) SyncShardStatus(ctx ctx.Context, request *h.SyncShardStatusRequest) error {
 	clusterName := request.GetSourceCluster()
-	now := time.Unix(0, request.GetTimestamp())
+	now := e.checkClusterClockSkew(clusterName, time.Unix(0, request.GetTimestamp()))
 
 	// here there are 3 main things
 	// 1. update the view of remote cluster's shard time
@@ -1667,10 +2558,105 @@ func (e *historyEngineImpl) SyncShardStatus(ctx ctx.Context, request *h.SyncShar
 	return nil
 }
 
+// checkClusterClockSkew compares the time reported by a remote cluster with the local time, a remote time ahead by
+// more than MaxClusterClockSkew is clamped to the local time so a wrong clock cannot fire standby timers early.
+// A remote time behind is kept since the remote cluster time of a shard never moves backwards.
+func (e *historyEngineImpl) checkClusterClockSkew(clusterName string, remoteTime time.Time) time.Time {
+	maxSkew := e.config.MaxClusterClockSkew()
+	if maxSkew <= 0 {
+		return remoteTime
+	}
+
+	localTime := e.shard.GetTimeSource().Now()
+	skew := remoteTime.Sub(localTime)
+	if skew <= maxSkew && skew >= -maxSkew {
+		return remoteTime
+	}
+
+	e.metricsClient.Scope(metrics.HistorySyncShardStatusScope, metrics.TargetClusterTag(clusterName)).
+		IncCounter(metrics.ClusterClockSkewDetected)
+	e.logger.Warn("Cluster clock skew detected.",
+		tag.SourceCluster(clusterName),
+		tag.Timestamp(remoteTime),
+		tag.ClusterClockSkew(skew),
+	)
+	if skew > 0 {
+		return localTime
+	}
+	return remoteTime
+}
+
 func (e *historyEngineImpl) SyncActivity(ctx ctx.Context, request *h.SyncActivityRequest) (retError error) {
 	return e.replicator.SyncActivity(ctx, request)
 }
 
+// DescribeQueueProcessorStatus reports the ack level, read level and estimated backlog of the
+// transfer, timer and replicator queue processors of the shard
+func (e *historyEngineImpl) DescribeQueueProcessorStatus() *ShardQueueProcessorStatus {
+	status := &ShardQueueProcessorStatus{
+		ShardID:            e.shard.GetShardID(),
+		CurrentClusterName: e.currentClusterName,
+		TransferProcessor:  e.txProcessor.describeStatus(),
+		TimerProcessor:     e.timerProcessor.describeStatus(),
+	}
+	if e.replicatorProcessor != nil {
+		status.ReplicatorProcessor = e.replicatorProcessor.describeStatus()
+	}
+	return status
+}
+
+// DescribeArchivalBacklog counts per domain the closed workflows of the shard whose history cleanup timer is due but
+// not yet processed while archival is enabled for the domain, at most archivalBacklogMaxPages pages of timer tasks
+// are read to keep the call cheap
+func (e *historyEngineImpl) DescribeArchivalBacklog() (*ShardArchivalBacklog, error) {
+	backlog := &ShardArchivalBacklog{
+		ShardID:       e.shard.GetShardID(),
+		DomainBacklog: make(map[string]int64),
+	}
+	if e.shard.GetService().GetClusterMetadata().ArchivalConfig().GetArchivalStatus() != cluster.ArchivalEnabled {
+		return backlog, nil
+	}
+
+	domainNames := make(map[string]string)
+	request := &persistence.GetTimerIndexTasksRequest{
+		MinTimestamp: e.shard.GetTimerClusterAckLevel(e.currentClusterName),
+		MaxTimestamp: e.shard.GetCurrentTime(e.currentClusterName),
+		BatchSize:    e.config.TimerTaskBatchSize(),
+	}
+	for page := 0; page < archivalBacklogMaxPages; page++ {
+		response, err := e.executionManager.GetTimerIndexTasks(request)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range response.Timers {
+			if task.TaskType != persistence.TaskTypeDeleteHistoryEvent {
+				continue
+			}
+			domainName, ok := domainNames[task.DomainID]
+			if !ok {
+				domainEntry, err := e.shard.GetDomainCache().GetDomainByID(task.DomainID)
+				if err != nil {
+					return nil, err
+				}
+				if domainEntry.GetConfig().ArchivalStatus == workflow.ArchivalStatusEnabled {
+					domainName = domainEntry.GetInfo().Name
+				}
+				domainNames[task.DomainID] = domainName
+			}
+			if domainName != "" {
+				backlog.DomainBacklog[domainName]++
+			}
+		}
+		if len(response.NextPageToken) == 0 {
+			return backlog, nil
+		}
+		request.NextPageToken = response.NextPageToken
+	}
+
+	backlog.Truncated = true
+	return backlog, nil
+}
+
 func (e *historyEngineImpl) ResetWorkflowExecution(ctx ctx.Context,
 	resetRequest *h.ResetWorkflowExecutionRequest) (response *workflow.ResetWorkflowExecutionResponse, retError error) {
 
@@ -1697,6 +2683,11 @@ func (e *historyEngineImpl) ResetWorkflowExecution(ctx ctx.Context,
 		WorkflowId: request.WorkflowExecution.WorkflowId,
 		RunId:      request.WorkflowExecution.RunId,
 	}
+	defer func() {
+		if retError == nil {
+			e.recordAudit(ctx, AuditOperationResetWorkflowExecution, domainID, baseExecution, "")
+		}
+	}()
 
 	baseContext, baseRelease, retError := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, baseExecution)
 	if retError != nil {
@@ -1757,39 +2748,598 @@ func (e *historyEngineImpl) ResetWorkflowExecution(ctx ctx.Context,
 	return e.resetor.ResetWorkflowExecution(ctx, request, baseContext, baseMutableState, currContext, currMutableState)
 }
 
-func (e *historyEngineImpl) DeleteExecutionFromVisibility(task *persistence.TimerTaskInfo) error {
-	request := &persistence.VisibilityDeleteWorkflowExecutionRequest{
-		DomainID:   task.DomainID,
-		WorkflowID: task.WorkflowID,
-		RunID:      task.RunID,
-		TaskID:     task.TaskID,
-	}
-	return e.visibilityMgr.DeleteWorkflowExecution(request) // delete from db
-}
+// DeleteWorkflowExecution purges the history, mutable state and visibility record of a closed workflow execution
+// without waiting for the retention period, deleting an execution which no longer exists is a noop
+func (e *historyEngineImpl) DeleteWorkflowExecution(ctx ctx.Context, domainUUID string,
+	execution workflow.WorkflowExecution) (retError error) {
 
-type updateWorkflowAction struct {
-	noop           bool
-	deleteWorkflow bool
-	createDecision bool
-	timerTasks     []persistence.Task
-	transferTasks  []persistence.Task
-}
+	domainID, err := validateDomainUUID(common.StringPtr(domainUUID))
+	if err != nil {
+		return err
+	}
 
-func (e *historyEngineImpl) updateWorkflowExecutionWithAction(ctx ctx.Context, domainID string, execution workflow.WorkflowExecution,
-	action func(builder mutableState, tBuilder *timerBuilder) (*updateWorkflowAction, error)) (retError error) {
-	context, release, err0 := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
-	if err0 != nil {
-		return err0
+	context, release, err := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
+	if err != nil {
+		if _, ok := err.(*workflow.EntityNotExistsError); ok {
+			// current run already deleted
+			return nil
+		}
+		return err
 	}
 	defer func() { release(retError) }()
 
-Update_History_Loop:
-	for attempt := 0; attempt < conditionalRetryCount; attempt++ {
-		msBuilder, err1 := context.loadWorkflowExecution()
-		if err1 != nil {
-			return err1
+	msBuilder, err := context.loadWorkflowExecution()
+	if err != nil {
+		if _, ok := err.(*workflow.EntityNotExistsError); ok {
+			// already deleted
+			return nil
 		}
-		tBuilder := e.getTimerBuilder(context.getExecution())
+		return err
+	}
+
+	if msBuilder.IsWorkflowExecutionRunning() {
+		return &workflow.BadRequestError{Message: "Cannot delete a running workflow execution."}
+	}
+
+	executionInfo := msBuilder.GetExecutionInfo()
+	taskID, err := e.shard.GetNextTransferTaskID()
+	if err != nil {
+		return err
+	}
+
+	// the execution record is deleted last so that a retry after a partial failure can still load the branch token
+	op := func() error {
+		return e.DeleteExecutionFromVisibility(&persistence.TimerTaskInfo{
+			DomainID:   domainID,
+			WorkflowID: executionInfo.WorkflowID,
+			RunID:      executionInfo.RunID,
+			TaskID:     taskID,
+		})
+	}
+	if err := backoff.Retry(op, persistenceOperationRetryPolicy, common.IsPersistenceTransientError); err != nil {
+		return err
+	}
+
+	op = func() error {
+		if msBuilder.GetEventStoreVersion() == persistence.EventStoreVersionV2 {
+			return persistence.DeleteWorkflowExecutionHistoryV2(e.historyV2Mgr, msBuilder.GetCurrentBranch(), common.IntPtr(e.shard.GetShardID()), e.logger)
+		}
+		return e.historyMgr.DeleteWorkflowExecutionHistory(&persistence.DeleteWorkflowExecutionHistoryRequest{
+			DomainID: domainID,
+			Execution: workflow.WorkflowExecution{
+				WorkflowId: common.StringPtr(executionInfo.WorkflowID),
+				RunId:      common.StringPtr(executionInfo.RunID),
+			},
+		})
+	}
+	if err := backoff.Retry(op, persistenceOperationRetryPolicy, common.IsPersistenceTransientError); err != nil {
+		return err
+	}
+
+	op = func() error {
+		return e.executionManager.DeleteCurrentWorkflowExecution(&persistence.DeleteCurrentWorkflowExecutionRequest{
+			DomainID:   domainID,
+			WorkflowID: executionInfo.WorkflowID,
+			RunID:      executionInfo.RunID,
+		})
+	}
+	if err := backoff.Retry(op, persistenceOperationRetryPolicy, common.IsPersistenceTransientError); err != nil {
+		return err
+	}
+
+	op = func() error {
+		return e.executionManager.DeleteWorkflowExecution(&persistence.DeleteWorkflowExecutionRequest{
+			DomainID:   domainID,
+			WorkflowID: executionInfo.WorkflowID,
+			RunID:      executionInfo.RunID,
+		})
+	}
+	if err := backoff.Retry(op, persistenceOperationRetryPolicy, common.IsPersistenceTransientError); err != nil {
+		return err
+	}
+
+	// force later accesses to read from database, the mutable state in cache is no longer valid
+	context.clear()
+	return nil
+}
+
+func (e *historyEngineImpl) DeleteExecutionFromVisibility(task *persistence.TimerTaskInfo) error {
+	request := &persistence.VisibilityDeleteWorkflowExecutionRequest{
+		DomainID:   task.DomainID,
+		WorkflowID: task.WorkflowID,
+		RunID:      task.RunID,
+		TaskID:     task.TaskID,
+	}
+	return e.visibilityMgr.DeleteWorkflowExecution(request) // delete from db
+}
+
+// ReemitOpenVisibility records the open visibility record of a running workflow execution again,
+// using the current mutable state. It is meant for backfilling a visibility store, e.g. after a
+// migration, and only writes to visibility, so it is safe to call repeatedly.
+func (e *historyEngineImpl) ReemitOpenVisibility(ctx ctx.Context, domainUUID string,
+	execution workflow.WorkflowExecution) (retError error) {
+
+	domainID, err := validateDomainUUID(common.StringPtr(domainUUID))
+	if err != nil {
+		return err
+	}
+	domainEntry, err := e.shard.GetDomainCache().GetDomainByID(domainID)
+	if err != nil {
+		return err
+	}
+
+	context, release, err := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
+	if err != nil {
+		return err
+	}
+	defer func() { release(retError) }()
+
+	msBuilder, err := context.loadWorkflowExecution()
+	if err != nil {
+		return err
+	}
+	if !msBuilder.IsWorkflowExecutionRunning() {
+		return ErrWorkflowCompleted
+	}
+
+	executionInfo := msBuilder.GetExecutionInfo()
+	wid := executionInfo.WorkflowID
+	// if sampled for longer retention is enabled, only those sampled workflows have visibility records
+	if domainEntry.IsSampledForLongerRetentionEnabled(wid) && !domainEntry.IsSampledForLongerRetention(wid) {
+		return nil
+	}
+
+	startEvent, found := msBuilder.GetStartEvent()
+	if !found {
+		return &workflow.InternalServiceError{Message: "Failed to load start event."}
+	}
+	// a new task ID makes sure the record is not rejected as stale by stores versioned on task ID
+	taskID, err := e.shard.GetNextTransferTaskID()
+	if err != nil {
+		return err
+	}
+
+	request := &persistence.RecordWorkflowExecutionStartedRequest{
+		DomainUUID: domainID,
+		Domain:     domainEntry.GetInfo().Name,
+		Execution: workflow.WorkflowExecution{
+			WorkflowId: common.StringPtr(wid),
+			RunId:      common.StringPtr(executionInfo.RunID),
+		},
+		WorkflowTypeName:   executionInfo.WorkflowTypeName,
+		StartTimestamp:     executionInfo.StartTimestamp.UnixNano(),
+		ExecutionTimestamp: getWorkflowExecutionTimestamp(msBuilder, startEvent).UnixNano(),
+		WorkflowTimeout:    int64(executionInfo.WorkflowTimeout),
+		TaskID:             taskID,
+		Memo:               getVisibilityMemo(executionInfo, startEvent),
+		SearchAttributes:   executionInfo.SearchAttributes,
+	}
+
+	// release the context lock since the rest of logic is making RPC call, which takes time
+	release(nil)
+	return e.visibilityMgr.RecordWorkflowExecutionStarted(request)
+}
+
+// ListWorkflowsWithStaleDecision scans one page of the open workflow executions of a domain, as recorded by visibility,
+// and returns those whose pending decision has been scheduled, or started if it is, for longer than the threshold.
+// A page scans at most staleDecisionScanMaxPageSize executions and may match none of them, callers keep paging until
+// the next page token is empty. Executions owned by other shards are skipped without being loaded, so finding all the
+// stale decisions of a domain takes a scan on every shard.
+func (e *historyEngineImpl) ListWorkflowsWithStaleDecision(ctx ctx.Context, domainUUID string,
+	threshold time.Duration, pageSize int, nextPageToken []byte) (*StaleDecisionWorkflows, error) {
+
+	domainEntry, err := e.getActiveDomainEntry(common.StringPtr(domainUUID))
+	if err != nil {
+		return nil, err
+	}
+	if threshold <= 0 {
+		return nil, &workflow.BadRequestError{Message: "Threshold must be positive."}
+	}
+	if pageSize <= 0 || pageSize > staleDecisionScanMaxPageSize {
+		pageSize = staleDecisionScanMaxPageSize
+	}
+
+	now := e.shard.GetTimeSource().Now()
+	resp, err := e.visibilityMgr.ListOpenWorkflowExecutions(&persistence.ListWorkflowExecutionsRequest{
+		DomainUUID:        domainEntry.GetInfo().ID,
+		Domain:            domainEntry.GetInfo().Name,
+		EarliestStartTime: 0,
+		LatestStartTime:   now.UnixNano(),
+		PageSize:          pageSize,
+		NextPageToken:     nextPageToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StaleDecisionWorkflows{NextPageToken: resp.NextPageToken}
+	for _, info := range resp.Executions {
+		if e.config.GetShardID(info.Execution.GetWorkflowId()) != e.shard.GetShardID() {
+			continue
+		}
+		stale, err := e.getStaleDecision(ctx, domainEntry.GetInfo().ID, *info.Execution, now.Add(-threshold))
+		if err != nil {
+			return nil, err
+		}
+		if stale != nil {
+			result.Workflows = append(result.Workflows, stale)
+		}
+	}
+	return result, nil
+}
+
+// getStaleDecision returns the pending decision of a workflow execution if it was scheduled, or started if it is,
+// before the cutoff, nil otherwise. Executions which are closed or not found are skipped.
+func (e *historyEngineImpl) getStaleDecision(ctx ctx.Context, domainID string, execution workflow.WorkflowExecution,
+	cutoff time.Time) (retStale *StaleDecisionWorkflow, retError error) {
+
+	context, release, retError := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
+	if retError != nil {
+		return
+	}
+	defer func() { release(retError) }()
+
+	msBuilder, err := context.loadWorkflowExecution()
+	if err != nil {
+		if _, ok := err.(*workflow.EntityNotExistsError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !msBuilder.IsWorkflowExecutionRunning() || !msBuilder.HasPendingDecisionTask() {
+		return nil, nil
+	}
+
+	di, ok := msBuilder.GetPendingDecision(msBuilder.GetExecutionInfo().DecisionScheduleID)
+	if !ok {
+		return nil, nil
+	}
+	since := di.ScheduledTimestamp
+	if di.StartedID != common.EmptyEventID {
+		since = di.StartedTimestamp
+	}
+	if since >= cutoff.UnixNano() {
+		return nil, nil
+	}
+
+	stale := &StaleDecisionWorkflow{
+		Execution:          execution,
+		DecisionScheduleID: di.ScheduleID,
+		ScheduledTimestamp: di.ScheduledTimestamp,
+	}
+	if di.StartedID != common.EmptyEventID {
+		stale.StartedTimestamp = di.StartedTimestamp
+	}
+	return stale, nil
+}
+
+// MarkBinaryBad adds a binary to the bad binaries of a local domain, so decisions completed by it are failed from now
+// on. If resetWorkflows is set, the running workflows of the domain which ran a decision of the binary are reset to
+// before its first decision, the same way the auto-reset on close does. The open workflows are read from visibility,
+// at most badBinaryResetMaxPages pages of them, and the ones owned by other shards are skipped without being loaded, so
+// resetting all the workflows of a domain takes a call on every shard. Returns the number of workflows reset.
+func (e *historyEngineImpl) MarkBinaryBad(ctx ctx.Context, domainUUID string, checksum string, reason string,
+	operator string, resetWorkflows bool) (int, error) {
+
+	domainEntry, err := e.getActiveDomainEntry(common.StringPtr(domainUUID))
+	if err != nil {
+		return 0, err
+	}
+	if checksum == "" {
+		return 0, &workflow.BadRequestError{Message: "Binary checksum is not set."}
+	}
+	domainID := domainEntry.GetInfo().ID
+	domainName := domainEntry.GetInfo().Name
+
+	badBinary := &workflow.BadBinaryInfo{
+		Reason:          common.StringPtr(reason),
+		Operator:        common.StringPtr(operator),
+		CreatedTimeNano: common.Int64Ptr(e.shard.GetTimeSource().Now().UnixNano()),
+	}
+	if err := e.addBadBinary(domainID, domainName, checksum, badBinary); err != nil {
+		return 0, err
+	}
+	e.logger.Info("Binary is marked as bad.",
+		tag.WorkflowDomainName(domainName),
+		tag.WorkflowBinaryChecksum(checksum),
+		tag.Operator(operator))
+	e.recordAudit(ctx, AuditOperationMarkBinaryBad, domainID, workflow.WorkflowExecution{}, operator)
+	if !resetWorkflows {
+		return 0, nil
+	}
+
+	// the domain cache is refreshed asynchronously, so the reset points are looked up for this binary only rather
+	// than against the cached bad binaries of the domain
+	badBinaries := &workflow.BadBinaries{Binaries: map[string]*workflow.BadBinaryInfo{checksum: badBinary}}
+	resetCtx := NewAdminOperationContext(ctx, operator)
+	resetCount := 0
+	request := &persistence.ListWorkflowExecutionsRequest{
+		DomainUUID:      domainID,
+		Domain:          domainName,
+		LatestStartTime: e.shard.GetTimeSource().Now().UnixNano(),
+		PageSize:        badBinaryResetPageSize,
+	}
+	for page := 0; page < badBinaryResetMaxPages; page++ {
+		resp, err := e.visibilityMgr.ListOpenWorkflowExecutions(request)
+		if err != nil {
+			return resetCount, err
+		}
+		for _, info := range resp.Executions {
+			if e.config.GetShardID(info.Execution.GetWorkflowId()) != e.shard.GetShardID() {
+				continue
+			}
+			resetPoint, err := e.findBadBinaryResetPoint(ctx, domainID, *info.Execution, badBinaries)
+			if err != nil {
+				return resetCount, err
+			}
+			if resetPoint == nil {
+				continue
+			}
+			if _, err := e.ResetWorkflowExecution(resetCtx, &h.ResetWorkflowExecutionRequest{
+				DomainUUID: common.StringPtr(domainID),
+				ResetRequest: &workflow.ResetWorkflowExecutionRequest{
+					Domain: common.StringPtr(domainName),
+					WorkflowExecution: &workflow.WorkflowExecution{
+						WorkflowId: info.Execution.WorkflowId,
+						RunId:      resetPoint.RunId,
+					},
+					Reason:                common.StringPtr(fmt.Sprintf("bad binary reset reason:%v, binaryChecksum:%v ", reason, checksum)),
+					DecisionFinishEventId: resetPoint.FirstDecisionCompletedId,
+					RequestId:             common.StringPtr(uuid.New()),
+				},
+			}); err != nil {
+				// the binary is already marked as bad, a workflow failing to reset does not stop the others
+				e.logger.Warn("Failed to reset workflow of bad binary.",
+					tag.WorkflowDomainName(domainName),
+					tag.WorkflowID(info.Execution.GetWorkflowId()),
+					tag.WorkflowRunID(info.Execution.GetRunId()),
+					tag.WorkflowBinaryChecksum(checksum),
+					tag.Error(err))
+				continue
+			}
+			resetCount++
+		}
+		if len(resp.NextPageToken) == 0 {
+			break
+		}
+		request.NextPageToken = resp.NextPageToken
+	}
+
+	e.metricsClient.Scope(metrics.HistoryMarkBinaryBadScope, metrics.DomainTag(domainName)).
+		AddCounter(metrics.BadBinaryResetWorkflowsCounter, int64(resetCount))
+	return resetCount, nil
+}
+
+// addBadBinary adds a binary to the bad binaries of a local domain, a binary which is already bad is left as is
+func (e *historyEngineImpl) addBadBinary(domainID string, domainName string, checksum string,
+	badBinary *workflow.BadBinaryInfo) error {
+
+	getResponse, err := e.metadataMgr.GetDomain(&persistence.GetDomainRequest{ID: domainID})
+	if err != nil {
+		return err
+	}
+	if getResponse.IsGlobalDomain {
+		return ErrGlobalDomainBadBinary
+	}
+	config := getResponse.Config
+	if _, ok := config.BadBinaries.Binaries[checksum]; ok {
+		return nil
+	}
+	if len(config.BadBinaries.Binaries) >= e.config.MaxBadBinaries(domainName) {
+		return &workflow.BadRequestError{
+			Message: fmt.Sprintf("Total resetBinaries cannot exceed the max limit: %v", e.config.MaxBadBinaries(domainName)),
+		}
+	}
+	if config.BadBinaries.Binaries == nil {
+		config.BadBinaries.Binaries = map[string]*workflow.BadBinaryInfo{}
+	}
+	config.BadBinaries.Binaries[checksum] = badBinary
+
+	updateRequest := &persistence.UpdateDomainRequest{
+		Info:                        getResponse.Info,
+		Config:                      config,
+		ReplicationConfig:           getResponse.ReplicationConfig,
+		ConfigVersion:               getResponse.ConfigVersion + 1,
+		FailoverVersion:             getResponse.FailoverVersion,
+		FailoverNotificationVersion: getResponse.FailoverNotificationVersion,
+		TableVersion:                getResponse.TableVersion,
+	}
+	switch getResponse.TableVersion {
+	case persistence.DomainTableVersionV1:
+		updateRequest.NotificationVersion = getResponse.NotificationVersion
+	case persistence.DomainTableVersionV2:
+		metadata, err := e.metadataMgr.GetMetadata()
+		if err != nil {
+			return err
+		}
+		updateRequest.NotificationVersion = metadata.NotificationVersion
+	default:
+		return &workflow.InternalServiceError{Message: "Domain table version is not set."}
+	}
+	return e.metadataMgr.UpdateDomain(updateRequest)
+}
+
+// findBadBinaryResetPoint returns the reset point of a running workflow execution before the first decision of one of
+// the bad binaries, nil if it has none. Like the auto-reset on close, workflows with pending child executions are not
+// reset, and executions which are not found by this shard are skipped.
+func (e *historyEngineImpl) findBadBinaryResetPoint(ctx ctx.Context, domainID string,
+	execution workflow.WorkflowExecution, badBinaries *workflow.BadBinaries) (retPoint *workflow.ResetPointInfo, retError error) {
+
+	context, release, retError := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
+	if retError != nil {
+		return
+	}
+	defer func() { release(retError) }()
+
+	msBuilder, err := context.loadWorkflowExecution()
+	if err != nil {
+		if _, ok := err.(*workflow.EntityNotExistsError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !msBuilder.IsWorkflowExecutionRunning() || len(msBuilder.GetPendingChildExecutionInfos()) > 0 {
+		return nil, nil
+	}
+	_, resetPoint := FindAutoResetPoint(badBinaries, msBuilder.GetExecutionInfo().AutoResetPoints)
+	return resetPoint, nil
+}
+
+// GetReplicationStatus returns the replication state of a workflow execution. It is also available for a domain which
+// is not active in this cluster, as that is where the replication lag of the domain shows.
+func (e *historyEngineImpl) GetReplicationStatus(ctx ctx.Context, domainUUID string,
+	execution workflow.WorkflowExecution) (retStatus *WorkflowReplicationStatus, retError error) {
+
+	domainID, err := validateDomainUUID(common.StringPtr(domainUUID))
+	if err != nil {
+		return nil, err
+	}
+
+	context, release, retError := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
+	if retError != nil {
+		return
+	}
+	defer func() { release(retError) }()
+
+	msBuilder, retError := context.loadWorkflowExecution()
+	if retError != nil {
+		return
+	}
+
+	status := &WorkflowReplicationStatus{NextEventID: msBuilder.GetNextEventID()}
+	replicationState := msBuilder.GetReplicationState()
+	if replicationState == nil {
+		return status, nil
+	}
+	status.Replicated = true
+	status.CurrentVersion = replicationState.CurrentVersion
+	status.LastWriteVersion = replicationState.LastWriteVersion
+	status.LastWriteEventID = replicationState.LastWriteEventID
+	status.LastReplicationInfo = make(map[string]*persistence.ReplicationInfo, len(replicationState.LastReplicationInfo))
+	for clusterName, info := range replicationState.LastReplicationInfo {
+		status.LastReplicationInfo[clusterName] = &persistence.ReplicationInfo{
+			Version:     info.Version,
+			LastEventID: info.LastEventID,
+		}
+	}
+	return status, nil
+}
+
+// CleanupOrphanedHistoryBranch deletes a history branch which was created for the given workflow execution, but whose
+// execution record was never created, e.g. when the process crashed between appending the first batch of events and
+// creating the execution in StartWorkflowExecution. The execution is the one recorded in the history tree info of the
+// branch, which is how a scanner is expected to find it. Only the first branch of a run can be orphaned this way, so
+// the branch must belong to the tree of the run, whose ID is the run ID, and must not be forked from another branch.
+// As a workflow which is just being started has no execution record either, callers should only pass branches which
+// were created well before.
+func (e *historyEngineImpl) CleanupOrphanedHistoryBranch(ctx ctx.Context, domainUUID string,
+	execution workflow.WorkflowExecution, branchToken []byte) (retError error) {
+
+	domainID, err := validateDomainUUID(common.StringPtr(domainUUID))
+	if err != nil {
+		return err
+	}
+	// without a run ID the cache resolves the current run, which is not necessarily the one owning the branch
+	if uuid.Parse(execution.GetRunId()) == nil {
+		return &workflow.BadRequestError{Message: "RunId is not set or not valid."}
+	}
+	if len(branchToken) == 0 {
+		return &workflow.BadRequestError{Message: "Branch token is not set."}
+	}
+	branch, err := persistence.DeserializeHistoryBranch(branchToken)
+	if err != nil {
+		return &workflow.BadRequestError{Message: "Branch token is not valid."}
+	}
+	// a forked branch, or a branch of another tree, shares its events with executions other than this one
+	if branch.GetTreeID() != execution.GetRunId() || len(branch.Ancestors) != 0 {
+		return &workflow.BadRequestError{Message: "Branch token is not the first branch of the workflow run."}
+	}
+
+	context, release, err := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
+	if err != nil {
+		return err
+	}
+	defer func() { release(retError) }()
+
+	_, err = context.loadWorkflowExecution()
+	if err == nil {
+		return ErrHistoryBranchInUse
+	}
+	if _, ok := err.(*workflow.EntityNotExistsError); !ok {
+		return err
+	}
+
+	e.logger.Info("Deleting orphaned history branch.",
+		tag.WorkflowDomainID(domainID),
+		tag.WorkflowID(execution.GetWorkflowId()),
+		tag.WorkflowRunID(execution.GetRunId()))
+	return e.historyV2Mgr.DeleteHistoryBranch(&persistence.DeleteHistoryBranchRequest{
+		BranchToken: branchToken,
+		ShardID:     common.IntPtr(e.shard.GetShardID()),
+	})
+}
+
+type updateWorkflowAction struct {
+	noop           bool
+	deleteWorkflow bool
+	createDecision bool
+	timerTasks     []persistence.Task
+	transferTasks  []persistence.Task
+}
+
+// checkSignalsBeforeFirstDecision rejects a signal to a cron workflow whose first decision has not been scheduled yet
+// once the signals received by the run reach the limit, all of them are pending until that decision
+func (e *historyEngineImpl) checkSignalsBeforeFirstDecision(
+	domainEntry *cache.DomainCacheEntry,
+	msBuilder mutableState,
+) error {
+
+	executionInfo := msBuilder.GetExecutionInfo()
+	maxAllowedSignals := e.config.MaximumSignalsBeforeFirstDecision(domainEntry.GetInfo().Name)
+	if maxAllowedSignals > 0 && int(executionInfo.SignalCount) >= maxAllowedSignals {
+		e.logger.Info("Execution limit reached for maximum signals before first decision",
+			tag.WorkflowSignalCount(executionInfo.SignalCount),
+			tag.WorkflowID(executionInfo.WorkflowID),
+			tag.WorkflowRunID(executionInfo.RunID),
+			tag.WorkflowDomainID(executionInfo.DomainID))
+		return ErrSignalsLimitExceeded
+	}
+	return nil
+}
+
+// canScheduleDecisionTask returns whether a new decision task can be scheduled for the workflow, a decision task that
+// is scheduled or started but not yet completed suppresses the new one. Only a decision task requested while another
+// one is started by a worker is counted as a suppressed duplicate, it is emitted on the scope of the caller.
+func canScheduleDecisionTask(msBuilder mutableState, shard ShardContext, metricsClient metrics.Client, scope int) bool {
+	if !msBuilder.HasPendingDecisionTask() {
+		return true
+	}
+	if msBuilder.HasInFlightDecisionTask() {
+		domainTag := metrics.DomainUnknownTag()
+		domainID := msBuilder.GetExecutionInfo().DomainID
+		if entry, err := shard.GetDomainCache().GetDomainByID(domainID); err == nil && entry != nil && entry.GetInfo() != nil {
+			domainTag = metrics.DomainTag(entry.GetInfo().Name)
+		}
+		metricsClient.Scope(scope, domainTag).IncCounter(metrics.DuplicateDecisionSuppressedCounter)
+	}
+	return false
+}
+
+func (e *historyEngineImpl) updateWorkflowExecutionWithAction(ctx ctx.Context, scope int, domainID string, execution workflow.WorkflowExecution,
+	action func(builder mutableState, tBuilder *timerBuilder) (*updateWorkflowAction, error)) (retError error) {
+	context, release, err0 := e.historyCache.getOrCreateWorkflowExecutionWithTimeout(ctx, domainID, execution)
+	if err0 != nil {
+		return err0
+	}
+	defer func() { release(retError) }()
+
+Update_History_Loop:
+	for attempt := 0; attempt < conditionalRetryCount; attempt++ {
+		msBuilder, err1 := context.loadWorkflowExecution()
+		if err1 != nil {
+			return err1
+		}
+		tBuilder := e.getTimerBuilder(context.getExecution())
 
 		// conduct caller action
 		postActions, err := action(msBuilder, tBuilder)
@@ -1797,6 +3347,7 @@ Update_History_Loop:
 			if err == ErrStaleState {
 				// Handler detected that cached workflow mutable could potentially be stale
 				// Reload workflow execution history
+				emitUpdateFailure(e.metricsClient, scope, updateFailureCauseStaleState)
 				context.clear()
 				continue Update_History_Loop
 			}
@@ -1823,7 +3374,7 @@ Update_History_Loop:
 
 		if postActions.createDecision {
 			// Create a transfer task to schedule a decision task
-			if !msBuilder.HasPendingDecisionTask() {
+			if canScheduleDecisionTask(msBuilder, e.shard, e.metricsClient, scope) {
 				di, err := msBuilder.AddDecisionTaskScheduledEvent()
 				if err != nil {
 					return &workflow.InternalServiceError{Message: "Failed to add decision scheduled event."}
@@ -1851,6 +3402,7 @@ Update_History_Loop:
 		// We apply the update to execution using optimistic concurrency.  If it fails due to a conflict then reload
 		// the history and try the operation again.
 		if err := context.updateWorkflowExecution(transferTasks, timerTasks, transactionID); err != nil {
+			emitUpdateError(e.metricsClient, scope, err)
 			if err == ErrConflict {
 				continue Update_History_Loop
 			}
@@ -1859,13 +3411,51 @@ Update_History_Loop:
 		e.timerProcessor.NotifyNewTimers(e.currentClusterName, e.shard.GetCurrentTime(e.currentClusterName), timerTasks)
 		return nil
 	}
+	emitUpdateFailure(e.metricsClient, scope, updateFailureCauseMaxAttempts)
 	return ErrMaxAttemptsExceeded
 }
 
-func (e *historyEngineImpl) updateWorkflowExecution(ctx ctx.Context, domainID string, execution workflow.WorkflowExecution,
+// recordAudit hands the audit entry of a successful administrative operation to the audit sink without blocking, the
+// operator is taken from the admin operation context and falls back to the identity given on the request
+func (e *historyEngineImpl) recordAudit(ctx ctx.Context, operation string, domainID string,
+	execution workflow.WorkflowExecution, identity string) {
+	if e.auditSink == nil {
+		return
+	}
+
+	operator, ok := getAdminOperator(ctx)
+	if !ok {
+		operator = identity
+	}
+	e.auditSink.Record(&AuditEntry{
+		Operation:  operation,
+		DomainID:   domainID,
+		WorkflowID: execution.GetWorkflowId(),
+		RunID:      execution.GetRunId(),
+		Operator:   operator,
+		Timestamp:  e.shard.GetTimeSource().Now(),
+	})
+}
+
+// getWorkflowTimeoutTime returns the time a running workflow times out at, the workflow timeout is counted from the
+// start timestamp plus the first decision backoff and is capped by the retry expiration time
+func getWorkflowTimeoutTime(msBuilder mutableState) time.Time {
+	executionInfo := msBuilder.GetExecutionInfo()
+	timeout := executionInfo.StartTimestamp.Add(time.Duration(executionInfo.WorkflowTimeout) * time.Second)
+	if startEvent, ok := msBuilder.GetStartEvent(); ok {
+		backoffSeconds := startEvent.WorkflowExecutionStartedEventAttributes.GetFirstDecisionTaskBackoffSeconds()
+		timeout = timeout.Add(time.Duration(backoffSeconds) * time.Second)
+	}
+	if !executionInfo.ExpirationTime.IsZero() && timeout.After(executionInfo.ExpirationTime) {
+		timeout = executionInfo.ExpirationTime
+	}
+	return timeout
+}
+
+func (e *historyEngineImpl) updateWorkflowExecution(ctx ctx.Context, scope int, domainID string, execution workflow.WorkflowExecution,
 	createDeletionTask, createDecisionTask bool,
 	action func(builder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error)) error {
-	return e.updateWorkflowExecutionWithAction(ctx, domainID, execution,
+	return e.updateWorkflowExecutionWithAction(ctx, scope, domainID, execution,
 		func(builder mutableState, tBuilder *timerBuilder) (*updateWorkflowAction, error) {
 			timerTasks, err := action(builder, tBuilder)
 			if err != nil {
@@ -1902,16 +3492,17 @@ func getWorkflowHistoryCleanupTasksFromShard(
 	} else {
 		retentionInDays = domainEntry.GetRetentionDays(workflowID)
 	}
-	deleteTask := createDeleteHistoryEventTimerTask(tBuilder, retentionInDays)
+	deleteTask := createDeleteHistoryEventTimerTask(shard.GetTimeSource(), tBuilder, retentionInDays)
 	return &persistence.CloseExecutionTask{}, deleteTask, nil
 }
 
-func createDeleteHistoryEventTimerTask(tBuilder *timerBuilder, retentionInDays int32) *persistence.DeleteHistoryEventTask {
+func createDeleteHistoryEventTimerTask(timeSource clock.TimeSource, tBuilder *timerBuilder,
+	retentionInDays int32) *persistence.DeleteHistoryEventTask {
 	retention := time.Duration(retentionInDays) * time.Hour * 24
 	if tBuilder != nil {
 		return tBuilder.createDeleteHistoryEventTimerTask(retention)
 	}
-	expiryTime := clock.NewRealTimeSource().Now().Add(retention)
+	expiryTime := timeSource.Now().Add(retention)
 	return &persistence.DeleteHistoryEventTask{
 		VisibilityTimestamp: expiryTime,
 	}
@@ -1946,19 +3537,95 @@ func (e *historyEngineImpl) failDecision(context workflowExecutionContext, sched
 		return nil, err
 	}
 
+	var attempt int64
+	if di, ok := msBuilder.GetPendingDecision(scheduleID); ok {
+		attempt = di.Attempt
+	}
+
 	if _, err = msBuilder.AddDecisionTaskFailedEvent(
 		scheduleID, startedID, cause, nil, request.GetIdentity(), "", "", "", 0,
 	); err != nil {
 		return nil, err
 	}
 
+	if _, err = e.terminateIfDecisionAttemptsExceedLimit(
+		msBuilder, attempt, metrics.HistoryRespondDecisionTaskCompletedScope,
+	); err != nil {
+		return nil, err
+	}
+
 	// Return new builder back to the caller for further updates
 	return msBuilder, nil
 }
 
+// terminateIfDecisionAttemptsExceedLimit terminates the workflow once a decision failed on its last allowed attempt,
+// the returned bool indicates whether the workflow got terminated
+func (e *historyEngineImpl) terminateIfDecisionAttemptsExceedLimit(msBuilder mutableState, attempt int64,
+	scope int) (bool, error) {
+
+	executionInfo := msBuilder.GetExecutionInfo()
+	domainEntry, err := e.shard.GetDomainCache().GetDomainByID(executionInfo.DomainID)
+	if err != nil {
+		return false, err
+	}
+	domainName := domainEntry.GetInfo().Name
+
+	maxAttempts := int64(e.config.MaximumDecisionTaskAttempts(domainName))
+	if maxAttempts <= 0 || attempt+1 < maxAttempts {
+		return false, nil
+	}
+
+	e.metricsClient.Scope(scope, metrics.DomainTag(domainName)).IncCounter(metrics.DecisionAttemptsLimitExceededCounter)
+	e.logger.Warn("Decision task attempts exceed limit, terminating workflow.",
+		tag.WorkflowDomainID(executionInfo.DomainID),
+		tag.WorkflowID(executionInfo.WorkflowID),
+		tag.WorkflowRunID(executionInfo.RunID),
+		tag.Attempt(int32(attempt)))
+
+	if _, err := msBuilder.AddWorkflowExecutionTerminatedEvent(
+		common.FailureReasonDecisionAttemptsExceedsLimit,
+		[]byte(fmt.Sprintf("decision task failed %v times", attempt+1)),
+		identityHistoryService,
+	); err != nil {
+		return false, &workflow.InternalServiceError{Message: "Unable to terminate workflow execution."}
+	}
+	return true, nil
+}
+
+func (e *historyEngineImpl) terminateIfHistoryLengthExceedsLimit(msBuilder mutableState, scope int) (bool, error) {
+	executionInfo := msBuilder.GetExecutionInfo()
+	domainEntry, err := e.shard.GetDomainCache().GetDomainByID(executionInfo.DomainID)
+	if err != nil {
+		return false, err
+	}
+	domainName := domainEntry.GetInfo().Name
+
+	maxLength := int64(e.config.MaximumHistoryLength(domainName))
+	historyLength := msBuilder.GetNextEventID() - common.FirstEventID
+	if maxLength <= 0 || historyLength < maxLength {
+		return false, nil
+	}
+
+	e.metricsClient.Scope(scope, metrics.DomainTag(domainName)).IncCounter(metrics.HistoryLengthLimitExceededCounter)
+	e.logger.Warn("History length exceeds limit, terminating workflow.",
+		tag.WorkflowDomainID(executionInfo.DomainID),
+		tag.WorkflowID(executionInfo.WorkflowID),
+		tag.WorkflowRunID(executionInfo.RunID),
+		tag.WorkflowNextEventID(msBuilder.GetNextEventID()))
+
+	if _, err := msBuilder.AddWorkflowExecutionTerminatedEvent(
+		common.FailureReasonHistoryLengthExceedsLimit,
+		[]byte(fmt.Sprintf("history length %v exceeds limit %v", historyLength, maxLength)),
+		identityHistoryService,
+	); err != nil {
+		return false, &workflow.InternalServiceError{Message: "Unable to terminate workflow execution."}
+	}
+	return true, nil
+}
+
 func (e *historyEngineImpl) getTimerBuilder(we *workflow.WorkflowExecution) *timerBuilder {
 	log := e.logger.WithTags(tag.WorkflowID(we.GetWorkflowId()), tag.WorkflowRunID(we.GetRunId()))
-	return newTimerBuilder(e.shard.GetConfig(), log, clock.NewRealTimeSource())
+	return newTimerBuilder(e.shard.GetConfig(), log, e.shard.GetTimeSource())
 }
 
 func (s *shardContextWrapper) UpdateWorkflowExecution(request *persistence.UpdateWorkflowExecutionRequest) (*persistence.UpdateWorkflowExecutionResponse, error) {
@@ -1990,7 +3657,19 @@ func (s *shardContextWrapper) NotifyNewHistoryEvent(event *historyEventNotificat
 	return err
 }
 
-func validateStartWorkflowExecutionRequest(request *workflow.StartWorkflowExecutionRequest, maxIDLengthLimit int) error {
+func validateSignalName(signalName string, maxIDLengthLimit int) error {
+	if signalName == "" {
+		return &workflow.BadRequestError{Message: "Missing SignalName."}
+	}
+	if len(signalName) > maxIDLengthLimit {
+		return &workflow.BadRequestError{Message: "SignalName exceeds length limit."}
+	}
+	return nil
+}
+
+func validateStartWorkflowExecutionRequest(request *workflow.StartWorkflowExecutionRequest, maxIDLengthLimit int,
+	maxNonRetriableErrorReasonsCount int, maxNonRetriableErrorReasonsLength int,
+	searchAttributesValidator *common.VisibilityQueryValidator) error {
 	if len(request.GetRequestId()) == 0 {
 		return &workflow.BadRequestError{Message: "Missing request ID."}
 	}
@@ -2018,8 +3697,57 @@ func validateStartWorkflowExecutionRequest(request *workflow.StartWorkflowExecut
 	if len(request.WorkflowType.GetName()) > maxIDLengthLimit {
 		return &workflow.BadRequestError{Message: "WorkflowType exceeds length limit."}
 	}
+	if request.FirstDecisionTaskStartToCloseTimeoutSeconds != nil &&
+		(request.GetFirstDecisionTaskStartToCloseTimeoutSeconds() <= 0 ||
+			request.GetFirstDecisionTaskStartToCloseTimeoutSeconds() > request.GetExecutionStartToCloseTimeoutSeconds()) {
+		return &workflow.BadRequestError{Message: "Invalid FirstDecisionTaskStartToCloseTimeoutSeconds."}
+	}
+	if err := searchAttributesValidator.ValidateSearchAttributeKeys(request.SearchAttributes); err != nil {
+		return err
+	}
 
-	return common.ValidateRetryPolicy(request.RetryPolicy)
+	if err := common.ValidateRetryPolicy(request.RetryPolicy); err != nil {
+		return err
+	}
+	if err := backoff.ValidateScheduleWithRetryPolicy(request.GetCronSchedule(), request.RetryPolicy); err != nil {
+		return err
+	}
+	return common.ValidateRetryPolicyNonRetriableErrorReasons(request.RetryPolicy,
+		maxNonRetriableErrorReasonsCount, maxNonRetriableErrorReasonsLength)
+}
+
+// checkShardBackpressure rejects starting a new workflow while the shard is too far behind on processing its transfer
+// or timer tasks, so that clients back off and the shard can catch up instead of accumulating more tasks
+func (e *historyEngineImpl) checkShardBackpressure(domainName string, scope int) error {
+	if !e.config.EnableShardBackpressure(domainName) {
+		return nil
+	}
+
+	// the gap between the ack and read levels is not used here, an idle shard never moves its timer ack level
+	// and the transfer max read level jumps ahead whenever the shard renews its range
+	transferLag := e.txProcessor.describeStatus().PendingTasks
+	timerLag := time.Duration(0)
+	if oldest := e.timerProcessor.describeStatus().OldestPendingTaskTimestamp; oldest > 0 {
+		timerLag = e.shard.GetCurrentTime(e.currentClusterName).Sub(time.Unix(0, oldest))
+	}
+	if transferLag > int64(e.config.ShardBackpressureMaxTransferLag(domainName)) ||
+		timerLag > e.config.ShardBackpressureMaxTimerLag(domainName) {
+		e.metricsClient.Scope(scope, metrics.DomainTag(domainName)).IncCounter(metrics.ShardBackpressureRejectedCounter)
+		return ErrShardBackpressure
+	}
+	return nil
+}
+
+// checkDomainDraining rejects starting a new workflow in a domain which is being drained for decommission, signals
+// and decisions of its existing workflows, including the child workflows they start, are not affected
+func (e *historyEngineImpl) checkDomainDraining(domainEntry *cache.DomainCacheEntry, scope int) error {
+	domainName := domainEntry.GetInfo().Name
+	if !e.config.DomainDraining(domainName) {
+		return nil
+	}
+
+	e.metricsClient.Scope(scope, metrics.DomainTag(domainName)).IncCounter(metrics.DomainDrainingRejectedCounter)
+	return ce.NewDomainDrainingError(domainName, e.currentClusterName)
 }
 
 func validateDomainUUID(domainUUID *string) (string, error) {
@@ -2051,6 +3779,45 @@ func getActiveDomainEntryFromShard(shard ShardContext, domainUUID *string) (*cac
 	return domainEntry, nil
 }
 
+func (e *historyEngineImpl) emitActivityTypeCounter(scope int, domainName string, activityTypeName string, counter int) {
+	e.metricsClient.Scope(
+		scope,
+		metrics.DomainTag(domainName),
+		metrics.ActivityTypeTag(activityTypeName),
+	).IncCounter(counter)
+}
+
+// emitUpdateFailure emits the conditional update failure counter for the operation of the given scope,
+// tagged with the cause of the failure
+func emitUpdateFailure(metricsClient metrics.Client, scope int, cause string) {
+	metricsClient.Scope(
+		scope,
+		metrics.UpdateFailureCauseTag(cause),
+	).IncCounter(metrics.ConditionalUpdateFailureCounter)
+}
+
+// emitUpdateError emits the conditional update failure counter if the error returned by a conditional update
+// is caused by a conflict or by exceeding the transaction size limit
+func emitUpdateError(metricsClient metrics.Client, scope int, err error) {
+	if err == ErrConflict {
+		emitUpdateFailure(metricsClient, scope, updateFailureCauseConflict)
+		return
+	}
+	if _, ok := err.(*persistence.TransactionSizeLimitError); ok {
+		emitUpdateFailure(metricsClient, scope, updateFailureCauseTransactionSize)
+	}
+}
+
+// getActivityTypeName returns the activity type from the scheduled event of the activity,
+// or empty string if the scheduled event cannot be loaded
+func getActivityTypeName(msBuilder mutableState, scheduleID int64) string {
+	scheduledEvent, ok := msBuilder.GetActivityScheduledEvent(scheduleID)
+	if !ok {
+		return ""
+	}
+	return scheduledEvent.ActivityTaskScheduledEventAttributes.ActivityType.GetName()
+}
+
 func getScheduleID(activityID string, msBuilder mutableState) (int64, error) {
 	if activityID == "" {
 		return 0, &workflow.BadRequestError{Message: "Neither ActivityID nor ScheduleID is provided"}
@@ -2059,6 +3826,19 @@ func getScheduleID(activityID string, msBuilder mutableState) (int64, error) {
 	if !ok {
 		return 0, &workflow.BadRequestError{Message: fmt.Sprintf("No such activityID: %s\n", activityID)}
 	}
+	// activity IDs are unique among pending activities unless the mutable state was replicated or loaded with
+	// duplicates, in which case responding by ID could silently target the wrong activity
+	pendingWithID := 0
+	for _, ai := range msBuilder.GetPendingActivityInfos() {
+		if ai.ActivityID == activityID {
+			pendingWithID++
+		}
+	}
+	if pendingWithID > 1 {
+		return 0, &workflow.BadRequestError{
+			Message: fmt.Sprintf("Multiple pending activities with activityID: %s, use task token instead\n", activityID),
+		}
+	}
 	return scheduleID, nil
 }
 
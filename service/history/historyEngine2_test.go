@@ -24,7 +24,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/mock"
@@ -36,6 +38,7 @@ import (
 	"github.com/uber/cadence/client"
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/loggerimpl"
@@ -45,6 +48,7 @@ import (
 	"github.com/uber/cadence/common/mocks"
 	p "github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/service"
+	"github.com/uber/cadence/common/service/dynamicconfig"
 	"github.com/uber/cadence/service/worker/archiver"
 )
 
@@ -186,6 +190,7 @@ func (s *engine2Suite) TestRecordDecisionTaskStartedSuccessStickyEnabled() {
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
 	executionInfo := msBuilder.GetExecutionInfo()
 	executionInfo.StickyTaskList = stickyTl
+	executionInfo.StickyScheduleToStartTimeout = 10
 
 	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
 	di := addDecisionTaskScheduledEvent(msBuilder)
@@ -237,6 +242,90 @@ func (s *engine2Suite) TestRecordDecisionTaskStartedSuccessStickyEnabled() {
 	expectedResponse.StickyExecutionEnabled = common.BoolPtr(true)
 	expectedResponse.NextEventId = common.Int64Ptr(msBuilder.GetNextEventID() + 1)
 	expectedResponse.Attempt = common.Int64Ptr(di.Attempt)
+	expectedResponse.WorkflowExecutionTaskList = common.TaskListPtr(workflow.TaskList{
+		Name: &executionInfo.TaskList,
+		Kind: common.TaskListKindPtr(workflow.TaskListKindSticky),
+	})
+	expectedResponse.StickyScheduleToStartTimeoutSeconds = common.Int32Ptr(10)
+	expectedResponse.EventStoreVersion = common.Int32Ptr(p.EventStoreVersionV2)
+	expectedResponse.BranchToken = msBuilder.GetCurrentBranch()
+
+	response, err := s.historyEngine.RecordDecisionTaskStarted(context.Background(), &request)
+	s.Nil(err)
+	s.NotNil(response)
+	expectedResponse.StartedTimestamp = response.StartedTimestamp
+	expectedResponse.ScheduledTimestamp = common.Int64Ptr(0)
+	expectedResponse.ScheduledTimestampOfThisAttempt = common.Int64Ptr(0)
+	s.Equal(&expectedResponse, response)
+}
+
+func (s *engine2Suite) TestRecordDecisionTaskStartedSuccessStickyEnabledNormalTaskList() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	stickyTl := "stickyTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2("test", s.historyEngine.shard, s.mockEventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	executionInfo := msBuilder.GetExecutionInfo()
+	executionInfo.StickyTaskList = stickyTl
+	executionInfo.StickyScheduleToStartTimeout = 10
+
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+
+	ms := createMutableState(msBuilder)
+
+	gwmsResponse := &p.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(nil, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&p.GetDomainResponse{
+			Info:   &p.DomainInfo{ID: domainID},
+			Config: &p.DomainConfig{Retention: 1},
+			ReplicationConfig: &p.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*p.ClusterReplicationConfig{
+					&p.ClusterReplicationConfig{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: p.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	request := h.RecordDecisionTaskStartedRequest{
+		DomainUUID:        common.StringPtr(domainID),
+		WorkflowExecution: &we,
+		ScheduleId:        common.Int64Ptr(2),
+		TaskId:            common.Int64Ptr(100),
+		RequestId:         common.StringPtr("reqId"),
+		// the decision was dispatched on the normal task list, e.g. after the sticky schedule to start timeout
+		PollRequest: &workflow.PollForDecisionTaskRequest{
+			TaskList: &workflow.TaskList{
+				Name: common.StringPtr(tl),
+			},
+			Identity: common.StringPtr(identity),
+		},
+	}
+
+	expectedResponse := h.RecordDecisionTaskStartedResponse{}
+	expectedResponse.WorkflowType = msBuilder.GetWorkflowType()
+	executionInfo = msBuilder.GetExecutionInfo()
+	if executionInfo.LastProcessedEvent != common.EmptyEventID {
+		expectedResponse.PreviousStartedEventId = common.Int64Ptr(executionInfo.LastProcessedEvent)
+	}
+	expectedResponse.ScheduledEventId = common.Int64Ptr(di.ScheduleID)
+	expectedResponse.StartedEventId = common.Int64Ptr(di.ScheduleID + 1)
+	expectedResponse.StickyExecutionEnabled = common.BoolPtr(false)
+	expectedResponse.NextEventId = common.Int64Ptr(msBuilder.GetNextEventID() + 1)
+	expectedResponse.Attempt = common.Int64Ptr(di.Attempt)
 	expectedResponse.WorkflowExecutionTaskList = common.TaskListPtr(workflow.TaskList{
 		Name: &executionInfo.TaskList,
 		Kind: common.TaskListKindPtr(workflow.TaskListKindNormal),
@@ -249,9 +338,146 @@ func (s *engine2Suite) TestRecordDecisionTaskStartedSuccessStickyEnabled() {
 	s.NotNil(response)
 	expectedResponse.StartedTimestamp = response.StartedTimestamp
 	expectedResponse.ScheduledTimestamp = common.Int64Ptr(0)
+	expectedResponse.ScheduledTimestampOfThisAttempt = common.Int64Ptr(0)
 	s.Equal(&expectedResponse, response)
 }
 
+func (s *engine2Suite) TestRecordDecisionTaskStartedFirstDecisionLatency() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	now := time.Now()
+	timeSource := clock.NewEventTimeSource()
+	timeSource.Update(now)
+	shard := s.historyEngine.shard.(*shardContextImpl)
+	shard.timeSource = timeSource
+	domainCache := &cache.DomainCacheMock{}
+	domainCache.On("GetDomainByID", mock.Anything).Return(cache.NewLocalDomainCacheEntryForTest(
+		&p.DomainInfo{ID: domainID, Name: "testDomain"}, &p.DomainConfig{}, "", nil,
+	), nil)
+	shard.domainCache = domainCache
+	scope := tally.NewTestScope("test", nil)
+	s.historyEngine.metricsClient = metrics.NewClient(scope, metrics.History)
+	s.historyEngine.decisionHandler = newDecisionHandler(s.historyEngine)
+
+	msBuilder := newMutableStateBuilderWithEventV2("test", s.historyEngine.shard, s.mockEventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
+	ms := createMutableState(msBuilder)
+	ms.ExecutionInfo.StartTimestamp = now.Add(-5 * time.Second)
+	gwmsResponse := &p.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(nil, nil).Once()
+
+	request := h.RecordDecisionTaskStartedRequest{
+		DomainUUID:        common.StringPtr(domainID),
+		WorkflowExecution: &we,
+		ScheduleId:        common.Int64Ptr(2),
+		TaskId:            common.Int64Ptr(100),
+		RequestId:         common.StringPtr("reqId"),
+		PollRequest: &workflow.PollForDecisionTaskRequest{
+			TaskList: &workflow.TaskList{
+				Name: common.StringPtr(tl),
+			},
+			Identity: common.StringPtr(identity),
+		},
+	}
+	_, err := s.historyEngine.RecordDecisionTaskStarted(context.Background(), &request)
+	s.Nil(err)
+
+	var latencies []time.Duration
+	for _, timer := range scope.Snapshot().Timers() {
+		if timer.Name() == "test.workflow_start_to_first_decision_latency" {
+			s.Equal("testDomain", timer.Tags()["domain"])
+			s.Equal(tl, timer.Tags()["tasklist"])
+			latencies = append(latencies, timer.Values()...)
+		}
+	}
+	s.Equal([]time.Duration{5 * time.Second}, latencies)
+}
+
+func (s *engine2Suite) TestRecordDecisionTaskStartedTransientDecision() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2("test", s.historyEngine.shard, s.mockEventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	s.Equal(di.ScheduledTimestamp, di.OriginalScheduledTimestamp)
+	firstScheduledTimestamp := di.ScheduledTimestamp
+	startedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	_, err := msBuilder.AddDecisionTaskFailedEvent(di.ScheduleID, startedEvent.GetEventId(),
+		workflow.DecisionTaskFailedCauseUnhandledDecision, nil, identity, "", "", "", 0)
+	s.Nil(err)
+	// the retried decision is transient, it is only kept in mutable state with its own scheduled time
+	di = addDecisionTaskScheduledEvent(msBuilder)
+	s.Equal(int64(1), di.Attempt)
+	s.Equal(firstScheduledTimestamp, di.OriginalScheduledTimestamp)
+	firstScheduledTimestamp = time.Now().Add(-time.Minute).UnixNano()
+	attemptScheduledTimestamp := time.Now().Add(-time.Second).UnixNano()
+
+	ms := createMutableState(msBuilder)
+	ms.ExecutionInfo.DecisionScheduledTimestamp = attemptScheduledTimestamp
+	ms.ExecutionInfo.DecisionOriginalScheduledTimestamp = firstScheduledTimestamp
+
+	gwmsResponse := &p.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(nil, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&p.GetDomainResponse{
+			Info:   &p.DomainInfo{ID: domainID},
+			Config: &p.DomainConfig{Retention: 1},
+			ReplicationConfig: &p.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*p.ClusterReplicationConfig{
+					&p.ClusterReplicationConfig{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: p.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	request := h.RecordDecisionTaskStartedRequest{
+		DomainUUID:        common.StringPtr(domainID),
+		WorkflowExecution: &we,
+		ScheduleId:        common.Int64Ptr(di.ScheduleID),
+		TaskId:            common.Int64Ptr(100),
+		RequestId:         common.StringPtr("reqId"),
+		PollRequest: &workflow.PollForDecisionTaskRequest{
+			TaskList: &workflow.TaskList{
+				Name: common.StringPtr(tl),
+			},
+			Identity: common.StringPtr(identity),
+		},
+	}
+
+	response, err := s.historyEngine.RecordDecisionTaskStarted(context.Background(), &request)
+	s.Nil(err)
+	s.NotNil(response)
+	s.Equal(int64(1), response.GetAttempt())
+	s.Equal(firstScheduledTimestamp, response.GetScheduledTimestamp())
+	s.Equal(attemptScheduledTimestamp, response.GetScheduledTimestampOfThisAttempt())
+	s.NotEqual(response.GetScheduledTimestamp(), response.GetScheduledTimestampOfThisAttempt())
+	s.NotNil(response.DecisionInfo)
+	s.Equal(attemptScheduledTimestamp, response.DecisionInfo.ScheduledEvent.GetTimestamp())
+}
+
 func (s *engine2Suite) TestRecordDecisionTaskStartedIfNoExecution() {
 	domainID := validDomainID
 	workflowExecution := &workflow.WorkflowExecution{
@@ -722,6 +948,10 @@ func (s *engine2Suite) TestRecordDecisionTaskSuccess() {
 	s.Equal("wType", *response.WorkflowType.Name)
 	s.True(response.PreviousStartedEventId == nil)
 	s.Equal(int64(3), *response.StartedEventId)
+	s.False(response.GetStickyExecutionEnabled())
+	s.Equal(tl, response.WorkflowExecutionTaskList.GetName())
+	s.Equal(workflow.TaskListKindNormal, response.WorkflowExecutionTaskList.GetKind())
+	s.Nil(response.StickyScheduleToStartTimeoutSeconds)
 }
 
 func (s *engine2Suite) TestRecordActivityTaskStartedIfNoExecution() {
@@ -1049,28 +1279,14 @@ func (s *engine2Suite) TestStartWorkflowExecution_BrandNew() {
 	})
 	s.Nil(err)
 	s.NotNil(resp.RunId)
+	s.Equal(int64(3), resp.GetNextEventId())
 }
 
-func (s *engine2Suite) TestStartWorkflowExecution_StillRunning_Dedup() {
+func (s *engine2Suite) TestStartWorkflowExecution_PersistenceTimeout() {
 	domainID := validDomainID
-	workflowID := "workflowID"
-	runID := "runID"
-	workflowType := "workflowType"
-	taskList := "testTaskList"
-	identity := "testIdentity"
-	requestID := "requestID"
-	lastWriteVersion := common.EmptyVersion
 
 	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything).Return(nil, &p.WorkflowExecutionAlreadyStartedError{
-		Msg:              "random message",
-		StartRequestID:   requestID,
-		RunID:            runID,
-		State:            p.WorkflowStateRunning,
-		CloseStatus:      p.WorkflowCloseStatusNone,
-		LastWriteVersion: lastWriteVersion,
-	}).Once()
-	s.mockHistoryV2Mgr.On("DeleteHistoryBranch", mock.Anything).Return(nil).Once()
+	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything).Return(nil, &p.TimeoutError{Msg: "random timeout"}).Once()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&p.GetDomainResponse{
 			Info:   &p.DomainInfo{ID: domainID},
@@ -1085,43 +1301,31 @@ func (s *engine2Suite) TestStartWorkflowExecution_StillRunning_Dedup() {
 		},
 		nil,
 	)
-
 	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &h.StartWorkflowExecutionRequest{
 		DomainUUID: common.StringPtr(domainID),
 		StartRequest: &workflow.StartWorkflowExecutionRequest{
 			Domain:                              common.StringPtr(domainID),
-			WorkflowId:                          common.StringPtr(workflowID),
-			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr(workflowType)},
-			TaskList:                            &workflow.TaskList{Name: common.StringPtr(taskList)},
+			WorkflowId:                          common.StringPtr("workflowID"),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("workflowType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr("testTaskList")},
 			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
 			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
-			Identity:                            common.StringPtr(identity),
-			RequestId:                           common.StringPtr(requestID),
+			Identity:                            common.StringPtr("testIdentity"),
+			RequestId:                           common.StringPtr(uuid.New()),
 		},
+		WaitForFirstDecisionScheduled: common.BoolPtr(true),
 	})
-	s.Nil(err)
-	s.Equal(runID, resp.GetRunId())
+	s.IsType(&p.TimeoutError{}, err)
+	// the caller can correlate the outcome of the start with the run ID and next event ID
+	s.NotNil(resp.RunId)
+	s.Equal(int64(3), resp.GetNextEventId())
 }
 
-func (s *engine2Suite) TestStartWorkflowExecution_StillRunning_NonDeDup() {
+func (s *engine2Suite) TestStartWorkflowExecution_WaitForFirstDecisionScheduled() {
 	domainID := validDomainID
-	workflowID := "workflowID"
-	runID := "runID"
-	workflowType := "workflowType"
-	taskList := "testTaskList"
-	identity := "testIdentity"
-	lastWriteVersion := common.EmptyVersion
 
 	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything).Return(nil, &p.WorkflowExecutionAlreadyStartedError{
-		Msg:              "random message",
-		StartRequestID:   "oldRequestID",
-		RunID:            runID,
-		State:            p.WorkflowStateRunning,
-		CloseStatus:      p.WorkflowCloseStatusNone,
-		LastWriteVersion: lastWriteVersion,
-	}).Once()
-	s.mockHistoryV2Mgr.On("DeleteHistoryBranch", mock.Anything).Return(nil).Once()
+	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything).Return(&p.CreateWorkflowExecutionResponse{}, nil).Once()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&p.GetDomainResponse{
 			Info:   &p.DomainInfo{ID: domainID},
@@ -1136,39 +1340,42 @@ func (s *engine2Suite) TestStartWorkflowExecution_StillRunning_NonDeDup() {
 		},
 		nil,
 	)
-
-	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &h.StartWorkflowExecutionRequest{
+	request := &h.StartWorkflowExecutionRequest{
 		DomainUUID: common.StringPtr(domainID),
 		StartRequest: &workflow.StartWorkflowExecutionRequest{
 			Domain:                              common.StringPtr(domainID),
-			WorkflowId:                          common.StringPtr(workflowID),
-			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr(workflowType)},
-			TaskList:                            &workflow.TaskList{Name: common.StringPtr(taskList)},
+			WorkflowId:                          common.StringPtr("workflowID"),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("workflowType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr("testTaskList")},
 			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
 			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
-			Identity:                            common.StringPtr(identity),
-			RequestId:                           common.StringPtr("newRequestID"),
+			Identity:                            common.StringPtr("testIdentity"),
+			RequestId:                           common.StringPtr(uuid.New()),
 		},
-	})
-	if _, ok := err.(*workflow.WorkflowExecutionAlreadyStartedError); !ok {
-		s.Fail("return err is not *shared.WorkflowExecutionAlreadyStartedError")
+		WaitForFirstDecisionScheduled: common.BoolPtr(true),
 	}
+	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), request)
+	s.Nil(err)
+	s.NotNil(resp.RunId)
+	// workflow started and decision scheduled events
+	s.Equal(int64(3), resp.GetNextEventId())
+
+	// a cron workflow does not schedule its first decision on start
+	request.FirstDecisionTaskBackoffSeconds = common.Int32Ptr(60)
+	resp, err = s.historyEngine.StartWorkflowExecution(context.Background(), request)
 	s.Nil(resp)
+	s.Equal(ErrFirstDecisionDelayed, err)
 }
 
-func (s *engine2Suite) TestStartWorkflowExecution_TimeoutError() {
+func (s *engine2Suite) TestStartWorkflowExecution_CallerProvidedRunID() {
 	domainID := validDomainID
-	workflowID := "workflowID"
-	workflowType := "workflowType"
-	taskList := "testTaskList"
-	identity := "testIdentity"
+	runID := uuid.New()
+
+	enableCallerProvidedRunID := s.config.EnableCallerProvidedRunID
+	defer func() { s.config.EnableCallerProvidedRunID = enableCallerProvidedRunID }()
 
 	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything).Return(nil, &p.TimeoutError{
-		Msg: "random message",
-	}).Once()
-	// NOTE: should not delete history for timeout because timeout is unknown instead of failure
-	//s.mockHistoryV2Mgr.On("DeleteHistoryBranch", mock.Anything).Return(nil).Once()
+	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything).Return(&p.CreateWorkflowExecutionResponse{}, nil).Once()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&p.GetDomainResponse{
 			Info:   &p.DomainInfo{ID: domainID},
@@ -1183,30 +1390,378 @@ func (s *engine2Suite) TestStartWorkflowExecution_TimeoutError() {
 		},
 		nil,
 	)
-
-	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &h.StartWorkflowExecutionRequest{
+	request := &h.StartWorkflowExecutionRequest{
 		DomainUUID: common.StringPtr(domainID),
 		StartRequest: &workflow.StartWorkflowExecutionRequest{
 			Domain:                              common.StringPtr(domainID),
-			WorkflowId:                          common.StringPtr(workflowID),
-			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr(workflowType)},
-			TaskList:                            &workflow.TaskList{Name: common.StringPtr(taskList)},
+			WorkflowId:                          common.StringPtr("workflowID"),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("workflowType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr("testTaskList")},
 			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
 			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
-			Identity:                            common.StringPtr(identity),
-			RequestId:                           common.StringPtr("newRequestID"),
+			Identity:                            common.StringPtr("testIdentity"),
+			RequestId:                           common.StringPtr(uuid.New()),
 		},
-	})
-	s.True(p.IsTimeoutError(err))
-	s.NotNil(resp)
+		RunId: common.StringPtr(runID),
+	}
+
+	// disabled by default
+	_, err := s.historyEngine.StartWorkflowExecution(context.Background(), request)
+	s.IsType(&workflow.BadRequestError{}, err)
+
+	s.config.EnableCallerProvidedRunID = dynamicconfig.GetBoolPropertyFnFilteredByDomain(true)
+	request.RunId = common.StringPtr("not a uuid")
+	_, err = s.historyEngine.StartWorkflowExecution(context.Background(), request)
+	s.EqualError(err, "BadRequestError{Message: Invalid RunId.}")
+
+	// a run ID which already has a history in the shard collides with it
+	s.mockHistoryV2Mgr.On("GetHistoryTree", &p.GetHistoryTreeRequest{
+		TreeID:  runID,
+		ShardID: common.IntPtr(s.historyEngine.shard.GetShardID()),
+	}).Return(&p.GetHistoryTreeResponse{Branches: []*workflow.HistoryBranch{{TreeID: common.StringPtr(runID)}}}, nil).Once()
+	request.RunId = common.StringPtr(runID)
+	_, err = s.historyEngine.StartWorkflowExecution(context.Background(), request)
+	s.EqualError(err, "BadRequestError{Message: RunId already exists.}")
+
+	s.mockHistoryV2Mgr.On("GetHistoryTree", mock.Anything).Return(&p.GetHistoryTreeResponse{}, nil).Once()
+	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), request)
+	s.Nil(err)
+	s.Equal(runID, resp.GetRunId())
+	createRequest := s.mockExecutionMgr.Calls[len(s.mockExecutionMgr.Calls)-1].Arguments.Get(0).(*p.CreateWorkflowExecutionRequest)
+	s.Equal(runID, createRequest.Execution.GetRunId())
 }
 
-func (s *engine2Suite) TestStartWorkflowExecution_NotRunning_PrevSuccess() {
+func (s *engine2Suite) TestStartWorkflowExecution_ShardBackpressure() {
 	domainID := validDomainID
-	workflowID := "workflowID"
-	runID := "runID"
-	workflowType := "workflowType"
-	taskList := "testTaskList"
+	transferAckMgr := s.historyEngine.txProcessor.(*transferQueueProcessorImpl).activeTaskProcessor.ackMgr.(*queueAckMgrImpl)
+	timerAckMgr := s.historyEngine.timerProcessor.(*timerQueueProcessorImpl).activeTimerProcessor.timerQueueAckMgr.(*timerQueueAckMgrImpl)
+	for taskID := int64(1); taskID <= 101; taskID++ {
+		transferAckMgr.outstandingTasks[taskID] = false
+	}
+	// completed tasks are not counted
+	transferAckMgr.outstandingTasks[102] = true
+	defer func() {
+		transferAckMgr.outstandingTasks = make(map[int64]bool)
+		timerAckMgr.outstandingTasks = make(map[TimerSequenceID]bool)
+	}()
+
+	enableShardBackpressure := s.config.EnableShardBackpressure
+	maxTransferLag := s.config.ShardBackpressureMaxTransferLag
+	maxTimerLag := s.config.ShardBackpressureMaxTimerLag
+	defer func() {
+		s.config.EnableShardBackpressure = enableShardBackpressure
+		s.config.ShardBackpressureMaxTransferLag = maxTransferLag
+		s.config.ShardBackpressureMaxTimerLag = maxTimerLag
+	}()
+	s.config.EnableShardBackpressure = dynamicconfig.GetBoolPropertyFnFilteredByDomain(true)
+	s.config.ShardBackpressureMaxTransferLag = dynamicconfig.GetIntPropertyFilteredByDomain(100)
+	s.config.ShardBackpressureMaxTimerLag = func(domain string) time.Duration { return time.Minute }
+
+	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &h.StartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		StartRequest: &workflow.StartWorkflowExecutionRequest{
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          common.StringPtr("workflowID"),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("workflowType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr("testTaskList")},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            common.StringPtr("testIdentity"),
+			RequestId:                           common.StringPtr(uuid.New()),
+		},
+	})
+	s.Nil(resp)
+	s.IsType(&workflow.ServiceBusyError{}, err)
+	s.mockExecutionMgr.AssertNotCalled(s.T(), "CreateWorkflowExecution", mock.Anything)
+
+	// transfer queue caught up, an idle timer queue is never behind
+	transferAckMgr.outstandingTasks[101] = true
+	s.Nil(s.historyEngine.checkShardBackpressure("", metrics.HistoryStartWorkflowExecutionScope))
+
+	now := s.historyEngine.shard.GetCurrentTime(s.historyEngine.currentClusterName)
+	timerAckMgr.outstandingTasks[TimerSequenceID{VisibilityTimestamp: now.Add(-30 * time.Second), TaskID: 1}] = false
+	timerAckMgr.outstandingTasks[TimerSequenceID{VisibilityTimestamp: now.Add(-2 * time.Minute), TaskID: 2}] = true
+	s.Nil(s.historyEngine.checkShardBackpressure("", metrics.HistoryStartWorkflowExecutionScope))
+	timerAckMgr.outstandingTasks[TimerSequenceID{VisibilityTimestamp: now.Add(-2 * time.Minute), TaskID: 3}] = false
+	s.Equal(ErrShardBackpressure, s.historyEngine.checkShardBackpressure("", metrics.HistoryStartWorkflowExecutionScope))
+
+	// disabled by default
+	s.config.EnableShardBackpressure = dynamicconfig.GetBoolPropertyFnFilteredByDomain(false)
+	s.Nil(s.historyEngine.checkShardBackpressure("", metrics.HistoryStartWorkflowExecutionScope))
+}
+
+func (s *engine2Suite) TestStartWorkflowExecution_DomainDraining() {
+	domainID := validDomainID
+	domainDraining := s.config.DomainDraining
+	defer func() { s.config.DomainDraining = domainDraining }()
+	s.config.DomainDraining = dynamicconfig.GetBoolPropertyFnFilteredByDomain(true)
+
+	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &h.StartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		StartRequest: &workflow.StartWorkflowExecutionRequest{
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          common.StringPtr("workflowID"),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("workflowType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr("testTaskList")},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            common.StringPtr("testIdentity"),
+			RequestId:                           common.StringPtr(uuid.New()),
+		},
+	})
+	s.Nil(resp)
+	s.IsType(&workflow.DomainNotActiveError{}, err)
+	s.Contains(err.(*workflow.DomainNotActiveError).Message, "draining")
+	s.mockExecutionMgr.AssertNotCalled(s.T(), "CreateWorkflowExecution", mock.Anything)
+
+	// child workflows started by existing workflows are not rejected
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything).Return(&p.CreateWorkflowExecutionResponse{}, nil).Once()
+	resp, err = s.historyEngine.StartWorkflowExecution(context.Background(), &h.StartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		StartRequest: &workflow.StartWorkflowExecutionRequest{
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          common.StringPtr("childWorkflowID"),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("workflowType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr("testTaskList")},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            common.StringPtr("testIdentity"),
+			RequestId:                           common.StringPtr(uuid.New()),
+		},
+		ParentExecutionInfo: &h.ParentExecutionInfo{
+			DomainUUID: common.StringPtr(domainID),
+			Domain:     common.StringPtr(domainID),
+			Execution: &workflow.WorkflowExecution{
+				WorkflowId: common.StringPtr("workflowID"),
+				RunId:      common.StringPtr(uuid.New()),
+			},
+			InitiatedId: common.Int64Ptr(5),
+		},
+	})
+	s.Nil(err)
+	s.NotNil(resp.RunId)
+}
+
+func (s *engine2Suite) TestStartWorkflowExecution_FirstDecisionTaskTimeout() {
+	domainID := validDomainID
+	workflowID := "workflowID"
+	workflowType := "workflowType"
+	taskList := "testTaskList"
+	identity := "testIdentity"
+
+	var scheduledEvent *workflow.HistoryEvent
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.MatchedBy(func(request *p.AppendHistoryNodesRequest) bool {
+		for _, event := range request.Events {
+			if event.GetEventType() == workflow.EventTypeDecisionTaskScheduled {
+				scheduledEvent = event
+			}
+		}
+		return true
+	})).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.MatchedBy(func(request *p.CreateWorkflowExecutionRequest) bool {
+		return request.DecisionStartToCloseTimeout == 20 && request.DecisionTimeoutValue == 2
+	})).Return(&p.CreateWorkflowExecutionResponse{}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&p.GetDomainResponse{
+			Info:   &p.DomainInfo{ID: domainID},
+			Config: &p.DomainConfig{Retention: 1},
+			ReplicationConfig: &p.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*p.ClusterReplicationConfig{
+					&p.ClusterReplicationConfig{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: p.DomainTableVersionV1,
+		},
+		nil,
+	)
+	startRequest := &workflow.StartWorkflowExecutionRequest{
+		Domain:                              common.StringPtr(domainID),
+		WorkflowId:                          common.StringPtr(workflowID),
+		WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr(workflowType)},
+		TaskList:                            &workflow.TaskList{Name: common.StringPtr(taskList)},
+		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(100),
+		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+		Identity:                            common.StringPtr(identity),
+		RequestId:                           common.StringPtr(uuid.New()),
+		FirstDecisionTaskStartToCloseTimeoutSeconds: common.Int32Ptr(200),
+	}
+
+	// the first decision timeout cannot exceed the workflow timeout
+	_, err := s.historyEngine.StartWorkflowExecution(context.Background(), &h.StartWorkflowExecutionRequest{
+		DomainUUID:   common.StringPtr(domainID),
+		StartRequest: startRequest,
+	})
+	s.IsType(&workflow.BadRequestError{}, err)
+
+	startRequest.FirstDecisionTaskStartToCloseTimeoutSeconds = common.Int32Ptr(20)
+	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &h.StartWorkflowExecutionRequest{
+		DomainUUID:   common.StringPtr(domainID),
+		StartRequest: startRequest,
+	})
+	s.Nil(err)
+	s.NotNil(resp.RunId)
+	s.NotNil(scheduledEvent)
+	s.Equal(int32(20), scheduledEvent.DecisionTaskScheduledEventAttributes.GetStartToCloseTimeoutSeconds())
+}
+
+func (s *engine2Suite) TestStartWorkflowExecution_StillRunning_Dedup() {
+	domainID := validDomainID
+	workflowID := "workflowID"
+	runID := "runID"
+	workflowType := "workflowType"
+	taskList := "testTaskList"
+	identity := "testIdentity"
+	requestID := "requestID"
+	lastWriteVersion := common.EmptyVersion
+
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything).Return(nil, &p.WorkflowExecutionAlreadyStartedError{
+		Msg:              "random message",
+		StartRequestID:   requestID,
+		RunID:            runID,
+		State:            p.WorkflowStateRunning,
+		CloseStatus:      p.WorkflowCloseStatusNone,
+		LastWriteVersion: lastWriteVersion,
+	}).Once()
+	s.mockHistoryV2Mgr.On("DeleteHistoryBranch", mock.Anything).Return(nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&p.GetDomainResponse{
+			Info:   &p.DomainInfo{ID: domainID},
+			Config: &p.DomainConfig{Retention: 1},
+			ReplicationConfig: &p.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*p.ClusterReplicationConfig{
+					&p.ClusterReplicationConfig{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: p.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &h.StartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		StartRequest: &workflow.StartWorkflowExecutionRequest{
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          common.StringPtr(workflowID),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr(workflowType)},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr(taskList)},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            common.StringPtr(identity),
+			RequestId:                           common.StringPtr(requestID),
+		},
+	})
+	s.Nil(err)
+	s.Equal(runID, resp.GetRunId())
+}
+
+func (s *engine2Suite) TestStartWorkflowExecution_StillRunning_NonDeDup() {
+	domainID := validDomainID
+	workflowID := "workflowID"
+	runID := "runID"
+	workflowType := "workflowType"
+	taskList := "testTaskList"
+	identity := "testIdentity"
+	lastWriteVersion := common.EmptyVersion
+
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything).Return(nil, &p.WorkflowExecutionAlreadyStartedError{
+		Msg:              "random message",
+		StartRequestID:   "oldRequestID",
+		RunID:            runID,
+		State:            p.WorkflowStateRunning,
+		CloseStatus:      p.WorkflowCloseStatusNone,
+		LastWriteVersion: lastWriteVersion,
+	}).Once()
+	s.mockHistoryV2Mgr.On("DeleteHistoryBranch", mock.Anything).Return(nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&p.GetDomainResponse{
+			Info:   &p.DomainInfo{ID: domainID},
+			Config: &p.DomainConfig{Retention: 1},
+			ReplicationConfig: &p.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*p.ClusterReplicationConfig{
+					&p.ClusterReplicationConfig{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: p.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &h.StartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		StartRequest: &workflow.StartWorkflowExecutionRequest{
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          common.StringPtr(workflowID),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr(workflowType)},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr(taskList)},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            common.StringPtr(identity),
+			RequestId:                           common.StringPtr("newRequestID"),
+		},
+	})
+	if _, ok := err.(*workflow.WorkflowExecutionAlreadyStartedError); !ok {
+		s.Fail("return err is not *shared.WorkflowExecutionAlreadyStartedError")
+	}
+	s.Nil(resp)
+}
+
+func (s *engine2Suite) TestStartWorkflowExecution_TimeoutError() {
+	domainID := validDomainID
+	workflowID := "workflowID"
+	workflowType := "workflowType"
+	taskList := "testTaskList"
+	identity := "testIdentity"
+
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything).Return(nil, &p.TimeoutError{
+		Msg: "random message",
+	}).Once()
+	// NOTE: should not delete history for timeout because timeout is unknown instead of failure
+	//s.mockHistoryV2Mgr.On("DeleteHistoryBranch", mock.Anything).Return(nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&p.GetDomainResponse{
+			Info:   &p.DomainInfo{ID: domainID},
+			Config: &p.DomainConfig{Retention: 1},
+			ReplicationConfig: &p.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*p.ClusterReplicationConfig{
+					&p.ClusterReplicationConfig{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: p.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &h.StartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		StartRequest: &workflow.StartWorkflowExecutionRequest{
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          common.StringPtr(workflowID),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr(workflowType)},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr(taskList)},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            common.StringPtr(identity),
+			RequestId:                           common.StringPtr("newRequestID"),
+		},
+	})
+	s.True(p.IsTimeoutError(err))
+	s.NotNil(resp)
+}
+
+func (s *engine2Suite) TestStartWorkflowExecution_NotRunning_PrevSuccess() {
+	domainID := validDomainID
+	workflowID := "workflowID"
+	runID := "runID"
+	workflowType := "workflowType"
+	taskList := "testTaskList"
 	identity := "testIdentity"
 	lastWriteVersion := common.EmptyVersion
 
@@ -1400,11 +1955,16 @@ func (s *engine2Suite) TestSignalWithStartWorkflowExecution_JustSignal() {
 	sRequest = &h.SignalWithStartWorkflowExecutionRequest{
 		DomainUUID: common.StringPtr(domainID),
 		SignalWithStartRequest: &workflow.SignalWithStartWorkflowExecutionRequest{
-			Domain:     common.StringPtr(domainID),
-			WorkflowId: common.StringPtr(workflowID),
-			Identity:   common.StringPtr(identity),
-			SignalName: common.StringPtr(signalName),
-			Input:      input,
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          common.StringPtr(workflowID),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("workflowType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr("testTaskList")},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            common.StringPtr(identity),
+			SignalName:                          common.StringPtr(signalName),
+			Input:                               input,
+			RequestId:                           common.StringPtr(uuid.New()),
 		},
 	}
 
@@ -1438,6 +1998,137 @@ func (s *engine2Suite) TestSignalWithStartWorkflowExecution_JustSignal() {
 	s.Equal(runID, resp.GetRunId())
 }
 
+func (s *engine2Suite) TestSignalWithStartWorkflowExecution_JustSignal_TTL() {
+	domainID := validDomainID
+	runID := validRunID
+	sRequest := &h.SignalWithStartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		SignalWithStartRequest: &workflow.SignalWithStartWorkflowExecutionRequest{
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          common.StringPtr("wId"),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("workflowType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr("testTaskList")},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            common.StringPtr("testIdentity"),
+			SignalName:                          common.StringPtr("my signal name"),
+			RequestId:                           common.StringPtr(uuid.New()),
+			TtlSeconds:                          common.Int32Ptr(10),
+		},
+	}
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.historyEngine.shard, s.mockEventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), runID)
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &p.GetWorkflowExecutionResponse{State: ms}
+	gceResponse := &p.GetCurrentExecutionResponse{RunID: runID}
+
+	var appendRequest *p.AppendHistoryNodesRequest
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Run(func(args mock.Arguments) {
+		appendRequest = args.Get(0).(*p.AppendHistoryNodesRequest)
+	}).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(nil, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&p.GetDomainResponse{
+			Info:   &p.DomainInfo{ID: domainID},
+			Config: &p.DomainConfig{Retention: 1},
+			ReplicationConfig: &p.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*p.ClusterReplicationConfig{
+					&p.ClusterReplicationConfig{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: p.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	before := time.Now()
+	_, err := s.historyEngine.SignalWithStartWorkflowExecution(context.Background(), sRequest)
+	s.Nil(err)
+	s.NotNil(appendRequest)
+	signaledEvent := appendRequest.Events[0]
+	s.Equal(workflow.EventTypeWorkflowExecutionSignaled, signaledEvent.GetEventType())
+	s.True(signaledEvent.WorkflowExecutionSignaledEventAttributes.GetExpirationTimestamp() >=
+		before.Add(10*time.Second).UnixNano())
+}
+
+func (s *engine2Suite) TestSignalWithStartWorkflowExecution_InvalidSignalName() {
+	domainID := validDomainID
+	sRequest := &h.SignalWithStartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		SignalWithStartRequest: &workflow.SignalWithStartWorkflowExecutionRequest{
+			Domain:     common.StringPtr(domainID),
+			WorkflowId: common.StringPtr("wId"),
+			Identity:   common.StringPtr("testIdentity"),
+			SignalName: common.StringPtr(""),
+			Input:      []byte("test input"),
+		},
+	}
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&p.GetDomainResponse{
+			Info:   &p.DomainInfo{ID: domainID},
+			Config: &p.DomainConfig{Retention: 1},
+			ReplicationConfig: &p.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*p.ClusterReplicationConfig{
+					&p.ClusterReplicationConfig{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: p.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	_, err := s.historyEngine.SignalWithStartWorkflowExecution(context.Background(), sRequest)
+	s.EqualError(err, "BadRequestError{Message: Missing SignalName.}")
+
+	sRequest.SignalWithStartRequest.SignalName = common.StringPtr(strings.Repeat("s", s.historyEngine.config.MaxIDLengthLimit()+1))
+	_, err = s.historyEngine.SignalWithStartWorkflowExecution(context.Background(), sRequest)
+	s.EqualError(err, "BadRequestError{Message: SignalName exceeds length limit.}")
+}
+
+func (s *engine2Suite) TestSignalWithStartWorkflowExecution_InvalidStartRequest() {
+	domainID := validDomainID
+	sRequest := &h.SignalWithStartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		SignalWithStartRequest: &workflow.SignalWithStartWorkflowExecutionRequest{
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          common.StringPtr("wId"),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("workflowType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr("testTaskList")},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(0),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            common.StringPtr("testIdentity"),
+			SignalName:                          common.StringPtr("my signal name"),
+			Input:                               []byte("test input"),
+			RequestId:                           common.StringPtr(uuid.New()),
+		},
+	}
+
+	// the request is rejected before the workflow is loaded, so no execution manager call is expected
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&p.GetDomainResponse{
+			Info:   &p.DomainInfo{ID: domainID},
+			Config: &p.DomainConfig{Retention: 1},
+			ReplicationConfig: &p.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*p.ClusterReplicationConfig{
+					&p.ClusterReplicationConfig{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: p.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	_, err := s.historyEngine.SignalWithStartWorkflowExecution(context.Background(), sRequest)
+	s.EqualError(err, "BadRequestError{Message: Missing or invalid ExecutionStartToCloseTimeoutSeconds.}")
+}
+
 func (s *engine2Suite) TestSignalWithStartWorkflowExecution_WorkflowNotExist() {
 	sRequest := &h.SignalWithStartWorkflowExecutionRequest{}
 	_, err := s.historyEngine.SignalWithStartWorkflowExecution(context.Background(), sRequest)
@@ -1493,6 +2184,124 @@ func (s *engine2Suite) TestSignalWithStartWorkflowExecution_WorkflowNotExist() {
 	s.NotNil(resp.GetRunId())
 }
 
+func (s *engine2Suite) TestSignalWithStartWorkflowExecution_InputsExceedSizeLimit() {
+	domainID := validDomainID
+	blobSizeLimitError := s.config.BlobSizeLimitError
+	defer func() { s.config.BlobSizeLimitError = blobSizeLimitError }()
+	s.config.BlobSizeLimitError = dynamicconfig.GetIntPropertyFilteredByDomain(100)
+
+	sRequest := &h.SignalWithStartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		SignalWithStartRequest: &workflow.SignalWithStartWorkflowExecutionRequest{
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          common.StringPtr("wId"),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("workflowType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr("testTaskList")},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            common.StringPtr("testIdentity"),
+			SignalName:                          common.StringPtr("my signal name"),
+			Input:                               make([]byte, 60),
+			SignalInput:                         make([]byte, 60),
+			RequestId:                           common.StringPtr(uuid.New()),
+		},
+	}
+
+	notExistErr := &workflow.EntityNotExistsError{Message: "Workflow not exist"}
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(nil, notExistErr)
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&p.GetDomainResponse{
+			Info:   &p.DomainInfo{ID: domainID},
+			Config: &p.DomainConfig{Retention: 1},
+			ReplicationConfig: &p.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*p.ClusterReplicationConfig{
+					&p.ClusterReplicationConfig{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: p.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	// each input is within the limit, but together they are not
+	resp, err := s.historyEngine.SignalWithStartWorkflowExecution(context.Background(), sRequest)
+	s.Nil(resp)
+	s.Equal(ErrSignalWithStartInputExceedsSizeLimit, err)
+	s.mockExecutionMgr.AssertNotCalled(s.T(), "CreateWorkflowExecution", mock.Anything)
+
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything).Return(&p.CreateWorkflowExecutionResponse{}, nil).Once()
+	sRequest.SignalWithStartRequest.SignalInput = make([]byte, 40)
+	resp, err = s.historyEngine.SignalWithStartWorkflowExecution(context.Background(), sRequest)
+	s.Nil(err)
+	s.NotNil(resp.GetRunId())
+}
+
+func (s *engine2Suite) TestSignalWithStartWorkflowExecution_DomainDraining() {
+	domainID := validDomainID
+	workflowID := "wId"
+	runID := validRunID
+	domainDraining := s.config.DomainDraining
+	defer func() { s.config.DomainDraining = domainDraining }()
+	s.config.DomainDraining = dynamicconfig.GetBoolPropertyFnFilteredByDomain(true)
+
+	sRequest := &h.SignalWithStartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		SignalWithStartRequest: &workflow.SignalWithStartWorkflowExecutionRequest{
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          common.StringPtr(workflowID),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("workflowType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr("testTaskList")},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            common.StringPtr("testIdentity"),
+			SignalName:                          common.StringPtr("my signal name"),
+			Input:                               []byte("test input"),
+			RequestId:                           common.StringPtr(uuid.New()),
+		},
+	}
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.historyEngine.shard, s.mockEventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), runID)
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &p.GetWorkflowExecutionResponse{State: ms}
+	gceResponse := &p.GetCurrentExecutionResponse{RunID: runID}
+	notExistErr := &workflow.EntityNotExistsError{Message: "Workflow not exist"}
+
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(nil, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&p.GetDomainResponse{
+			Info:   &p.DomainInfo{ID: domainID},
+			Config: &p.DomainConfig{Retention: 1},
+			ReplicationConfig: &p.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*p.ClusterReplicationConfig{
+					&p.ClusterReplicationConfig{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: p.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	// the running workflow is still signaled
+	resp, err := s.historyEngine.SignalWithStartWorkflowExecution(context.Background(), sRequest)
+	s.Nil(err)
+	s.Equal(runID, resp.GetRunId())
+
+	// but a new workflow is not started
+	sRequest.SignalWithStartRequest.WorkflowId = common.StringPtr("wId2")
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(nil, notExistErr).Once()
+	resp, err = s.historyEngine.SignalWithStartWorkflowExecution(context.Background(), sRequest)
+	s.Nil(resp)
+	s.IsType(&workflow.DomainNotActiveError{}, err)
+	s.mockExecutionMgr.AssertNotCalled(s.T(), "CreateWorkflowExecution", mock.Anything)
+}
+
 func (s *engine2Suite) TestSignalWithStartWorkflowExecution_CreateTimeout() {
 	sRequest := &h.SignalWithStartWorkflowExecutionRequest{}
 	_, err := s.historyEngine.SignalWithStartWorkflowExecution(context.Background(), sRequest)
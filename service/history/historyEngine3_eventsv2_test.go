@@ -186,6 +186,7 @@ func (s *engine3Suite) TestRecordDecisionTaskStartedSuccessStickyEnabled() {
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
 	executionInfo := msBuilder.GetExecutionInfo()
 	executionInfo.StickyTaskList = stickyTl
+	executionInfo.StickyScheduleToStartTimeout = 10
 
 	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
 	di := addDecisionTaskScheduledEvent(msBuilder)
@@ -239,8 +240,9 @@ func (s *engine3Suite) TestRecordDecisionTaskStartedSuccessStickyEnabled() {
 	expectedResponse.Attempt = common.Int64Ptr(di.Attempt)
 	expectedResponse.WorkflowExecutionTaskList = common.TaskListPtr(workflow.TaskList{
 		Name: &executionInfo.TaskList,
-		Kind: common.TaskListKindPtr(workflow.TaskListKindNormal),
+		Kind: common.TaskListKindPtr(workflow.TaskListKindSticky),
 	})
+	expectedResponse.StickyScheduleToStartTimeoutSeconds = common.Int32Ptr(10)
 	expectedResponse.EventStoreVersion = common.Int32Ptr(p.EventStoreVersionV2)
 	expectedResponse.BranchToken = msBuilder.GetCurrentBranch()
 
@@ -249,6 +251,7 @@ func (s *engine3Suite) TestRecordDecisionTaskStartedSuccessStickyEnabled() {
 	s.NotNil(response)
 	expectedResponse.StartedTimestamp = response.StartedTimestamp
 	expectedResponse.ScheduledTimestamp = common.Int64Ptr(0)
+	expectedResponse.ScheduledTimestampOfThisAttempt = common.Int64Ptr(0)
 	s.Equal(&expectedResponse, response)
 }
 
@@ -319,11 +322,16 @@ func (s *engine3Suite) TestSignalWithStartWorkflowExecution_JustSignal() {
 	sRequest = &h.SignalWithStartWorkflowExecutionRequest{
 		DomainUUID: common.StringPtr(domainID),
 		SignalWithStartRequest: &workflow.SignalWithStartWorkflowExecutionRequest{
-			Domain:     common.StringPtr(domainID),
-			WorkflowId: common.StringPtr(workflowID),
-			Identity:   common.StringPtr(identity),
-			SignalName: common.StringPtr(signalName),
-			Input:      input,
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          common.StringPtr(workflowID),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("workflowType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr("testTaskList")},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            common.StringPtr(identity),
+			SignalName:                          common.StringPtr(signalName),
+			Input:                               input,
+			RequestId:                           common.StringPtr(uuid.New()),
 		},
 	}
 
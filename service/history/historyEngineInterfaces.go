@@ -41,6 +41,113 @@ type (
 		timestamp              time.Time
 	}
 
+	// QueueProcessorStatus is a point in time view of the progress of a single queue processor.
+	// For the transfer and replicator processors the levels are task IDs, and the backlog is the
+	// number of task IDs allocated by the shard beyond the ack level. For the timer processor
+	// the levels are visibility timestamps in unix nanoseconds, and the backlog is the lag in
+	// nanoseconds between the ack level and the max read level.
+	QueueProcessorStatus struct {
+		AckLevel     int64
+		ReadLevel    int64
+		MaxReadLevel int64
+		Backlog      int64
+		// PendingTasks is the number of tasks read by the processor which are not yet completed
+		PendingTasks int64
+		// OldestPendingTaskTimestamp is the visibility timestamp in unix nanoseconds of the oldest pending
+		// timer task, zero if there is none or for the transfer and replicator processors
+		OldestPendingTaskTimestamp int64
+		// Locked is true while the task processing is locked by a domain failover
+		Locked bool
+	}
+
+	// ShardQueueProcessorStatus is the status of all the queue processors of a shard
+	ShardQueueProcessorStatus struct {
+		ShardID            int
+		CurrentClusterName string
+		TransferProcessor  *QueueProcessorStatus
+		TimerProcessor     *QueueProcessorStatus
+		// ReplicatorProcessor is nil if replication is not enabled for the shard
+		ReplicatorProcessor *QueueProcessorStatus
+	}
+
+	// MutableStateSnapshot is the serialized mutable state of a workflow execution exported for offline analysis
+	MutableStateSnapshot struct {
+		// MutableState is the JSON encoded persistence form of the mutable state, gzip compressed if Compressed is set
+		MutableState []byte
+		Compressed   bool
+		BranchToken  []byte
+	}
+
+	// ShardArchivalBacklog is the number of closed workflows per domain of a shard whose history cleanup is due but
+	// which are not archived yet
+	ShardArchivalBacklog struct {
+		ShardID int
+		// DomainBacklog maps domain name to the number of workflows pending archival
+		DomainBacklog map[string]int64
+		// Truncated is true if the shard has more due timer tasks than were scanned, the counts are then lower bounds
+		Truncated bool
+	}
+
+	// HostArchivalBacklog is the number of closed workflows per domain pending archival on the shards of a host
+	HostArchivalBacklog struct {
+		// DomainBacklog maps domain name to the number of workflows pending archival
+		DomainBacklog map[string]int64
+		// TruncatedShardIDs are the shards which reported a truncated backlog, the counts are then lower bounds
+		TruncatedShardIDs []int
+	}
+
+	// WorkflowExecutionResult is the outcome of a closed workflow execution as recorded on its completion event
+	WorkflowExecutionResult struct {
+		CloseStatus workflow.WorkflowExecutionCloseStatus
+		CloseTime   int64
+		// Result is only set for completed workflows
+		Result []byte
+		// Reason is set for failed and terminated workflows, Details for failed, terminated and canceled workflows
+		Reason  *string
+		Details []byte
+	}
+
+	// DecisionReplayResult compares the events recorded for the decisions of a completed decision task with the
+	// events the same decisions produce when replayed against the mutable state rebuilt from the preceding history
+	DecisionReplayResult struct {
+		// RecordedEvents are the events the decisions produced when the decision task was completed
+		RecordedEvents []*workflow.HistoryEvent
+		// ReplayedEvents are the events produced by replaying the decisions
+		ReplayedEvents []*workflow.HistoryEvent
+		// FailCause is set if the replayed decisions fail the decision task
+		FailCause *workflow.DecisionTaskFailedCause
+	}
+
+	// StaleDecisionWorkflow is an open workflow execution whose pending decision has been scheduled or started for
+	// longer than the requested threshold
+	StaleDecisionWorkflow struct {
+		Execution          workflow.WorkflowExecution
+		DecisionScheduleID int64
+		ScheduledTimestamp int64
+		// StartedTimestamp is 0 if the decision is not started yet
+		StartedTimestamp int64
+	}
+
+	// WorkflowReplicationStatus is the replication state of a workflow execution, comparing the last events received
+	// from each cluster with the local next event ID shows how far behind the replication of the workflow is
+	WorkflowReplicationStatus struct {
+		// Replicated is false for a workflow of a local domain, which has no replication state to report
+		Replicated       bool
+		NextEventID      int64
+		CurrentVersion   int64
+		LastWriteVersion int64
+		LastWriteEventID int64
+		// LastReplicationInfo is the version and ID of the last event replicated from each cluster, by cluster name
+		LastReplicationInfo map[string]*persistence.ReplicationInfo
+	}
+
+	// StaleDecisionWorkflows is one page of workflow executions with a stale pending decision
+	StaleDecisionWorkflows struct {
+		Workflows []*StaleDecisionWorkflow
+		// NextPageToken is empty once all the open executions of the domain are scanned
+		NextPageToken []byte
+	}
+
 	// Engine represents an interface for managing workflow execution history.
 	Engine interface {
 		common.Daemon
@@ -49,16 +156,29 @@ type (
 			error)
 		GetMutableState(ctx context.Context, request *h.GetMutableStateRequest) (*h.GetMutableStateResponse, error)
 		DescribeMutableState(ctx context.Context, request *h.DescribeMutableStateRequest) (*h.DescribeMutableStateResponse, error)
+		ExportMutableState(ctx context.Context, domainUUID string, execution workflow.WorkflowExecution, compress bool) (
+			*MutableStateSnapshot, error)
+		GetCurrentBranch(ctx context.Context, domainUUID string, execution workflow.WorkflowExecution) ([]byte, int32, int64, error)
+		GetCurrentRunID(ctx context.Context, domainUUID string, workflowID string) (string, bool, error)
+		GetRawHistory(ctx context.Context, domainUUID string, execution workflow.WorkflowExecution, firstEventID int64,
+			nextEventID int64, pageSize int, nextPageToken []byte) ([]*workflow.HistoryEvent, []byte, error)
 		ResetStickyTaskList(ctx context.Context, resetRequest *h.ResetStickyTaskListRequest) (*h.ResetStickyTaskListResponse, error)
 		DescribeWorkflowExecution(ctx context.Context,
 			request *h.DescribeWorkflowExecutionRequest) (*workflow.DescribeWorkflowExecutionResponse, error)
+		GetWorkflowExecutionResult(ctx context.Context, domainUUID string, execution workflow.WorkflowExecution) (
+			*WorkflowExecutionResult, error)
 		RecordDecisionTaskStarted(ctx context.Context, request *h.RecordDecisionTaskStartedRequest) (*h.RecordDecisionTaskStartedResponse, error)
 		RecordActivityTaskStarted(ctx context.Context, request *h.RecordActivityTaskStartedRequest) (*h.RecordActivityTaskStartedResponse, error)
 		RespondDecisionTaskCompleted(ctx context.Context, request *h.RespondDecisionTaskCompletedRequest) (*h.RespondDecisionTaskCompletedResponse, error)
 		RespondDecisionTaskFailed(ctx context.Context, request *h.RespondDecisionTaskFailedRequest) error
+		ReplayDecision(ctx context.Context, domainUUID string, execution workflow.WorkflowExecution, decisionScheduleID int64) (
+			*DecisionReplayResult, error)
 		RespondActivityTaskCompleted(ctx context.Context, request *h.RespondActivityTaskCompletedRequest) error
 		RespondActivityTaskFailed(ctx context.Context, request *h.RespondActivityTaskFailedRequest) error
 		RespondActivityTaskCanceled(ctx context.Context, request *h.RespondActivityTaskCanceledRequest) error
+		ForceCompleteActivity(ctx context.Context, domainUUID string, execution workflow.WorkflowExecution, scheduleID int64,
+			result []byte) error
+		ExtendWorkflowTimeout(ctx context.Context, domainUUID string, execution workflow.WorkflowExecution, additionalSeconds int32) error
 		RecordActivityTaskHeartbeat(ctx context.Context, request *h.RecordActivityTaskHeartbeatRequest) (*workflow.RecordActivityTaskHeartbeatResponse, error)
 		RequestCancelWorkflowExecution(ctx context.Context, request *h.RequestCancelWorkflowExecutionRequest) error
 		SignalWorkflowExecution(ctx context.Context, request *h.SignalWorkflowExecutionRequest) error
@@ -66,6 +186,16 @@ type (
 			*workflow.StartWorkflowExecutionResponse, error)
 		RemoveSignalMutableState(ctx context.Context, request *h.RemoveSignalMutableStateRequest) error
 		TerminateWorkflowExecution(ctx context.Context, request *h.TerminateWorkflowExecutionRequest) error
+		DeleteWorkflowExecution(ctx context.Context, domainUUID string, execution workflow.WorkflowExecution) error
+		ReemitOpenVisibility(ctx context.Context, domainUUID string, execution workflow.WorkflowExecution) error
+		ListWorkflowsWithStaleDecision(ctx context.Context, domainUUID string, threshold time.Duration, pageSize int,
+			nextPageToken []byte) (*StaleDecisionWorkflows, error)
+		GetReplicationStatus(ctx context.Context, domainUUID string, execution workflow.WorkflowExecution) (*WorkflowReplicationStatus,
+			error)
+		MarkBinaryBad(ctx context.Context, domainUUID string, checksum string, reason string, operator string,
+			resetWorkflows bool) (int, error)
+		CleanupOrphanedHistoryBranch(ctx context.Context, domainUUID string, execution workflow.WorkflowExecution,
+			branchToken []byte) error
 		ResetWorkflowExecution(ctx context.Context, request *h.ResetWorkflowExecutionRequest) (*workflow.ResetWorkflowExecutionResponse, error)
 		ScheduleDecisionTask(ctx context.Context, request *h.ScheduleDecisionTaskRequest) error
 		RecordChildExecutionCompleted(ctx context.Context, request *h.RecordChildExecutionCompletedRequest) error
@@ -73,6 +203,8 @@ type (
 		ReplicateRawEvents(ctx context.Context, request *h.ReplicateRawEventsRequest) error
 		SyncShardStatus(ctx context.Context, request *h.SyncShardStatusRequest) error
 		SyncActivity(ctx context.Context, request *h.SyncActivityRequest) error
+		DescribeQueueProcessorStatus() *ShardQueueProcessorStatus
+		DescribeArchivalBacklog() (*ShardArchivalBacklog, error)
 	}
 
 	// EngineFactory is used to create an instance of sharded history engine
@@ -83,6 +215,7 @@ type (
 	queueProcessor interface {
 		common.Daemon
 		notifyNewTask()
+		describeStatus() *QueueProcessorStatus
 	}
 
 	queueAckMgr interface {
@@ -91,6 +224,7 @@ type (
 		completeQueueTask(taskID int64)
 		getQueueAckLevel() int64
 		getQueueReadLevel() int64
+		getPendingTaskCount() int64
 		updateQueueAckLevel()
 	}
 
@@ -115,6 +249,7 @@ type (
 		NotifyNewTask(clusterName string, transferTasks []persistence.Task)
 		LockTaskPrrocessing()
 		UnlockTaskPrrocessing()
+		describeStatus() *QueueProcessorStatus
 	}
 
 	// TODO the timer queue processor and the one below, timer processor
@@ -126,6 +261,7 @@ type (
 		NotifyNewTimers(clusterName string, currentTime time.Time, timerTask []persistence.Task)
 		LockTaskPrrocessing()
 		UnlockTaskPrrocessing()
+		describeStatus() *QueueProcessorStatus
 	}
 
 	timerProcessor interface {
@@ -140,6 +276,8 @@ type (
 		completeTimerTask(timerTask *persistence.TimerTaskInfo)
 		getAckLevel() TimerSequenceID
 		getReadLevel() TimerSequenceID
+		getOldestPendingTask() (TimerSequenceID, bool)
+		getPendingTaskCount() int64
 		updateAckLevel()
 	}
 
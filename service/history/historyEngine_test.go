@@ -21,9 +21,16 @@
 package history
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -37,7 +44,10 @@ import (
 	"github.com/uber/cadence/client"
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/cluster"
+	"github.com/uber/cadence/common/codec"
+	"github.com/uber/cadence/common/definition"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/loggerimpl"
 	"github.com/uber/cadence/common/messaging"
@@ -46,6 +56,7 @@ import (
 	"github.com/uber/cadence/common/persistence"
 	p "github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/service"
+	"github.com/uber/cadence/common/service/dynamicconfig"
 	"github.com/uber/cadence/service/worker/archiver"
 )
 
@@ -222,6 +233,46 @@ func (s *engineSuite) TestGetMutableStateSync() {
 	s.Equal(int64(4), response.GetNextEventId())
 }
 
+func (s *engineSuite) TestGetMutableState_IncludeBranchAncestry() {
+	ctx := context.Background()
+	domainID := validDomainID
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-get-workflow-execution-branch-ancestry"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tasklist := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), execution.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", tasklist, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tasklist, identity)
+	ms := createMutableState(msBuilder)
+	gweResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gweResponse, nil).Once()
+
+	// the branch ancestry is not returned unless asked for
+	response, err := s.mockHistoryEngine.GetMutableState(ctx, &history.GetMutableStateRequest{
+		DomainUUID: common.StringPtr(domainID),
+		Execution:  &execution,
+	})
+	s.Nil(err)
+	s.Nil(response.BranchAncestry)
+
+	response, err = s.mockHistoryEngine.GetMutableState(ctx, &history.GetMutableStateRequest{
+		DomainUUID:            common.StringPtr(domainID),
+		Execution:             &execution,
+		IncludeBranchAncestry: common.BoolPtr(true),
+	})
+	s.Nil(err)
+	s.Equal(msBuilder.GetCurrentBranch(), response.BranchToken)
+	s.NotNil(response.BranchAncestry)
+	s.Equal(execution.GetRunId(), response.BranchAncestry.GetTreeID())
+	s.NotEmpty(response.BranchAncestry.GetBranchID())
+	s.Empty(response.BranchAncestry.Ancestors)
+}
+
 func (s *engineSuite) TestGetMutableState_InvalidRunID() {
 	ctx := context.Background()
 	domainID := validDomainID
@@ -253,11 +304,11 @@ func (s *engineSuite) TestGetMutableState_EmptyRunID() {
 	s.Equal(&workflow.EntityNotExistsError{}, err)
 }
 
-func (s *engineSuite) TestGetMutableStateLongPoll() {
+func (s *engineSuite) TestGetCurrentBranch() {
 	ctx := context.Background()
 	domainID := validDomainID
 	execution := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("test-get-workflow-execution-event-id"),
+		WorkflowId: common.StringPtr("test-get-current-branch"),
 		RunId:      common.StringPtr(validRunID),
 	}
 	tasklist := "testTaskList"
@@ -268,10 +319,159 @@ func (s *engineSuite) TestGetMutableStateLongPoll() {
 	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", tasklist, []byte("input"), 100, 200, identity)
 	di := addDecisionTaskScheduledEvent(msBuilder)
 	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tasklist, identity)
+	_ = msBuilder.SetHistoryTree(execution.GetRunId())
 	ms := createMutableState(msBuilder)
 	gweResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-	// right now the next event ID is 4
 	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gweResponse, nil).Once()
+
+	branchToken, eventStoreVersion, nextEventID, err := s.mockHistoryEngine.GetCurrentBranch(ctx, domainID, execution)
+	s.Nil(err)
+	s.Equal(msBuilder.GetCurrentBranch(), branchToken)
+	s.NotEmpty(branchToken)
+	s.Equal(int32(persistence.EventStoreVersionV2), eventStoreVersion)
+	s.Equal(int64(4), nextEventID)
+
+	// the second call is served from the cache
+	branchToken, _, nextEventID, err = s.mockHistoryEngine.GetCurrentBranch(ctx, domainID, execution)
+	s.Nil(err)
+	s.Equal(msBuilder.GetCurrentBranch(), branchToken)
+	s.Equal(int64(4), nextEventID)
+}
+
+func (s *engineSuite) TestGetCurrentBranch_InvalidDomainUUID() {
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-get-current-branch"),
+		RunId:      common.StringPtr(validRunID),
+	}
+
+	_, _, _, err := s.mockHistoryEngine.GetCurrentBranch(context.Background(), "domain-id-not-valid-uuid", execution)
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *engineSuite) TestGetCurrentRunID() {
+	domainID := validDomainID
+	workflowID := "wId"
+
+	s.mockExecutionMgr.On("GetCurrentExecution", &persistence.GetCurrentExecutionRequest{
+		DomainID:   domainID,
+		WorkflowID: workflowID,
+	}).Return(&persistence.GetCurrentExecutionResponse{
+		RunID: validRunID,
+		State: persistence.WorkflowStateRunning,
+	}, nil).Once()
+	runID, isRunning, err := s.mockHistoryEngine.GetCurrentRunID(context.Background(), domainID, workflowID)
+	s.Nil(err)
+	s.Equal(validRunID, runID)
+	s.True(isRunning)
+
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(&persistence.GetCurrentExecutionResponse{
+		RunID:       validRunID,
+		State:       persistence.WorkflowStateCompleted,
+		CloseStatus: persistence.WorkflowCloseStatusCompleted,
+	}, nil).Once()
+	runID, isRunning, err = s.mockHistoryEngine.GetCurrentRunID(context.Background(), domainID, workflowID)
+	s.Nil(err)
+	s.Equal(validRunID, runID)
+	s.False(isRunning)
+
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(nil, &workflow.EntityNotExistsError{}).Once()
+	_, _, err = s.mockHistoryEngine.GetCurrentRunID(context.Background(), domainID, workflowID)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
+
+	_, _, err = s.mockHistoryEngine.GetCurrentRunID(context.Background(), domainID, "")
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *engineSuite) TestGetRawHistory_EventStoreV2() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	startedEvent := addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+
+	s.mockHistoryV2Mgr.On("ReadHistoryBranch", &persistence.ReadHistoryBranchRequest{
+		BranchToken:   ms.ExecutionInfo.BranchToken,
+		MinEventID:    common.FirstEventID,
+		MaxEventID:    int64(3),
+		PageSize:      defaultHistoryPageSize,
+		NextPageToken: nil,
+		ShardID:       common.IntPtr(s.mockHistoryEngine.shard.GetShardID()),
+	}).Return(&persistence.ReadHistoryBranchResponse{
+		HistoryEvents: []*workflow.HistoryEvent{startedEvent},
+		NextPageToken: []byte("next page"),
+	}, nil).Once()
+
+	events, token, err := s.mockHistoryEngine.GetRawHistory(context.Background(), domainID, we, 0, 0, defaultHistoryPageSize+1, nil)
+	s.Nil(err)
+	s.Equal([]*workflow.HistoryEvent{startedEvent}, events)
+	s.Equal([]byte("next page"), token)
+}
+
+func (s *engineSuite) TestGetRawHistory_EventStoreV1() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	ms := createMutableState(msBuilder)
+	ms.ExecutionInfo.EventStoreVersion = 0
+	ms.ExecutionInfo.BranchToken = nil
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+
+	s.mockHistoryMgr.On("GetWorkflowExecutionHistory", &persistence.GetWorkflowExecutionHistoryRequest{
+		DomainID:      domainID,
+		Execution:     we,
+		FirstEventID:  int64(3),
+		NextEventID:   int64(4),
+		PageSize:      10,
+		NextPageToken: []byte("page"),
+	}).Return(&persistence.GetWorkflowExecutionHistoryResponse{
+		History: &workflow.History{Events: []*workflow.HistoryEvent{decisionStartedEvent}},
+	}, nil).Once()
+
+	events, token, err := s.mockHistoryEngine.GetRawHistory(context.Background(), domainID, we, 3, 100, 10, []byte("page"))
+	s.Nil(err)
+	s.Equal([]*workflow.HistoryEvent{decisionStartedEvent}, events)
+	s.Nil(token)
+}
+
+func (s *engineSuite) TestDescribeMutableState_CacheMetrics() {
+	scope := tally.NewTestScope("test", nil)
+	s.mockHistoryEngine.metricsClient = metrics.NewClient(scope, metrics.History)
+	domainID := validDomainID
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-describe-mutable-state"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tasklist := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), execution.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", tasklist, []byte("input"), 100, 200, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
+	ms := createMutableState(msBuilder)
+	gweResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gweResponse, nil).Times(3)
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
 			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
@@ -287,71 +487,33 @@ func (s *engineSuite) TestGetMutableStateLongPoll() {
 		nil,
 	).Once()
 
-	// test long poll on next event ID change
-	asycWorkflowUpdate := func(delay time.Duration) {
-		taskToken, _ := json.Marshal(&common.TaskToken{
-			WorkflowID: *execution.WorkflowId,
-			RunID:      *execution.RunId,
-			ScheduleID: 2,
-		})
-		s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-		s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
-
-		timer := time.NewTimer(delay)
-
-		<-timer.C
-		s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
-			&persistence.GetDomainResponse{
-				Info:   &persistence.DomainInfo{ID: domainID},
-				Config: &persistence.DomainConfig{Retention: 1},
-				ReplicationConfig: &persistence.DomainReplicationConfig{
-					ActiveClusterName: cluster.TestCurrentClusterName,
-					Clusters: []*persistence.ClusterReplicationConfig{
-						{ClusterName: cluster.TestCurrentClusterName},
-					},
-				},
-				TableVersion: persistence.DomainTableVersionV1,
-			},
-			nil,
-		)
-		_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
-			DomainUUID: common.StringPtr(domainID),
-			CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-				TaskToken: taskToken,
-				Identity:  &identity,
-			},
-		})
-		s.Nil(err)
-		// right now the next event ID is 5
+	request := &history.DescribeMutableStateRequest{
+		DomainUUID: common.StringPtr(domainID),
+		Execution:  &execution,
 	}
-
-	// return immediately, since the expected next event ID appears
-	response, err := s.mockHistoryEngine.GetMutableState(ctx, &history.GetMutableStateRequest{
-		DomainUUID:          common.StringPtr(domainID),
-		Execution:           &execution,
-		ExpectedNextEventId: common.Int64Ptr(3),
-	})
+	resp, err := s.mockHistoryEngine.DescribeMutableState(context.Background(), request)
 	s.Nil(err)
-	s.Equal(int64(4), *response.NextEventId)
+	s.Nil(resp.MutableStateInCache)
+	s.NotNil(resp.MutableStateInDatabase)
 
-	// long poll, new event happen before long poll timeout
-	go asycWorkflowUpdate(time.Second * 2)
-	start := time.Now()
-	response, err = s.mockHistoryEngine.GetMutableState(ctx, &history.GetMutableStateRequest{
-		DomainUUID:          common.StringPtr(domainID),
-		Execution:           &execution,
-		ExpectedNextEventId: common.Int64Ptr(4),
-	})
-	s.True(time.Now().After(start.Add(time.Second * 1)))
+	// load the mutable state into the cache so the next describe is a cache hit
+	_, _, _, err = s.mockHistoryEngine.GetCurrentBranch(context.Background(), domainID, execution)
 	s.Nil(err)
-	s.Equal(int64(5), *response.NextEventId)
+	resp, err = s.mockHistoryEngine.DescribeMutableState(context.Background(), request)
+	s.Nil(err)
+	s.NotNil(resp.MutableStateInCache)
+	s.NotNil(resp.MutableStateInDatabase)
+
+	tags := "+domain=testDomain,operation=DescribeMutableState,shard=" + strconv.Itoa(s.mockHistoryEngine.shard.GetShardID())
+	counters := scope.Snapshot().Counters()
+	s.Equal(int64(1), counters["test.cache_miss"+tags].Value())
+	s.Equal(int64(1), counters["test.cache_hit"+tags].Value())
 }
 
-func (s *engineSuite) TestGetMutableStateLongPollTimeout() {
-	ctx := context.Background()
+func (s *engineSuite) TestReemitOpenVisibility_Running() {
 	domainID := validDomainID
 	execution := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("test-get-workflow-execution-event-id"),
+		WorkflowId: common.StringPtr("test-reemit-open-visibility"),
 		RunId:      common.StringPtr(validRunID),
 	}
 	tasklist := "testTaskList"
@@ -360,11 +522,11 @@ func (s *engineSuite) TestGetMutableStateLongPollTimeout() {
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), execution.GetRunId())
 	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", tasklist, []byte("input"), 100, 200, identity)
-	di := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tasklist, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
 	ms := createMutableState(msBuilder)
 	gweResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-	// right now the next event ID is 4
+
+	s.mockHistoryEngine.visibilityMgr = s.mockVisibilityMgr
 	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gweResponse, nil).Once()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -380,25 +542,44 @@ func (s *engineSuite) TestGetMutableStateLongPollTimeout() {
 		},
 		nil,
 	).Once()
+	s.mockVisibilityMgr.On("RecordWorkflowExecutionStarted", mock.MatchedBy(func(request *persistence.RecordWorkflowExecutionStartedRequest) bool {
+		return request.DomainUUID == domainID && request.Domain == "testDomain" &&
+			request.Execution.GetWorkflowId() == execution.GetWorkflowId() && request.Execution.GetRunId() == execution.GetRunId() &&
+			request.WorkflowTypeName == "wType" && request.WorkflowTimeout == 100
+	})).Return(nil).Twice()
 
-	// long poll, no event happen after long poll timeout
-	response, err := s.mockHistoryEngine.GetMutableState(ctx, &history.GetMutableStateRequest{
-		DomainUUID:          common.StringPtr(domainID),
-		Execution:           &execution,
-		ExpectedNextEventId: common.Int64Ptr(4),
-	})
+	err := s.mockHistoryEngine.ReemitOpenVisibility(context.Background(), domainID, execution)
+	s.Nil(err)
+
+	// emitting again only upserts the same record
+	err = s.mockHistoryEngine.ReemitOpenVisibility(context.Background(), domainID, execution)
 	s.Nil(err)
-	s.Equal(int64(4), *response.NextEventId)
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedInvalidToken() {
+func (s *engineSuite) TestReemitOpenVisibility_Completed() {
 	domainID := validDomainID
-	invalidToken, _ := json.Marshal("bad token")
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-reemit-open-visibility"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tasklist := "testTaskList"
 	identity := "testIdentity"
 
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), execution.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", tasklist, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tasklist, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID, decisionStartedEvent.GetEventId(), nil, identity)
+	addCompleteWorkflowEvent(msBuilder, decisionCompletedEvent.GetEventId(), nil)
+	ms := createMutableState(msBuilder)
+	gweResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockHistoryEngine.visibilityMgr = s.mockVisibilityMgr
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gweResponse, nil).Once()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
 			Config: &persistence.DomainConfig{Retention: 1},
 			ReplicationConfig: &persistence.DomainReplicationConfig{
 				ActiveClusterName: cluster.TestCurrentClusterName,
@@ -409,33 +590,47 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedInvalidToken() {
 			TableVersion: persistence.DomainTableVersionV1,
 		},
 		nil,
-	)
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        invalidToken,
-			Decisions:        nil,
-			ExecutionContext: nil,
-			Identity:         &identity,
-		},
-	})
+	).Once()
 
-	s.NotNil(err)
-	s.IsType(&workflow.BadRequestError{}, err)
+	err := s.mockHistoryEngine.ReemitOpenVisibility(context.Background(), domainID, execution)
+	s.Equal(ErrWorkflowCompleted, err)
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedIfNoExecution() {
+func (s *engineSuite) TestListWorkflowsWithStaleDecision() {
 	domainID := validDomainID
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		RunID:      validRunID,
-		ScheduleID: 2,
-	})
-	identity := "testIdentity"
+	s.mockHistoryEngine.config.NumberOfShards = 16
+	shardID := s.mockHistoryEngine.shard.GetShardID()
+	newExecution := func(prefix string, onShard bool) workflow.WorkflowExecution {
+		for i := 0; ; i++ {
+			workflowID := fmt.Sprintf("%v-%v", prefix, i)
+			if (s.mockHistoryEngine.config.GetShardID(workflowID) == shardID) == onShard {
+				return workflow.WorkflowExecution{WorkflowId: common.StringPtr(workflowID), RunId: common.StringPtr(uuid.New())}
+			}
+		}
+	}
+	staleExecution := newExecution("stale-decision", true)
+	freshExecution := newExecution("fresh-decision", true)
+	// the execution of another shard is not loaded
+	otherShardExecution := newExecution("other-shard", false)
+
+	mockGetWorkflowExecution := func(execution workflow.WorkflowExecution, scheduledTimestamp time.Time) {
+		msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+			loggerimpl.NewDevelopmentForTest(s.Suite), execution.GetRunId())
+		addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", "testTaskList", []byte("input"), 100, 200, "testIdentity")
+		addDecisionTaskScheduledEvent(msBuilder)
+		ms := createMutableState(msBuilder)
+		ms.ExecutionInfo.DecisionScheduledTimestamp = scheduledTimestamp.UnixNano()
+		s.mockExecutionMgr.On("GetWorkflowExecution", mock.MatchedBy(func(request *persistence.GetWorkflowExecutionRequest) bool {
+			return request.Execution.GetWorkflowId() == execution.GetWorkflowId()
+		})).Return(&persistence.GetWorkflowExecutionResponse{State: ms}, nil).Once()
+	}
+	mockGetWorkflowExecution(staleExecution, time.Now().Add(-2*time.Hour))
+	mockGetWorkflowExecution(freshExecution, time.Now())
 
+	s.mockHistoryEngine.visibilityMgr = s.mockVisibilityMgr
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
 			Config: &persistence.DomainConfig{Retention: 1},
 			ReplicationConfig: &persistence.DomainReplicationConfig{
 				ActiveClusterName: cluster.TestCurrentClusterName,
@@ -447,81 +642,87 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedIfNoExecution() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil, &workflow.EntityNotExistsError{}).Once()
+	s.mockVisibilityMgr.On("ListOpenWorkflowExecutions", mock.MatchedBy(func(request *persistence.ListWorkflowExecutionsRequest) bool {
+		return request.DomainUUID == domainID && request.PageSize == 10 && string(request.NextPageToken) == "token"
+	})).Return(&persistence.ListWorkflowExecutionsResponse{
+		Executions: []*workflow.WorkflowExecutionInfo{
+			{Execution: &staleExecution},
+			{Execution: &freshExecution},
+			{Execution: &otherShardExecution},
+		},
+		NextPageToken: []byte("next token"),
+	}, nil).Once()
+
+	result, err := s.mockHistoryEngine.ListWorkflowsWithStaleDecision(context.Background(), domainID, time.Hour, 10, []byte("token"))
+	s.Nil(err)
+	s.Equal([]byte("next token"), result.NextPageToken)
+	s.Equal(1, len(result.Workflows))
+	s.Equal(staleExecution, result.Workflows[0].Execution)
+	s.Equal(int64(2), result.Workflows[0].DecisionScheduleID)
+	s.Equal(int64(0), result.Workflows[0].StartedTimestamp)
 
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken: taskToken,
-			Identity:  &identity,
-		},
-	})
-	s.NotNil(err)
-	s.IsType(&workflow.EntityNotExistsError{}, err)
+	_, err = s.mockHistoryEngine.ListWorkflowsWithStaleDecision(context.Background(), domainID, 0, 10, nil)
+	s.IsType(&workflow.BadRequestError{}, err)
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedIfGetExecutionFailed() {
+func (s *engineSuite) TestMarkBinaryBad() {
 	domainID := validDomainID
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		RunID:      validRunID,
-		ScheduleID: 2,
-	})
-	identity := "testIdentity"
-
+	config := &persistence.DomainConfig{Retention: 1}
+	s.mockHistoryEngine.metadataMgr = s.mockMetadataMgr
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
-			Config: &persistence.DomainConfig{Retention: 1},
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
+			Config: config,
 			ReplicationConfig: &persistence.DomainReplicationConfig{
 				ActiveClusterName: cluster.TestCurrentClusterName,
 				Clusters: []*persistence.ClusterReplicationConfig{
 					{ClusterName: cluster.TestCurrentClusterName},
 				},
 			},
-			TableVersion: persistence.DomainTableVersionV1,
+			ConfigVersion:       3,
+			NotificationVersion: 5,
+			TableVersion:        persistence.DomainTableVersionV1,
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil, errors.New("FAILED")).Once()
+	s.mockMetadataMgr.On("UpdateDomain", mock.MatchedBy(func(request *persistence.UpdateDomainRequest) bool {
+		badBinary, ok := request.Config.BadBinaries.Binaries["bad-checksum"]
+		return ok && badBinary.GetReason() == "crash loop" && badBinary.GetOperator() == "operator" &&
+			request.ConfigVersion == 4 && request.NotificationVersion == 5
+	})).Return(nil).Once()
+
+	resetCount, err := s.mockHistoryEngine.MarkBinaryBad(context.Background(), domainID, "bad-checksum", "crash loop",
+		"operator", false)
+	s.Nil(err)
+	s.Equal(0, resetCount)
 
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken: taskToken,
-			Identity:  &identity,
-		},
-	})
-	s.EqualError(err, "FAILED")
+	// marking the binary again leaves the domain as is
+	_, err = s.mockHistoryEngine.MarkBinaryBad(context.Background(), domainID, "bad-checksum", "crash loop", "operator", false)
+	s.Nil(err)
+	s.mockMetadataMgr.AssertNumberOfCalls(s.T(), "UpdateDomain", 1)
+
+	_, err = s.mockHistoryEngine.MarkBinaryBad(context.Background(), domainID, "", "crash loop", "operator", false)
+	s.IsType(&workflow.BadRequestError{}, err)
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedUpdateExecutionFailed() {
+func (s *engineSuite) TestMarkBinaryBad_ResetWorkflowsOfShard() {
 	domainID := validDomainID
-	we := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("wId"),
-		RunId:      common.StringPtr(validRunID),
+	s.mockHistoryEngine.config.NumberOfShards = 16
+	shardID := s.mockHistoryEngine.shard.GetShardID()
+	var otherShardExecution workflow.WorkflowExecution
+	for i := 0; ; i++ {
+		workflowID := fmt.Sprintf("other-shard-%v", i)
+		if s.mockHistoryEngine.config.GetShardID(workflowID) != shardID {
+			otherShardExecution = workflow.WorkflowExecution{WorkflowId: common.StringPtr(workflowID), RunId: common.StringPtr(uuid.New())}
+			break
+		}
 	}
-	tl := "testTaskList"
-
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 2,
-	})
-	identity := "testIdentity"
-
-	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
-	di := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-
-	ms := createMutableState(msBuilder)
-	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
+	s.mockHistoryEngine.metadataMgr = s.mockMetadataMgr
+	s.mockHistoryEngine.visibilityMgr = s.mockVisibilityMgr
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
 			Config: &persistence.DomainConfig{Retention: 1},
 			ReplicationConfig: &persistence.DomainReplicationConfig{
 				ActiveClusterName: cluster.TestCurrentClusterName,
@@ -533,49 +734,27 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedUpdateExecutionFailed() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, errors.New("FAILED")).Once()
-	s.mockShardManager.On("UpdateShard", mock.Anything).Return(nil).Once()
-
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken: taskToken,
-			Identity:  &identity,
-		},
-	})
-	s.NotNil(err)
-	s.EqualError(err, "FAILED")
+	s.mockMetadataMgr.On("UpdateDomain", mock.Anything).Return(nil).Once()
+	s.mockVisibilityMgr.On("ListOpenWorkflowExecutions", mock.MatchedBy(func(request *persistence.ListWorkflowExecutionsRequest) bool {
+		return request.DomainUUID == domainID
+	})).Return(&persistence.ListWorkflowExecutionsResponse{
+		Executions: []*workflow.WorkflowExecutionInfo{{Execution: &otherShardExecution}},
+	}, nil).Once()
+
+	// the execution of another shard is neither loaded nor reset
+	resetCount, err := s.mockHistoryEngine.MarkBinaryBad(context.Background(), domainID, "bad-checksum", "crash loop",
+		"operator", true)
+	s.Nil(err)
+	s.Equal(0, resetCount)
+	s.mockExecutionMgr.AssertNotCalled(s.T(), "GetWorkflowExecution", mock.Anything)
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedIfTaskCompleted() {
+func (s *engineSuite) TestMarkBinaryBad_GlobalDomain() {
 	domainID := validDomainID
-	we := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("wId"),
-		RunId:      common.StringPtr(validRunID),
-	}
-	tl := "testTaskList"
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 2,
-	})
-	identity := "testIdentity"
-
-	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
-	di := addDecisionTaskScheduledEvent(msBuilder)
-	startedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-	addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID, *startedEvent.EventId, nil, identity)
-
-	ms := createMutableState(msBuilder)
-	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-
+	s.mockHistoryEngine.metadataMgr = s.mockMetadataMgr
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
 			Config: &persistence.DomainConfig{Retention: 1},
 			ReplicationConfig: &persistence.DomainReplicationConfig{
 				ActiveClusterName: cluster.TestCurrentClusterName,
@@ -583,254 +762,3984 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedIfTaskCompleted() {
 					{ClusterName: cluster.TestCurrentClusterName},
 				},
 			},
-			TableVersion: persistence.DomainTableVersionV1,
+			IsGlobalDomain: true,
+			TableVersion:   persistence.DomainTableVersionV1,
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken: taskToken,
-			Identity:  &identity,
-		},
-	})
-	s.NotNil(err)
-	s.IsType(&workflow.EntityNotExistsError{}, err)
+	_, err := s.mockHistoryEngine.MarkBinaryBad(context.Background(), domainID, "bad-checksum", "crash loop", "operator", true)
+	s.Equal(ErrGlobalDomainBadBinary, err)
+	s.mockMetadataMgr.AssertNotCalled(s.T(), "UpdateDomain", mock.Anything)
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedIfTaskNotStarted() {
+func (s *engineSuite) TestFindBadBinaryResetPoint() {
 	domainID := validDomainID
-	we := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("wId"),
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-find-bad-binary-reset-point"),
 		RunId:      common.StringPtr(validRunID),
 	}
-	tl := "testTaskList"
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 2,
-	})
-	identity := "testIdentity"
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+		loggerimpl.NewDevelopmentForTest(s.Suite), execution.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", "testTaskList", []byte("input"), 100, 200, "testIdentity")
 	addDecisionTaskScheduledEvent(msBuilder)
+	ms := createMutableState(msBuilder)
+	resetPoint := &workflow.ResetPointInfo{
+		BinaryChecksum:           common.StringPtr("bad-checksum"),
+		RunId:                    common.StringPtr(validRunID),
+		FirstDecisionCompletedId: common.Int64Ptr(4),
+		Resettable:               common.BoolPtr(true),
+	}
+	ms.ExecutionInfo.AutoResetPoints = &workflow.ResetPoints{Points: []*workflow.ResetPointInfo{resetPoint}}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(&persistence.GetWorkflowExecutionResponse{State: ms}, nil).Once()
+
+	badBinaries := &workflow.BadBinaries{Binaries: map[string]*workflow.BadBinaryInfo{"bad-checksum": {}}}
+	point, err := s.mockHistoryEngine.findBadBinaryResetPoint(context.Background(), domainID, execution, badBinaries)
+	s.Nil(err)
+	s.Equal(resetPoint, point)
+
+	// other binaries have no reset point
+	point, err = s.mockHistoryEngine.findBadBinaryResetPoint(context.Background(), domainID, execution,
+		&workflow.BadBinaries{Binaries: map[string]*workflow.BadBinaryInfo{"other-checksum": {}}})
+	s.Nil(err)
+	s.Nil(point)
+
+	// executions owned by other shards are skipped
+	otherExecution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-find-bad-binary-reset-point-other-shard"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil, &workflow.EntityNotExistsError{}).Once()
+	point, err = s.mockHistoryEngine.findBadBinaryResetPoint(context.Background(), domainID, otherExecution, badBinaries)
+	s.Nil(err)
+	s.Nil(point)
+}
+
+func (s *engineSuite) TestGetReplicationStatus() {
+	domainID := validDomainID
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-get-replication-status"),
+		RunId:      common.StringPtr(validRunID),
+	}
 
+	msBuilder := newMutableStateBuilderWithReplicationStateWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(),
+		s.mockHistoryEngine.shard, s.eventsCache, loggerimpl.NewDevelopmentForTest(s.Suite), 10, execution.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", "testTaskList", []byte("input"), 100, 200, "testIdentity")
+	addDecisionTaskScheduledEvent(msBuilder)
 	ms := createMutableState(msBuilder)
-	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	ms.ReplicationState.LastWriteVersion = 10
+	ms.ReplicationState.LastWriteEventID = 2
+	ms.ReplicationState.LastReplicationInfo = map[string]*persistence.ReplicationInfo{
+		cluster.TestAlternativeClusterName: {Version: 11, LastEventID: 1},
+	}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(&persistence.GetWorkflowExecutionResponse{State: ms}, nil).Once()
 
-	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
-		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
-			Config: &persistence.DomainConfig{Retention: 1},
-			ReplicationConfig: &persistence.DomainReplicationConfig{
-				ActiveClusterName: cluster.TestCurrentClusterName,
-				Clusters: []*persistence.ClusterReplicationConfig{
-					{ClusterName: cluster.TestCurrentClusterName},
-				},
-			},
-			TableVersion: persistence.DomainTableVersionV1,
-		},
-		nil,
-	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	status, err := s.mockHistoryEngine.GetReplicationStatus(context.Background(), domainID, execution)
+	s.Nil(err)
+	s.Equal(&WorkflowReplicationStatus{
+		Replicated:       true,
+		NextEventID:      3,
+		CurrentVersion:   10,
+		LastWriteVersion: 10,
+		LastWriteEventID: 2,
+		LastReplicationInfo: map[string]*persistence.ReplicationInfo{
+			cluster.TestAlternativeClusterName: {Version: 11, LastEventID: 1},
+		},
+	}, status)
+}
 
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken: taskToken,
-		},
-	})
-	s.NotNil(err)
-	s.IsType(&workflow.EntityNotExistsError{}, err)
+func (s *engineSuite) TestGetReplicationStatus_LocalDomain() {
+	domainID := validDomainID
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-get-replication-status"),
+		RunId:      common.StringPtr(validRunID),
+	}
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), execution.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", "testTaskList", []byte("input"), 100, 200, "testIdentity")
+	addDecisionTaskScheduledEvent(msBuilder)
+	ms := createMutableState(msBuilder)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(&persistence.GetWorkflowExecutionResponse{State: ms}, nil).Once()
+
+	status, err := s.mockHistoryEngine.GetReplicationStatus(context.Background(), domainID, execution)
+	s.Nil(err)
+	s.Equal(&WorkflowReplicationStatus{Replicated: false, NextEventID: 3}, status)
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedConflictOnUpdate() {
+func (s *engineSuite) TestCleanupOrphanedHistoryBranch_Referenced() {
 	domainID := validDomainID
-	we := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("wId"),
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-cleanup-orphaned-history-branch"),
 		RunId:      common.StringPtr(validRunID),
 	}
-	tl := "testTaskList"
+	tasklist := "testTaskList"
 	identity := "testIdentity"
-	executionContext := []byte("context")
-	activity1ID := "activity1"
-	activity1Type := "activity_type1"
-	activity1Input := []byte("input1")
-	activity1Result := []byte("activity1_result")
-	activity2ID := "activity2"
-	activity2Type := "activity_type2"
-	activity2Input := []byte("input2")
-	activity2Result := []byte("activity2_result")
-	activity3ID := "activity3"
-	activity3Type := "activity_type3"
-	activity3Input := []byte("input3")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
-	di1 := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di1.ScheduleID, tl, identity)
-	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di1.ScheduleID,
-		*decisionStartedEvent1.EventId, nil, identity)
-	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId,
-		activity1ID, activity1Type, tl, activity1Input, 100, 10, 5)
-	activity2ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId,
-		activity2ID, activity2Type, tl, activity2Input, 100, 10, 5)
-	activity1StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
-	activity2StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity2ScheduledEvent.EventId, identity)
-	addActivityTaskCompletedEvent(msBuilder, *activity1ScheduledEvent.EventId,
-		*activity1StartedEvent.EventId, activity1Result, identity)
-	di2 := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent2 := addDecisionTaskStartedEvent(msBuilder, di2.ScheduleID, tl, identity)
+		loggerimpl.NewDevelopmentForTest(s.Suite), execution.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", tasklist, []byte("input"), 100, 200, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
+	ms := createMutableState(msBuilder)
+	gweResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		RunID:      we.GetRunId(),
-		ScheduleID: di2.ScheduleID,
-	})
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gweResponse, nil).Once()
 
-	decisions := []*workflow.Decision{{
-		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
-		ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
-			ActivityId:                    common.StringPtr(activity3ID),
-			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr(activity3Type)},
-			TaskList:                      &workflow.TaskList{Name: &tl},
-			Input:                         activity3Input,
-			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
-			ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
-			StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
-			HeartbeatTimeoutSeconds:       common.Int32Ptr(5),
-		},
-	}}
+	err := s.mockHistoryEngine.CleanupOrphanedHistoryBranch(context.Background(), domainID, execution, msBuilder.GetCurrentBranch())
+	s.Equal(ErrHistoryBranchInUse, err)
+	s.mockHistoryV2Mgr.AssertNotCalled(s.T(), "DeleteHistoryBranch", mock.Anything)
+}
 
-	ms := createMutableState(msBuilder)
-	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+func (s *engineSuite) TestCleanupOrphanedHistoryBranch_Orphaned() {
+	domainID := validDomainID
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-cleanup-orphaned-history-branch"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	branchToken, err := persistence.NewHistoryBranchToken(execution.GetRunId())
+	s.Nil(err)
 
-	addActivityTaskCompletedEvent(msBuilder, *activity2ScheduledEvent.EventId,
-		*activity2StartedEvent.EventId, activity2Result, identity)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil, &workflow.EntityNotExistsError{}).Once()
+	s.mockHistoryV2Mgr.On("DeleteHistoryBranch", &persistence.DeleteHistoryBranchRequest{
+		BranchToken: branchToken,
+		ShardID:     common.IntPtr(s.mockHistoryEngine.shard.GetShardID()),
+	}).Return(nil).Once()
 
-	ms2 := createMutableState(msBuilder)
-	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: ms2}
+	err = s.mockHistoryEngine.CleanupOrphanedHistoryBranch(context.Background(), domainID, execution, branchToken)
+	s.Nil(err)
 
-	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
-		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
-			Config: &persistence.DomainConfig{Retention: 1},
-			ReplicationConfig: &persistence.DomainReplicationConfig{
-				ActiveClusterName: cluster.TestCurrentClusterName,
-				Clusters: []*persistence.ClusterReplicationConfig{
-					{ClusterName: cluster.TestCurrentClusterName},
-				},
-			},
-			TableVersion: persistence.DomainTableVersionV1,
-		},
-		nil,
-	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}},
-		&persistence.ConditionFailedError{}).Once()
+	// without a run ID the current run of the workflow would be checked instead of the one owning the branch
+	err = s.mockHistoryEngine.CleanupOrphanedHistoryBranch(context.Background(), domainID, workflow.WorkflowExecution{
+		WorkflowId: execution.WorkflowId,
+	}, branchToken)
+	s.IsType(&workflow.BadRequestError{}, err)
 
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	// the branch of another run, e.g. the base run of a reset, is not deleted through this run
+	otherBranchToken, err := persistence.NewHistoryBranchToken(uuid.New())
+	s.Nil(err)
+	err = s.mockHistoryEngine.CleanupOrphanedHistoryBranch(context.Background(), domainID, execution, otherBranchToken)
+	s.IsType(&workflow.BadRequestError{}, err)
 
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: executionContext,
-			Identity:         &identity,
+	forkedBranchToken, err := codec.NewThriftRWEncoder().Encode(&workflow.HistoryBranch{
+		TreeID:   common.StringPtr(execution.GetRunId()),
+		BranchID: common.StringPtr(uuid.New()),
+		Ancestors: []*workflow.HistoryBranchRange{
+			{BranchID: common.StringPtr(uuid.New()), BeginNodeID: common.Int64Ptr(1), EndNodeID: common.Int64Ptr(5)},
 		},
 	})
-	s.Nil(err, s.printHistory(msBuilder))
-	s.Equal(int64(16), ms2.ExecutionInfo.NextEventID)
-	s.Equal(*decisionStartedEvent2.EventId, ms2.ExecutionInfo.LastProcessedEvent)
-	s.Equal(executionContext, ms2.ExecutionInfo.ExecutionContext)
+	s.Nil(err)
+	err = s.mockHistoryEngine.CleanupOrphanedHistoryBranch(context.Background(), domainID, execution, forkedBranchToken)
+	s.IsType(&workflow.BadRequestError{}, err)
+}
 
-	executionBuilder := s.getBuilder(domainID, we)
-	activity3Attributes := s.getActivityScheduledEvent(executionBuilder, 13).ActivityTaskScheduledEventAttributes
-	s.Equal(activity3ID, *activity3Attributes.ActivityId)
-	s.Equal(activity3Type, *activity3Attributes.ActivityType.Name)
-	s.Equal(int64(12), *activity3Attributes.DecisionTaskCompletedEventId)
-	s.Equal(tl, *activity3Attributes.TaskList.Name)
-	s.Equal(activity3Input, activity3Attributes.Input)
-	s.Equal(int32(100), *activity3Attributes.ScheduleToCloseTimeoutSeconds)
-	s.Equal(int32(10), *activity3Attributes.ScheduleToStartTimeoutSeconds)
-	s.Equal(int32(50), *activity3Attributes.StartToCloseTimeoutSeconds)
-	s.Equal(int32(5), *activity3Attributes.HeartbeatTimeoutSeconds)
+func (s *engineSuite) TestDescribeQueueProcessorStatus() {
+	shard := s.mockHistoryEngine.shard.(*shardContextWrapper).ShardContext.(*shardContextImpl)
+	shard.transferMaxReadLevel = 10
+
+	status := s.mockHistoryEngine.DescribeQueueProcessorStatus()
+	s.Equal(shard.GetShardID(), status.ShardID)
+	s.Equal(cluster.TestCurrentClusterName, status.CurrentClusterName)
+	s.Equal(int64(0), status.TransferProcessor.AckLevel)
+	s.Equal(int64(10), status.TransferProcessor.MaxReadLevel)
+	s.Equal(int64(10), status.TransferProcessor.Backlog)
+	s.False(status.TransferProcessor.Locked)
+	s.NotNil(status.TimerProcessor)
+	s.False(status.TimerProcessor.Locked)
+	s.Nil(status.ReplicatorProcessor)
+
+	s.mockHistoryEngine.txProcessor.LockTaskPrrocessing()
+	s.mockHistoryEngine.timerProcessor.LockTaskPrrocessing()
+	status = s.mockHistoryEngine.DescribeQueueProcessorStatus()
+	s.True(status.TransferProcessor.Locked)
+	s.True(status.TimerProcessor.Locked)
+	s.mockHistoryEngine.txProcessor.UnlockTaskPrrocessing()
+	s.mockHistoryEngine.timerProcessor.UnlockTaskPrrocessing()
+}
 
-	di, ok := executionBuilder.GetPendingDecision(15)
-	s.True(ok)
-	s.Equal(int32(100), di.DecisionTimeout)
+func (s *engineSuite) TestDeleteWorkflowExecution_Closed() {
+	domainID := validDomainID
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-delete-workflow-execution"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tasklist := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), execution.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", tasklist, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tasklist, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID, decisionStartedEvent.GetEventId(), nil, identity)
+	addCompleteWorkflowEvent(msBuilder, decisionCompletedEvent.GetEventId(), nil)
+	_ = msBuilder.SetHistoryTree(execution.GetRunId())
+	ms := createMutableState(msBuilder)
+	gweResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockHistoryEngine.visibilityMgr = s.mockVisibilityMgr
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gweResponse, nil).Once()
+	s.mockVisibilityMgr.On("DeleteWorkflowExecution", mock.MatchedBy(func(request *persistence.VisibilityDeleteWorkflowExecutionRequest) bool {
+		return request.DomainID == domainID && request.WorkflowID == execution.GetWorkflowId() && request.RunID == execution.GetRunId()
+	})).Return(nil).Once()
+	s.mockHistoryV2Mgr.On("DeleteHistoryBranch", mock.MatchedBy(func(request *persistence.DeleteHistoryBranchRequest) bool {
+		return bytes.Equal(msBuilder.GetCurrentBranch(), request.BranchToken)
+	})).Return(nil).Once()
+	s.mockExecutionMgr.On("DeleteCurrentWorkflowExecution", mock.Anything).Return(nil).Once()
+	s.mockExecutionMgr.On("DeleteWorkflowExecution", mock.Anything).Return(nil).Once()
+
+	err := s.mockHistoryEngine.DeleteWorkflowExecution(context.Background(), domainID, execution)
+	s.Nil(err)
+
+	// deleting again is a noop
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil, &workflow.EntityNotExistsError{}).Once()
+	err = s.mockHistoryEngine.DeleteWorkflowExecution(context.Background(), domainID, execution)
+	s.Nil(err)
 }
 
-func (s *engineSuite) TestValidateSignalRequest() {
-	workflowType := "testType"
-	input := []byte("input")
-	startRequest := &workflow.StartWorkflowExecutionRequest{
-		WorkflowId:                          common.StringPtr("ID"),
-		WorkflowType:                        &workflow.WorkflowType{Name: &workflowType},
-		TaskList:                            &workflow.TaskList{Name: common.StringPtr("taskptr")},
-		Input:                               input,
-		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(10),
-		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(10),
-		Identity:                            common.StringPtr("identity"),
+func (s *engineSuite) TestDeleteWorkflowExecution_Running() {
+	domainID := validDomainID
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-delete-workflow-execution"),
+		RunId:      common.StringPtr(validRunID),
 	}
-	err := validateStartWorkflowExecutionRequest(startRequest, 999)
-	s.Error(err, "startRequest doesn't have request id, it should error out")
+	tasklist := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), execution.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", tasklist, []byte("input"), 100, 200, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
+	ms := createMutableState(msBuilder)
+	gweResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gweResponse, nil).Once()
+
+	err := s.mockHistoryEngine.DeleteWorkflowExecution(context.Background(), domainID, execution)
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *engineSuite) TestGetMutableStateLongPoll() {
+	ctx := context.Background()
+	domainID := validDomainID
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-get-workflow-execution-event-id"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tasklist := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), execution.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", tasklist, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tasklist, identity)
+	ms := createMutableState(msBuilder)
+	gweResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	// right now the next event ID is 4
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gweResponse, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	).Once()
+
+	// test long poll on next event ID change
+	asycWorkflowUpdate := func(delay time.Duration) {
+		taskToken, _ := json.Marshal(&common.TaskToken{
+			WorkflowID: *execution.WorkflowId,
+			RunID:      *execution.RunId,
+			ScheduleID: 2,
+		})
+		s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+		s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+		timer := time.NewTimer(delay)
+
+		<-timer.C
+		s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+			&persistence.GetDomainResponse{
+				Info:   &persistence.DomainInfo{ID: domainID},
+				Config: &persistence.DomainConfig{Retention: 1},
+				ReplicationConfig: &persistence.DomainReplicationConfig{
+					ActiveClusterName: cluster.TestCurrentClusterName,
+					Clusters: []*persistence.ClusterReplicationConfig{
+						{ClusterName: cluster.TestCurrentClusterName},
+					},
+				},
+				TableVersion: persistence.DomainTableVersionV1,
+			},
+			nil,
+		)
+		_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+			DomainUUID: common.StringPtr(domainID),
+			CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+				TaskToken: taskToken,
+				Identity:  &identity,
+			},
+		})
+		s.Nil(err)
+		// right now the next event ID is 5
+	}
+
+	// return immediately, since the expected next event ID appears
+	response, err := s.mockHistoryEngine.GetMutableState(ctx, &history.GetMutableStateRequest{
+		DomainUUID:          common.StringPtr(domainID),
+		Execution:           &execution,
+		ExpectedNextEventId: common.Int64Ptr(3),
+	})
+	s.Nil(err)
+	s.Equal(int64(4), *response.NextEventId)
+
+	// long poll, new event happen before long poll timeout
+	go asycWorkflowUpdate(time.Second * 2)
+	start := time.Now()
+	response, err = s.mockHistoryEngine.GetMutableState(ctx, &history.GetMutableStateRequest{
+		DomainUUID:          common.StringPtr(domainID),
+		Execution:           &execution,
+		ExpectedNextEventId: common.Int64Ptr(4),
+	})
+	s.True(time.Now().After(start.Add(time.Second * 1)))
+	s.Nil(err)
+	s.Equal(int64(5), *response.NextEventId)
+}
+
+func (s *engineSuite) TestGetMutableStateLongPollTimeout() {
+	ctx := context.Background()
+	domainID := validDomainID
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("test-get-workflow-execution-event-id"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tasklist := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), execution.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, execution, "wType", tasklist, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tasklist, identity)
+	ms := createMutableState(msBuilder)
+	gweResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	// right now the next event ID is 4
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gweResponse, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	).Once()
+
+	// long poll, no event happen after long poll timeout
+	response, err := s.mockHistoryEngine.GetMutableState(ctx, &history.GetMutableStateRequest{
+		DomainUUID:          common.StringPtr(domainID),
+		Execution:           &execution,
+		ExpectedNextEventId: common.Int64Ptr(4),
+	})
+	s.Nil(err)
+	s.Equal(int64(4), *response.NextEventId)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedInvalidToken() {
+	domainID := validDomainID
+	invalidToken, _ := json.Marshal("bad token")
+	identity := "testIdentity"
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        invalidToken,
+			Decisions:        nil,
+			ExecutionContext: nil,
+			Identity:         &identity,
+		},
+	})
+
+	s.NotNil(err)
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedIfNoExecution() {
+	domainID := validDomainID
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      validRunID,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil, &workflow.EntityNotExistsError{}).Once()
+
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Identity:  &identity,
+		},
+	})
+	s.NotNil(err)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedIfGetExecutionFailed() {
+	domainID := validDomainID
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      validRunID,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil, errors.New("FAILED")).Once()
+
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Identity:  &identity,
+		},
+	})
+	s.EqualError(err, "FAILED")
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedUpdateExecutionFailed() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, errors.New("FAILED")).Once()
+	s.mockShardManager.On("UpdateShard", mock.Anything).Return(nil).Once()
+
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Identity:  &identity,
+		},
+	})
+	s.NotNil(err)
+	s.EqualError(err, "FAILED")
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedIfTaskCompleted() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	startedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID, *startedEvent.EventId, nil, identity)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Identity:  &identity,
+		},
+	})
+	s.NotNil(err)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedIfTaskNotStarted() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+		},
+	})
+	s.NotNil(err)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedConflictOnUpdate() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+	executionContext := []byte("context")
+	activity1ID := "activity1"
+	activity1Type := "activity_type1"
+	activity1Input := []byte("input1")
+	activity1Result := []byte("activity1_result")
+	activity2ID := "activity2"
+	activity2Type := "activity_type2"
+	activity2Input := []byte("input2")
+	activity2Result := []byte("activity2_result")
+	activity3ID := "activity3"
+	activity3Type := "activity_type3"
+	activity3Input := []byte("input3")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	di1 := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di1.ScheduleID, tl, identity)
+	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di1.ScheduleID,
+		*decisionStartedEvent1.EventId, nil, identity)
+	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId,
+		activity1ID, activity1Type, tl, activity1Input, 100, 10, 5)
+	activity2ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId,
+		activity2ID, activity2Type, tl, activity2Input, 100, 10, 5)
+	activity1StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
+	activity2StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity2ScheduledEvent.EventId, identity)
+	addActivityTaskCompletedEvent(msBuilder, *activity1ScheduledEvent.EventId,
+		*activity1StartedEvent.EventId, activity1Result, identity)
+	di2 := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent2 := addDecisionTaskStartedEvent(msBuilder, di2.ScheduleID, tl, identity)
+
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: di2.ScheduleID,
+	})
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
+		ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr(activity3ID),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr(activity3Type)},
+			TaskList:                      &workflow.TaskList{Name: &tl},
+			Input:                         activity3Input,
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
+			ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
+			StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
+			HeartbeatTimeoutSeconds:       common.Int32Ptr(5),
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	addActivityTaskCompletedEvent(msBuilder, *activity2ScheduledEvent.EventId,
+		*activity2StartedEvent.EventId, activity2Result, identity)
+
+	ms2 := createMutableState(msBuilder)
+	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: ms2}
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}},
+		&persistence.ConditionFailedError{}).Once()
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	s.Equal(int64(16), ms2.ExecutionInfo.NextEventID)
+	s.Equal(*decisionStartedEvent2.EventId, ms2.ExecutionInfo.LastProcessedEvent)
+	s.Equal(executionContext, ms2.ExecutionInfo.ExecutionContext)
+
+	executionBuilder := s.getBuilder(domainID, we)
+	activity3Attributes := s.getActivityScheduledEvent(executionBuilder, 13).ActivityTaskScheduledEventAttributes
+	s.Equal(activity3ID, *activity3Attributes.ActivityId)
+	s.Equal(activity3Type, *activity3Attributes.ActivityType.Name)
+	s.Equal(int64(12), *activity3Attributes.DecisionTaskCompletedEventId)
+	s.Equal(tl, *activity3Attributes.TaskList.Name)
+	s.Equal(activity3Input, activity3Attributes.Input)
+	s.Equal(int32(100), *activity3Attributes.ScheduleToCloseTimeoutSeconds)
+	s.Equal(int32(10), *activity3Attributes.ScheduleToStartTimeoutSeconds)
+	s.Equal(int32(50), *activity3Attributes.StartToCloseTimeoutSeconds)
+	s.Equal(int32(5), *activity3Attributes.HeartbeatTimeoutSeconds)
+
+	di, ok := executionBuilder.GetPendingDecision(15)
+	s.True(ok)
+	s.Equal(int32(100), di.DecisionTimeout)
+}
+
+func (s *engineSuite) TestValidateSignalRequest() {
+	workflowType := "testType"
+	input := []byte("input")
+	startRequest := &workflow.StartWorkflowExecutionRequest{
+		WorkflowId:                          common.StringPtr("ID"),
+		WorkflowType:                        &workflow.WorkflowType{Name: &workflowType},
+		TaskList:                            &workflow.TaskList{Name: common.StringPtr("taskptr")},
+		Input:                               input,
+		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(10),
+		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(10),
+		Identity:                            common.StringPtr("identity"),
+	}
+	err := validateStartWorkflowExecutionRequest(startRequest, 999, 10, 1000, s.searchAttributesValidator())
+	s.Error(err, "startRequest doesn't have request id, it should error out")
+}
+
+func (s *engineSuite) TestValidateStartWorkflowExecutionRequest_NonRetriableErrorReasons() {
+	workflowType := "testType"
+	startRequest := &workflow.StartWorkflowExecutionRequest{
+		RequestId:                           common.StringPtr(uuid.New()),
+		WorkflowId:                          common.StringPtr("ID"),
+		WorkflowType:                        &workflow.WorkflowType{Name: &workflowType},
+		TaskList:                            &workflow.TaskList{Name: common.StringPtr("taskptr")},
+		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(10),
+		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(10),
+		Identity:                            common.StringPtr("identity"),
+		RetryPolicy: &workflow.RetryPolicy{
+			InitialIntervalInSeconds: common.Int32Ptr(1),
+			BackoffCoefficient:       common.Float64Ptr(2),
+			MaximumAttempts:          common.Int32Ptr(3),
+			NonRetriableErrorReasons: []string{"reason 1", "reason 2"},
+		},
+	}
+	s.Nil(validateStartWorkflowExecutionRequest(startRequest, 999, 2, 16, s.searchAttributesValidator()))
+
+	err := validateStartWorkflowExecutionRequest(startRequest, 999, 1, 16, s.searchAttributesValidator())
+	s.IsType(&workflow.BadRequestError{}, err)
+
+	err = validateStartWorkflowExecutionRequest(startRequest, 999, 2, 15, s.searchAttributesValidator())
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *engineSuite) TestValidateStartWorkflowExecutionRequest_CronScheduleWithRetryPolicy() {
+	workflowType := "testType"
+	startRequest := &workflow.StartWorkflowExecutionRequest{
+		RequestId:                           common.StringPtr(uuid.New()),
+		WorkflowId:                          common.StringPtr("ID"),
+		WorkflowType:                        &workflow.WorkflowType{Name: &workflowType},
+		TaskList:                            &workflow.TaskList{Name: common.StringPtr("taskptr")},
+		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(10),
+		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(10),
+		Identity:                            common.StringPtr("identity"),
+		CronSchedule:                        common.StringPtr("@every 1h"),
+		RetryPolicy: &workflow.RetryPolicy{
+			InitialIntervalInSeconds:    common.Int32Ptr(1),
+			BackoffCoefficient:          common.Float64Ptr(2),
+			ExpirationIntervalInSeconds: common.Int32Ptr(3600),
+		},
+	}
+	s.Nil(validateStartWorkflowExecutionRequest(startRequest, 999, 10, 1000, s.searchAttributesValidator()))
+
+	startRequest.RetryPolicy.ExpirationIntervalInSeconds = common.Int32Ptr(7200)
+	err := validateStartWorkflowExecutionRequest(startRequest, 999, 10, 1000, s.searchAttributesValidator())
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *engineSuite) TestValidateStartWorkflowExecutionRequest_SearchAttributes() {
+	workflowType := "testType"
+	startRequest := &workflow.StartWorkflowExecutionRequest{
+		RequestId:                           common.StringPtr(uuid.New()),
+		WorkflowId:                          common.StringPtr("ID"),
+		WorkflowType:                        &workflow.WorkflowType{Name: &workflowType},
+		TaskList:                            &workflow.TaskList{Name: common.StringPtr("taskptr")},
+		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(10),
+		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(10),
+		Identity:                            common.StringPtr("identity"),
+		SearchAttributes: &workflow.SearchAttributes{
+			IndexedFields: map[string][]byte{
+				definition.CustomStringField: []byte(`"custom"`),
+				definition.CustomIntField:    []byte("1"),
+			},
+		},
+	}
+	s.Nil(validateStartWorkflowExecutionRequest(startRequest, 999, 10, 1000, s.searchAttributesValidator()))
+
+	startRequest.SearchAttributes.IndexedFields["UnknownField"] = []byte("1")
+	err := validateStartWorkflowExecutionRequest(startRequest, 999, 10, 1000, s.searchAttributesValidator())
+	s.IsType(&workflow.BadRequestError{}, err)
+	s.Contains(err.(*workflow.BadRequestError).Message, "UnknownField")
+	s.NotContains(err.(*workflow.BadRequestError).Message, definition.CustomStringField)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedMaxAttemptsExceeded() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	executionContext := []byte("context")
+	input := []byte("input")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
+		ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr("activity1"),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
+			TaskList:                      &workflow.TaskList{Name: &tl},
+			Input:                         input,
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
+			ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
+			StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
+			HeartbeatTimeoutSeconds:       common.Int32Ptr(5),
+		},
+	}}
+
+	for i := 0; i < conditionalRetryCount; i++ {
+		ms := createMutableState(msBuilder)
+		gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+		s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+		s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}},
+			&persistence.ConditionFailedError{}).Once()
+	}
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+		},
+	})
+	s.NotNil(err)
+	s.Equal(ErrMaxAttemptsExceeded, err)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedCompleteWorkflowFailed() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+	executionContext := []byte("context")
+	activity1ID := "activity1"
+	activity1Type := "activity_type1"
+	activity1Input := []byte("input1")
+	activity1Result := []byte("activity1_result")
+	activity2ID := "activity2"
+	activity2Type := "activity_type2"
+	activity2Input := []byte("input2")
+	activity2Result := []byte("activity2_result")
+	workflowResult := []byte("workflow result")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 25, 200, identity)
+	di1 := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di1.ScheduleID, tl, identity)
+	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di1.ScheduleID,
+		*decisionStartedEvent1.EventId, nil, identity)
+	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId,
+		activity1ID, activity1Type, tl, activity1Input, 100, 10, 5)
+	activity2ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId,
+		activity2ID, activity2Type, tl, activity2Input, 100, 10, 5)
+	activity1StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
+	activity2StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity2ScheduledEvent.EventId, identity)
+	addActivityTaskCompletedEvent(msBuilder, *activity1ScheduledEvent.EventId,
+		*activity1StartedEvent.EventId, activity1Result, identity)
+	di2 := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di2.ScheduleID, tl, identity)
+	addActivityTaskCompletedEvent(msBuilder, *activity2ScheduledEvent.EventId,
+		*activity2StartedEvent.EventId, activity2Result, identity)
+
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: di2.ScheduleID,
+	})
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeCompleteWorkflowExecution),
+		CompleteWorkflowExecutionDecisionAttributes: &workflow.CompleteWorkflowExecutionDecisionAttributes{
+			Result: workflowResult,
+		},
+	}}
+
+	for i := 0; i < 2; i++ {
+		ms := createMutableState(msBuilder)
+		gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	}
+
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(15), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(*decisionStartedEvent1.EventId, executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+	s.True(executionBuilder.HasPendingDecisionTask())
+	di3, ok := executionBuilder.GetPendingDecision(executionBuilder.GetExecutionInfo().NextEventID - 1)
+	s.True(ok)
+	s.Equal(executionBuilder.GetExecutionInfo().NextEventID-1, di3.ScheduleID)
+	s.Equal(int64(0), di3.Attempt)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedFailWorkflowFailed() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+	executionContext := []byte("context")
+	activity1ID := "activity1"
+	activity1Type := "activity_type1"
+	activity1Input := []byte("input1")
+	activity1Result := []byte("activity1_result")
+	activity2ID := "activity2"
+	activity2Type := "activity_type2"
+	activity2Input := []byte("input2")
+	activity2Result := []byte("activity2_result")
+	reason := "workflow fail reason"
+	details := []byte("workflow fail details")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 25, 200, identity)
+	di1 := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di1.ScheduleID, tl, identity)
+	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di1.ScheduleID,
+		*decisionStartedEvent1.EventId, nil, identity)
+	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity1ID,
+		activity1Type, tl, activity1Input, 100, 10, 5)
+	activity2ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity2ID,
+		activity2Type, tl, activity2Input, 100, 10, 5)
+	activity1StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
+	activity2StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity2ScheduledEvent.EventId, identity)
+	addActivityTaskCompletedEvent(msBuilder, *activity1ScheduledEvent.EventId,
+		*activity1StartedEvent.EventId, activity1Result, identity)
+	di2 := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di2.ScheduleID, tl, identity)
+	addActivityTaskCompletedEvent(msBuilder, *activity2ScheduledEvent.EventId,
+		*activity2StartedEvent.EventId, activity2Result, identity)
+
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: di2.ScheduleID,
+	})
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeFailWorkflowExecution),
+		FailWorkflowExecutionDecisionAttributes: &workflow.FailWorkflowExecutionDecisionAttributes{
+			Reason:  &reason,
+			Details: details,
+		},
+	}}
+
+	for i := 0; i < 2; i++ {
+		ms := createMutableState(msBuilder)
+		gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	}
+
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(15), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(*decisionStartedEvent1.EventId, executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+	s.True(executionBuilder.HasPendingDecisionTask())
+	di3, ok := executionBuilder.GetPendingDecision(executionBuilder.GetExecutionInfo().NextEventID - 1)
+	s.True(ok)
+	s.Equal(executionBuilder.GetExecutionInfo().NextEventID-1, di3.ScheduleID)
+	s.Equal(int64(0), di3.Attempt)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedBadDecisionAttributes() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+	executionContext := []byte("context")
+	activity1ID := "activity1"
+	activity1Type := "activity_type1"
+	activity1Input := []byte("input1")
+	activity1Result := []byte("activity1_result")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 25, 200, identity)
+	di1 := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di1.ScheduleID, tl, identity)
+	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di1.ScheduleID,
+		*decisionStartedEvent1.EventId, nil, identity)
+	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity1ID,
+		activity1Type, tl, activity1Input, 100, 10, 5)
+	activity1StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
+	addActivityTaskCompletedEvent(msBuilder, *activity1ScheduledEvent.EventId,
+		*activity1StartedEvent.EventId, activity1Result, identity)
+	di2 := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di2.ScheduleID, tl, identity)
+
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: di2.ScheduleID,
+	})
+
+	// Decision with nil attributes
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeCompleteWorkflowExecution),
+	}}
+
+	gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse1, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
+
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{
+		MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
+	).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+		},
+	})
+	s.Nil(err)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompleted_PendingChildWorkflowsLimitExceeded() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+	s.mockHistoryEngine.config.MaximumChildWorkflowsPerExecution = dynamicconfig.GetIntPropertyFilteredByDomain(1)
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di1 := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di1.ScheduleID, tl, identity)
+	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di1.ScheduleID,
+		decisionStartedEvent1.GetEventId(), nil, identity)
+	addStartChildWorkflowExecutionInitiatedEvent(msBuilder, decisionCompletedEvent1.GetEventId(), uuid.New(),
+		domainID, "child wId 1", "child wType", tl, nil, 100, 10)
+	di2 := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di2.ScheduleID, tl, identity)
+
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: we.GetWorkflowId(),
+		RunID:      we.GetRunId(),
+		ScheduleID: di2.ScheduleID,
+	})
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeStartChildWorkflowExecution),
+		StartChildWorkflowExecutionDecisionAttributes: &workflow.StartChildWorkflowExecutionDecisionAttributes{
+			WorkflowId:                          common.StringPtr("child wId 2"),
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("child wType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr(tl)},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(100),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(10),
+			ChildPolicy:                         common.ChildPolicyPtr(workflow.ChildPolicyTerminate),
+		},
+	}}
+
+	gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse1, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
+
+	var failedEvent *workflow.HistoryEvent
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Run(func(args mock.Arguments) {
+		for _, event := range args.Get(0).(*p.AppendHistoryNodesRequest).Events {
+			if event.GetEventType() == workflow.EventTypeDecisionTaskFailed {
+				failedEvent = event
+			}
+		}
+	}).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{
+		MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
+	).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Decisions: decisions,
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err)
+	s.NotNil(failedEvent)
+	s.Equal(workflow.DecisionTaskFailedCausePendingChildWorkflowsLimitExceeded,
+		failedEvent.DecisionTaskFailedEventAttributes.GetCause())
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(1, len(executionBuilder.GetPendingChildExecutionInfos()))
+	s.True(executionBuilder.HasPendingDecisionTask())
+}
+
+// This test unit tests the activity schedule timeout validation logic of HistoryEngine's RespondDecisionTaskComplete function.
+// An scheduled activity decision has 3 timeouts: ScheduleToClose, ScheduleToStart and StartToClose.
+// This test verifies that when either ScheduleToClose or ScheduleToStart and StartToClose are specified,
+// HistoryEngine's validateActivityScheduleAttribute will deduce the missing timeout and fill it in
+// instead of returning a BadRequest error and only when all three are missing should a BadRequest be returned.
+func (s *engineSuite) TestRespondDecisionTaskCompletedSingleActivityScheduledAttribute() {
+	workflowTimeout := int32(100)
+	testIterationVariables := []struct {
+		scheduleToClose         *int32
+		scheduleToStart         *int32
+		startToClose            *int32
+		heartbeat               *int32
+		expectedScheduleToClose int32
+		expectedScheduleToStart int32
+		expectedStartToClose    int32
+		expectDecisionFail      bool
+	}{
+		// No ScheduleToClose timeout, will use ScheduleToStart + StartToClose
+		{nil, common.Int32Ptr(3), common.Int32Ptr(7), nil,
+			3 + 7, 3, 7, false},
+		// Has ScheduleToClose timeout but not ScheduleToStart or StartToClose,
+		// will use ScheduleToClose for ScheduleToStart and StartToClose
+		{common.Int32Ptr(7), nil, nil, nil,
+			7, 7, 7, false},
+		// No ScheduleToClose timeout, ScheduleToStart or StartToClose, expect error return
+		{nil, nil, nil, nil,
+			0, 0, 0, true},
+		// Negative ScheduleToClose, expect error return
+		{common.Int32Ptr(-1), nil, nil, nil,
+			0, 0, 0, true},
+		// Negative ScheduleToStart, expect error return
+		{nil, common.Int32Ptr(-1), nil, nil,
+			0, 0, 0, true},
+		// Negative StartToClose, expect error return
+		{nil, nil, common.Int32Ptr(-1), nil,
+			0, 0, 0, true},
+		// Negative HeartBeat, expect error return
+		{nil, nil, nil, common.Int32Ptr(-1),
+			0, 0, 0, true},
+		// Use workflow timeout
+		{common.Int32Ptr(workflowTimeout), nil, nil, nil,
+			workflowTimeout, workflowTimeout, workflowTimeout, false},
+		// Timeout larger than workflow timeout
+		{common.Int32Ptr(workflowTimeout + 1), nil, nil, nil,
+			workflowTimeout, workflowTimeout, workflowTimeout, false},
+		{nil, common.Int32Ptr(workflowTimeout + 1), nil, nil,
+			0, 0, 0, true},
+		{nil, nil, common.Int32Ptr(workflowTimeout + 1), nil,
+			0, 0, 0, true},
+		{nil, nil, nil, common.Int32Ptr(workflowTimeout + 1),
+			0, 0, 0, true},
+		// No ScheduleToClose timeout, will use ScheduleToStart + StartToClose, but exceed limit
+		{nil, common.Int32Ptr(workflowTimeout), common.Int32Ptr(10), nil,
+			workflowTimeout, workflowTimeout, 10, false},
+	}
+
+	for _, iVar := range testIterationVariables {
+		domainID := validDomainID
+		we := workflow.WorkflowExecution{
+			WorkflowId: common.StringPtr("wId"),
+			RunId:      common.StringPtr(validRunID),
+		}
+		tl := "testTaskList"
+		taskToken, _ := json.Marshal(&common.TaskToken{
+			WorkflowID: "wId",
+			RunID:      we.GetRunId(),
+			ScheduleID: 2,
+		})
+		identity := "testIdentity"
+		executionContext := []byte("context")
+		input := []byte("input")
+
+		msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+			loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+		addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), workflowTimeout, 200, identity)
+		di := addDecisionTaskScheduledEvent(msBuilder)
+		addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+		decisions := []*workflow.Decision{{
+			DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
+			ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
+				ActivityId:                    common.StringPtr("activity1"),
+				ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
+				TaskList:                      &workflow.TaskList{Name: &tl},
+				Input:                         input,
+				ScheduleToCloseTimeoutSeconds: iVar.scheduleToClose,
+				ScheduleToStartTimeoutSeconds: iVar.scheduleToStart,
+				StartToCloseTimeoutSeconds:    iVar.startToClose,
+				HeartbeatTimeoutSeconds:       iVar.heartbeat,
+			},
+		}}
+
+		gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse1, nil).Once()
+		if iVar.expectDecisionFail {
+			gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+			s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
+		}
+
+		s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+		s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+		s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+			&persistence.GetDomainResponse{
+				Info:   &persistence.DomainInfo{ID: domainID},
+				Config: &persistence.DomainConfig{Retention: 1},
+				ReplicationConfig: &persistence.DomainReplicationConfig{
+					ActiveClusterName: cluster.TestCurrentClusterName,
+					Clusters: []*persistence.ClusterReplicationConfig{
+						{ClusterName: cluster.TestCurrentClusterName},
+					},
+				},
+				TableVersion: persistence.DomainTableVersionV1,
+			},
+			nil,
+		)
+		_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+			DomainUUID: common.StringPtr(domainID),
+			CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+				TaskToken:        taskToken,
+				Decisions:        decisions,
+				ExecutionContext: executionContext,
+				Identity:         &identity,
+			},
+		})
+
+		s.Nil(err, s.printHistory(msBuilder))
+		executionBuilder := s.getBuilder(domainID, we)
+		if !iVar.expectDecisionFail {
+			s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
+			s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
+			s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+			s.False(executionBuilder.HasPendingDecisionTask())
+
+			activity1Attributes := s.getActivityScheduledEvent(executionBuilder, int64(5)).ActivityTaskScheduledEventAttributes
+			s.Equal(iVar.expectedScheduleToClose, activity1Attributes.GetScheduleToCloseTimeoutSeconds())
+			s.Equal(iVar.expectedScheduleToStart, activity1Attributes.GetScheduleToStartTimeoutSeconds())
+			s.Equal(iVar.expectedStartToClose, activity1Attributes.GetStartToCloseTimeoutSeconds())
+		} else {
+			s.Equal(int64(5), executionBuilder.GetExecutionInfo().NextEventID)
+			s.Equal(common.EmptyEventID, executionBuilder.GetExecutionInfo().LastProcessedEvent)
+			s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+			s.True(executionBuilder.HasPendingDecisionTask())
+		}
+		s.TearDownTest()
+		s.SetupTest()
+	}
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedBadBinary() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	executionContext := []byte("context")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	var decisions []*workflow.Decision
+
+	gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse1, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info: &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{
+				Retention: 1,
+				BadBinaries: workflow.BadBinaries{
+					Binaries: map[string]*workflow.BadBinaryInfo{
+						"test-bad-binary": {},
+					},
+				},
+			},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+			BinaryChecksum:   common.StringPtr("test-bad-binary"),
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(5), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(common.EmptyEventID, executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+	s.True(executionBuilder.HasPendingDecisionTask())
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedExecutionContextExceedsLimit() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	previousExecutionContext := []byte("context")
+	executionContext := []byte("oversized execution context")
+	s.mockHistoryEngine.config.BlobSizeLimitError = dynamicconfig.GetIntPropertyFilteredByDomain(len(executionContext) - 1)
+	s.mockHistoryEngine.config.BlobSizeLimitWarn = dynamicconfig.GetIntPropertyFilteredByDomain(len(previousExecutionContext))
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	msBuilder.GetExecutionInfo().ExecutionContext = previousExecutionContext
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeCompleteWorkflowExecution),
+		CompleteWorkflowExecutionDecisionAttributes: &workflow.CompleteWorkflowExecutionDecisionAttributes{
+			Result: []byte("success"),
+		},
+	}}
+
+	gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+
+	var appendedEvents []*workflow.HistoryEvent
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse1, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Run(func(args mock.Arguments) {
+		appendedEvents = append(appendedEvents, args.Get(0).(*p.AppendHistoryNodesRequest).Events...)
+	}).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(5), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(previousExecutionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+	s.True(executionBuilder.HasPendingDecisionTask())
+	s.Equal(1, len(appendedEvents))
+	s.Equal(workflow.EventTypeDecisionTaskFailed, appendedEvents[0].GetEventType())
+	s.Equal(workflow.DecisionTaskFailedCauseBadExecutionContextSize,
+		appendedEvents[0].DecisionTaskFailedEventAttributes.GetCause())
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompleted_AutoResetPointsDisabled() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	s.mockHistoryEngine.config.EnableAutoResetPoints = dynamicconfig.GetBoolPropertyFnFilteredByDomain(false)
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:      taskToken,
+			Identity:       &identity,
+			BinaryChecksum: common.StringPtr("test-binary"),
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Nil(executionBuilder.GetExecutionInfo().AutoResetPoints)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedSingleActivityScheduledDecision() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	executionContext := []byte("context")
+	input := []byte("input")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
+		ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr("activity1"),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
+			TaskList:                      &workflow.TaskList{Name: &tl},
+			Input:                         input,
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
+			ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
+			StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
+			HeartbeatTimeoutSeconds:       common.Int32Ptr(5),
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+	s.False(executionBuilder.HasPendingDecisionTask())
+
+	activity1Attributes := s.getActivityScheduledEvent(executionBuilder, int64(5)).ActivityTaskScheduledEventAttributes
+	s.Equal("activity1", *activity1Attributes.ActivityId)
+	s.Equal("activity_type1", *activity1Attributes.ActivityType.Name)
+	s.Equal(int64(4), *activity1Attributes.DecisionTaskCompletedEventId)
+	s.Equal(tl, *activity1Attributes.TaskList.Name)
+	s.Equal(input, activity1Attributes.Input)
+	s.Equal(int32(100), *activity1Attributes.ScheduleToCloseTimeoutSeconds)
+	s.Equal(int32(10), *activity1Attributes.ScheduleToStartTimeoutSeconds)
+	s.Equal(int32(50), *activity1Attributes.StartToCloseTimeoutSeconds)
+	s.Equal(int32(5), *activity1Attributes.HeartbeatTimeoutSeconds)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedActivityTypeBlobSizeLimit() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	input := []byte("activity input exceeding the domain limit")
+	s.mockHistoryEngine.config.BlobSizeLimitWarn = dynamicconfig.GetIntPropertyFilteredByDomain(5)
+	s.mockHistoryEngine.config.BlobSizeLimitError = dynamicconfig.GetIntPropertyFilteredByDomain(10)
+	s.mockHistoryEngine.config.ActivityTypeBlobSizeLimitError = dynamicconfig.GetMapPropertyFn(map[string]interface{}{
+		"large_activity_type": 100,
+	})
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
+		ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr("activity1"),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("large_activity_type")},
+			TaskList:                      &workflow.TaskList{Name: &tl},
+			Input:                         input,
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
+			ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
+			StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
+			HeartbeatTimeoutSeconds:       common.Int32Ptr(5),
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Decisions: decisions,
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	// the input is within the limit of its activity type, even though it exceeds the domain limit
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+	activity1Attributes := s.getActivityScheduledEvent(executionBuilder, int64(5)).ActivityTaskScheduledEventAttributes
+	s.Equal("large_activity_type", activity1Attributes.ActivityType.GetName())
+	s.Equal(input, activity1Attributes.Input)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedCronLastCompletionResultSizeLimit() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	s.mockHistoryEngine.config.BlobSizeLimitWarn = dynamicconfig.GetIntPropertyFilteredByDomain(5)
+	s.mockHistoryEngine.config.BlobSizeLimitError = dynamicconfig.GetIntPropertyFilteredByDomain(10)
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	// the last completion result of the previous cron run exceeds the current limit
+	msBuilder.AddWorkflowExecutionStartedEvent(we, &history.StartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		StartRequest: &workflow.StartWorkflowExecutionRequest{
+			WorkflowId:                          we.WorkflowId,
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("wType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr(tl)},
+			Input:                               []byte("input"),
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(100),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(200),
+			Identity:                            common.StringPtr(identity),
+			CronSchedule:                        common.StringPtr("@every 1h"),
+		},
+		LastCompletionResult: []byte("last completion result exceeding the limit"),
+	})
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeFailWorkflowExecution),
+		FailWorkflowExecutionDecisionAttributes: &workflow.FailWorkflowExecutionDecisionAttributes{
+			Reason:  common.StringPtr("fail reason"),
+			Details: []byte("details"),
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	// the next cron run is not created
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	var appendedEvents []*workflow.HistoryEvent
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Run(func(args mock.Arguments) {
+		appendedEvents = append(appendedEvents, args.Get(0).(*p.AppendHistoryNodesRequest).Events...)
+	}).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Decisions: decisions,
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(persistence.WorkflowStateCompleted, executionBuilder.GetExecutionInfo().State)
+	s.Equal(persistence.WorkflowCloseStatusFailed, executionBuilder.GetExecutionInfo().CloseStatus)
+	completionEvent := appendedEvents[len(appendedEvents)-1]
+	s.Equal(workflow.EventTypeWorkflowExecutionFailed, completionEvent.GetEventType())
+	s.Equal(common.FailureReasonDecisionBlobSizeExceedsLimit,
+		completionEvent.WorkflowExecutionFailedEventAttributes.GetReason())
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedCronResultSizeLimit() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	s.mockHistoryEngine.config.BlobSizeLimitWarn = dynamicconfig.GetIntPropertyFilteredByDomain(5)
+	s.mockHistoryEngine.config.BlobSizeLimitError = dynamicconfig.GetIntPropertyFilteredByDomain(10)
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	msBuilder.AddWorkflowExecutionStartedEvent(we, &history.StartWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		StartRequest: &workflow.StartWorkflowExecutionRequest{
+			WorkflowId:                          we.WorkflowId,
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("wType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr(tl)},
+			Input:                               []byte("input"),
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(100),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(200),
+			Identity:                            common.StringPtr(identity),
+			CronSchedule:                        common.StringPtr("@every 1h"),
+		},
+	})
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	// the result of the run, carried forward to the next cron run, exceeds the limit
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeCompleteWorkflowExecution),
+		CompleteWorkflowExecutionDecisionAttributes: &workflow.CompleteWorkflowExecutionDecisionAttributes{
+			Result: []byte("completion result exceeding the limit"),
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	// the next cron run is not created
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	var appendedEvents []*workflow.HistoryEvent
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Run(func(args mock.Arguments) {
+		appendedEvents = append(appendedEvents, args.Get(0).(*p.AppendHistoryNodesRequest).Events...)
+	}).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Decisions: decisions,
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(persistence.WorkflowStateCompleted, executionBuilder.GetExecutionInfo().State)
+	s.Equal(persistence.WorkflowCloseStatusFailed, executionBuilder.GetExecutionInfo().CloseStatus)
+	for _, event := range appendedEvents {
+		s.NotEqual(workflow.EventTypeWorkflowExecutionContinuedAsNew, event.GetEventType())
+	}
+	completionEvent := appendedEvents[len(appendedEvents)-1]
+	s.Equal(workflow.EventTypeWorkflowExecutionFailed, completionEvent.GetEventType())
+	s.Equal(common.FailureReasonDecisionBlobSizeExceedsLimit,
+		completionEvent.WorkflowExecutionFailedEventAttributes.GetReason())
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedEventsWritten() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	var decisions []*workflow.Decision
+	for _, activityID := range []string{"activity1", "activity2"} {
+		decisions = append(decisions, &workflow.Decision{
+			DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
+			ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
+				ActivityId:                    common.StringPtr(activityID),
+				ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
+				TaskList:                      &workflow.TaskList{Name: &tl},
+				Input:                         []byte("input"),
+				ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
+				ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
+				StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
+				HeartbeatTimeoutSeconds:       common.Int32Ptr(5),
+			},
+		})
+	}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	resp, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Decisions: decisions,
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	// DecisionTaskCompleted and two ActivityTaskScheduled events
+	s.Equal(int64(3), resp.GetEventsWritten())
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(7), executionBuilder.GetExecutionInfo().NextEventID)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedDispatchOnlyActivityScheduledDecision() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
+		ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr("activity1"),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
+			TaskList:                      &workflow.TaskList{Name: &tl},
+			Input:                         []byte("input"),
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
+			ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
+			StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
+			DispatchOnly:                  common.BoolPtr(true),
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	var timerTasks []persistence.Task
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(
+		&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
+	).Run(func(args mock.Arguments) {
+		timerTasks = args.Get(0).(*persistence.UpdateWorkflowExecutionRequest).TimerTasks
+	}).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Decisions: decisions,
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+
+	activity1Attributes := s.getActivityScheduledEvent(executionBuilder, int64(5)).ActivityTaskScheduledEventAttributes
+	s.True(activity1Attributes.GetDispatchOnly())
+	s.Equal(int32(10), activity1Attributes.GetScheduleToStartTimeoutSeconds())
+	s.Equal(int32(0), activity1Attributes.GetScheduleToCloseTimeoutSeconds())
+	s.Equal(int32(50), activity1Attributes.GetStartToCloseTimeoutSeconds())
+	s.Equal(int32(0), activity1Attributes.GetHeartbeatTimeoutSeconds())
+
+	// only the schedule to start timeout is tracked for the activity
+	var activityTimeouts []*persistence.ActivityTimeoutTask
+	for _, task := range timerTasks {
+		if timeoutTask, ok := task.(*persistence.ActivityTimeoutTask); ok {
+			activityTimeouts = append(activityTimeouts, timeoutTask)
+		}
+	}
+	s.Equal(1, len(activityTimeouts))
+	s.Equal(int(workflow.TimeoutTypeScheduleToStart), activityTimeouts[0].TimeoutType)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedDelayedActivityScheduledDecision() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
+		ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr("activity1"),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
+			TaskList:                      &workflow.TaskList{Name: &tl},
+			Input:                         []byte("input"),
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
+			ScheduleToStartTimeoutSeconds: common.Int32Ptr(60),
+			StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
+			ScheduleDelaySeconds:          common.Int32Ptr(30),
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	var updateRequest *persistence.UpdateWorkflowExecutionRequest
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(
+		&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
+	).Run(func(args mock.Arguments) {
+		updateRequest = args.Get(0).(*persistence.UpdateWorkflowExecutionRequest)
+	}).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Decisions: decisions,
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	activity1Attributes := s.getActivityScheduledEvent(executionBuilder, int64(5)).ActivityTaskScheduledEventAttributes
+	s.Equal(int32(30), activity1Attributes.GetScheduleDelaySeconds())
+	ai, ok := executionBuilder.GetActivityInfo(int64(5))
+	s.True(ok)
+
+	// the activity is not dispatched to matching right away, but by a timer once the delay elapses
+	for _, task := range updateRequest.TransferTasks {
+		s.NotEqual(persistence.TransferTaskTypeActivityTask, task.GetType())
+	}
+	var retryTimers []*persistence.ActivityRetryTimerTask
+	for _, task := range updateRequest.TimerTasks {
+		if retryTimer, ok := task.(*persistence.ActivityRetryTimerTask); ok {
+			retryTimers = append(retryTimers, retryTimer)
+		}
+	}
+	s.Equal(1, len(retryTimers))
+	s.Equal(int64(5), retryTimers[0].EventID)
+	s.Equal(int32(0), retryTimers[0].Attempt)
+	s.Equal(ai.ScheduledTime.Add(30*time.Second), retryTimers[0].VisibilityTimestamp)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedCompleteWorkflowSuccess() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	executionContext := []byte("context")
+	workflowResult := []byte("success")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeCompleteWorkflowExecution),
+		CompleteWorkflowExecutionDecisionAttributes: &workflow.CompleteWorkflowExecutionDecisionAttributes{
+			Result: workflowResult,
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info: &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{
+				Retention:      1,
+				ArchivalStatus: workflow.ArchivalStatusEnabled,
+			},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	s.mockClusterMetadata.On("IsArchivalEnabled").Return(true)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
+	s.Equal(persistence.WorkflowStateCompleted, executionBuilder.GetExecutionInfo().State)
+	s.False(executionBuilder.HasPendingDecisionTask())
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedDomainDeleted() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeStartTimer),
+		StartTimerDecisionAttributes: &workflow.StartTimerDecisionAttributes{
+			TimerId:                   common.StringPtr("timer1"),
+			StartToFireTimeoutSeconds: common.Int64Ptr(10),
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	// the domain is deleted after the workflow is loaded, but before the update is committed
+	deletedDomainEntry := cache.NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{ID: domainID, Status: persistence.DomainStatusDeleted},
+		&persistence.DomainConfig{Retention: 1},
+		cluster.TestCurrentClusterName,
+		nil,
+	)
+	mockDomainCache := &cache.DomainCacheMock{}
+	mockDomainCache.On("GetDomainByID", domainID).Return(deletedDomainEntry, nil)
+	shard := s.mockHistoryEngine.shard.(*shardContextWrapper).ShardContext.(*shardContextImpl)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Run(func(args mock.Arguments) {
+		shard.domainCache = mockDomainCache
+	}).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Decisions: decisions,
+			Identity:  &identity,
+		},
+	})
+	s.Equal(ErrDomainDeleted, err)
+	s.mockHistoryV2Mgr.AssertNotCalled(s.T(), "AppendHistoryNodes", mock.Anything)
+	s.mockExecutionMgr.AssertNotCalled(s.T(), "UpdateWorkflowExecution", mock.Anything)
+
+	mockDomainCache.AssertExpectations(s.T())
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedFailWorkflowSuccess() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	executionContext := []byte("context")
+	details := []byte("fail workflow details")
+	reason := "fail workflow reason"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeFailWorkflowExecution),
+		FailWorkflowExecutionDecisionAttributes: &workflow.FailWorkflowExecutionDecisionAttributes{
+			Reason:  &reason,
+			Details: details,
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info: &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{
+				Retention:      1,
+				ArchivalStatus: workflow.ArchivalStatusEnabled,
+			},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockClusterMetadata.On("IsArchivalEnabled").Return(true)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
+	s.Equal(persistence.WorkflowStateCompleted, executionBuilder.GetExecutionInfo().State)
+	s.False(executionBuilder.HasPendingDecisionTask())
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedLocalActivityMarkerExceedsLimit() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	markerDetails := []byte("local activity result exceeding the limit")
+	s.mockHistoryEngine.config.BlobSizeLimitWarn = dynamicconfig.GetIntPropertyFilteredByDomain(5)
+	s.mockHistoryEngine.config.BlobSizeLimitError = dynamicconfig.GetIntPropertyFilteredByDomain(10)
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeRecordMarker),
+		RecordMarkerDecisionAttributes: &workflow.RecordMarkerDecisionAttributes{
+			MarkerName: common.StringPtr(localActivityMarkerName),
+			Details:    markerDetails,
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockClusterMetadata.On("IsArchivalEnabled").Return(false)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Decisions: decisions,
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(persistence.WorkflowStateCompleted, executionBuilder.GetExecutionInfo().State)
+	s.Equal(persistence.WorkflowCloseStatusFailed, executionBuilder.GetExecutionInfo().CloseStatus)
+	s.False(executionBuilder.HasPendingDecisionTask())
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedUpsertWorkflowMemo() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	msBuilder.GetExecutionInfo().Memo = map[string][]byte{
+		"kept":    []byte("kept value"),
+		"updated": []byte("old value"),
+	}
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeUpsertWorkflowMemo),
+		UpsertWorkflowMemoDecisionAttributes: &workflow.UpsertWorkflowMemoDecisionAttributes{
+			Memo: &workflow.Memo{Fields: map[string][]byte{
+				"updated": []byte("new value"),
+				"added":   []byte("added value"),
+			}},
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	var transferTasks []persistence.Task
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Run(func(args mock.Arguments) {
+		transferTasks = append(transferTasks, args.Get(0).(*persistence.UpdateWorkflowExecutionRequest).TransferTasks...)
+	}).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Decisions: decisions,
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(map[string][]byte{
+		"kept":    []byte("kept value"),
+		"updated": []byte("new value"),
+		"added":   []byte("added value"),
+	}, executionBuilder.GetExecutionInfo().Memo)
+
+	var upsertTasks int
+	for _, task := range transferTasks {
+		if task.GetType() == persistence.TransferTaskTypeUpsertWorkflowMemo {
+			upsertTasks++
+		}
+	}
+	s.Equal(1, upsertTasks)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedUpsertWorkflowMemoExceedsLimit() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	sizeLimitError := s.mockHistoryEngine.config.BlobSizeLimitError
+	defer func() { s.mockHistoryEngine.config.BlobSizeLimitError = sizeLimitError }()
+	s.mockHistoryEngine.config.BlobSizeLimitError = dynamicconfig.GetIntPropertyFilteredByDomain(70)
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	existingMemo := map[string][]byte{
+		"existing": []byte("memo value"),
+	}
+	msBuilder.GetExecutionInfo().Memo = existingMemo
+
+	// the upserted fields alone are within the limit, the merged memo is not
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeUpsertWorkflowMemo),
+		UpsertWorkflowMemoDecisionAttributes: &workflow.UpsertWorkflowMemoDecisionAttributes{
+			Memo: &workflow.Memo{Fields: map[string][]byte{
+				"key": []byte("value"),
+			}},
+		},
+	}}
+
+	gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+
+	var appendedEvents []*workflow.HistoryEvent
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse1, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Run(func(args mock.Arguments) {
+		appendedEvents = append(appendedEvents, args.Get(0).(*p.AppendHistoryNodesRequest).Events...)
+	}).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Decisions: decisions,
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(5), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(existingMemo, executionBuilder.GetExecutionInfo().Memo)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+	s.True(executionBuilder.HasPendingDecisionTask())
+	s.Equal(1, len(appendedEvents))
+	s.Equal(workflow.EventTypeDecisionTaskFailed, appendedEvents[0].GetEventType())
+	s.Equal(workflow.DecisionTaskFailedCauseBadUpsertWorkflowMemoAttributes,
+		appendedEvents[0].DecisionTaskFailedEventAttributes.GetCause())
+	s.Contains(string(appendedEvents[0].DecisionTaskFailedEventAttributes.Details), "size 74 after UpsertWorkflowMemoDecisionAttributes exceeds limit 70")
+}
+
+func (s *engineSuite) TestRespondDecisionTaskFailedSuccess() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockClusterMetadata.On("IsArchivalEnabled").Return(false)
+	err := s.mockHistoryEngine.RespondDecisionTaskFailed(context.Background(), &history.RespondDecisionTaskFailedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		FailedRequest: &workflow.RespondDecisionTaskFailedRequest{
+			TaskToken: taskToken,
+			Cause:     common.DecisionTaskFailedCausePtr(workflow.DecisionTaskFailedCauseUnhandledDecision),
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(5), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+	s.True(executionBuilder.HasPendingDecisionTask())
+	di, ok := executionBuilder.GetPendingDecision(int64(5))
+	s.True(ok)
+	s.Equal(int64(1), di.Attempt)
+	s.Equal(common.EmptyEventID, di.StartedID)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskFailedAttemptsExceedLimit() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	s.mockHistoryEngine.config.MaximumDecisionTaskAttempts = dynamicconfig.GetIntPropertyFilteredByDomain(1)
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockClusterMetadata.On("IsArchivalEnabled").Return(false)
+	err := s.mockHistoryEngine.RespondDecisionTaskFailed(context.Background(), &history.RespondDecisionTaskFailedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		FailedRequest: &workflow.RespondDecisionTaskFailedRequest{
+			TaskToken: taskToken,
+			Cause:     common.DecisionTaskFailedCausePtr(workflow.DecisionTaskFailedCauseUnhandledDecision),
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(persistence.WorkflowStateCompleted, executionBuilder.GetExecutionInfo().State)
+	s.Equal(persistence.WorkflowCloseStatusTerminated, executionBuilder.GetExecutionInfo().CloseStatus)
+	s.False(executionBuilder.HasPendingDecisionTask())
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedHistoryLengthExceedsLimit() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	s.mockHistoryEngine.config.MaximumHistoryLength = dynamicconfig.GetIntPropertyFilteredByDomain(5)
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
+		ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
+			ActivityId:                    common.StringPtr("activity1"),
+			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
+			TaskList:                      &workflow.TaskList{Name: &tl},
+			Input:                         []byte("input"),
+			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
+			ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
+			StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
+			HeartbeatTimeoutSeconds:       common.Int32Ptr(5),
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	var updateRequest *persistence.UpdateWorkflowExecutionRequest
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Run(func(args mock.Arguments) {
+		updateRequest = args.Get(0).(*persistence.UpdateWorkflowExecutionRequest)
+	}).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockClusterMetadata.On("IsArchivalEnabled").Return(false)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Decisions: decisions,
+			Identity:  &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(7), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(persistence.WorkflowStateCompleted, executionBuilder.GetExecutionInfo().State)
+	s.Equal(persistence.WorkflowCloseStatusTerminated, executionBuilder.GetExecutionInfo().CloseStatus)
+	s.False(executionBuilder.HasPendingDecisionTask())
+
+	s.NotNil(updateRequest)
+	var hasCloseTask bool
+	for _, task := range updateRequest.TransferTasks {
+		if task.GetType() == persistence.TransferTaskTypeCloseExecution {
+			hasCloseTask = true
+		}
+	}
+	s.True(hasCloseTask)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedSignalExternalWorkflowSuccess() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	executionContext := []byte("context")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeSignalExternalWorkflowExecution),
+		SignalExternalWorkflowExecutionDecisionAttributes: &workflow.SignalExternalWorkflowExecutionDecisionAttributes{
+			Domain: common.StringPtr(domainID),
+			Execution: &workflow.WorkflowExecution{
+				WorkflowId: we.WorkflowId,
+				RunId:      we.RunId,
+			},
+			SignalName: common.StringPtr("signal"),
+			Input:      []byte("test input"),
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedTransferTaskOrdering() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	targetExecution := &workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("target wId"),
+		RunId:      common.StringPtr(uuid.New()),
+	}
+	decisions := []*workflow.Decision{
+		{
+			DecisionType: common.DecisionTypePtr(workflow.DecisionTypeSignalExternalWorkflowExecution),
+			SignalExternalWorkflowExecutionDecisionAttributes: &workflow.SignalExternalWorkflowExecutionDecisionAttributes{
+				Domain:     common.StringPtr(domainID),
+				Execution:  targetExecution,
+				SignalName: common.StringPtr("signal"),
+				Input:      []byte("test input"),
+			},
+		},
+		{
+			DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
+			ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
+				ActivityId:                    common.StringPtr("activity1"),
+				ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
+				TaskList:                      &workflow.TaskList{Name: &tl},
+				Input:                         []byte("input1"),
+				ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
+				ScheduleToStartTimeoutSeconds: common.Int32Ptr(50),
+				StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
+				HeartbeatTimeoutSeconds:       common.Int32Ptr(5),
+			},
+		},
+		{
+			DecisionType: common.DecisionTypePtr(workflow.DecisionTypeRequestCancelExternalWorkflowExecution),
+			RequestCancelExternalWorkflowExecutionDecisionAttributes: &workflow.RequestCancelExternalWorkflowExecutionDecisionAttributes{
+				Domain:     common.StringPtr(domainID),
+				WorkflowId: targetExecution.WorkflowId,
+				RunId:      targetExecution.RunId,
+			},
+		},
+		{
+			DecisionType: common.DecisionTypePtr(workflow.DecisionTypeStartChildWorkflowExecution),
+			StartChildWorkflowExecutionDecisionAttributes: &workflow.StartChildWorkflowExecutionDecisionAttributes{
+				WorkflowId:                          common.StringPtr("child wId"),
+				WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("child wType")},
+				TaskList:                            &workflow.TaskList{Name: common.StringPtr(tl)},
+				ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(100),
+				TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(10),
+				ChildPolicy:                         common.ChildPolicyPtr(workflow.ChildPolicyTerminate),
+			},
+		},
+	}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	var updateRequest *persistence.UpdateWorkflowExecutionRequest
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(
+		&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
+	).Run(func(args mock.Arguments) {
+		updateRequest = args.Get(0).(*persistence.UpdateWorkflowExecutionRequest)
+	}).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:                  taskToken,
+			Decisions:                  decisions,
+			Identity:                   &identity,
+			ForceCreateNewDecisionTask: common.BoolPtr(true),
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+
+	// tasks generated by the decisions come in decision order, followed by the task for the new decision
+	var taskTypes []int
+	for _, task := range updateRequest.TransferTasks {
+		taskTypes = append(taskTypes, task.GetType())
+	}
+	s.Equal([]int{
+		persistence.TransferTaskTypeSignalExecution,
+		persistence.TransferTaskTypeActivityTask,
+		persistence.TransferTaskTypeCancelExecution,
+		persistence.TransferTaskTypeStartChildExecution,
+		persistence.TransferTaskTypeDecisionTask,
+	}, taskTypes)
+	for i := 1; i < len(updateRequest.TransferTasks); i++ {
+		s.True(updateRequest.TransferTasks[i-1].GetTaskID() < updateRequest.TransferTasks[i].GetTaskID())
+	}
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedResetStickyForNextDecision() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	stickyTl := "testStickyTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	msBuilder.GetExecutionInfo().StickyTaskList = stickyTl
+	msBuilder.GetExecutionInfo().StickyScheduleToStartTimeout = 10
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	var updateRequest *persistence.UpdateWorkflowExecutionRequest
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(
+		&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
+	).Run(func(args mock.Arguments) {
+		updateRequest = args.Get(0).(*persistence.UpdateWorkflowExecutionRequest)
+	}).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Identity:  &identity,
+			StickyAttributes: &workflow.StickyExecutionAttributes{
+				WorkerTaskList:                &workflow.TaskList{Name: common.StringPtr(stickyTl)},
+				ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
+			},
+			ResetStickyForNextDecision: common.BoolPtr(true),
+			ForceCreateNewDecisionTask: common.BoolPtr(true),
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+
+	// the next decision is scheduled on the normal task list without a sticky schedule to start timeout,
+	// the sticky attributes are kept for the decisions after it
+	executionBuilder := s.getBuilder(domainID, we)
+	s.True(executionBuilder.IsStickyTaskListEnabled())
+	s.Equal(stickyTl, executionBuilder.GetExecutionInfo().StickyTaskList)
+	s.Equal(int32(10), executionBuilder.GetExecutionInfo().StickyScheduleToStartTimeout)
+	s.True(executionBuilder.HasPendingDecisionTask())
+	var decisionTask *persistence.DecisionTask
+	for _, task := range updateRequest.TransferTasks {
+		if task.GetType() == persistence.TransferTaskTypeDecisionTask {
+			decisionTask = task.(*persistence.DecisionTask)
+		}
+	}
+	s.NotNil(decisionTask)
+	s.Equal(tl, decisionTask.TaskList)
+	for _, task := range updateRequest.TimerTasks {
+		s.NotEqual(persistence.TaskTypeDecisionTimeout, task.GetType())
+	}
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedResetStickyForNextDecisionReturnNewDecisionTask() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	stickyTl := "testStickyTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	s.mockHistoryEngine.config.ReturnNewDecisionTaskAlwaysSticky = dynamicconfig.GetBoolPropertyFnFilteredByDomain(true)
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, stickyTl, identity)
+	msBuilder.GetExecutionInfo().StickyTaskList = stickyTl
+	msBuilder.GetExecutionInfo().StickyScheduleToStartTimeout = 10
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(
+		&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
+	).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	resp, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Identity:  &identity,
+			StickyAttributes: &workflow.StickyExecutionAttributes{
+				WorkerTaskList:                &workflow.TaskList{Name: common.StringPtr(stickyTl)},
+				ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
+			},
+			ResetStickyForNextDecision: common.BoolPtr(true),
+			ForceCreateNewDecisionTask: common.BoolPtr(true),
+			ReturnNewDecisionTask:      common.BoolPtr(true),
+		},
+	})
+	s.Nil(err, s.printHistory(msBuilder))
+
+	// the worker evicted the workflow, so the new decision task comes with the full history
+	s.NotNil(resp.StartedResponse)
+	s.False(resp.StartedResponse.GetStickyExecutionEnabled())
+	s.Nil(resp.StartedResponse.StickyScheduleToStartTimeoutSeconds)
+	executionBuilder := s.getBuilder(domainID, we)
+	s.True(executionBuilder.IsStickyTaskListEnabled())
+	s.Equal(stickyTl, executionBuilder.GetExecutionInfo().StickyTaskList)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedStickyTaskListTooLong() {
+	domainID := validDomainID
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      validRunID,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	stickyTl := strings.Repeat("t", s.mockHistoryEngine.config.MaxIDLengthLimit()+1)
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken: taskToken,
+			Identity:  &identity,
+			StickyAttributes: &workflow.StickyExecutionAttributes{
+				WorkerTaskList:                &workflow.TaskList{Name: common.StringPtr(stickyTl)},
+				ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
+			},
+		},
+	})
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedEmptyDecisionLoop() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	s.mockHistoryEngine.config.EmptyDecisionLoopLimit = dynamicconfig.GetIntPropertyFilteredByDomain(2)
+	s.mockHistoryEngine.config.EmptyDecisionLoopMinInterval = dynamicconfig.GetDurationPropertyFnFilteredByDomain(time.Second)
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	ms := createMutableState(msBuilder)
+	// the first decision heartbeats a long running local activity
+	ms.ExecutionInfo.DecisionStartedTimestamp = time.Now().Add(-time.Minute).UnixNano()
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	var appendedEvents []*workflow.HistoryEvent
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Run(func(args mock.Arguments) {
+		appendedEvents = append(appendedEvents, args.Get(0).(*p.AppendHistoryNodesRequest).Events...)
+	}).Times(3)
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(
+		&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
+	).Times(3)
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	completeEmptyDecision := func(scheduleID int64) (*history.RespondDecisionTaskCompletedResponse, error) {
+		taskToken, _ := json.Marshal(&common.TaskToken{
+			WorkflowID: "wId",
+			RunID:      we.GetRunId(),
+			ScheduleID: scheduleID,
+		})
+		return s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+			DomainUUID: common.StringPtr(domainID),
+			CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+				TaskToken:                  taskToken,
+				Identity:                   &identity,
+				ForceCreateNewDecisionTask: common.BoolPtr(true),
+				ReturnNewDecisionTask:      common.BoolPtr(true),
+			},
+		})
+	}
+
+	// the slow empty decision is a heartbeat and is not counted
+	resp, err := completeEmptyDecision(di.ScheduleID)
+	s.Nil(err, s.printHistory(msBuilder))
+	s.NotNil(resp.StartedResponse)
+	executionBuilder := s.getBuilder(domainID, we)
+	s.True(executionBuilder.IsWorkflowExecutionRunning())
+	wfContext, release, err := s.mockHistoryEngine.historyCache.getOrCreateWorkflowExecution(domainID, we)
+	s.Nil(err)
+	s.Equal(0, wfContext.getEmptyDecisionCount())
+	s.Equal(1, wfContext.getDecisionHeartbeatCount())
+	release(nil)
+
+	// the first fast empty decision is below the limit and gets a new decision
+	resp, err = completeEmptyDecision(resp.StartedResponse.GetScheduledEventId())
+	s.Nil(err, s.printHistory(msBuilder))
+	s.NotNil(resp.StartedResponse)
+	executionBuilder = s.getBuilder(domainID, we)
+	s.True(executionBuilder.IsWorkflowExecutionRunning())
+	s.True(executionBuilder.HasPendingDecisionTask())
+
+	// the second consecutive fast empty decision reaches the limit and fails the workflow
+	resp, err = completeEmptyDecision(resp.StartedResponse.GetScheduledEventId())
+	s.Nil(err, s.printHistory(msBuilder))
+	s.Nil(resp.StartedResponse)
+	executionBuilder = s.getBuilder(domainID, we)
+	s.False(executionBuilder.IsWorkflowExecutionRunning())
+	s.False(executionBuilder.HasPendingDecisionTask())
+
+	lastEvent := appendedEvents[len(appendedEvents)-1]
+	s.Equal(workflow.EventTypeWorkflowExecutionFailed, lastEvent.GetEventType())
+	s.Equal(common.FailureReasonEmptyDecisionLoop, lastEvent.WorkflowExecutionFailedEventAttributes.GetReason())
+}
+
+func (s *engineSuite) TestDescribeWorkflowExecution_DecisionHeartbeatCount() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	// every decision heartbeat takes longer than the empty decision loop min interval on the shard clock
+	s.mockHistoryEngine.config.EmptyDecisionLoopMinInterval = dynamicconfig.GetDurationPropertyFnFilteredByDomain(time.Second)
+	shard := s.mockHistoryEngine.shard.(*shardContextWrapper).ShardContext.(*shardContextImpl)
+	shard.timeSource = clock.NewEventTimeSource().Update(time.Now().Add(time.Hour))
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Times(3)
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(
+		&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
+	).Times(3)
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	completeDecision := func(scheduleID int64, decisions []*workflow.Decision) *history.RespondDecisionTaskCompletedResponse {
+		taskToken, _ := json.Marshal(&common.TaskToken{
+			WorkflowID: "wId",
+			RunID:      we.GetRunId(),
+			ScheduleID: scheduleID,
+		})
+		resp, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+			DomainUUID: common.StringPtr(domainID),
+			CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+				TaskToken:                  taskToken,
+				Decisions:                  decisions,
+				Identity:                   &identity,
+				ForceCreateNewDecisionTask: common.BoolPtr(true),
+				ReturnNewDecisionTask:      common.BoolPtr(true),
+			},
+		})
+		s.Nil(err, s.printHistory(msBuilder))
+		return resp
+	}
+	describe := func() *workflow.DescribeWorkflowExecutionResponse {
+		resp, err := s.mockHistoryEngine.DescribeWorkflowExecution(context.Background(), &history.DescribeWorkflowExecutionRequest{
+			DomainUUID: common.StringPtr(domainID),
+			Request:    &workflow.DescribeWorkflowExecutionRequest{Execution: &we},
+		})
+		s.Nil(err)
+		return resp
+	}
+
+	s.False(describe().IsSetDecisionHeartbeatCount())
+
+	// two decision heartbeats in a row
+	resp := completeDecision(di.ScheduleID, nil)
+	resp = completeDecision(resp.StartedResponse.GetScheduledEventId(), nil)
+	s.Equal(int32(2), describe().GetDecisionHeartbeatCount())
+	wfContext, release, err := s.mockHistoryEngine.historyCache.getOrCreateWorkflowExecution(domainID, we)
+	s.Nil(err)
+	s.Equal(0, wfContext.getEmptyDecisionCount())
+	release(nil)
+
+	// a decision making progress ends the heartbeats
+	completeDecision(resp.StartedResponse.GetScheduledEventId(), []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeRecordMarker),
+		RecordMarkerDecisionAttributes: &workflow.RecordMarkerDecisionAttributes{
+			MarkerName: common.StringPtr("marker"),
+		},
+	}})
+	s.False(describe().IsSetDecisionHeartbeatCount())
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedReturnNewDecisionTask() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	var appendedEvents []*workflow.HistoryEvent
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Run(func(args mock.Arguments) {
+		appendedEvents = append(appendedEvents, args.Get(0).(*p.AppendHistoryNodesRequest).Events...)
+	}).Twice()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(
+		&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
+	).Twice()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	completeDecision := func(scheduleID int64) *history.RespondDecisionTaskCompletedResponse {
+		taskToken, _ := json.Marshal(&common.TaskToken{
+			WorkflowID: "wId",
+			RunID:      we.GetRunId(),
+			ScheduleID: scheduleID,
+		})
+		resp, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+			DomainUUID: common.StringPtr(domainID),
+			CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+				TaskToken:                  taskToken,
+				Identity:                   &identity,
+				ForceCreateNewDecisionTask: common.BoolPtr(true),
+				ReturnNewDecisionTask:      common.BoolPtr(true),
+			},
+		})
+		s.Nil(err, s.printHistory(msBuilder))
+		return resp
+	}
+	lastStartedEvent := func() *workflow.HistoryEvent {
+		for i := len(appendedEvents) - 1; i >= 0; i-- {
+			if appendedEvents[i].GetEventType() == workflow.EventTypeDecisionTaskStarted {
+				return appendedEvents[i]
+			}
+		}
+		return nil
+	}
+
+	// the new decision task is started by the worker completing the previous one and is sticky by default
+	resp := completeDecision(di.ScheduleID)
+	s.True(resp.StartedResponse.GetStickyExecutionEnabled())
+	startedEvent := lastStartedEvent()
+	s.NotNil(startedEvent)
+	s.Equal(resp.StartedResponse.GetStartedEventId(), startedEvent.GetEventId())
+	s.Equal(identity, startedEvent.DecisionTaskStartedEventAttributes.GetIdentity())
+	s.NotNil(uuid.Parse(startedEvent.DecisionTaskStartedEventAttributes.GetRequestId()))
+
+	// unless configured otherwise, the workflow has no sticky task list
+	s.mockHistoryEngine.config.ReturnNewDecisionTaskAlwaysSticky = dynamicconfig.GetBoolPropertyFnFilteredByDomain(false)
+	resp = completeDecision(resp.StartedResponse.GetScheduledEventId())
+	s.False(resp.StartedResponse.GetStickyExecutionEnabled())
+	s.Equal(identity, lastStartedEvent().DecisionTaskStartedEventAttributes.GetIdentity())
+}
+
+func (s *engineSuite) TestReplayDecision() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	completedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID, di.ScheduleID+1, nil, identity)
+	addActivityTaskScheduledEvent(msBuilder, completedEvent.GetEventId(), "activity1", "activity_type1", tl, []byte("input1"), 100, 10, 5)
+	addTimerStartedEvent(msBuilder, completedEvent.GetEventId(), "timer1", 10)
+	events := msBuilder.GetHistoryBuilder().GetHistory().Events
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("ReadHistoryBranchByBatch", mock.Anything).Return(&persistence.ReadHistoryBranchByBatchResponse{
+		History: []*workflow.History{
+			{Events: events[0:2]},
+			{Events: events[2:3]},
+			{Events: events[3:]},
+		},
+	}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	// nothing is persisted, UpdateWorkflowExecution is not mocked
+	result, err := s.mockHistoryEngine.ReplayDecision(context.Background(), domainID, we, di.ScheduleID)
+	s.Nil(err)
+	s.Nil(result.FailCause)
+	s.Equal(events[4:], result.RecordedEvents)
+	s.Equal(2, len(result.ReplayedEvents))
+	for i, event := range result.ReplayedEvents {
+		s.Equal(result.RecordedEvents[i].GetEventId(), event.GetEventId())
+		s.Equal(result.RecordedEvents[i].GetEventType(), event.GetEventType())
+	}
+	s.Equal("activity1", result.ReplayedEvents[0].ActivityTaskScheduledEventAttributes.GetActivityId())
+	s.Equal("timer1", result.ReplayedEvents[1].TimerStartedEventAttributes.GetTimerId())
+}
+
+func (s *engineSuite) TestReplayDecision_NotCompleted() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	events := msBuilder.GetHistoryBuilder().GetHistory().Events
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("ReadHistoryBranchByBatch", mock.Anything).Return(&persistence.ReadHistoryBranchByBatchResponse{
+		History: []*workflow.History{
+			{Events: events[0:2]},
+			{Events: events[2:3]},
+		},
+	}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	_, err := s.mockHistoryEngine.ReplayDecision(context.Background(), domainID, we, di.ScheduleID)
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedSignalExternalWorkflowFailed() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr("invalid run id"),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: we.GetWorkflowId(),
+		RunID:      we.GetRunId(),
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	executionContext := []byte("context")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeSignalExternalWorkflowExecution),
+		SignalExternalWorkflowExecutionDecisionAttributes: &workflow.SignalExternalWorkflowExecutionDecisionAttributes{
+			Domain: common.StringPtr(domainID),
+			Execution: &workflow.WorkflowExecution{
+				WorkflowId: we.WorkflowId,
+				RunId:      we.RunId,
+			},
+			SignalName: common.StringPtr("signal"),
+			Input:      []byte("test input"),
+		},
+	}}
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+		},
+	})
+
+	s.EqualError(err, "BadRequestError{Message: RunID is not valid UUID.}")
+}
+
+func (s *engineSuite) TestRespondDecisionTaskCompletedSignalExternalWorkflowFailed_UnKnownDomain() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+	executionContext := []byte("context")
+	foreignDomain := "unknown domain"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeSignalExternalWorkflowExecution),
+		SignalExternalWorkflowExecutionDecisionAttributes: &workflow.SignalExternalWorkflowExecutionDecisionAttributes{
+			Domain: common.StringPtr(foreignDomain),
+			Execution: &workflow.WorkflowExecution{
+				WorkflowId: we.WorkflowId,
+				RunId:      we.RunId,
+			},
+			SignalName: common.StringPtr("signal"),
+			Input:      []byte("test input"),
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", &persistence.GetDomainRequest{ID: domainID}).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	).Once()
+	s.mockMetadataMgr.On("GetDomain", &persistence.GetDomainRequest{Name: foreignDomain}).Return(
+		nil, errors.New("get foreign domain error")).Once()
+
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: executionContext,
+			Identity:         &identity,
+		},
+	})
+
+	s.NotNil(err)
+}
+
+func (s *engineSuite) TestRespondActivityTaskCompletedInvalidToken() {
+	domainID := validDomainID
+	invalidToken, _ := json.Marshal("bad token")
+	identity := "testIdentity"
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: invalidToken,
+			Result:    nil,
+			Identity:  &identity,
+		},
+	})
+
+	s.NotNil(err)
+	s.IsType(&workflow.BadRequestError{}, err)
+}
+
+func (s *engineSuite) TestRespondActivityTaskCompletedIfNoExecution() {
+	domainID := validDomainID
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      validRunID,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil, &workflow.EntityNotExistsError{}).Once()
+
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: taskToken,
+			Identity:  &identity,
+		},
+	})
+	s.NotNil(err)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
+}
+
+func (s *engineSuite) TestRespondActivityTaskCompletedIfNoRunID() {
+	domainID := validDomainID
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(nil, &workflow.EntityNotExistsError{}).Once()
+
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: taskToken,
+			Identity:  &identity,
+		},
+	})
+	s.NotNil(err)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
+}
+
+func (s *engineSuite) TestRespondActivityTaskCompletedIfGetExecutionFailed() {
+	domainID := validDomainID
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		RunID:      validRunID,
+		ScheduleID: 2,
+	})
+	identity := "testIdentity"
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil, errors.New("FAILED")).Once()
+
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: taskToken,
+			Identity:  &identity,
+		},
+	})
+	s.EqualError(err, "FAILED")
+}
+
+func (s *engineSuite) TestRespondActivityTaskCompletedIfNoAIdProvided() {
+	domainID := validDomainID
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		ScheduleID: common.EmptyEventID,
+	})
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), validRunID)
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	gceResponse := &persistence.GetCurrentExecutionResponse{RunID: validRunID}
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: taskToken,
+			Identity:  &identity,
+		},
+	})
+	s.EqualError(err, "BadRequestError{Message: Neither ActivityID nor ScheduleID is provided}")
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedMaxAttemptsExceeded() {
+func (s *engineSuite) TestRespondActivityTaskCompletedIfNoAidFound() {
 	domainID := validDomainID
-	we := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("wId"),
-		RunId:      common.StringPtr(validRunID),
-	}
-	tl := "testTaskList"
 	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 2,
+		WorkflowID: "wId",
+		ScheduleID: common.EmptyEventID,
+		ActivityID: "aid",
 	})
 	identity := "testIdentity"
-	executionContext := []byte("context")
-	input := []byte("input")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
-	di := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-
-	decisions := []*workflow.Decision{{
-		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
-		ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
-			ActivityId:                    common.StringPtr("activity1"),
-			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
-			TaskList:                      &workflow.TaskList{Name: &tl},
-			Input:                         input,
-			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
-			ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
-			StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
-			HeartbeatTimeoutSeconds:       common.Int32Ptr(5),
-		},
-	}}
-
-	for i := 0; i < conditionalRetryCount; i++ {
-		ms := createMutableState(msBuilder)
-		gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-
-		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-		s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-		s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}},
-			&persistence.ConditionFailedError{}).Once()
-	}
+		loggerimpl.NewDevelopmentForTest(s.Suite), validRunID)
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	gceResponse := &persistence.GetCurrentExecutionResponse{RunID: validRunID}
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -846,79 +4755,50 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedMaxAttemptsExceeded() {
 		},
 		nil,
 	)
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: executionContext,
-			Identity:         &identity,
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: taskToken,
+			Identity:  &identity,
 		},
 	})
-	s.NotNil(err)
-	s.Equal(ErrMaxAttemptsExceeded, err)
+	s.EqualError(err, "BadRequestError{Message: No such activityID: aid\n}")
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedCompleteWorkflowFailed() {
+func (s *engineSuite) TestRespondActivityTaskCompletedUpdateExecutionFailed() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
 		RunId:      common.StringPtr(validRunID),
 	}
 	tl := "testTaskList"
-	identity := "testIdentity"
-	executionContext := []byte("context")
-	activity1ID := "activity1"
-	activity1Type := "activity_type1"
-	activity1Input := []byte("input1")
-	activity1Result := []byte("activity1_result")
-	activity2ID := "activity2"
-	activity2Type := "activity_type2"
-	activity2Input := []byte("input2")
-	activity2Result := []byte("activity2_result")
-	workflowResult := []byte("workflow result")
-
-	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 25, 200, identity)
-	di1 := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di1.ScheduleID, tl, identity)
-	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di1.ScheduleID,
-		*decisionStartedEvent1.EventId, nil, identity)
-	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId,
-		activity1ID, activity1Type, tl, activity1Input, 100, 10, 5)
-	activity2ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId,
-		activity2ID, activity2Type, tl, activity2Input, 100, 10, 5)
-	activity1StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
-	activity2StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity2ScheduledEvent.EventId, identity)
-	addActivityTaskCompletedEvent(msBuilder, *activity1ScheduledEvent.EventId,
-		*activity1StartedEvent.EventId, activity1Result, identity)
-	di2 := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di2.ScheduleID, tl, identity)
-	addActivityTaskCompletedEvent(msBuilder, *activity2ScheduledEvent.EventId,
-		*activity2StartedEvent.EventId, activity2Result, identity)
-
 	taskToken, _ := json.Marshal(&common.TaskToken{
 		WorkflowID: *we.WorkflowId,
 		RunID:      *we.RunId,
-		ScheduleID: di2.ScheduleID,
+		ScheduleID: 5,
 	})
+	identity := "testIdentity"
+	activityID := "activity1_id"
+	activityType := "activity_type1"
+	activityInput := []byte("input1")
+	activityResult := []byte("activity result")
 
-	decisions := []*workflow.Decision{{
-		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeCompleteWorkflowExecution),
-		CompleteWorkflowExecutionDecisionAttributes: &workflow.CompleteWorkflowExecutionDecisionAttributes{
-			Result: workflowResult,
-		},
-	}}
-
-	for i := 0; i < 2; i++ {
-		ms := createMutableState(msBuilder)
-		gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-	}
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
+		activityType, tl, activityInput, 100, 10, 5)
+	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
 
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -934,90 +4814,115 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedCompleteWorkflowFailed() {
 		},
 		nil,
 	)
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, errors.New("FAILED")).Once()
+	s.mockShardManager.On("UpdateShard", mock.Anything).Return(nil).Once()
+
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: executionContext,
-			Identity:         &identity,
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: taskToken,
+			Result:    activityResult,
+			Identity:  &identity,
 		},
 	})
-	s.Nil(err, s.printHistory(msBuilder))
-	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(15), executionBuilder.GetExecutionInfo().NextEventID)
-	s.Equal(*decisionStartedEvent1.EventId, executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
-	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
-	s.True(executionBuilder.HasPendingDecisionTask())
-	di3, ok := executionBuilder.GetPendingDecision(executionBuilder.GetExecutionInfo().NextEventID - 1)
-	s.True(ok)
-	s.Equal(executionBuilder.GetExecutionInfo().NextEventID-1, di3.ScheduleID)
-	s.Equal(int64(0), di3.Attempt)
+	s.EqualError(err, "FAILED")
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedFailWorkflowFailed() {
+func (s *engineSuite) TestRespondActivityTaskCompletedIfTaskCompleted() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
 		RunId:      common.StringPtr(validRunID),
 	}
 	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 5,
+	})
 	identity := "testIdentity"
-	executionContext := []byte("context")
-	activity1ID := "activity1"
-	activity1Type := "activity_type1"
-	activity1Input := []byte("input1")
-	activity1Result := []byte("activity1_result")
-	activity2ID := "activity2"
-	activity2Type := "activity_type2"
-	activity2Input := []byte("input2")
-	activity2Result := []byte("activity2_result")
-	reason := "workflow fail reason"
-	details := []byte("workflow fail details")
+	activityID := "activity1_id"
+	activityType := "activity_type1"
+	activityInput := []byte("input1")
+	activityResult := []byte("activity result")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 25, 200, identity)
-	di1 := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di1.ScheduleID, tl, identity)
-	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di1.ScheduleID,
-		*decisionStartedEvent1.EventId, nil, identity)
-	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity1ID,
-		activity1Type, tl, activity1Input, 100, 10, 5)
-	activity2ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity2ID,
-		activity2Type, tl, activity2Input, 100, 10, 5)
-	activity1StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
-	activity2StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity2ScheduledEvent.EventId, identity)
-	addActivityTaskCompletedEvent(msBuilder, *activity1ScheduledEvent.EventId,
-		*activity1StartedEvent.EventId, activity1Result, identity)
-	di2 := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di2.ScheduleID, tl, identity)
-	addActivityTaskCompletedEvent(msBuilder, *activity2ScheduledEvent.EventId,
-		*activity2StartedEvent.EventId, activity2Result, identity)
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
+		activityType, tl, activityInput, 100, 10, 5)
+	activityStartedEvent := addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
+	addActivityTaskCompletedEvent(msBuilder, *activityScheduledEvent.EventId, *activityStartedEvent.EventId,
+		activityResult, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
 
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: di2.ScheduleID,
-	})
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
-	decisions := []*workflow.Decision{{
-		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeFailWorkflowExecution),
-		FailWorkflowExecutionDecisionAttributes: &workflow.FailWorkflowExecutionDecisionAttributes{
-			Reason:  &reason,
-			Details: details,
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
 		},
-	}}
+		nil,
+	)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 
-	for i := 0; i < 2; i++ {
-		ms := createMutableState(msBuilder)
-		gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: taskToken,
+			Result:    activityResult,
+			Identity:  &identity,
+		},
+	})
+	s.NotNil(err)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
+}
+
+func (s *engineSuite) TestRespondActivityTaskCompletedIfTaskNotStarted() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
 	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 5,
+	})
+	identity := "testIdentity"
+	activityID := "activity1_id"
+	activityType := "activity_type1"
+	activityInput := []byte("input1")
+	activityResult := []byte("activity result")
 
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
+		activityType, tl, activityInput, 100, 10, 5)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -1033,77 +4938,68 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedFailWorkflowFailed() {
 		},
 		nil,
 	)
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: executionContext,
-			Identity:         &identity,
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: taskToken,
+			Result:    activityResult,
+			Identity:  &identity,
 		},
 	})
-	s.Nil(err, s.printHistory(msBuilder))
-	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(15), executionBuilder.GetExecutionInfo().NextEventID)
-	s.Equal(*decisionStartedEvent1.EventId, executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
-	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
-	s.True(executionBuilder.HasPendingDecisionTask())
-	di3, ok := executionBuilder.GetPendingDecision(executionBuilder.GetExecutionInfo().NextEventID - 1)
-	s.True(ok)
-	s.Equal(executionBuilder.GetExecutionInfo().NextEventID-1, di3.ScheduleID)
-	s.Equal(int64(0), di3.Attempt)
+	s.NotNil(err)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedBadDecisionAttributes() {
+func (s *engineSuite) TestRespondActivityTaskCompletedConflictOnUpdate() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
 		RunId:      common.StringPtr(validRunID),
 	}
 	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 5,
+	})
 	identity := "testIdentity"
-	executionContext := []byte("context")
 	activity1ID := "activity1"
 	activity1Type := "activity_type1"
 	activity1Input := []byte("input1")
 	activity1Result := []byte("activity1_result")
+	activity2ID := "activity2"
+	activity2Type := "activity_type2"
+	activity2Input := []byte("input2")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 25, 200, identity)
-	di1 := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di1.ScheduleID, tl, identity)
-	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di1.ScheduleID,
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
 		*decisionStartedEvent1.EventId, nil, identity)
 	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity1ID,
 		activity1Type, tl, activity1Input, 100, 10, 5)
-	activity1StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
-	addActivityTaskCompletedEvent(msBuilder, *activity1ScheduledEvent.EventId,
-		*activity1StartedEvent.EventId, activity1Result, identity)
-	di2 := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di2.ScheduleID, tl, identity)
+	activity2ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity2ID,
+		activity2Type, tl, activity2Input, 100, 10, 5)
+	addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
+	addActivityTaskStartedEvent(msBuilder, *activity2ScheduledEvent.EventId, identity)
 
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: di2.ScheduleID,
-	})
+	ms1 := createMutableState(msBuilder)
+	gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: ms1}
 
-	// Decision with nil attributes
-	decisions := []*workflow.Decision{{
-		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeCompleteWorkflowExecution),
-	}}
+	ms2 := createMutableState(msBuilder)
+	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: ms2}
 
-	gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
-	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
 	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse1, nil).Once()
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, &persistence.ConditionFailedError{}).Once()
 
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
 	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{
-		MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
-	).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -1119,168 +5015,31 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedBadDecisionAttributes() {
 		},
 		nil,
 	)
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: executionContext,
-			Identity:         &identity,
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: taskToken,
+			Result:    activity1Result,
+			Identity:  &identity,
 		},
 	})
-	s.Nil(err)
-}
-
-// This test unit tests the activity schedule timeout validation logic of HistoryEngine's RespondDecisionTaskComplete function.
-// An scheduled activity decision has 3 timeouts: ScheduleToClose, ScheduleToStart and StartToClose.
-// This test verifies that when either ScheduleToClose or ScheduleToStart and StartToClose are specified,
-// HistoryEngine's validateActivityScheduleAttribute will deduce the missing timeout and fill it in
-// instead of returning a BadRequest error and only when all three are missing should a BadRequest be returned.
-func (s *engineSuite) TestRespondDecisionTaskCompletedSingleActivityScheduledAttribute() {
-	workflowTimeout := int32(100)
-	testIterationVariables := []struct {
-		scheduleToClose         *int32
-		scheduleToStart         *int32
-		startToClose            *int32
-		heartbeat               *int32
-		expectedScheduleToClose int32
-		expectedScheduleToStart int32
-		expectedStartToClose    int32
-		expectDecisionFail      bool
-	}{
-		// No ScheduleToClose timeout, will use ScheduleToStart + StartToClose
-		{nil, common.Int32Ptr(3), common.Int32Ptr(7), nil,
-			3 + 7, 3, 7, false},
-		// Has ScheduleToClose timeout but not ScheduleToStart or StartToClose,
-		// will use ScheduleToClose for ScheduleToStart and StartToClose
-		{common.Int32Ptr(7), nil, nil, nil,
-			7, 7, 7, false},
-		// No ScheduleToClose timeout, ScheduleToStart or StartToClose, expect error return
-		{nil, nil, nil, nil,
-			0, 0, 0, true},
-		// Negative ScheduleToClose, expect error return
-		{common.Int32Ptr(-1), nil, nil, nil,
-			0, 0, 0, true},
-		// Negative ScheduleToStart, expect error return
-		{nil, common.Int32Ptr(-1), nil, nil,
-			0, 0, 0, true},
-		// Negative StartToClose, expect error return
-		{nil, nil, common.Int32Ptr(-1), nil,
-			0, 0, 0, true},
-		// Negative HeartBeat, expect error return
-		{nil, nil, nil, common.Int32Ptr(-1),
-			0, 0, 0, true},
-		// Use workflow timeout
-		{common.Int32Ptr(workflowTimeout), nil, nil, nil,
-			workflowTimeout, workflowTimeout, workflowTimeout, false},
-		// Timeout larger than workflow timeout
-		{common.Int32Ptr(workflowTimeout + 1), nil, nil, nil,
-			workflowTimeout, workflowTimeout, workflowTimeout, false},
-		{nil, common.Int32Ptr(workflowTimeout + 1), nil, nil,
-			0, 0, 0, true},
-		{nil, nil, common.Int32Ptr(workflowTimeout + 1), nil,
-			0, 0, 0, true},
-		{nil, nil, nil, common.Int32Ptr(workflowTimeout + 1),
-			0, 0, 0, true},
-		// No ScheduleToClose timeout, will use ScheduleToStart + StartToClose, but exceed limit
-		{nil, common.Int32Ptr(workflowTimeout), common.Int32Ptr(10), nil,
-			workflowTimeout, workflowTimeout, 10, false},
-	}
-
-	for _, iVar := range testIterationVariables {
-		domainID := validDomainID
-		we := workflow.WorkflowExecution{
-			WorkflowId: common.StringPtr("wId"),
-			RunId:      common.StringPtr(validRunID),
-		}
-		tl := "testTaskList"
-		taskToken, _ := json.Marshal(&common.TaskToken{
-			WorkflowID: "wId",
-			RunID:      we.GetRunId(),
-			ScheduleID: 2,
-		})
-		identity := "testIdentity"
-		executionContext := []byte("context")
-		input := []byte("input")
-
-		msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-			loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-		addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), workflowTimeout, 200, identity)
-		di := addDecisionTaskScheduledEvent(msBuilder)
-		addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-
-		decisions := []*workflow.Decision{{
-			DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
-			ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
-				ActivityId:                    common.StringPtr("activity1"),
-				ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
-				TaskList:                      &workflow.TaskList{Name: &tl},
-				Input:                         input,
-				ScheduleToCloseTimeoutSeconds: iVar.scheduleToClose,
-				ScheduleToStartTimeoutSeconds: iVar.scheduleToStart,
-				StartToCloseTimeoutSeconds:    iVar.startToClose,
-				HeartbeatTimeoutSeconds:       iVar.heartbeat,
-			},
-		}}
-
-		gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
-		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse1, nil).Once()
-		if iVar.expectDecisionFail {
-			gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
-			s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
-		}
-
-		s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-		s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
-
-		s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
-			&persistence.GetDomainResponse{
-				Info:   &persistence.DomainInfo{ID: domainID},
-				Config: &persistence.DomainConfig{Retention: 1},
-				ReplicationConfig: &persistence.DomainReplicationConfig{
-					ActiveClusterName: cluster.TestCurrentClusterName,
-					Clusters: []*persistence.ClusterReplicationConfig{
-						{ClusterName: cluster.TestCurrentClusterName},
-					},
-				},
-				TableVersion: persistence.DomainTableVersionV1,
-			},
-			nil,
-		)
-		_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
-			DomainUUID: common.StringPtr(domainID),
-			CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-				TaskToken:        taskToken,
-				Decisions:        decisions,
-				ExecutionContext: executionContext,
-				Identity:         &identity,
-			},
-		})
-
-		s.Nil(err, s.printHistory(msBuilder))
-		executionBuilder := s.getBuilder(domainID, we)
-		if !iVar.expectDecisionFail {
-			s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
-			s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
-			s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
-			s.False(executionBuilder.HasPendingDecisionTask())
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(11), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
 
-			activity1Attributes := s.getActivityScheduledEvent(executionBuilder, int64(5)).ActivityTaskScheduledEventAttributes
-			s.Equal(iVar.expectedScheduleToClose, activity1Attributes.GetScheduleToCloseTimeoutSeconds())
-			s.Equal(iVar.expectedScheduleToStart, activity1Attributes.GetScheduleToStartTimeoutSeconds())
-			s.Equal(iVar.expectedStartToClose, activity1Attributes.GetStartToCloseTimeoutSeconds())
-		} else {
-			s.Equal(int64(5), executionBuilder.GetExecutionInfo().NextEventID)
-			s.Equal(common.EmptyEventID, executionBuilder.GetExecutionInfo().LastProcessedEvent)
-			s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
-			s.True(executionBuilder.HasPendingDecisionTask())
-		}
-		s.TearDownTest()
-		s.SetupTest()
-	}
+	s.True(executionBuilder.HasPendingDecisionTask())
+	di, ok := executionBuilder.GetPendingDecision(int64(10))
+	s.True(ok)
+	s.Equal(int32(100), di.DecisionTimeout)
+	s.Equal(int64(10), di.ScheduleID)
+	s.Equal(common.EmptyEventID, di.StartedID)
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedBadBinary() {
+func (s *engineSuite) TestRespondActivityTaskCompletedMaxAttemptsExceeded() {
+	scope := tally.NewTestScope("test", nil)
+	s.mockHistoryEngine.metricsClient = metrics.NewClient(scope, metrics.History)
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
@@ -1288,40 +5047,40 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedBadBinary() {
 	}
 	tl := "testTaskList"
 	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		RunID:      we.GetRunId(),
-		ScheduleID: 2,
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 5,
 	})
 	identity := "testIdentity"
-	executionContext := []byte("context")
+	activityID := "activity1_id"
+	activityType := "activity_type1"
+	activityInput := []byte("input1")
+	activityResult := []byte("activity result")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
 	di := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-
-	var decisions []*workflow.Decision
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
+		activityType, tl, activityInput, 100, 10, 5)
+	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
 
-	gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
-	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+	for i := 0; i < conditionalRetryCount; i++ {
+		ms := createMutableState(msBuilder)
+		gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse1, nil).Once()
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+		s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+		s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, &persistence.ConditionFailedError{}).Once()
+	}
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
-			Info: &persistence.DomainInfo{ID: domainID},
-			Config: &persistence.DomainConfig{
-				Retention: 1,
-				BadBinaries: workflow.BadBinaries{
-					Binaries: map[string]*workflow.BadBinaryInfo{
-						"test-bad-binary": {},
-					},
-				},
-			},
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
 			ReplicationConfig: &persistence.DomainReplicationConfig{
 				ActiveClusterName: cluster.TestCurrentClusterName,
 				Clusters: []*persistence.ClusterReplicationConfig{
@@ -1332,26 +5091,23 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedBadBinary() {
 		},
 		nil,
 	)
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: executionContext,
-			Identity:         &identity,
-			BinaryChecksum:   common.StringPtr("test-bad-binary"),
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: taskToken,
+			Result:    activityResult,
+			Identity:  &identity,
 		},
 	})
-	s.Nil(err, s.printHistory(msBuilder))
-	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(5), executionBuilder.GetExecutionInfo().NextEventID)
-	s.Equal(common.EmptyEventID, executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
-	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
-	s.True(executionBuilder.HasPendingDecisionTask())
+	s.Equal(ErrMaxAttemptsExceeded, err)
+
+	counters := scope.Snapshot().Counters()
+	tags := "+operation=RespondActivityTaskCompleted,update_failure_cause="
+	s.Equal(int64(conditionalRetryCount), counters["test.conditional_update_failure"+tags+updateFailureCauseConflict].Value())
+	s.Equal(int64(1), counters["test.conditional_update_failure"+tags+updateFailureCauseMaxAttempts].Value())
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedSingleActivityScheduledDecision() {
+func (s *engineSuite) TestRespondActivityTaskCompletedSuccess() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
@@ -1359,33 +5115,26 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedSingleActivityScheduledDec
 	}
 	tl := "testTaskList"
 	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		RunID:      we.GetRunId(),
-		ScheduleID: 2,
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 5,
 	})
 	identity := "testIdentity"
-	executionContext := []byte("context")
-	input := []byte("input")
+	activityID := "activity1_id"
+	activityType := "activity_type1"
+	activityInput := []byte("input1")
+	activityResult := []byte("activity result")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
 	di := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-
-	decisions := []*workflow.Decision{{
-		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeScheduleActivityTask),
-		ScheduleActivityTaskDecisionAttributes: &workflow.ScheduleActivityTaskDecisionAttributes{
-			ActivityId:                    common.StringPtr("activity1"),
-			ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
-			TaskList:                      &workflow.TaskList{Name: &tl},
-			Input:                         input,
-			ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
-			ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
-			StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
-			HeartbeatTimeoutSeconds:       common.Int32Ptr(5),
-		},
-	}}
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
+		activityType, tl, activityInput, 100, 10, 5)
+	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
 
 	ms := createMutableState(msBuilder)
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
@@ -1408,36 +5157,31 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedSingleActivityScheduledDec
 		},
 		nil,
 	)
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: executionContext,
-			Identity:         &identity,
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: taskToken,
+			Result:    activityResult,
+			Identity:  &identity,
 		},
 	})
 	s.Nil(err, s.printHistory(msBuilder))
 	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(int64(9), executionBuilder.GetExecutionInfo().NextEventID)
 	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
 	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
-	s.False(executionBuilder.HasPendingDecisionTask())
 
-	activity1Attributes := s.getActivityScheduledEvent(executionBuilder, int64(5)).ActivityTaskScheduledEventAttributes
-	s.Equal("activity1", *activity1Attributes.ActivityId)
-	s.Equal("activity_type1", *activity1Attributes.ActivityType.Name)
-	s.Equal(int64(4), *activity1Attributes.DecisionTaskCompletedEventId)
-	s.Equal(tl, *activity1Attributes.TaskList.Name)
-	s.Equal(input, activity1Attributes.Input)
-	s.Equal(int32(100), *activity1Attributes.ScheduleToCloseTimeoutSeconds)
-	s.Equal(int32(10), *activity1Attributes.ScheduleToStartTimeoutSeconds)
-	s.Equal(int32(50), *activity1Attributes.StartToCloseTimeoutSeconds)
-	s.Equal(int32(5), *activity1Attributes.HeartbeatTimeoutSeconds)
+	s.True(executionBuilder.HasPendingDecisionTask())
+	di, ok := executionBuilder.GetPendingDecision(int64(8))
+	s.True(ok)
+	s.Equal(int32(100), di.DecisionTimeout)
+	s.Equal(int64(8), di.ScheduleID)
+	s.Equal(common.EmptyEventID, di.StartedID)
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedCompleteWorkflowSuccess() {
+func (s *engineSuite) TestRespondActivityTaskCompletedActivityTypeMetrics() {
+	scope := tally.NewTestScope("test", nil)
+	s.mockHistoryEngine.metricsClient = metrics.NewClient(scope, metrics.History)
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
@@ -1447,24 +5191,21 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedCompleteWorkflowSuccess()
 	taskToken, _ := json.Marshal(&common.TaskToken{
 		WorkflowID: *we.WorkflowId,
 		RunID:      *we.RunId,
-		ScheduleID: 2,
+		ScheduleID: 5,
 	})
 	identity := "testIdentity"
-	executionContext := []byte("context")
-	workflowResult := []byte("success")
+	activityType := "activity_type1"
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
 	di := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-
-	decisions := []*workflow.Decision{{
-		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeCompleteWorkflowExecution),
-		CompleteWorkflowExecutionDecisionAttributes: &workflow.CompleteWorkflowExecutionDecisionAttributes{
-			Result: workflowResult,
-		},
-	}}
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, "activity1_id",
+		activityType, tl, []byte("input1"), 100, 10, 5)
+	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
 
 	ms := createMutableState(msBuilder)
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
@@ -1472,13 +5213,11 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedCompleteWorkflowSuccess()
 	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
 	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
-			Info: &persistence.DomainInfo{ID: domainID},
-			Config: &persistence.DomainConfig{
-				Retention:      1,
-				ArchivalStatus: workflow.ArchivalStatusEnabled,
-			},
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
+			Config: &persistence.DomainConfig{Retention: 1},
 			ReplicationConfig: &persistence.DomainReplicationConfig{
 				ActiveClusterName: cluster.TestCurrentClusterName,
 				Clusters: []*persistence.ClusterReplicationConfig{
@@ -1489,340 +5228,372 @@ func (s *engineSuite) TestRespondDecisionTaskCompletedCompleteWorkflowSuccess()
 		},
 		nil,
 	)
-
-	s.mockClusterMetadata.On("IsArchivalEnabled").Return(true)
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: executionContext,
-			Identity:         &identity,
+		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+			TaskToken: taskToken,
+			Result:    []byte("activity result"),
+			Identity:  &identity,
 		},
 	})
 	s.Nil(err, s.printHistory(msBuilder))
-	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
-	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
-	s.Equal(persistence.WorkflowStateCompleted, executionBuilder.GetExecutionInfo().State)
-	s.False(executionBuilder.HasPendingDecisionTask())
+
+	tags := "+activity_type=" + activityType + ",domain=testDomain,operation=RespondActivityTaskCompleted"
+	counters := scope.Snapshot().Counters()
+	s.Equal(int64(1), counters["test.activity_type_completed"+tags].Value())
+	s.Nil(counters["test.activity_type_failed"+tags])
+}
+
+func (s *engineSuite) TestGetWorkflowExecutionResultRunning() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:              &persistence.DomainInfo{ID: domainID},
+			Config:            &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{ActiveClusterName: cluster.TestCurrentClusterName},
+			TableVersion:      persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	result, err := s.mockHistoryEngine.GetWorkflowExecutionResult(context.Background(), domainID, we)
+	s.Equal(ErrWorkflowNotCompleted, err)
+	s.Nil(result)
+}
+
+func (s *engineSuite) TestGetWorkflowExecutionResultCompleted() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+	workflowResult := []byte("workflow result")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	completionEvent := addCompleteWorkflowEvent(msBuilder, *decisionCompletedEvent.EventId, workflowResult)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:              &persistence.DomainInfo{ID: domainID},
+			Config:            &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{ActiveClusterName: cluster.TestCurrentClusterName},
+			TableVersion:      persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	result, err := s.mockHistoryEngine.GetWorkflowExecutionResult(context.Background(), domainID, we)
+	s.Nil(err)
+	s.Equal(workflow.WorkflowExecutionCloseStatusCompleted, result.CloseStatus)
+	s.Equal(completionEvent.GetTimestamp(), result.CloseTime)
+	s.Equal(workflowResult, result.Result)
+	s.Nil(result.Reason)
+	s.Nil(result.Details)
+
+	s.mockHistoryEngine.config.BlobSizeLimitError = dynamicconfig.GetIntPropertyFilteredByDomain(len(workflowResult) - 1)
+	result, err = s.mockHistoryEngine.GetWorkflowExecutionResult(context.Background(), domainID, we)
+	s.Equal(ErrWorkflowResultExceedsSizeLimit, err)
+	s.Nil(result)
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedFailWorkflowSuccess() {
+func (s *engineSuite) TestDescribeWorkflowExecutionClosed() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
 		RunId:      common.StringPtr(validRunID),
 	}
 	tl := "testTaskList"
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 2,
-	})
 	identity := "testIdentity"
-	executionContext := []byte("context")
-	details := []byte("fail workflow details")
-	reason := "fail workflow reason"
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
 	di := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-
-	decisions := []*workflow.Decision{{
-		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeFailWorkflowExecution),
-		FailWorkflowExecutionDecisionAttributes: &workflow.FailWorkflowExecutionDecisionAttributes{
-			Reason:  &reason,
-			Details: details,
-		},
-	}}
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	completionEvent := addCompleteWorkflowEvent(msBuilder, *decisionCompletedEvent.EventId, []byte("workflow result"))
+	s.Equal(completionEvent.GetTimestamp(), msBuilder.GetExecutionInfo().CloseTimestamp)
 
 	ms := createMutableState(msBuilder)
+	// make the cached close timestamp distinguishable from the one of the completion event
+	cachedCloseTimestamp := completionEvent.GetTimestamp() + 1
+	ms.ExecutionInfo.CloseTimestamp = cachedCloseTimestamp
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-
 	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
-			Info: &persistence.DomainInfo{ID: domainID},
-			Config: &persistence.DomainConfig{
-				Retention:      1,
-				ArchivalStatus: workflow.ArchivalStatusEnabled,
-			},
-			ReplicationConfig: &persistence.DomainReplicationConfig{
-				ActiveClusterName: cluster.TestCurrentClusterName,
-				Clusters: []*persistence.ClusterReplicationConfig{
-					{ClusterName: cluster.TestCurrentClusterName},
-				},
-			},
-			TableVersion: persistence.DomainTableVersionV1,
+			Info:              &persistence.DomainInfo{ID: domainID},
+			Config:            &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{ActiveClusterName: cluster.TestCurrentClusterName},
+			TableVersion:      persistence.DomainTableVersionV1,
 		},
 		nil,
 	)
-	s.mockClusterMetadata.On("IsArchivalEnabled").Return(true)
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+
+	request := &history.DescribeWorkflowExecutionRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: executionContext,
-			Identity:         &identity,
-		},
-	})
-	s.Nil(err, s.printHistory(msBuilder))
-	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
-	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
-	s.Equal(persistence.WorkflowStateCompleted, executionBuilder.GetExecutionInfo().State)
-	s.False(executionBuilder.HasPendingDecisionTask())
+		Request:    &workflow.DescribeWorkflowExecutionRequest{Execution: &we},
+	}
+	resp, err := s.mockHistoryEngine.DescribeWorkflowExecution(context.Background(), request)
+	s.Nil(err)
+	s.Equal(workflow.WorkflowExecutionCloseStatusCompleted, resp.WorkflowExecutionInfo.GetCloseStatus())
+	s.Equal(cachedCloseTimestamp, resp.WorkflowExecutionInfo.GetCloseTime())
+
+	loadCompletionEvent := s.mockHistoryEngine.config.DescribeWorkflowLoadCompletionEvent
+	defer func() { s.mockHistoryEngine.config.DescribeWorkflowLoadCompletionEvent = loadCompletionEvent }()
+	s.mockHistoryEngine.config.DescribeWorkflowLoadCompletionEvent = dynamicconfig.GetBoolPropertyFnFilteredByDomain(true)
+
+	resp, err = s.mockHistoryEngine.DescribeWorkflowExecution(context.Background(), request)
+	s.Nil(err)
+	s.Equal(completionEvent.GetTimestamp(), resp.WorkflowExecutionInfo.GetCloseTime())
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedSignalExternalWorkflowSuccess() {
+func (s *engineSuite) TestDescribeWorkflowExecutionClosedWithoutCloseTimestamp() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
 		RunId:      common.StringPtr(validRunID),
 	}
 	tl := "testTaskList"
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 2,
-	})
 	identity := "testIdentity"
-	executionContext := []byte("context")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
 	di := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-
-	decisions := []*workflow.Decision{{
-		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeSignalExternalWorkflowExecution),
-		SignalExternalWorkflowExecutionDecisionAttributes: &workflow.SignalExternalWorkflowExecutionDecisionAttributes{
-			Domain: common.StringPtr(domainID),
-			Execution: &workflow.WorkflowExecution{
-				WorkflowId: we.WorkflowId,
-				RunId:      we.RunId,
-			},
-			SignalName: common.StringPtr("signal"),
-			Input:      []byte("test input"),
-		},
-	}}
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	completionEvent := addCompleteWorkflowEvent(msBuilder, *decisionCompletedEvent.EventId, []byte("workflow result"))
 
 	ms := createMutableState(msBuilder)
+	// workflow closed before the close timestamp was cached in mutable state
+	ms.ExecutionInfo.CloseTimestamp = 0
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-
 	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
-	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
-		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
-			Config: &persistence.DomainConfig{Retention: 1},
-			ReplicationConfig: &persistence.DomainReplicationConfig{
-				ActiveClusterName: cluster.TestCurrentClusterName,
-				Clusters: []*persistence.ClusterReplicationConfig{
-					{ClusterName: cluster.TestCurrentClusterName},
-				},
-			},
-			TableVersion: persistence.DomainTableVersionV1,
-		},
-		nil,
-	)
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+
+	resp, err := s.mockHistoryEngine.DescribeWorkflowExecution(context.Background(), &history.DescribeWorkflowExecutionRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: executionContext,
-			Identity:         &identity,
-		},
+		Request:    &workflow.DescribeWorkflowExecutionRequest{Execution: &we},
 	})
-	s.Nil(err, s.printHistory(msBuilder))
-	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(6), executionBuilder.GetExecutionInfo().NextEventID)
-	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(executionContext, executionBuilder.GetExecutionInfo().ExecutionContext)
+	s.Nil(err)
+	s.Equal(completionEvent.GetTimestamp(), resp.WorkflowExecutionInfo.GetCloseTime())
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedSignalExternalWorkflowFailed() {
+func (s *engineSuite) TestDescribeWorkflowExecutionPendingActivityRetryBackoff() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
-		RunId:      common.StringPtr("invalid run id"),
+		RunId:      common.StringPtr(validRunID),
 	}
 	tl := "testTaskList"
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: we.GetWorkflowId(),
-		RunID:      we.GetRunId(),
-		ScheduleID: 2,
-	})
 	identity := "testIdentity"
-	executionContext := []byte("context")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
 	di := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	_, retryingInfo := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, "activity1",
+		"activity_type1", tl, []byte("input1"), 100, 10, 5)
+	_, neverStartedInfo := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, "activity2",
+		"activity_type1", tl, []byte("input2"), 100, 10, 5)
+
+	nextRetryTime := time.Now().Add(time.Minute)
+	retryingInfo.HasRetryPolicy = true
+	retryingInfo.Attempt = 2
+	retryingInfo.ScheduledTime = nextRetryTime
+	retryingInfo.LastFailureReason = "some random reason"
+	retryingInfo.LastHeartBeatUpdatedTime = time.Now().Add(-time.Minute)
+	retryingInfo.Details = []byte("progress")
+	neverStartedInfo.HasRetryPolicy = true
 
-	decisions := []*workflow.Decision{{
-		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeSignalExternalWorkflowExecution),
-		SignalExternalWorkflowExecutionDecisionAttributes: &workflow.SignalExternalWorkflowExecutionDecisionAttributes{
-			Domain: common.StringPtr(domainID),
-			Execution: &workflow.WorkflowExecution{
-				WorkflowId: we.WorkflowId,
-				RunId:      we.RunId,
-			},
-			SignalName: common.StringPtr("signal"),
-			Input:      []byte("test input"),
-		},
-	}}
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 
-	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
-		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
-			Config: &persistence.DomainConfig{Retention: 1},
-			ReplicationConfig: &persistence.DomainReplicationConfig{
-				ActiveClusterName: cluster.TestCurrentClusterName,
-				Clusters: []*persistence.ClusterReplicationConfig{
-					{ClusterName: cluster.TestCurrentClusterName},
-				},
-			},
-			TableVersion: persistence.DomainTableVersionV1,
-		},
-		nil,
-	)
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+	resp, err := s.mockHistoryEngine.DescribeWorkflowExecution(context.Background(), &history.DescribeWorkflowExecutionRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: executionContext,
-			Identity:         &identity,
-		},
+		Request:    &workflow.DescribeWorkflowExecutionRequest{Execution: &we},
 	})
-
-	s.EqualError(err, "BadRequestError{Message: RunID is not valid UUID.}")
+	s.Nil(err)
+	s.Equal(2, len(resp.PendingActivities))
+	for _, pi := range resp.PendingActivities {
+		s.Equal(workflow.PendingActivityStateScheduled, pi.GetState())
+		switch pi.GetActivityID() {
+		case "activity1":
+			s.True(pi.GetRetryBackoff())
+			s.Equal(int32(2), pi.GetAttempt())
+			s.Equal("some random reason", pi.GetLastFailureReason())
+			s.Equal(nextRetryTime.UnixNano(), pi.GetNextRetryTimestamp())
+			s.Equal([]byte("progress"), pi.HeartbeatDetails)
+		case "activity2":
+			s.False(pi.GetRetryBackoff())
+			s.Equal(int32(0), pi.GetAttempt())
+			s.Nil(pi.LastFailureReason)
+			s.Nil(pi.NextRetryTimestamp)
+		default:
+			s.Fail("unexpected pending activity", pi.GetActivityID())
+		}
+	}
 }
 
-func (s *engineSuite) TestRespondDecisionTaskCompletedSignalExternalWorkflowFailed_UnKnownDomain() {
+func (s *engineSuite) TestExportMutableState() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
 		RunId:      common.StringPtr(validRunID),
 	}
 	tl := "testTaskList"
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 2,
-	})
 	identity := "testIdentity"
-	executionContext := []byte("context")
-	foreignDomain := "unknown domain"
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
-	di := addDecisionTaskScheduledEvent(msBuilder)
-	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-
-	decisions := []*workflow.Decision{{
-		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeSignalExternalWorkflowExecution),
-		SignalExternalWorkflowExecutionDecisionAttributes: &workflow.SignalExternalWorkflowExecutionDecisionAttributes{
-			Domain: common.StringPtr(foreignDomain),
-			Execution: &workflow.WorkflowExecution{
-				WorkflowId: we.WorkflowId,
-				RunId:      we.RunId,
-			},
-			SignalName: common.StringPtr("signal"),
-			Input:      []byte("test input"),
-		},
-	}}
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
 
 	ms := createMutableState(msBuilder)
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-
 	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-	s.mockMetadataMgr.On("GetDomain", &persistence.GetDomainRequest{ID: domainID}).Return(
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
-			Config: &persistence.DomainConfig{Retention: 1},
-			ReplicationConfig: &persistence.DomainReplicationConfig{
-				ActiveClusterName: cluster.TestCurrentClusterName,
-				Clusters: []*persistence.ClusterReplicationConfig{
-					{ClusterName: cluster.TestCurrentClusterName},
-				},
-			},
-			TableVersion: persistence.DomainTableVersionV1,
+			Info:              &persistence.DomainInfo{ID: domainID},
+			Config:            &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{ActiveClusterName: cluster.TestCurrentClusterName},
+			TableVersion:      persistence.DomainTableVersionV1,
 		},
 		nil,
-	).Once()
-	s.mockMetadataMgr.On("GetDomain", &persistence.GetDomainRequest{Name: foreignDomain}).Return(
-		nil, errors.New("get foreign domain error")).Once()
+	)
 
-	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: executionContext,
-			Identity:         &identity,
-		},
-	})
+	snapshot, err := s.mockHistoryEngine.ExportMutableState(context.Background(), domainID, we, true)
+	s.Nil(err)
+	s.True(snapshot.Compressed)
+	s.Equal(ms.ExecutionInfo.BranchToken, snapshot.BranchToken)
 
-	s.NotNil(err)
+	reader, err := gzip.NewReader(bytes.NewReader(snapshot.MutableState))
+	s.Nil(err)
+	data, err := ioutil.ReadAll(reader)
+	s.Nil(err)
+	exported := &persistence.WorkflowMutableState{}
+	s.Nil(json.Unmarshal(data, exported))
+	s.Equal(we.GetWorkflowId(), exported.ExecutionInfo.WorkflowID)
+	s.Equal(ms.ExecutionInfo.NextEventID, exported.ExecutionInfo.NextEventID)
 }
 
-func (s *engineSuite) TestRespondActivityTaskCompletedInvalidToken() {
+func (s *engineSuite) TestExportMutableStateExceedsSizeLimit() {
 	domainID := validDomainID
-	invalidToken, _ := json.Marshal("bad token")
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
 	identity := "testIdentity"
 
-	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
-		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
-			Config: &persistence.DomainConfig{Retention: 1},
-			ReplicationConfig: &persistence.DomainReplicationConfig{
-				ActiveClusterName: cluster.TestCurrentClusterName,
-				Clusters: []*persistence.ClusterReplicationConfig{
-					{ClusterName: cluster.TestCurrentClusterName},
-				},
-			},
-			TableVersion: persistence.DomainTableVersionV1,
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:              &persistence.DomainInfo{ID: domainID},
+			Config:            &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{ActiveClusterName: cluster.TestCurrentClusterName},
+			TableVersion:      persistence.DomainTableVersionV1,
 		},
 		nil,
 	)
-	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
-			TaskToken: invalidToken,
-			Result:    nil,
-			Identity:  &identity,
-		},
-	})
 
-	s.NotNil(err)
-	s.IsType(&workflow.BadRequestError{}, err)
+	sizeLimit := s.mockHistoryEngine.config.MutableStateExportSizeLimit
+	defer func() { s.mockHistoryEngine.config.MutableStateExportSizeLimit = sizeLimit }()
+	s.mockHistoryEngine.config.MutableStateExportSizeLimit = dynamicconfig.GetIntPropertyFilteredByDomain(10)
+
+	snapshot, err := s.mockHistoryEngine.ExportMutableState(context.Background(), domainID, we, false)
+	s.Equal(ErrMutableStateExportExceedsSizeLimit, err)
+	s.Nil(snapshot)
 }
 
-func (s *engineSuite) TestRespondActivityTaskCompletedIfNoExecution() {
+func (s *engineSuite) TestForceCompleteActivityNotAdmin() {
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	err := s.mockHistoryEngine.ForceCompleteActivity(context.Background(), validDomainID, we, 5, []byte("result"))
+	s.Equal(ErrNoAdminPermission, err)
+
+	err = s.mockHistoryEngine.ForceCompleteActivity(NewAdminOperationContext(context.Background(), ""), validDomainID, we, 5, []byte("result"))
+	s.Equal(ErrNoAdminPermission, err)
+}
+
+func (s *engineSuite) TestForceCompleteActivity() {
 	domainID := validDomainID
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		RunID:      validRunID,
-		ScheduleID: 2,
-	})
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
 	identity := "testIdentity"
+	operator := "testOperator"
+	activityResult := []byte("activity result")
+	auditSink := newChannelAuditSink(2)
+	s.mockHistoryEngine.auditSink = auditSink
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, "activity1_id",
+		"activity_type1", tl, []byte("input1"), 100, 10, 5)
+	activity2ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, "activity2_id",
+		"activity_type2", tl, []byte("input2"), 100, 10, 5)
+	addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
+	// the rejected call and the conflicting update clear the cached mutable state, so the workflow is loaded 3 times
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Twice()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, &persistence.ConditionFailedError{}).Once()
+	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: createMutableState(msBuilder)}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
 			Info:   &persistence.DomainInfo{ID: domainID},
@@ -1837,27 +5608,56 @@ func (s *engineSuite) TestRespondActivityTaskCompletedIfNoExecution() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil, &workflow.EntityNotExistsError{}).Once()
 
-	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
-			TaskToken: taskToken,
-			Identity:  &identity,
-		},
-	})
-	s.NotNil(err)
-	s.IsType(&workflow.EntityNotExistsError{}, err)
+	adminCtx := NewAdminOperationContext(context.Background(), operator)
+	err := s.mockHistoryEngine.ForceCompleteActivity(adminCtx, domainID, we, *activity2ScheduledEvent.EventId, activityResult)
+	s.Equal(ErrActivityTaskNotStarted, err)
+
+	err = s.mockHistoryEngine.ForceCompleteActivity(adminCtx, domainID, we, *activity1ScheduledEvent.EventId, activityResult)
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(10), executionBuilder.GetExecutionInfo().NextEventID)
+	_, ok := executionBuilder.GetActivityInfo(*activity1ScheduledEvent.EventId)
+	s.False(ok)
+	s.True(executionBuilder.HasPendingDecisionTask())
+	di, ok = executionBuilder.GetPendingDecision(int64(9))
+	s.True(ok)
+	s.Equal(common.EmptyEventID, di.StartedID)
+	// the update was retried after the conflict, the operation is audited once
+	entry := <-auditSink.entries
+	s.Equal(AuditOperationForceCompleteActivity, entry.Operation)
+	s.Equal(domainID, entry.DomainID)
+	s.Equal(we.GetWorkflowId(), entry.WorkflowID)
+	s.Equal(operator, entry.Operator)
+	s.Empty(auditSink.entries)
 }
 
-func (s *engineSuite) TestRespondActivityTaskCompletedIfNoRunID() {
+func (s *engineSuite) TestExtendWorkflowTimeout() {
 	domainID := validDomainID
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		ScheduleID: 2,
-	})
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
 	identity := "testIdentity"
+	s.mockHistoryEngine.config.MaxWorkflowExecutionTimeout = dynamicconfig.GetDurationPropertyFnFilteredByDomain(150 * time.Second)
+	auditSink := newChannelAuditSink(2)
+	s.mockHistoryEngine.auditSink = auditSink
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
+	var appendedEvents []*workflow.HistoryEvent
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Run(func(args mock.Arguments) {
+		appendedEvents = args.Get(0).(*p.AppendHistoryNodesRequest).Events
+	}).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
 			Info:   &persistence.DomainInfo{ID: domainID},
@@ -1872,28 +5672,60 @@ func (s *engineSuite) TestRespondActivityTaskCompletedIfNoRunID() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(nil, &workflow.EntityNotExistsError{}).Once()
 
-	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
-			TaskToken: taskToken,
-			Identity:  &identity,
-		},
-	})
-	s.NotNil(err)
-	s.IsType(&workflow.EntityNotExistsError{}, err)
+	err := s.mockHistoryEngine.ExtendWorkflowTimeout(context.Background(), domainID, we, 100)
+	s.Equal(ErrNoAdminPermission, err)
+
+	adminCtx := NewAdminOperationContext(context.Background(), "testOperator")
+	err = s.mockHistoryEngine.ExtendWorkflowTimeout(adminCtx, domainID, we, 0)
+	s.IsType(&workflow.BadRequestError{}, err)
+
+	err = s.mockHistoryEngine.ExtendWorkflowTimeout(adminCtx, domainID, we, 100)
+	s.Nil(err)
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int32(150), executionBuilder.GetExecutionInfo().WorkflowTimeout)
+	s.Equal(1, len(appendedEvents))
+	s.Equal(workflow.EventTypeWorkflowExecutionTimeoutExtended, appendedEvents[0].GetEventType())
+	attributes := appendedEvents[0].WorkflowExecutionTimeoutExtendedEventAttributes
+	s.Equal(int32(150), attributes.GetExecutionStartToCloseTimeoutSeconds())
+	s.Equal("testOperator", attributes.GetIdentity())
+	entry := <-auditSink.entries
+	s.Equal(AuditOperationExtendWorkflowTimeout, entry.Operation)
+	s.Equal(domainID, entry.DomainID)
+	s.Equal(we.GetWorkflowId(), entry.WorkflowID)
+	s.Equal(we.GetRunId(), entry.RunID)
+	s.Equal("testOperator", entry.Operator)
+
+	// already at the max, no update is made
+	err = s.mockHistoryEngine.ExtendWorkflowTimeout(adminCtx, domainID, we, 100)
+	s.Nil(err)
+	s.Equal(int32(150), executionBuilder.GetExecutionInfo().WorkflowTimeout)
+	s.Empty(auditSink.entries)
 }
 
-func (s *engineSuite) TestRespondActivityTaskCompletedIfGetExecutionFailed() {
+func (s *engineSuite) TestExtendWorkflowTimeoutCappedByExpirationTime() {
 	domainID := validDomainID
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		RunID:      validRunID,
-		ScheduleID: 2,
-	})
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
 	identity := "testIdentity"
+	s.mockHistoryEngine.config.MaxWorkflowExecutionTimeout = dynamicconfig.GetDurationPropertyFnFilteredByDomain(150 * time.Second)
+	auditSink := newChannelAuditSink(1)
+	s.mockHistoryEngine.auditSink = auditSink
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
+	executionInfo := msBuilder.GetExecutionInfo()
+	executionInfo.ExpirationTime = executionInfo.StartTimestamp.Add(50 * time.Second)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
 			Info:   &persistence.DomainInfo{ID: domainID},
@@ -1908,32 +5740,28 @@ func (s *engineSuite) TestRespondActivityTaskCompletedIfGetExecutionFailed() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil, errors.New("FAILED")).Once()
 
-	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
-			TaskToken: taskToken,
-			Identity:  &identity,
-		},
-	})
-	s.EqualError(err, "FAILED")
+	err := s.mockHistoryEngine.ExtendWorkflowTimeout(NewAdminOperationContext(context.Background(), "testOperator"), domainID, we, 100)
+	s.IsType(&workflow.BadRequestError{}, err)
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int32(100), executionBuilder.GetExecutionInfo().WorkflowTimeout)
+	s.Empty(auditSink.entries)
 }
 
-func (s *engineSuite) TestRespondActivityTaskCompletedIfNoAIdProvided() {
+func (s *engineSuite) TestExtendWorkflowTimeoutCompleted() {
 	domainID := validDomainID
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		ScheduleID: common.EmptyEventID,
-	})
-	identity := "testIdentity"
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), validRunID)
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
 	ms := createMutableState(msBuilder)
+	ms.ExecutionInfo.State = persistence.WorkflowStateCompleted
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-	gceResponse := &persistence.GetCurrentExecutionResponse{RunID: validRunID}
 
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
 			Info:   &persistence.DomainInfo{ID: domainID},
@@ -1948,33 +5776,52 @@ func (s *engineSuite) TestRespondActivityTaskCompletedIfNoAIdProvided() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 
-	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
-			TaskToken: taskToken,
-			Identity:  &identity,
-		},
-	})
-	s.EqualError(err, "BadRequestError{Message: Neither ActivityID nor ScheduleID is provided}")
+	err := s.mockHistoryEngine.ExtendWorkflowTimeout(NewAdminOperationContext(context.Background(), "testOperator"), domainID, we, 100)
+	s.Equal(ErrWorkflowCompleted, err)
 }
 
-func (s *engineSuite) TestRespondActivityTaskCompletedIfNoAidFound() {
+func (s *engineSuite) TestRespondActivityTaskCompletedResultExceedsLimit() {
 	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
 	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		ScheduleID: common.EmptyEventID,
-		ActivityID: "aid",
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 5,
 	})
 	identity := "testIdentity"
+	activityID := "activity1_id"
+	activityType := "activity_type1"
+	activityInput := []byte("input1")
+	activityResult := []byte("activity result exceeding the limit")
+	s.mockHistoryEngine.config.BlobSizeLimitWarn = dynamicconfig.GetIntPropertyFilteredByDomain(5)
+	s.mockHistoryEngine.config.BlobSizeLimitError = dynamicconfig.GetIntPropertyFilteredByDomain(10)
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), validRunID)
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
+		activityType, tl, activityInput, 100, 10, 5)
+	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
+
 	ms := createMutableState(msBuilder)
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-	gceResponse := &persistence.GetCurrentExecutionResponse{RunID: validRunID}
+
+	var appendedEvents []*workflow.HistoryEvent
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.MatchedBy(func(request *p.AppendHistoryNodesRequest) bool {
+		appendedEvents = request.Events
+		return true
+	})).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -1990,43 +5837,53 @@ func (s *engineSuite) TestRespondActivityTaskCompletedIfNoAidFound() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-
 	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
 		DomainUUID: common.StringPtr(domainID),
 		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
 			TaskToken: taskToken,
+			Result:    activityResult,
 			Identity:  &identity,
 		},
 	})
-	s.EqualError(err, "BadRequestError{Message: No such activityID: aid\n}")
+	s.Nil(err)
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(9), executionBuilder.GetExecutionInfo().NextEventID)
+	s.True(executionBuilder.HasPendingDecisionTask())
+	_, ok := executionBuilder.GetActivityInfo(int64(5))
+	s.False(ok)
+
+	s.Equal(2, len(appendedEvents))
+	s.Equal(workflow.EventTypeActivityTaskFailed, appendedEvents[0].GetEventType())
+	attributes := appendedEvents[0].ActivityTaskFailedEventAttributes
+	s.Equal(common.FailureReasonActivityResultSizeExceedsLimit, attributes.GetReason())
+	s.Equal(activityResult[0:10], attributes.Details)
 }
 
-func (s *engineSuite) TestRespondActivityTaskCompletedUpdateExecutionFailed() {
+func (s *engineSuite) TestRespondActivityTaskCompletedByIdSuccess() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
 		RunId:      common.StringPtr(validRunID),
 	}
 	tl := "testTaskList"
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 5,
-	})
+
 	identity := "testIdentity"
 	activityID := "activity1_id"
 	activityType := "activity_type1"
 	activityInput := []byte("input1")
 	activityResult := []byte("activity result")
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		ScheduleID: common.EmptyEventID,
+		ActivityID: activityID,
+	})
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
-	di := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	decisionScheduledEvent := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, decisionScheduledEvent.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, decisionScheduledEvent.ScheduleID,
 		*decisionStartedEvent.EventId, nil, identity)
 	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
 		activityType, tl, activityInput, 100, 10, 5)
@@ -2034,6 +5891,12 @@ func (s *engineSuite) TestRespondActivityTaskCompletedUpdateExecutionFailed() {
 
 	ms := createMutableState(msBuilder)
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	gceResponse := &persistence.GetCurrentExecutionResponse{RunID: *we.RunId}
+
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -2049,11 +5912,6 @@ func (s *engineSuite) TestRespondActivityTaskCompletedUpdateExecutionFailed() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, errors.New("FAILED")).Once()
-	s.mockShardManager.On("UpdateShard", mock.Anything).Return(nil).Once()
-
 	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
 		DomainUUID: common.StringPtr(domainID),
 		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
@@ -2062,43 +5920,68 @@ func (s *engineSuite) TestRespondActivityTaskCompletedUpdateExecutionFailed() {
 			Identity:  &identity,
 		},
 	})
-	s.EqualError(err, "FAILED")
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(9), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+
+	s.True(executionBuilder.HasPendingDecisionTask())
+	di, ok := executionBuilder.GetPendingDecision(int64(8))
+	s.True(ok)
+	s.Equal(int32(100), di.DecisionTimeout)
+	s.Equal(int64(8), di.ScheduleID)
+	s.Equal(common.EmptyEventID, di.StartedID)
 }
 
-func (s *engineSuite) TestRespondActivityTaskCompletedIfTaskCompleted() {
+func (s *engineSuite) TestRespondActivityTaskCompletedByIdReusedActivityID() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("wId"),
-		RunId:      common.StringPtr(validRunID),
-	}
-	tl := "testTaskList"
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 5,
-	})
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+
 	identity := "testIdentity"
 	activityID := "activity1_id"
 	activityType := "activity_type1"
 	activityInput := []byte("input1")
 	activityResult := []byte("activity result")
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		ScheduleID: common.EmptyEventID,
+		ActivityID: activityID,
+	})
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
-	di := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
-		*decisionStartedEvent.EventId, nil, identity)
-	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	di1 := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di1.ScheduleID, tl, identity)
+	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di1.ScheduleID,
+		*decisionStartedEvent1.EventId, nil, identity)
+	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activityID,
 		activityType, tl, activityInput, 100, 10, 5)
-	activityStartedEvent := addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
-	addActivityTaskCompletedEvent(msBuilder, *activityScheduledEvent.EventId, *activityStartedEvent.EventId,
+	activity1StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
+	addActivityTaskCompletedEvent(msBuilder, *activity1ScheduledEvent.EventId, *activity1StartedEvent.EventId,
 		activityResult, identity)
-	addDecisionTaskScheduledEvent(msBuilder)
+	di2 := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent2 := addDecisionTaskStartedEvent(msBuilder, di2.ScheduleID, tl, identity)
+	decisionCompletedEvent2 := addDecisionTaskCompletedEvent(msBuilder, di2.ScheduleID,
+		*decisionStartedEvent2.EventId, nil, identity)
+	// activity ID is reused after the first activity completed
+	activity2ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent2.EventId, activityID,
+		activityType, tl, activityInput, 100, 10, 5)
+	addActivityTaskStartedEvent(msBuilder, *activity2ScheduledEvent.EventId, identity)
 
 	ms := createMutableState(msBuilder)
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	gceResponse := &persistence.GetCurrentExecutionResponse{RunID: *we.RunId}
+
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -2114,8 +5997,6 @@ func (s *engineSuite) TestRespondActivityTaskCompletedIfTaskCompleted() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-
 	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
 		DomainUUID: common.StringPtr(domainID),
 		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
@@ -2124,41 +6005,51 @@ func (s *engineSuite) TestRespondActivityTaskCompletedIfTaskCompleted() {
 			Identity:  &identity,
 		},
 	})
-	s.NotNil(err)
-	s.IsType(&workflow.EntityNotExistsError{}, err)
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	_, ok := executionBuilder.GetActivityInfo(*activity2ScheduledEvent.EventId)
+	s.False(ok)
+	s.Equal(0, len(executionBuilder.GetPendingActivityInfos()))
+	s.True(executionBuilder.HasPendingDecisionTask())
 }
 
-func (s *engineSuite) TestRespondActivityTaskCompletedIfTaskNotStarted() {
+func (s *engineSuite) TestRespondActivityTaskCompletedByIdMultiplePendingActivities() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
 		RunId:      common.StringPtr(validRunID),
 	}
 	tl := "testTaskList"
+
+	identity := "testIdentity"
+	activityID := "activity1_id"
 	taskToken, _ := json.Marshal(&common.TaskToken{
 		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 5,
+		ScheduleID: common.EmptyEventID,
+		ActivityID: activityID,
 	})
-	identity := "testIdentity"
-	activityID := "activity1_id"
-	activityType := "activity_type1"
-	activityInput := []byte("input1")
-	activityResult := []byte("activity result")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
-	di := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	decisionScheduledEvent := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, decisionScheduledEvent.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, decisionScheduledEvent.ScheduleID,
 		*decisionStartedEvent.EventId, nil, identity)
-	addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
-		activityType, tl, activityInput, 100, 10, 5)
+	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
+		"activity_type1", tl, []byte("input1"), 100, 10, 5)
+	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
 
 	ms := createMutableState(msBuilder)
+	// a second pending activity sharing the activity ID
+	duplicate := copyActivityInfo(ms.ActivityInfos[*activityScheduledEvent.EventId])
+	duplicate.ScheduleID = *activityScheduledEvent.EventId + 100
+	ms.ActivityInfos[duplicate.ScheduleID] = duplicate
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	gceResponse := &persistence.GetCurrentExecutionResponse{RunID: *we.RunId}
 
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
 			Info:   &persistence.DomainInfo{ID: domainID},
@@ -2173,68 +6064,22 @@ func (s *engineSuite) TestRespondActivityTaskCompletedIfTaskNotStarted() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-
 	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
 		DomainUUID: common.StringPtr(domainID),
 		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
 			TaskToken: taskToken,
-			Result:    activityResult,
+			Result:    []byte("activity result"),
 			Identity:  &identity,
 		},
 	})
-	s.NotNil(err)
-	s.IsType(&workflow.EntityNotExistsError{}, err)
+	s.IsType(&workflow.BadRequestError{}, err)
+	s.mockExecutionMgr.AssertNotCalled(s.T(), "UpdateWorkflowExecution", mock.Anything)
 }
 
-func (s *engineSuite) TestRespondActivityTaskCompletedConflictOnUpdate() {
+func (s *engineSuite) TestRespondActivityTaskFailedInvalidToken() {
 	domainID := validDomainID
-	we := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("wId"),
-		RunId:      common.StringPtr(validRunID),
-	}
-	tl := "testTaskList"
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 5,
-	})
+	invalidToken, _ := json.Marshal("bad token")
 	identity := "testIdentity"
-	activity1ID := "activity1"
-	activity1Type := "activity_type1"
-	activity1Input := []byte("input1")
-	activity1Result := []byte("activity1_result")
-	activity2ID := "activity2"
-	activity2Type := "activity_type2"
-	activity2Input := []byte("input2")
-
-	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
-	di := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
-		*decisionStartedEvent1.EventId, nil, identity)
-	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity1ID,
-		activity1Type, tl, activity1Input, 100, 10, 5)
-	activity2ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity2ID,
-		activity2Type, tl, activity2Input, 100, 10, 5)
-	addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
-	addActivityTaskStartedEvent(msBuilder, *activity2ScheduledEvent.EventId, identity)
-
-	ms1 := createMutableState(msBuilder)
-	gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: ms1}
-
-	ms2 := createMutableState(msBuilder)
-	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: ms2}
-
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse1, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, &persistence.ConditionFailedError{}).Once()
-
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -2250,65 +6095,26 @@ func (s *engineSuite) TestRespondActivityTaskCompletedConflictOnUpdate() {
 		},
 		nil,
 	)
-	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
+	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
-			TaskToken: taskToken,
-			Result:    activity1Result,
+		FailedRequest: &workflow.RespondActivityTaskFailedRequest{
+			TaskToken: invalidToken,
 			Identity:  &identity,
 		},
 	})
-	s.Nil(err, s.printHistory(msBuilder))
-	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(11), executionBuilder.GetExecutionInfo().NextEventID)
-	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
 
-	s.True(executionBuilder.HasPendingDecisionTask())
-	di, ok := executionBuilder.GetPendingDecision(int64(10))
-	s.True(ok)
-	s.Equal(int32(100), di.DecisionTimeout)
-	s.Equal(int64(10), di.ScheduleID)
-	s.Equal(common.EmptyEventID, di.StartedID)
+	s.NotNil(err)
+	s.IsType(&workflow.BadRequestError{}, err)
 }
 
-func (s *engineSuite) TestRespondActivityTaskCompletedMaxAttemptsExceeded() {
+func (s *engineSuite) TestRespondActivityTaskFailedIfNoExecution() {
 	domainID := validDomainID
-	we := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("wId"),
-		RunId:      common.StringPtr(validRunID),
-	}
-	tl := "testTaskList"
 	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 5,
+		WorkflowID: "wId",
+		RunID:      validRunID,
+		ScheduleID: 2,
 	})
 	identity := "testIdentity"
-	activityID := "activity1_id"
-	activityType := "activity_type1"
-	activityInput := []byte("input1")
-	activityResult := []byte("activity result")
-
-	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
-	di := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
-		*decisionStartedEvent.EventId, nil, identity)
-	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
-		activityType, tl, activityInput, 100, 10, 5)
-	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
-
-	for i := 0; i < conditionalRetryCount; i++ {
-		ms := createMutableState(msBuilder)
-		gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-
-		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-		s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-		s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, &persistence.ConditionFailedError{}).Once()
-	}
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -2324,52 +6130,27 @@ func (s *engineSuite) TestRespondActivityTaskCompletedMaxAttemptsExceeded() {
 		},
 		nil,
 	)
-	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil,
+		&workflow.EntityNotExistsError{}).Once()
+
+	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+		FailedRequest: &workflow.RespondActivityTaskFailedRequest{
 			TaskToken: taskToken,
-			Result:    activityResult,
 			Identity:  &identity,
 		},
 	})
-	s.Equal(ErrMaxAttemptsExceeded, err)
+	s.NotNil(err)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
 }
 
-func (s *engineSuite) TestRespondActivityTaskCompletedSuccess() {
+func (s *engineSuite) TestRespondActivityTaskFailedIfNoRunID() {
 	domainID := validDomainID
-	we := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("wId"),
-		RunId:      common.StringPtr(validRunID),
-	}
-	tl := "testTaskList"
 	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		RunID:      *we.RunId,
-		ScheduleID: 5,
+		WorkflowID: "wId",
+		ScheduleID: 2,
 	})
 	identity := "testIdentity"
-	activityID := "activity1_id"
-	activityType := "activity_type1"
-	activityInput := []byte("input1")
-	activityResult := []byte("activity result")
-
-	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
-	di := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
-	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
-		*decisionStartedEvent.EventId, nil, identity)
-	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
-		activityType, tl, activityInput, 100, 10, 5)
-	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
-
-	ms := createMutableState(msBuilder)
-	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -2385,66 +6166,28 @@ func (s *engineSuite) TestRespondActivityTaskCompletedSuccess() {
 		},
 		nil,
 	)
-	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(nil,
+		&workflow.EntityNotExistsError{}).Once()
+
+	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+		FailedRequest: &workflow.RespondActivityTaskFailedRequest{
 			TaskToken: taskToken,
-			Result:    activityResult,
 			Identity:  &identity,
 		},
 	})
-	s.Nil(err, s.printHistory(msBuilder))
-	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(9), executionBuilder.GetExecutionInfo().NextEventID)
-	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
-
-	s.True(executionBuilder.HasPendingDecisionTask())
-	di, ok := executionBuilder.GetPendingDecision(int64(8))
-	s.True(ok)
-	s.Equal(int32(100), di.DecisionTimeout)
-	s.Equal(int64(8), di.ScheduleID)
-	s.Equal(common.EmptyEventID, di.StartedID)
-}
-
-func (s *engineSuite) TestRespondActivityTaskCompletedByIdSuccess() {
-	domainID := validDomainID
-	we := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("wId"),
-		RunId:      common.StringPtr(validRunID),
-	}
-	tl := "testTaskList"
+	s.NotNil(err)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
+}
 
-	identity := "testIdentity"
-	activityID := "activity1_id"
-	activityType := "activity_type1"
-	activityInput := []byte("input1")
-	activityResult := []byte("activity result")
+func (s *engineSuite) TestRespondActivityTaskFailedIfGetExecutionFailed() {
+	domainID := validDomainID
 	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: *we.WorkflowId,
-		ScheduleID: common.EmptyEventID,
-		ActivityID: activityID,
+		WorkflowID: "wId",
+		RunID:      validRunID,
+		ScheduleID: 2,
 	})
-
-	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
-	decisionScheduledEvent := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, decisionScheduledEvent.ScheduleID, tl, identity)
-	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, decisionScheduledEvent.ScheduleID,
-		*decisionStartedEvent.EventId, nil, identity)
-	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
-		activityType, tl, activityInput, 100, 10, 5)
-	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
-
-	ms := createMutableState(msBuilder)
-	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-	gceResponse := &persistence.GetCurrentExecutionResponse{RunID: *we.RunId}
-
-	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	identity := "testIdentity"
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -2460,33 +6203,33 @@ func (s *engineSuite) TestRespondActivityTaskCompletedByIdSuccess() {
 		},
 		nil,
 	)
-	err := s.mockHistoryEngine.RespondActivityTaskCompleted(context.Background(), &history.RespondActivityTaskCompletedRequest{
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil,
+		errors.New("FAILED")).Once()
+
+	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondActivityTaskCompletedRequest{
+		FailedRequest: &workflow.RespondActivityTaskFailedRequest{
 			TaskToken: taskToken,
-			Result:    activityResult,
 			Identity:  &identity,
 		},
 	})
-	s.Nil(err, s.printHistory(msBuilder))
-	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(9), executionBuilder.GetExecutionInfo().NextEventID)
-	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
-
-	s.True(executionBuilder.HasPendingDecisionTask())
-	di, ok := executionBuilder.GetPendingDecision(int64(8))
-	s.True(ok)
-	s.Equal(int32(100), di.DecisionTimeout)
-	s.Equal(int64(8), di.ScheduleID)
-	s.Equal(common.EmptyEventID, di.StartedID)
+	s.EqualError(err, "FAILED")
 }
 
-func (s *engineSuite) TestRespondActivityTaskFailedInvalidToken() {
+func (s *engineSuite) TestRespondActivityTaskFailededIfNoAIdProvided() {
 	domainID := validDomainID
-	invalidToken, _ := json.Marshal("bad token")
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: "wId",
+		ScheduleID: common.EmptyEventID,
+	})
 	identity := "testIdentity"
 
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), validRunID)
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	gceResponse := &persistence.GetCurrentExecutionResponse{RunID: validRunID}
+
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
 			Info:   &persistence.DomainInfo{ID: domainID},
@@ -2501,27 +6244,34 @@ func (s *engineSuite) TestRespondActivityTaskFailedInvalidToken() {
 		},
 		nil,
 	)
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+
 	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
 		FailedRequest: &workflow.RespondActivityTaskFailedRequest{
-			TaskToken: invalidToken,
+			TaskToken: taskToken,
 			Identity:  &identity,
 		},
 	})
-
-	s.NotNil(err)
-	s.IsType(&workflow.BadRequestError{}, err)
+	s.EqualError(err, "BadRequestError{Message: Neither ActivityID nor ScheduleID is provided}")
 }
 
-func (s *engineSuite) TestRespondActivityTaskFailedIfNoExecution() {
+func (s *engineSuite) TestRespondActivityTaskFailededIfNoAIdFound() {
 	domainID := validDomainID
 	taskToken, _ := json.Marshal(&common.TaskToken{
 		WorkflowID: "wId",
-		RunID:      validRunID,
-		ScheduleID: 2,
+		ScheduleID: common.EmptyEventID,
+		ActivityID: "aid",
 	})
 	identity := "testIdentity"
 
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), validRunID)
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	gceResponse := &persistence.GetCurrentExecutionResponse{RunID: validRunID}
+
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
 			Info:   &persistence.DomainInfo{ID: domainID},
@@ -2536,8 +6286,8 @@ func (s *engineSuite) TestRespondActivityTaskFailedIfNoExecution() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil,
-		&workflow.EntityNotExistsError{}).Once()
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 
 	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
@@ -2546,17 +6296,44 @@ func (s *engineSuite) TestRespondActivityTaskFailedIfNoExecution() {
 			Identity:  &identity,
 		},
 	})
-	s.NotNil(err)
-	s.IsType(&workflow.EntityNotExistsError{}, err)
+	s.EqualError(err, "BadRequestError{Message: No such activityID: aid\n}")
 }
 
-func (s *engineSuite) TestRespondActivityTaskFailedIfNoRunID() {
+func (s *engineSuite) TestRespondActivityTaskFailedUpdateExecutionFailed() {
 	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
 	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		ScheduleID: 2,
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 5,
 	})
 	identity := "testIdentity"
+	activityID := "activity1_id"
+	activityType := "activity_type1"
+	activityInput := []byte("input1")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
+		activityType, tl, activityInput, 100, 10, 5)
+	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, errors.New("FAILED")).Once()
+	s.mockShardManager.On("UpdateShard", mock.Anything).Return(nil).Once()
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -2572,9 +6349,6 @@ func (s *engineSuite) TestRespondActivityTaskFailedIfNoRunID() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(nil,
-		&workflow.EntityNotExistsError{}).Once()
-
 	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
 		FailedRequest: &workflow.RespondActivityTaskFailedRequest{
@@ -2582,18 +6356,44 @@ func (s *engineSuite) TestRespondActivityTaskFailedIfNoRunID() {
 			Identity:  &identity,
 		},
 	})
-	s.NotNil(err)
-	s.IsType(&workflow.EntityNotExistsError{}, err)
+	s.EqualError(err, "FAILED")
 }
 
-func (s *engineSuite) TestRespondActivityTaskFailedIfGetExecutionFailed() {
+func (s *engineSuite) TestRespondActivityTaskFailedIfTaskCompleted() {
 	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
 	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		RunID:      validRunID,
-		ScheduleID: 2,
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 5,
 	})
 	identity := "testIdentity"
+	activityID := "activity1_id"
+	activityType := "activity_type1"
+	activityInput := []byte("input1")
+	failReason := "fail reason"
+	details := []byte("fail details")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
+		activityType, tl, activityInput, 100, 10, 5)
+	activityStartedEvent := addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
+	addActivityTaskFailedEvent(msBuilder, *activityScheduledEvent.EventId, *activityStartedEvent.EventId,
+		failReason, details, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -2609,32 +6409,50 @@ func (s *engineSuite) TestRespondActivityTaskFailedIfGetExecutionFailed() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(nil,
-		errors.New("FAILED")).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 
 	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
 		FailedRequest: &workflow.RespondActivityTaskFailedRequest{
 			TaskToken: taskToken,
+			Reason:    &failReason,
+			Details:   details,
 			Identity:  &identity,
 		},
 	})
-	s.EqualError(err, "FAILED")
+	s.NotNil(err)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
 }
 
-func (s *engineSuite) TestRespondActivityTaskFailededIfNoAIdProvided() {
+func (s *engineSuite) TestRespondActivityTaskFailedIfTaskNotStarted() {
 	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
 	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		ScheduleID: common.EmptyEventID,
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 5,
 	})
 	identity := "testIdentity"
+	activityID := "activity1_id"
+	activityType := "activity_type1"
+	activityInput := []byte("input1")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), validRunID)
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
+		activityType, tl, activityInput, 100, 10, 5)
+
 	ms := createMutableState(msBuilder)
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-	gceResponse := &persistence.GetCurrentExecutionResponse{RunID: validRunID}
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -2650,7 +6468,6 @@ func (s *engineSuite) TestRespondActivityTaskFailededIfNoAIdProvided() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
 	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 
 	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
@@ -2660,23 +6477,64 @@ func (s *engineSuite) TestRespondActivityTaskFailededIfNoAIdProvided() {
 			Identity:  &identity,
 		},
 	})
-	s.EqualError(err, "BadRequestError{Message: Neither ActivityID nor ScheduleID is provided}")
+	s.NotNil(err)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
 }
 
-func (s *engineSuite) TestRespondActivityTaskFailededIfNoAIdFound() {
-	domainID := validDomainID
-	taskToken, _ := json.Marshal(&common.TaskToken{
-		WorkflowID: "wId",
-		ScheduleID: common.EmptyEventID,
-		ActivityID: "aid",
-	})
-	identity := "testIdentity"
+func (s *engineSuite) TestRespondActivityTaskFailedConflictOnUpdate() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 5,
+	})
+	identity := "testIdentity"
+	activity1ID := "activity1"
+	activity1Type := "activity_type1"
+	activity1Input := []byte("input1")
+	failReason := "fail reason"
+	details := []byte("fail details.")
+	activity2ID := "activity2"
+	activity2Type := "activity_type2"
+	activity2Input := []byte("input2")
+	activity2Result := []byte("activity2_result")
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 25, 25, identity)
+	di1 := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di1.ScheduleID, tl, identity)
+	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di1.ScheduleID,
+		*decisionStartedEvent1.EventId, nil, identity)
+	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity1ID,
+		activity1Type, tl, activity1Input, 100, 10, 5)
+	activity2ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity2ID,
+		activity2Type, tl, activity2Input, 100, 10, 5)
+	addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
+	activity2StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity2ScheduledEvent.EventId, identity)
+
+	ms1 := createMutableState(msBuilder)
+	gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: ms1}
+
+	addActivityTaskCompletedEvent(msBuilder, *activity2ScheduledEvent.EventId,
+		*activity2StartedEvent.EventId, activity2Result, identity)
+	addDecisionTaskScheduledEvent(msBuilder)
+
+	ms2 := createMutableState(msBuilder)
+	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: ms2}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse1, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, &persistence.ConditionFailedError{}).Once()
 
-	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
-		loggerimpl.NewDevelopmentForTest(s.Suite), validRunID)
-	ms := createMutableState(msBuilder)
-	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-	gceResponse := &persistence.GetCurrentExecutionResponse{RunID: validRunID}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -2692,20 +6550,30 @@ func (s *engineSuite) TestRespondActivityTaskFailededIfNoAIdFound() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything).Return(gceResponse, nil).Once()
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-
 	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
 		FailedRequest: &workflow.RespondActivityTaskFailedRequest{
 			TaskToken: taskToken,
+			Reason:    &failReason,
+			Details:   details,
 			Identity:  &identity,
 		},
 	})
-	s.EqualError(err, "BadRequestError{Message: No such activityID: aid\n}")
+	s.Nil(err, s.printHistory(msBuilder))
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(12), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+
+	s.True(executionBuilder.HasPendingDecisionTask())
+	di, ok := executionBuilder.GetPendingDecision(int64(10))
+	s.True(ok)
+	s.Equal(int32(25), di.DecisionTimeout)
+	s.Equal(int64(10), di.ScheduleID)
+	s.Equal(common.EmptyEventID, di.StartedID)
 }
 
-func (s *engineSuite) TestRespondActivityTaskFailedUpdateExecutionFailed() {
+func (s *engineSuite) TestRespondActivityTaskFailedMaxAttemptsExceeded() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
@@ -2733,13 +6601,14 @@ func (s *engineSuite) TestRespondActivityTaskFailedUpdateExecutionFailed() {
 		activityType, tl, activityInput, 100, 10, 5)
 	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
 
-	ms := createMutableState(msBuilder)
-	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	for i := 0; i < conditionalRetryCount; i++ {
+		ms := createMutableState(msBuilder)
+		gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, errors.New("FAILED")).Once()
-	s.mockShardManager.On("UpdateShard", mock.Anything).Return(nil).Once()
+		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+		s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+		s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, &persistence.ConditionFailedError{}).Once()
+	}
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
@@ -2762,10 +6631,10 @@ func (s *engineSuite) TestRespondActivityTaskFailedUpdateExecutionFailed() {
 			Identity:  &identity,
 		},
 	})
-	s.EqualError(err, "FAILED")
+	s.Equal(ErrMaxAttemptsExceeded, err)
 }
 
-func (s *engineSuite) TestRespondActivityTaskFailedIfTaskCompleted() {
+func (s *engineSuite) TestRespondActivityTaskFailedSuccess() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
@@ -2781,8 +6650,8 @@ func (s *engineSuite) TestRespondActivityTaskFailedIfTaskCompleted() {
 	activityID := "activity1_id"
 	activityType := "activity_type1"
 	activityInput := []byte("input1")
-	failReason := "fail reason"
-	details := []byte("fail details")
+	failReason := "failed"
+	failDetails := []byte("fail details.")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
@@ -2793,14 +6662,15 @@ func (s *engineSuite) TestRespondActivityTaskFailedIfTaskCompleted() {
 		*decisionStartedEvent.EventId, nil, identity)
 	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
 		activityType, tl, activityInput, 100, 10, 5)
-	activityStartedEvent := addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
-	addActivityTaskFailedEvent(msBuilder, *activityScheduledEvent.EventId, *activityStartedEvent.EventId,
-		failReason, details, identity)
-	addDecisionTaskScheduledEvent(msBuilder)
+	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
 
 	ms := createMutableState(msBuilder)
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
 			Info:   &persistence.DomainInfo{ID: domainID},
@@ -2815,25 +6685,48 @@ func (s *engineSuite) TestRespondActivityTaskFailedIfTaskCompleted() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-
 	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
 		FailedRequest: &workflow.RespondActivityTaskFailedRequest{
 			TaskToken: taskToken,
 			Reason:    &failReason,
-			Details:   details,
+			Details:   failDetails,
 			Identity:  &identity,
 		},
 	})
-	s.NotNil(err)
-	s.IsType(&workflow.EntityNotExistsError{}, err)
+	s.Nil(err)
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(9), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+
+	s.True(executionBuilder.HasPendingDecisionTask())
+	di, ok := executionBuilder.GetPendingDecision(int64(8))
+	s.True(ok)
+	s.Equal(int32(100), di.DecisionTimeout)
+	s.Equal(int64(8), di.ScheduleID)
+	s.Equal(common.EmptyEventID, di.StartedID)
 }
 
-func (s *engineSuite) TestRespondActivityTaskFailedIfTaskNotStarted() {
+func (s *engineSuite) TestRespondActivityTaskFailedRetryKeepsHeartbeatDetails() {
+	heartbeatDetails := []byte("heartbeat details")
+	s.Equal(heartbeatDetails, s.failAndRestartActivityWithHeartbeatDetails("wId-keep", heartbeatDetails))
+}
+
+func (s *engineSuite) TestRespondActivityTaskFailedRetryDropsHeartbeatDetails() {
+	// mutable state reads its config from the shard, which is shared by the whole suite
+	keepHeartbeatDetails := s.config.ActivityRetryKeepHeartbeatDetails
+	defer func() { s.config.ActivityRetryKeepHeartbeatDetails = keepHeartbeatDetails }()
+	s.config.ActivityRetryKeepHeartbeatDetails = dynamicconfig.GetBoolPropertyFnFilteredByDomain(false)
+	s.Empty(s.failAndRestartActivityWithHeartbeatDetails("wId-drop", []byte("heartbeat details")))
+}
+
+// failAndRestartActivityWithHeartbeatDetails fails a heartbeating activity with a retry policy, then starts
+// its next attempt and returns the heartbeat details handed to that attempt
+func (s *engineSuite) failAndRestartActivityWithHeartbeatDetails(workflowID string, heartbeatDetails []byte) []byte {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
-		WorkflowId: common.StringPtr("wId"),
+		WorkflowId: common.StringPtr(workflowID),
 		RunId:      common.StringPtr(validRunID),
 	}
 	tl := "testTaskList"
@@ -2843,9 +6736,6 @@ func (s *engineSuite) TestRespondActivityTaskFailedIfTaskNotStarted() {
 		ScheduleID: 5,
 	})
 	identity := "testIdentity"
-	activityID := "activity1_id"
-	activityType := "activity_type1"
-	activityInput := []byte("input1")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
@@ -2854,12 +6744,29 @@ func (s *engineSuite) TestRespondActivityTaskFailedIfTaskNotStarted() {
 	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
 	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
 		*decisionStartedEvent.EventId, nil, identity)
-	addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
-		activityType, tl, activityInput, 100, 10, 5)
+	activityScheduledEvent, ai, _ := msBuilder.AddActivityTaskScheduledEvent(*decisionCompletedEvent.EventId, &workflow.ScheduleActivityTaskDecisionAttributes{
+		ActivityId:                    common.StringPtr("activity1_id"),
+		ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
+		TaskList:                      &workflow.TaskList{Name: common.StringPtr(tl)},
+		Input:                         []byte("input1"),
+		ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
+		ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
+		StartToCloseTimeoutSeconds:    common.Int32Ptr(50),
+		HeartbeatTimeoutSeconds:       common.Int32Ptr(5),
+		RetryPolicy: &workflow.RetryPolicy{
+			InitialIntervalInSeconds: common.Int32Ptr(1),
+			BackoffCoefficient:       common.Float64Ptr(1),
+			MaximumAttempts:          common.Int32Ptr(3),
+		},
+	})
+	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
+	msBuilder.UpdateActivityProgress(ai, &workflow.RecordActivityTaskHeartbeatRequest{Details: heartbeatDetails})
 
 	ms := createMutableState(msBuilder)
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Twice()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
 			Info:   &persistence.DomainInfo{ID: domainID},
@@ -2874,20 +6781,128 @@ func (s *engineSuite) TestRespondActivityTaskFailedIfTaskNotStarted() {
 		},
 		nil,
 	)
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 
 	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
 		FailedRequest: &workflow.RespondActivityTaskFailedRequest{
 			TaskToken: taskToken,
+			Reason:    common.StringPtr("failed"),
+			Details:   []byte("fail details."),
 			Identity:  &identity,
 		},
 	})
-	s.NotNil(err)
-	s.IsType(&workflow.EntityNotExistsError{}, err)
+	s.Nil(err)
+
+	response, err := s.mockHistoryEngine.RecordActivityTaskStarted(context.Background(), &history.RecordActivityTaskStartedRequest{
+		DomainUUID:        common.StringPtr(domainID),
+		WorkflowExecution: &we,
+		ScheduleId:        common.Int64Ptr(*activityScheduledEvent.EventId),
+		TaskId:            common.Int64Ptr(100),
+		RequestId:         common.StringPtr("reqId"),
+		PollRequest: &workflow.PollForActivityTaskRequest{
+			TaskList: &workflow.TaskList{Name: common.StringPtr(tl)},
+			Identity: common.StringPtr(identity),
+		},
+	})
+	s.Nil(err)
+	s.Equal(int64(1), response.GetAttempt())
+	return response.HeartbeatDetails
 }
 
-func (s *engineSuite) TestRespondActivityTaskFailedConflictOnUpdate() {
+func (s *engineSuite) TestContinueAsNewInheritsSearchAttributesAndMemo() {
+	for _, initiator := range []workflow.ContinueAsNewInitiator{
+		workflow.ContinueAsNewInitiatorDecider,
+		workflow.ContinueAsNewInitiatorCronSchedule,
+		workflow.ContinueAsNewInitiatorRetryPolicy,
+	} {
+		executionInfo := s.continueAsNewWithSearchAttributesAndMemo(initiator, nil, nil)
+		s.Equal(map[string][]byte{"memoKey": []byte("previous memo")}, executionInfo.Memo, initiator.String())
+		s.Equal(map[string][]byte{"CustomKeywordField": []byte(`"previous"`)}, executionInfo.SearchAttributes, initiator.String())
+	}
+}
+
+func (s *engineSuite) TestContinueAsNewOverridesSearchAttributesAndMemo() {
+	memo := &workflow.Memo{Fields: map[string][]byte{"memoKey": []byte("new memo")}}
+	searchAttributes := &workflow.SearchAttributes{IndexedFields: map[string][]byte{"CustomKeywordField": []byte(`"new"`)}}
+	executionInfo := s.continueAsNewWithSearchAttributesAndMemo(workflow.ContinueAsNewInitiatorDecider, memo, searchAttributes)
+	s.Equal(memo.Fields, executionInfo.Memo)
+	s.Equal(searchAttributes.IndexedFields, executionInfo.SearchAttributes)
+}
+
+func (s *engineSuite) TestContinueAsNewDoesNotInheritSearchAttributesAndMemo() {
+	// mutable state reads its config from the shard, which is shared by the whole suite
+	inherit := s.config.ContinueAsNewInheritSearchAttributesAndMemo
+	defer func() { s.config.ContinueAsNewInheritSearchAttributesAndMemo = inherit }()
+	s.config.ContinueAsNewInheritSearchAttributesAndMemo = dynamicconfig.GetBoolPropertyFnFilteredByDomain(false)
+
+	for _, initiator := range []workflow.ContinueAsNewInitiator{
+		workflow.ContinueAsNewInitiatorDecider,
+		workflow.ContinueAsNewInitiatorCronSchedule,
+		workflow.ContinueAsNewInitiatorRetryPolicy,
+	} {
+		executionInfo := s.continueAsNewWithSearchAttributesAndMemo(initiator, nil, nil)
+		s.Empty(executionInfo.Memo, initiator.String())
+		s.Empty(executionInfo.SearchAttributes, initiator.String())
+	}
+}
+
+// continueAsNewWithSearchAttributesAndMemo continues a workflow that has a memo and search attributes as new, using
+// the given memo and search attributes on the continue as new, and returns the execution info of the next run
+func (s *engineSuite) continueAsNewWithSearchAttributesAndMemo(initiator workflow.ContinueAsNewInitiator,
+	memo *workflow.Memo, searchAttributes *workflow.SearchAttributes) *persistence.WorkflowExecutionInfo {
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(uuid.New()),
+	}
+	tl := "testTaskList"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 10, "testIdentity")
+	executionInfo := msBuilder.GetExecutionInfo()
+	executionInfo.Memo = map[string][]byte{"memoKey": []byte("previous memo")}
+	executionInfo.SearchAttributes = map[string][]byte{"CustomKeywordField": []byte(`"previous"`)}
+
+	domainEntry := cache.NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{ID: validDomainID},
+		&persistence.DomainConfig{Retention: 1},
+		cluster.TestCurrentClusterName,
+		nil,
+	)
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: validDomainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	_, newBuilder, err := msBuilder.AddContinueAsNewEvent(msBuilder.GetNextEventID(), common.EmptyEventID, domainEntry, "",
+		&workflow.ContinueAsNewWorkflowExecutionDecisionAttributes{
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("wType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr(tl)},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(100),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(10),
+			Initiator:                           initiator.Ptr(),
+			Memo:                                memo,
+			SearchAttributes:                    searchAttributes,
+		}, persistence.EventStoreVersionV2)
+	s.NoError(err)
+	s.Equal(newBuilder.GetExecutionInfo().Memo, msBuilder.GetContinueAsNew().Memo)
+	s.Equal(newBuilder.GetExecutionInfo().SearchAttributes, msBuilder.GetContinueAsNew().SearchAttributes)
+	return newBuilder.GetExecutionInfo()
+}
+
+func (s *engineSuite) TestRespondActivityTaskFailedRetryBudgetExhausted() {
+	scope := tally.NewTestScope("test", nil)
+	s.mockHistoryEngine.metricsClient = metrics.NewClient(scope, metrics.History)
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
@@ -2900,51 +6915,40 @@ func (s *engineSuite) TestRespondActivityTaskFailedConflictOnUpdate() {
 		ScheduleID: 5,
 	})
 	identity := "testIdentity"
-	activity1ID := "activity1"
-	activity1Type := "activity_type1"
-	activity1Input := []byte("input1")
-	failReason := "fail reason"
-	details := []byte("fail details.")
-	activity2ID := "activity2"
-	activity2Type := "activity_type2"
-	activity2Input := []byte("input2")
-	activity2Result := []byte("activity2_result")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
-	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 25, 25, identity)
-	di1 := addDecisionTaskScheduledEvent(msBuilder)
-	decisionStartedEvent1 := addDecisionTaskStartedEvent(msBuilder, di1.ScheduleID, tl, identity)
-	decisionCompletedEvent1 := addDecisionTaskCompletedEvent(msBuilder, di1.ScheduleID,
-		*decisionStartedEvent1.EventId, nil, identity)
-	activity1ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity1ID,
-		activity1Type, tl, activity1Input, 100, 10, 5)
-	activity2ScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent1.EventId, activity2ID,
-		activity2Type, tl, activity2Input, 100, 10, 5)
-	addActivityTaskStartedEvent(msBuilder, *activity1ScheduledEvent.EventId, identity)
-	activity2StartedEvent := addActivityTaskStartedEvent(msBuilder, *activity2ScheduledEvent.EventId, identity)
-
-	ms1 := createMutableState(msBuilder)
-	gwmsResponse1 := &persistence.GetWorkflowExecutionResponse{State: ms1}
-
-	addActivityTaskCompletedEvent(msBuilder, *activity2ScheduledEvent.EventId,
-		*activity2StartedEvent.EventId, activity2Result, identity)
-	addDecisionTaskScheduledEvent(msBuilder)
-
-	ms2 := createMutableState(msBuilder)
-	gwmsResponse2 := &persistence.GetWorkflowExecutionResponse{State: ms2}
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	// the activity expires one second after it is scheduled, so the first retry cannot start before it expires
+	activityScheduledEvent, _, _ := msBuilder.AddActivityTaskScheduledEvent(*decisionCompletedEvent.EventId, &workflow.ScheduleActivityTaskDecisionAttributes{
+		ActivityId:                    common.StringPtr("activity1_id"),
+		ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
+		TaskList:                      &workflow.TaskList{Name: common.StringPtr(tl)},
+		Input:                         []byte("input1"),
+		ScheduleToCloseTimeoutSeconds: common.Int32Ptr(1),
+		ScheduleToStartTimeoutSeconds: common.Int32Ptr(1),
+		StartToCloseTimeoutSeconds:    common.Int32Ptr(1),
+		RetryPolicy: &workflow.RetryPolicy{
+			InitialIntervalInSeconds: common.Int32Ptr(1),
+			BackoffCoefficient:       common.Float64Ptr(1),
+			MaximumAttempts:          common.Int32Ptr(3),
+		},
+	})
+	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
 
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse1, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, &persistence.ConditionFailedError{}).Once()
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
-	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse2, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
 	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
 	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
-
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
 			Config: &persistence.DomainConfig{Retention: 1},
 			ReplicationConfig: &persistence.DomainReplicationConfig{
 				ActiveClusterName: cluster.TestCurrentClusterName,
@@ -2956,30 +6960,33 @@ func (s *engineSuite) TestRespondActivityTaskFailedConflictOnUpdate() {
 		},
 		nil,
 	)
+
 	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
 		FailedRequest: &workflow.RespondActivityTaskFailedRequest{
 			TaskToken: taskToken,
-			Reason:    &failReason,
-			Details:   details,
+			Reason:    common.StringPtr("failed"),
+			Details:   []byte("fail details."),
 			Identity:  &identity,
 		},
 	})
-	s.Nil(err, s.printHistory(msBuilder))
+	s.Nil(err)
 	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(12), executionBuilder.GetExecutionInfo().NextEventID)
-	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
-
+	_, isRunning := executionBuilder.GetActivityInfo(*activityScheduledEvent.EventId)
+	s.False(isRunning)
 	s.True(executionBuilder.HasPendingDecisionTask())
-	di, ok := executionBuilder.GetPendingDecision(int64(10))
-	s.True(ok)
-	s.Equal(int32(25), di.DecisionTimeout)
-	s.Equal(int64(10), di.ScheduleID)
-	s.Equal(common.EmptyEventID, di.StartedID)
+
+	counters := scope.Snapshot().Counters()
+	s.Equal(int64(1), counters["test.activity_retry_budget_exhausted+domain=testDomain,operation=RespondActivityTaskFailed"].Value())
 }
 
-func (s *engineSuite) TestRespondActivityTaskFailedMaxAttemptsExceeded() {
+func (s *engineSuite) TestRespondActivityTaskFailedGlobalNonRetryableError() {
+	nonRetryableErrors := s.config.GlobalNonRetryableActivityErrors
+	defer func() { s.config.GlobalNonRetryableActivityErrors = nonRetryableErrors }()
+	s.config.GlobalNonRetryableActivityErrors = func(domain string) string { return "auth-failed" }
+
+	scope := tally.NewTestScope("test", nil)
+	s.mockHistoryEngine.metricsClient = metrics.NewClient(scope, metrics.History)
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
@@ -2992,9 +6999,6 @@ func (s *engineSuite) TestRespondActivityTaskFailedMaxAttemptsExceeded() {
 		ScheduleID: 5,
 	})
 	identity := "testIdentity"
-	activityID := "activity1_id"
-	activityType := "activity_type1"
-	activityInput := []byte("input1")
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
@@ -3003,22 +7007,32 @@ func (s *engineSuite) TestRespondActivityTaskFailedMaxAttemptsExceeded() {
 	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
 	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
 		*decisionStartedEvent.EventId, nil, identity)
-	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
-		activityType, tl, activityInput, 100, 10, 5)
+	activityScheduledEvent, _, _ := msBuilder.AddActivityTaskScheduledEvent(*decisionCompletedEvent.EventId, &workflow.ScheduleActivityTaskDecisionAttributes{
+		ActivityId:                    common.StringPtr("activity1_id"),
+		ActivityType:                  &workflow.ActivityType{Name: common.StringPtr("activity_type1")},
+		TaskList:                      &workflow.TaskList{Name: common.StringPtr(tl)},
+		Input:                         []byte("input1"),
+		ScheduleToCloseTimeoutSeconds: common.Int32Ptr(100),
+		ScheduleToStartTimeoutSeconds: common.Int32Ptr(10),
+		StartToCloseTimeoutSeconds:    common.Int32Ptr(10),
+		RetryPolicy: &workflow.RetryPolicy{
+			InitialIntervalInSeconds:    common.Int32Ptr(1),
+			BackoffCoefficient:          common.Float64Ptr(1),
+			MaximumAttempts:             common.Int32Ptr(3),
+			ExpirationIntervalInSeconds: common.Int32Ptr(100),
+		},
+	})
 	addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
 
-	for i := 0; i < conditionalRetryCount; i++ {
-		ms := createMutableState(msBuilder)
-		gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
-
-		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-		s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
-		s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, &persistence.ConditionFailedError{}).Once()
-	}
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
 		&persistence.GetDomainResponse{
-			Info:   &persistence.DomainInfo{ID: domainID},
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
 			Config: &persistence.DomainConfig{Retention: 1},
 			ReplicationConfig: &persistence.DomainReplicationConfig{
 				ActiveClusterName: cluster.TestCurrentClusterName,
@@ -3030,17 +7044,27 @@ func (s *engineSuite) TestRespondActivityTaskFailedMaxAttemptsExceeded() {
 		},
 		nil,
 	)
+
 	err := s.mockHistoryEngine.RespondActivityTaskFailed(context.Background(), &history.RespondActivityTaskFailedRequest{
 		DomainUUID: common.StringPtr(domainID),
 		FailedRequest: &workflow.RespondActivityTaskFailedRequest{
 			TaskToken: taskToken,
+			Reason:    common.StringPtr("auth-failed"),
+			Details:   []byte("fail details."),
 			Identity:  &identity,
 		},
 	})
-	s.Equal(ErrMaxAttemptsExceeded, err)
+	s.Nil(err)
+	executionBuilder := s.getBuilder(domainID, we)
+	_, isRunning := executionBuilder.GetActivityInfo(*activityScheduledEvent.EventId)
+	s.False(isRunning)
+	s.True(executionBuilder.HasPendingDecisionTask())
+
+	counters := scope.Snapshot().Counters()
+	s.Equal(int64(1), counters["test.activity_global_non_retryable_error+domain=testDomain,operation=RespondActivityTaskFailed"].Value())
 }
 
-func (s *engineSuite) TestRespondActivityTaskFailedSuccess() {
+func (s *engineSuite) TestRespondActivityTaskFailedDetailsExceedsLimit() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
@@ -3057,7 +7081,9 @@ func (s *engineSuite) TestRespondActivityTaskFailedSuccess() {
 	activityType := "activity_type1"
 	activityInput := []byte("input1")
 	failReason := "failed"
-	failDetails := []byte("fail details.")
+	failDetails := []byte("fail details exceeding the limit")
+	s.mockHistoryEngine.config.BlobSizeLimitWarn = dynamicconfig.GetIntPropertyFilteredByDomain(5)
+	s.mockHistoryEngine.config.BlobSizeLimitError = dynamicconfig.GetIntPropertyFilteredByDomain(10)
 
 	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
 		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
@@ -3073,8 +7099,12 @@ func (s *engineSuite) TestRespondActivityTaskFailedSuccess() {
 	ms := createMutableState(msBuilder)
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
+	var appendedEvents []*workflow.HistoryEvent
 	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.MatchedBy(func(request *p.AppendHistoryNodesRequest) bool {
+		appendedEvents = request.Events
+		return true
+	})).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
 	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
@@ -3100,18 +7130,16 @@ func (s *engineSuite) TestRespondActivityTaskFailedSuccess() {
 			Identity:  &identity,
 		},
 	})
-	s.Nil(err)
-	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(9), executionBuilder.GetExecutionInfo().NextEventID)
-	s.Equal(int64(3), executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
-
-	s.True(executionBuilder.HasPendingDecisionTask())
-	di, ok := executionBuilder.GetPendingDecision(int64(8))
-	s.True(ok)
-	s.Equal(int32(100), di.DecisionTimeout)
-	s.Equal(int64(8), di.ScheduleID)
-	s.Equal(common.EmptyEventID, di.StartedID)
+	s.Nil(err)
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(9), executionBuilder.GetExecutionInfo().NextEventID)
+	s.True(executionBuilder.HasPendingDecisionTask())
+
+	s.Equal(2, len(appendedEvents))
+	s.Equal(workflow.EventTypeActivityTaskFailed, appendedEvents[0].GetEventType())
+	attributes := appendedEvents[0].ActivityTaskFailedEventAttributes
+	s.Equal(common.FailureReasonFailureDetailsExceedsLimit, attributes.GetReason())
+	s.Equal(failDetails[0:10], attributes.Details)
 }
 
 func (s *engineSuite) TestRespondActivityTaskFailedByIDSuccess() {
@@ -3771,6 +7799,98 @@ func (s *engineSuite) TestRequestCancel_RespondDecisionTaskCompleted_NotSchedule
 	s.False(executionBuilder.HasPendingDecisionTask())
 }
 
+func (s *engineSuite) TestRequestCancel_RespondDecisionTaskCompleted_ActivityAlreadyCompleted() {
+	scope := tally.NewTestScope("test", nil)
+	s.mockHistoryEngine.decisionHandler.(*decisionHandlerImpl).metricsClient = metrics.NewClient(scope, metrics.History)
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	taskToken, _ := json.Marshal(&common.TaskToken{
+		WorkflowID: *we.WorkflowId,
+		RunID:      *we.RunId,
+		ScheduleID: 8,
+	})
+	identity := "testIdentity"
+	activityID := "activity1_id"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 100, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	decisionStartedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	decisionCompletedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID,
+		*decisionStartedEvent.EventId, nil, identity)
+	activityScheduledEvent, _ := addActivityTaskScheduledEvent(msBuilder, *decisionCompletedEvent.EventId, activityID,
+		"activity_type1", tl, []byte("input1"), 100, 10, 5)
+	activityStartedEvent := addActivityTaskStartedEvent(msBuilder, *activityScheduledEvent.EventId, identity)
+	addActivityTaskCompletedEvent(msBuilder, *activityScheduledEvent.EventId, *activityStartedEvent.EventId,
+		[]byte("result1"), identity)
+	di2 := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di2.ScheduleID, tl, identity)
+
+	decisions := []*workflow.Decision{{
+		DecisionType: common.DecisionTypePtr(workflow.DecisionTypeRequestCancelActivityTask),
+		RequestCancelActivityTaskDecisionAttributes: &workflow.RequestCancelActivityTaskDecisionAttributes{
+			ActivityId: common.StringPtr(activityID),
+		},
+	}}
+
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	var appendRequest *p.AppendHistoryNodesRequest
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Run(func(args mock.Arguments) {
+		appendRequest = args.Get(0).(*p.AppendHistoryNodesRequest)
+	}).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	_, err := s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: []byte("context"),
+			Identity:         &identity,
+		},
+	})
+	s.Nil(err)
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(12), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+
+	s.NotNil(appendRequest)
+	var failedAttributes *workflow.RequestCancelActivityTaskFailedEventAttributes
+	for _, event := range appendRequest.Events {
+		if event.GetEventType() == workflow.EventTypeRequestCancelActivityTaskFailed {
+			failedAttributes = event.RequestCancelActivityTaskFailedEventAttributes
+		}
+	}
+	s.NotNil(failedAttributes)
+	s.Equal(activityID, failedAttributes.GetActivityId())
+	s.Equal(activityCancellationMsgActivityIDUnknown, failedAttributes.GetCause())
+
+	counters := scope.Snapshot().Counters()
+	s.Equal(int64(1), counters["test.decision_referenced_completed_activity+domain=testDomain,operation=RespondDecisionTaskCompleted"].Value())
+}
+
 func (s *engineSuite) TestRequestCancel_RespondDecisionTaskCompleted_Scheduled() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
@@ -4585,30 +8705,322 @@ func (s *engineSuite) TestCancelTimer_RespondDecisionTaskCompleted_TimerFired()
 		},
 		nil,
 	)
-	_, err = s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
-		DomainUUID: common.StringPtr(domainID),
-		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
-			TaskToken:        taskToken,
-			Decisions:        decisions,
-			ExecutionContext: []byte("context"),
-			Identity:         &identity,
+	_, err = s.mockHistoryEngine.RespondDecisionTaskCompleted(context.Background(), &history.RespondDecisionTaskCompletedRequest{
+		DomainUUID: common.StringPtr(domainID),
+		CompleteRequest: &workflow.RespondDecisionTaskCompletedRequest{
+			TaskToken:        taskToken,
+			Decisions:        decisions,
+			ExecutionContext: []byte("context"),
+			Identity:         &identity,
+		},
+	})
+	s.Nil(err)
+
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(int64(10), executionBuilder.GetExecutionInfo().NextEventID)
+	s.Equal(int64(7), executionBuilder.GetExecutionInfo().LastProcessedEvent)
+	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
+	s.False(executionBuilder.HasPendingDecisionTask())
+	s.False(executionBuilder.HasBufferedEvents())
+}
+
+func (s *engineSuite) TestSignalWorkflowExecution() {
+	signalRequest := &history.SignalWorkflowExecutionRequest{}
+	err := s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), signalRequest)
+	s.EqualError(err, "BadRequestError{Message: Missing domain UUID.}")
+
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	identity := "testIdentity"
+	signalName := "my signal name"
+	input := []byte("test input")
+	signalRequest = &history.SignalWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		SignalRequest: &workflow.SignalWorkflowExecutionRequest{
+			Domain:            common.StringPtr(domainID),
+			WorkflowExecution: &we,
+			Identity:          common.StringPtr(identity),
+			SignalName:        common.StringPtr(signalName),
+			Input:             input,
+		},
+	}
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	ms := createMutableState(msBuilder)
+	ms.ExecutionInfo.DomainID = validDomainID
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	err = s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), signalRequest)
+	s.Nil(err)
+}
+
+func (s *engineSuite) TestSignalWorkflowExecution_InvalidSignalName() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	signalRequest := &history.SignalWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		SignalRequest: &workflow.SignalWorkflowExecutionRequest{
+			Domain:            common.StringPtr(domainID),
+			WorkflowExecution: &we,
+			Identity:          common.StringPtr("testIdentity"),
+			Input:             []byte("test input"),
+		},
+	}
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	err := s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), signalRequest)
+	s.EqualError(err, "BadRequestError{Message: Missing SignalName.}")
+
+	signalRequest.SignalRequest.SignalName = common.StringPtr(strings.Repeat("s", s.mockHistoryEngine.config.MaxIDLengthLimit()+1))
+	err = s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), signalRequest)
+	s.EqualError(err, "BadRequestError{Message: SignalName exceeds length limit.}")
+}
+
+func (s *engineSuite) TestSignalWorkflowExecution_RepairWorkflowTimeoutTask() {
+	enableRepair := s.config.EnableWorkflowTimeoutRepair
+	defer func() { s.config.EnableWorkflowTimeoutRepair = enableRepair }()
+	s.config.EnableWorkflowTimeoutRepair = dynamicconfig.GetBoolPropertyFnFilteredByDomain(true)
+
+	domainID := validDomainID
+	workflowID := "wId"
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	outstandingRunID := uuid.New()
+	testCases := []struct {
+		name         string
+		runID        string
+		timers       []*persistence.TimerTaskInfo
+		expectRepair bool
+	}{
+		{
+			name:         "missing",
+			runID:        uuid.New(),
+			timers:       []*persistence.TimerTaskInfo{},
+			expectRepair: true,
+		},
+		{
+			name:  "timer of another run",
+			runID: uuid.New(),
+			timers: []*persistence.TimerTaskInfo{
+				{DomainID: domainID, WorkflowID: workflowID, RunID: uuid.New(), TaskType: persistence.TaskTypeWorkflowTimeout},
+			},
+			expectRepair: true,
+		},
+		{
+			name:  "outstanding",
+			runID: outstandingRunID,
+			timers: []*persistence.TimerTaskInfo{
+				{DomainID: domainID, WorkflowID: workflowID, RunID: outstandingRunID, TaskType: persistence.TaskTypeWorkflowTimeout},
+			},
+			expectRepair: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		we := workflow.WorkflowExecution{
+			WorkflowId: common.StringPtr(workflowID),
+			RunId:      common.StringPtr(tc.runID),
+		}
+		msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+			loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+		addWorkflowExecutionStartedEvent(msBuilder, we, "wType", "testTaskList", []byte("input"), 100, 200, "testIdentity")
+		ms := createMutableState(msBuilder)
+		ms.ExecutionInfo.DomainID = domainID
+		gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+		expectedTimeout := ms.ExecutionInfo.StartTimestamp.Add(100 * time.Second)
+
+		var timerTasks []persistence.Task
+		s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+		s.mockExecutionMgr.On("GetTimerIndexTasks", mock.MatchedBy(func(input *persistence.GetTimerIndexTasksRequest) bool {
+			return !input.MinTimestamp.After(expectedTimeout) && !input.MaxTimestamp.Before(expectedTimeout)
+		})).Return(&persistence.GetTimerIndexTasksResponse{Timers: tc.timers}, nil).Once()
+		s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Twice()
+		s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(
+			&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
+		).Run(func(args mock.Arguments) {
+			timerTasks = args.Get(0).(*persistence.UpdateWorkflowExecutionRequest).TimerTasks
+		}).Twice()
+
+		signal := func() []persistence.Task {
+			err := s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), &history.SignalWorkflowExecutionRequest{
+				DomainUUID: common.StringPtr(domainID),
+				SignalRequest: &workflow.SignalWorkflowExecutionRequest{
+					Domain:            common.StringPtr(domainID),
+					WorkflowExecution: &we,
+					Identity:          common.StringPtr("testIdentity"),
+					SignalName:        common.StringPtr("my signal name"),
+				},
+			})
+			s.Nil(err, tc.name)
+
+			var repaired []persistence.Task
+			for _, task := range timerTasks {
+				if task.GetType() == persistence.TaskTypeWorkflowTimeout {
+					repaired = append(repaired, task)
+				}
+			}
+			return repaired
+		}
+
+		repaired := signal()
+		if tc.expectRepair {
+			s.Equal(1, len(repaired), tc.name)
+			s.Equal(expectedTimeout, repaired[0].GetVisibilityTimestamp(), tc.name)
+		} else {
+			s.Empty(repaired, tc.name)
+		}
+
+		// the timer queue is only checked when the workflow is loaded, the cached workflow is not repaired again
+		s.Empty(signal(), tc.name)
+	}
+}
+
+func (s *engineSuite) TestRecordChildExecutionCompleted_DuplicateDelivery() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	childExecution := &workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("child wId"),
+		RunId:      common.StringPtr(uuid.New()),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	startedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	completedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID, startedEvent.GetEventId(), nil, identity)
+	initiatedEvent, _ := addStartChildWorkflowExecutionInitiatedEvent(msBuilder, completedEvent.GetEventId(), uuid.New(),
+		domainID, childExecution.GetWorkflowId(), "child wType", tl, nil, 100, 10)
+	childStartedEvent := addChildWorkflowExecutionStartedEvent(msBuilder, initiatedEvent.GetEventId(), domainID,
+		childExecution.GetWorkflowId(), childExecution.GetRunId(), "child wType")
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	// the second delivery finds the recorded completion in the history and writes nothing
+	history := []*workflow.HistoryEvent{completedEvent, initiatedEvent, childStartedEvent}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Run(func(args mock.Arguments) {
+		history = append(history, args.Get(0).(*p.AppendHistoryNodesRequest).Events...)
+	}).Once()
+	s.mockHistoryV2Mgr.On("ReadHistoryBranch", mock.Anything).Return(func(request *p.ReadHistoryBranchRequest) *p.ReadHistoryBranchResponse {
+		var events []*workflow.HistoryEvent
+		for _, event := range history {
+			if event.GetEventId() >= request.MinEventID && event.GetEventId() < request.MaxEventID {
+				events = append(events, event)
+			}
+		}
+		return &p.ReadHistoryBranchResponse{HistoryEvents: events}
+	}, nil).Twice()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	request := &history.RecordChildExecutionCompletedRequest{
+		DomainUUID:         common.StringPtr(domainID),
+		WorkflowExecution:  &we,
+		InitiatedId:        common.Int64Ptr(initiatedEvent.GetEventId()),
+		CompletedExecution: childExecution,
+		CompletionEvent: &workflow.HistoryEvent{
+			EventType: common.EventTypePtr(workflow.EventTypeWorkflowExecutionCompleted),
+			WorkflowExecutionCompletedEventAttributes: &workflow.WorkflowExecutionCompletedEventAttributes{
+				Result: []byte("child result"),
+			},
 		},
-	})
+	}
+	err := s.mockHistoryEngine.RecordChildExecutionCompleted(context.Background(), request)
 	s.Nil(err)
-
 	executionBuilder := s.getBuilder(domainID, we)
-	s.Equal(int64(10), executionBuilder.GetExecutionInfo().NextEventID)
-	s.Equal(int64(7), executionBuilder.GetExecutionInfo().LastProcessedEvent)
-	s.Equal(persistence.WorkflowStateRunning, executionBuilder.GetExecutionInfo().State)
-	s.False(executionBuilder.HasPendingDecisionTask())
-	s.False(executionBuilder.HasBufferedEvents())
-}
+	_, ok := executionBuilder.GetChildExecutionInfo(initiatedEvent.GetEventId())
+	s.False(ok)
+	s.True(executionBuilder.HasPendingDecisionTask())
+	nextEventID := executionBuilder.GetNextEventID()
 
-func (s *engineSuite) TestSignalWorkflowExecution() {
-	signalRequest := &history.SignalWorkflowExecutionRequest{}
-	err := s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), signalRequest)
-	s.EqualError(err, "BadRequestError{Message: Missing domain UUID.}")
+	err = s.mockHistoryEngine.RecordChildExecutionCompleted(context.Background(), request)
+	s.Nil(err)
+	s.Equal(nextEventID, s.getBuilder(domainID, we).GetNextEventID())
+
+	// a completion for an event which did not initiate a child is rejected
+	request.InitiatedId = common.Int64Ptr(completedEvent.GetEventId())
+	err = s.mockHistoryEngine.RecordChildExecutionCompleted(context.Background(), request)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
+
+	// a completion for a child which was never initiated is still rejected
+	request.InitiatedId = common.Int64Ptr(nextEventID + 10)
+	err = s.mockHistoryEngine.RecordChildExecutionCompleted(context.Background(), request)
+	s.IsType(&workflow.EntityNotExistsError{}, err)
+}
 
+func (s *engineSuite) TestSignalWorkflowExecution_WithHeader() {
 	domainID := validDomainID
 	we := workflow.WorkflowExecution{
 		WorkflowId: common.StringPtr("wId"),
@@ -4617,7 +9029,10 @@ func (s *engineSuite) TestSignalWorkflowExecution() {
 	identity := "testIdentity"
 	signalName := "my signal name"
 	input := []byte("test input")
-	signalRequest = &history.SignalWorkflowExecutionRequest{
+	header := &workflow.Header{
+		Fields: map[string][]byte{"tracing-context": []byte("trace-id")},
+	}
+	signalRequest := &history.SignalWorkflowExecutionRequest{
 		DomainUUID: common.StringPtr(domainID),
 		SignalRequest: &workflow.SignalWorkflowExecutionRequest{
 			Domain:            common.StringPtr(domainID),
@@ -4625,6 +9040,7 @@ func (s *engineSuite) TestSignalWorkflowExecution() {
 			Identity:          common.StringPtr(identity),
 			SignalName:        common.StringPtr(signalName),
 			Input:             input,
+			Header:            header,
 		},
 	}
 
@@ -4634,8 +9050,12 @@ func (s *engineSuite) TestSignalWorkflowExecution() {
 	ms.ExecutionInfo.DomainID = validDomainID
 	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
 
+	var appendedEvents []*workflow.HistoryEvent
 	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
-	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.MatchedBy(func(request *p.AppendHistoryNodesRequest) bool {
+		appendedEvents = request.Events
+		return true
+	})).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
 	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
 
 	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
@@ -4652,8 +9072,14 @@ func (s *engineSuite) TestSignalWorkflowExecution() {
 		},
 		nil,
 	)
-	err = s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), signalRequest)
+	err := s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), signalRequest)
 	s.Nil(err)
+
+	s.NotEmpty(appendedEvents)
+	s.Equal(workflow.EventTypeWorkflowExecutionSignaled, appendedEvents[0].GetEventType())
+	attributes := appendedEvents[0].WorkflowExecutionSignaledEventAttributes
+	s.Equal(signalName, attributes.GetSignalName())
+	s.Equal(header, attributes.Header)
 }
 
 // Test signal decision by adding request ID
@@ -4713,6 +9139,234 @@ func (s *engineSuite) TestSignalWorkflowExecution_DuplicateRequest() {
 	s.Nil(err)
 }
 
+func (s *engineSuite) TestSignalWorkflowExecution_EvictOldestRequestID() {
+	maxSignalRequestedIDs := s.config.MaxSignalRequestedIDs
+	s.config.MaxSignalRequestedIDs = dynamicconfig.GetIntPropertyFilteredByDomain(2)
+	defer func() { s.config.MaxSignalRequestedIDs = maxSignalRequestedIDs }()
+
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	signalRequest := &history.SignalWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		SignalRequest: &workflow.SignalWorkflowExecutionRequest{
+			Domain:            common.StringPtr(domainID),
+			WorkflowExecution: &we,
+			Identity:          common.StringPtr("testIdentity"),
+			SignalName:        common.StringPtr("my signal name"),
+			Input:             []byte("test input"),
+			RequestId:         common.StringPtr("request-c"),
+		},
+	}
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	ms := createMutableState(msBuilder)
+	ms.SignalRequestedIDs = map[string]struct{}{
+		"request-a": {},
+		"request-b": {},
+	}
+	// request-b was signaled before request-a
+	ms.ExecutionInfo.SignalRequestedTimestamps = map[string]int64{
+		"request-a": 2,
+		"request-b": 1,
+	}
+	ms.ExecutionInfo.DomainID = validDomainID
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	var updateRequest *p.UpdateWorkflowExecutionRequest
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Run(func(arguments mock.Arguments) {
+		updateRequest = arguments.Get(0).(*p.UpdateWorkflowExecutionRequest)
+	}).Once()
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+	err := s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), signalRequest)
+	s.Nil(err)
+	s.NotNil(updateRequest)
+	s.Equal([]string{"request-c"}, updateRequest.UpsertSignalRequestedIDs)
+	s.Equal("request-b", updateRequest.DeleteSignalRequestedID)
+	timestamps := updateRequest.ExecutionInfo.SignalRequestedTimestamps
+	s.Len(timestamps, 2)
+	s.Equal(int64(2), timestamps["request-a"])
+	s.True(timestamps["request-c"] > 2)
+}
+
+func (s *engineSuite) TestCheckClusterClockSkew() {
+	scope := tally.NewTestScope("test", nil)
+	s.mockHistoryEngine.metricsClient = metrics.NewClient(scope, metrics.History)
+	s.mockHistoryEngine.config.MaxClusterClockSkew = dynamicconfig.GetDurationPropertyFn(time.Minute)
+	clusterName := cluster.TestAlternativeClusterName
+
+	now := time.Now()
+	remoteTime := now.Add(30 * time.Second)
+	s.Equal(remoteTime, s.mockHistoryEngine.checkClusterClockSkew(clusterName, remoteTime))
+	remoteTime = now.Add(-time.Hour)
+	s.Equal(remoteTime, s.mockHistoryEngine.checkClusterClockSkew(clusterName, remoteTime))
+	remoteTime = now.Add(time.Hour)
+	clamped := s.mockHistoryEngine.checkClusterClockSkew(clusterName, remoteTime)
+	s.True(clamped.Before(remoteTime.Add(-30 * time.Minute)))
+	s.False(clamped.Before(now))
+
+	counter := scope.Snapshot().Counters()["test.cluster_clock_skew_detected+operation=SyncShardStatus,target_cluster="+clusterName]
+	s.NotNil(counter)
+	s.Equal(int64(2), counter.Value())
+}
+
+func (s *engineSuite) TestSignalWorkflowExecution_InFlightDecision() {
+	scope := tally.NewTestScope("test", nil)
+	s.mockHistoryEngine.metricsClient = metrics.NewClient(scope, metrics.History)
+
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	ms := createMutableState(msBuilder)
+	ms.ExecutionInfo.DomainID = domainID
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	signalCount := 10
+	var transferTasksLock sync.Mutex
+	var transferTasks []persistence.Task
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Times(signalCount)
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Run(func(args mock.Arguments) {
+		transferTasksLock.Lock()
+		defer transferTasksLock.Unlock()
+		transferTasks = append(transferTasks, args.Get(0).(*persistence.UpdateWorkflowExecutionRequest).TransferTasks...)
+	}).Times(signalCount)
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID, Name: "testDomain"},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, signalCount)
+	for i := 0; i < signalCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), &history.SignalWorkflowExecutionRequest{
+				DomainUUID: common.StringPtr(domainID),
+				SignalRequest: &workflow.SignalWorkflowExecutionRequest{
+					Domain:            common.StringPtr(domainID),
+					WorkflowExecution: &we,
+					Identity:          common.StringPtr(identity),
+					SignalName:        common.StringPtr("signal" + strconv.Itoa(i)),
+					Input:             []byte("test input"),
+				},
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		s.Nil(err)
+	}
+
+	for _, task := range transferTasks {
+		s.NotEqual(persistence.TransferTaskTypeDecisionTask, task.GetType())
+	}
+	executionBuilder := s.getBuilder(domainID, we)
+	s.Equal(di.ScheduleID, executionBuilder.GetExecutionInfo().DecisionScheduleID)
+	counters := scope.Snapshot().Counters()
+	s.Equal(int64(signalCount), counters["test.duplicate_decision_suppressed+domain=testDomain,operation=SignalWorkflowExecution"].Value())
+}
+
+func (s *engineSuite) TestSignalWorkflowExecution_CronNotStarted_SignalsLimitExceeded() {
+	domainID := validDomainID
+	we := &workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	identity := "testIdentity"
+	signalName := "my signal name"
+	input := []byte("test input")
+	signalRequest := &history.SignalWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		SignalRequest: &workflow.SignalWorkflowExecutionRequest{
+			Domain:            common.StringPtr(domainID),
+			WorkflowExecution: we,
+			Identity:          common.StringPtr(identity),
+			SignalName:        common.StringPtr(signalName),
+			Input:             input,
+		},
+	}
+	s.mockHistoryEngine.config.MaximumSignalsBeforeFirstDecision = dynamicconfig.GetIntPropertyFilteredByDomain(2)
+
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	// the first decision of the cron workflow waits for the cron schedule
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	ms := createMutableState(msBuilder)
+	ms.ExecutionInfo.DomainID = validDomainID
+	ms.ExecutionInfo.CronSchedule = "@every 1h"
+	ms.ExecutionInfo.SignalCount = 1
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Once()
+	err := s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), signalRequest)
+	s.Nil(err)
+
+	// the second signal reaches the limit, the next one is rejected
+	executionBuilder := s.getBuilder(domainID, *we)
+	s.Equal(int32(2), executionBuilder.GetExecutionInfo().SignalCount)
+	s.False(executionBuilder.HasPendingDecisionTask())
+	err = s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), signalRequest)
+	s.Equal(ErrSignalsLimitExceeded, err)
+}
+
 func (s *engineSuite) TestSignalWorkflowExecution_Failed() {
 	signalRequest := &history.SignalWorkflowExecutionRequest{}
 	err := s.mockHistoryEngine.SignalWorkflowExecution(context.Background(), signalRequest)
@@ -4806,6 +9460,165 @@ func (s *engineSuite) TestRemoveSignalMutableState() {
 	s.Nil(err)
 }
 
+func (s *engineSuite) TestDescribeArchivalBacklog() {
+	archivalDomainID := uuid.New()
+	noArchivalDomainID := uuid.New()
+	s.mockClusterMetadata.On("ArchivalConfig").Return(cluster.NewArchivalConfig(cluster.ArchivalEnabled, "testBucket", false))
+	for domainID, archivalStatus := range map[string]workflow.ArchivalStatus{
+		archivalDomainID:   workflow.ArchivalStatusEnabled,
+		noArchivalDomainID: workflow.ArchivalStatusDisabled,
+	} {
+		s.mockMetadataMgr.On("GetDomain", &persistence.GetDomainRequest{ID: domainID}).Return(
+			&persistence.GetDomainResponse{
+				Info:   &persistence.DomainInfo{ID: domainID, Name: "domain-" + domainID},
+				Config: &persistence.DomainConfig{Retention: 1, ArchivalStatus: archivalStatus},
+				ReplicationConfig: &persistence.DomainReplicationConfig{
+					ActiveClusterName: cluster.TestCurrentClusterName,
+					Clusters: []*persistence.ClusterReplicationConfig{
+						{ClusterName: cluster.TestCurrentClusterName},
+					},
+				},
+				TableVersion: persistence.DomainTableVersionV1,
+			},
+			nil,
+		).Once()
+	}
+	s.mockExecutionMgr.On("GetTimerIndexTasks", mock.Anything).Return(&persistence.GetTimerIndexTasksResponse{
+		Timers: []*persistence.TimerTaskInfo{
+			{DomainID: archivalDomainID, WorkflowID: "wId1", RunID: uuid.New(), TaskType: persistence.TaskTypeDeleteHistoryEvent},
+			{DomainID: archivalDomainID, WorkflowID: "wId2", RunID: uuid.New(), TaskType: persistence.TaskTypeWorkflowTimeout},
+			{DomainID: noArchivalDomainID, WorkflowID: "wId3", RunID: uuid.New(), TaskType: persistence.TaskTypeDeleteHistoryEvent},
+		},
+		NextPageToken: []byte("token"),
+	}, nil).Once()
+	s.mockExecutionMgr.On("GetTimerIndexTasks", mock.Anything).Return(&persistence.GetTimerIndexTasksResponse{
+		Timers: []*persistence.TimerTaskInfo{
+			{DomainID: archivalDomainID, WorkflowID: "wId4", RunID: uuid.New(), TaskType: persistence.TaskTypeDeleteHistoryEvent},
+		},
+	}, nil).Once()
+
+	backlog, err := s.mockHistoryEngine.DescribeArchivalBacklog()
+	s.Nil(err)
+	s.Equal(s.mockHistoryEngine.shard.GetShardID(), backlog.ShardID)
+	s.Equal(map[string]int64{"domain-" + archivalDomainID: 2}, backlog.DomainBacklog)
+	s.False(backlog.Truncated)
+}
+
+func (s *engineSuite) TestTerminateWorkflowExecution_ChildPolicyTerminate() {
+	childExecution, updateRequest := s.terminateWithChildPolicy(workflow.ChildPolicyTerminate)
+	tasks := getChildPolicyTasks(updateRequest.TransferTasks)
+	s.Equal(1, len(tasks))
+	terminateTask, ok := tasks[0].(*persistence.TerminateChildExecutionTask)
+	s.True(ok)
+	s.Equal(validDomainID, terminateTask.TargetDomainID)
+	s.Equal(childExecution.GetWorkflowId(), terminateTask.TargetWorkflowID)
+	s.Equal(childExecution.GetRunId(), terminateTask.TargetRunID)
+}
+
+func (s *engineSuite) TestTerminateWorkflowExecution_ChildPolicyRequestCancel() {
+	childExecution, updateRequest := s.terminateWithChildPolicy(workflow.ChildPolicyRequestCancel)
+	tasks := getChildPolicyTasks(updateRequest.TransferTasks)
+	s.Equal(1, len(tasks))
+	cancelTask, ok := tasks[0].(*persistence.CancelChildExecutionTask)
+	s.True(ok)
+	s.Equal(validDomainID, cancelTask.TargetDomainID)
+	s.Equal(childExecution.GetWorkflowId(), cancelTask.TargetWorkflowID)
+	s.Equal(childExecution.GetRunId(), cancelTask.TargetRunID)
+}
+
+func (s *engineSuite) TestTerminateWorkflowExecution_ChildPolicyAbandon() {
+	_, updateRequest := s.terminateWithChildPolicy(workflow.ChildPolicyAbandon)
+	s.Empty(getChildPolicyTasks(updateRequest.TransferTasks))
+}
+
+// terminateWithChildPolicy terminates a workflow with a single started child using the given child policy and
+// returns the child execution along with the persisted update request
+func (s *engineSuite) terminateWithChildPolicy(childPolicy workflow.ChildPolicy) (*workflow.WorkflowExecution,
+	*persistence.UpdateWorkflowExecutionRequest) {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	childExecution := &workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("child wId"),
+		RunId:      common.StringPtr(uuid.New()),
+	}
+	tl := "testTaskList"
+	identity := "testIdentity"
+
+	msBuilder := newMutableStateBuilderWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(), s.mockHistoryEngine.shard, s.eventsCache,
+		loggerimpl.NewDevelopmentForTest(s.Suite), we.GetRunId())
+	addWorkflowExecutionStartedEvent(msBuilder, we, "wType", tl, []byte("input"), 100, 200, identity)
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	startedEvent := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, tl, identity)
+	completedEvent := addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID, startedEvent.GetEventId(), nil, identity)
+	initiatedEvent, _, _ := msBuilder.AddStartChildWorkflowExecutionInitiatedEvent(completedEvent.GetEventId(), uuid.New(),
+		&workflow.StartChildWorkflowExecutionDecisionAttributes{
+			Domain:                              common.StringPtr(domainID),
+			WorkflowId:                          childExecution.WorkflowId,
+			WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("child wType")},
+			TaskList:                            &workflow.TaskList{Name: common.StringPtr(tl)},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(100),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(10),
+			ChildPolicy:                         common.ChildPolicyPtr(childPolicy),
+		})
+	addChildWorkflowExecutionStartedEvent(msBuilder, initiatedEvent.GetEventId(), domainID, childExecution.GetWorkflowId(),
+		childExecution.GetRunId(), "child wType")
+	ms := createMutableState(msBuilder)
+	gwmsResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+
+	var updateRequest *persistence.UpdateWorkflowExecutionRequest
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(gwmsResponse, nil).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(
+		&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil,
+	).Run(func(args mock.Arguments) {
+		updateRequest = args.Get(0).(*persistence.UpdateWorkflowExecutionRequest)
+	}).Once()
+	s.mockMetadataMgr.On("GetDomain", mock.Anything).Return(
+		&persistence.GetDomainResponse{
+			Info:   &persistence.DomainInfo{ID: domainID, Name: domainID},
+			Config: &persistence.DomainConfig{Retention: 1},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				ActiveClusterName: cluster.TestCurrentClusterName,
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{ClusterName: cluster.TestCurrentClusterName},
+				},
+			},
+			TableVersion: persistence.DomainTableVersionV1,
+		},
+		nil,
+	)
+
+	err := s.mockHistoryEngine.TerminateWorkflowExecution(context.Background(), &history.TerminateWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(domainID),
+		TerminateRequest: &workflow.TerminateWorkflowExecutionRequest{
+			WorkflowExecution: &we,
+			Reason:            common.StringPtr("terminate reason"),
+			Identity:          common.StringPtr(identity),
+		},
+	})
+	s.Nil(err)
+	s.NotNil(updateRequest)
+	return childExecution, updateRequest
+}
+
+func getChildPolicyTasks(transferTasks []persistence.Task) []persistence.Task {
+	var tasks []persistence.Task
+	for _, task := range transferTasks {
+		switch task.GetType() {
+		case persistence.TransferTaskTypeTerminateChildExecution, persistence.TransferTaskTypeCancelChildExecution:
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+func (s *engineSuite) searchAttributesValidator() *common.VisibilityQueryValidator {
+	return common.NewQueryValidator(s.config.ValidSearchAttributes)
+}
+
 func (s *engineSuite) getBuilder(domainID string, we workflow.WorkflowExecution) mutableState {
 	context, release, err := s.mockHistoryEngine.historyCache.getOrCreateWorkflowExecution(domainID, we)
 	if err != nil {
@@ -5125,6 +9938,7 @@ func copyWorkflowExecutionInfo(sourceInfo *persistence.WorkflowExecutionInfo) *p
 		ExecutionContext:             sourceInfo.ExecutionContext,
 		State:                        sourceInfo.State,
 		CloseStatus:                  sourceInfo.CloseStatus,
+		CloseTimestamp:               sourceInfo.CloseTimestamp,
 		LastFirstEventID:             sourceInfo.LastFirstEventID,
 		LastEventTaskID:              sourceInfo.LastEventTaskID,
 		NextEventID:                  sourceInfo.NextEventID,
@@ -5148,6 +9962,7 @@ func copyWorkflowExecutionInfo(sourceInfo *persistence.WorkflowExecutionInfo) *p
 		ClientFeatureVersion:         sourceInfo.ClientFeatureVersion,
 		ClientImpl:                   sourceInfo.ClientImpl,
 		AutoResetPoints:              sourceInfo.AutoResetPoints,
+		Memo:                         sourceInfo.Memo,
 		Attempt:                      sourceInfo.Attempt,
 		HasRetryPolicy:               sourceInfo.HasRetryPolicy,
 		InitialInterval:              sourceInfo.InitialInterval,
@@ -5222,6 +10037,7 @@ func copyActivityInfo(sourceInfo *persistence.ActivityInfo) *persistence.Activit
 		ExpirationTime:           sourceInfo.ExpirationTime,
 		MaximumAttempts:          sourceInfo.MaximumAttempts,
 		NonRetriableErrors:       sourceInfo.NonRetriableErrors,
+		LastFailureReason:        sourceInfo.LastFailureReason,
 		//// Not written to database - This is used only for deduping heartbeat timer creation
 		// LastHeartbeatTimeoutVisibility: sourceInfo.LastHeartbeatTimeoutVisibility,
 	}
@@ -918,7 +918,7 @@ func (r *historyReplicator) terminateWorkflow(
 		RunId:      common.StringPtr(runID),
 	}
 	var currentLastWriteVersion int64
-	err := r.historyEngine.updateWorkflowExecution(ctx, domainID, execution, true, false,
+	err := r.historyEngine.updateWorkflowExecution(ctx, metrics.ReplicateHistoryEventsScope, domainID, execution, true, false,
 		func(msBuilder mutableState, tBuilder *timerBuilder) ([]persistence.Task, error) {
 
 			// compare the current last write version first
@@ -1132,7 +1132,9 @@ func (r *historyReplicator) garbageCollectSignals(context workflowExecutionConte
 			if _, err := msBuilder.AddWorkflowExecutionSignaled(
 				attr.GetSignalName(),
 				attr.Input,
-				attr.GetIdentity()); err != nil {
+				attr.GetIdentity(),
+				attr.Header,
+				attr.GetExpirationTimestamp()); err != nil {
 				return false, &workflow.InternalServiceError{Message: "Unable to signal workflow execution."}
 			}
 		}
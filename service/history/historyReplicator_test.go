@@ -135,6 +135,7 @@ func (s *historyReplicatorSuite) SetupTest() {
 		logger:             s.logger,
 		tokenSerializer:    common.NewJSONTaskTokenSerializer(),
 		metricsClient:      s.mockShard.GetMetricsClient(),
+		config:             s.mockShard.GetConfig(),
 		txProcessor:        s.mockTxProcessor,
 		timerProcessor:     s.mockTimerProcessor,
 	}
@@ -1121,6 +1122,7 @@ func (s *historyReplicatorSuite) TestApplyOtherEventsVersionChecking_IncomingLes
 	signalName := "some random signal name"
 	signalInput := []byte("some random signal input")
 	signalIdentity := "some random signal identity"
+	signalHeader := &shared.Header{Fields: map[string][]byte{"some random key": []byte("some random value")}}
 
 	context := &mockWorkflowExecutionContext{}
 	defer context.AssertExpectations(s.T())
@@ -1136,6 +1138,7 @@ func (s *historyReplicatorSuite) TestApplyOtherEventsVersionChecking_IncomingLes
 					SignalName: common.StringPtr(signalName),
 					Input:      signalInput,
 					Identity:   common.StringPtr(signalIdentity),
+					Header:     signalHeader,
 				},
 			},
 		}},
@@ -1144,7 +1147,7 @@ func (s *historyReplicatorSuite) TestApplyOtherEventsVersionChecking_IncomingLes
 	msBuilderIn.On("GetLastWriteVersion").Return(currentLastWriteVersion)
 	msBuilderIn.On("IsWorkflowExecutionRunning").Return(true)
 	msBuilderIn.On("UpdateReplicationStateVersion", currentLastWriteVersion, true).Once()
-	msBuilderIn.On("AddWorkflowExecutionSignaled", signalName, signalInput, signalIdentity).Return(&shared.HistoryEvent{
+	msBuilderIn.On("AddWorkflowExecutionSignaled", signalName, signalInput, signalIdentity, signalHeader, int64(0)).Return(&shared.HistoryEvent{
 		EventType: shared.EventTypeWorkflowExecutionSignaled.Ptr(),
 		Timestamp: common.Int64Ptr(time.Now().UnixNano()),
 		WorkflowExecutionSignaledEventAttributes: &shared.WorkflowExecutionSignaledEventAttributes{
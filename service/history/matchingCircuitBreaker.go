@@ -0,0 +1,192 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	m "github.com/uber/cadence/.gen/go/matching"
+	workflow "github.com/uber/cadence/.gen/go/shared"
+	"github.com/uber/cadence/client/matching"
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/service/dynamicconfig"
+	"go.uber.org/yarpc"
+)
+
+const (
+	matchingCircuitBreakerStateClosed matchingCircuitBreakerState = iota
+	matchingCircuitBreakerStateHalfOpen
+	matchingCircuitBreakerStateOpen
+)
+
+type (
+	matchingCircuitBreakerState int
+
+	// matchingCircuitBreaker stops the transfer queue from dispatching tasks to matching
+	// after too many consecutive failures, and lets a single probe request through once
+	// the open duration has elapsed to decide whether matching has recovered.
+	matchingCircuitBreaker struct {
+		sync.Mutex
+		state               matchingCircuitBreakerState
+		consecutiveFailures int
+		openedAt            time.Time
+		probeInFlight       bool
+
+		threshold    dynamicconfig.IntPropertyFn
+		openDuration dynamicconfig.DurationPropertyFn
+		timeSource   clock.TimeSource
+		metricsScope metrics.Scope
+	}
+
+	// matchingCircuitBreakerClient guards the task dispatch calls of the matching client with a circuit breaker
+	matchingCircuitBreakerClient struct {
+		matching.Client
+		breaker *matchingCircuitBreaker
+	}
+)
+
+var _ matching.Client = (*matchingCircuitBreakerClient)(nil)
+
+func newMatchingCircuitBreaker(shardID int, config *Config, timeSource clock.TimeSource,
+	metricsClient metrics.Client) *matchingCircuitBreaker {
+	b := &matchingCircuitBreaker{
+		state:        matchingCircuitBreakerStateClosed,
+		threshold:    config.TransferProcessorMatchingCircuitBreakerThreshold,
+		openDuration: config.TransferProcessorMatchingCircuitBreakerOpenDuration,
+		timeSource:   timeSource,
+		metricsScope: metricsClient.Scope(metrics.TransferQueueProcessorScope, metrics.ShardTag(strconv.Itoa(shardID))),
+	}
+	b.metricsScope.UpdateGauge(metrics.MatchingCircuitBreakerStateGauge, float64(b.state))
+	return b
+}
+
+func newMatchingCircuitBreakerClient(client matching.Client, breaker *matchingCircuitBreaker) matching.Client {
+	return &matchingCircuitBreakerClient{
+		Client:  client,
+		breaker: breaker,
+	}
+}
+
+func (c *matchingCircuitBreakerClient) AddActivityTask(
+	ctx context.Context,
+	addRequest *m.AddActivityTaskRequest,
+	opts ...yarpc.CallOption) error {
+	if !c.breaker.allow() {
+		return ErrMatchingCircuitBreakerOpen
+	}
+	err := c.Client.AddActivityTask(ctx, addRequest, opts...)
+	c.breaker.record(err)
+	return err
+}
+
+func (c *matchingCircuitBreakerClient) AddDecisionTask(
+	ctx context.Context,
+	addRequest *m.AddDecisionTaskRequest,
+	opts ...yarpc.CallOption) error {
+	if !c.breaker.allow() {
+		return ErrMatchingCircuitBreakerOpen
+	}
+	err := c.Client.AddDecisionTask(ctx, addRequest, opts...)
+	c.breaker.record(err)
+	return err
+}
+
+// allow returns whether a request to matching can be made
+func (b *matchingCircuitBreaker) allow() bool {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.threshold() <= 0 {
+		b.reset()
+		return true
+	}
+
+	switch b.state {
+	case matchingCircuitBreakerStateOpen:
+		if b.timeSource.Now().Sub(b.openedAt) < b.openDuration() {
+			return false
+		}
+		b.setState(matchingCircuitBreakerStateHalfOpen)
+		b.probeInFlight = true
+		return true
+	case matchingCircuitBreakerStateHalfOpen:
+		if b.probeInFlight {
+			return false
+		}
+		b.probeInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the result of a request to matching
+func (b *matchingCircuitBreaker) record(err error) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.probeInFlight = false
+	if !isMatchingUnhealthyError(err) {
+		b.reset()
+		return
+	}
+
+	b.consecutiveFailures++
+	threshold := b.threshold()
+	if threshold <= 0 {
+		return
+	}
+	if b.state == matchingCircuitBreakerStateHalfOpen || b.consecutiveFailures >= threshold {
+		b.openedAt = b.timeSource.Now()
+		b.setState(matchingCircuitBreakerStateOpen)
+	}
+}
+
+func (b *matchingCircuitBreaker) reset() {
+	b.consecutiveFailures = 0
+	b.probeInFlight = false
+	b.setState(matchingCircuitBreakerStateClosed)
+}
+
+func (b *matchingCircuitBreaker) setState(state matchingCircuitBreakerState) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	b.metricsScope.UpdateGauge(metrics.MatchingCircuitBreakerStateGauge, float64(state))
+}
+
+// isMatchingUnhealthyError returns whether the error indicates matching itself is not healthy,
+// as opposed to errors caused by the request being dispatched
+func isMatchingUnhealthyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch err.(type) {
+	case *workflow.EntityNotExistsError, *workflow.BadRequestError, *workflow.DomainNotActiveError:
+		return false
+	}
+	return true
+}
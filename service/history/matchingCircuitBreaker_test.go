@@ -0,0 +1,153 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	m "github.com/uber/cadence/.gen/go/matching"
+	workflow "github.com/uber/cadence/.gen/go/shared"
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/mocks"
+	"github.com/uber/cadence/common/service/dynamicconfig"
+)
+
+type (
+	matchingCircuitBreakerSuite struct {
+		suite.Suite
+		// override suite.Suite.Assertions with require.Assertions; this means that s.NotNil(nil) will stop the test,
+		// not merely log an error
+		*require.Assertions
+
+		config             *Config
+		timeSource         *clock.EventTimeSource
+		mockMatchingClient *mocks.MatchingClient
+		breaker            *matchingCircuitBreaker
+		client             *matchingCircuitBreakerClient
+	}
+)
+
+func TestMatchingCircuitBreakerSuite(t *testing.T) {
+	s := new(matchingCircuitBreakerSuite)
+	suite.Run(t, s)
+}
+
+func (s *matchingCircuitBreakerSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+
+	s.config = NewDynamicConfigForTest()
+	s.config.TransferProcessorMatchingCircuitBreakerThreshold = dynamicconfig.GetIntPropertyFn(3)
+	s.config.TransferProcessorMatchingCircuitBreakerOpenDuration = dynamicconfig.GetDurationPropertyFn(time.Second)
+	s.timeSource = clock.NewEventTimeSource().Update(time.Now())
+	s.mockMatchingClient = &mocks.MatchingClient{}
+	s.breaker = newMatchingCircuitBreaker(1, s.config, s.timeSource, metrics.NewClient(tally.NoopScope, metrics.History))
+	s.client = newMatchingCircuitBreakerClient(s.mockMatchingClient, s.breaker).(*matchingCircuitBreakerClient)
+}
+
+func (s *matchingCircuitBreakerSuite) TearDownTest() {
+	s.mockMatchingClient.AssertExpectations(s.T())
+}
+
+func (s *matchingCircuitBreakerSuite) TestOpenAfterConsecutiveFailures() {
+	matchingErr := &workflow.InternalServiceError{Message: "matching unavailable"}
+	s.mockMatchingClient.On("AddDecisionTask", mock.Anything, mock.Anything).Return(matchingErr).Times(3)
+
+	for i := 0; i < 3; i++ {
+		s.Equal(matchingErr, s.client.AddDecisionTask(context.Background(), &m.AddDecisionTaskRequest{}))
+	}
+	s.Equal(matchingCircuitBreakerStateOpen, s.breaker.state)
+
+	err := s.client.AddActivityTask(context.Background(), &m.AddActivityTaskRequest{})
+	s.Equal(ErrMatchingCircuitBreakerOpen, err)
+}
+
+func (s *matchingCircuitBreakerSuite) TestSuccessResetsFailures() {
+	matchingErr := errors.New("matching unavailable")
+	s.mockMatchingClient.On("AddDecisionTask", mock.Anything, mock.Anything).Return(matchingErr).Times(2)
+	s.mockMatchingClient.On("AddActivityTask", mock.Anything, mock.Anything).Return(nil).Once()
+
+	s.client.AddDecisionTask(context.Background(), &m.AddDecisionTaskRequest{})
+	s.client.AddDecisionTask(context.Background(), &m.AddDecisionTaskRequest{})
+	s.NoError(s.client.AddActivityTask(context.Background(), &m.AddActivityTaskRequest{}))
+	s.Equal(matchingCircuitBreakerStateClosed, s.breaker.state)
+	s.Equal(0, s.breaker.consecutiveFailures)
+}
+
+func (s *matchingCircuitBreakerSuite) TestRequestErrorsNotCounted() {
+	s.mockMatchingClient.On("AddDecisionTask", mock.Anything, mock.Anything).Return(&workflow.EntityNotExistsError{}).Times(3)
+	s.mockMatchingClient.On("AddActivityTask", mock.Anything, mock.Anything).Return(&workflow.BadRequestError{}).Times(3)
+
+	for i := 0; i < 3; i++ {
+		s.client.AddDecisionTask(context.Background(), &m.AddDecisionTaskRequest{})
+		s.client.AddActivityTask(context.Background(), &m.AddActivityTaskRequest{})
+	}
+	s.Equal(matchingCircuitBreakerStateClosed, s.breaker.state)
+}
+
+func (s *matchingCircuitBreakerSuite) TestHalfOpenProbeSuccess() {
+	s.openBreaker()
+	s.mockMatchingClient.On("AddDecisionTask", mock.Anything, mock.Anything).Return(nil).Once()
+
+	s.timeSource.Update(s.timeSource.Now().Add(time.Second))
+	s.True(s.breaker.allow())
+	s.Equal(matchingCircuitBreakerStateHalfOpen, s.breaker.state)
+	// only a single probe is allowed while half open
+	s.False(s.breaker.allow())
+	s.breaker.record(nil)
+	s.Equal(matchingCircuitBreakerStateClosed, s.breaker.state)
+
+	s.NoError(s.client.AddDecisionTask(context.Background(), &m.AddDecisionTaskRequest{}))
+}
+
+func (s *matchingCircuitBreakerSuite) TestHalfOpenProbeFailure() {
+	s.openBreaker()
+
+	s.timeSource.Update(s.timeSource.Now().Add(time.Second))
+	s.True(s.breaker.allow())
+	s.breaker.record(errors.New("matching unavailable"))
+	s.Equal(matchingCircuitBreakerStateOpen, s.breaker.state)
+	s.False(s.breaker.allow())
+}
+
+func (s *matchingCircuitBreakerSuite) TestDisabled() {
+	s.openBreaker()
+	s.config.TransferProcessorMatchingCircuitBreakerThreshold = dynamicconfig.GetIntPropertyFn(0)
+	s.breaker.threshold = s.config.TransferProcessorMatchingCircuitBreakerThreshold
+
+	s.True(s.breaker.allow())
+	s.Equal(matchingCircuitBreakerStateClosed, s.breaker.state)
+}
+
+func (s *matchingCircuitBreakerSuite) openBreaker() {
+	for i := 0; i < 3; i++ {
+		s.True(s.breaker.allow())
+		s.breaker.record(errors.New("matching unavailable"))
+	}
+	s.Equal(matchingCircuitBreakerStateOpen, s.breaker.state)
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/uber/cadence/common/errors"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
 	"github.com/uber/cadence/common/persistence"
 )
 
@@ -200,7 +201,10 @@ func (e *mutableStateBuilder) Load(state *persistence.WorkflowMutableState) {
 	e.bufferedEvents = state.BufferedEvents
 	e.bufferedReplicationTasks = state.BufferedReplicationTasks
 	for _, ai := range state.ActivityInfos {
-		e.pendingActivityInfoByActivityID[ai.ActivityID] = ai.ScheduleID
+		// if pending activities share an ID, map the ID to the most recently scheduled one
+		if scheduleID, ok := e.pendingActivityInfoByActivityID[ai.ActivityID]; !ok || ai.ScheduleID > scheduleID {
+			e.pendingActivityInfoByActivityID[ai.ActivityID] = ai.ScheduleID
+		}
 	}
 
 	e.hasBufferedEventsInPersistence = len(e.bufferedEvents) > 0
@@ -356,7 +360,7 @@ func (e *mutableStateBuilder) FlushBufferedEvents() error {
 	if !e.HasInFlightDecisionTask() {
 		// flush persisted buffered events
 		if len(e.bufferedEvents) > 0 {
-			reorderFunc(e.bufferedEvents)
+			reorderFunc(e.dropExpiredSignals(e.bufferedEvents))
 			e.bufferedEvents = nil
 		}
 		if e.hasBufferedEventsInPersistence {
@@ -364,7 +368,7 @@ func (e *mutableStateBuilder) FlushBufferedEvents() error {
 		}
 
 		// flush pending buffered events
-		reorderFunc(e.updateBufferedEvents)
+		reorderFunc(e.dropExpiredSignals(e.updateBufferedEvents))
 		// clear pending buffered events
 		e.updateBufferedEvents = nil
 
@@ -374,7 +378,7 @@ func (e *mutableStateBuilder) FlushBufferedEvents() error {
 		}
 
 		// flush new buffered events that were not saved to persistence yet
-		newCommittedEvents = append(newCommittedEvents, newBufferedEvents...)
+		newCommittedEvents = append(newCommittedEvents, e.dropExpiredSignals(newBufferedEvents)...)
 		newBufferedEvents = nil
 	}
 
@@ -655,6 +659,30 @@ loop:
 	return input[0:nextIndex]
 }
 
+// dropExpiredSignals removes the signals whose ttl passed while they were buffered behind an in-flight decision,
+// the next decision would start after their expiry so they are never applied. The dropped signals do not count
+// toward the signals limit of the execution.
+func (e *mutableStateBuilder) dropExpiredSignals(bufferedEvents []*workflow.HistoryEvent) []*workflow.HistoryEvent {
+	now := e.shard.GetTimeSource().Now().UnixNano()
+	var result []*workflow.HistoryEvent
+	for _, event := range bufferedEvents {
+		if isExpiredSignal(event, now) {
+			e.executionInfo.SignalCount--
+			continue
+		}
+		result = append(result, event)
+	}
+	return result
+}
+
+func isExpiredSignal(event *workflow.HistoryEvent, now int64) bool {
+	if event.GetEventType() != workflow.EventTypeWorkflowExecutionSignaled {
+		return false
+	}
+	expirationTimestamp := event.WorkflowExecutionSignaledEventAttributes.GetExpirationTimestamp()
+	return expirationTimestamp > 0 && expirationTimestamp < now
+}
+
 func (e *mutableStateBuilder) assignEventIDToBufferedEvents() {
 	newCommittedEvents := e.hBuilder.history
 
@@ -840,6 +868,7 @@ func (e *mutableStateBuilder) shouldBufferEvent(eventType workflow.EventType) bo
 		workflow.EventTypeCancelTimerFailed,
 		workflow.EventTypeRequestCancelExternalWorkflowExecutionInitiated,
 		workflow.EventTypeMarkerRecorded,
+		workflow.EventTypeUpsertWorkflowMemo,
 		workflow.EventTypeStartChildWorkflowExecutionInitiated,
 		workflow.EventTypeSignalExternalWorkflowExecutionInitiated:
 		// do not buffer event if event is directly generated from a corresponding decision
@@ -954,7 +983,7 @@ func (e *mutableStateBuilder) GetCronBackoffDuration() time.Duration {
 	if len(info.CronSchedule) == 0 {
 		return backoff.NoBackoff
 	}
-	return backoff.GetBackoffForNextSchedule(info.CronSchedule, time.Now())
+	return backoff.GetBackoffForNextSchedule(info.CronSchedule, e.shard.GetTimeSource().Now())
 }
 
 // GetSignalInfo get details about a signal request that is currently in progress.
@@ -1106,13 +1135,25 @@ func (e *mutableStateBuilder) DeleteActivity(scheduleEventID int64) error {
 	}
 	delete(e.pendingActivityInfoIDs, scheduleEventID)
 
-	_, ok = e.pendingActivityInfoByActivityID[a.ActivityID]
+	scheduleID, ok := e.pendingActivityInfoByActivityID[a.ActivityID]
 	if !ok {
 		errorMsg := fmt.Sprintf("Unable to find activity: %v in mutable state", a.ActivityID)
 		e.logger.Error(errorMsg, tag.ErrorTypeInvalidMutableStateAction)
 		return errors.NewInternalFailureError(errorMsg)
 	}
-	delete(e.pendingActivityInfoByActivityID, a.ActivityID)
+	// the ID may be mapped to another pending activity sharing it, which is still pending
+	if scheduleID == scheduleEventID {
+		delete(e.pendingActivityInfoByActivityID, a.ActivityID)
+		// remap the ID to the most recently scheduled remaining activity sharing it, if any
+		for _, ai := range e.pendingActivityInfoIDs {
+			if ai.ActivityID != a.ActivityID {
+				continue
+			}
+			if remappedID, ok := e.pendingActivityInfoByActivityID[a.ActivityID]; !ok || ai.ScheduleID > remappedID {
+				e.pendingActivityInfoByActivityID[a.ActivityID] = ai.ScheduleID
+			}
+		}
+	}
 
 	e.deleteActivityInfos[scheduleEventID] = struct{}{}
 	return nil
@@ -1138,14 +1179,15 @@ func (e *mutableStateBuilder) DeleteUserTimer(timerID string) {
 
 func (e *mutableStateBuilder) getDecisionInfo() *decisionInfo {
 	return &decisionInfo{
-		Version:            e.executionInfo.DecisionVersion,
-		ScheduleID:         e.executionInfo.DecisionScheduleID,
-		StartedID:          e.executionInfo.DecisionStartedID,
-		RequestID:          e.executionInfo.DecisionRequestID,
-		DecisionTimeout:    e.executionInfo.DecisionTimeout,
-		Attempt:            e.executionInfo.DecisionAttempt,
-		StartedTimestamp:   e.executionInfo.DecisionStartedTimestamp,
-		ScheduledTimestamp: e.executionInfo.DecisionScheduledTimestamp,
+		Version:                    e.executionInfo.DecisionVersion,
+		ScheduleID:                 e.executionInfo.DecisionScheduleID,
+		StartedID:                  e.executionInfo.DecisionStartedID,
+		RequestID:                  e.executionInfo.DecisionRequestID,
+		DecisionTimeout:            e.executionInfo.DecisionTimeout,
+		Attempt:                    e.executionInfo.DecisionAttempt,
+		StartedTimestamp:           e.executionInfo.DecisionStartedTimestamp,
+		ScheduledTimestamp:         e.executionInfo.DecisionScheduledTimestamp,
+		OriginalScheduledTimestamp: e.executionInfo.DecisionOriginalScheduledTimestamp,
 	}
 }
 
@@ -1192,6 +1234,20 @@ func (e *mutableStateBuilder) GetInFlightDecisionTask() (*decisionInfo, bool) {
 	return di, true
 }
 
+// GetBufferedEvents returns the events which are buffered while a decision is in flight and not written to the
+// history yet
+func (e *mutableStateBuilder) GetBufferedEvents() []*workflow.HistoryEvent {
+	var events []*workflow.HistoryEvent
+	events = append(events, e.bufferedEvents...)
+	events = append(events, e.updateBufferedEvents...)
+	for _, event := range e.hBuilder.history {
+		if event.GetEventId() == common.BufferedEventID {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
 func (e *mutableStateBuilder) HasBufferedEvents() bool {
 	if len(e.bufferedEvents) > 0 || len(e.updateBufferedEvents) > 0 {
 		return true
@@ -1206,6 +1262,27 @@ func (e *mutableStateBuilder) HasBufferedEvents() bool {
 	return false
 }
 
+// HasUnhandledBufferedEvents returns whether there are buffered events for the next decision to handle, the signals
+// which expired while buffered are dropped once flushed and are not handled
+func (e *mutableStateBuilder) HasUnhandledBufferedEvents() bool {
+	now := e.shard.GetTimeSource().Now().UnixNano()
+	for _, bufferedEvents := range [][]*workflow.HistoryEvent{e.bufferedEvents, e.updateBufferedEvents} {
+		for _, event := range bufferedEvents {
+			if !isExpiredSignal(event, now) {
+				return true
+			}
+		}
+	}
+
+	for _, event := range e.hBuilder.history {
+		if event.GetEventId() == common.BufferedEventID && !isExpiredSignal(event, now) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (e *mutableStateBuilder) HasBufferedReplicationTasks() bool {
 	if len(e.bufferedReplicationTasks) > 0 || e.updateBufferedReplicationTasks != nil {
 		return true
@@ -1224,6 +1301,7 @@ func (e *mutableStateBuilder) UpdateDecision(di *decisionInfo) {
 	e.executionInfo.DecisionAttempt = di.Attempt
 	e.executionInfo.DecisionStartedTimestamp = di.StartedTimestamp
 	e.executionInfo.DecisionScheduledTimestamp = di.ScheduledTimestamp
+	e.executionInfo.DecisionOriginalScheduledTimestamp = di.OriginalScheduledTimestamp
 
 	e.logger.Debug(fmt.Sprintf("Decision Updated: {Schedule: %v, Started: %v, ID: %v, Timeout: %v, Attempt: %v, Timestamp: %v}",
 		di.ScheduleID, di.StartedID, di.RequestID, di.DecisionTimeout, di.Attempt, di.StartedTimestamp))
@@ -1232,14 +1310,15 @@ func (e *mutableStateBuilder) UpdateDecision(di *decisionInfo) {
 // DeleteDecision deletes a decision task.
 func (e *mutableStateBuilder) DeleteDecision() {
 	emptyDecisionInfo := &decisionInfo{
-		Version:            common.EmptyVersion,
-		ScheduleID:         common.EmptyEventID,
-		StartedID:          common.EmptyEventID,
-		RequestID:          emptyUUID,
-		DecisionTimeout:    0,
-		Attempt:            0,
-		StartedTimestamp:   0,
-		ScheduledTimestamp: 0,
+		Version:                    common.EmptyVersion,
+		ScheduleID:                 common.EmptyEventID,
+		StartedID:                  common.EmptyEventID,
+		RequestID:                  emptyUUID,
+		DecisionTimeout:            0,
+		Attempt:                    0,
+		StartedTimestamp:           0,
+		ScheduledTimestamp:         0,
+		OriginalScheduledTimestamp: 0,
 	}
 	e.UpdateDecision(emptyDecisionInfo)
 }
@@ -1259,6 +1338,7 @@ func (e *mutableStateBuilder) FailDecision(incrementAttempt bool) {
 	if incrementAttempt {
 		failDecisionInfo.Attempt = e.executionInfo.DecisionAttempt + 1
 		failDecisionInfo.ScheduledTimestamp = time.Now().UnixNano()
+		failDecisionInfo.OriginalScheduledTimestamp = e.executionInfo.DecisionOriginalScheduledTimestamp
 	}
 	e.UpdateDecision(failDecisionInfo)
 }
@@ -1315,10 +1395,48 @@ func (e *mutableStateBuilder) AddSignalRequested(requestID string) {
 	}
 	e.pendingSignalRequestedIDs[requestID] = struct{}{} // add requestID to set
 	e.updateSignalRequestedIDs[requestID] = struct{}{}
+	if e.executionInfo.SignalRequestedTimestamps == nil {
+		e.executionInfo.SignalRequestedTimestamps = make(map[string]int64)
+	}
+	e.executionInfo.SignalRequestedTimestamps[requestID] = e.shard.GetTimeSource().Now().UnixNano()
+	e.evictSignalRequested()
+}
+
+// evictSignalRequested bounds the signaled requestId dedup set by evicting the oldest requestId once the set exceeds
+// MaxSignalRequestedIDs, and emits the size of the set. A signal retried with an evicted requestId is no longer
+// deduplicated and is applied again, since eviction is FIFO this only affects very old requestIds. The time each
+// requestId was added is persisted with the execution, requestIds added before it was recorded are evicted first.
+func (e *mutableStateBuilder) evictSignalRequested() {
+	domainEntry, err := e.shard.GetDomainCache().GetDomainByID(e.executionInfo.DomainID)
+	if err != nil {
+		return
+	}
+	domainName := domainEntry.GetInfo().Name
+
+	// persistence deletes at most one signaled requestId per update
+	maxSize := e.config.MaxSignalRequestedIDs(domainName)
+	if maxSize > 0 && len(e.pendingSignalRequestedIDs) > maxSize && e.deleteSignalRequestedID == "" {
+		oldest := ""
+		oldestTimestamp := int64(0)
+		for requestID := range e.pendingSignalRequestedIDs {
+			timestamp := e.executionInfo.SignalRequestedTimestamps[requestID]
+			if oldest == "" || timestamp < oldestTimestamp || (timestamp == oldestTimestamp && requestID < oldest) {
+				oldest = requestID
+				oldestTimestamp = timestamp
+			}
+		}
+		e.DeleteSignalRequested(oldest)
+	}
+
+	e.shard.GetMetricsClient().Scope(
+		metrics.HistorySignalWorkflowExecutionScope,
+		metrics.DomainTag(domainName),
+	).UpdateGauge(metrics.SignalRequestedIDsSize, float64(len(e.pendingSignalRequestedIDs)))
 }
 
 func (e *mutableStateBuilder) DeleteSignalRequested(requestID string) {
 	delete(e.pendingSignalRequestedIDs, requestID)
+	delete(e.executionInfo.SignalRequestedTimestamps, requestID)
 	e.deleteSignalRequestedID = requestID
 }
 
@@ -1351,6 +1469,17 @@ func (e *mutableStateBuilder) addWorkflowExecutionStartedEventForContinueAsNew(
 		decisionTimeout = attributes.GetTaskStartToCloseTimeoutSeconds()
 	}
 
+	memo := attributes.Memo
+	searchAttributes := attributes.SearchAttributes
+	if e.inheritSearchAttributesAndMemoOnContinueAsNew(domainID) {
+		if memo == nil && len(previousExecutionInfo.Memo) > 0 {
+			memo = &workflow.Memo{Fields: previousExecutionInfo.Memo}
+		}
+		if searchAttributes == nil && len(previousExecutionInfo.SearchAttributes) > 0 {
+			searchAttributes = &workflow.SearchAttributes{IndexedFields: previousExecutionInfo.SearchAttributes}
+		}
+	}
+
 	createRequest := &workflow.StartWorkflowExecutionRequest{
 		RequestId:                           common.StringPtr(uuid.New()),
 		Domain:                              common.StringPtr(domainID),
@@ -1363,6 +1492,8 @@ func (e *mutableStateBuilder) addWorkflowExecutionStartedEventForContinueAsNew(
 		Header:                              attributes.Header,
 		RetryPolicy:                         attributes.RetryPolicy,
 		CronSchedule:                        attributes.CronSchedule,
+		Memo:                                memo,
+		SearchAttributes:                    searchAttributes,
 	}
 
 	req := &h.StartWorkflowExecutionRequest{
@@ -1463,6 +1594,7 @@ func (e *mutableStateBuilder) ReplicateWorkflowExecutionStartedEvent(
 	e.executionInfo.WorkflowTypeName = event.WorkflowType.GetName()
 	e.executionInfo.WorkflowTimeout = event.GetExecutionStartToCloseTimeoutSeconds()
 	e.executionInfo.DecisionTimeoutValue = event.GetTaskStartToCloseTimeoutSeconds()
+	e.executionInfo.StartTimestamp = time.Unix(0, startEvent.GetTimestamp())
 
 	e.executionInfo.State = persistence.WorkflowStateCreated
 	e.executionInfo.CloseStatus = persistence.WorkflowCloseStatusNone
@@ -1507,12 +1639,24 @@ func (e *mutableStateBuilder) ReplicateWorkflowExecutionStartedEvent(
 	if event.SearchAttributes != nil {
 		e.executionInfo.SearchAttributes = event.SearchAttributes.GetIndexedFields()
 	}
+	if event.Memo != nil {
+		e.executionInfo.Memo = event.Memo.GetFields()
+	}
 
 	e.writeEventToCache(startEvent)
 	return nil
 }
 
 func (e *mutableStateBuilder) AddDecisionTaskScheduledEvent() (*decisionInfo, error) {
+	// decision timeout should already be set from workflow execution started event
+	return e.AddDecisionTaskScheduledEventWithTimeout(e.executionInfo.DecisionTimeoutValue)
+}
+
+// AddDecisionTaskScheduledEventWithTimeout schedules a decision using the given start to close timeout
+// instead of the decision timeout of the workflow, e.g. for a first decision which needs a longer timeout
+func (e *mutableStateBuilder) AddDecisionTaskScheduledEventWithTimeout(
+	startToCloseTimeoutSeconds int32,
+) (*decisionInfo, error) {
 
 	opTag := tag.WorkflowActionDecisionTaskScheduled
 	if err := e.checkMutability(opTag); err != nil {
@@ -1531,12 +1675,11 @@ func (e *mutableStateBuilder) AddDecisionTaskScheduledEvent() (*decisionInfo, er
 	// since decision is scheduled
 	e.executionInfo.State = persistence.WorkflowStateRunning
 
-	// Tasklist and decision timeout should already be set from workflow execution started event
+	// Tasklist should already be set from workflow execution started event
 	taskList := e.executionInfo.TaskList
 	if e.IsStickyTaskListEnabled() {
 		taskList = e.executionInfo.StickyTaskList
 	}
-	startToCloseTimeoutSeconds := e.executionInfo.DecisionTimeoutValue
 
 	// Flush any buffered events before creating the decision, otherwise it will result in invalid IDs for transient
 	// decision and will cause in timeout processing to not work for transient decisions
@@ -1596,6 +1739,7 @@ func (e *mutableStateBuilder) ReplicateTransientDecisionTaskScheduled() (*decisi
 		ScheduledTimestamp: time.Now().UnixNano(),
 		StartedTimestamp:   0,
 	}
+	di.OriginalScheduledTimestamp = e.getDecisionOriginalScheduledTimestamp(di.Attempt, di.ScheduledTimestamp)
 
 	e.UpdateDecision(di)
 	return di, nil
@@ -1610,21 +1754,31 @@ func (e *mutableStateBuilder) ReplicateDecisionTaskScheduledEvent(
 	timestamp int64,
 ) (*decisionInfo, error) {
 	di := &decisionInfo{
-		Version:            version,
-		ScheduleID:         scheduleID,
-		StartedID:          common.EmptyEventID,
-		RequestID:          emptyUUID,
-		DecisionTimeout:    startToCloseTimeoutSeconds,
-		TaskList:           taskList,
-		Attempt:            attempt,
-		ScheduledTimestamp: timestamp,
-		StartedTimestamp:   0,
+		Version:                    version,
+		ScheduleID:                 scheduleID,
+		StartedID:                  common.EmptyEventID,
+		RequestID:                  emptyUUID,
+		DecisionTimeout:            startToCloseTimeoutSeconds,
+		TaskList:                   taskList,
+		Attempt:                    attempt,
+		ScheduledTimestamp:         timestamp,
+		StartedTimestamp:           0,
+		OriginalScheduledTimestamp: e.getDecisionOriginalScheduledTimestamp(attempt, timestamp),
 	}
 
 	e.UpdateDecision(di)
 	return di, nil
 }
 
+// getDecisionOriginalScheduledTimestamp returns the scheduled time of the first attempt of a decision scheduled at the
+// given attempt and time, retries keep the time recorded when the previous attempt failed
+func (e *mutableStateBuilder) getDecisionOriginalScheduledTimestamp(attempt int64, timestamp int64) int64 {
+	if attempt > 0 && e.executionInfo.DecisionOriginalScheduledTimestamp != 0 {
+		return e.executionInfo.DecisionOriginalScheduledTimestamp
+	}
+	return timestamp
+}
+
 func (e *mutableStateBuilder) AddDecisionTaskStartedEvent(
 	scheduleEventID int64,
 	requestID string,
@@ -1702,14 +1856,15 @@ func (e *mutableStateBuilder) ReplicateDecisionTaskStartedEvent(
 	e.executionInfo.State = persistence.WorkflowStateRunning
 	// Update mutable decision state
 	di = &decisionInfo{
-		Version:            version,
-		ScheduleID:         scheduleID,
-		StartedID:          startedID,
-		RequestID:          requestID,
-		DecisionTimeout:    di.DecisionTimeout,
-		Attempt:            di.Attempt,
-		StartedTimestamp:   timestamp,
-		ScheduledTimestamp: di.ScheduledTimestamp,
+		Version:                    version,
+		ScheduleID:                 scheduleID,
+		StartedID:                  startedID,
+		RequestID:                  requestID,
+		DecisionTimeout:            di.DecisionTimeout,
+		Attempt:                    di.Attempt,
+		StartedTimestamp:           timestamp,
+		ScheduledTimestamp:         di.ScheduledTimestamp,
+		OriginalScheduledTimestamp: di.OriginalScheduledTimestamp,
 	}
 
 	e.UpdateDecision(di)
@@ -1747,6 +1902,10 @@ func (e *mutableStateBuilder) addBinaryCheckSumIfNotExists(
 	event *workflow.HistoryEvent,
 	maxResetPoints int,
 ) {
+	if maxResetPoints <= 0 {
+		// reset points are disabled for the domain
+		return
+	}
 	binChecksum := event.GetDecisionTaskCompletedEventAttributes().GetBinaryChecksum()
 	if len(binChecksum) == 0 {
 		return
@@ -2406,6 +2565,7 @@ func (e *mutableStateBuilder) ReplicateWorkflowExecutionCompletedEvent(
 	e.executionInfo.State = persistence.WorkflowStateCompleted
 	e.executionInfo.CloseStatus = persistence.WorkflowCloseStatusCompleted
 	e.executionInfo.CompletionEventBatchID = firstEventID // Used when completion event needs to be loaded from database
+	e.executionInfo.CloseTimestamp = event.GetTimestamp()
 	e.writeEventToCache(event)
 	return nil
 }
@@ -2435,6 +2595,7 @@ func (e *mutableStateBuilder) ReplicateWorkflowExecutionFailedEvent(
 	e.executionInfo.State = persistence.WorkflowStateCompleted
 	e.executionInfo.CloseStatus = persistence.WorkflowCloseStatusFailed
 	e.executionInfo.CompletionEventBatchID = firstEventID // Used when completion event needs to be loaded from database
+	e.executionInfo.CloseTimestamp = event.GetTimestamp()
 	e.writeEventToCache(event)
 	return nil
 }
@@ -2461,6 +2622,7 @@ func (e *mutableStateBuilder) ReplicateWorkflowExecutionTimedoutEvent(
 	e.executionInfo.State = persistence.WorkflowStateCompleted
 	e.executionInfo.CloseStatus = persistence.WorkflowCloseStatusTimedOut
 	e.executionInfo.CompletionEventBatchID = firstEventID // Used when completion event needs to be loaded from database
+	e.executionInfo.CloseTimestamp = event.GetTimestamp()
 	e.writeEventToCache(event)
 	return nil
 }
@@ -2525,6 +2687,7 @@ func (e *mutableStateBuilder) ReplicateWorkflowExecutionCanceledEvent(
 	e.executionInfo.State = persistence.WorkflowStateCompleted
 	e.executionInfo.CloseStatus = persistence.WorkflowCloseStatusCanceled
 	e.executionInfo.CompletionEventBatchID = firstEventID // Used when completion event needs to be loaded from database
+	e.executionInfo.CloseTimestamp = event.GetTimestamp()
 	e.writeEventToCache(event)
 	return nil
 }
@@ -2929,6 +3092,50 @@ func (e *mutableStateBuilder) AddRecordMarkerEvent(
 	return e.hBuilder.AddMarkerRecordedEvent(decisionCompletedEventID, attributes), nil
 }
 
+func (e *mutableStateBuilder) AddUpsertWorkflowMemoEvent(
+	decisionCompletedEventID int64,
+	attributes *workflow.UpsertWorkflowMemoDecisionAttributes,
+) (*workflow.HistoryEvent, error) {
+
+	opTag := tag.WorkflowActionUpsertWorkflowMemo
+	if err := e.checkMutability(opTag); err != nil {
+		return nil, err
+	}
+
+	event := e.hBuilder.AddUpsertWorkflowMemoEvent(decisionCompletedEventID, attributes)
+	if err := e.ReplicateUpsertWorkflowMemoEvent(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (e *mutableStateBuilder) ReplicateUpsertWorkflowMemoEvent(
+	event *workflow.HistoryEvent,
+) error {
+
+	memo := e.executionInfo.Memo
+	if memo == nil {
+		// workflows started before the memo was kept in execution info only have it in their start event
+		startEvent, ok := e.GetStartEvent()
+		if !ok {
+			return &workflow.InternalServiceError{Message: "Unable to load workflow start event."}
+		}
+		memo = startEvent.WorkflowExecutionStartedEventAttributes.Memo.GetFields()
+	}
+
+	// upserted fields override the existing ones with the same key
+	upsertFields := event.UpsertWorkflowMemoEventAttributes.Memo.GetFields()
+	newMemo := make(map[string][]byte, len(memo)+len(upsertFields))
+	for k, v := range memo {
+		newMemo[k] = v
+	}
+	for k, v := range upsertFields {
+		newMemo[k] = v
+	}
+	e.executionInfo.Memo = newMemo
+	return nil
+}
+
 func (e *mutableStateBuilder) AddWorkflowExecutionTerminatedEvent(
 	reason string, details []byte, identity string,
 ) (*workflow.HistoryEvent, error) {
@@ -2953,6 +3160,7 @@ func (e *mutableStateBuilder) ReplicateWorkflowExecutionTerminatedEvent(
 	e.executionInfo.State = persistence.WorkflowStateCompleted
 	e.executionInfo.CloseStatus = persistence.WorkflowCloseStatusTerminated
 	e.executionInfo.CompletionEventBatchID = firstEventID // Used when completion event needs to be loaded from database
+	e.executionInfo.CloseTimestamp = event.GetTimestamp()
 	e.writeEventToCache(event)
 	return nil
 }
@@ -2961,6 +3169,8 @@ func (e *mutableStateBuilder) AddWorkflowExecutionSignaled(
 	signalName string,
 	input []byte,
 	identity string,
+	header *workflow.Header,
+	expirationTimestamp int64,
 ) (*workflow.HistoryEvent, error) {
 
 	opTag := tag.WorkflowActionWorkflowSignaled
@@ -2968,7 +3178,7 @@ func (e *mutableStateBuilder) AddWorkflowExecutionSignaled(
 		return nil, err
 	}
 
-	event := e.hBuilder.AddWorkflowExecutionSignaledEvent(signalName, input, identity)
+	event := e.hBuilder.AddWorkflowExecutionSignaledEvent(signalName, input, identity, header, expirationTimestamp)
 	if err := e.ReplicateWorkflowExecutionSignaled(event); err != nil {
 		return nil, err
 	}
@@ -2981,6 +3191,31 @@ func (e *mutableStateBuilder) ReplicateWorkflowExecutionSignaled(event *workflow
 	return nil
 }
 
+func (e *mutableStateBuilder) AddWorkflowExecutionTimeoutExtendedEvent(
+	timeoutSeconds int32,
+	identity string,
+) (*workflow.HistoryEvent, error) {
+
+	opTag := tag.WorkflowActionWorkflowTimeoutExtend
+	if err := e.checkMutability(opTag); err != nil {
+		return nil, err
+	}
+
+	event := e.hBuilder.AddWorkflowExecutionTimeoutExtendedEvent(timeoutSeconds, identity)
+	if err := e.ReplicateWorkflowExecutionTimeoutExtendedEvent(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (e *mutableStateBuilder) ReplicateWorkflowExecutionTimeoutExtendedEvent(
+	event *workflow.HistoryEvent,
+) error {
+
+	e.executionInfo.WorkflowTimeout = event.WorkflowExecutionTimeoutExtendedEventAttributes.GetExecutionStartToCloseTimeoutSeconds()
+	return nil
+}
+
 func (e *mutableStateBuilder) AddContinueAsNewEvent(
 	firstEventID int64,
 	decisionCompletedEventID int64,
@@ -3120,6 +3355,7 @@ func (e *mutableStateBuilder) ReplicateWorkflowExecutionContinuedAsNewEvent(
 	e.executionInfo.State = persistence.WorkflowStateCompleted
 	e.executionInfo.CloseStatus = persistence.WorkflowCloseStatusContinuedAsNew
 	e.executionInfo.CompletionEventBatchID = firstEventID // Used when completion event needs to be loaded from database
+	e.executionInfo.CloseTimestamp = continueAsNewEvent.GetTimestamp()
 	e.writeEventToCache(continueAsNewEvent)
 
 	parentDomainID := ""
@@ -3165,6 +3401,8 @@ func (e *mutableStateBuilder) ReplicateWorkflowExecutionContinuedAsNewEvent(
 		EventStoreVersion:       newStateBuilder.GetEventStoreVersion(),
 		BranchToken:             newStateBuilder.GetCurrentBranch(),
 		PreviousAutoResetPoints: rolloverAutoResetPointsWithExpiringTime(startedAttributes.GetPrevAutoResetPoints(), prevRunID, continueAsNewEvent.GetTimestamp(), domainRetentionDays),
+		SearchAttributes:        newExecutionInfo.SearchAttributes,
+		Memo:                    newExecutionInfo.Memo,
 	}
 
 	if continueAsNew.HasRetryPolicy {
@@ -3604,8 +3842,15 @@ func (e *mutableStateBuilder) CreateActivityRetryTimer(
 	failureReason string,
 ) persistence.Task {
 
+	if e.isGlobalNonRetryableActivityError(failureReason) {
+		return nil
+	}
+
 	retryTask := prepareActivityNextRetry(e.GetCurrentVersion(), ai, failureReason)
 	if retryTask != nil {
+		if !e.keepHeartbeatDetailsOnRetry() {
+			ai.Details = nil
+		}
 		e.updateActivityInfos[ai] = struct{}{}
 		e.syncActivityTasks[ai.ScheduleID] = struct{}{}
 	}
@@ -3613,6 +3858,36 @@ func (e *mutableStateBuilder) CreateActivityRetryTimer(
 	return retryTask
 }
 
+// keepHeartbeatDetailsOnRetry returns whether the next attempt of an activity should see the heartbeat details of
+// the failed attempt, the details are kept if the domain cannot be looked up as that is the historical behavior
+func (e *mutableStateBuilder) keepHeartbeatDetailsOnRetry() bool {
+	domainEntry, err := e.shard.GetDomainCache().GetDomainByID(e.executionInfo.DomainID)
+	if err != nil {
+		return true
+	}
+	return e.config.ActivityRetryKeepHeartbeatDetails(domainEntry.GetInfo().Name)
+}
+
+// inheritSearchAttributesAndMemoOnContinueAsNew returns whether a run continued as new in the domain starts with the
+// search attributes and memo of the previous run, they are inherited if the domain cannot be looked up
+func (e *mutableStateBuilder) inheritSearchAttributesAndMemoOnContinueAsNew(domainID string) bool {
+	domainEntry, err := e.shard.GetDomainCache().GetDomainByID(domainID)
+	if err != nil {
+		return true
+	}
+	return e.config.ContinueAsNewInheritSearchAttributesAndMemo(domainEntry.GetInfo().Name)
+}
+
+// isGlobalNonRetryableActivityError returns whether the domain never retries activities failing with failureReason,
+// the failure is retried as usual if the domain cannot be looked up
+func (e *mutableStateBuilder) isGlobalNonRetryableActivityError(failureReason string) bool {
+	domainEntry, err := e.shard.GetDomainCache().GetDomainByID(e.executionInfo.DomainID)
+	if err != nil {
+		return false
+	}
+	return isGlobalNonRetryableActivityError(e.config.GlobalNonRetryableActivityErrors(domainEntry.GetInfo().Name), failureReason)
+}
+
 func (e *mutableStateBuilder) GetContinueAsNew() *persistence.CreateWorkflowExecutionRequest {
 	return e.continueAsNew
 }
@@ -188,6 +188,7 @@ func (s *mutableStateSuite) TestShouldBufferEvent() {
 		workflow.EventTypeCancelTimerFailed:                               true,
 		workflow.EventTypeRequestCancelExternalWorkflowExecutionInitiated: true,
 		workflow.EventTypeMarkerRecorded:                                  true,
+		workflow.EventTypeUpsertWorkflowMemo:                              true,
 		workflow.EventTypeStartChildWorkflowExecutionInitiated:            true,
 		workflow.EventTypeSignalExternalWorkflowExecutionInitiated:        true,
 	}
@@ -325,6 +326,154 @@ func (s *mutableStateSuite) TestReorderEvents() {
 	s.Equal(int64(5), s.msBuilder.hBuilder.history[1].ActivityTaskCompletedEventAttributes.GetScheduledEventId())
 }
 
+func (s *mutableStateSuite) TestFlushBufferedEventsDropsExpiredSignals() {
+	domainID := validDomainID
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	tl := "testTaskList"
+
+	info := &persistence.WorkflowExecutionInfo{
+		DomainID:             domainID,
+		WorkflowID:           we.GetWorkflowId(),
+		RunID:                we.GetRunId(),
+		TaskList:             tl,
+		WorkflowTypeName:     "wType",
+		WorkflowTimeout:      200,
+		DecisionTimeoutValue: 100,
+		State:                persistence.WorkflowStateRunning,
+		CloseStatus:          persistence.WorkflowCloseStatusNone,
+		NextEventID:          int64(8),
+		LastProcessedEvent:   int64(3),
+		LastUpdatedTimestamp: time.Now(),
+		DecisionVersion:      common.EmptyVersion,
+		DecisionScheduleID:   common.EmptyEventID,
+		DecisionStartedID:    common.EmptyEventID,
+		DecisionTimeout:      100,
+		SignalCount:          3,
+	}
+
+	bufferedEvents := []*workflow.HistoryEvent{
+		&workflow.HistoryEvent{
+			EventId:   common.Int64Ptr(common.BufferedEventID),
+			EventType: workflow.EventTypeWorkflowExecutionSignaled.Ptr(),
+			Version:   common.Int64Ptr(1),
+			WorkflowExecutionSignaledEventAttributes: &workflow.WorkflowExecutionSignaledEventAttributes{
+				SignalName:          common.StringPtr("expired"),
+				ExpirationTimestamp: common.Int64Ptr(time.Now().Add(-time.Minute).UnixNano()),
+			},
+		},
+		&workflow.HistoryEvent{
+			EventId:   common.Int64Ptr(common.BufferedEventID),
+			EventType: workflow.EventTypeWorkflowExecutionSignaled.Ptr(),
+			Version:   common.Int64Ptr(1),
+			WorkflowExecutionSignaledEventAttributes: &workflow.WorkflowExecutionSignaledEventAttributes{
+				SignalName:          common.StringPtr("not-expired"),
+				ExpirationTimestamp: common.Int64Ptr(time.Now().Add(time.Minute).UnixNano()),
+			},
+		},
+		&workflow.HistoryEvent{
+			EventId:   common.Int64Ptr(common.BufferedEventID),
+			EventType: workflow.EventTypeWorkflowExecutionSignaled.Ptr(),
+			Version:   common.Int64Ptr(1),
+			WorkflowExecutionSignaledEventAttributes: &workflow.WorkflowExecutionSignaledEventAttributes{
+				SignalName: common.StringPtr("no-ttl"),
+			},
+		},
+	}
+
+	replicationState := &persistence.ReplicationState{
+		StartVersion:        int64(1),
+		CurrentVersion:      int64(1),
+		LastWriteVersion:    common.EmptyVersion,
+		LastWriteEventID:    common.EmptyEventID,
+		LastReplicationInfo: make(map[string]*persistence.ReplicationInfo),
+	}
+
+	dbState := &persistence.WorkflowMutableState{
+		ExecutionInfo:    info,
+		BufferedEvents:   bufferedEvents,
+		ReplicationState: replicationState,
+	}
+
+	s.msBuilder.Load(dbState)
+	s.Equal(3, len(s.msBuilder.bufferedEvents))
+
+	err := s.msBuilder.FlushBufferedEvents()
+	s.Nil(err)
+	s.Equal(2, len(s.msBuilder.hBuilder.history))
+	s.Equal("not-expired", s.msBuilder.hBuilder.history[0].WorkflowExecutionSignaledEventAttributes.GetSignalName())
+	s.Equal(int64(8), s.msBuilder.hBuilder.history[0].GetEventId())
+	s.Equal("no-ttl", s.msBuilder.hBuilder.history[1].WorkflowExecutionSignaledEventAttributes.GetSignalName())
+	s.Equal(int64(9), s.msBuilder.hBuilder.history[1].GetEventId())
+	// the dropped signal does not count toward the signals limit
+	s.Equal(int32(2), s.msBuilder.GetExecutionInfo().SignalCount)
+}
+
+func (s *mutableStateSuite) TestHasUnhandledBufferedEventsSkipsExpiredSignals() {
+	expiredSignal := &workflow.HistoryEvent{
+		EventId:   common.Int64Ptr(common.BufferedEventID),
+		EventType: workflow.EventTypeWorkflowExecutionSignaled.Ptr(),
+		WorkflowExecutionSignaledEventAttributes: &workflow.WorkflowExecutionSignaledEventAttributes{
+			SignalName:          common.StringPtr("expired"),
+			ExpirationTimestamp: common.Int64Ptr(time.Now().Add(-time.Minute).UnixNano()),
+		},
+	}
+	signal := &workflow.HistoryEvent{
+		EventId:   common.Int64Ptr(common.BufferedEventID),
+		EventType: workflow.EventTypeWorkflowExecutionSignaled.Ptr(),
+		WorkflowExecutionSignaledEventAttributes: &workflow.WorkflowExecutionSignaledEventAttributes{
+			SignalName: common.StringPtr("no-ttl"),
+		},
+	}
+
+	// only expired signals are buffered, no decision is needed to handle them
+	s.msBuilder.Load(&persistence.WorkflowMutableState{
+		ExecutionInfo:  &persistence.WorkflowExecutionInfo{},
+		BufferedEvents: []*workflow.HistoryEvent{expiredSignal},
+	})
+	s.True(s.msBuilder.HasBufferedEvents())
+	s.False(s.msBuilder.HasUnhandledBufferedEvents())
+
+	s.msBuilder.Load(&persistence.WorkflowMutableState{
+		ExecutionInfo:  &persistence.WorkflowExecutionInfo{},
+		BufferedEvents: []*workflow.HistoryEvent{expiredSignal, signal},
+	})
+	s.True(s.msBuilder.HasUnhandledBufferedEvents())
+}
+
+func (s *mutableStateSuite) TestDeleteActivityRemapsSharedActivityID() {
+	activityID := "activity1_id"
+	s.msBuilder.Load(&persistence.WorkflowMutableState{
+		ExecutionInfo: &persistence.WorkflowExecutionInfo{},
+		ActivityInfos: map[int64]*persistence.ActivityInfo{
+			5:  {ScheduleID: 5, ActivityID: activityID},
+			10: {ScheduleID: 10, ActivityID: activityID},
+			15: {ScheduleID: 15, ActivityID: "activity2_id"},
+		},
+	})
+	scheduleID, ok := s.msBuilder.GetScheduleIDByActivityID(activityID)
+	s.True(ok)
+	s.Equal(int64(10), scheduleID)
+
+	// deleting the mapped activity remaps the ID to the remaining one sharing it
+	s.NoError(s.msBuilder.DeleteActivity(10))
+	scheduleID, ok = s.msBuilder.GetScheduleIDByActivityID(activityID)
+	s.True(ok)
+	s.Equal(int64(5), scheduleID)
+	ai, ok := s.msBuilder.GetActivityByActivityID(activityID)
+	s.True(ok)
+	s.Equal(int64(5), ai.ScheduleID)
+
+	s.NoError(s.msBuilder.DeleteActivity(5))
+	_, ok = s.msBuilder.GetScheduleIDByActivityID(activityID)
+	s.False(ok)
+	scheduleID, ok = s.msBuilder.GetScheduleIDByActivityID("activity2_id")
+	s.True(ok)
+	s.Equal(int64(15), scheduleID)
+}
+
 func (s *mutableStateSuite) TestTrimEvents() {
 	var input []*workflow.HistoryEvent
 	output := s.msBuilder.trimEventsAfterWorkflowClose(input)
@@ -157,6 +157,18 @@ func (a *queueAckMgrImpl) getQueueReadLevel() int64 {
 	return a.readLevel
 }
 
+func (a *queueAckMgrImpl) getPendingTaskCount() int64 {
+	a.Lock()
+	defer a.Unlock()
+	count := int64(0)
+	for _, acked := range a.outstandingTasks {
+		if !acked {
+			count++
+		}
+	}
+	return count
+}
+
 func (a *queueAckMgrImpl) getFinishedChan() <-chan struct{} {
 	return a.finishedChan
 }
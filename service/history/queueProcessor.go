@@ -147,6 +147,19 @@ func (p *queueProcessorBase) notifyNewTask() {
 	}
 }
 
+func (p *queueProcessorBase) describeStatus() *QueueProcessorStatus {
+	ackLevel := p.ackMgr.getQueueAckLevel()
+	// transfer and replication tasks share the task ID sequence of the shard
+	maxReadLevel := p.shard.GetTransferMaxReadLevel()
+	return &QueueProcessorStatus{
+		AckLevel:     ackLevel,
+		ReadLevel:    p.ackMgr.getQueueReadLevel(),
+		MaxReadLevel: maxReadLevel,
+		Backlog:      common.MaxInt64(maxReadLevel-ackLevel, 0),
+		PendingTasks: p.ackMgr.getPendingTaskCount(),
+	}
+}
+
 func (p *queueProcessorBase) processorPump() {
 	<-time.NewTimer(backoff.NewJitter().JitDuration(p.options.StartDelay(), 0.99)).C
 
@@ -332,6 +345,10 @@ FilterLoop:
 				p.ackTaskOnce(task, scope, shouldProcessTask, startTime, attempt)
 				return
 			}
+			// the task was rejected without being dispatched to matching, this is not an attempt
+			if err == ErrMatchingCircuitBreakerOpen {
+				continue
+			}
 			incAttempt()
 		}
 	}
@@ -370,6 +387,12 @@ func (p *queueProcessorBase) handleTaskError(scope int, startTime time.Time,
 		return err
 	}
 
+	// this is a transient error, backoff and retry the task once matching recovers
+	if err == ErrMatchingCircuitBreakerOpen {
+		p.metricsClient.IncCounter(scope, metrics.TaskMatchingCircuitBreakerOpenCounter)
+		return err
+	}
+
 	if err == ErrTaskDiscarded {
 		p.metricsClient.IncCounter(scope, metrics.TaskDiscarded)
 		err = nil
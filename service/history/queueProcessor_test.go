@@ -29,6 +29,7 @@ import (
 	"github.com/uber-go/tally"
 	workflow "github.com/uber/cadence/.gen/go/shared"
 	"github.com/uber/cadence/client"
+	"github.com/uber/cadence/common/backoff"
 	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/log"
@@ -178,6 +179,32 @@ func (s *queueProcessorSuite) TestProcessTaskAndAck_DomainTrue_ProcessErrNoErr()
 	s.queueProcessor.processTaskAndAck(s.notificationChan, task)
 }
 
+func (s *queueProcessorSuite) TestProcessTaskAndAck_MatchingCircuitBreakerOpen_NotCountedAsAttempt() {
+	testScope := tally.NewTestScope("", nil)
+	s.queueProcessor.metricsClient = metrics.NewClient(testScope, metrics.History)
+	retryPolicy := backoff.NewExponentialRetryPolicy(time.Millisecond)
+	retryPolicy.SetMaximumAttempts(1)
+	s.queueProcessor.retryPolicy = retryPolicy
+
+	task := &persistence.TransferTaskInfo{TaskID: 12345}
+	var taskFilter queueTaskFilter = func(qTask queueTaskInfo) (bool, error) {
+		return true, nil
+	}
+	s.mockProcessor.On("getTaskFilter").Return(taskFilter).Once()
+	s.mockProcessor.On("process", task, true).Return(s.scope, ErrMatchingCircuitBreakerOpen).Twice()
+	s.mockProcessor.On("process", task, true).Return(s.scope, nil).Once()
+	s.mockQueueAckMgr.On("completeQueueTask", task.GetTaskID()).Once()
+	s.queueProcessor.processTaskAndAck(s.notificationChan, task)
+
+	var attempts []time.Duration
+	for _, timer := range testScope.Snapshot().Timers() {
+		if timer.Name() == "task_attempt" {
+			attempts = append(attempts, timer.Values()...)
+		}
+	}
+	s.Equal([]time.Duration{0}, attempts)
+}
+
 func (s *queueProcessorSuite) TestHandleTaskError_EntiryNotExists() {
 	err := &workflow.EntityNotExistsError{}
 	s.Nil(s.queueProcessor.handleTaskError(s.scope, time.Now(), s.notificationChan, err, s.logger))
@@ -23,6 +23,7 @@ package history
 import (
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/uber/cadence/.gen/go/shared"
@@ -36,7 +37,7 @@ func prepareActivityNextRetry(version int64, a *persistence.ActivityInfo, errRea
 }
 
 func prepareActivityNextRetryWithNowTime(version int64, a *persistence.ActivityInfo, errReason string, now time.Time) persistence.Task {
-	if !a.HasRetryPolicy || a.CancelRequested {
+	if !a.HasRetryPolicy || a.CancelRequested || activityRetryBudgetExhausted(a, errReason, now) {
 		return nil
 	}
 
@@ -54,6 +55,7 @@ func prepareActivityNextRetryWithNowTime(version int64, a *persistence.ActivityI
 	a.RequestID = ""
 	a.StartedTime = time.Time{}
 	a.TimerTaskStatus = TimerTaskStatusNone
+	a.LastFailureReason = errReason
 
 	return &persistence.ActivityRetryTimerTask{
 		Version:             a.Version,
@@ -63,11 +65,68 @@ func prepareActivityNextRetryWithNowTime(version int64, a *persistence.ActivityI
 	}
 }
 
+// activityRetryBudgetExhausted returns true if the activity would be retried for errReason, but the next attempt
+// could not be scheduled before the activity expires
+func activityRetryBudgetExhausted(a *persistence.ActivityInfo, errReason string, now time.Time) bool {
+	if !a.HasRetryPolicy || a.CancelRequested || a.ExpirationTime.IsZero() {
+		return false
+	}
+
+	backoffInterval := getRetryInterval(a.Attempt, a.MaximumAttempts, a.InitialInterval, a.MaximumInterval, a.BackoffCoefficient, errReason, a.NonRetriableErrors)
+	if backoffInterval == backoff.NoBackoff {
+		return false
+	}
+	return !now.Add(backoffInterval).Before(a.ExpirationTime)
+}
+
+// isGlobalNonRetryableActivityError returns true if errReason is one of the comma separated reasons a domain never
+// retries
+func isGlobalNonRetryableActivityError(nonRetryableErrors string, errReason string) bool {
+	for _, reason := range strings.Split(nonRetryableErrors, ",") {
+		if reason = strings.TrimSpace(reason); reason != "" && reason == errReason {
+			return true
+		}
+	}
+	return false
+}
+
 func getBackoffInterval(currAttempt, maxAttempts, initInterval, maxInterval int32, backoffCoefficient float64, now, expirationTime time.Time, errReason string, nonRetriableErrors []string) time.Duration {
 	if maxAttempts == 0 && expirationTime.IsZero() {
 		return backoff.NoBackoff
 	}
 
+	backoffInterval := getRetryInterval(currAttempt, maxAttempts, initInterval, maxInterval, backoffCoefficient, errReason, nonRetriableErrors)
+	if backoffInterval == backoff.NoBackoff {
+		return backoff.NoBackoff
+	}
+
+	nextScheduleTime := now.Add(backoffInterval)
+	if !expirationTime.IsZero() && nextScheduleTime.After(expirationTime) {
+		return backoff.NoBackoff
+	}
+
+	return backoffInterval
+}
+
+// getWorkflowRetryBackoff returns the backoff which preceded the given attempt of a retrying workflow. A backoff which
+// cannot be represented, e.g. computed from a coefficient persisted before the retry policy was validated, is 0.
+func getWorkflowRetryBackoff(attempt, initInterval, maxInterval int32, backoffCoefficient float64) time.Duration {
+	backoffSeconds := float64(initInterval) * math.Pow(backoffCoefficient, float64(attempt-1))
+	if math.IsNaN(backoffSeconds) || backoffSeconds < 0 {
+		return 0
+	}
+	if maxInterval > 0 && backoffSeconds > float64(maxInterval) {
+		backoffSeconds = float64(maxInterval)
+	}
+	if backoffSeconds >= float64(math.MaxInt64/int64(time.Second)) {
+		// also covers +Inf, math.Pow() could overflow
+		return 0
+	}
+	return time.Duration(backoffSeconds) * time.Second
+}
+
+// getRetryInterval returns the backoff before the next attempt without taking the expiration time into account
+func getRetryInterval(currAttempt, maxAttempts, initInterval, maxInterval int32, backoffCoefficient float64, errReason string, nonRetriableErrors []string) time.Duration {
 	if maxAttempts > 0 && currAttempt >= maxAttempts-1 {
 		// currAttempt starts from 0.
 		// MaximumAttempts is the total attempts, including initial (non-retry) attempt.
@@ -89,15 +148,10 @@ func getBackoffInterval(currAttempt, maxAttempts, initInterval, maxInterval int3
 		nextInterval = int64(maxInterval)
 	}
 
-	backoffInterval := time.Duration(nextInterval) * time.Second
-	nextScheduleTime := now.Add(backoffInterval)
-	if !expirationTime.IsZero() && nextScheduleTime.After(expirationTime) {
-		return backoff.NoBackoff
-	}
-
 	// make sure we don't retry size exceeded error reasons. Note that FailureReasonFailureDetailsExceedsLimit is retryable.
 	if errReason == common.FailureReasonCancelDetailsExceedsLimit ||
 		errReason == common.FailureReasonCompleteResultExceedsLimit ||
+		errReason == common.FailureReasonActivityResultSizeExceedsLimit ||
 		errReason == common.FailureReasonHeartbeatExceedsLimit ||
 		errReason == common.FailureReasonDecisionBlobSizeExceedsLimit {
 		return backoff.NoBackoff
@@ -110,7 +164,7 @@ func getBackoffInterval(currAttempt, maxAttempts, initInterval, maxInterval int3
 		}
 	}
 
-	return backoffInterval
+	return time.Duration(nextInterval) * time.Second
 }
 
 func getTimeoutErrorReason(timeoutType shared.TimeoutType) string {
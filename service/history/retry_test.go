@@ -21,6 +21,7 @@
 package history
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -66,6 +67,7 @@ func Test_NextRetry(t *testing.T) {
 	a.NotNil(retryTask)
 	a.Equal(version, retryTask.GetVersion())
 	a.Equal(now.Add(time.Second), retryTask.(*persistence.ActivityRetryTimerTask).VisibilityTimestamp)
+	a.Equal(reason, ai.LastFailureReason)
 
 	retryTask = prepareActivityNextRetryWithNowTime(version, ai, reason, now)
 	a.NotNil(retryTask)
@@ -102,6 +104,16 @@ func Test_NextRetry(t *testing.T) {
 	ai.ExpirationTime = now.Add(time.Second * 5)
 	retryTask = prepareActivityNextRetryWithNowTime(version, ai, reason, now)
 	a.Nil(retryTask)
+	a.True(activityRetryBudgetExhausted(ai, reason, now))
+
+	// no retry if next interval ends exactly at the expiration time
+	ai.ExpirationTime = now.Add(time.Second * 10)
+	a.True(activityRetryBudgetExhausted(ai, reason, now))
+	retryTask = prepareActivityNextRetryWithNowTime(version, ai, reason, now)
+	a.Nil(retryTask)
+
+	// a non-retriable error does not count as an exhausted retry budget
+	a.False(activityRetryBudgetExhausted(ai, "bad-reason", now))
 
 	// extend expiration, next interval should be 10s
 	version += 10
@@ -117,3 +129,34 @@ func Test_NextRetry(t *testing.T) {
 	retryTask = prepareActivityNextRetryWithNowTime(version, ai, reason, now)
 	a.Equal(now.Add(time.Second*10), retryTask.(*persistence.ActivityRetryTimerTask).VisibilityTimestamp)
 }
+
+func Test_GlobalNonRetryableActivityError(t *testing.T) {
+	a := assert.New(t)
+
+	a.False(isGlobalNonRetryableActivityError("", "some-reason"))
+	a.False(isGlobalNonRetryableActivityError("", ""))
+	a.False(isGlobalNonRetryableActivityError("auth-failed,", ""))
+	a.True(isGlobalNonRetryableActivityError("auth-failed", "auth-failed"))
+	a.True(isGlobalNonRetryableActivityError("bad-input, auth-failed", "auth-failed"))
+	a.False(isGlobalNonRetryableActivityError("bad-input,auth-failed", "auth"))
+}
+
+func Test_WorkflowRetryBackoff(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(time.Second, getWorkflowRetryBackoff(1, 1, 0, 2))
+	a.Equal(4*time.Second, getWorkflowRetryBackoff(3, 1, 0, 2))
+	a.Equal(time.Second, getWorkflowRetryBackoff(3, 1, 0, 1))
+	// capped by the max interval
+	a.Equal(3*time.Second, getWorkflowRetryBackoff(3, 1, 3, 2))
+
+	// coefficients which would never pass the retry policy validation
+	a.Equal(time.Duration(0), getWorkflowRetryBackoff(3, 1, 0, 0))
+	a.Equal(time.Duration(0), getWorkflowRetryBackoff(2, 1, 0, -2))
+	a.Equal(time.Duration(0), getWorkflowRetryBackoff(3, 1, 0, math.NaN()))
+	a.Equal(time.Duration(0), getWorkflowRetryBackoff(3, 1, 0, math.Inf(1)))
+	a.Equal(10*time.Second, getWorkflowRetryBackoff(3, 1, 10, math.Inf(1)))
+
+	// math.Pow() could overflow
+	a.Equal(time.Duration(0), getWorkflowRetryBackoff(2000, 1, 0, 2))
+}
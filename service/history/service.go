@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/definition"
 	"github.com/uber/cadence/common/log/loggerimpl"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/metrics"
@@ -49,6 +50,47 @@ type Config struct {
 	EnableVisibilityToKafka         dynamicconfig.BoolPropertyFn
 	EmitShardDiffLog                dynamicconfig.BoolPropertyFn
 	MaxAutoResetPoints              dynamicconfig.IntPropertyFnWithDomainFilter
+	EnableAutoResetPoints           dynamicconfig.BoolPropertyFnWithDomainFilter
+	EnableWorkflowTimeoutRepair     dynamicconfig.BoolPropertyFnWithDomainFilter
+	ResetWorkflowMaxReplayDuration  dynamicconfig.DurationPropertyFnWithDomainFilter
+	// shard backpressure settings, rejecting new workflow starts while the shard is behind on task processing
+	EnableShardBackpressure         dynamicconfig.BoolPropertyFnWithDomainFilter
+	ShardBackpressureMaxTransferLag dynamicconfig.IntPropertyFnWithDomainFilter
+	ShardBackpressureMaxTimerLag    dynamicconfig.DurationPropertyFnWithDomainFilter
+	// ActivityRetryKeepHeartbeatDetails is whether heartbeat details survive an activity retry
+	ActivityRetryKeepHeartbeatDetails dynamicconfig.BoolPropertyFnWithDomainFilter
+	// HistoryPageSize is the page size of internal history reads
+	HistoryPageSize dynamicconfig.IntPropertyFnWithDomainFilter
+	// CronMinBackoffInterval is the lower bound of the backoff between two runs of a cron workflow
+	CronMinBackoffInterval dynamicconfig.DurationPropertyFnWithDomainFilter
+	// CronMisfireFireImmediately is whether a misfired cron slot is run right away instead of skipped
+	CronMisfireFireImmediately dynamicconfig.BoolPropertyFnWithDomainFilter
+	// DecisionTypeMetricsSampleRate is the fraction of decision task completions emitting per decision type counters
+	DecisionTypeMetricsSampleRate dynamicconfig.FloatPropertyFnWithDomainFilter
+	// GlobalNonRetryableActivityErrors is the comma separated list of activity failure reasons never retried in a domain
+	GlobalNonRetryableActivityErrors dynamicconfig.StringPropertyFnWithDomainFilter
+	// MutableStateExportSizeLimit is the max size of the serialized mutable state returned by ExportMutableState
+	MutableStateExportSizeLimit dynamicconfig.IntPropertyFnWithDomainFilter
+	// DescribeWorkflowLoadCompletionEvent is whether describing a closed workflow eagerly loads its completion event
+	DescribeWorkflowLoadCompletionEvent dynamicconfig.BoolPropertyFnWithDomainFilter
+	// MaxWorkflowExecutionTimeout is the bound of a workflow timeout extended by an operator
+	MaxWorkflowExecutionTimeout dynamicconfig.DurationPropertyFnWithDomainFilter
+	// ContinueAsNewInheritSearchAttributesAndMemo is whether search attributes and memo carry over to the next run
+	ContinueAsNewInheritSearchAttributesAndMemo dynamicconfig.BoolPropertyFnWithDomainFilter
+	// EnableCallerProvidedRunID is whether the run ID on an internal start request is honored
+	EnableCallerProvidedRunID dynamicconfig.BoolPropertyFnWithDomainFilter
+	// ValidSearchAttributes is the registry of search attribute keys accepted on start and continue-as-new
+	ValidSearchAttributes dynamicconfig.MapPropertyFn
+	// MaxSignalRequestedIDs is the max size of the signal request ID dedup set of a workflow
+	MaxSignalRequestedIDs dynamicconfig.IntPropertyFnWithDomainFilter
+	// MaxClusterClockSkew is the max tolerated difference between a remote cluster's shard time and the local time
+	MaxClusterClockSkew dynamicconfig.DurationPropertyFn
+	// DomainDraining is whether a domain rejects new workflows while its existing workflows finish
+	DomainDraining dynamicconfig.BoolPropertyFnWithDomainFilter
+	// ReturnNewDecisionTaskAlwaysSticky is whether a decision task returned on completion is always sticky enabled
+	ReturnNewDecisionTaskAlwaysSticky dynamicconfig.BoolPropertyFnWithDomainFilter
+	// MaxBadBinaries is the max number of bad binaries in a domain config, shared with the frontend
+	MaxBadBinaries dynamicconfig.IntPropertyFnWithDomainFilter
 
 	// HistoryCache settings
 	// Change of these configs require shard restart
@@ -100,6 +142,8 @@ type Config struct {
 	TransferProcessorUpdateAckInterval                  dynamicconfig.DurationPropertyFn
 	TransferProcessorUpdateAckIntervalJitterCoefficient dynamicconfig.FloatPropertyFn
 	TransferProcessorCompleteTransferInterval           dynamicconfig.DurationPropertyFn
+	TransferProcessorMatchingCircuitBreakerThreshold    dynamicconfig.IntPropertyFn
+	TransferProcessorMatchingCircuitBreakerOpenDuration dynamicconfig.DurationPropertyFn
 
 	// ReplicatorQueueProcessor settings
 	ReplicatorTaskBatchSize                               dynamicconfig.IntPropertyFn
@@ -119,6 +163,27 @@ type Config struct {
 	// System Limits
 	MaximumBufferedEventsBatch dynamicconfig.IntPropertyFn
 	MaximumSignalsPerExecution dynamicconfig.IntPropertyFnWithDomainFilter
+	// MaximumSignalsBeforeFirstDecision is the max number of signals a cron workflow accepts while its first
+	// decision waits for the cron schedule, they are all handled by that decision; 0 means unlimited.
+	MaximumSignalsBeforeFirstDecision dynamicconfig.IntPropertyFnWithDomainFilter
+	// MaximumChildWorkflowsPerExecution is the max number of pending child workflows of a single
+	// execution, closed children do not count against it; 0 means unlimited.
+	MaximumChildWorkflowsPerExecution dynamicconfig.IntPropertyFnWithDomainFilter
+	// MaximumDecisionTaskAttempts is the number of consecutive decision task attempts, transient
+	// decisions included, after which the workflow is terminated; 0 means unlimited.
+	// Transient decision attempts are not written to history, so a terminated workflow only
+	// shows the first DecisionTaskFailed event followed by the termination.
+	MaximumDecisionTaskAttempts dynamicconfig.IntPropertyFnWithDomainFilter
+	// MaximumHistoryLength is the number of history events after which the workflow is terminated
+	// when its decision completes; 0 means unlimited.
+	MaximumHistoryLength dynamicconfig.IntPropertyFnWithDomainFilter
+	// EmptyDecisionLoopLimit is the number of consecutive decisions which add no events but force a
+	// new decision after which the workflow is failed; 0 means disabled. The count is kept in the
+	// workflow cache only, so it starts over when the workflow is evicted or the shard moves.
+	EmptyDecisionLoopLimit dynamicconfig.IntPropertyFnWithDomainFilter
+	// EmptyDecisionLoopMinInterval is the time from the start of a decision within which it has to be
+	// completed to count toward EmptyDecisionLoopLimit. It does not affect the decision heartbeat count.
+	EmptyDecisionLoopMinInterval dynamicconfig.DurationPropertyFnWithDomainFilter
 
 	// ShardUpdateMinInterval the minimal time interval which the shard info can be updated
 	ShardUpdateMinInterval dynamicconfig.DurationPropertyFn
@@ -136,6 +201,12 @@ type Config struct {
 
 	NumArchiveSystemWorkflows dynamicconfig.IntPropertyFn
 	ArchiveRequestRPS         dynamicconfig.IntPropertyFn
+	ArchivalOnRequestOnly     dynamicconfig.BoolPropertyFnWithDomainFilter
+	// ArchiveMutableStateSnapshot is whether the mutable state is archived alongside the history, snapshots larger
+	// than ArchiveMutableStateSnapshotSizeLimit are skipped as they ride in the archival request signalled to the
+	// archiver workflow and end up in its history
+	ArchiveMutableStateSnapshot          dynamicconfig.BoolPropertyFnWithDomainFilter
+	ArchiveMutableStateSnapshotSizeLimit dynamicconfig.IntPropertyFnWithDomainFilter
 
 	BlobSizeLimitError     dynamicconfig.IntPropertyFnWithDomainFilter
 	BlobSizeLimitWarn      dynamicconfig.IntPropertyFnWithDomainFilter
@@ -143,6 +214,13 @@ type Config struct {
 	HistorySizeLimitWarn   dynamicconfig.IntPropertyFnWithDomainFilter
 	HistoryCountLimitError dynamicconfig.IntPropertyFnWithDomainFilter
 	HistoryCountLimitWarn  dynamicconfig.IntPropertyFnWithDomainFilter
+	// ActivityTypeBlobSizeLimitError overrides BlobSizeLimitError for the input of the activity types it contains
+	ActivityTypeBlobSizeLimitError dynamicconfig.MapPropertyFn
+
+	// MaxNonRetriableErrorReasonsCount and MaxNonRetriableErrorReasonsLength bound the retry policy
+	// NonRetriableErrorReasons accepted on workflow start and activity schedule
+	MaxNonRetriableErrorReasonsCount  dynamicconfig.IntPropertyFn
+	MaxNonRetriableErrorReasonsLength dynamicconfig.IntPropertyFn
 
 	ThrottledLogRPS dynamicconfig.IntPropertyFn
 }
@@ -163,6 +241,29 @@ func NewConfig(dc *dynamicconfig.Collection, numberOfShards int, enableVisibilit
 		VisibilityOpenMaxQPS:                                  dc.GetIntPropertyFilteredByDomain(dynamicconfig.HistoryVisibilityOpenMaxQPS, 300),
 		VisibilityClosedMaxQPS:                                dc.GetIntPropertyFilteredByDomain(dynamicconfig.HistoryVisibilityClosedMaxQPS, 300),
 		MaxAutoResetPoints:                                    dc.GetIntPropertyFilteredByDomain(dynamicconfig.HistoryMaxAutoResetPoints, defaultHistoryMaxAutoResetPoints),
+		EnableAutoResetPoints:                                 dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.EnableAutoResetPoints, true),
+		EnableWorkflowTimeoutRepair:                           dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.EnableWorkflowTimeoutRepair, false),
+		ResetWorkflowMaxReplayDuration:                        dc.GetDurationPropertyFilteredByDomain(dynamicconfig.ResetWorkflowMaxReplayDuration, 0),
+		EnableShardBackpressure:                               dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.EnableShardBackpressure, false),
+		ShardBackpressureMaxTransferLag:                       dc.GetIntPropertyFilteredByDomain(dynamicconfig.ShardBackpressureMaxTransferLag, 100),
+		ShardBackpressureMaxTimerLag:                          dc.GetDurationPropertyFilteredByDomain(dynamicconfig.ShardBackpressureMaxTimerLag, 10*time.Minute),
+		ActivityRetryKeepHeartbeatDetails:                     dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.ActivityRetryKeepHeartbeatDetails, true),
+		HistoryPageSize:                                       dc.GetIntPropertyFilteredByDomain(dynamicconfig.HistoryPageSize, defaultHistoryPageSize),
+		CronMinBackoffInterval:                                dc.GetDurationPropertyFilteredByDomain(dynamicconfig.CronMinBackoffInterval, 5*time.Second),
+		CronMisfireFireImmediately:                            dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.CronMisfireFireImmediately, false),
+		DecisionTypeMetricsSampleRate:                         dc.GetFloat64PropertyFilteredByDomain(dynamicconfig.DecisionTypeMetricsSampleRate, 1.0),
+		GlobalNonRetryableActivityErrors:                      dc.GetStringPropertyFnWithDomainFilter(dynamicconfig.GlobalNonRetryableActivityErrors, ""),
+		MutableStateExportSizeLimit:                           dc.GetIntPropertyFilteredByDomain(dynamicconfig.MutableStateExportSizeLimit, 16*1024*1024),
+		DescribeWorkflowLoadCompletionEvent:                   dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.DescribeWorkflowLoadCompletionEvent, false),
+		MaxWorkflowExecutionTimeout:                           dc.GetDurationPropertyFilteredByDomain(dynamicconfig.MaxWorkflowExecutionTimeout, 365*24*time.Hour),
+		ContinueAsNewInheritSearchAttributesAndMemo:           dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.ContinueAsNewInheritSearchAttributesAndMemo, true),
+		EnableCallerProvidedRunID:                             dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.EnableCallerProvidedRunID, false),
+		ValidSearchAttributes:                                 dc.GetMapProperty(dynamicconfig.ValidSearchAttributes, definition.GetDefaultIndexedKeys()),
+		MaxSignalRequestedIDs:                                 dc.GetIntPropertyFilteredByDomain(dynamicconfig.MaxSignalRequestedIDs, 10000),
+		MaxClusterClockSkew:                                   dc.GetDurationProperty(dynamicconfig.MaxClusterClockSkew, 5*time.Minute),
+		DomainDraining:                                        dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.DomainDraining, false),
+		ReturnNewDecisionTaskAlwaysSticky:                     dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.ReturnNewDecisionTaskAlwaysSticky, true),
+		MaxBadBinaries:                                        dc.GetIntPropertyFilteredByDomain(dynamicconfig.FrontendMaxBadBinaries, 10),
 		EnableVisibilityToKafka:                               dc.GetBoolProperty(dynamicconfig.EnableVisibilityToKafka, enableVisibilityToKafka),
 		EmitShardDiffLog:                                      dc.GetBoolProperty(dynamicconfig.EmitShardDiffLog, false),
 		HistoryCacheInitialSize:                               dc.GetIntProperty(dynamicconfig.HistoryCacheInitialSize, 128),
@@ -202,6 +303,8 @@ func NewConfig(dc *dynamicconfig.Collection, numberOfShards int, enableVisibilit
 		TransferProcessorUpdateAckInterval:                    dc.GetDurationProperty(dynamicconfig.TransferProcessorUpdateAckInterval, 30*time.Second),
 		TransferProcessorUpdateAckIntervalJitterCoefficient:   dc.GetFloat64Property(dynamicconfig.TransferProcessorUpdateAckIntervalJitterCoefficient, 0.15),
 		TransferProcessorCompleteTransferInterval:             dc.GetDurationProperty(dynamicconfig.TransferProcessorCompleteTransferInterval, 60*time.Second),
+		TransferProcessorMatchingCircuitBreakerThreshold:      dc.GetIntProperty(dynamicconfig.TransferProcessorMatchingCircuitBreakerThreshold, 10),
+		TransferProcessorMatchingCircuitBreakerOpenDuration:   dc.GetDurationProperty(dynamicconfig.TransferProcessorMatchingCircuitBreakerOpenDuration, 5*time.Second),
 		ReplicatorTaskBatchSize:                               dc.GetIntProperty(dynamicconfig.ReplicatorTaskBatchSize, 100),
 		ReplicatorTaskWorkerCount:                             dc.GetIntProperty(dynamicconfig.ReplicatorTaskWorkerCount, 10),
 		ReplicatorTaskMaxRetryCount:                           dc.GetIntProperty(dynamicconfig.ReplicatorTaskMaxRetryCount, 100),
@@ -215,6 +318,12 @@ func NewConfig(dc *dynamicconfig.Collection, numberOfShards int, enableVisibilit
 		HistoryMgrNumConns:                                    dc.GetIntProperty(dynamicconfig.HistoryMgrNumConns, 50),
 		MaximumBufferedEventsBatch:                            dc.GetIntProperty(dynamicconfig.MaximumBufferedEventsBatch, 100),
 		MaximumSignalsPerExecution:                            dc.GetIntPropertyFilteredByDomain(dynamicconfig.MaximumSignalsPerExecution, 0),
+		MaximumSignalsBeforeFirstDecision:                     dc.GetIntPropertyFilteredByDomain(dynamicconfig.MaximumSignalsBeforeFirstDecision, 0),
+		MaximumChildWorkflowsPerExecution:                     dc.GetIntPropertyFilteredByDomain(dynamicconfig.MaximumChildWorkflowsPerExecution, 0),
+		MaximumDecisionTaskAttempts:                           dc.GetIntPropertyFilteredByDomain(dynamicconfig.MaximumDecisionTaskAttempts, 0),
+		MaximumHistoryLength:                                  dc.GetIntPropertyFilteredByDomain(dynamicconfig.MaximumHistoryLength, 0),
+		EmptyDecisionLoopLimit:                                dc.GetIntPropertyFilteredByDomain(dynamicconfig.EmptyDecisionLoopLimit, 0),
+		EmptyDecisionLoopMinInterval:                          dc.GetDurationPropertyFilteredByDomain(dynamicconfig.EmptyDecisionLoopMinInterval, time.Second),
 		ShardUpdateMinInterval:                                dc.GetDurationProperty(dynamicconfig.ShardUpdateMinInterval, 5*time.Minute),
 		ShardSyncMinInterval:                                  dc.GetDurationProperty(dynamicconfig.ShardSyncMinInterval, 5*time.Minute),
 
@@ -223,8 +332,11 @@ func NewConfig(dc *dynamicconfig.Collection, numberOfShards int, enableVisibilit
 		EventEncodingType:          dc.GetStringPropertyFnWithDomainFilter(dynamicconfig.DefaultEventEncoding, string(common.EncodingTypeThriftRW)),
 		EnableEventsV2:             dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.EnableEventsV2, true),
 
-		NumArchiveSystemWorkflows: dc.GetIntProperty(dynamicconfig.NumArchiveSystemWorkflows, 1000),
-		ArchiveRequestRPS:         dc.GetIntProperty(dynamicconfig.ArchiveRequestRPS, 300), // should be much smaller than frontend RPS
+		NumArchiveSystemWorkflows:            dc.GetIntProperty(dynamicconfig.NumArchiveSystemWorkflows, 1000),
+		ArchiveRequestRPS:                    dc.GetIntProperty(dynamicconfig.ArchiveRequestRPS, 300), // should be much smaller than frontend RPS
+		ArchivalOnRequestOnly:                dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.ArchivalOnRequestOnly, false),
+		ArchiveMutableStateSnapshot:          dc.GetBoolPropertyFnWithDomainFilter(dynamicconfig.ArchiveMutableStateSnapshot, false),
+		ArchiveMutableStateSnapshotSizeLimit: dc.GetIntPropertyFilteredByDomain(dynamicconfig.ArchiveMutableStateSnapshotSizeLimit, 32*1024),
 
 		BlobSizeLimitError:     dc.GetIntPropertyFilteredByDomain(dynamicconfig.BlobSizeLimitError, 2*1024*1024),
 		BlobSizeLimitWarn:      dc.GetIntPropertyFilteredByDomain(dynamicconfig.BlobSizeLimitError, 256*1024),
@@ -233,6 +345,11 @@ func NewConfig(dc *dynamicconfig.Collection, numberOfShards int, enableVisibilit
 		HistoryCountLimitError: dc.GetIntPropertyFilteredByDomain(dynamicconfig.HistoryCountLimitError, 200*1024),
 		HistoryCountLimitWarn:  dc.GetIntPropertyFilteredByDomain(dynamicconfig.HistoryCountLimitWarn, 50*1024),
 
+		ActivityTypeBlobSizeLimitError: dc.GetMapProperty(dynamicconfig.ActivityTypeBlobSizeLimitError, map[string]interface{}{}),
+
+		MaxNonRetriableErrorReasonsCount:  dc.GetIntProperty(dynamicconfig.MaxNonRetriableErrorReasonsCount, 100),
+		MaxNonRetriableErrorReasonsLength: dc.GetIntProperty(dynamicconfig.MaxNonRetriableErrorReasonsLength, 10*1024),
+
 		ThrottledLogRPS: dc.GetIntProperty(dynamicconfig.HistoryThrottledLogRPS, 20),
 	}
 
@@ -106,6 +106,8 @@ type (
 		logger           log.Logger
 		throttledLogger  log.Logger
 		metricsClient    metrics.Client
+		// timeSource is the clock of the shard injected through the shard controller, the real time is used if unset
+		timeSource clock.TimeSource
 
 		sync.RWMutex
 		lastUpdated               time.Time
@@ -932,7 +934,7 @@ func (s *shardContextImpl) updateMaxReadLevelLocked(rl int64) {
 
 func (s *shardContextImpl) updateShardInfoLocked() error {
 	var err error
-	now := clock.NewRealTimeSource().Now()
+	now := s.GetTimeSource().Now()
 	if s.lastUpdated.Add(s.config.ShardUpdateMinInterval()).After(now) {
 		return nil
 	}
@@ -1054,7 +1056,10 @@ func (s *shardContextImpl) allocateTimerIDsLocked(domainEntry *cache.DomainCache
 }
 
 func (s *shardContextImpl) GetTimeSource() clock.TimeSource {
-	return clock.NewRealTimeSource()
+	if s.timeSource == nil {
+		return clock.NewRealTimeSource()
+	}
+	return s.timeSource
 }
 
 func (s *shardContextImpl) SetCurrentTime(cluster string, currentTime time.Time) {
@@ -1164,6 +1169,7 @@ func acquireShard(shardItem *historyShardsItem, closeCh chan<- int) (ShardContex
 		config:                    shardItem.config,
 		standbyClusterCurrentTime: standbyClusterCurrentTime,
 		timerMaxReadLevelMap:      timerMaxReadLevelMap, // use ack to init read level
+		timeSource:                shardItem.timeSource,
 	}
 	context.logger = shardItem.logger
 	context.throttledLogger = shardItem.throttledLogger
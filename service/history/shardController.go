@@ -28,6 +28,7 @@ import (
 
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/membership"
@@ -61,6 +62,7 @@ type (
 		throttledLoggger    log.Logger
 		config              *Config
 		metricsClient       metrics.Client
+		timeSource          clock.TimeSource
 
 		sync.RWMutex
 		historyShards map[int]*historyShardsItem
@@ -86,6 +88,7 @@ type (
 		logger          log.Logger
 		throttledLogger log.Logger
 		metricsClient   metrics.Client
+		timeSource      clock.TimeSource
 	}
 )
 
@@ -98,7 +101,7 @@ const (
 func newShardController(svc service.Service, host *membership.HostInfo, resolver membership.ServiceResolver,
 	shardMgr persistence.ShardManager, historyMgr persistence.HistoryManager, historyV2Mgr persistence.HistoryV2Manager, domainCache cache.DomainCache,
 	executionMgrFactory persistence.ExecutionManagerFactory, factory EngineFactory,
-	config *Config, logger log.Logger, metricsClient metrics.Client) *shardController {
+	config *Config, logger log.Logger, metricsClient metrics.Client, timeSource clock.TimeSource) *shardController {
 	logger = logger.WithTags(tag.ComponentShardController)
 	return &shardController{
 		service:             svc,
@@ -118,13 +121,15 @@ func newShardController(svc service.Service, host *membership.HostInfo, resolver
 		throttledLoggger:    svc.GetThrottledLogger(),
 		config:              config,
 		metricsClient:       metricsClient,
+		timeSource:          timeSource,
 	}
 }
 
 func newHistoryShardsItem(shardID int, svc service.Service, shardMgr persistence.ShardManager,
 	historyMgr persistence.HistoryManager, historyV2Mgr persistence.HistoryV2Manager, domainCache cache.DomainCache,
 	executionMgrFactory persistence.ExecutionManagerFactory, factory EngineFactory, host *membership.HostInfo,
-	config *Config, logger log.Logger, throttledLog log.Logger, metricsClient metrics.Client) (*historyShardsItem, error) {
+	config *Config, logger log.Logger, throttledLog log.Logger, metricsClient metrics.Client,
+	timeSource clock.TimeSource) (*historyShardsItem, error) {
 
 	executionMgr, err := executionMgrFactory.NewExecutionManager(shardID)
 	if err != nil {
@@ -146,6 +151,7 @@ func newHistoryShardsItem(shardID int, svc service.Service, shardMgr persistence
 		logger:          logger.WithTags(tag.ShardID(shardID)),
 		throttledLogger: throttledLog.WithTags(tag.ShardID(shardID)),
 		metricsClient:   metricsClient,
+		timeSource:      timeSource,
 	}, nil
 }
 
@@ -241,7 +247,8 @@ func (c *shardController) getOrCreateHistoryShardItem(shardID int) (*historyShar
 
 	if info.Identity() == c.host.Identity() {
 		shardItem, err := newHistoryShardsItem(shardID, c.service, c.shardMgr, c.historyMgr, c.historyV2Mgr, c.domainCache,
-			c.executionMgrFactory, c.engineFactory, c.host, c.config, c.logger, c.throttledLoggger, c.metricsClient)
+			c.executionMgrFactory, c.engineFactory, c.host, c.config, c.logger, c.throttledLoggger, c.metricsClient,
+			c.timeSource)
 		if err != nil {
 			return nil, err
 		}
@@ -391,6 +398,47 @@ func (c *shardController) shardIDs() []int32 {
 	return ids
 }
 
+// describeArchivalBacklog sums up per domain the archival backlog of all shards with a started engine on this host,
+// shards are not acquired by the call and shards failing to report are skipped
+func (c *shardController) describeArchivalBacklog() *HostArchivalBacklog {
+	c.RLock()
+	items := make([]*historyShardsItem, 0, len(c.historyShards))
+	for _, item := range c.historyShards {
+		items = append(items, item)
+	}
+	c.RUnlock()
+
+	backlog := &HostArchivalBacklog{DomainBacklog: make(map[string]int64)}
+	for _, item := range items {
+		engine := item.getEngineIfStarted()
+		if engine == nil {
+			continue
+		}
+		shardBacklog, err := engine.DescribeArchivalBacklog()
+		if err != nil {
+			c.logger.Warn("Failed to describe archival backlog of shard.", tag.ShardID(item.shardID), tag.Error(err))
+			continue
+		}
+		for domainName, count := range shardBacklog.DomainBacklog {
+			backlog.DomainBacklog[domainName] += count
+		}
+		if shardBacklog.Truncated {
+			backlog.TruncatedShardIDs = append(backlog.TruncatedShardIDs, shardBacklog.ShardID)
+		}
+	}
+	return backlog
+}
+
+func (i *historyShardsItem) getEngineIfStarted() Engine {
+	i.RLock()
+	defer i.RUnlock()
+
+	if i.status != historyShardsItemStatusStarted {
+		return nil
+	}
+	return i.engine
+}
+
 func (i *historyShardsItem) getOrCreateEngine(shardClosedCh chan<- int) (Engine, error) {
 	i.RLock()
 	if i.status == historyShardsItemStatusStarted {
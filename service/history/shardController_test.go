@@ -32,6 +32,7 @@ import (
 	"github.com/uber-go/tally"
 	"github.com/uber/cadence/client"
 	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/loggerimpl"
@@ -65,6 +66,7 @@ type (
 		config                  *Config
 		logger                  log.Logger
 		metricsClient           metrics.Client
+		timeSource              *clock.EventTimeSource
 	}
 )
 
@@ -91,8 +93,10 @@ func (s *shardControllerSuite) SetupTest() {
 	s.mockClientBean = &client.MockClientBean{}
 	s.mockService = service.NewTestService(s.mockClusterMetadata, s.mockMessagingClient, s.metricsClient, s.mockClientBean)
 	s.domainCache = cache.NewDomainCache(s.mockMetadaraMgr, s.mockClusterMetadata, s.metricsClient, s.logger)
+	s.timeSource = clock.NewEventTimeSource().Update(time.Now())
 	s.controller = newShardController(s.mockService, s.hostInfo, s.mockServiceResolver, s.mockShardManager,
-		s.mockHistoryMgr, s.mockHistoryV2Mgr, s.domainCache, s.mockExecutionMgrFactory, s.mockEngineFactory, s.config, s.logger, s.metricsClient)
+		s.mockHistoryMgr, s.mockHistoryV2Mgr, s.domainCache, s.mockExecutionMgrFactory, s.mockEngineFactory, s.config, s.logger, s.metricsClient,
+		s.timeSource)
 }
 
 func (s *shardControllerSuite) TearDownTest() {
@@ -124,7 +128,10 @@ func (s *shardControllerSuite) TestAcquireShardSuccess() {
 			mockEngine := &MockHistoryEngine{}
 			mockEngine.On("Start").Return().Once()
 			s.mockServiceResolver.On("Lookup", string(shardID)).Return(s.hostInfo, nil).Twice()
-			s.mockEngineFactory.On("CreateEngine", mock.Anything).Return(mockEngine).Once()
+			s.mockEngineFactory.On("CreateEngine", mock.Anything).Return(mockEngine).Run(func(args mock.Arguments) {
+				// the shard uses the clock the controller was created with
+				s.Equal(s.timeSource.Now(), args.Get(0).(ShardContext).GetTimeSource().Now())
+			}).Once()
 			s.mockShardManager.On("GetShard", &persistence.GetShardRequest{ShardID: shardID}).Return(
 				&persistence.GetShardResponse{
 					ShardInfo: &persistence.ShardInfo{
@@ -352,7 +359,7 @@ func (s *shardControllerSuite) TestHistoryEngineClosed() {
 	numShards := 4
 	s.config.NumberOfShards = numShards
 	s.controller = newShardController(s.mockService, s.hostInfo, s.mockServiceResolver, s.mockShardManager, s.mockHistoryMgr, s.mockHistoryV2Mgr,
-		s.domainCache, s.mockExecutionMgrFactory, s.mockEngineFactory, s.config, s.logger, s.metricsClient)
+		s.domainCache, s.mockExecutionMgrFactory, s.mockEngineFactory, s.config, s.logger, s.metricsClient, clock.NewRealTimeSource())
 	historyEngines := make(map[int]*MockHistoryEngine)
 	for shardID := 0; shardID < numShards; shardID++ {
 		mockEngine := &MockHistoryEngine{}
@@ -445,7 +452,7 @@ func (s *shardControllerSuite) TestRingUpdated() {
 	numShards := 4
 	s.config.NumberOfShards = numShards
 	s.controller = newShardController(s.mockService, s.hostInfo, s.mockServiceResolver, s.mockShardManager, s.mockHistoryMgr, s.mockHistoryV2Mgr,
-		s.domainCache, s.mockExecutionMgrFactory, s.mockEngineFactory, s.config, s.logger, s.metricsClient)
+		s.domainCache, s.mockExecutionMgrFactory, s.mockEngineFactory, s.config, s.logger, s.metricsClient, clock.NewRealTimeSource())
 	historyEngines := make(map[int]*MockHistoryEngine)
 	for shardID := 0; shardID < numShards; shardID++ {
 		mockEngine := &MockHistoryEngine{}
@@ -525,7 +532,7 @@ func (s *shardControllerSuite) TestShardControllerClosed() {
 	numShards := 4
 	s.config.NumberOfShards = numShards
 	s.controller = newShardController(s.mockService, s.hostInfo, s.mockServiceResolver, s.mockShardManager, s.mockHistoryMgr, s.mockHistoryV2Mgr,
-		s.domainCache, s.mockExecutionMgrFactory, s.mockEngineFactory, s.config, s.logger, s.metricsClient)
+		s.domainCache, s.mockExecutionMgrFactory, s.mockEngineFactory, s.config, s.logger, s.metricsClient, clock.NewRealTimeSource())
 	historyEngines := make(map[int]*MockHistoryEngine)
 	for shardID := 0; shardID < numShards; shardID++ {
 		mockEngine := &MockHistoryEngine{}
@@ -571,6 +578,34 @@ func (s *shardControllerSuite) TestShardControllerClosed() {
 	workerWG.Wait()
 }
 
+func (s *shardControllerSuite) TestDescribeArchivalBacklog() {
+	engine0 := &MockHistoryEngine{}
+	engine0.On("DescribeArchivalBacklog").Return(&ShardArchivalBacklog{
+		ShardID:       0,
+		DomainBacklog: map[string]int64{"domain1": 2, "domain2": 1},
+	}, nil).Once()
+	engine1 := &MockHistoryEngine{}
+	engine1.On("DescribeArchivalBacklog").Return(&ShardArchivalBacklog{
+		ShardID:       1,
+		DomainBacklog: map[string]int64{"domain1": 3},
+		Truncated:     true,
+	}, nil).Once()
+	engine2 := &MockHistoryEngine{}
+	engine2.On("DescribeArchivalBacklog").Return(nil, errors.New("some random error")).Once()
+
+	s.controller.historyShards[0] = &historyShardsItem{shardID: 0, status: historyShardsItemStatusStarted, engine: engine0}
+	s.controller.historyShards[1] = &historyShardsItem{shardID: 1, status: historyShardsItemStatusStarted, engine: engine1}
+	s.controller.historyShards[2] = &historyShardsItem{shardID: 2, status: historyShardsItemStatusStarted, engine: engine2}
+	s.controller.historyShards[3] = &historyShardsItem{shardID: 3, status: historyShardsItemStatusInitialized}
+
+	backlog := s.controller.describeArchivalBacklog()
+	s.Equal(map[string]int64{"domain1": 5, "domain2": 1}, backlog.DomainBacklog)
+	s.Equal([]int{1}, backlog.TruncatedShardIDs)
+	engine0.AssertExpectations(s.T())
+	engine1.AssertExpectations(s.T())
+	engine2.AssertExpectations(s.T())
+}
+
 func (s *shardControllerSuite) setupMocksForAcquireShard(shardID int, mockEngine *MockHistoryEngine, currentRangeID,
 	newRangeID int64) {
 
@@ -437,11 +437,28 @@ func (b *stateBuilderImpl) applyEvents(domainID, requestID string, execution sha
 		case shared.EventTypeMarkerRecorded:
 			// No mutable state action is needed
 
+		case shared.EventTypeUpsertWorkflowMemo:
+			if err := b.msBuilder.ReplicateUpsertWorkflowMemoEvent(event); err != nil {
+				return nil, nil, nil, err
+			}
+
+			b.transferTasks = append(b.transferTasks, &persistence.UpsertWorkflowMemoTask{})
+
 		case shared.EventTypeWorkflowExecutionSignaled:
 			if err := b.msBuilder.ReplicateWorkflowExecutionSignaled(event); err != nil {
 				return nil, nil, nil, err
 			}
 
+		case shared.EventTypeWorkflowExecutionTimeoutExtended:
+			if err := b.msBuilder.ReplicateWorkflowExecutionTimeoutExtendedEvent(event); err != nil {
+				return nil, nil, nil, err
+			}
+
+			// the timer scheduled for the old timeout is dropped when it fires before the new one
+			b.timerTasks = append(b.timerTasks, &persistence.WorkflowTimeoutTask{
+				VisibilityTimestamp: getWorkflowTimeoutTime(b.msBuilder),
+			})
+
 		case shared.EventTypeWorkflowExecutionCancelRequested:
 			if err := b.msBuilder.ReplicateWorkflowExecutionCancelRequestedEvent(event); err != nil {
 				return nil, nil, nil, err
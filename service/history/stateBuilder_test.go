@@ -362,6 +362,49 @@ func (s *stateBuilderSuite) TestApplyEvents_EventTypeWorkflowExecutionSignaled()
 	s.Empty(s.stateBuilder.newRunTransferTasks)
 }
 
+func (s *stateBuilderSuite) TestApplyEvents_EventTypeWorkflowExecutionTimeoutExtended() {
+	version := int64(1)
+	requestID := uuid.New()
+	domainID := validDomainID
+	execution := shared.WorkflowExecution{
+		WorkflowId: common.StringPtr("some random workflow ID"),
+		RunId:      common.StringPtr(validRunID),
+	}
+
+	now := time.Now()
+	evenType := shared.EventTypeWorkflowExecutionTimeoutExtended
+	event := &shared.HistoryEvent{
+		Version:   common.Int64Ptr(version),
+		EventId:   common.Int64Ptr(130),
+		Timestamp: common.Int64Ptr(now.UnixNano()),
+		EventType: &evenType,
+		WorkflowExecutionTimeoutExtendedEventAttributes: &shared.WorkflowExecutionTimeoutExtendedEventAttributes{
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(200),
+			Identity:                            common.StringPtr("some random operator"),
+		},
+	}
+	executionInfo := &persistence.WorkflowExecutionInfo{
+		StartTimestamp:  now,
+		WorkflowTimeout: 200,
+	}
+	s.mockUpdateVersion(event)
+	s.mockMutableState.On("GetExecutionInfo").Return(executionInfo)
+	s.mockMutableState.On("GetStartEvent").Return(nil, false)
+	s.mockMutableState.On("ReplicateWorkflowExecutionTimeoutExtendedEvent", event).Return(nil).Once()
+
+	s.mockMutableState.On("ClearStickyness").Once()
+	_, _, _, err := s.stateBuilder.applyEvents(domainID, requestID, execution, s.toHistory(event), nil, 0, 0)
+	s.Nil(err)
+
+	s.Equal(1, len(s.stateBuilder.timerTasks))
+	timerTask, ok := s.stateBuilder.timerTasks[0].(*persistence.WorkflowTimeoutTask)
+	s.True(ok)
+	s.True(timerTask.VisibilityTimestamp.Equal(now.Add(200 * time.Second)))
+	s.Empty(s.stateBuilder.transferTasks)
+	s.Empty(s.stateBuilder.newRunTimerTasks)
+	s.Empty(s.stateBuilder.newRunTransferTasks)
+}
+
 func (s *stateBuilderSuite) TestApplyEvents_EventTypeWorkflowExecutionFailed() {
 	version := int64(1)
 	requestID := uuid.New()
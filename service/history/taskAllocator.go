@@ -23,6 +23,7 @@ package history
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	workflow "github.com/uber/cadence/.gen/go/shared"
 	"github.com/uber/cadence/common/cache"
@@ -37,6 +38,7 @@ type (
 		verifyStandbyTask(standbyCluster string, taskDomainID string, task interface{}) (bool, error)
 		lock()
 		unlock()
+		isLocked() bool
 	}
 
 	taskAllocatorImpl struct {
@@ -46,6 +48,7 @@ type (
 		logger             log.Logger
 
 		locker sync.RWMutex
+		locked int32
 	}
 )
 
@@ -127,9 +130,16 @@ func (t *taskAllocatorImpl) verifyStandbyTask(standbyCluster string, taskDomainI
 // lock block all task allocation
 func (t *taskAllocatorImpl) lock() {
 	t.locker.Lock()
+	atomic.StoreInt32(&t.locked, 1)
 }
 
 // unlock resume the task allocator
 func (t *taskAllocatorImpl) unlock() {
+	atomic.StoreInt32(&t.locked, 0)
 	t.locker.Unlock()
 }
+
+// isLocked return whether task allocation is currently blocked
+func (t *taskAllocatorImpl) isLocked() bool {
+	return atomic.LoadInt32(&t.locked) == 1
+}
@@ -279,32 +279,39 @@ func (tb *timerBuilder) loadActivityTimers(msBuilder mutableState) {
 	tb.activityTimers = make(timers, 0, len(tb.pendingActivityTimers))
 	for _, v := range tb.pendingActivityTimers {
 		if v.ScheduleID != common.EmptyEventID {
-			scheduleToCloseExpiry := v.ScheduledTime.Add(time.Duration(v.ScheduleToCloseTimeout) * time.Second)
-			if !v.ExpirationTime.IsZero() && v.ExpirationTime.Before(scheduleToCloseExpiry) {
-				// expire before scheduleToClose timeout
-				scheduleToCloseExpiry = v.ExpirationTime
-			}
-			td := &timerDetails{
-				TimerSequenceID: TimerSequenceID{VisibilityTimestamp: scheduleToCloseExpiry},
-				ActivityID:      v.ScheduleID,
-				EventID:         v.ScheduleID,
-				Attempt:         v.Attempt,
-				TimeoutSec:      v.ScheduleToCloseTimeout,
-				TimeoutType:     w.TimeoutTypeScheduleToClose,
-				TaskCreated:     (v.TimerTaskStatus & TimerTaskStatusCreatedScheduleToClose) != 0}
-			tb.activityTimers = append(tb.activityTimers, td)
-
-			if v.StartedID != common.EmptyEventID {
-				startToCloseExpiry := v.StartedTime.Add(time.Duration(v.StartToCloseTimeout) * time.Second)
+			// dispatch only activities have no schedule to close timeout and are not tracked once started,
+			// their start to close timeout is only the deadline given to the worker
+			dispatchOnly := v.ScheduleToCloseTimeout == 0
+			if !dispatchOnly {
+				scheduleToCloseExpiry := v.ScheduledTime.Add(time.Duration(v.ScheduleToCloseTimeout) * time.Second)
+				if !v.ExpirationTime.IsZero() && v.ExpirationTime.Before(scheduleToCloseExpiry) {
+					// expire before scheduleToClose timeout
+					scheduleToCloseExpiry = v.ExpirationTime
+				}
 				td := &timerDetails{
-					TimerSequenceID: TimerSequenceID{VisibilityTimestamp: startToCloseExpiry},
+					TimerSequenceID: TimerSequenceID{VisibilityTimestamp: scheduleToCloseExpiry},
 					ActivityID:      v.ScheduleID,
 					EventID:         v.ScheduleID,
 					Attempt:         v.Attempt,
-					TimeoutType:     w.TimeoutTypeStartToClose,
-					TimeoutSec:      v.StartToCloseTimeout,
-					TaskCreated:     (v.TimerTaskStatus & TimerTaskStatusCreatedStartToClose) != 0}
+					TimeoutSec:      v.ScheduleToCloseTimeout,
+					TimeoutType:     w.TimeoutTypeScheduleToClose,
+					TaskCreated:     (v.TimerTaskStatus & TimerTaskStatusCreatedScheduleToClose) != 0}
 				tb.activityTimers = append(tb.activityTimers, td)
+			}
+
+			if v.StartedID != common.EmptyEventID {
+				if !dispatchOnly {
+					startToCloseExpiry := v.StartedTime.Add(time.Duration(v.StartToCloseTimeout) * time.Second)
+					td := &timerDetails{
+						TimerSequenceID: TimerSequenceID{VisibilityTimestamp: startToCloseExpiry},
+						ActivityID:      v.ScheduleID,
+						EventID:         v.ScheduleID,
+						Attempt:         v.Attempt,
+						TimeoutType:     w.TimeoutTypeStartToClose,
+						TimeoutSec:      v.StartToCloseTimeout,
+						TaskCreated:     (v.TimerTaskStatus & TimerTaskStatusCreatedStartToClose) != 0}
+					tb.activityTimers = append(tb.activityTimers, td)
+				}
 				if v.HeartbeatTimeout > 0 {
 					lastHeartBeatTS := v.LastHeartBeatUpdatedTime
 					if lastHeartBeatTS.Before(v.StartedTime) {
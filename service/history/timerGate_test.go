@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/suite"
+	"github.com/uber/cadence/common/clock"
 )
 
 type (
@@ -41,7 +42,7 @@ type (
 )
 
 func BenchmarkLocalTimer(b *testing.B) {
-	timer := NewLocalTimerGate()
+	timer := NewLocalTimerGate(clock.NewRealTimeSource())
 
 	for i := 0; i < b.N; i++ {
 		timer.Update(time.Now())
@@ -67,7 +68,7 @@ func (s *localTimerGateSuite) TearDownSuite() {
 }
 
 func (s *localTimerGateSuite) SetupTest() {
-	s.localTimerGate = NewLocalTimerGate()
+	s.localTimerGate = NewLocalTimerGate(clock.NewRealTimeSource())
 }
 
 func (s *localTimerGateSuite) TearDownTest() {
@@ -105,6 +106,23 @@ func (s *localTimerGateSuite) TestTimerFire() {
 	}
 }
 
+func (s *localTimerGateSuite) TestTimerFire_TimeSource() {
+	now := time.Now()
+	timeSource := clock.NewEventTimeSource().Update(now.Add(time.Hour))
+	timerGate := NewLocalTimerGate(timeSource)
+	defer timerGate.Close()
+	// the new timer is in the future of the real time but not of the time source
+	newTimer := now.Add(time.Hour)
+	deadline := now.Add(2 * time.Second)
+	timerGate.Update(newTimer)
+
+	select {
+	case <-timerGate.FireChan():
+	case <-time.NewTimer(deadline.Sub(now)).C:
+		s.Fail("timer should fire before test deadline")
+	}
+}
+
 func (s *localTimerGateSuite) TestTimerFireAfterUpdate_Active_Updated_BeforeNow() {
 	now := time.Now()
 	newTimer := now.Add(9 * time.Second)
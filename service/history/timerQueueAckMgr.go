@@ -279,6 +279,37 @@ func (t *timerQueueAckMgrImpl) getReadLevel() TimerSequenceID {
 	return t.readLevel
 }
 
+// getOldestPendingTask returns the loaded timer task with the smallest visibility timestamp which is not yet
+// completed, the bool is false if every loaded timer task is completed
+func (t *timerQueueAckMgrImpl) getOldestPendingTask() (TimerSequenceID, bool) {
+	t.Lock()
+	defer t.Unlock()
+	var oldest TimerSequenceID
+	found := false
+	for timerSequenceID, acked := range t.outstandingTasks {
+		if acked {
+			continue
+		}
+		if !found || compareTimerIDLess(&timerSequenceID, &oldest) {
+			oldest = timerSequenceID
+			found = true
+		}
+	}
+	return oldest, found
+}
+
+func (t *timerQueueAckMgrImpl) getPendingTaskCount() int64 {
+	t.Lock()
+	defer t.Unlock()
+	count := int64(0)
+	for _, acked := range t.outstandingTasks {
+		if !acked {
+			count++
+		}
+	}
+	return count
+}
+
 func (t *timerQueueAckMgrImpl) getAckLevel() TimerSequenceID {
 	t.Lock()
 	defer t.Unlock()
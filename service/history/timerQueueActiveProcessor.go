@@ -78,7 +78,7 @@ func newTimerQueueActiveProcessor(shard ShardContext, historyService *historyEng
 		currentClusterName,
 	)
 
-	timerGate := NewLocalTimerGate()
+	timerGate := NewLocalTimerGate(shard.GetTimeSource())
 	processor := &timerQueueActiveProcessorImpl{
 		shard:              shard,
 		historyService:     historyService,
@@ -153,7 +153,7 @@ func newTimerQueueFailoverProcessor(shard ShardContext, historyService *historyE
 		logger,
 	)
 
-	timerGate := NewLocalTimerGate()
+	timerGate := NewLocalTimerGate(shard.GetTimeSource())
 	processor := &timerQueueActiveProcessorImpl{
 		shard:              shard,
 		historyService:     historyService,
@@ -271,7 +271,7 @@ Update_History_Loop:
 		tBuilder := t.historyService.getTimerBuilder(context.getExecution())
 
 		var timerTasks []persistence.Task
-		scheduleNewDecision := false
+		timerFired := false
 
 	ExpireUserTimers:
 		for _, td := range tBuilder.GetUserTimers(msBuilder) {
@@ -287,7 +287,7 @@ Update_History_Loop:
 					return errFailedToAddTimerFiredEvent
 				}
 
-				scheduleNewDecision = !msBuilder.HasPendingDecisionTask()
+				timerFired = true
 			} else {
 				// See if we have next timer in list to be created.
 				if !td.TaskCreated {
@@ -306,7 +306,8 @@ Update_History_Loop:
 
 		// We apply the update to execution using optimistic concurrency.  If it fails due to a conflict than reload
 		// the history and try the operation again.
-		err = t.updateWorkflowExecution(context, msBuilder, scheduleNewDecision, false, timerTasks)
+		scheduleNewDecision := timerFired && canScheduleDecisionTask(msBuilder, t.shard, t.metricsClient, metrics.TimerActiveTaskUserTimerScope)
+		err = t.updateWorkflowExecution(metrics.TimerActiveTaskUserTimerScope, context, msBuilder, scheduleNewDecision, false, timerTasks)
 		if err != nil {
 			if err == ErrConflict {
 				continue Update_History_Loop
@@ -314,6 +315,7 @@ Update_History_Loop:
 		}
 		return err
 	}
+	emitUpdateFailure(t.metricsClient, metrics.TimerActiveTaskUserTimerScope, updateFailureCauseMaxAttempts)
 	return ErrMaxAttemptsExceeded
 }
 
@@ -461,8 +463,8 @@ Update_History_Loop:
 		if updateHistory || updateState {
 			// We apply the update to execution using optimistic concurrency.  If it fails due to a conflict than reload
 			// the history and try the operation again.
-			scheduleNewDecision := updateHistory && !msBuilder.HasPendingDecisionTask()
-			err := t.updateWorkflowExecution(context, msBuilder, scheduleNewDecision, false, timerTasks)
+			scheduleNewDecision := updateHistory && canScheduleDecisionTask(msBuilder, t.shard, t.metricsClient, metrics.TimerActiveTaskActivityTimeoutScope)
+			err := t.updateWorkflowExecution(metrics.TimerActiveTaskActivityTimeoutScope, context, msBuilder, scheduleNewDecision, false, timerTasks)
 			if err != nil {
 				if err == ErrConflict {
 					continue Update_History_Loop
@@ -475,6 +477,7 @@ Update_History_Loop:
 
 		return nil
 	}
+	emitUpdateFailure(t.metricsClient, metrics.TimerActiveTaskActivityTimeoutScope, updateFailureCauseMaxAttempts)
 	return ErrMaxAttemptsExceeded
 }
 
@@ -535,7 +538,7 @@ Update_History_Loop:
 		if scheduleNewDecision {
 			// We apply the update to execution using optimistic concurrency.  If it fails due to a conflict than reload
 			// the history and try the operation again.
-			err := t.updateWorkflowExecution(context, msBuilder, scheduleNewDecision, false, nil)
+			err := t.updateWorkflowExecution(metrics.TimerActiveTaskDecisionTimeoutScope, context, msBuilder, scheduleNewDecision, false, nil)
 			if err != nil {
 				if err == ErrConflict {
 					continue Update_History_Loop
@@ -547,6 +550,7 @@ Update_History_Loop:
 		return nil
 
 	}
+	emitUpdateFailure(t.metricsClient, metrics.TimerActiveTaskDecisionTimeoutScope, updateFailureCauseMaxAttempts)
 	return ErrMaxAttemptsExceeded
 }
 
@@ -579,7 +583,7 @@ Update_History_Loop:
 		}
 
 		// schedule first decision task
-		err = t.updateWorkflowExecution(context, msBuilder, true, false, nil)
+		err = t.updateWorkflowExecution(metrics.TimerActiveTaskWorkflowBackoffTimerScope, context, msBuilder, true, false, nil)
 		if err != nil {
 			if err == ErrConflict {
 				continue Update_History_Loop
@@ -588,6 +592,7 @@ Update_History_Loop:
 		return err
 	}
 
+	emitUpdateFailure(t.metricsClient, metrics.TimerActiveTaskWorkflowBackoffTimerScope, updateFailureCauseMaxAttempts)
 	return ErrMaxAttemptsExceeded
 }
 
@@ -705,6 +710,11 @@ Update_History_Loop:
 			}
 		}
 
+		// the workflow timeout was extended after this timer was created, the timer for the new timeout takes over
+		if task.VisibilityTimestamp.Add(workflowTimeoutRepairWindow).Before(getWorkflowTimeoutTime(msBuilder)) {
+			return nil
+		}
+
 		timeoutReason := getTimeoutErrorReason(workflow.TimeoutTypeStartToClose)
 		backoffInterval := msBuilder.GetRetryBackoffDuration(timeoutReason)
 		continueAsNewInitiator := workflow.ContinueAsNewInitiatorRetryPolicy
@@ -720,7 +730,7 @@ Update_History_Loop:
 
 			// We apply the update to execution using optimistic concurrency.  If it fails due to a conflict than reload
 			// the history and try the operation again.
-			err = t.updateWorkflowExecution(context, msBuilder, false, true, nil)
+			err = t.updateWorkflowExecution(metrics.TimerActiveTaskWorkflowTimeoutScope, context, msBuilder, false, true, nil)
 			if err != nil {
 				if err == ErrConflict {
 					continue Update_History_Loop
@@ -785,6 +795,7 @@ Update_History_Loop:
 		err = context.continueAsNewWorkflowExecution(nil, continueAsNewBuilder, transferTasks, timerTasks, transactionID)
 
 		if err != nil {
+			emitUpdateError(t.metricsClient, metrics.TimerActiveTaskWorkflowTimeoutScope, err)
 			if err == ErrConflict {
 				continue Update_History_Loop
 			}
@@ -793,10 +804,12 @@ Update_History_Loop:
 		}
 		return err
 	}
+	emitUpdateFailure(t.metricsClient, metrics.TimerActiveTaskWorkflowTimeoutScope, updateFailureCauseMaxAttempts)
 	return ErrMaxAttemptsExceeded
 }
 
 func (t *timerQueueActiveProcessorImpl) updateWorkflowExecution(
+	scope int,
 	context workflowExecutionContext,
 	msBuilder mutableState,
 	scheduleNewDecision bool,
@@ -835,6 +848,7 @@ func (t *timerQueueActiveProcessorImpl) updateWorkflowExecution(
 
 	err = context.updateWorkflowExecution(transferTasks, timerTasks, transactionID)
 	if err != nil {
+		emitUpdateError(t.metricsClient, scope, err)
 		if isShardOwnershiptLostError(err) {
 			// Shard is stolen.  Stop timer processing to reduce duplicates
 			t.timerQueueProcessorBase.Stop()
@@ -29,6 +29,7 @@ import (
 
 	h "github.com/uber/cadence/.gen/go/history"
 	"github.com/uber/cadence/client/matching"
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/metrics"
@@ -194,6 +195,24 @@ func (t *timerQueueProcessorImpl) UnlockTaskPrrocessing() {
 	t.taskAllocator.unlock()
 }
 
+func (t *timerQueueProcessorImpl) describeStatus() *QueueProcessorStatus {
+	ackMgr := t.activeTimerProcessor.timerQueueAckMgr
+	ackLevel := ackMgr.getAckLevel().VisibilityTimestamp.UnixNano()
+	maxReadLevel := t.shard.GetTimerMaxReadLevel(t.currentClusterName).UnixNano()
+	status := &QueueProcessorStatus{
+		AckLevel:     ackLevel,
+		ReadLevel:    ackMgr.getReadLevel().VisibilityTimestamp.UnixNano(),
+		MaxReadLevel: maxReadLevel,
+		Backlog:      common.MaxInt64(maxReadLevel-ackLevel, 0),
+		PendingTasks: ackMgr.getPendingTaskCount(),
+		Locked:       t.taskAllocator.isLocked(),
+	}
+	if oldest, ok := ackMgr.getOldestPendingTask(); ok {
+		status.OldestPendingTaskTimestamp = oldest.VisibilityTimestamp.UnixNano()
+	}
+	return status
+}
+
 func (t *timerQueueProcessorImpl) getTimerFiredCount(clusterName string) uint64 {
 	if clusterName == t.currentClusterName {
 		return t.activeTimerProcessor.getTimerFiredCount()
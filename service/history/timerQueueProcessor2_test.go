@@ -34,6 +34,7 @@ import (
 	"github.com/uber/cadence/client"
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/loggerimpl"
@@ -83,7 +84,6 @@ func (s *timerQueueProcessor2Suite) SetupSuite() {
 }
 
 func (s *timerQueueProcessor2Suite) SetupTest() {
-	shardID := 0
 	s.mockMatchingClient = &mocks.MatchingClient{}
 	s.mockExecutionMgr = &mocks.ExecutionManager{}
 	s.mockShardManager = &mocks.ShardManager{}
@@ -115,6 +115,18 @@ func (s *timerQueueProcessor2Suite) SetupTest() {
 	s.mockService = service.NewTestService(s.mockClusterMetadata, s.mockMessagingClient, metricsClient, s.mockClientBean)
 	s.mockEventsCache = &MockEventsCache{}
 
+	// this is used by shard context, not relevent to this test, so we do not care how many times "GetCurrentClusterName" os called
+	s.mockClusterMetadata.On("GetCurrentClusterName").Return(cluster.TestCurrentClusterName)
+	s.mockClusterMetadata.On("GetAllClusterInfo").Return(cluster.TestSingleDCClusterInfo)
+	s.mockClusterMetadata.On("IsGlobalDomainEnabled").Return(false)
+	s.mockClusterMetadata.On("IsArchivalEnabled").Return(false)
+	s.setupHistoryEngine(clock.NewRealTimeSource())
+}
+
+// setupHistoryEngine creates the shard using the given clock and the history engine on top of it
+func (s *timerQueueProcessor2Suite) setupHistoryEngine(timeSource clock.TimeSource) {
+	shardID := 0
+	metricsClient := metrics.NewClient(tally.NoopScope, metrics.History)
 	domainCache := cache.NewDomainCache(s.mockMetadataMgr, s.mockClusterMetadata, metricsClient, s.logger)
 	s.mockShard = &shardContextImpl{
 		service:                   s.mockService,
@@ -132,14 +144,10 @@ func (s *timerQueueProcessor2Suite) SetupTest() {
 		eventsCache:               s.mockEventsCache,
 		metricsClient:             metrics.NewClient(tally.NoopScope, metrics.History),
 		timerMaxReadLevelMap:      make(map[string]time.Time),
+		timeSource:                timeSource,
 	}
 
 	historyCache := newHistoryCache(s.mockShard)
-	// this is used by shard context, not relevent to this test, so we do not care how many times "GetCurrentClusterName" os called
-	s.mockClusterMetadata.On("GetCurrentClusterName").Return(cluster.TestCurrentClusterName)
-	s.mockClusterMetadata.On("GetAllClusterInfo").Return(cluster.TestSingleDCClusterInfo)
-	s.mockClusterMetadata.On("IsGlobalDomainEnabled").Return(false)
-	s.mockClusterMetadata.On("IsArchivalEnabled").Return(false)
 	h := &historyEngineImpl{
 		currentClusterName: s.mockShard.GetService().GetClusterMetadata().GetCurrentClusterName(),
 		shard:              s.mockShard,
@@ -150,6 +158,7 @@ func (s *timerQueueProcessor2Suite) SetupTest() {
 		logger:             s.logger,
 		tokenSerializer:    common.NewJSONTaskTokenSerializer(),
 		metricsClient:      s.mockShard.GetMetricsClient(),
+		visibilityMgr:      s.mockVisibilityMgr,
 	}
 	h.txProcessor = newTransferQueueProcessor(s.mockShard, h, s.mockVisibilityMgr, s.mockMatchingClient, &mocks.HistoryClient{}, s.logger)
 	h.timerProcessor = newTimerQueueProcessor(s.mockShard, h, s.mockMatchingClient, s.logger)
@@ -317,3 +326,135 @@ func (s *timerQueueProcessor2Suite) TestWorkflowTimeout() {
 	<-waitCh
 	s.mockHistoryEngine.timerProcessor.(*timerQueueProcessorImpl).activeTimerProcessor.Stop()
 }
+
+func (s *timerQueueProcessor2Suite) TestUserTimersFiredWithInFlightDecision() {
+	scope := tally.NewTestScope("test", nil)
+	s.mockHistoryEngine.metricsClient = metrics.NewClient(scope, metrics.History)
+	s.mockHistoryEngine.timerProcessor = newTimerQueueProcessor(s.mockShard, s.mockHistoryEngine, s.mockMatchingClient, s.logger)
+
+	domainID := testDomainActiveID
+	we := workflow.WorkflowExecution{WorkflowId: common.StringPtr("user-timers-in-flight-decision-test"),
+		RunId: common.StringPtr(validRunID)}
+	taskList := "user-timers-in-flight-decision"
+
+	builder := newMutableStateBuilderWithEventV2(cluster.TestCurrentClusterName, s.mockShard, s.mockEventsCache, s.logger, we.GetRunId())
+	s.mockEventsCache.On("putEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything).Return().Once()
+	startRequest := &workflow.StartWorkflowExecutionRequest{
+		WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("wType")},
+		TaskList:                            common.TaskListPtr(workflow.TaskList{Name: common.StringPtr(taskList)}),
+		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(100),
+		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(10),
+	}
+	builder.AddWorkflowExecutionStartedEvent(we, &history.StartWorkflowExecutionRequest{
+		DomainUUID:   common.StringPtr(domainID),
+		StartRequest: startRequest,
+	})
+
+	di := addDecisionTaskScheduledEvent(builder)
+	startedEvent := addDecisionTaskStartedEvent(builder, di.ScheduleID, taskList, uuid.New())
+	completedEvent := addDecisionTaskCompletedEvent(builder, di.ScheduleID, startedEvent.GetEventId(), nil, uuid.New())
+	timerEvent, _ := addTimerStartedEvent(builder, completedEvent.GetEventId(), "timer1", 1)
+	addTimerStartedEvent(builder, completedEvent.GetEventId(), "timer2", 1)
+	// the decision started after the timers were scheduled is still running when they fire
+	di = addDecisionTaskScheduledEvent(builder)
+	addDecisionTaskStartedEvent(builder, di.ScheduleID, taskList, uuid.New())
+
+	ms := createMutableState(builder)
+	wfResponse := &persistence.GetWorkflowExecutionResponse{State: ms}
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(wfResponse, nil).Once()
+
+	var updateRequest *persistence.UpdateWorkflowExecutionRequest
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("UpdateWorkflowExecution", mock.Anything).Return(&p.UpdateWorkflowExecutionResponse{MutableStateUpdateSessionStats: &p.MutableStateUpdateSessionStats{}}, nil).Run(func(arguments mock.Arguments) {
+		updateRequest = arguments.Get(0).(*persistence.UpdateWorkflowExecutionRequest)
+	}).Once()
+
+	timerTask := &persistence.TimerTaskInfo{
+		DomainID:            domainID,
+		WorkflowID:          we.GetWorkflowId(),
+		RunID:               we.GetRunId(),
+		TaskID:              int64(100),
+		TaskType:            persistence.TaskTypeUserTimer,
+		VisibilityTimestamp: time.Now().Add(2 * time.Second),
+		EventID:             timerEvent.GetEventId(),
+	}
+	err := s.mockHistoryEngine.timerProcessor.(*timerQueueProcessorImpl).activeTimerProcessor.processExpiredUserTimer(timerTask)
+	s.Nil(err)
+
+	// both timers fired, but the running decision picks them up and the suppressed decision is counted once
+	for _, task := range updateRequest.TransferTasks {
+		s.NotEqual(persistence.TransferTaskTypeDecisionTask, task.GetType())
+	}
+	s.Equal(di.ScheduleID, updateRequest.ExecutionInfo.DecisionScheduleID)
+	counters := scope.Snapshot().Counters()
+	s.Equal(int64(1), counters["test.duplicate_decision_suppressed+operation=WorkflowContext"].Value())
+}
+
+func (s *timerQueueProcessor2Suite) TestDeleteHistoryEventFiredOnShardTime() {
+	now := time.Now()
+	timeSource := clock.NewEventTimeSource().Update(now)
+	s.setupHistoryEngine(timeSource)
+
+	domainID := testDomainActiveID
+	we := workflow.WorkflowExecution{WorkflowId: common.StringPtr("delete-history-event-shard-time-test"),
+		RunId: common.StringPtr(validRunID)}
+	taskList := "delete-history-event-shard-time"
+
+	builder := newMutableStateBuilderWithEventV2(cluster.TestCurrentClusterName, s.mockShard, s.mockEventsCache, s.logger, we.GetRunId())
+	// start and completion events
+	s.mockEventsCache.On("putEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Twice()
+	startRequest := &workflow.StartWorkflowExecutionRequest{
+		WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr("wType")},
+		TaskList:                            common.TaskListPtr(workflow.TaskList{Name: common.StringPtr(taskList)}),
+		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(10),
+		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(1),
+	}
+	builder.AddWorkflowExecutionStartedEvent(we, &history.StartWorkflowExecutionRequest{
+		DomainUUID:   common.StringPtr(domainID),
+		StartRequest: startRequest,
+	})
+	di := addDecisionTaskScheduledEvent(builder)
+	startedEvent := addDecisionTaskStartedEvent(builder, di.ScheduleID, taskList, uuid.New())
+	completedEvent := addDecisionTaskCompletedEvent(builder, di.ScheduleID, startedEvent.GetEventId(), nil, "identity")
+	addCompleteWorkflowEvent(builder, completedEvent.GetEventId(), nil)
+
+	// the history is deleted after the retention of one day measured by the shard clock
+	_, task, err := getWorkflowHistoryCleanupTasksFromShard(s.mockShard, domainID, we.GetWorkflowId(),
+		s.mockHistoryEngine.getTimerBuilder(&we))
+	s.NoError(err)
+	deleteTask := task.(*persistence.DeleteHistoryEventTask)
+	s.Equal(now.Add(24*time.Hour), deleteTask.VisibilityTimestamp)
+
+	timerTask := &persistence.TimerTaskInfo{
+		DomainID:            domainID,
+		WorkflowID:          we.GetWorkflowId(),
+		RunID:               we.GetRunId(),
+		TaskID:              int64(100),
+		TaskType:            persistence.TaskTypeDeleteHistoryEvent,
+		VisibilityTimestamp: deleteTask.VisibilityTimestamp,
+	}
+	s.mockExecutionMgr.On("GetTimerIndexTasks", mock.Anything).Return(
+		&persistence.GetTimerIndexTasksResponse{Timers: []*persistence.TimerTaskInfo{timerTask}}, nil).Once()
+	s.mockExecutionMgr.On("GetTimerIndexTasks", mock.Anything).Return(
+		&persistence.GetTimerIndexTasksResponse{Timers: []*persistence.TimerTaskInfo{}}, nil) // for lookAheadTask
+	s.mockShardManager.On("UpdateShard", mock.Anything).Return(nil).Maybe()
+
+	ms := createMutableState(builder)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(&persistence.GetWorkflowExecutionResponse{State: ms}, nil).Once()
+	s.mockClusterMetadata.On("ArchivalConfig").Return(cluster.NewArchivalConfig(cluster.ArchivalDisabled, "", false))
+	s.mockExecutionMgr.On("DeleteCurrentWorkflowExecution", mock.Anything).Return(nil).Once()
+	s.mockExecutionMgr.On("DeleteWorkflowExecution", mock.Anything).Return(nil).Once()
+	s.mockHistoryV2Mgr.On("DeleteHistoryBranch", mock.Anything).Return(nil).Once()
+	waitCh := make(chan struct{})
+	s.mockVisibilityMgr.On("DeleteWorkflowExecution", mock.Anything).Return(nil).Run(func(arguments mock.Arguments) {
+		// Done.
+		waitCh <- struct{}{}
+	}).Once()
+
+	// the retention has passed on the shard clock but not in real time, the timer still fires
+	timeSource.Update(now.Add(24 * time.Hour))
+	s.mockHistoryEngine.timerProcessor.(*timerQueueProcessorImpl).activeTimerProcessor.Start()
+	<-waitCh
+	s.mockHistoryEngine.timerProcessor.(*timerQueueProcessorImpl).activeTimerProcessor.Stop()
+}
@@ -21,9 +21,11 @@
 package history
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -330,7 +332,7 @@ func (t *timerQueueProcessorBase) internalProcessor() error {
 				t.config.TimerProcessorMaxPollInterval(),
 				t.config.TimerProcessorMaxPollIntervalJitterCoefficient(),
 			))
-			if t.lastPollTime.Add(t.config.TimerProcessorMaxPollInterval()).Before(time.Now()) {
+			if t.lastPollTime.Add(t.config.TimerProcessorMaxPollInterval()).Before(t.shard.GetTimeSource().Now()) {
 				lookAheadTimer, err := t.readAndFanoutTimerTasks()
 				if err != nil {
 					return err
@@ -363,7 +365,7 @@ func (t *timerQueueProcessorBase) readAndFanoutTimerTasks() (*persistence.TimerT
 		return nil, nil
 	}
 
-	t.lastPollTime = time.Now()
+	t.lastPollTime = t.shard.GetTimeSource().Now()
 	timerTasks, lookAheadTask, moreTasks, err := t.timerQueueAckMgr.readTimerTasks()
 	if err != nil {
 		t.notifyNewTimer(time.Time{}) // re-enqueue the event
@@ -602,12 +604,32 @@ func (t *timerQueueProcessorBase) processDeleteHistoryEvent(task *persistence.Ti
 			t.metricsClient.IncCounter(metrics.HistoryProcessDeleteHistoryEventScope, metrics.WorkflowCleanupDeleteCount)
 			return t.deleteWorkflow(task, msBuilder, context)
 		}
+		if t.config.ArchivalOnRequestOnly(domainCacheEntry.GetInfo().Name) && !isArchivalRequested(msBuilder) {
+			t.metricsClient.IncCounter(metrics.HistoryProcessDeleteHistoryEventScope, metrics.WorkflowCleanupArchiveSkippedCount)
+			return t.deleteWorkflow(task, msBuilder, context)
+		}
 		t.metricsClient.IncCounter(metrics.HistoryProcessDeleteHistoryEventScope, metrics.WorkflowCleanupArchiveCount)
 		return t.archiveWorkflow(task, msBuilder, context)
 	}
 	return nil
 }
 
+// isArchivalRequested returns whether the workflow requested archival by setting common.ArchivalRequestedMemoKey
+// to true in its memo
+func isArchivalRequested(msBuilder mutableState) bool {
+	executionInfo := msBuilder.GetExecutionInfo()
+	var startEvent *workflow.HistoryEvent
+	if executionInfo.Memo == nil {
+		startEvent, _ = msBuilder.GetStartEvent()
+	}
+	memo := getVisibilityMemo(executionInfo, startEvent)
+	if memo == nil {
+		return false
+	}
+	value, ok := memo.Fields[common.ArchivalRequestedMemoKey]
+	return ok && strings.TrimSpace(string(value)) == "true"
+}
+
 func (t *timerQueueProcessorBase) deleteWorkflow(task *persistence.TimerTaskInfo, msBuilder mutableState, context workflowExecutionContext) error {
 	if err := t.deleteCurrentWorkflowExecution(task); err != nil {
 		return err
@@ -648,6 +670,21 @@ func (t *timerQueueProcessorBase) archiveWorkflow(task *persistence.TimerTaskInf
 		CloseFailoverVersion: msBuilder.GetLastWriteVersion(),
 		BucketName:           domainCacheEntry.GetConfig().ArchivalBucket,
 	}
+	if t.config.ArchiveMutableStateSnapshot(req.DomainName) {
+		req.MutableStateSnapshot = t.getMutableStateSnapshot(task, msBuilder, req.DomainName)
+	}
+	// link the archived history to the one of the previous run of a continue-as-new chain, the previous run closed
+	// with the version this run started with
+	startEvent, ok := msBuilder.GetStartEvent()
+	if !ok {
+		t.logger.Warn("failed to load start event for archival, archiving history without previous run",
+			tag.WorkflowID(task.WorkflowID),
+			tag.WorkflowRunID(task.RunID),
+			tag.WorkflowDomainID(task.DomainID))
+	} else if prevRunID := startEvent.WorkflowExecutionStartedEventAttributes.GetContinuedExecutionRunId(); len(prevRunID) != 0 {
+		req.PreviousRunID = prevRunID
+		req.PreviousRunCloseFailoverVersion = startEvent.GetVersion()
+	}
 
 	// send signal before deleting mutable state to make sure archival is idempotent
 	if err := t.historyService.archivalClient.Archive(req); err != nil {
@@ -676,6 +713,28 @@ func (t *timerQueueProcessorBase) archiveWorkflow(task *persistence.TimerTaskInf
 	return nil
 }
 
+// getMutableStateSnapshot returns the JSON encoded mutable state to archive alongside the history, or nil if it cannot
+// be encoded or exceeds the size limit in which case the history is archived without it
+func (t *timerQueueProcessorBase) getMutableStateSnapshot(task *persistence.TimerTaskInfo, msBuilder mutableState, domainName string) []byte {
+	snapshot, err := json.Marshal(msBuilder.CopyToPersistence())
+	if err != nil {
+		t.logger.Warn("failed to encode mutable state snapshot for archival", tag.Error(err),
+			tag.WorkflowID(task.WorkflowID),
+			tag.WorkflowRunID(task.RunID),
+			tag.WorkflowDomainID(task.DomainID))
+		return nil
+	}
+	if limit := t.config.ArchiveMutableStateSnapshotSizeLimit(domainName); len(snapshot) > limit {
+		t.logger.Warn("mutable state snapshot exceeds size limit, archiving history without it",
+			tag.WorkflowID(task.WorkflowID),
+			tag.WorkflowRunID(task.RunID),
+			tag.WorkflowDomainID(task.DomainID),
+			tag.WorkflowSize(int64(len(snapshot))))
+		return nil
+	}
+	return snapshot
+}
+
 func (t *timerQueueProcessorBase) deleteWorkflowExecution(task *persistence.TimerTaskInfo) error {
 	op := func() error {
 		return t.executionManager.DeleteWorkflowExecution(&persistence.DeleteWorkflowExecutionRequest{
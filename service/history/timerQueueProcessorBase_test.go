@@ -31,7 +31,9 @@ import (
 	"github.com/uber-go/tally"
 	workflow "github.com/uber/cadence/.gen/go/shared"
 	"github.com/uber/cadence/client"
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/loggerimpl"
@@ -115,7 +117,7 @@ func (s *timerQueueProcessorBaseSuite) SetupTest() {
 			metricsClient: metricsClient,
 		},
 		s.mockQueueAckMgr,
-		NewLocalTimerGate(),
+		NewLocalTimerGate(clock.NewRealTimeSource()),
 		dynamicconfig.GetIntPropertyFn(10),
 		dynamicconfig.GetDurationPropertyFn(0*time.Second),
 		s.logger,
@@ -209,6 +211,34 @@ func (s *timerQueueProcessorBaseSuite) TestDeleteWorkflow_NoErr() {
 	s.NoError(err)
 }
 
+func (s *timerQueueProcessorBaseSuite) TestIsArchivalRequested() {
+	ms := &mockMutableState{}
+	ms.On("GetExecutionInfo").Return(&persistence.WorkflowExecutionInfo{
+		Memo: map[string][]byte{common.ArchivalRequestedMemoKey: []byte("true")},
+	}).Once()
+	s.True(isArchivalRequested(ms))
+
+	ms.On("GetExecutionInfo").Return(&persistence.WorkflowExecutionInfo{
+		Memo: map[string][]byte{common.ArchivalRequestedMemoKey: []byte("false")},
+	}).Once()
+	s.False(isArchivalRequested(ms))
+
+	ms.On("GetExecutionInfo").Return(&persistence.WorkflowExecutionInfo{
+		Memo: map[string][]byte{"other key": []byte("true")},
+	}).Once()
+	s.False(isArchivalRequested(ms))
+
+	// workflows started before the memo was kept in execution info fall back to the start event
+	ms.On("GetExecutionInfo").Return(&persistence.WorkflowExecutionInfo{}).Once()
+	ms.On("GetStartEvent").Return(&workflow.HistoryEvent{
+		WorkflowExecutionStartedEventAttributes: &workflow.WorkflowExecutionStartedEventAttributes{
+			Memo: &workflow.Memo{Fields: map[string][]byte{common.ArchivalRequestedMemoKey: []byte("true")}},
+		},
+	}, true).Once()
+	s.True(isArchivalRequested(ms))
+	ms.AssertExpectations(s.T())
+}
+
 func (s *timerQueueProcessorBaseSuite) TestHandleTaskError_EntiryNotExists() {
 	err := &workflow.EntityNotExistsError{}
 	s.Nil(s.timerQueueProcessor.handleTaskError(s.scope, time.Now(), s.notificationChan, err, s.logger))
@@ -438,6 +438,11 @@ func (t *timerQueueStandbyProcessorImpl) processWorkflowTimeout(timerTask *persi
 			return nil
 		}
 
+		// the workflow timeout was extended after this timer was created, the timer for the new timeout takes over
+		if timerTask.VisibilityTimestamp.Add(workflowTimeoutRepairWindow).Before(getWorkflowTimeoutTime(msBuilder)) {
+			return nil
+		}
+
 		if t.discardTask(timerTask) {
 			// returning nil and set next event ID
 			// the post action function below shall take over
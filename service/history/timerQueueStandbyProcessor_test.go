@@ -920,6 +920,79 @@ func (s *timerQueueStandbyProcessorSuite) TestProcessWorkflowTimeout_Success() {
 	s.Nil(err)
 }
 
+func (s *timerQueueStandbyProcessorSuite) TestProcessWorkflowTimeout_Extended() {
+	domainID := "some random domain ID"
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("some random workflow ID"),
+		RunId:      common.StringPtr(uuid.New()),
+	}
+	workflowType := "some random workflow type"
+	taskListName := "some random task list"
+
+	version := int64(4096)
+	msBuilder := newMutableStateBuilderWithReplicationStateWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(),
+		s.mockShard, s.mockShard.GetEventsCache(), s.logger, version, execution.GetRunId())
+	_, err := msBuilder.AddWorkflowExecutionStartedEvent(
+		execution,
+		&history.StartWorkflowExecutionRequest{
+			DomainUUID: common.StringPtr(domainID),
+			StartRequest: &workflow.StartWorkflowExecutionRequest{
+				WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr(workflowType)},
+				TaskList:                            &workflow.TaskList{Name: common.StringPtr(taskListName)},
+				ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(2),
+				TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(1),
+			},
+		},
+	)
+	s.Nil(err)
+
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	event := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, taskListName, uuid.New())
+	di.StartedID = event.GetEventId()
+	addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID, di.StartedID, nil, "some random identity")
+	oldTimeout := getWorkflowTimeoutTime(msBuilder)
+
+	// the extension replicated from the active cluster
+	eventType := workflow.EventTypeWorkflowExecutionTimeoutExtended
+	err = msBuilder.ReplicateWorkflowExecutionTimeoutExtendedEvent(&workflow.HistoryEvent{
+		EventId:   common.Int64Ptr(msBuilder.GetNextEventID()),
+		EventType: &eventType,
+		Version:   common.Int64Ptr(version),
+		WorkflowExecutionTimeoutExtendedEventAttributes: &workflow.WorkflowExecutionTimeoutExtendedEventAttributes{
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(3600),
+			Identity:                            common.StringPtr("some random operator"),
+		},
+	})
+	s.Nil(err)
+	s.Equal(int32(3600), msBuilder.GetExecutionInfo().WorkflowTimeout)
+	newTimeout := getWorkflowTimeoutTime(msBuilder)
+	s.True(newTimeout.After(oldTimeout))
+
+	timerTask := &persistence.TimerTaskInfo{
+		Version:             version,
+		DomainID:            domainID,
+		WorkflowID:          execution.GetWorkflowId(),
+		RunID:               execution.GetRunId(),
+		TaskID:              int64(100),
+		TaskType:            persistence.TaskTypeWorkflowTimeout,
+		TimeoutType:         int(workflow.TimeoutTypeStartToClose),
+		VisibilityTimestamp: oldTimeout,
+	}
+
+	persistenceMutableState := createMutableState(msBuilder)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(&persistence.GetWorkflowExecutionResponse{State: persistenceMutableState}, nil)
+
+	// the timer of the old timeout is acked without waiting for the workflow to time out
+	_, err = s.timerQueueStandbyProcessor.process(timerTask, true)
+	s.Nil(err)
+
+	// the timer of the new timeout waits for the active cluster
+	timerTask.TaskID = int64(101)
+	timerTask.VisibilityTimestamp = newTimeout
+	_, err = s.timerQueueStandbyProcessor.process(timerTask, true)
+	s.Equal(ErrTaskRetry, err)
+}
+
 func (s *timerQueueStandbyProcessorSuite) TestProcessRetryTimeout() {
 	domainID := "some random domain ID"
 	execution := workflow.WorkflowExecution{
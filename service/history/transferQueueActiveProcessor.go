@@ -38,7 +38,11 @@ import (
 	"github.com/uber/cadence/common/persistence"
 )
 
-const identityHistoryService = "history-service"
+const (
+	identityHistoryService = "history-service"
+	// childPolicyTerminateReason is the reason recorded on child executions terminated by their child policy
+	childPolicyTerminateReason = "by parent termination child policy"
+)
 
 type (
 	transferQueueActiveProcessorImpl struct {
@@ -253,6 +257,21 @@ func (t *transferQueueActiveProcessorImpl) process(qTask queueTaskInfo, shouldPr
 			err = t.processResetWorkflow(task)
 		}
 		return metrics.TransferActiveTaskResetWorkflowScope, err
+	case persistence.TransferTaskTypeUpsertWorkflowMemo:
+		if shouldProcessTask {
+			err = t.processUpsertWorkflowMemo(task)
+		}
+		return metrics.TransferActiveTaskUpsertWorkflowMemoScope, err
+	case persistence.TransferTaskTypeTerminateChildExecution:
+		if shouldProcessTask {
+			err = t.processTerminateChildExecution(task)
+		}
+		return metrics.TransferActiveTaskTerminateChildScope, err
+	case persistence.TransferTaskTypeCancelChildExecution:
+		if shouldProcessTask {
+			err = t.processCancelChildExecution(task)
+		}
+		return metrics.TransferActiveTaskCancelChildScope, err
 	default:
 		return metrics.TransferActiveQueueProcessorScope, errUnknownTransferTask
 	}
@@ -412,7 +431,7 @@ func (t *transferQueueActiveProcessorImpl) processCloseExecution(task *persisten
 		return &workflow.InternalServiceError{Message: "Unable to get workflow start event."}
 	}
 	workflowExecutionTimestamp := getWorkflowExecutionTimestamp(msBuilder, startEvent)
-	visibilityMemo := getVisibilityMemo(startEvent)
+	visibilityMemo := getVisibilityMemo(executionInfo, startEvent)
 	searchAttr := executionInfo.SearchAttributes
 
 	// release the context lock since we no longer need mutable state builder and
@@ -740,14 +759,17 @@ func (t *transferQueueActiveProcessorImpl) processStartChildExecution(task *pers
 
 	// Get target domain name
 	var targetDomain string
+	var targetDomainDeleted bool
 	if domainEntry, err := t.shard.GetDomainCache().GetDomainByID(targetDomainID); err != nil {
 		if _, ok := err.(*workflow.EntityNotExistsError); !ok {
 			return err
 		}
 		// it is possible that the domain got deleted. Use domainID instead as this is only needed for the history event
 		targetDomain = targetDomainID
+		targetDomainDeleted = true
 	} else {
 		targetDomain = domainEntry.GetInfo().Name
+		targetDomainDeleted = domainEntry.GetInfo().Status == persistence.DomainStatusDeleted
 	}
 
 	initiatedEventID := task.ScheduleID
@@ -763,6 +785,7 @@ func (t *transferQueueActiveProcessorImpl) processStartChildExecution(task *pers
 		return nil
 	}
 
+	var childExecution *workflow.WorkflowExecution
 	initiatedEvent, ok := msBuilder.GetChildExecutionInitiatedEvent(initiatedEventID)
 	if ok && ci.StartedID == common.EmptyEventID {
 		attributes := initiatedEvent.StartChildWorkflowExecutionInitiatedEventAttributes
@@ -821,18 +844,30 @@ func (t *transferQueueActiveProcessorImpl) processStartChildExecution(task *pers
 		if err != nil {
 			return err
 		}
-		// Finally create first decision task for Child execution so it is really started
-		err = t.createFirstDecisionTask(targetDomainID, &workflow.WorkflowExecution{
+		childExecution = &workflow.WorkflowExecution{
 			WorkflowId: common.StringPtr(task.TargetWorkflowID),
 			RunId:      common.StringPtr(*startResponse.RunId),
-		})
+		}
 	} else {
 		// ChildExecution already started, just create DecisionTask and complete transfer task
-		childExecution := &workflow.WorkflowExecution{
+		childExecution = &workflow.WorkflowExecution{
 			WorkflowId: common.StringPtr(ci.StartedWorkflowID),
 			RunId:      common.StringPtr(ci.StartedRunID),
 		}
-		err = t.createFirstDecisionTask(targetDomainID, childExecution)
+	}
+
+	// Finally create first decision task for Child execution so it is really started
+	err = t.createFirstDecisionTask(targetDomainID, childExecution)
+	if err != nil && targetDomainDeleted {
+		// the first decision can never be scheduled in a deleted domain, so instead of retrying forever report the
+		// child as failed to the parent. Release the context lock as the parent is updated through the history client
+		release(nil)
+		return t.recordChildExecutionUnschedulable(task, childExecution, err)
+	}
+	if _, ok := err.(*workflow.EntityNotExistsError); ok {
+		// Maybe child workflow execution already timedout or terminated
+		// Safe to discard the error and complete this transfer task
+		return nil
 	}
 
 	return err
@@ -876,7 +911,7 @@ func (t *transferQueueActiveProcessorImpl) processRecordWorkflowStarted(task *pe
 		return &workflow.InternalServiceError{Message: "Failed to load start event."}
 	}
 	executionTimestamp := getWorkflowExecutionTimestamp(msBuilder, startEvent)
-	visibilityMemo := getVisibilityMemo(startEvent)
+	visibilityMemo := getVisibilityMemo(executionInfo, startEvent)
 	searchAttr := executionInfo.SearchAttributes
 
 	// release the context lock since we no longer need mutable state builder and
@@ -886,6 +921,160 @@ func (t *transferQueueActiveProcessorImpl) processRecordWorkflowStarted(task *pe
 		workflowTimeout, task.GetTaskID(), visibilityMemo, searchAttr)
 }
 
+func (t *transferQueueActiveProcessorImpl) processUpsertWorkflowMemo(task *persistence.TransferTaskInfo) (retError error) {
+	var err error
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr(task.WorkflowID),
+		RunId:      common.StringPtr(task.RunID),
+	}
+
+	context, release, err := t.cache.getOrCreateWorkflowExecution(task.DomainID, execution)
+	if err != nil {
+		return err
+	}
+	defer func() { release(retError) }()
+
+	var msBuilder mutableState
+	msBuilder, err = loadMutableStateForTransferTask(context, task, t.metricsClient, t.logger)
+	if err != nil {
+		return err
+	} else if msBuilder == nil || !msBuilder.IsWorkflowExecutionRunning() {
+		return nil
+	}
+
+	// no task version check here, the open record is rebuilt from the current mutable state
+	// so processing a stale task still writes the latest memo
+	executionInfo := msBuilder.GetExecutionInfo()
+	workflowTimeout := executionInfo.WorkflowTimeout
+	wfTypeName := executionInfo.WorkflowTypeName
+	startTimestamp := executionInfo.StartTimestamp.UnixNano()
+	startEvent, found := msBuilder.GetStartEvent()
+	if !found {
+		return &workflow.InternalServiceError{Message: "Failed to load start event."}
+	}
+	executionTimestamp := getWorkflowExecutionTimestamp(msBuilder, startEvent)
+	visibilityMemo := getVisibilityMemo(executionInfo, startEvent)
+	searchAttr := executionInfo.SearchAttributes
+
+	// release the context lock since we no longer need mutable state builder and
+	// the rest of logic is making RPC call, which takes time.
+	release(nil)
+	return t.recordWorkflowStarted(task.DomainID, execution, wfTypeName, startTimestamp, executionTimestamp.UnixNano(),
+		workflowTimeout, task.GetTaskID(), visibilityMemo, searchAttr)
+}
+
+func (t *transferQueueActiveProcessorImpl) processTerminateChildExecution(task *persistence.TransferTaskInfo) error {
+	targetDomain, ok, err := t.loadChildPolicyTarget(task)
+	if err != nil || !ok {
+		return err
+	}
+
+	terminateRequest := &h.TerminateWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(task.TargetDomainID),
+		TerminateRequest: &workflow.TerminateWorkflowExecutionRequest{
+			Domain: common.StringPtr(targetDomain),
+			WorkflowExecution: &workflow.WorkflowExecution{
+				WorkflowId: common.StringPtr(task.TargetWorkflowID),
+				RunId:      common.StringPtr(task.TargetRunID),
+			},
+			Reason:   common.StringPtr(childPolicyTerminateReason),
+			Identity: common.StringPtr(identityHistoryService),
+		},
+	}
+
+	op := func() error {
+		return t.historyClient.TerminateWorkflowExecution(nil, terminateRequest)
+	}
+
+	err = backoff.Retry(op, persistenceOperationRetryPolicy, common.IsPersistenceTransientError)
+	if _, ok := err.(*workflow.EntityNotExistsError); ok {
+		// this could happen if the child execution has already completed
+		return nil
+	}
+	return err
+}
+
+func (t *transferQueueActiveProcessorImpl) processCancelChildExecution(task *persistence.TransferTaskInfo) error {
+	targetDomain, ok, err := t.loadChildPolicyTarget(task)
+	if err != nil || !ok {
+		return err
+	}
+
+	cancelRequest := &h.RequestCancelWorkflowExecutionRequest{
+		DomainUUID: common.StringPtr(task.TargetDomainID),
+		CancelRequest: &workflow.RequestCancelWorkflowExecutionRequest{
+			Domain: common.StringPtr(targetDomain),
+			WorkflowExecution: &workflow.WorkflowExecution{
+				WorkflowId: common.StringPtr(task.TargetWorkflowID),
+				RunId:      common.StringPtr(task.TargetRunID),
+			},
+			Identity:  common.StringPtr(identityHistoryService),
+			RequestId: common.StringPtr(uuid.New()),
+		},
+		ExternalInitiatedEventId: common.Int64Ptr(task.ScheduleID),
+		ExternalWorkflowExecution: &workflow.WorkflowExecution{
+			WorkflowId: common.StringPtr(task.WorkflowID),
+			RunId:      common.StringPtr(task.RunID),
+		},
+		ChildWorkflowOnly: common.BoolPtr(true),
+	}
+
+	op := func() error {
+		return t.historyClient.RequestCancelWorkflowExecution(nil, cancelRequest)
+	}
+
+	err = backoff.Retry(op, persistenceOperationRetryPolicy, common.IsPersistenceTransientError)
+	switch err.(type) {
+	case *workflow.CancellationAlreadyRequestedError, *workflow.EntityNotExistsError:
+		// this could happen if the child execution has already completed or is already being cancelled
+		return nil
+	}
+	return err
+}
+
+// loadChildPolicyTarget verifies that the child execution targeted by a child policy task is still pending in its
+// closed parent, and returns the name of the child's domain
+func (t *transferQueueActiveProcessorImpl) loadChildPolicyTarget(task *persistence.TransferTaskInfo) (_ string, _ bool, retError error) {
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr(task.WorkflowID),
+		RunId:      common.StringPtr(task.RunID),
+	}
+
+	context, release, err := t.cache.getOrCreateWorkflowExecution(task.DomainID, execution)
+	if err != nil {
+		return "", false, err
+	}
+	defer func() { release(retError) }()
+
+	msBuilder, err := loadMutableStateForTransferTask(context, task, t.metricsClient, t.logger)
+	if err != nil {
+		return "", false, err
+	} else if msBuilder == nil || msBuilder.IsWorkflowExecutionRunning() {
+		// this can happen if workflow is reset.
+		return "", false, nil
+	}
+
+	ok, err := verifyTaskVersion(t.shard, t.logger, task.DomainID, msBuilder.GetLastWriteVersion(), task.Version, task)
+	if err != nil || !ok {
+		return "", false, err
+	}
+
+	if _, isPending := msBuilder.GetChildExecutionInfo(task.ScheduleID); !isPending {
+		t.logger.Debug("Child execution already completed.", tag.TaskID(task.TaskID), tag.WorkflowScheduleID(task.ScheduleID), tag.TaskType(task.TaskType))
+		return "", false, nil
+	}
+
+	domainEntry, err := t.shard.GetDomainCache().GetDomainByID(task.TargetDomainID)
+	if err != nil {
+		if _, ok := err.(*workflow.EntityNotExistsError); ok {
+			// the child domain got deleted, there is nothing left to apply the child policy to
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return domainEntry.GetInfo().Name, true, nil
+}
+
 func (t *transferQueueActiveProcessorImpl) processResetWorkflow(task *persistence.TransferTaskInfo) (retError error) {
 	var err error
 	execution := workflow.WorkflowExecution{
@@ -1016,7 +1205,7 @@ func (t *transferQueueActiveProcessorImpl) recordChildExecutionStarted(task *per
 	context workflowExecutionContext, initiatedAttributes *workflow.StartChildWorkflowExecutionInitiatedEventAttributes,
 	runID string) error {
 
-	return t.updateWorkflowExecution(task.DomainID, context, true,
+	return t.updateWorkflowExecution(metrics.TransferActiveTaskStartChildExecutionScope, task.DomainID, context, true,
 		func(msBuilder mutableState) error {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return &workflow.EntityNotExistsError{Message: "Workflow execution already completed."}
@@ -1048,7 +1237,7 @@ func (t *transferQueueActiveProcessorImpl) recordStartChildExecutionFailed(task
 	context workflowExecutionContext,
 	initiatedAttributes *workflow.StartChildWorkflowExecutionInitiatedEventAttributes) error {
 
-	return t.updateWorkflowExecution(task.DomainID, context, true,
+	return t.updateWorkflowExecution(metrics.TransferActiveTaskStartChildExecutionScope, task.DomainID, context, true,
 		func(msBuilder mutableState) error {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return &workflow.EntityNotExistsError{Message: "Workflow execution already completed."}
@@ -1071,27 +1260,54 @@ func (t *transferQueueActiveProcessorImpl) recordStartChildExecutionFailed(task
 // child execution.
 func (t *transferQueueActiveProcessorImpl) createFirstDecisionTask(domainID string,
 	execution *workflow.WorkflowExecution) error {
-	err := t.historyClient.ScheduleDecisionTask(nil, &h.ScheduleDecisionTaskRequest{
+	return t.historyClient.ScheduleDecisionTask(nil, &h.ScheduleDecisionTaskRequest{
 		DomainUUID:        common.StringPtr(domainID),
 		WorkflowExecution: execution,
 		IsFirstDecision:   common.BoolPtr(true),
 	})
+}
 
-	if err != nil {
-		if _, ok := err.(*workflow.EntityNotExistsError); ok {
-			// Maybe child workflow execution already timedout or terminated
-			// Safe to discard the error and complete this transfer task
-			return nil
-		}
-	}
+// recordChildExecutionUnschedulable reports a started child execution whose first decision can never be scheduled
+// to the parent execution as failed
+func (t *transferQueueActiveProcessorImpl) recordChildExecutionUnschedulable(task *persistence.TransferTaskInfo,
+	childExecution *workflow.WorkflowExecution, scheduleErr error) error {
+
+	t.logger.Warn("Child workflow first decision cannot be scheduled, reporting child as failed to parent.",
+		tag.WorkflowDomainID(task.DomainID),
+		tag.WorkflowID(task.WorkflowID),
+		tag.WorkflowRunID(task.RunID),
+		tag.WorkflowScheduleID(task.ScheduleID),
+		tag.Error(scheduleErr))
 
+	err := t.historyClient.RecordChildExecutionCompleted(nil, &h.RecordChildExecutionCompletedRequest{
+		DomainUUID: common.StringPtr(task.DomainID),
+		WorkflowExecution: &workflow.WorkflowExecution{
+			WorkflowId: common.StringPtr(task.WorkflowID),
+			RunId:      common.StringPtr(task.RunID),
+		},
+		InitiatedId:        common.Int64Ptr(task.ScheduleID),
+		CompletedExecution: childExecution,
+		CompletionEvent: &workflow.HistoryEvent{
+			EventType: common.EventTypePtr(workflow.EventTypeWorkflowExecutionFailed),
+			WorkflowExecutionFailedEventAttributes: &workflow.WorkflowExecutionFailedEventAttributes{
+				Reason:  common.StringPtr(common.FailureReasonChildFirstDecisionUnschedulable),
+				Details: []byte(scheduleErr.Error()),
+			},
+		},
+	})
+
+	// Check to see if the error is non-transient, in which case reset the error and continue with processing
+	switch err.(type) {
+	case *workflow.EntityNotExistsError:
+		err = nil
+	}
 	return err
 }
 
 func (t *transferQueueActiveProcessorImpl) requestCancelCompleted(task *persistence.TransferTaskInfo,
 	context workflowExecutionContext, request *h.RequestCancelWorkflowExecutionRequest) error {
 
-	return t.updateWorkflowExecution(task.DomainID, context, true,
+	return t.updateWorkflowExecution(metrics.TransferActiveTaskCancelExecutionScope, task.DomainID, context, true,
 		func(msBuilder mutableState) error {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return &workflow.EntityNotExistsError{Message: "Workflow execution already completed."}
@@ -1118,7 +1334,7 @@ func (t *transferQueueActiveProcessorImpl) requestSignalCompleted(task *persiste
 	context workflowExecutionContext,
 	request *h.SignalWorkflowExecutionRequest) error {
 
-	return t.updateWorkflowExecution(task.DomainID, context, true,
+	return t.updateWorkflowExecution(metrics.TransferActiveTaskSignalExecutionScope, task.DomainID, context, true,
 		func(msBuilder mutableState) error {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return &workflow.EntityNotExistsError{Message: "Workflow execution already completed."}
@@ -1144,7 +1360,7 @@ func (t *transferQueueActiveProcessorImpl) requestSignalCompleted(task *persiste
 func (t *transferQueueActiveProcessorImpl) requestCancelFailed(task *persistence.TransferTaskInfo,
 	context workflowExecutionContext, request *h.RequestCancelWorkflowExecutionRequest) error {
 
-	return t.updateWorkflowExecution(task.DomainID, context, true,
+	return t.updateWorkflowExecution(metrics.TransferActiveTaskCancelExecutionScope, task.DomainID, context, true,
 		func(msBuilder mutableState) error {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return &workflow.EntityNotExistsError{Message: "Workflow execution already completed."}
@@ -1172,7 +1388,7 @@ func (t *transferQueueActiveProcessorImpl) requestSignalFailed(task *persistence
 	context workflowExecutionContext,
 	request *h.SignalWorkflowExecutionRequest) error {
 
-	return t.updateWorkflowExecution(task.DomainID, context, true,
+	return t.updateWorkflowExecution(metrics.TransferActiveTaskSignalExecutionScope, task.DomainID, context, true,
 		func(msBuilder mutableState) error {
 			if !msBuilder.IsWorkflowExecutionRunning() {
 				return &workflow.EntityNotExistsError{Message: "Workflow is not running."}
@@ -1197,7 +1413,7 @@ func (t *transferQueueActiveProcessorImpl) requestSignalFailed(task *persistence
 		})
 }
 
-func (t *transferQueueActiveProcessorImpl) updateWorkflowExecution(domainID string, context workflowExecutionContext,
+func (t *transferQueueActiveProcessorImpl) updateWorkflowExecution(scope int, domainID string, context workflowExecutionContext,
 	createDecisionTask bool, action func(builder mutableState) error) error {
 Update_History_Loop:
 	for attempt := 0; attempt < conditionalRetryCount; attempt++ {
@@ -1230,6 +1446,7 @@ Update_History_Loop:
 		// We apply the update to execution using optimistic concurrency.  If it fails due to a conflict then reload
 		// the history and try the operation again.
 		if err := context.updateWorkflowExecution(transferTasks, timerTasks, transactionID); err != nil {
+			emitUpdateError(t.metricsClient, scope, err)
 			if err == ErrConflict {
 				continue Update_History_Loop
 			}
@@ -1241,6 +1458,7 @@ Update_History_Loop:
 		return nil
 	}
 
+	emitUpdateFailure(t.metricsClient, scope, updateFailureCauseMaxAttempts)
 	return ErrMaxAttemptsExceeded
 }
 
@@ -1378,6 +1378,99 @@ func (s *transferQueueActiveProcessorSuite) TestProcessStartChildExecution_Succe
 	s.Nil(err)
 }
 
+func (s *transferQueueActiveProcessorSuite) TestProcessStartChildExecution_Started_TargetDomainDeleted() {
+	domainID := "some random domain ID"
+	domainName := "some random domain Name"
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("some random workflow ID"),
+		RunId:      common.StringPtr(uuid.New()),
+	}
+	workflowType := "some random workflow type"
+	taskListName := "some random task list"
+
+	childDomainID := "some random child domain ID"
+	childWorkflowID := "some random child workflow ID"
+	childRunID := uuid.New()
+	childWorkflowType := "some random child workflow type"
+	childTaskListName := "some random child task list"
+
+	msBuilder := newMutableStateBuilderWithReplicationStateWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(),
+		s.mockShard, s.mockShard.GetEventsCache(), s.logger, s.version, execution.GetRunId())
+	_, err := msBuilder.AddWorkflowExecutionStartedEvent(
+		execution,
+		&history.StartWorkflowExecutionRequest{
+			DomainUUID: common.StringPtr(domainID),
+			StartRequest: &workflow.StartWorkflowExecutionRequest{
+				WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr(workflowType)},
+				TaskList:                            &workflow.TaskList{Name: common.StringPtr(taskListName)},
+				ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(2),
+				TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(1),
+			},
+		},
+	)
+	s.Nil(err)
+
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	event := addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, taskListName, uuid.New())
+	di.StartedID = event.GetEventId()
+	event = addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID, di.StartedID, nil, "some random identity")
+
+	taskID := int64(59)
+	event, ci := addStartChildWorkflowExecutionInitiatedEvent(msBuilder, event.GetEventId(), uuid.New(),
+		childDomainID, childWorkflowID, childWorkflowType, childTaskListName, nil, 1, 1)
+
+	transferTask := &persistence.TransferTaskInfo{
+		Version:          s.version,
+		DomainID:         domainID,
+		WorkflowID:       execution.GetWorkflowId(),
+		RunID:            execution.GetRunId(),
+		TargetDomainID:   childDomainID,
+		TargetWorkflowID: childWorkflowID,
+		TargetRunID:      "",
+		TaskID:           taskID,
+		TaskList:         taskListName,
+		TaskType:         persistence.TransferTaskTypeStartChildExecution,
+		ScheduleID:       event.GetEventId(),
+	}
+
+	event = addChildWorkflowExecutionStartedEvent(msBuilder, event.GetEventId(), childDomainID, childWorkflowID, childRunID, childWorkflowType)
+	ci.StartedID = event.GetEventId()
+	msBuilder.UpdateReplicationStateLastEventID(s.mockClusterMetadata.GetCurrentClusterName(), s.version, event.GetEventId())
+
+	persistenceMutableState := createMutableState(msBuilder)
+	s.mockMetadataMgr.ExpectedCalls = nil
+	s.mockMetadataMgr.On("GetDomain", &persistence.GetDomainRequest{ID: domainID}).Return(&persistence.GetDomainResponse{
+		Info:              &persistence.DomainInfo{Name: domainName},
+		Config:            &persistence.DomainConfig{},
+		ReplicationConfig: &persistence.DomainReplicationConfig{},
+		FailoverVersion:   s.version,
+		TableVersion:      persistence.DomainTableVersionV1,
+	}, nil).Once()
+	s.mockMetadataMgr.On("GetDomain", &persistence.GetDomainRequest{ID: childDomainID}).Return(nil, &workflow.EntityNotExistsError{})
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(&persistence.GetWorkflowExecutionResponse{State: persistenceMutableState}, nil)
+	s.mockHistoryClient.On("ScheduleDecisionTask", nil, &history.ScheduleDecisionTaskRequest{
+		DomainUUID: common.StringPtr(childDomainID),
+		WorkflowExecution: &workflow.WorkflowExecution{
+			WorkflowId: common.StringPtr(childWorkflowID),
+			RunId:      common.StringPtr(childRunID),
+		},
+		IsFirstDecision: common.BoolPtr(true),
+	}).Return(&workflow.EntityNotExistsError{}).Once()
+	s.mockHistoryClient.On("RecordChildExecutionCompleted", nil, mock.MatchedBy(func(request *history.RecordChildExecutionCompletedRequest) bool {
+		return request.GetDomainUUID() == domainID &&
+			request.WorkflowExecution.GetWorkflowId() == execution.GetWorkflowId() &&
+			request.WorkflowExecution.GetRunId() == execution.GetRunId() &&
+			request.GetInitiatedId() == transferTask.ScheduleID &&
+			request.CompletedExecution.GetWorkflowId() == childWorkflowID &&
+			request.CompletedExecution.GetRunId() == childRunID &&
+			request.CompletionEvent.GetEventType() == workflow.EventTypeWorkflowExecutionFailed &&
+			request.CompletionEvent.WorkflowExecutionFailedEventAttributes.GetReason() == common.FailureReasonChildFirstDecisionUnschedulable
+	})).Return(nil).Once()
+
+	_, err = s.transferQueueActiveProcessor.process(transferTask, true)
+	s.Nil(err)
+}
+
 func (s *transferQueueActiveProcessorSuite) TestProcessStartChildExecution_Duplication() {
 	domainID := "some random domain ID"
 	domainName := "some random domain Name"
@@ -1521,6 +1614,73 @@ func (s *transferQueueActiveProcessorSuite) TestProcessRecordWorkflowStartedTask
 	s.Nil(err)
 }
 
+func (s *transferQueueActiveProcessorSuite) TestProcessUpsertWorkflowMemoTask() {
+	domainID := "some random domain ID"
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("some random workflow ID"),
+		RunId:      common.StringPtr(uuid.New()),
+	}
+	workflowType := "some random workflow type"
+	taskListName := "some random task list"
+
+	msBuilder := newMutableStateBuilderWithReplicationStateWithEventV2(s.mockClusterMetadata.GetCurrentClusterName(),
+		s.mockShard, s.mockShard.GetEventsCache(), s.logger, s.version, execution.GetRunId())
+
+	event, err := msBuilder.AddWorkflowExecutionStartedEvent(
+		execution,
+		&history.StartWorkflowExecutionRequest{
+			DomainUUID: common.StringPtr(domainID),
+			StartRequest: &workflow.StartWorkflowExecutionRequest{
+				WorkflowType:                        &workflow.WorkflowType{Name: common.StringPtr(workflowType)},
+				TaskList:                            &workflow.TaskList{Name: common.StringPtr(taskListName)},
+				ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(2),
+				TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(1),
+				Memo: &workflow.Memo{Fields: map[string][]byte{
+					"kept":    []byte("kept value"),
+					"updated": []byte("old value"),
+				}},
+			},
+		},
+	)
+	s.Nil(err)
+
+	di := addDecisionTaskScheduledEvent(msBuilder)
+	event = addDecisionTaskStartedEvent(msBuilder, di.ScheduleID, taskListName, uuid.New())
+	di.StartedID = event.GetEventId()
+	event = addDecisionTaskCompletedEvent(msBuilder, di.ScheduleID, di.StartedID, nil, "some random identity")
+
+	event, err = msBuilder.AddUpsertWorkflowMemoEvent(event.GetEventId(), &workflow.UpsertWorkflowMemoDecisionAttributes{
+		Memo: &workflow.Memo{Fields: map[string][]byte{
+			"updated": []byte("new value"),
+		}},
+	})
+	s.Nil(err)
+
+	taskID := int64(59)
+	transferTask := &persistence.TransferTaskInfo{
+		Version:    s.version,
+		DomainID:   domainID,
+		WorkflowID: execution.GetWorkflowId(),
+		RunID:      execution.GetRunId(),
+		TaskID:     taskID,
+		TaskType:   persistence.TransferTaskTypeUpsertWorkflowMemo,
+		ScheduleID: event.GetEventId(),
+	}
+
+	persistenceMutableState := createMutableState(msBuilder)
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything).Return(&persistence.GetWorkflowExecutionResponse{State: persistenceMutableState}, nil)
+	recordRequest := s.createRecordWorkflowExecutionStartedRequest(transferTask, msBuilder, 0)
+	recordRequest.ExecutionTimestamp = 0
+	recordRequest.Memo = &workflow.Memo{Fields: map[string][]byte{
+		"kept":    []byte("kept value"),
+		"updated": []byte("new value"),
+	}}
+	s.mockVisibilityMgr.On("RecordWorkflowExecutionStarted", recordRequest).Once().Return(nil)
+
+	_, err = s.transferQueueActiveProcessor.process(transferTask, true)
+	s.Nil(err)
+}
+
 func (s *transferQueueActiveProcessorSuite) createAddActivityTaskRequest(task *persistence.TransferTaskInfo,
 	ai *persistence.ActivityInfo) *matching.AddActivityTaskRequest {
 	execution := workflow.WorkflowExecution{
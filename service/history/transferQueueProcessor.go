@@ -69,6 +69,10 @@ func newTransferQueueProcessor(shard ShardContext, historyService *historyEngine
 	visibilityMgr persistence.VisibilityManager, matchingClient matching.Client,
 	historyClient history.Client, logger log.Logger) *transferQueueProcessorImpl {
 	logger = logger.WithTags(tag.ComponentTransferQueue)
+	matchingClient = newMatchingCircuitBreakerClient(
+		matchingClient,
+		newMatchingCircuitBreaker(shard.GetShardID(), shard.GetConfig(), shard.GetTimeSource(), historyService.metricsClient),
+	)
 	currentClusterName := shard.GetService().GetClusterMetadata().GetCurrentClusterName()
 	taskAllocator := newTaskAllocator(shard)
 	standbyTaskProcessors := make(map[string]*transferQueueStandbyProcessorImpl)
@@ -206,6 +210,12 @@ func (t *transferQueueProcessorImpl) UnlockTaskPrrocessing() {
 	t.taskAllocator.unlock()
 }
 
+func (t *transferQueueProcessorImpl) describeStatus() *QueueProcessorStatus {
+	status := t.activeTaskProcessor.describeStatus()
+	status.Locked = t.taskAllocator.isLocked()
+	return status
+}
+
 func (t *transferQueueProcessorImpl) completeTransferLoop() {
 	timer := time.NewTimer(t.config.TransferProcessorCompleteTransferInterval())
 	defer timer.Stop()
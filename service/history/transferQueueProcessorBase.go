@@ -241,7 +241,11 @@ func getWorkflowExecutionTimestamp(msBuilder mutableState, startEvent *workflow.
 	return executionTimestamp
 }
 
-func getVisibilityMemo(startEvent *workflow.HistoryEvent) *workflow.Memo {
+func getVisibilityMemo(executionInfo *persistence.WorkflowExecutionInfo, startEvent *workflow.HistoryEvent) *workflow.Memo {
+	if executionInfo != nil && executionInfo.Memo != nil {
+		return &workflow.Memo{Fields: executionInfo.Memo}
+	}
+	// workflows started before the memo was kept in execution info only have it in their start event
 	if startEvent == nil {
 		return nil
 	}
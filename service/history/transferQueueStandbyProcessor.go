@@ -169,6 +169,17 @@ func (t *transferQueueStandbyProcessorImpl) process(qTask queueTaskInfo, shouldP
 	case persistence.TransferTaskTypeResetWorkflow:
 		// no reset needed for standby
 		return metrics.TransferStandbyTaskResetWorkflowScope, err
+	case persistence.TransferTaskTypeUpsertWorkflowMemo:
+		if shouldProcessTask {
+			err = t.processUpsertWorkflowMemo(task)
+		}
+		return metrics.TransferStandbyTaskUpsertWorkflowMemoScope, err
+	case persistence.TransferTaskTypeTerminateChildExecution:
+		// child policy is only applied by the active cluster
+		return metrics.TransferStandbyTaskTerminateChildScope, err
+	case persistence.TransferTaskTypeCancelChildExecution:
+		// child policy is only applied by the active cluster
+		return metrics.TransferStandbyTaskCancelChildScope, err
 	default:
 		return metrics.TransferStandbyQueueProcessorScope, errUnknownTransferTask
 	}
@@ -292,7 +303,7 @@ func (t *transferQueueStandbyProcessorImpl) processCloseExecution(transferTask *
 			return &workflow.InternalServiceError{Message: "Failed to load start event."}
 		}
 		workflowExecutionTimestamp := getWorkflowExecutionTimestamp(msBuilder, startEvent)
-		visibilityMemo := getVisibilityMemo(startEvent)
+		visibilityMemo := getVisibilityMemo(executionInfo, startEvent)
 		searchAttr := executionInfo.SearchAttributes
 
 		ok, err := verifyTaskVersion(t.shard, t.logger, transferTask.DomainID, msBuilder.GetLastWriteVersion(), transferTask.Version, transferTask)
@@ -449,7 +460,35 @@ func (t *transferQueueStandbyProcessorImpl) processRecordWorkflowStarted(transfe
 			return &workflow.InternalServiceError{Message: "Failed to load start event."}
 		}
 		executionTimestamp := getWorkflowExecutionTimestamp(msBuilder, startEvent)
-		visibilityMemo := getVisibilityMemo(startEvent)
+		visibilityMemo := getVisibilityMemo(executionInfo, startEvent)
+		searchAttr := executionInfo.SearchAttributes
+
+		return t.recordWorkflowStarted(transferTask.DomainID, execution, wfTypeName, startTimestamp, executionTimestamp.UnixNano(),
+			workflowTimeout, transferTask.GetTaskID(), visibilityMemo, searchAttr)
+	}, standbyTaskPostActionNoOp)
+}
+
+func (t *transferQueueStandbyProcessorImpl) processUpsertWorkflowMemo(transferTask *persistence.TransferTaskInfo) error {
+	processTaskIfClosed := false
+
+	execution := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr(transferTask.WorkflowID),
+		RunId:      common.StringPtr(transferTask.RunID),
+	}
+
+	return t.processTransfer(processTaskIfClosed, transferTask, func(msBuilder mutableState) error {
+		// no task version check here, the open record is rebuilt from the current mutable state
+		// so processing a stale task still writes the latest memo
+		executionInfo := msBuilder.GetExecutionInfo()
+		workflowTimeout := executionInfo.WorkflowTimeout
+		wfTypeName := executionInfo.WorkflowTypeName
+		startTimestamp := executionInfo.StartTimestamp.UnixNano()
+		startEvent, found := msBuilder.GetStartEvent()
+		if !found {
+			return &workflow.InternalServiceError{Message: "Failed to load start event."}
+		}
+		executionTimestamp := getWorkflowExecutionTimestamp(msBuilder, startEvent)
+		visibilityMemo := getVisibilityMemo(executionInfo, startEvent)
 		searchAttr := executionInfo.SearchAttributes
 
 		return t.recordWorkflowStarted(transferTask.DomainID, execution, wfTypeName, startTimestamp, executionTimestamp.UnixNano(),
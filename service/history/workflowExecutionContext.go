@@ -55,7 +55,9 @@ type (
 			msBuilder mutableState, sourceCluster string, createReplicationTask bool, now time.Time,
 			transferTasks []persistence.Task, replicationTasks []persistence.Task, timerTasks []persistence.Task,
 			createMode int, prevRunID string, prevLastWriteVersion int64) error
+		getDecisionHeartbeatCount() int
 		getDomainID() string
+		getEmptyDecisionCount() int
 		getExecution() *workflow.WorkflowExecution
 		getLogger() log.Logger
 		loadWorkflowExecution() (mutableState, error)
@@ -71,6 +73,8 @@ type (
 			resetBuilder mutableState,
 		) (mutableState, error)
 		resetWorkflowExecution(currMutableState mutableState, updateCurr bool, closeTask, cleanupTask persistence.Task, newMutableState mutableState, transferTasks, timerTasks, currReplicationTasks, insertReplicationTasks []persistence.Task, baseRunID string, forkRunNextEventID, prevRunVersion int64) (retError error)
+		setDecisionHeartbeatCount(count int)
+		setEmptyDecisionCount(count int)
 		scheduleNewDecision(transferTasks []persistence.Task, timerTasks []persistence.Task) ([]persistence.Task, []persistence.Task, error)
 		unlock()
 		updateWorkflowExecutionForStandby(transferTasks []persistence.Task, timerTasks []persistence.Task, transactionID int64, now time.Time, createReplicationTask bool, standbyHistoryBuilder *historyBuilder, sourceCluster string) error
@@ -93,6 +97,16 @@ type (
 		msBuilder             mutableState
 		updateCondition       int64
 		createReplicationTask bool
+		// emptyDecisionCount is only used by the empty decision loop guard, it is the number of consecutive
+		// decisions which only forced a new decision within EmptyDecisionLoopMinInterval of their start,
+		// it is not persisted and starts over whenever the context is created again
+		emptyDecisionCount int
+		// decisionHeartbeatCount is the number of consecutive decisions which only forced a new decision,
+		// however long they took, it is not persisted either
+		decisionHeartbeatCount int
+		// timeoutRepairTask is the workflow timeout timer found missing when the mutable state was loaded from
+		// persistence, it is written along with the next update of the workflow
+		timeoutRepairTask persistence.Task
 	}
 )
 
@@ -188,6 +202,13 @@ func (c *workflowExecutionContextImpl) loadWorkflowExecutionInternal() error {
 	c.msBuilder = msBuilder
 	// finally emit execution and session stats
 	c.emitWorkflowExecutionStats(response.MutableStateStats, c.msBuilder.GetHistorySize())
+
+	// the timer queue is only checked on a cache miss, a failed check is done again the next time the workflow is loaded
+	timeoutRepairTask, err := c.getWorkflowTimeoutRepairTask()
+	if err != nil {
+		c.logger.Warn("Failed to check the workflow timeout timer.", tag.Error(err))
+	}
+	c.timeoutRepairTask = timeoutRepairTask
 	return nil
 }
 
@@ -248,6 +269,7 @@ func (c *workflowExecutionContextImpl) createWorkflowExecution(
 		CronSchedule:                executionInfo.CronSchedule,
 		ReplicationState:            replicationState,
 		SearchAttributes:            executionInfo.SearchAttributes,
+		Memo:                        executionInfo.Memo,
 
 		// retry policy
 		HasRetryPolicy:     executionInfo.HasRetryPolicy,
@@ -527,8 +549,16 @@ func (c *workflowExecutionContextImpl) updateWorkflowExecutionForActive(transfer
 		}
 	}
 
+	if c.timeoutRepairTask != nil {
+		timerTasks = append(timerTasks, c.timeoutRepairTask)
+	}
+
 	now := time.Now()
-	return c.update(transferTasks, timerTasks, transactionID, now, c.createReplicationTask, nil, "", newStateBuilder)
+	if err := c.update(transferTasks, timerTasks, transactionID, now, c.createReplicationTask, nil, "", newStateBuilder); err != nil {
+		return err
+	}
+	c.timeoutRepairTask = nil
+	return nil
 }
 
 func (c *workflowExecutionContextImpl) updateWorkflowExecution(transferTasks []persistence.Task,
@@ -654,13 +684,6 @@ func (c *workflowExecutionContextImpl) update(transferTasks []persistence.Task,
 		executionInfo.SetLastFirstEventID(activeHistoryBuilder.history[0].GetEventId())
 		newHistorySize += size
 
-		// enforce history size/count limit (only on active side)
-		config := c.shard.GetConfig()
-		sizeLimitWarn := config.HistorySizeLimitWarn(executionInfo.DomainID)
-		countLimitWarn := config.HistoryCountLimitWarn(executionInfo.DomainID)
-		historyCount := int(c.msBuilder.GetNextEventID()) - 1
-		historySize := int(c.msBuilder.GetHistorySize()) + newHistorySize
-
 		// All execution stats are emitted under emitWorkflowExecutionStats which is only invoked when the mutableState
 		// is loaded.  Looks like MutableStateStats are returned by persistence layer when mutableState is loaded from DB.
 		// It is much better to emit the entire execution stats on each update.  So for now we are explicitly emitting
@@ -669,11 +692,36 @@ func (c *workflowExecutionContextImpl) update(transferTasks []persistence.Task,
 		if entry, err := c.shard.GetDomainCache().GetDomainByID(executionInfo.DomainID); err == nil && entry != nil && entry.GetInfo() != nil {
 			domain = entry.GetInfo().Name
 		}
+
+		// enforce history size/count limit (only on active side)
+		config := c.shard.GetConfig()
+		sizeLimitWarn := config.HistorySizeLimitWarn(executionInfo.DomainID)
+		countLimitWarn := config.HistoryCountLimitWarn(executionInfo.DomainID)
+		historyCount := int(c.msBuilder.GetNextEventID()) - 1
+		prevHistorySize := int(c.msBuilder.GetHistorySize())
+		historySize := prevHistorySize + newHistorySize
+
 		domainSizeScope := c.metricsClient.Scope(metrics.ExecutionSizeStatsScope, metrics.DomainTag(domain))
 		domainCountScope := c.metricsClient.Scope(metrics.ExecutionCountStatsScope, metrics.DomainTag(domain))
 		domainSizeScope.RecordTimer(metrics.HistorySize, time.Duration(historySize))
 		domainCountScope.RecordTimer(metrics.HistoryCount, time.Duration(historyCount))
 
+		// the warning is only emitted by the update which crosses the threshold, so that chatty workflows
+		// can be found before they hit the error limit without a metric/log per update afterwards
+		if prevHistorySize <= sizeLimitWarn && historySize > sizeLimitWarn {
+			c.metricsClient.Scope(
+				metrics.ExecutionSizeStatsScope,
+				metrics.DomainTag(domain),
+				metrics.WorkflowTypeTag(executionInfo.WorkflowTypeName),
+			).IncCounter(metrics.WorkflowHistorySizeWarnCounter)
+			c.shard.GetThrottledLogger().Warn("history size crosses warn limit.",
+				tag.WorkflowDomainName(domain),
+				tag.WorkflowType(executionInfo.WorkflowTypeName),
+				tag.WorkflowID(executionInfo.WorkflowID),
+				tag.WorkflowRunID(executionInfo.RunID),
+				tag.WorkflowHistorySize(historySize))
+		}
+
 		if historySize > sizeLimitWarn || historyCount > countLimitWarn {
 			// emit warning
 			c.logger.Warn("history size exceeds limit.",
@@ -1043,9 +1091,74 @@ func (c *workflowExecutionContextImpl) updateWorkflowExecutionWithRetry(
 	return resp, err
 }
 
+func (c *workflowExecutionContextImpl) getDecisionHeartbeatCount() int {
+	return c.decisionHeartbeatCount
+}
+
+func (c *workflowExecutionContextImpl) setDecisionHeartbeatCount(count int) {
+	c.decisionHeartbeatCount = count
+}
+
+func (c *workflowExecutionContextImpl) getEmptyDecisionCount() int {
+	return c.emptyDecisionCount
+}
+
+func (c *workflowExecutionContextImpl) setEmptyDecisionCount(count int) {
+	c.emptyDecisionCount = count
+}
+
+// getWorkflowTimeoutRepairTask returns a new workflow timeout timer for a running workflow whose timeout is still in the
+// future but which has no workflow timeout timer left in the timer queue, it returns nil when nothing needs repair
+func (c *workflowExecutionContextImpl) getWorkflowTimeoutRepairTask() (persistence.Task, error) {
+	domainEntry, err := c.shard.GetDomainCache().GetDomainByID(c.domainID)
+	if err != nil {
+		return nil, err
+	}
+	config := c.shard.GetConfig()
+	if !config.EnableWorkflowTimeoutRepair(domainEntry.GetInfo().Name) || !c.msBuilder.IsWorkflowExecutionRunning() {
+		return nil, nil
+	}
+
+	executionInfo := c.msBuilder.GetExecutionInfo()
+	timeout := getWorkflowTimeoutTime(c.msBuilder)
+	if !timeout.After(c.shard.GetTimeSource().Now()) {
+		return nil, nil
+	}
+
+	// the timer is created slightly after the start timestamp is taken, so look around the computed timeout
+	request := &persistence.GetTimerIndexTasksRequest{
+		MinTimestamp: timeout.Add(-workflowTimeoutRepairWindow),
+		MaxTimestamp: timeout.Add(workflowTimeoutRepairWindow),
+		BatchSize:    config.TimerTaskBatchSize(),
+	}
+	for {
+		response, err := c.executionManager.GetTimerIndexTasks(request)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range response.Timers {
+			if task.TaskType == persistence.TaskTypeWorkflowTimeout &&
+				task.DomainID == executionInfo.DomainID &&
+				task.WorkflowID == executionInfo.WorkflowID &&
+				task.RunID == executionInfo.RunID {
+				return nil, nil
+			}
+		}
+		if len(response.NextPageToken) == 0 {
+			break
+		}
+		request.NextPageToken = response.NextPageToken
+	}
+
+	c.logger.Warn("Workflow timeout timer is missing, scheduling a new one.")
+	c.metricsClient.IncCounter(metrics.WorkflowContextScope, metrics.WorkflowTimeoutTaskRepairedCounter)
+	return &persistence.WorkflowTimeoutTask{VisibilityTimestamp: timeout}, nil
+}
+
 func (c *workflowExecutionContextImpl) clear() {
 	c.metricsClient.IncCounter(metrics.WorkflowContextScope, metrics.WorkflowContextCleared)
 	c.msBuilder = nil
+	c.timeoutRepairTask = nil
 }
 
 // scheduleNewDecision is helper method which has the logic for scheduling new decision for a workflow execution.
@@ -1061,7 +1174,7 @@ func (c *workflowExecutionContextImpl) scheduleNewDecision(transferTasks []persi
 	}
 
 	executionInfo := msBuilder.GetExecutionInfo()
-	if !msBuilder.HasPendingDecisionTask() {
+	if canScheduleDecisionTask(msBuilder, c.shard, c.metricsClient, metrics.WorkflowContextScope) {
 		di, err := msBuilder.AddDecisionTaskScheduledEvent()
 		if err != nil {
 			return nil, nil, &workflow.InternalServiceError{Message: "Failed to add decision scheduled event."}
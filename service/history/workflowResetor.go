@@ -31,6 +31,8 @@ import (
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/cache"
 	ce "github.com/uber/cadence/common/errors"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
 	"github.com/uber/cadence/common/persistence"
 )
 
@@ -80,6 +82,11 @@ func (w *workflowResetorImpl) ResetWorkflowExecution(
 		return nil, retError
 	}
 
+	retError = w.validateResetLineage(baseMutableState, currMutableState, request.GetDecisionFinishEventId())
+	if retError != nil {
+		return nil, retError
+	}
+
 	resetNewRunID := uuid.New()
 	response := &workflow.ResetWorkflowExecutionResponse{
 		RunId: common.StringPtr(resetNewRunID),
@@ -181,6 +188,46 @@ func (w *workflowResetorImpl) validateResetWorkflowBeforeReplay(baseMutableState
 	return nil
 }
 
+// validateResetLineage rejects a reset of the base run if the current run was itself created by resetting the base run
+// at the same decision, resetting again would only recreate the current run. A reset run carries the base run ID on
+// the decision failed event written at the reset point, so only that event of the current run is read.
+func (w *workflowResetorImpl) validateResetLineage(
+	baseMutableState, currMutableState mutableState,
+	decisionFinishEventID int64,
+) error {
+	baseRunID := baseMutableState.GetExecutionInfo().RunID
+	currRunID := currMutableState.GetExecutionInfo().RunID
+	if baseRunID == currRunID ||
+		currMutableState.GetEventStoreVersion() != persistence.EventStoreVersionV2 ||
+		currMutableState.GetNextEventID() <= decisionFinishEventID {
+		return nil
+	}
+
+	response, err := w.eng.historyV2Mgr.ReadHistoryBranch(&persistence.ReadHistoryBranchRequest{
+		BranchToken: currMutableState.GetCurrentBranch(),
+		MinEventID:  decisionFinishEventID,
+		MaxEventID:  decisionFinishEventID + 1,
+		PageSize:    1,
+		ShardID:     common.IntPtr(w.eng.shard.GetShardID()),
+	})
+	if err != nil {
+		return err
+	}
+	for _, event := range response.HistoryEvents {
+		if event.GetEventId() != decisionFinishEventID || event.GetEventType() != workflow.EventTypeDecisionTaskFailed {
+			continue
+		}
+		attr := event.GetDecisionTaskFailedEventAttributes()
+		if attr.GetCause() == workflow.DecisionTaskFailedCauseResetWorkflow && attr.GetBaseRunId() == baseRunID {
+			return &workflow.BadRequestError{
+				Message: fmt.Sprintf("run %v was already reset at event %v into current run %v, reset the current run instead.",
+					baseRunID, decisionFinishEventID, currRunID),
+			}
+		}
+	}
+	return nil
+}
+
 func (w *workflowResetorImpl) validateResetWorkflowAfterReplay(newMutableState mutableState) error {
 	if retError := newMutableState.CheckResettable(); retError != nil {
 		return retError
@@ -254,9 +301,17 @@ func (w *workflowResetorImpl) buildNewMutableStateForReset(
 	baseRunID := baseMutableState.GetExecutionInfo().RunID
 
 	// replay history to reset point(exclusive) to rebuild mutableState
+	replayCtx := ctx
+	if maxReplayDuration := w.eng.config.ResetWorkflowMaxReplayDuration(domainEntry.GetInfo().Name); maxReplayDuration > 0 {
+		var cancel context.CancelFunc
+		replayCtx, cancel = context.WithTimeout(ctx, maxReplayDuration)
+		defer cancel()
+	}
+	sw := w.eng.metricsClient.StartTimer(metrics.HistoryResetWorkflowExecutionScope, metrics.ResetWorkflowReplayLatency)
 	forkEventVersion, wfTimeoutSecs, receivedSignals, continueRunID, newStateBuilder, retError := w.replayHistoryEvents(
-		resetDecisionCompletedEventID, requestedID, baseMutableState, newRunID,
+		replayCtx, resetDecisionCompletedEventID, requestedID, baseMutableState, newRunID,
 	)
+	sw.Stop()
 	if retError != nil {
 		return
 	}
@@ -433,8 +488,10 @@ func (w *workflowResetorImpl) replayReceivedSignals(
 			SignalName: se.GetWorkflowExecutionSignaledEventAttributes().SignalName,
 			Identity:   se.GetWorkflowExecutionSignaledEventAttributes().Identity,
 			Input:      se.GetWorkflowExecutionSignaledEventAttributes().Input,
+			Header:     se.GetWorkflowExecutionSignaledEventAttributes().Header,
 		}
-		newMutableState.AddWorkflowExecutionSignaled(sigReq.GetSignalName(), sigReq.GetInput(), sigReq.GetIdentity())
+		newMutableState.AddWorkflowExecutionSignaled(sigReq.GetSignalName(), sigReq.GetInput(), sigReq.GetIdentity(), sigReq.Header,
+			se.GetWorkflowExecutionSignaledEventAttributes().GetExpirationTimestamp())
 	}
 	for {
 		if len(continueRunID) == 0 {
@@ -466,7 +523,7 @@ func (w *workflowResetorImpl) replayReceivedSignals(
 			MinEventID:  common.FirstEventID,
 			// NOTE: read through history to the end so that we can collect all the received signals
 			MaxEventID:    continueMutableState.GetNextEventID(),
-			PageSize:      defaultHistoryPageSize,
+			PageSize:      w.historyPageSize(newMutableState.GetExecutionInfo().DomainID),
 			NextPageToken: nextPageToken,
 			ShardID:       common.IntPtr(w.eng.shard.GetShardID()),
 		}
@@ -483,8 +540,10 @@ func (w *workflowResetorImpl) replayReceivedSignals(
 							SignalName: e.GetWorkflowExecutionSignaledEventAttributes().SignalName,
 							Identity:   e.GetWorkflowExecutionSignaledEventAttributes().Identity,
 							Input:      e.GetWorkflowExecutionSignaledEventAttributes().Input,
+							Header:     e.GetWorkflowExecutionSignaledEventAttributes().Header,
 						}
-						newMutableState.AddWorkflowExecutionSignaled(sigReq.GetSignalName(), sigReq.GetInput(), sigReq.GetIdentity())
+						newMutableState.AddWorkflowExecutionSignaled(sigReq.GetSignalName(), sigReq.GetInput(), sigReq.GetIdentity(), sigReq.Header,
+							e.GetWorkflowExecutionSignaledEventAttributes().GetExpirationTimestamp())
 					} else if e.GetEventType() == workflow.EventTypeWorkflowExecutionContinuedAsNew {
 						attr := e.GetWorkflowExecutionContinuedAsNewEventAttributes()
 						continueRunID = attr.GetNewExecutionRunId()
@@ -547,6 +606,7 @@ func getRespondActivityTaskFailedRequestFromActivity(ai *persistence.ActivityInf
 
 // TODO: @shreyassrivatsan reduce the number of return parameters from this method or return a struct
 func (w *workflowResetorImpl) replayHistoryEvents(
+	ctx context.Context,
 	decisionFinishEventID int64,
 	requestID string,
 	prevMutableState mutableState,
@@ -565,7 +625,7 @@ func (w *workflowResetorImpl) replayHistoryEvents(
 		MinEventID:  common.FirstEventID,
 		// NOTE: read through history to the end so that we can keep the received signals
 		MaxEventID:    prevMutableState.GetNextEventID(),
-		PageSize:      defaultHistoryPageSize,
+		PageSize:      w.historyPageSize(domainID),
 		NextPageToken: nextPageToken,
 		ShardID:       common.IntPtr(w.eng.shard.GetShardID()),
 	}
@@ -573,6 +633,18 @@ func (w *workflowResetorImpl) replayHistoryEvents(
 	var lastBatch []*workflow.HistoryEvent
 
 	for {
+		// the base run and its context are locked during replay, give up once the deadline passes so the
+		// caller can release them and retry later
+		if ctx.Err() != nil {
+			w.eng.logger.Warn("Reset workflow aborted replaying history.",
+				tag.WorkflowDomainID(domainID),
+				tag.WorkflowID(prevExecution.GetWorkflowId()),
+				tag.WorkflowRunID(prevExecution.GetRunId()),
+				tag.Error(ctx.Err()))
+			retError = ErrResetReplayTimeout
+			return
+		}
+
 		var readResp *persistence.ReadHistoryBranchByBatchResponse
 		readResp, retError = w.eng.historyV2Mgr.ReadHistoryBranchByBatch(readReq)
 		if retError != nil {
@@ -803,6 +875,15 @@ func (w *workflowResetorImpl) ApplyResetEvent(
 	return nil
 }
 
+// historyPageSize returns the page size for reading the history of a run in the given domain
+func (w *workflowResetorImpl) historyPageSize(domainID string) int {
+	domainEntry, err := w.eng.shard.GetDomainCache().GetDomainByID(domainID)
+	if err != nil {
+		return defaultHistoryPageSize
+	}
+	return w.eng.config.HistoryPageSize(domainEntry.GetInfo().Name)
+}
+
 // TODO: @shreyassrivatsan reduce number of return parameters from this method
 func (w *workflowResetorImpl) replicateResetEvent(
 	baseMutableState mutableState,
@@ -829,7 +910,7 @@ func (w *workflowResetorImpl) replicateResetEvent(
 		BranchToken:   baseMutableState.GetCurrentBranch(),
 		MinEventID:    common.FirstEventID,
 		MaxEventID:    decisionFinishEventID,
-		PageSize:      defaultHistoryPageSize,
+		PageSize:      w.historyPageSize(domainID),
 		NextPageToken: nextPageToken,
 		ShardID:       common.IntPtr(w.eng.shard.GetShardID()),
 	}
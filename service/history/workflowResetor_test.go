@@ -49,6 +49,7 @@ import (
 	"github.com/uber/cadence/common/mocks"
 	p "github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/service"
+	"github.com/uber/cadence/common/service/dynamicconfig"
 	"github.com/uber/cadence/service/worker/archiver"
 )
 
@@ -848,6 +849,133 @@ func (s *resetorSuite) TestResetWorkflowExecution_NoReplication() {
 	s.Nil(resetReq.InsertSignalRequestedIDs)
 }
 
+func (s *resetorSuite) TestReplayHistoryEvents_DeadlineExceeded() {
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	msBuilder := newMutableStateBuilderWithEventV2(cluster.TestCurrentClusterName, s.historyEngine.shard, s.mockEventsCache,
+		s.logger, we.GetRunId())
+	msBuilder.GetExecutionInfo().DomainID = validDomainID
+	msBuilder.GetExecutionInfo().WorkflowID = we.GetWorkflowId()
+	s.mockDomainCache.On("GetDomainByID", validDomainID).Return(
+		cache.NewLocalDomainCacheEntryForTest(&p.DomainInfo{ID: validDomainID}, &p.DomainConfig{}, "", nil), nil,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resetor := s.resetor.(*workflowResetorImpl)
+	_, _, _, _, _, err := resetor.replayHistoryEvents(ctx, common.FirstEventID+5, uuid.New().String(), msBuilder, uuid.New().String())
+	s.Equal(ErrResetReplayTimeout, err)
+	s.True(common.IsServiceTransientError(err))
+	s.mockHistoryV2Mgr.AssertNotCalled(s.T(), "ReadHistoryBranchByBatch", mock.Anything)
+}
+
+func (s *resetorSuite) TestReplayHistoryEvents_UsesConfiguredPageSize() {
+	we := workflow.WorkflowExecution{
+		WorkflowId: common.StringPtr("wId"),
+		RunId:      common.StringPtr(validRunID),
+	}
+	msBuilder := newMutableStateBuilderWithEventV2(cluster.TestCurrentClusterName, s.historyEngine.shard, s.mockEventsCache,
+		s.logger, we.GetRunId())
+	msBuilder.GetExecutionInfo().DomainID = validDomainID
+	msBuilder.GetExecutionInfo().WorkflowID = we.GetWorkflowId()
+
+	testDomainEntry := cache.NewLocalDomainCacheEntryForTest(
+		&p.DomainInfo{ID: validDomainID, Name: "testDomain"}, &p.DomainConfig{Retention: 1}, "", nil,
+	)
+	s.mockDomainCache.On("GetDomainByID", validDomainID).Return(testDomainEntry, nil)
+	originalPageSize := s.config.HistoryPageSize
+	defer func() { s.config.HistoryPageSize = originalPageSize }()
+	s.config.HistoryPageSize = dynamicconfig.GetIntPropertyFilteredByDomain(7)
+
+	readErr := &workflow.InternalServiceError{Message: "read failed"}
+	s.mockHistoryV2Mgr.On("ReadHistoryBranchByBatch", mock.MatchedBy(func(req *p.ReadHistoryBranchRequest) bool {
+		return req.PageSize == 7
+	})).Return(nil, readErr).Once()
+
+	resetor := s.resetor.(*workflowResetorImpl)
+	_, _, _, _, _, err := resetor.replayHistoryEvents(context.Background(), common.FirstEventID+5, uuid.New().String(), msBuilder, uuid.New().String())
+	s.Equal(readErr, err)
+}
+
+func (s *resetorSuite) TestResetWorkflowExecution_AlreadyResetFromBase() {
+	testDomainEntry := cache.NewLocalDomainCacheEntryForTest(
+		&p.DomainInfo{ID: validDomainID, Name: "testDomain"}, &p.DomainConfig{Retention: 1}, "", nil,
+	)
+	s.mockDomainCache.On("GetDomain", "testDomain").Return(testDomainEntry, nil)
+
+	wid := "wId"
+	baseRunID := uuid.New().String()
+	currRunID := uuid.New().String()
+	resetEventID := int64(29)
+
+	// the base run was reset at event 29 into the current run
+	baseMutableState := newMutableStateBuilderWithEventV2(cluster.TestCurrentClusterName, s.historyEngine.shard, s.mockEventsCache,
+		s.logger, baseRunID)
+	baseMutableState.GetExecutionInfo().DomainID = validDomainID
+	baseMutableState.GetExecutionInfo().WorkflowID = wid
+	baseMutableState.GetExecutionInfo().RunID = baseRunID
+	baseMutableState.GetExecutionInfo().NextEventID = 34
+	currMutableState := newMutableStateBuilderWithEventV2(cluster.TestCurrentClusterName, s.historyEngine.shard, s.mockEventsCache,
+		s.logger, currRunID)
+	currMutableState.GetExecutionInfo().DomainID = validDomainID
+	currMutableState.GetExecutionInfo().WorkflowID = wid
+	currMutableState.GetExecutionInfo().RunID = currRunID
+	currMutableState.GetExecutionInfo().NextEventID = 35
+	currMutableState.GetExecutionInfo().BranchToken = []byte("currBranchToken")
+
+	s.mockHistoryV2Mgr.On("ReadHistoryBranch", &p.ReadHistoryBranchRequest{
+		BranchToken: []byte("currBranchToken"),
+		MinEventID:  resetEventID,
+		MaxEventID:  resetEventID + 1,
+		PageSize:    1,
+		ShardID:     common.IntPtr(s.shardID),
+	}).Return(&p.ReadHistoryBranchResponse{
+		HistoryEvents: []*workflow.HistoryEvent{
+			{
+				EventId:   common.Int64Ptr(resetEventID),
+				EventType: common.EventTypePtr(workflow.EventTypeDecisionTaskFailed),
+				DecisionTaskFailedEventAttributes: &workflow.DecisionTaskFailedEventAttributes{
+					Cause:     common.DecisionTaskFailedCausePtr(workflow.DecisionTaskFailedCauseResetWorkflow),
+					BaseRunId: common.StringPtr(baseRunID),
+					NewRunId:  common.StringPtr(currRunID),
+				},
+			},
+		},
+	}, nil).Once()
+
+	// resetting the base run again at the same decision would recreate the current run
+	_, err := s.resetor.ResetWorkflowExecution(context.Background(), &workflow.ResetWorkflowExecutionRequest{
+		Domain: common.StringPtr("testDomain"),
+		WorkflowExecution: &workflow.WorkflowExecution{
+			WorkflowId: common.StringPtr(wid),
+			RunId:      common.StringPtr(baseRunID),
+		},
+		Reason:                common.StringPtr("test reset again"),
+		DecisionFinishEventId: common.Int64Ptr(resetEventID),
+		RequestId:             common.StringPtr(uuid.New().String()),
+	}, nil, baseMutableState, nil, currMutableState)
+	s.IsType(&workflow.BadRequestError{}, err)
+	s.True(currMutableState.IsWorkflowExecutionRunning())
+	s.mockHistoryV2Mgr.AssertNotCalled(s.T(), "ReadHistoryBranchByBatch", mock.Anything)
+
+	// the current run is not the result of resetting the base run at an earlier decision
+	s.mockHistoryV2Mgr.On("ReadHistoryBranch", mock.MatchedBy(func(req *p.ReadHistoryBranchRequest) bool {
+		return req.MinEventID == resetEventID-4
+	})).Return(&p.ReadHistoryBranchResponse{
+		HistoryEvents: []*workflow.HistoryEvent{
+			{
+				EventId:   common.Int64Ptr(resetEventID - 4),
+				EventType: common.EventTypePtr(workflow.EventTypeDecisionTaskCompleted),
+			},
+		},
+	}, nil).Once()
+	resetor := s.resetor.(*workflowResetorImpl)
+	s.NoError(resetor.validateResetLineage(baseMutableState, currMutableState, resetEventID-4))
+}
+
 func (s *resetorSuite) assertTimerIDs(ids []string, timers []*p.TimerInfo) {
 	m := map[string]bool{}
 	for _, s := range ids {
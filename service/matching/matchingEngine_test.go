@@ -236,6 +236,8 @@ func (s *matchingEngineSuite) PollForDecisionTasksResultTest() {
 	}
 	execution := workflow.WorkflowExecution{RunId: &runID, WorkflowId: &workflowID}
 	scheduleID := int64(0)
+	scheduledTimestamp := time.Now().Add(-time.Minute).UnixNano()
+	scheduledTimestampOfThisAttempt := time.Now().UnixNano()
 
 	// History service is using mock
 	s.historyClient.On("RecordDecisionTaskStarted", mock.Anything,
@@ -246,7 +248,9 @@ func (s *matchingEngineSuite) PollForDecisionTasksResultTest() {
 			response.WorkflowType = &workflowType
 			response.PreviousStartedEventId = common.Int64Ptr(scheduleID)
 			response.ScheduledEventId = common.Int64Ptr(scheduleID + 1)
-			response.Attempt = common.Int64Ptr(0)
+			response.Attempt = common.Int64Ptr(1)
+			response.ScheduledTimestamp = common.Int64Ptr(scheduledTimestamp)
+			response.ScheduledTimestampOfThisAttempt = common.Int64Ptr(scheduledTimestampOfThisAttempt)
 			response.StickyExecutionEnabled = common.BoolPtr(true)
 			response.WorkflowExecutionTaskList = common.TaskListPtr(workflow.TaskList{
 				Name: &tl,
@@ -281,13 +285,15 @@ func (s *matchingEngineSuite) PollForDecisionTasksResultTest() {
 		WorkflowExecution:      &execution,
 		WorkflowType:           &workflowType,
 		PreviousStartedEventId: common.Int64Ptr(scheduleID),
-		Attempt:                common.Int64Ptr(0),
+		Attempt:                common.Int64Ptr(1),
 		BacklogCountHint:       common.Int64Ptr(1),
 		StickyExecutionEnabled: common.BoolPtr(true),
 		WorkflowExecutionTaskList: common.TaskListPtr(workflow.TaskList{
 			Name: &tl,
 			Kind: common.TaskListKindPtr(workflow.TaskListKindNormal),
 		}),
+		ScheduledTimestamp:              common.Int64Ptr(scheduledTimestamp),
+		ScheduledTimestampOfThisAttempt: common.Int64Ptr(scheduledTimestampOfThisAttempt),
 	}
 
 	s.Nil(err)
@@ -36,14 +36,17 @@ import (
 	"github.com/uber/cadence/common/metrics"
 	"github.com/uber/cadence/common/persistence"
 	"go.uber.org/cadence"
+	clientShared "go.uber.org/cadence/.gen/go/shared"
 	"go.uber.org/cadence/activity"
 )
 
 const (
-	uploadHistoryActivityFnName = "uploadHistoryActivity"
-	deleteBlobActivityFnName    = "deleteBlobActivity"
-	deleteHistoryActivityFnName = "deleteHistoryActivity"
-	blobstoreTimeout            = 30 * time.Second
+	uploadHistoryActivityFnName         = "uploadHistoryActivity"
+	uploadMutableStateActivityFnName    = "uploadMutableStateActivity"
+	deleteBlobActivityFnName            = "deleteBlobActivity"
+	deleteHistoryActivityFnName         = "deleteHistoryActivity"
+	deleteOrphanedHistoryActivityFnName = "deleteOrphanedHistoryActivity"
+	blobstoreTimeout                    = 30 * time.Second
 
 	errGetDomainByID = "could not get domain cache entry"
 	errConstructKey  = "could not construct blob key"
@@ -55,17 +58,20 @@ const (
 	errDownloadBlob  = "could not download existing blob"
 	errDeleteBlob    = "could not delete existing blob"
 
-	errDeleteHistoryV1 = "failed to delete history from events_v1"
-	errDeleteHistoryV2 = "failed to delete history from events_v2"
+	errDeleteHistoryV1  = "failed to delete history from events_v1"
+	errDeleteHistoryV2  = "failed to delete history from events_v2"
+	errDescribeWorkflow = "could not describe workflow execution"
 
 	errHistoryMutated = "history was mutated during uploading"
 )
 
 var (
-	uploadHistoryActivityNonRetryableErrors = []string{errGetDomainByID, errConstructKey, errGetTags, errUploadBlob, errReadBlob, errEmptyBucket, errConstructBlob, errDownloadBlob, errHistoryMutated}
-	deleteBlobActivityNonRetryableErrors    = []string{errConstructKey, errGetTags, errUploadBlob, errEmptyBucket, errDeleteBlob}
-	deleteHistoryActivityNonRetryableErrors = []string{errDeleteHistoryV1, errDeleteHistoryV2}
-	errContextTimeout                       = errors.New("activity aborted because context timed out")
+	uploadHistoryActivityNonRetryableErrors         = []string{errGetDomainByID, errConstructKey, errGetTags, errUploadBlob, errReadBlob, errEmptyBucket, errConstructBlob, errDownloadBlob, errHistoryMutated}
+	uploadMutableStateActivityNonRetryableErrors    = []string{errConstructKey, errUploadBlob, errEmptyBucket, errConstructBlob}
+	deleteBlobActivityNonRetryableErrors            = []string{errConstructKey, errGetTags, errUploadBlob, errEmptyBucket, errDeleteBlob}
+	deleteHistoryActivityNonRetryableErrors         = []string{errDeleteHistoryV1, errDeleteHistoryV2}
+	deleteOrphanedHistoryActivityNonRetryableErrors = []string{errDeleteHistoryV1, errDeleteHistoryV2, errDescribeWorkflow}
+	errContextTimeout                               = errors.New("activity aborted because context timed out")
 )
 
 const (
@@ -125,6 +131,7 @@ func uploadHistoryActivity(ctx context.Context, request ArchiveRequest) (err err
 
 	var handledLastBlob bool
 	var totalUploadSize int64
+	var totalUncompressedUploadSize int64
 
 	runBlobIntegrityCheck := shouldRun(container.Config.BlobIntegrityCheckProbability())
 	var uploadedHistoryEventHashes []uint64
@@ -167,7 +174,9 @@ func uploadHistoryActivity(ctx context.Context, request ArchiveRequest) (err err
 
 		if historyMutated(historyBlob, &request) {
 			scope.IncCounter(metrics.ArchiverHistoryMutatedCount)
-			logger.Error(uploadErrorMsg, tag.ArchivalUploadFailReason("history was mutated during archiving"))
+			logger.Error(uploadErrorMsg, tag.ArchivalUploadFailReason("history was mutated during archiving"),
+				tag.FailoverVersion(common.Int64Default(historyBlob.Header.LastFailoverVersion)),
+				tag.WorkflowEventID(common.Int64Default(historyBlob.Header.LastEventID)))
 			return cadence.NewCustomError(errHistoryMutated)
 		}
 
@@ -177,7 +186,7 @@ func uploadHistoryActivity(ctx context.Context, request ArchiveRequest) (err err
 			modifyBlobForConstCheck(historyBlob, tags)
 		}
 
-		blob, reason, err := constructBlob(historyBlob, container.Config.EnableArchivalCompression(domainName))
+		blob, uncompressedBlobSize, reason, err := constructBlob(historyBlob, container.Config.EnableArchivalCompression(domainName))
 		if err != nil {
 			logger.Error(uploadErrorMsg, tag.ArchivalUploadFailReason(reason), tag.ArchivalBlobKey(key.String()))
 			return cadence.NewCustomError(errConstructBlob, err.Error())
@@ -185,6 +194,8 @@ func uploadHistoryActivity(ctx context.Context, request ArchiveRequest) (err err
 		currBlobSize := int64(len(blob.Body))
 		scope.RecordTimer(metrics.ArchiverBlobSize, time.Duration(currBlobSize))
 		totalUploadSize = totalUploadSize + currBlobSize
+		scope.RecordTimer(metrics.ArchiverUncompressedBlobSize, time.Duration(uncompressedBlobSize))
+		totalUncompressedUploadSize = totalUncompressedUploadSize + uncompressedBlobSize
 		if runConstTest {
 			existingBlob, err := downloadBlob(ctx, blobstoreClient, request.BucketName, key)
 			if err != nil {
@@ -214,6 +225,7 @@ func uploadHistoryActivity(ctx context.Context, request ArchiveRequest) (err err
 		handledLastBlob = *historyBlob.Header.IsLast
 	}
 	scope.RecordTimer(metrics.ArchiverTotalUploadSize, time.Duration(totalUploadSize))
+	scope.RecordTimer(metrics.ArchiverTotalUncompressedUploadSize, time.Duration(totalUncompressedUploadSize))
 	indexBlobKey, err := NewHistoryIndexBlobKey(request.DomainID, request.WorkflowID, request.RunID)
 	if err != nil {
 		logger.Error(uploadErrorMsg, tag.ArchivalUploadFailReason("could not construct index blob key"))
@@ -236,7 +248,7 @@ func uploadHistoryActivity(ctx context.Context, request ArchiveRequest) (err err
 		scope.IncCounter(metrics.ArchiverRunningBlobIntegrityCheckCount)
 		blobDownloader := container.HistoryBlobDownloader
 		if blobDownloader == nil {
-			blobDownloader = NewHistoryBlobDownloader(blobstoreClient)
+			blobDownloader = NewHistoryBlobDownloader(blobstoreClient, common.CreateBlobstoreOperationRetryPolicy(), logger)
 		}
 		req := &DownloadBlobRequest{
 			ArchivalBucket:       request.BucketName,
@@ -266,6 +278,55 @@ func uploadHistoryActivity(ctx context.Context, request ArchiveRequest) (err err
 	return nil
 }
 
+// uploadMutableStateActivity is used to upload the mutable state snapshot of a closed workflow execution to blobstore,
+// the snapshot is keyed alongside the history blobs of the same close failover version.
+// upload will be skipped and no error will be returned if the request does not carry a snapshot.
+// method will always return either: nil, errContextTimeout or an error from uploadMutableStateActivityNonRetryableErrors.
+func uploadMutableStateActivity(ctx context.Context, request ArchiveRequest) (err error) {
+	container := ctx.Value(bootstrapContainerKey).(*BootstrapContainer)
+	scope := container.MetricsClient.Scope(metrics.ArchiverUploadMutableStateActivityScope, metrics.DomainTag(request.DomainName))
+	sw := scope.StartTimer(metrics.CadenceLatency)
+	defer func() {
+		sw.Stop()
+		if err != nil {
+			if err == errContextTimeout {
+				scope.IncCounter(metrics.CadenceErrContextTimeoutCounter)
+			} else {
+				scope.IncCounter(metrics.ArchiverNonRetryableErrorCount)
+			}
+		}
+	}()
+
+	logger := tagLoggerWithRequest(tagLoggerWithActivityInfo(container.Logger, activity.GetInfo(ctx)), request)
+	if len(request.MutableStateSnapshot) == 0 {
+		logger.Warn(uploadSkipMsg, tag.ArchivalUploadFailReason("mutable state snapshot is not available"))
+		scope.IncCounter(metrics.ArchiverSkipUploadCount)
+		return nil
+	}
+	if err := validateArchivalRequest(&request); err != nil {
+		logger.Error(uploadErrorMsg, tag.ArchivalUploadFailReason(err.Error()))
+		return err
+	}
+
+	key, err := NewMutableStateBlobKey(request.DomainID, request.WorkflowID, request.RunID, request.CloseFailoverVersion)
+	if err != nil {
+		logger.Error(uploadErrorMsg, tag.ArchivalUploadFailReason("could not construct mutable state blob key"))
+		return cadence.NewCustomError(errConstructKey, err.Error())
+	}
+	blob, err := constructMutableStateBlob(request.MutableStateSnapshot, container.Config.EnableArchivalCompression(request.DomainName))
+	if err != nil {
+		logger.Error(uploadErrorMsg, tag.ArchivalUploadFailReason("failed to wrap mutable state blob"), tag.ArchivalBlobKey(key.String()))
+		return cadence.NewCustomError(errConstructBlob, err.Error())
+	}
+	scope.RecordTimer(metrics.ArchiverBlobSize, time.Duration(len(blob.Body)))
+	scope.RecordTimer(metrics.ArchiverUncompressedBlobSize, time.Duration(len(request.MutableStateSnapshot)))
+	if err := uploadBlob(ctx, container.Blobstore, request.BucketName, key, blob); err != nil {
+		logger.Error(uploadErrorMsg, tag.ArchivalUploadFailReason(errorDetails(err)), tag.ArchivalBlobKey(key.String()), tag.Error(err))
+		return err
+	}
+	return nil
+}
+
 // deleteHistoryActivity deletes workflow execution history from persistence.
 // method will retry all retryable operations until context expires.
 // method will always return either: nil, contextTimeoutErr or an error from deleteHistoryActivityNonRetryableErrors.
@@ -298,6 +359,48 @@ func deleteHistoryActivity(ctx context.Context, request ArchiveRequest) (err err
 	return nil
 }
 
+// deleteOrphanedHistoryActivity deletes a history which was left in place because it was mutated after archival was
+// requested. The history is only deleted once its run no longer exists, a run which still exists archives and deletes
+// its history again when it closes.
+// method will always return either: nil, contextTimeoutErr or an error from deleteOrphanedHistoryActivityNonRetryableErrors.
+func deleteOrphanedHistoryActivity(ctx context.Context, request ArchiveRequest) (err error) {
+	container := ctx.Value(bootstrapContainerKey).(*BootstrapContainer)
+	scope := container.MetricsClient.Scope(metrics.ArchiverDeleteOrphanedHistoryActivityScope, metrics.DomainTag(request.DomainName))
+	sw := scope.StartTimer(metrics.CadenceLatency)
+	defer func() {
+		sw.Stop()
+		if err != nil {
+			if err == errContextTimeout {
+				scope.IncCounter(metrics.CadenceErrContextTimeoutCounter)
+			} else {
+				scope.IncCounter(metrics.ArchiverNonRetryableErrorCount)
+			}
+		}
+	}()
+	logger := tagLoggerWithRequest(tagLoggerWithActivityInfo(container.Logger, activity.GetInfo(ctx)), request)
+	exists, err := workflowExists(ctx, container, request)
+	if err != nil {
+		logger.Error("failed to check whether the run of the mutated history still exists", tag.Error(err))
+		return err
+	}
+	if exists {
+		logger.Info("run of the mutated history still exists, leaving the history to be archived when it closes")
+		return nil
+	}
+	if request.EventStoreVersion == persistence.EventStoreVersionV2 {
+		if err := deleteHistoryV2(ctx, container, request); err != nil {
+			logger.Error("failed to delete orphaned history from events v2", tag.ArchivalDeleteHistoryFailReason(errorDetails(err)), tag.Error(err))
+			return err
+		}
+		return nil
+	}
+	if err := deleteHistoryV1(ctx, container, request); err != nil {
+		logger.Error("failed to delete orphaned history from events v1", tag.ArchivalDeleteHistoryFailReason(errorDetails(err)), tag.Error(err))
+		return err
+	}
+	return nil
+}
+
 // deleteBlobActivity deletes uploaded history blobs from blob store.
 // method will retry all retryable operations until context expires.
 // method will always return either: nil, contextTimeoutErr or an error from deleteBlobActivityNonRetryableErrors.
@@ -503,6 +606,43 @@ func getDomainByID(ctx context.Context, domainCache cache.DomainCache, id string
 	return entry, nil
 }
 
+func workflowExists(ctx context.Context, container *BootstrapContainer, request ArchiveRequest) (bool, error) {
+	describeReq := &clientShared.DescribeWorkflowExecutionRequest{
+		Domain: common.StringPtr(request.DomainName),
+		Execution: &clientShared.WorkflowExecution{
+			WorkflowId: common.StringPtr(request.WorkflowID),
+			RunId:      common.StringPtr(request.RunID),
+		},
+	}
+	op := func() error {
+		_, err := container.PublicClient.DescribeWorkflowExecution(ctx, describeReq)
+		return err
+	}
+	for {
+		err := backoff.Retry(op, common.CreatePersistanceRetryPolicy(), isRetryableDescribeError)
+		switch err.(type) {
+		case nil:
+			return true, nil
+		case *clientShared.EntityNotExistsError:
+			return false, nil
+		}
+		if !isRetryableDescribeError(err) {
+			return false, cadence.NewCustomError(errDescribeWorkflow, err.Error())
+		}
+		if contextExpired(ctx) {
+			return false, errContextTimeout
+		}
+	}
+}
+
+func isRetryableDescribeError(err error) bool {
+	switch err.(type) {
+	case *clientShared.InternalServiceError, *clientShared.ServiceBusyError:
+		return true
+	}
+	return false
+}
+
 func deleteHistoryV1(ctx context.Context, container *BootstrapContainer, request ArchiveRequest) error {
 	deleteHistoryReq := &persistence.DeleteWorkflowExecutionHistoryRequest{
 		DomainID: request.DomainID,
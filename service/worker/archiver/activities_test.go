@@ -26,6 +26,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber/cadence/.gen/go/shared"
@@ -41,6 +42,8 @@ import (
 	"github.com/uber/cadence/common/mocks"
 	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/service/dynamicconfig"
+	"go.uber.org/cadence/.gen/go/cadence/workflowservicetest"
+	clientShared "go.uber.org/cadence/.gen/go/shared"
 	"go.uber.org/cadence/testsuite"
 	"go.uber.org/cadence/worker"
 	"go.uber.org/zap"
@@ -754,6 +757,51 @@ func (s *activitiesSuite) TestUploadHistoryActivity_Fail_HistoryMutated() {
 	s.Equal(errHistoryMutated, err.Error())
 }
 
+func (s *activitiesSuite) TestUploadHistoryActivity_Fail_HistoryMutated_LastEventIDMismatch() {
+	s.metricsClient.On("Scope", metrics.ArchiverUploadHistoryActivityScope, []metrics.Tag{metrics.DomainTag(testDomainName)}).Return(s.metricsScope).Once()
+	s.metricsScope.On("IncCounter", metrics.ArchiverNonRetryableErrorCount).Once()
+	s.metricsScope.On("IncCounter", metrics.ArchiverHistoryMutatedCount).Once()
+	firstKey, _ := NewHistoryBlobKey(testDomainID, testWorkflowID, testRunID, testCloseFailoverVersion, common.FirstBlobPageToken)
+	domainCache, mockClusterMetadata := s.archivalConfig(true, testArchivalBucket, true)
+	mockBlobstore := &mocks.BlobstoreClient{}
+	mockBlobstore.On("GetTags", mock.Anything, mock.Anything, firstKey).Return(nil, blobstore.ErrBlobNotExists).Once()
+	mockHistoryBlobReader := &HistoryBlobReaderMock{}
+	// Return a history blob with the same failover version but more events than were present when archival was requested
+	mockHistoryBlobReader.On("GetBlob", common.FirstBlobPageToken).Return(&HistoryBlob{
+		Header: &HistoryBlobHeader{
+			LastFailoverVersion: common.Int64Ptr(testCloseFailoverVersion),
+			LastEventID:         common.Int64Ptr(testNextEventID + 10),
+			IsLast:              common.BoolPtr(true),
+		},
+	}, nil)
+	container := &BootstrapContainer{
+		Logger:            s.logger,
+		MetricsClient:     s.metricsClient,
+		DomainCache:       domainCache,
+		ClusterMetadata:   mockClusterMetadata,
+		Blobstore:         mockBlobstore,
+		HistoryBlobReader: mockHistoryBlobReader,
+		Config:            getConfig(false, false),
+	}
+	env := s.NewTestActivityEnvironment()
+	env.SetWorkerOptions(worker.Options{
+		BackgroundActivityContext: context.WithValue(context.Background(), bootstrapContainerKey, container),
+	})
+	request := ArchiveRequest{
+		DomainID:             testDomainID,
+		DomainName:           testDomainName,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		BranchToken:          testBranchToken,
+		NextEventID:          testNextEventID,
+		CloseFailoverVersion: testCloseFailoverVersion,
+		BucketName:           testArchivalBucket,
+	}
+	_, err := env.ExecuteActivity(uploadHistoryActivity, request)
+	s.Equal(errHistoryMutated, err.Error())
+	mockBlobstore.AssertNotCalled(s.T(), "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func (s *activitiesSuite) TestUploadHistoryActivity_Fail_CouldNotRunBlobIntegrityCheck() {
 	s.metricsClient.On("Scope", metrics.ArchiverUploadHistoryActivityScope, []metrics.Tag{metrics.DomainTag(testDomainName)}).Return(s.metricsScope).Once()
 	s.metricsScope.On("IncCounter", metrics.ArchiverRunningBlobIntegrityCheckCount).Once()
@@ -1007,6 +1055,92 @@ func (s *activitiesSuite) TestUploadHistoryActivity_Success_BlobIntegrityCheckPa
 	s.NoError(err)
 }
 
+func (s *activitiesSuite) TestUploadMutableStateActivity_Skip_SnapshotNotAvailable() {
+	s.metricsClient.On("Scope", metrics.ArchiverUploadMutableStateActivityScope, []metrics.Tag{metrics.DomainTag(testDomainName)}).Return(s.metricsScope).Once()
+	s.metricsScope.On("IncCounter", metrics.ArchiverSkipUploadCount).Once()
+	mockBlobstore := &mocks.BlobstoreClient{}
+	container := &BootstrapContainer{
+		Logger:        s.logger,
+		MetricsClient: s.metricsClient,
+		Blobstore:     mockBlobstore,
+		Config:        getConfig(false, false),
+	}
+	env := s.NewTestActivityEnvironment()
+	env.SetWorkerOptions(worker.Options{
+		BackgroundActivityContext: context.WithValue(context.Background(), bootstrapContainerKey, container),
+	})
+	request := ArchiveRequest{
+		DomainID:             testDomainID,
+		DomainName:           testDomainName,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		CloseFailoverVersion: testCloseFailoverVersion,
+		BucketName:           testArchivalBucket,
+	}
+	_, err := env.ExecuteActivity(uploadMutableStateActivity, request)
+	s.NoError(err)
+	mockBlobstore.AssertNotCalled(s.T(), "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *activitiesSuite) TestUploadMutableStateActivity_Fail_UploadBlobNonRetryableError() {
+	s.metricsClient.On("Scope", metrics.ArchiverUploadMutableStateActivityScope, []metrics.Tag{metrics.DomainTag(testDomainName)}).Return(s.metricsScope).Once()
+	s.metricsScope.On("IncCounter", metrics.ArchiverNonRetryableErrorCount).Once()
+	mockBlobstore := &mocks.BlobstoreClient{}
+	mockBlobstore.On("Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("some non-retryable error")).Once()
+	mockBlobstore.On("IsRetryableError", mock.Anything).Return(false)
+	container := &BootstrapContainer{
+		Logger:        s.logger,
+		MetricsClient: s.metricsClient,
+		Blobstore:     mockBlobstore,
+		Config:        getConfig(false, false),
+	}
+	env := s.NewTestActivityEnvironment()
+	env.SetWorkerOptions(worker.Options{
+		BackgroundActivityContext: context.WithValue(context.Background(), bootstrapContainerKey, container),
+	})
+	request := ArchiveRequest{
+		DomainID:             testDomainID,
+		DomainName:           testDomainName,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		CloseFailoverVersion: testCloseFailoverVersion,
+		BucketName:           testArchivalBucket,
+		MutableStateSnapshot: []byte("{}"),
+	}
+	_, err := env.ExecuteActivity(uploadMutableStateActivity, request)
+	s.Equal(errUploadBlob, err.Error())
+}
+
+func (s *activitiesSuite) TestUploadMutableStateActivity_Success() {
+	s.metricsClient.On("Scope", metrics.ArchiverUploadMutableStateActivityScope, []metrics.Tag{metrics.DomainTag(testDomainName)}).Return(s.metricsScope).Once()
+	expectedKey, err := NewMutableStateBlobKey(testDomainID, testWorkflowID, testRunID, testCloseFailoverVersion)
+	s.NoError(err)
+	mockBlobstore := &mocks.BlobstoreClient{}
+	mockBlobstore.On("Upload", mock.Anything, testArchivalBucket, expectedKey, mock.Anything).Return(nil).Once()
+	container := &BootstrapContainer{
+		Logger:        s.logger,
+		MetricsClient: s.metricsClient,
+		Blobstore:     mockBlobstore,
+		Config:        getConfig(false, false),
+	}
+	env := s.NewTestActivityEnvironment()
+	env.SetWorkerOptions(worker.Options{
+		BackgroundActivityContext: context.WithValue(context.Background(), bootstrapContainerKey, container),
+	})
+	request := ArchiveRequest{
+		DomainID:             testDomainID,
+		DomainName:           testDomainName,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		CloseFailoverVersion: testCloseFailoverVersion,
+		BucketName:           testArchivalBucket,
+		MutableStateSnapshot: []byte("{}"),
+	}
+	_, err = env.ExecuteActivity(uploadMutableStateActivity, request)
+	s.NoError(err)
+	mockBlobstore.AssertExpectations(s.T())
+}
+
 func (s *activitiesSuite) TestDeleteBlobActivity_Fail_ConstructBlobKeyError() {
 	s.metricsClient.On("Scope", metrics.ArchiverDeleteBlobActivityScope, []metrics.Tag{metrics.DomainTag(testDomainName)}).Return(s.metricsScope).Once()
 	s.metricsScope.On("IncCounter", metrics.ArchiverNonRetryableErrorCount).Once()
@@ -1337,6 +1471,88 @@ func (s *activitiesSuite) TestDeleteHistoryActivity_Success() {
 	s.NoError(err)
 }
 
+func (s *activitiesSuite) TestDeleteOrphanedHistoryActivity_RunExists() {
+	s.metricsClient.On("Scope", metrics.ArchiverDeleteOrphanedHistoryActivityScope, []metrics.Tag{metrics.DomainTag(testDomainName)}).Return(s.metricsScope).Once()
+	mockCtrl := gomock.NewController(s.T())
+	defer mockCtrl.Finish()
+	publicClient := workflowservicetest.NewMockClient(mockCtrl)
+	publicClient.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any()).Return(&clientShared.DescribeWorkflowExecutionResponse{}, nil)
+	mockHistoryV2Manager := &mocks.HistoryV2Manager{}
+	container := &BootstrapContainer{
+		Logger:           s.logger,
+		MetricsClient:    s.metricsClient,
+		PublicClient:     publicClient,
+		HistoryV2Manager: mockHistoryV2Manager,
+	}
+	env := s.NewTestActivityEnvironment()
+	env.SetWorkerOptions(worker.Options{
+		BackgroundActivityContext: context.WithValue(context.Background(), bootstrapContainerKey, container),
+	})
+	_, err := env.ExecuteActivity(deleteOrphanedHistoryActivity, s.orphanedHistoryRequest())
+	s.NoError(err)
+	mockHistoryV2Manager.AssertNotCalled(s.T(), "DeleteHistoryBranch", mock.Anything)
+}
+
+func (s *activitiesSuite) TestDeleteOrphanedHistoryActivity_RunNotExists() {
+	s.metricsClient.On("Scope", metrics.ArchiverDeleteOrphanedHistoryActivityScope, []metrics.Tag{metrics.DomainTag(testDomainName)}).Return(s.metricsScope).Once()
+	mockCtrl := gomock.NewController(s.T())
+	defer mockCtrl.Finish()
+	publicClient := workflowservicetest.NewMockClient(mockCtrl)
+	publicClient.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any()).Return(nil, &clientShared.EntityNotExistsError{})
+	mockHistoryV2Manager := &mocks.HistoryV2Manager{}
+	mockHistoryV2Manager.On("DeleteHistoryBranch", mock.Anything).Return(nil).Once()
+	container := &BootstrapContainer{
+		Logger:           s.logger,
+		MetricsClient:    s.metricsClient,
+		PublicClient:     publicClient,
+		HistoryV2Manager: mockHistoryV2Manager,
+	}
+	env := s.NewTestActivityEnvironment()
+	env.SetWorkerOptions(worker.Options{
+		BackgroundActivityContext: context.WithValue(context.Background(), bootstrapContainerKey, container),
+	})
+	_, err := env.ExecuteActivity(deleteOrphanedHistoryActivity, s.orphanedHistoryRequest())
+	s.NoError(err)
+	mockHistoryV2Manager.AssertExpectations(s.T())
+}
+
+func (s *activitiesSuite) TestDeleteOrphanedHistoryActivity_Fail_DescribeNonRetryableError() {
+	s.metricsClient.On("Scope", metrics.ArchiverDeleteOrphanedHistoryActivityScope, []metrics.Tag{metrics.DomainTag(testDomainName)}).Return(s.metricsScope).Once()
+	s.metricsScope.On("IncCounter", metrics.ArchiverNonRetryableErrorCount).Once()
+	mockCtrl := gomock.NewController(s.T())
+	defer mockCtrl.Finish()
+	publicClient := workflowservicetest.NewMockClient(mockCtrl)
+	publicClient.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any()).Return(nil, &clientShared.BadRequestError{})
+	mockHistoryV2Manager := &mocks.HistoryV2Manager{}
+	container := &BootstrapContainer{
+		Logger:           s.logger,
+		MetricsClient:    s.metricsClient,
+		PublicClient:     publicClient,
+		HistoryV2Manager: mockHistoryV2Manager,
+	}
+	env := s.NewTestActivityEnvironment()
+	env.SetWorkerOptions(worker.Options{
+		BackgroundActivityContext: context.WithValue(context.Background(), bootstrapContainerKey, container),
+	})
+	_, err := env.ExecuteActivity(deleteOrphanedHistoryActivity, s.orphanedHistoryRequest())
+	s.Equal(errDescribeWorkflow, err.Error())
+	mockHistoryV2Manager.AssertNotCalled(s.T(), "DeleteHistoryBranch", mock.Anything)
+}
+
+func (s *activitiesSuite) orphanedHistoryRequest() ArchiveRequest {
+	return ArchiveRequest{
+		DomainID:             testDomainID,
+		DomainName:           testDomainName,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		BranchToken:          testBranchToken,
+		NextEventID:          testNextEventID,
+		CloseFailoverVersion: testCloseFailoverVersion,
+		EventStoreVersion:    persistence.EventStoreVersionV2,
+		BucketName:           testArchivalBucket,
+	}
+}
+
 func (s *activitiesSuite) archivalConfig(
 	domainEnablesArchival bool,
 	domainArchivalBucket string,
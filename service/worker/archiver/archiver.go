@@ -42,9 +42,36 @@ type (
 		logger        log.Logger
 		metricsClient metrics.Client
 		concurrency   int
-		requestCh     workflow.Channel
-		resultCh      workflow.Channel
+		// requests with ArchivalPriorityHigh are pumped into highPriorityRequestCh, all others into requestCh
+		highPriorityRequestCh workflow.Channel
+		requestCh             workflow.Channel
+		resultCh              workflow.Channel
+		// inFlightHashes are the hashes of the requests being handled, a request identical to one in flight
+		// is not handled again, it holds at most concurrency entries
+		inFlightHashes map[uint64]struct{}
 	}
+
+	// requestReceiver pulls requests for a single archiver coroutine
+	requestReceiver struct {
+		highPriorityRequestCh workflow.Channel
+		requestCh             workflow.Channel
+		highPriorityClosed    bool
+		closed                bool
+		highPriorityStreak    int
+	}
+)
+
+const (
+	// highPriorityWeight is the number of consecutive high priority requests a coroutine handles
+	// before it handles a waiting normal priority request, so normal priority requests are never starved.
+	highPriorityWeight = 4
+
+	// the delete history local activity gets deleteLocalBaseTimeout plus deleteLocalTimeoutPerEventBatch for
+	// every deleteLocalEventBatchSize events of the history, bounded by deleteLocalMaxTimeout
+	deleteLocalBaseTimeout          = time.Minute
+	deleteLocalTimeoutPerEventBatch = 30 * time.Second
+	deleteLocalEventBatchSize       = 10000
+	deleteLocalMaxTimeout           = 5 * time.Minute
 )
 
 // NewArchiver returns a new Archiver
@@ -53,32 +80,47 @@ func NewArchiver(
 	logger log.Logger,
 	metricsClient metrics.Client,
 	concurrency int,
+	highPriorityRequestCh workflow.Channel,
 	requestCh workflow.Channel,
 ) Archiver {
 	return &archiver{
-		ctx:           ctx,
-		logger:        logger,
-		metricsClient: metricsClient,
-		concurrency:   concurrency,
-		requestCh:     requestCh,
-		resultCh:      workflow.NewChannel(ctx),
+		ctx:                   ctx,
+		logger:                logger,
+		metricsClient:         metricsClient,
+		concurrency:           concurrency,
+		highPriorityRequestCh: highPriorityRequestCh,
+		requestCh:             requestCh,
+		resultCh:              workflow.NewChannel(ctx),
+		inFlightHashes:        make(map[uint64]struct{}),
 	}
 }
 
 // Start spawns concurrency count of coroutine to handle archivals (does not block).
+// Coroutines prefer high priority requests and finish once both request channels are closed and drained.
 func (a *archiver) Start() {
 	a.metricsClient.IncCounter(metrics.ArchiverScope, metrics.ArchiverStartedCount)
 	for i := 0; i < a.concurrency; i++ {
 		workflow.Go(a.ctx, func(ctx workflow.Context) {
 			a.metricsClient.IncCounter(metrics.ArchiverScope, metrics.ArchiverCoroutineStartedCount)
+			receiver := &requestReceiver{
+				highPriorityRequestCh: a.highPriorityRequestCh,
+				requestCh:             a.requestCh,
+			}
 			var handledHashes []uint64
 			for {
 				var request ArchiveRequest
-				if more := a.requestCh.Receive(ctx, &request); !more {
+				if more := receiver.receive(ctx, &request); !more {
 					break
 				}
-				handleRequest(ctx, a.logger, a.metricsClient, request)
-				handledHashes = append(handledHashes, hash(request))
+				requestHash := hash(request)
+				if _, ok := a.inFlightHashes[requestHash]; ok {
+					a.metricsClient.IncCounter(metrics.ArchiverScope, metrics.ArchiverDuplicateRequestCount)
+				} else {
+					a.inFlightHashes[requestHash] = struct{}{}
+					handleRequest(ctx, a.logger, a.metricsClient, request)
+					delete(a.inFlightHashes, requestHash)
+				}
+				handledHashes = append(handledHashes, requestHash)
 			}
 			a.resultCh.Send(ctx, handledHashes)
 			a.metricsClient.IncCounter(metrics.ArchiverScope, metrics.ArchiverCoroutineStoppedCount)
@@ -99,8 +141,82 @@ func (a *archiver) Finished() []uint64 {
 	return handledHashes
 }
 
+// receive blocks until a request is available and returns false once both channels are closed and drained.
+func (r *requestReceiver) receive(ctx workflow.Context, request *ArchiveRequest) bool {
+	for !r.highPriorityClosed || !r.closed {
+		if r.receiveAsync(request) {
+			return true
+		}
+		if r.highPriorityClosed && r.closed {
+			break
+		}
+		received := false
+		selector := workflow.NewSelector(ctx)
+		if !r.highPriorityClosed {
+			selector.AddReceive(r.highPriorityRequestCh, func(c workflow.Channel, more bool) {
+				if !more {
+					r.highPriorityClosed = true
+					return
+				}
+				c.Receive(ctx, request)
+				r.highPriorityStreak++
+				received = true
+			})
+		}
+		if !r.closed {
+			selector.AddReceive(r.requestCh, func(c workflow.Channel, more bool) {
+				if !more {
+					r.closed = true
+					return
+				}
+				c.Receive(ctx, request)
+				r.highPriorityStreak = 0
+				received = true
+			})
+		}
+		selector.Select(ctx)
+		if received {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *requestReceiver) receiveAsync(request *ArchiveRequest) bool {
+	if r.highPriorityStreak >= highPriorityWeight && r.receiveNormalPriorityAsync(request) {
+		return true
+	}
+	return r.receiveHighPriorityAsync(request) || r.receiveNormalPriorityAsync(request)
+}
+
+func (r *requestReceiver) receiveHighPriorityAsync(request *ArchiveRequest) bool {
+	if r.highPriorityClosed {
+		return false
+	}
+	ok, more := r.highPriorityRequestCh.ReceiveAsyncWithMoreFlag(request)
+	if ok {
+		r.highPriorityStreak++
+	}
+	r.highPriorityClosed = !ok && !more
+	return ok
+}
+
+func (r *requestReceiver) receiveNormalPriorityAsync(request *ArchiveRequest) bool {
+	if r.closed {
+		return false
+	}
+	ok, more := r.requestCh.ReceiveAsyncWithMoreFlag(request)
+	if ok {
+		r.highPriorityStreak = 0
+	}
+	r.closed = !ok && !more
+	return ok
+}
+
 func handleRequest(ctx workflow.Context, logger log.Logger, metricsClient metrics.Client, request ArchiveRequest) {
 	sw := metricsClient.StartTimer(metrics.ArchiverScope, metrics.ArchiverHandleRequestLatency)
+	prioritySW := metricsClient.StartTimer(metrics.ArchiverScope, priorityLatencyMetric(request.Priority))
+	defer prioritySW.Stop()
 	logger = tagLoggerWithRequest(logger, request)
 	ao := workflow.ActivityOptions{
 		ScheduleToStartTimeout: 10 * time.Minute,
@@ -122,6 +238,28 @@ func handleRequest(ctx workflow.Context, logger log.Logger, metricsClient metric
 		metricsClient.IncCounter(metrics.ArchiverScope, metrics.ArchiverUploadSuccessCount)
 	}
 	uploadSW.Stop()
+	historyMutated := isHistoryMutatedError(err)
+
+	if err == nil && len(request.MutableStateSnapshot) != 0 {
+		ao := workflow.ActivityOptions{
+			ScheduleToStartTimeout: 10 * time.Minute,
+			StartToCloseTimeout:    5 * time.Minute,
+			RetryPolicy: &cadence.RetryPolicy{
+				InitialInterval:          time.Second,
+				BackoffCoefficient:       2.0,
+				ExpirationInterval:       10 * time.Minute,
+				NonRetriableErrorReasons: uploadMutableStateActivityNonRetryableErrors,
+			},
+		}
+		actCtx := workflow.WithActivityOptions(ctx, ao)
+		// the snapshot is only a debugging aid, failing to upload it does not fail the archival of the history
+		if err := workflow.ExecuteActivity(actCtx, uploadMutableStateActivityFnName, request).Get(actCtx, nil); err != nil {
+			logger.Error("failed to upload mutable state snapshot, history is archived without it", tag.Error(err))
+			metricsClient.IncCounter(metrics.ArchiverScope, metrics.ArchiverUploadMutableStateFailedAllRetriesCount)
+		} else {
+			metricsClient.IncCounter(metrics.ArchiverScope, metrics.ArchiverUploadMutableStateSuccessCount)
+		}
+	}
 
 	if err != nil {
 		ao := workflow.ActivityOptions{
@@ -145,12 +283,39 @@ func handleRequest(ctx workflow.Context, logger log.Logger, metricsClient metric
 		deleteBlobSW.Stop()
 	}
 
+	deleteLocalTimeout := deleteHistoryLocalActivityTimeout(request.NextEventID)
+	if historyMutated {
+		// the history changed after archival was requested (e.g. the workflow was reset), it no longer
+		// corresponds to the request and is not deleted unarchived, unless its run is gone and nothing
+		// else would ever archive or delete it
+		logger.Error("history was mutated after archival was requested, skipping deletion of history")
+		ao := workflow.ActivityOptions{
+			ScheduleToStartTimeout: 10 * time.Minute,
+			StartToCloseTimeout:    5 * time.Minute,
+			RetryPolicy: &cadence.RetryPolicy{
+				InitialInterval:          time.Second,
+				BackoffCoefficient:       2.0,
+				ExpirationInterval:       10 * time.Minute,
+				NonRetriableErrorReasons: deleteOrphanedHistoryActivityNonRetryableErrors,
+			},
+		}
+		actCtx := workflow.WithActivityOptions(ctx, ao)
+		if err := workflow.ExecuteActivity(actCtx, deleteOrphanedHistoryActivityFnName, request).Get(actCtx, nil); err != nil {
+			logger.Error("failed to delete orphaned history, this means zombie histories are left", tag.Error(err))
+			metricsClient.IncCounter(metrics.ArchiverScope, metrics.ArchiverDeleteOrphanedHistoryFailedAllRetriesCount)
+		} else {
+			metricsClient.IncCounter(metrics.ArchiverScope, metrics.ArchiverDeleteOrphanedHistorySuccessCount)
+		}
+		sw.Stop()
+		return
+	}
+
 	lao := workflow.LocalActivityOptions{
-		ScheduleToCloseTimeout: 1 * time.Minute,
+		ScheduleToCloseTimeout: deleteLocalTimeout,
 		RetryPolicy: &cadence.RetryPolicy{
 			InitialInterval:          time.Second,
 			BackoffCoefficient:       2.0,
-			ExpirationInterval:       3 * time.Minute,
+			ExpirationInterval:       3 * deleteLocalTimeout,
 			NonRetriableErrorReasons: deleteHistoryActivityNonRetryableErrors,
 		},
 	}
@@ -163,7 +328,11 @@ func handleRequest(ctx workflow.Context, logger log.Logger, metricsClient metric
 		deleteSW.Stop()
 		return
 	}
-	metricsClient.IncCounter(metrics.ArchiverScope, metrics.ArchiverDeleteLocalFailedAllRetriesCount)
+	if _, ok := err.(*workflow.TimeoutError); ok {
+		metricsClient.IncCounter(metrics.ArchiverScope, metrics.ArchiverDeleteLocalTimeoutCount)
+	} else {
+		metricsClient.IncCounter(metrics.ArchiverScope, metrics.ArchiverDeleteLocalFailedAllRetriesCount)
+	}
 	logger.Warn("deleting history though local activity failed, attempting to run as normal activity", tag.Error(err))
 	ao = workflow.ActivityOptions{
 		ScheduleToStartTimeout: 10 * time.Minute,
@@ -185,3 +354,25 @@ func handleRequest(ctx workflow.Context, logger log.Logger, metricsClient metric
 	sw.Stop()
 	deleteSW.Stop()
 }
+
+func isHistoryMutatedError(err error) bool {
+	customErr, ok := err.(*cadence.CustomError)
+	return ok && customErr.Reason() == errHistoryMutated
+}
+
+// deleteHistoryLocalActivityTimeout scales the timeout of the delete history local activity with the length of
+// the history, so that deleting large histories does not needlessly fall back to a normal activity
+func deleteHistoryLocalActivityTimeout(nextEventID int64) time.Duration {
+	timeout := deleteLocalBaseTimeout + time.Duration(nextEventID/deleteLocalEventBatchSize)*deleteLocalTimeoutPerEventBatch
+	if timeout > deleteLocalMaxTimeout {
+		return deleteLocalMaxTimeout
+	}
+	return timeout
+}
+
+func priorityLatencyMetric(priority ArchivalPriority) int {
+	if priority == ArchivalPriorityHigh {
+		return metrics.ArchiverHandleHighPriorityRequestLatency
+	}
+	return metrics.ArchiverHandleNormalPriorityRequestLatency
+}
@@ -55,6 +55,8 @@ func TestArchiverSuite(t *testing.T) {
 func (s *archiverSuite) SetupSuite() {
 	workflow.Register(handleRequestWorkflow)
 	workflow.Register(startAndFinishArchiverWorkflow)
+	workflow.Register(prioritizedArchiverWorkflow)
+	workflow.Register(duplicateRequestArchiverWorkflow)
 }
 
 func (s *archiverSuite) SetupTest() {
@@ -103,6 +105,28 @@ func (s *archiverSuite) TestHandleRequest_UploadFails_ExpireRetryTimeout() {
 	s.NoError(env.GetWorkflowError())
 }
 
+func (s *archiverSuite) TestHandleRequest_UploadFails_HistoryMutated() {
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverUploadFailedAllRetriesCount).Once()
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverDeleteBlobSuccessCount).Once()
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverDeleteOrphanedHistorySuccessCount).Once()
+	archiverTestLogger.On("Error", mock.Anything, mock.Anything).Twice()
+
+	env := s.NewTestWorkflowEnvironment()
+	env.OnActivity(uploadHistoryActivityFnName, mock.Anything, mock.Anything).Return(cadence.NewCustomError(errHistoryMutated))
+	env.OnActivity(deleteBlobActivityFnName, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(deleteOrphanedHistoryActivityFnName, mock.Anything, mock.Anything).Return(nil).Once()
+	var historyDeleted bool
+	env.OnActivity(deleteHistoryActivityFnName, mock.Anything, mock.Anything).Return(func(context.Context, ArchiveRequest) error {
+		historyDeleted = true
+		return nil
+	})
+	env.ExecuteWorkflow(handleRequestWorkflow, ArchiveRequest{})
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	s.False(historyDeleted)
+}
+
 func (s *archiverSuite) TestHandleRequest_LocalDeleteFails_NonRetryableError() {
 	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverUploadSuccessCount).Once()
 	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverDeleteLocalFailedAllRetriesCount).Once()
@@ -147,6 +171,46 @@ func (s *archiverSuite) TestHandleRequest_LocalDeleteFailsThenSucceeds() {
 	s.NoError(env.GetWorkflowError())
 }
 
+func (s *archiverSuite) TestHandleRequest_UploadsMutableStateSnapshot() {
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverUploadSuccessCount).Once()
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverUploadMutableStateSuccessCount).Once()
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverDeleteLocalSuccessCount).Once()
+
+	env := s.NewTestWorkflowEnvironment()
+	env.OnActivity(uploadHistoryActivityFnName, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(uploadMutableStateActivityFnName, mock.Anything, mock.Anything).Return(nil).Once()
+	env.OnActivity(deleteHistoryActivityFnName, mock.Anything, mock.Anything).Return(nil)
+	env.ExecuteWorkflow(handleRequestWorkflow, ArchiveRequest{MutableStateSnapshot: []byte("{}")})
+
+	env.AssertExpectations(s.T())
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+}
+
+func (s *archiverSuite) TestHandleRequest_MutableStateSnapshotUploadFails() {
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverUploadSuccessCount).Once()
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverUploadMutableStateFailedAllRetriesCount).Once()
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverDeleteLocalSuccessCount).Once()
+	archiverTestLogger.On("Error", mock.Anything, mock.Anything).Once()
+
+	env := s.NewTestWorkflowEnvironment()
+	env.OnActivity(uploadHistoryActivityFnName, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(uploadMutableStateActivityFnName, mock.Anything, mock.Anything).Return(cadence.NewCustomError(errUploadBlob))
+	env.OnActivity(deleteHistoryActivityFnName, mock.Anything, mock.Anything).Return(nil)
+	env.ExecuteWorkflow(handleRequestWorkflow, ArchiveRequest{MutableStateSnapshot: []byte("{}")})
+
+	env.AssertExpectations(s.T())
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+}
+
+func (s *archiverSuite) TestDeleteHistoryLocalActivityTimeout() {
+	s.Equal(deleteLocalBaseTimeout, deleteHistoryLocalActivityTimeout(0))
+	s.Equal(deleteLocalBaseTimeout, deleteHistoryLocalActivityTimeout(deleteLocalEventBatchSize-1))
+	s.Equal(deleteLocalBaseTimeout+2*deleteLocalTimeoutPerEventBatch, deleteHistoryLocalActivityTimeout(2*deleteLocalEventBatchSize))
+	s.Equal(deleteLocalMaxTimeout, deleteHistoryLocalActivityTimeout(1000*deleteLocalEventBatchSize))
+}
+
 func (s *archiverSuite) TestRunArchiver() {
 	numRequests := 1000
 	concurrency := 10
@@ -167,22 +231,61 @@ func (s *archiverSuite) TestRunArchiver() {
 	s.NoError(env.GetWorkflowError())
 }
 
+func (s *archiverSuite) TestRunArchiver_PrefersHighPriority() {
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, mock.Anything)
+
+	env := s.NewTestWorkflowEnvironment()
+	env.OnActivity(uploadHistoryActivityFnName, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(deleteHistoryActivityFnName, mock.Anything, mock.Anything).Return(nil)
+	env.ExecuteWorkflow(prioritizedArchiverWorkflow)
+
+	env.AssertExpectations(s.T())
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+}
+
+func (s *archiverSuite) TestRunArchiver_SkipsDuplicateInFlightRequest() {
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverUploadSuccessCount).Once()
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverDeleteLocalSuccessCount).Once()
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverDuplicateRequestCount).Once()
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverStartedCount).Once()
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverCoroutineStartedCount).Twice()
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverCoroutineStoppedCount).Twice()
+	archiverTestMetrics.On("IncCounter", metrics.ArchiverScope, metrics.ArchiverStoppedCount).Once()
+
+	env := s.NewTestWorkflowEnvironment()
+	env.OnActivity(uploadHistoryActivityFnName, mock.Anything, mock.Anything).Return(nil).Once()
+	env.OnActivity(deleteHistoryActivityFnName, mock.Anything, mock.Anything).Return(nil).Once()
+	env.ExecuteWorkflow(duplicateRequestArchiverWorkflow)
+
+	env.AssertExpectations(s.T())
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+}
+
 func handleRequestWorkflow(ctx workflow.Context, request ArchiveRequest) error {
 	handleRequest(ctx, archiverTestLogger, archiverTestMetrics, request)
 	return nil
 }
 
 func startAndFinishArchiverWorkflow(ctx workflow.Context, concurrency int, numRequests int) error {
+	highPriorityRequestCh := workflow.NewBufferedChannel(ctx, numRequests)
 	requestCh := workflow.NewBufferedChannel(ctx, numRequests)
-	archiver := NewArchiver(ctx, archiverTestLogger, archiverTestMetrics, concurrency, requestCh)
+	archiver := NewArchiver(ctx, archiverTestLogger, archiverTestMetrics, concurrency, highPriorityRequestCh, requestCh)
 	archiver.Start()
 	sentHashes := make([]uint64, numRequests, numRequests)
 	workflow.Go(ctx, func(ctx workflow.Context) {
 		for i := 0; i < numRequests; i++ {
-			ar, hash := randomArchiveRequest()
-			requestCh.Send(ctx, ar)
-			sentHashes[i] = hash
+			ar, _ := randomArchiveRequest()
+			if i%2 == 0 {
+				ar.Priority = ArchivalPriorityHigh
+				highPriorityRequestCh.Send(ctx, ar)
+			} else {
+				requestCh.Send(ctx, ar)
+			}
+			sentHashes[i] = hash(ar)
 		}
+		highPriorityRequestCh.Close()
 		requestCh.Close()
 	})
 	handledHashes := archiver.Finished()
@@ -192,6 +295,68 @@ func startAndFinishArchiverWorkflow(ctx workflow.Context, concurrency int, numRe
 	return nil
 }
 
+func prioritizedArchiverWorkflow(ctx workflow.Context) error {
+	numHighPriority := 2*highPriorityWeight + 1
+	numNormalPriority := 3
+	highPriorityRequestCh := workflow.NewBufferedChannel(ctx, numHighPriority)
+	requestCh := workflow.NewBufferedChannel(ctx, numNormalPriority)
+	var highPriorityHashes, normalPriorityHashes []uint64
+	for i := 0; i < numHighPriority; i++ {
+		ar, _ := randomArchiveRequest()
+		ar.Priority = ArchivalPriorityHigh
+		highPriorityRequestCh.Send(ctx, ar)
+		highPriorityHashes = append(highPriorityHashes, hash(ar))
+	}
+	for i := 0; i < numNormalPriority; i++ {
+		ar, h := randomArchiveRequest()
+		requestCh.Send(ctx, ar)
+		normalPriorityHashes = append(normalPriorityHashes, h)
+	}
+	highPriorityRequestCh.Close()
+	requestCh.Close()
+
+	// a single coroutine handles requests in order, so the weighting is observable
+	archiver := NewArchiver(ctx, archiverTestLogger, archiverTestMetrics, 1, highPriorityRequestCh, requestCh)
+	archiver.Start()
+	handledHashes := archiver.Finished()
+
+	var expectedHashes []uint64
+	expectedHashes = append(expectedHashes, highPriorityHashes[:highPriorityWeight]...)
+	expectedHashes = append(expectedHashes, normalPriorityHashes[0])
+	expectedHashes = append(expectedHashes, highPriorityHashes[highPriorityWeight:2*highPriorityWeight]...)
+	expectedHashes = append(expectedHashes, normalPriorityHashes[1])
+	expectedHashes = append(expectedHashes, highPriorityHashes[2*highPriorityWeight:]...)
+	expectedHashes = append(expectedHashes, normalPriorityHashes[2])
+	if len(handledHashes) != len(expectedHashes) {
+		return errors.New("handled hashes does not equal sent hashes")
+	}
+	for i := range expectedHashes {
+		if handledHashes[i] != expectedHashes[i] {
+			return errors.New("requests were not handled in priority order")
+		}
+	}
+	return nil
+}
+
+func duplicateRequestArchiverWorkflow(ctx workflow.Context) error {
+	highPriorityRequestCh := workflow.NewBufferedChannel(ctx, 2)
+	requestCh := workflow.NewChannel(ctx)
+	ar, h := randomArchiveRequest()
+	highPriorityRequestCh.Send(ctx, ar)
+	highPriorityRequestCh.Send(ctx, ar)
+	highPriorityRequestCh.Close()
+	requestCh.Close()
+
+	// the second coroutine receives the duplicate while the first one is still handling the request
+	archiver := NewArchiver(ctx, archiverTestLogger, archiverTestMetrics, 2, highPriorityRequestCh, requestCh)
+	archiver.Start()
+	handledHashes := archiver.Finished()
+	if !hashesEqual(handledHashes, []uint64{h, h}) {
+		return errors.New("handled hashes does not equal sent hashes")
+	}
+	return nil
+}
+
 func randomArchiveRequest() (ArchiveRequest, uint64) {
 	ar := ArchiveRequest{
 		DomainID:   fmt.Sprintf("%v", rand.Intn(1000)),
@@ -38,6 +38,9 @@ import (
 )
 
 type (
+	// ArchivalPriority determines the order in which the archiver handles requests
+	ArchivalPriority int
+
 	// ArchiveRequest is request to Archive
 	ArchiveRequest struct {
 		ShardID              int
@@ -50,6 +53,13 @@ type (
 		NextEventID          int64
 		CloseFailoverVersion int64
 		BucketName           string
+		Priority             ArchivalPriority
+		// MutableStateSnapshot is the JSON encoded persistence form of the mutable state at close,
+		// it is empty if the snapshot is not archived alongside the history
+		MutableStateSnapshot []byte
+		// PreviousRunID is the run which continued as new into this run, it is empty for the first run of a chain
+		PreviousRunID                   string
+		PreviousRunCloseFailoverVersion int64
 	}
 
 	// Client is used to archive workflow histories
@@ -66,6 +76,13 @@ type (
 	}
 )
 
+const (
+	// ArchivalPriorityNormal is the default priority, requests with this priority are handled in the order they arrive
+	ArchivalPriorityNormal ArchivalPriority = iota
+	// ArchivalPriorityHigh requests are handled ahead of normal priority requests
+	ArchivalPriorityHigh
+)
+
 const tooManyRequestsErrMsg = "Too many requests to archival workflow"
 
 // NewClient creates a new Client
@@ -105,8 +105,10 @@ var (
 func init() {
 	workflow.RegisterWithOptions(archivalWorkflow, workflow.RegisterOptions{Name: archivalWorkflowFnName})
 	activity.RegisterWithOptions(uploadHistoryActivity, activity.RegisterOptions{Name: uploadHistoryActivityFnName})
+	activity.RegisterWithOptions(uploadMutableStateActivity, activity.RegisterOptions{Name: uploadMutableStateActivityFnName})
 	activity.RegisterWithOptions(deleteBlobActivity, activity.RegisterOptions{Name: deleteBlobActivityFnName})
 	activity.RegisterWithOptions(deleteHistoryActivity, activity.RegisterOptions{Name: deleteHistoryActivityFnName})
+	activity.RegisterWithOptions(deleteOrphanedHistoryActivity, activity.RegisterOptions{Name: deleteOrphanedHistoryActivityFnName})
 }
 
 // NewClientWorker returns a new ClientWorker
@@ -51,6 +51,13 @@ type (
 		UploadCluster        *string `json:"upload_cluster,omitempty"`
 		EventCount           *int64  `json:"event_count,omitempty"`
 		CloseFailoverVersion *int64  `json:"close_failover_version,omitempty"`
+		// EventEncoding is the encoding of the history events in the body, blobs uploaded before it was recorded are json
+		EventEncoding *string `json:"event_encoding,omitempty"`
+		// the previous run fields point to the archived history of the run which continued as new into this run,
+		// they are only set for runs of a continue-as-new chain
+		PreviousRunID                   *string `json:"previous_run_id,omitempty"`
+		PreviousRunArchivalBucket       *string `json:"previous_run_archival_bucket,omitempty"`
+		PreviousRunCloseFailoverVersion *int64  `json:"previous_run_close_failover_version,omitempty"`
 	}
 
 	// HistoryBlob is the serializable data that forms the body of a blob
@@ -60,8 +67,19 @@ type (
 	}
 )
 
+const (
+	historyBlobKeyExtension = "history"
+)
+
 var (
-	errInvalidKeyInput = errors.New("invalid input to construct history blob key")
+	// ErrHistoryBlobPageNotExists indicates the requested page of an archived history does not exist
+	ErrHistoryBlobPageNotExists = errors.New("history blob page does not exist")
+	// ErrNoPreviousRun indicates the archived history is of the first run of a continue-as-new chain
+	ErrNoPreviousRun = errors.New("archived history has no previous run")
+
+	errInvalidKeyInput      = errors.New("invalid input to construct history blob key")
+	errNotHistoryBlobKey    = errors.New("key is not a history blob key")
+	errUnknownEventEncoding = errors.New("unknown history event encoding")
 )
 
 // NewHistoryBlobKey returns a key for history blob
@@ -72,11 +90,31 @@ func NewHistoryBlobKey(domainID, workflowID, runID string, closeFailoverVersion
 	if pageToken < common.FirstBlobPageToken {
 		return nil, errInvalidKeyInput
 	}
+	return blob.NewKey(historyBlobKeyExtension, historyBlobKeyHash(domainID, workflowID, runID), strconv.FormatInt(closeFailoverVersion, 10), strconv.Itoa(pageToken))
+}
+
+// NewHistoryBlobKeyPrefix returns the prefix shared by the keys of all history blobs of a workflow run, across all versions and pages
+func NewHistoryBlobKeyPrefix(domainID, workflowID, runID string) (string, error) {
+	if len(domainID) == 0 || len(workflowID) == 0 || len(runID) == 0 {
+		return "", errInvalidKeyInput
+	}
+	return historyBlobKeyHash(domainID, workflowID, runID) + "_", nil
+}
+
+// GetHistoryBlobKeyVersion returns the close failover version encoded in a history blob key
+func GetHistoryBlobKeyVersion(key blob.Key) (int64, error) {
+	pieces := key.Pieces()
+	if key.Extension() != historyBlobKeyExtension || len(pieces) != 3 {
+		return 0, errNotHistoryBlobKey
+	}
+	return strconv.ParseInt(pieces[1], 10, 64)
+}
+
+func historyBlobKeyHash(domainID, workflowID, runID string) string {
 	domainIDHash := fmt.Sprintf("%v", farm.Fingerprint64([]byte(domainID)))
 	workflowIDHash := fmt.Sprintf("%v", farm.Fingerprint64([]byte(workflowID)))
 	runIDHash := fmt.Sprintf("%v", farm.Fingerprint64([]byte(runID)))
-	combinedHash := strings.Join([]string{domainIDHash, workflowIDHash, runIDHash}, "")
-	return blob.NewKey("history", combinedHash, strconv.FormatInt(closeFailoverVersion, 10), strconv.Itoa(pageToken))
+	return strings.Join([]string{domainIDHash, workflowIDHash, runIDHash}, "")
 }
 
 // NewNonDeterministicBlobKey returns a key for the non-deterministic history blob given the key for the other blob
@@ -107,27 +145,51 @@ func IsLast(tags map[string]string) bool {
 	return ok && last == "true"
 }
 
-func constructBlob(historyBlob *HistoryBlob, enableCompression bool) (*blob.Blob, string, error) {
+// constructBlob returns the wrapped blob along with the size of its body before compression
+func constructBlob(historyBlob *HistoryBlob, enableCompression bool) (*blob.Blob, int64, string, error) {
 	body, err := json.Marshal(historyBlob)
 	if err != nil {
-		return nil, "failed to serialize blob", err
+		return nil, 0, "failed to serialize blob", err
 	}
 	tags, err := ConvertHeaderToTags(historyBlob.Header)
 	if err != nil {
-		return nil, "failed to convert header to tags", err
+		return nil, 0, "failed to convert header to tags", err
 	}
 	wrapFunctions := []blob.WrapFn{blob.JSONEncoded()}
 	if enableCompression {
-		wrapFunctions = append(wrapFunctions, blob.GzipCompressed())
+		wrapFunctions = []blob.WrapFn{blob.GzipJSONEncoded(), blob.GzipCompressed()}
 	}
 	blob, err := blob.Wrap(blob.NewBlob(body, tags), wrapFunctions...)
 	if err != nil {
-		return nil, "failed to wrap blob", err
+		return nil, 0, "failed to wrap blob", err
 	}
-	return blob, "", nil
+	return blob, int64(len(body)), "", nil
 }
 
 func modifyBlobForConstCheck(historyBlob *HistoryBlob, existingTags map[string]string) {
 	historyBlob.Header.UploadCluster = common.StringPtr(existingTags["upload_cluster"])
 	historyBlob.Header.UploadDateTime = common.StringPtr(existingTags["upload_date_time"])
+	// blobs uploaded before the event encoding was recorded do not have the tag
+	if _, ok := existingTags["event_encoding"]; !ok {
+		historyBlob.Header.EventEncoding = nil
+	}
+	// nor do they link to the previous run
+	if _, ok := existingTags["previous_run_id"]; !ok {
+		historyBlob.Header.PreviousRunID = nil
+		historyBlob.Header.PreviousRunArchivalBucket = nil
+		historyBlob.Header.PreviousRunCloseFailoverVersion = nil
+	}
+}
+
+// getEventEncoding returns the encoding of the history events in the blob body
+func getEventEncoding(header *HistoryBlobHeader) (common.EncodingType, error) {
+	if header.EventEncoding == nil {
+		return common.EncodingTypeJSON, nil
+	}
+	switch encoding := common.EncodingType(*header.EventEncoding); encoding {
+	case common.EncodingTypeJSON:
+		return encoding, nil
+	default:
+		return common.EncodingTypeUnknown, errUnknownEventEncoding
+	}
 }
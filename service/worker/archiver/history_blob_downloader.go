@@ -26,8 +26,11 @@ import (
 	"errors"
 
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/backoff"
 	"github.com/uber/cadence/common/blobstore"
 	"github.com/uber/cadence/common/blobstore/blob"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
 )
 
 type (
@@ -39,21 +42,73 @@ type (
 		WorkflowID           string
 		RunID                string
 		CloseFailoverVersion *int64
+		// ReconstructMissingIndex makes the highest version be recovered by listing the history blobs
+		// of the run when the index blob does not exist, this is much slower than reading the index blob
+		ReconstructMissingIndex bool
 	}
 
 	// DownloadBlobResponse is response from DownloadBlob
 	DownloadBlobResponse struct {
 		NextPageToken []byte
 		HistoryBlob   *HistoryBlob
+		EventEncoding common.EncodingType
+	}
+
+	// DownloadBlobByPageRequest is request to DownloadBlobByPage
+	DownloadBlobByPageRequest struct {
+		ArchivalBucket       string
+		DomainID             string
+		WorkflowID           string
+		RunID                string
+		CloseFailoverVersion int64
+		PageToken            int
+	}
+
+	// DownloadMutableStateRequest is request to DownloadMutableState
+	DownloadMutableStateRequest struct {
+		ArchivalBucket       string
+		DomainID             string
+		WorkflowID           string
+		RunID                string
+		CloseFailoverVersion *int64
+	}
+
+	// DownloadMutableStateResponse is response from DownloadMutableState
+	DownloadMutableStateResponse struct {
+		// MutableState is the JSON encoded persistence form of the mutable state at close
+		MutableState         []byte
+		CloseFailoverVersion int64
+	}
+
+	// GetPreviousRunRequest is request to GetPreviousRun
+	GetPreviousRunRequest struct {
+		ArchivalBucket       string
+		DomainID             string
+		WorkflowID           string
+		RunID                string
+		CloseFailoverVersion *int64
+	}
+
+	// GetPreviousRunResponse is response from GetPreviousRun, it points to the archived history of the run which
+	// continued as new into the requested run, within the same domain and workflow ID
+	GetPreviousRunResponse struct {
+		ArchivalBucket       string
+		RunID                string
+		CloseFailoverVersion int64
 	}
 
 	// HistoryBlobDownloader is used to download history blobs
 	HistoryBlobDownloader interface {
 		DownloadBlob(context.Context, *DownloadBlobRequest) (*DownloadBlobResponse, error)
+		DownloadBlobByPage(context.Context, *DownloadBlobByPageRequest) (*DownloadBlobResponse, error)
+		DownloadMutableState(context.Context, *DownloadMutableStateRequest) (*DownloadMutableStateResponse, error)
+		GetPreviousRun(context.Context, *GetPreviousRunRequest) (*GetPreviousRunResponse, error)
 	}
 
 	historyBlobDownloader struct {
 		blobstoreClient blobstore.Client
+		retryPolicy     backoff.RetryPolicy
+		logger          log.Logger
 	}
 
 	archivalToken struct {
@@ -62,10 +117,17 @@ type (
 	}
 )
 
-// NewHistoryBlobDownloader returns a new HistoryBlobDownloader
-func NewHistoryBlobDownloader(blobstoreClient blobstore.Client) HistoryBlobDownloader {
+// NewHistoryBlobDownloader returns a new HistoryBlobDownloader, blobstore reads failing with a retryable error
+// are retried according to retryPolicy
+func NewHistoryBlobDownloader(
+	blobstoreClient blobstore.Client,
+	retryPolicy backoff.RetryPolicy,
+	logger log.Logger,
+) HistoryBlobDownloader {
 	return &historyBlobDownloader{
 		blobstoreClient: blobstoreClient,
+		retryPolicy:     retryPolicy,
+		logger:          logger,
 	}
 }
 
@@ -85,15 +147,7 @@ func (d *historyBlobDownloader) DownloadBlob(ctx context.Context, request *Downl
 			CloseFailoverVersion: *request.CloseFailoverVersion,
 		}
 	} else {
-		indexKey, err := NewHistoryIndexBlobKey(request.DomainID, request.WorkflowID, request.RunID)
-		if err != nil {
-			return nil, err
-		}
-		indexTags, err := d.blobstoreClient.GetTags(ctx, request.ArchivalBucket, indexKey)
-		if err != nil {
-			return nil, err
-		}
-		highestVersion, err := GetHighestVersion(indexTags)
+		highestVersion, err := d.getHighestVersion(ctx, request)
 		if err != nil {
 			return nil, err
 		}
@@ -106,23 +160,123 @@ func (d *historyBlobDownloader) DownloadBlob(ctx context.Context, request *Downl
 	if err != nil {
 		return nil, err
 	}
-	b, err := d.blobstoreClient.Download(ctx, request.ArchivalBucket, key)
+	b, err := d.download(ctx, request.ArchivalBucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return d.getDownloadBlobResponse(b, token)
+}
+
+// DownloadBlobByPage is used to access a single page of the history blobs of a version without paging from the
+// first page. Returns ErrHistoryBlobPageNotExists if the page is out of range.
+func (d *historyBlobDownloader) DownloadBlobByPage(ctx context.Context, request *DownloadBlobByPageRequest) (*DownloadBlobResponse, error) {
+	if request.PageToken < common.FirstBlobPageToken {
+		return nil, ErrHistoryBlobPageNotExists
+	}
+	key, err := NewHistoryBlobKey(request.DomainID, request.WorkflowID, request.RunID, request.CloseFailoverVersion, request.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.download(ctx, request.ArchivalBucket, key)
+	if err == blobstore.ErrBlobNotExists {
+		return nil, ErrHistoryBlobPageNotExists
+	}
 	if err != nil {
 		return nil, err
 	}
+	return d.getDownloadBlobResponse(b, &archivalToken{
+		BlobstorePageToken:   request.PageToken,
+		CloseFailoverVersion: request.CloseFailoverVersion,
+	})
+}
+
+// DownloadMutableState is used to access the mutable state snapshot archived alongside the history blobs.
+// CloseFailoverVersion can be optionally provided to get a specific version of the snapshot, if not provided gets
+// the snapshot of the highest archived version. Returns ErrMutableStateSnapshotNotArchived if no snapshot was archived.
+func (d *historyBlobDownloader) DownloadMutableState(ctx context.Context, request *DownloadMutableStateRequest) (*DownloadMutableStateResponse, error) {
+	closeFailoverVersion := request.CloseFailoverVersion
+	if closeFailoverVersion == nil {
+		highestVersion, err := d.getHighestVersion(ctx, &DownloadBlobRequest{
+			ArchivalBucket: request.ArchivalBucket,
+			DomainID:       request.DomainID,
+			WorkflowID:     request.WorkflowID,
+			RunID:          request.RunID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		closeFailoverVersion = highestVersion
+	}
+	key, err := NewMutableStateBlobKey(request.DomainID, request.WorkflowID, request.RunID, *closeFailoverVersion)
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.download(ctx, request.ArchivalBucket, key)
+	if err == blobstore.ErrBlobNotExists {
+		return nil, ErrMutableStateSnapshotNotArchived
+	}
+	if err != nil {
+		return nil, err
+	}
+	unwrappedBlob, _, err := blob.Unwrap(b)
+	if err != nil {
+		return nil, err
+	}
+	return &DownloadMutableStateResponse{
+		MutableState:         unwrappedBlob.Body,
+		CloseFailoverVersion: *closeFailoverVersion,
+	}, nil
+}
+
+// GetPreviousRun is used to walk a continue-as-new chain backward, it reads the link to the previous run from the
+// header of the first history blob of the run. CloseFailoverVersion can be optionally provided, if not provided the
+// highest available version is read. Returns ErrNoPreviousRun for the first run of a chain, or for a run archived
+// before the link was recorded.
+func (d *historyBlobDownloader) GetPreviousRun(ctx context.Context, request *GetPreviousRunRequest) (*GetPreviousRunResponse, error) {
+	resp, err := d.DownloadBlob(ctx, &DownloadBlobRequest{
+		ArchivalBucket:       request.ArchivalBucket,
+		DomainID:             request.DomainID,
+		WorkflowID:           request.WorkflowID,
+		RunID:                request.RunID,
+		CloseFailoverVersion: request.CloseFailoverVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	header := resp.HistoryBlob.Header
+	if header.PreviousRunID == nil || header.PreviousRunCloseFailoverVersion == nil {
+		return nil, ErrNoPreviousRun
+	}
+	archivalBucket := request.ArchivalBucket
+	if header.PreviousRunArchivalBucket != nil {
+		archivalBucket = *header.PreviousRunArchivalBucket
+	}
+	return &GetPreviousRunResponse{
+		ArchivalBucket:       archivalBucket,
+		RunID:                *header.PreviousRunID,
+		CloseFailoverVersion: *header.PreviousRunCloseFailoverVersion,
+	}, nil
+}
+
+// getDownloadBlobResponse decodes a downloaded history blob, the next page token points to the page following it
+func (d *historyBlobDownloader) getDownloadBlobResponse(b *blob.Blob, token *archivalToken) (*DownloadBlobResponse, error) {
 	unwrappedBlob, wrappingLayers, err := blob.Unwrap(b)
 	if err != nil {
 		return nil, err
 	}
 	historyBlob := &HistoryBlob{}
 	switch *wrappingLayers.EncodingFormat {
-	case blob.JSONEncoding:
+	case blob.JSONEncoding, blob.GzipJSONEncoding:
 		if err := json.Unmarshal(unwrappedBlob.Body, historyBlob); err != nil {
 			return nil, err
 		}
 	default:
 		return nil, errors.New("unknown blob encoding format")
 	}
+	eventEncoding, err := getEventEncoding(historyBlob.Header)
+	if err != nil {
+		return nil, err
+	}
 	if *historyBlob.Header.IsLast {
 		token = nil
 	} else {
@@ -135,9 +289,90 @@ func (d *historyBlobDownloader) DownloadBlob(ctx context.Context, request *Downl
 	return &DownloadBlobResponse{
 		NextPageToken: nextToken,
 		HistoryBlob:   historyBlob,
+		EventEncoding: eventEncoding,
 	}, nil
 }
 
+func (d *historyBlobDownloader) getHighestVersion(ctx context.Context, request *DownloadBlobRequest) (*int64, error) {
+	indexKey, err := NewHistoryIndexBlobKey(request.DomainID, request.WorkflowID, request.RunID)
+	if err != nil {
+		return nil, err
+	}
+	indexTags, err := d.getTags(ctx, request.ArchivalBucket, indexKey)
+	if err == blobstore.ErrBlobNotExists && request.ReconstructMissingIndex {
+		d.logger.Warn("history index blob does not exist, reconstructing highest version from history blobs",
+			tag.ArchivalBucket(request.ArchivalBucket),
+			tag.ArchivalBlobKey(indexKey.String()),
+			tag.ArchivalRequestDomainID(request.DomainID),
+			tag.ArchivalRequestWorkflowID(request.WorkflowID),
+			tag.ArchivalRequestRunID(request.RunID))
+		return d.reconstructHighestVersion(ctx, request)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return GetHighestVersion(indexTags)
+}
+
+// reconstructHighestVersion finds the highest version for which at least one history blob of the run exists
+func (d *historyBlobDownloader) reconstructHighestVersion(ctx context.Context, request *DownloadBlobRequest) (*int64, error) {
+	prefix, err := NewHistoryBlobKeyPrefix(request.DomainID, request.WorkflowID, request.RunID)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := d.blobstoreClient.ListByPrefix(ctx, request.ArchivalBucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var result *int64
+	for _, key := range keys {
+		version, err := GetHistoryBlobKeyVersion(key)
+		if err != nil {
+			continue
+		}
+		if result == nil || version > *result {
+			result = &version
+		}
+	}
+	if result == nil {
+		return nil, blobstore.ErrBlobNotExists
+	}
+	d.logger.Info("reconstructed highest version from history blobs",
+		tag.ArchivalBucket(request.ArchivalBucket),
+		tag.ArchivalRequestDomainID(request.DomainID),
+		tag.ArchivalRequestWorkflowID(request.WorkflowID),
+		tag.ArchivalRequestRunID(request.RunID),
+		tag.ArchivalRequestCloseFailoverVersion(*result))
+	return result, nil
+}
+
+func (d *historyBlobDownloader) getTags(ctx context.Context, bucket string, key blob.Key) (map[string]string, error) {
+	var tags map[string]string
+	op := func() error {
+		var err error
+		tags, err = d.blobstoreClient.GetTags(ctx, bucket, key)
+		return err
+	}
+	err := backoff.Retry(op, d.retryPolicy, d.isRetryableError)
+	return tags, err
+}
+
+func (d *historyBlobDownloader) download(ctx context.Context, bucket string, key blob.Key) (*blob.Blob, error) {
+	var b *blob.Blob
+	op := func() error {
+		var err error
+		b, err = d.blobstoreClient.Download(ctx, bucket, key)
+		return err
+	}
+	err := backoff.Retry(op, d.retryPolicy, d.isRetryableError)
+	return b, err
+}
+
+// isRetryableError reports whether a blobstore read should be retried, a blob which does not exist never is
+func (d *historyBlobDownloader) isRetryableError(err error) bool {
+	return err != blobstore.ErrBlobNotExists && d.blobstoreClient.IsRetryableError(err)
+}
+
 func deserializeArchivalToken(bytes []byte) (*archivalToken, error) {
 	token := &archivalToken{}
 	err := json.Unmarshal(bytes, token)
@@ -52,3 +52,72 @@ func (_m *HistoryBlobDownloaderMock) DownloadBlob(_a0 context.Context, _a1 *Down
 
 	return r0, r1
 }
+
+// DownloadBlobByPage provides a mock function with given fields: _a0, _a1
+func (_m *HistoryBlobDownloaderMock) DownloadBlobByPage(_a0 context.Context, _a1 *DownloadBlobByPageRequest) (*DownloadBlobResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DownloadBlobResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *DownloadBlobByPageRequest) *DownloadBlobResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DownloadBlobResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *DownloadBlobByPageRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DownloadMutableState provides a mock function with given fields: _a0, _a1
+func (_m *HistoryBlobDownloaderMock) DownloadMutableState(_a0 context.Context, _a1 *DownloadMutableStateRequest) (*DownloadMutableStateResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DownloadMutableStateResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *DownloadMutableStateRequest) *DownloadMutableStateResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DownloadMutableStateResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *DownloadMutableStateRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPreviousRun provides a mock function with given fields: _a0, _a1
+func (_m *HistoryBlobDownloaderMock) GetPreviousRun(_a0 context.Context, _a1 *GetPreviousRunRequest) (*GetPreviousRunResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *GetPreviousRunResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *GetPreviousRunRequest) *GetPreviousRunResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*GetPreviousRunResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *GetPreviousRunRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -25,12 +25,17 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber/cadence/.gen/go/shared"
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/backoff"
+	"github.com/uber/cadence/common/blobstore"
 	"github.com/uber/cadence/common/blobstore/blob"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/loggerimpl"
 	"github.com/uber/cadence/common/mocks"
 )
 
@@ -43,6 +48,8 @@ const (
 type historyBlobDownloaderSuite struct {
 	suite.Suite
 	blobstoreClient *mocks.BlobstoreClient
+	retryPolicy     backoff.RetryPolicy
+	logger          log.Logger
 }
 
 func TestHistoryBlobDownloderSuite(t *testing.T) {
@@ -51,6 +58,10 @@ func TestHistoryBlobDownloderSuite(t *testing.T) {
 
 func (s *historyBlobDownloaderSuite) SetupTest() {
 	s.blobstoreClient = &mocks.BlobstoreClient{}
+	policy := backoff.NewExponentialRetryPolicy(time.Millisecond)
+	policy.SetMaximumAttempts(2)
+	s.retryPolicy = policy
+	s.logger = loggerimpl.NewNopLogger()
 }
 
 func (s *historyBlobDownloaderSuite) TearDownTest() {
@@ -58,7 +69,7 @@ func (s *historyBlobDownloaderSuite) TearDownTest() {
 }
 
 func (s *historyBlobDownloaderSuite) TestDownloadBlob_Failed_CouldNotDeserializeToken() {
-	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient)
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
 	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
 		NextPageToken: []byte{1},
 	})
@@ -68,7 +79,8 @@ func (s *historyBlobDownloaderSuite) TestDownloadBlob_Failed_CouldNotDeserialize
 
 func (s *historyBlobDownloaderSuite) TestDownloadBlob_Failed_CouldNotGetHighestVersion() {
 	s.blobstoreClient.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("failed to get tags")).Once()
-	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient)
+	s.blobstoreClient.On("IsRetryableError", mock.Anything).Return(false).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
 	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
 		ArchivalBucket: testArchivalBucket,
 		DomainID:       testDomainID,
@@ -82,7 +94,8 @@ func (s *historyBlobDownloaderSuite) TestDownloadBlob_Failed_CouldNotGetHighestV
 func (s *historyBlobDownloaderSuite) TestDownloadBlob_Failed_CouldNotDownloadBlob() {
 	s.blobstoreClient.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(map[string]string{testLowVersionStr: ""}, nil).Once()
 	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("failed to download blob")).Once()
-	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient)
+	s.blobstoreClient.On("IsRetryableError", mock.Anything).Return(false).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
 	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
 		ArchivalBucket: testArchivalBucket,
 		DomainID:       testDomainID,
@@ -98,7 +111,7 @@ func (s *historyBlobDownloaderSuite) TestDownloadBlob_Success_GetFirstPage() {
 	key := s.getHistoryKey(testHighVersion, common.FirstBlobPageToken)
 	s.blobstoreClient.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(map[string]string{testLowVersionStr: "", testHighVersionStr: ""}, nil).Once()
 	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(page, nil).Once()
-	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient)
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
 	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
 		ArchivalBucket: testArchivalBucket,
 		DomainID:       testDomainID,
@@ -110,11 +123,47 @@ func (s *historyBlobDownloaderSuite) TestDownloadBlob_Success_GetFirstPage() {
 	s.Equal(s.getPageToken(common.FirstBlobPageToken+1, testHighVersion), resp.NextPageToken)
 }
 
+func (s *historyBlobDownloaderSuite) TestDownloadBlob_Success_RetryTransientErrors() {
+	expected, page := s.getBlob(common.FirstBlobPageToken, false)
+	key := s.getHistoryKey(testHighVersion, common.FirstBlobPageToken)
+	transientErr := errors.New("transient blobstore error")
+	s.blobstoreClient.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(nil, transientErr).Once()
+	s.blobstoreClient.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(map[string]string{testLowVersionStr: "", testHighVersionStr: ""}, nil).Once()
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(nil, transientErr).Once()
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(page, nil).Once()
+	s.blobstoreClient.On("IsRetryableError", transientErr).Return(true).Twice()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
+		ArchivalBucket: testArchivalBucket,
+		DomainID:       testDomainID,
+		WorkflowID:     testWorkflowID,
+		RunID:          testRunID,
+	})
+	s.NoError(err)
+	s.Equal(hash(*expected), hash(*resp.HistoryBlob))
+	s.Equal(s.getPageToken(common.FirstBlobPageToken+1, testHighVersion), resp.NextPageToken)
+}
+
+func (s *historyBlobDownloaderSuite) TestDownloadBlob_Failed_RetryAttemptsExhausted() {
+	transientErr := errors.New("transient blobstore error")
+	s.blobstoreClient.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(nil, transientErr).Times(3)
+	s.blobstoreClient.On("IsRetryableError", transientErr).Return(true).Twice()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
+		ArchivalBucket: testArchivalBucket,
+		DomainID:       testDomainID,
+		WorkflowID:     testWorkflowID,
+		RunID:          testRunID,
+	})
+	s.Equal(transientErr, err)
+	s.Nil(resp)
+}
+
 func (s *historyBlobDownloaderSuite) TestDownloadBlob_Success_ProvidedVersion() {
 	expected, page := s.getBlob(common.FirstBlobPageToken, false)
 	key := s.getHistoryKey(testHighVersion, common.FirstBlobPageToken)
 	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(page, nil).Once()
-	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient)
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
 	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
 		ArchivalBucket:       testArchivalBucket,
 		DomainID:             testDomainID,
@@ -131,7 +180,7 @@ func (s *historyBlobDownloaderSuite) TestDownloadBlob_Success_UsePageToken() {
 	expected, page := s.getBlob(common.FirstBlobPageToken+1, false)
 	key := s.getHistoryKey(testHighVersion, common.FirstBlobPageToken+1)
 	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(page, nil).Once()
-	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient)
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
 	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
 		ArchivalBucket: testArchivalBucket,
 		DomainID:       testDomainID,
@@ -148,7 +197,7 @@ func (s *historyBlobDownloaderSuite) TestDownloadBlob_Success_GetLastPage() {
 	expected, page := s.getBlob(common.FirstBlobPageToken+1, true)
 	key := s.getHistoryKey(testHighVersion, common.FirstBlobPageToken+1)
 	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(page, nil).Once()
-	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient)
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
 	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
 		ArchivalBucket: testArchivalBucket,
 		DomainID:       testDomainID,
@@ -170,7 +219,7 @@ func (s *historyBlobDownloaderSuite) TestDownloadBlob_Success_DownloadMultiple()
 		expectedBlobs = append(expectedBlobs, currExpected)
 	}
 	s.blobstoreClient.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(map[string]string{testLowVersionStr: "", testHighVersionStr: ""}, nil).Once()
-	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient)
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
 	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
 		ArchivalBucket: testArchivalBucket,
 		DomainID:       testDomainID,
@@ -194,6 +243,264 @@ func (s *historyBlobDownloaderSuite) TestDownloadBlob_Success_DownloadMultiple()
 	}
 }
 
+func (s *historyBlobDownloaderSuite) TestDownloadBlob_Failed_IndexMissing_ReconstructNotRequested() {
+	s.blobstoreClient.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(nil, blobstore.ErrBlobNotExists).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
+		ArchivalBucket: testArchivalBucket,
+		DomainID:       testDomainID,
+		WorkflowID:     testWorkflowID,
+		RunID:          testRunID,
+	})
+	s.Equal(blobstore.ErrBlobNotExists, err)
+	s.Nil(resp)
+}
+
+func (s *historyBlobDownloaderSuite) TestDownloadBlob_Failed_IndexMissing_NoHistoryBlobs() {
+	s.blobstoreClient.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(nil, blobstore.ErrBlobNotExists).Once()
+	s.blobstoreClient.On("ListByPrefix", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
+		ArchivalBucket:          testArchivalBucket,
+		DomainID:                testDomainID,
+		WorkflowID:              testWorkflowID,
+		RunID:                   testRunID,
+		ReconstructMissingIndex: true,
+	})
+	s.Equal(blobstore.ErrBlobNotExists, err)
+	s.Nil(resp)
+}
+
+func (s *historyBlobDownloaderSuite) TestDownloadBlob_Success_IndexMissing_Reconstruct() {
+	expected, page := s.getBlob(common.FirstBlobPageToken, false)
+	key := s.getHistoryKey(testHighVersion, common.FirstBlobPageToken)
+	prefix, err := NewHistoryBlobKeyPrefix(testDomainID, testWorkflowID, testRunID)
+	s.NoError(err)
+	listedKeys := []blob.Key{
+		s.getHistoryKey(1, common.FirstBlobPageToken),
+		s.getHistoryKey(1, common.FirstBlobPageToken+1),
+		key,
+		s.getHistoryKey(testHighVersion, common.FirstBlobPageToken+1),
+	}
+	s.blobstoreClient.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(nil, blobstore.ErrBlobNotExists).Once()
+	s.blobstoreClient.On("ListByPrefix", mock.Anything, testArchivalBucket, prefix).Return(listedKeys, nil).Once()
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(page, nil).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
+		ArchivalBucket:          testArchivalBucket,
+		DomainID:                testDomainID,
+		WorkflowID:              testWorkflowID,
+		RunID:                   testRunID,
+		ReconstructMissingIndex: true,
+	})
+	s.NoError(err)
+	s.Equal(hash(*expected), hash(*resp.HistoryBlob))
+	s.Equal(s.getPageToken(common.FirstBlobPageToken+1, testHighVersion), resp.NextPageToken)
+}
+
+func (s *historyBlobDownloaderSuite) TestDownloadBlob_Success_GzipJSONEncoding() {
+	expected, _ := s.getBlob(common.FirstBlobPageToken, true)
+	page, _, reason, err := constructBlob(expected, true)
+	s.NoError(err, reason)
+	key := s.getHistoryKey(testHighVersion, common.FirstBlobPageToken)
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(page, nil).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
+		ArchivalBucket:       testArchivalBucket,
+		DomainID:             testDomainID,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		CloseFailoverVersion: common.Int64Ptr(testHighVersion),
+	})
+	s.NoError(err)
+	s.Equal(hash(*expected), hash(*resp.HistoryBlob))
+	s.Nil(resp.NextPageToken)
+}
+
+func (s *historyBlobDownloaderSuite) TestDownloadBlob_Success_LegacyBlobWithoutEventEncoding() {
+	expected, page := s.getBlob(common.FirstBlobPageToken, true)
+	s.Nil(expected.Header.EventEncoding)
+	key := s.getHistoryKey(testHighVersion, common.FirstBlobPageToken)
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(page, nil).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
+		ArchivalBucket:       testArchivalBucket,
+		DomainID:             testDomainID,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		CloseFailoverVersion: common.Int64Ptr(testHighVersion),
+	})
+	s.NoError(err)
+	s.Equal(common.EncodingTypeJSON, resp.EventEncoding)
+}
+
+func (s *historyBlobDownloaderSuite) TestDownloadBlob_Success_EventEncoding() {
+	expected, _ := s.getBlob(common.FirstBlobPageToken, true)
+	expected.Header.EventEncoding = common.StringPtr(string(common.EncodingTypeJSON))
+	page, _, reason, err := constructBlob(expected, false)
+	s.NoError(err, reason)
+	key := s.getHistoryKey(testHighVersion, common.FirstBlobPageToken)
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(page, nil).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
+		ArchivalBucket:       testArchivalBucket,
+		DomainID:             testDomainID,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		CloseFailoverVersion: common.Int64Ptr(testHighVersion),
+	})
+	s.NoError(err)
+	s.Equal(hash(*expected), hash(*resp.HistoryBlob))
+	s.Equal(common.EncodingTypeJSON, resp.EventEncoding)
+}
+
+func (s *historyBlobDownloaderSuite) TestDownloadBlob_Failed_UnknownEventEncoding() {
+	expected, _ := s.getBlob(common.FirstBlobPageToken, true)
+	expected.Header.EventEncoding = common.StringPtr("some random encoding")
+	page, _, reason, err := constructBlob(expected, false)
+	s.NoError(err, reason)
+	key := s.getHistoryKey(testHighVersion, common.FirstBlobPageToken)
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(page, nil).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadBlob(context.Background(), &DownloadBlobRequest{
+		ArchivalBucket:       testArchivalBucket,
+		DomainID:             testDomainID,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		CloseFailoverVersion: common.Int64Ptr(testHighVersion),
+	})
+	s.Equal(errUnknownEventEncoding, err)
+	s.Nil(resp)
+}
+
+func (s *historyBlobDownloaderSuite) TestDownloadBlobByPage_Success() {
+	expected, page := s.getBlob(common.FirstBlobPageToken+4, false)
+	key := s.getHistoryKey(testHighVersion, common.FirstBlobPageToken+4)
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(page, nil).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadBlobByPage(context.Background(), &DownloadBlobByPageRequest{
+		ArchivalBucket:       testArchivalBucket,
+		DomainID:             testDomainID,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		CloseFailoverVersion: testHighVersion,
+		PageToken:            common.FirstBlobPageToken + 4,
+	})
+	s.NoError(err)
+	s.Equal(hash(*expected), hash(*resp.HistoryBlob))
+	s.Equal(s.getPageToken(common.FirstBlobPageToken+5, testHighVersion), resp.NextPageToken)
+}
+
+func (s *historyBlobDownloaderSuite) TestDownloadBlobByPage_Failed_InvalidPage() {
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadBlobByPage(context.Background(), &DownloadBlobByPageRequest{
+		ArchivalBucket:       testArchivalBucket,
+		DomainID:             testDomainID,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		CloseFailoverVersion: testHighVersion,
+		PageToken:            common.FirstBlobPageToken - 1,
+	})
+	s.Equal(ErrHistoryBlobPageNotExists, err)
+	s.Nil(resp)
+}
+
+func (s *historyBlobDownloaderSuite) TestDownloadBlobByPage_Failed_PageOutOfRange() {
+	key := s.getHistoryKey(testHighVersion, 100)
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(nil, blobstore.ErrBlobNotExists).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadBlobByPage(context.Background(), &DownloadBlobByPageRequest{
+		ArchivalBucket:       testArchivalBucket,
+		DomainID:             testDomainID,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		CloseFailoverVersion: testHighVersion,
+		PageToken:            100,
+	})
+	s.Equal(ErrHistoryBlobPageNotExists, err)
+	s.Nil(resp)
+}
+
+func (s *historyBlobDownloaderSuite) TestGetPreviousRun_TwoRunChain() {
+	firstRunID := "test-first-run-id"
+	secondRunID := testRunID
+
+	// the second run continued as new from the first run, which closed with the low version
+	secondRunBlob, _ := s.getBlob(common.FirstBlobPageToken, true)
+	secondRunBlob.Header.PreviousRunID = common.StringPtr(firstRunID)
+	secondRunBlob.Header.PreviousRunArchivalBucket = common.StringPtr(testArchivalBucket)
+	secondRunBlob.Header.PreviousRunCloseFailoverVersion = common.Int64Ptr(1)
+	firstRunBlob, _ := s.getBlob(common.FirstBlobPageToken, true)
+
+	secondRunKey, err := NewHistoryBlobKey(testDomainID, testWorkflowID, secondRunID, testHighVersion, common.FirstBlobPageToken)
+	s.NoError(err)
+	firstRunKey, err := NewHistoryBlobKey(testDomainID, testWorkflowID, firstRunID, 1, common.FirstBlobPageToken)
+	s.NoError(err)
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, secondRunKey).Return(s.wrapBlob(secondRunBlob), nil).Once()
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, firstRunKey).Return(s.wrapBlob(firstRunBlob), nil).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+
+	resp, err := blobDownloader.GetPreviousRun(context.Background(), &GetPreviousRunRequest{
+		ArchivalBucket:       testArchivalBucket,
+		DomainID:             testDomainID,
+		WorkflowID:           testWorkflowID,
+		RunID:                secondRunID,
+		CloseFailoverVersion: common.Int64Ptr(testHighVersion),
+	})
+	s.NoError(err)
+	s.Equal(&GetPreviousRunResponse{
+		ArchivalBucket:       testArchivalBucket,
+		RunID:                firstRunID,
+		CloseFailoverVersion: 1,
+	}, resp)
+
+	resp, err = blobDownloader.GetPreviousRun(context.Background(), &GetPreviousRunRequest{
+		ArchivalBucket:       resp.ArchivalBucket,
+		DomainID:             testDomainID,
+		WorkflowID:           testWorkflowID,
+		RunID:                resp.RunID,
+		CloseFailoverVersion: common.Int64Ptr(resp.CloseFailoverVersion),
+	})
+	s.Equal(ErrNoPreviousRun, err)
+	s.Nil(resp)
+}
+
+func (s *historyBlobDownloaderSuite) TestDownloadMutableState_Failed_NotArchived() {
+	key, err := NewMutableStateBlobKey(testDomainID, testWorkflowID, testRunID, testHighVersion)
+	s.NoError(err)
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(nil, blobstore.ErrBlobNotExists).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadMutableState(context.Background(), &DownloadMutableStateRequest{
+		ArchivalBucket:       testArchivalBucket,
+		DomainID:             testDomainID,
+		WorkflowID:           testWorkflowID,
+		RunID:                testRunID,
+		CloseFailoverVersion: common.Int64Ptr(testHighVersion),
+	})
+	s.Equal(ErrMutableStateSnapshotNotArchived, err)
+	s.Nil(resp)
+}
+
+func (s *historyBlobDownloaderSuite) TestDownloadMutableState_Success_HighestVersion() {
+	snapshot := []byte(`{"ExecutionInfo":{"WorkflowID":"test-workflow-id"}}`)
+	page, err := constructMutableStateBlob(snapshot, true)
+	s.NoError(err)
+	key, err := NewMutableStateBlobKey(testDomainID, testWorkflowID, testRunID, testHighVersion)
+	s.NoError(err)
+	s.blobstoreClient.On("GetTags", mock.Anything, mock.Anything, mock.Anything).Return(map[string]string{testLowVersionStr: "", testHighVersionStr: ""}, nil).Once()
+	s.blobstoreClient.On("Download", mock.Anything, mock.Anything, key).Return(page, nil).Once()
+	blobDownloader := NewHistoryBlobDownloader(s.blobstoreClient, s.retryPolicy, s.logger)
+	resp, err := blobDownloader.DownloadMutableState(context.Background(), &DownloadMutableStateRequest{
+		ArchivalBucket: testArchivalBucket,
+		DomainID:       testDomainID,
+		WorkflowID:     testWorkflowID,
+		RunID:          testRunID,
+	})
+	s.NoError(err)
+	s.Equal(snapshot, resp.MutableState)
+	s.Equal(int64(testHighVersion), resp.CloseFailoverVersion)
+}
+
 func (s *historyBlobDownloaderSuite) getIndexKey() blob.Key {
 	key, err := NewHistoryIndexBlobKey(testDomainID, testWorkflowID, testRunID)
 	s.NoError(err)
@@ -221,11 +528,15 @@ func (s *historyBlobDownloaderSuite) getBlob(pageToken int, last bool) (*History
 	for i := 0; i < 10; i++ {
 		historyBlob.Body.Events = append(historyBlob.Body.Events, &shared.HistoryEvent{EventId: common.Int64Ptr(int64(i))})
 	}
+	return historyBlob, s.wrapBlob(historyBlob)
+}
+
+func (s *historyBlobDownloaderSuite) wrapBlob(historyBlob *HistoryBlob) *blob.Blob {
 	bytes, err := json.Marshal(historyBlob)
 	s.NoError(err)
 	result, err := blob.Wrap(blob.NewBlob(bytes, map[string]string{}), blob.JSONEncoded())
 	s.NoError(err)
-	return historyBlob, result
+	return result
 }
 
 func (s *historyBlobDownloaderSuite) getPageToken(blobstorePageToken int, version int64) []byte {
@@ -65,6 +65,11 @@ type (
 		closeFailoverVersion int64
 		shardID              int
 		sizeEstimator        SizeEstimator
+
+		// the following link the blobs to the archived history of the previous run
+		bucketName                      string
+		previousRunID                   string
+		previousRunCloseFailoverVersion int64
 	}
 )
 
@@ -99,6 +104,10 @@ func NewHistoryBlobIterator(
 		closeFailoverVersion: request.CloseFailoverVersion,
 		shardID:              request.ShardID,
 		sizeEstimator:        container.HistorySizeEstimator,
+
+		bucketName:                      request.BucketName,
+		previousRunID:                   request.PreviousRunID,
+		previousRunCloseFailoverVersion: request.PreviousRunCloseFailoverVersion,
 	}
 	if it.sizeEstimator == nil {
 		it.sizeEstimator = NewJSONSizeEstimator()
@@ -142,6 +151,12 @@ func (i *historyBlobIterator) Next() (*HistoryBlob, error) {
 		UploadCluster:        &i.clusterName,
 		EventCount:           &eventCount,
 		CloseFailoverVersion: &i.closeFailoverVersion,
+		EventEncoding:        common.StringPtr(string(common.EncodingTypeJSON)),
+	}
+	if len(i.previousRunID) != 0 {
+		header.PreviousRunID = common.StringPtr(i.previousRunID)
+		header.PreviousRunArchivalBucket = common.StringPtr(i.bucketName)
+		header.PreviousRunCloseFailoverVersion = common.Int64Ptr(i.previousRunCloseFailoverVersion)
 	}
 	if i.HasNext() {
 		i.blobPageToken++
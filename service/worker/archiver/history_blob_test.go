@@ -21,6 +21,7 @@
 package archiver
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -86,6 +87,31 @@ func (s *HistoryBlobSuite) TestNewHistoryBlobKey() {
 	}
 }
 
+func (s *HistoryBlobSuite) TestNewHistoryBlobKeyPrefix() {
+	_, err := NewHistoryBlobKeyPrefix("", "testWorkflowID", "testRunID")
+	s.Error(err)
+
+	prefix, err := NewHistoryBlobKeyPrefix("testDomainID", "testWorkflowID", "testRunID")
+	s.NoError(err)
+	key, err := NewHistoryBlobKey("testDomainID", "testWorkflowID", "testRunID", 5, common.FirstBlobPageToken)
+	s.NoError(err)
+	s.True(strings.HasPrefix(key.String(), prefix))
+	s.Equal("17971674567288329890367046253745284795510285995943906173973_", prefix)
+}
+
+func (s *HistoryBlobSuite) TestGetHistoryBlobKeyVersion() {
+	key, err := NewHistoryBlobKey("testDomainID", "testWorkflowID", "testRunID", 5, common.FirstBlobPageToken)
+	s.NoError(err)
+	version, err := GetHistoryBlobKeyVersion(key)
+	s.NoError(err)
+	s.Equal(int64(5), version)
+
+	indexKey, err := NewHistoryIndexBlobKey("testDomainID", "testWorkflowID", "testRunID")
+	s.NoError(err)
+	_, err = GetHistoryBlobKeyVersion(indexKey)
+	s.Error(err)
+}
+
 func (s *HistoryBlobSuite) TestConvertHeaderToTags() {
 	testCases := []struct {
 		header     *HistoryBlobHeader
@@ -160,3 +186,37 @@ func (s *HistoryBlobSuite) TestIsLast() {
 		s.Equal(tc.isLast, IsLast(tags))
 	}
 }
+
+func (s *HistoryBlobSuite) TestModifyBlobForConstCheck_LegacyBlob() {
+	historyBlob := &HistoryBlob{
+		Header: &HistoryBlobHeader{
+			UploadCluster:  common.StringPtr("new_cluster"),
+			UploadDateTime: common.StringPtr("new_date_time"),
+			EventEncoding:  common.StringPtr(string(common.EncodingTypeJSON)),
+		},
+	}
+	existingTags := map[string]string{
+		"upload_cluster":   "existing_cluster",
+		"upload_date_time": "existing_date_time",
+	}
+	modifyBlobForConstCheck(historyBlob, existingTags)
+	s.Equal("existing_cluster", *historyBlob.Header.UploadCluster)
+	s.Equal("existing_date_time", *historyBlob.Header.UploadDateTime)
+	s.Nil(historyBlob.Header.EventEncoding)
+	tags, err := ConvertHeaderToTags(historyBlob.Header)
+	s.NoError(err)
+	s.Equal(existingTags, tags)
+}
+
+func (s *HistoryBlobSuite) TestGetEventEncoding() {
+	encoding, err := getEventEncoding(&HistoryBlobHeader{})
+	s.NoError(err)
+	s.Equal(common.EncodingTypeJSON, encoding)
+
+	encoding, err = getEventEncoding(&HistoryBlobHeader{EventEncoding: common.StringPtr(string(common.EncodingTypeJSON))})
+	s.NoError(err)
+	s.Equal(common.EncodingTypeJSON, encoding)
+
+	_, err = getEventEncoding(&HistoryBlobHeader{EventEncoding: common.StringPtr("unknown-encoding")})
+	s.Equal(errUnknownEventEncoding, err)
+}
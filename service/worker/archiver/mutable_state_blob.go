@@ -0,0 +1,54 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package archiver
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/uber/cadence/common/blobstore/blob"
+)
+
+const (
+	mutableStateBlobKeyExtension = "mutablestate"
+)
+
+var (
+	// ErrMutableStateSnapshotNotArchived indicates no mutable state snapshot was archived for the workflow run
+	ErrMutableStateSnapshotNotArchived = errors.New("mutable state snapshot is not archived")
+)
+
+// NewMutableStateBlobKey returns a key for the mutable state snapshot blob archived alongside the history blobs
+func NewMutableStateBlobKey(domainID, workflowID, runID string, closeFailoverVersion int64) (blob.Key, error) {
+	if len(domainID) == 0 || len(workflowID) == 0 || len(runID) == 0 {
+		return nil, errInvalidKeyInput
+	}
+	return blob.NewKey(mutableStateBlobKeyExtension, historyBlobKeyHash(domainID, workflowID, runID), strconv.FormatInt(closeFailoverVersion, 10))
+}
+
+// constructMutableStateBlob returns the wrapped blob of an already JSON encoded mutable state snapshot
+func constructMutableStateBlob(snapshot []byte, enableCompression bool) (*blob.Blob, error) {
+	wrapFunctions := []blob.WrapFn{blob.JSONEncoded()}
+	if enableCompression {
+		wrapFunctions = []blob.WrapFn{blob.GzipJSONEncoded(), blob.GzipCompressed()}
+	}
+	return blob.Wrap(blob.NewBlob(snapshot, map[string]string{}), wrapFunctions...)
+}
@@ -48,8 +48,10 @@ type (
 		carryover     []ArchiveRequest
 		timeout       time.Duration
 		requestLimit  int
-		requestCh     workflow.Channel
-		signalCh      workflow.Channel
+		// highPriorityRequestCh receives requests with ArchivalPriorityHigh, requestCh receives all others
+		highPriorityRequestCh workflow.Channel
+		requestCh             workflow.Channel
+		signalCh              workflow.Channel
 	}
 )
 
@@ -61,25 +63,27 @@ func NewPump(
 	carryover []ArchiveRequest,
 	timeout time.Duration,
 	requestLimit int,
+	highPriorityRequestCh workflow.Channel,
 	requestCh workflow.Channel,
 	signalCh workflow.Channel,
 ) Pump {
 	return &pump{
-		ctx:           ctx,
-		logger:        logger,
-		metricsClient: metricsClient,
-		carryover:     carryover,
-		timeout:       timeout,
-		requestLimit:  requestLimit,
-		requestCh:     requestCh,
-		signalCh:      signalCh,
+		ctx:                   ctx,
+		logger:                logger,
+		metricsClient:         metricsClient,
+		carryover:             carryover,
+		timeout:               timeout,
+		requestLimit:          requestLimit,
+		highPriorityRequestCh: highPriorityRequestCh,
+		requestCh:             requestCh,
+		signalCh:              signalCh,
 	}
 }
 
-// Run pumps requests into request channel.
+// Run pumps requests into request channels based on their priority.
 // Blocks until either timout occurs or request limit is satisfied.
 // Returns a PumpResult which contains a summary of what was pumped.
-// Upon returning request channel is closed.
+// Upon returning both request channels are closed.
 func (p *pump) Run() PumpResult {
 	sw := p.metricsClient.StartTimer(metrics.ArchiverPumpScope, metrics.CadenceLatency)
 
@@ -97,12 +101,12 @@ func (p *pump) Run() PumpResult {
 	}
 	for i := 0; i < carryoverBoundIndex; i++ {
 		request := p.carryover[i]
-		p.requestCh.Send(p.ctx, request)
+		p.send(request)
 		pumpResult.PumpedHashes = append(pumpResult.PumpedHashes, hash(request))
 	}
 	if len(pumpResult.PumpedHashes) == p.requestLimit {
 		sw.Stop()
-		p.requestCh.Close()
+		p.closeRequestChannels()
 		return pumpResult
 	}
 	selector := workflow.NewSelector(p.ctx)
@@ -124,7 +128,7 @@ func (p *pump) Run() PumpResult {
 		}
 		var request ArchiveRequest
 		ch.Receive(p.ctx, &request)
-		p.requestCh.Send(p.ctx, request)
+		p.send(request)
 		pumpResult.PumpedHashes = append(pumpResult.PumpedHashes, hash(request))
 		finished = len(pumpResult.PumpedHashes) == p.requestLimit
 		if finished {
@@ -135,6 +139,19 @@ func (p *pump) Run() PumpResult {
 		selector.Select(p.ctx)
 	}
 	sw.Stop()
-	p.requestCh.Close()
+	p.closeRequestChannels()
 	return pumpResult
 }
+
+func (p *pump) send(request ArchiveRequest) {
+	if request.Priority == ArchivalPriorityHigh {
+		p.highPriorityRequestCh.Send(p.ctx, request)
+		return
+	}
+	p.requestCh.Send(p.ctx, request)
+}
+
+func (p *pump) closeRequestChannels() {
+	p.highPriorityRequestCh.Close()
+	p.requestCh.Close()
+}
@@ -53,6 +53,7 @@ func (s *pumpSuite) SetupSuite() {
 	workflow.Register(pumpWorkflow)
 	workflow.Register(signalChClosePumpWorkflow)
 	workflow.Register(signalAndCarryoverPumpWorkflow)
+	workflow.Register(priorityCarryoverPumpWorkflow)
 }
 
 func (s *pumpSuite) SetupTest() {
@@ -150,11 +151,23 @@ func (s *pumpSuite) TestPumpRun_SignalChannelClosedUnexpectedly() {
 	s.NoError(env.GetWorkflowError())
 }
 
+func (s *pumpSuite) TestPumpRun_RequestsRoutedByPriority() {
+	pumpTestMetrics.On("UpdateGauge", metrics.ArchiverPumpScope, metrics.ArchiverBacklogSizeGauge, float64(0)).Once()
+
+	env := s.NewTestWorkflowEnvironment()
+	env.ExecuteWorkflow(priorityCarryoverPumpWorkflow, 10)
+
+	env.AssertExpectations(s.T())
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+}
+
 func carryoverSatisfiesLimitWorkflow(ctx workflow.Context, requestLimit int, carryoverSize int) error {
 	unhandledCarryoverSize := carryoverSize - requestLimit
 	carryover, carryoverHashes := randomCarryover(carryoverSize)
+	highPriorityRequestCh := workflow.NewBufferedChannel(ctx, requestLimit)
 	requestCh := workflow.NewBufferedChannel(ctx, requestLimit)
-	pump := NewPump(ctx, pumpTestLogger, pumpTestMetrics, carryover, time.Nanosecond, requestLimit, requestCh, nil)
+	pump := NewPump(ctx, pumpTestLogger, pumpTestMetrics, carryover, time.Nanosecond, requestLimit, highPriorityRequestCh, requestCh, nil)
 	actual := pump.Run()
 	expected := PumpResult{
 		PumpedHashes:          carryoverHashes[:len(carryoverHashes)-unhandledCarryoverSize],
@@ -173,8 +186,9 @@ func carryoverSatisfiesLimitWorkflow(ctx workflow.Context, requestLimit int, car
 func pumpWorkflow(ctx workflow.Context, requestLimit int, numRequests int) error {
 	signalCh := workflow.NewBufferedChannel(ctx, requestLimit)
 	signalsSent, signalHashes := sendRequestsToChannel(ctx, signalCh, numRequests)
+	highPriorityRequestCh := workflow.NewBufferedChannel(ctx, requestLimit)
 	requestCh := workflow.NewBufferedChannel(ctx, requestLimit)
-	pump := NewPump(ctx, pumpTestLogger, pumpTestMetrics, nil, time.Nanosecond, requestLimit, requestCh, signalCh)
+	pump := NewPump(ctx, pumpTestLogger, pumpTestMetrics, nil, time.Nanosecond, requestLimit, highPriorityRequestCh, requestCh, signalCh)
 	actual := pump.Run()
 	expected := PumpResult{
 		PumpedHashes:          signalHashes,
@@ -194,8 +208,9 @@ func signalChClosePumpWorkflow(ctx workflow.Context, requestLimit int, numReques
 	signalCh := workflow.NewBufferedChannel(ctx, requestLimit)
 	signalsSent, signalHashes := sendRequestsToChannelBlocking(ctx, signalCh, numRequests)
 	signalCh.Close()
+	highPriorityRequestCh := workflow.NewBufferedChannel(ctx, requestLimit)
 	requestCh := workflow.NewBufferedChannel(ctx, requestLimit)
-	pump := NewPump(ctx, pumpTestLogger, pumpTestMetrics, nil, time.Nanosecond, requestLimit, requestCh, signalCh)
+	pump := NewPump(ctx, pumpTestLogger, pumpTestMetrics, nil, time.Nanosecond, requestLimit, highPriorityRequestCh, requestCh, signalCh)
 	actual := pump.Run()
 	expected := PumpResult{
 		PumpedHashes:          signalHashes,
@@ -215,8 +230,9 @@ func signalAndCarryoverPumpWorkflow(ctx workflow.Context, requestLimit int, carr
 	signalCh := workflow.NewBufferedChannel(ctx, requestLimit)
 	signalsSent, signalHashes := sendRequestsToChannel(ctx, signalCh, numSignals)
 	carryover, carryoverHashes := randomCarryover(carryoverSize)
+	highPriorityRequestCh := workflow.NewBufferedChannel(ctx, requestLimit)
 	requestCh := workflow.NewBufferedChannel(ctx, requestLimit)
-	pump := NewPump(ctx, pumpTestLogger, pumpTestMetrics, carryover, time.Nanosecond, requestLimit, requestCh, signalCh)
+	pump := NewPump(ctx, pumpTestLogger, pumpTestMetrics, carryover, time.Nanosecond, requestLimit, highPriorityRequestCh, requestCh, signalCh)
 	actual := pump.Run()
 	expected := PumpResult{
 		PumpedHashes:          append(carryoverHashes, signalHashes...),
@@ -232,6 +248,39 @@ func signalAndCarryoverPumpWorkflow(ctx workflow.Context, requestLimit int, carr
 	return nil
 }
 
+func priorityCarryoverPumpWorkflow(ctx workflow.Context, requestLimit int) error {
+	carryover, carryoverHashes := randomCarryover(requestLimit)
+	var highPriorityCarryover, normalPriorityCarryover []ArchiveRequest
+	for i := range carryover {
+		if i%2 == 0 {
+			carryover[i].Priority = ArchivalPriorityHigh
+			highPriorityCarryover = append(highPriorityCarryover, carryover[i])
+		} else {
+			normalPriorityCarryover = append(normalPriorityCarryover, carryover[i])
+		}
+		carryoverHashes[i] = hash(carryover[i])
+	}
+	highPriorityRequestCh := workflow.NewBufferedChannel(ctx, requestLimit)
+	requestCh := workflow.NewBufferedChannel(ctx, requestLimit)
+	pump := NewPump(ctx, pumpTestLogger, pumpTestMetrics, carryover, time.Nanosecond, requestLimit, highPriorityRequestCh, requestCh, nil)
+	actual := pump.Run()
+	expected := PumpResult{
+		PumpedHashes:          carryoverHashes,
+		UnhandledCarryover:    nil,
+		TimeoutWithoutSignals: false,
+	}
+	if !pumpResultsEqual(expected, actual) {
+		return errors.New("did not get expected pump result")
+	}
+	if !channelContainsExpected(ctx, highPriorityRequestCh, highPriorityCarryover) {
+		return errors.New("high priority request channel was not populated with expected values")
+	}
+	if !channelContainsExpected(ctx, requestCh, normalPriorityCarryover) {
+		return errors.New("request channel was not populated with expected values")
+	}
+	return nil
+}
+
 func sendRequestsToChannel(ctx workflow.Context, ch workflow.Channel, numRequests int) ([]ArchiveRequest, []uint64) {
 	requests := make([]ArchiveRequest, numRequests, numRequests)
 	hashes := make([]uint64, numRequests, numRequests)
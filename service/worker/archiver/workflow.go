@@ -76,15 +76,16 @@ func archivalWorkflowHelper(
 				TimelimitPerIteration: timeLimit,
 			}
 		}).Get(&dcResult)
+	highPriorityRequestCh := workflow.NewBufferedChannel(ctx, dcResult.ArchivalsPerIteration)
 	requestCh := workflow.NewBufferedChannel(ctx, dcResult.ArchivalsPerIteration)
 	if archiver == nil {
-		archiver = NewArchiver(ctx, logger, metricsClient, dcResult.ArchiverConcurrency, requestCh)
+		archiver = NewArchiver(ctx, logger, metricsClient, dcResult.ArchiverConcurrency, highPriorityRequestCh, requestCh)
 	}
 	archiverSW := metricsClient.StartTimer(metrics.ArchiverArchivalWorkflowScope, metrics.ArchiverHandleAllRequestsLatency)
 	archiver.Start()
 	signalCh := workflow.GetSignalChannel(ctx, signalName)
 	if pump == nil {
-		pump = NewPump(ctx, logger, metricsClient, carryover, dcResult.TimelimitPerIteration, dcResult.ArchivalsPerIteration, requestCh, signalCh)
+		pump = NewPump(ctx, logger, metricsClient, carryover, dcResult.TimelimitPerIteration, dcResult.ArchivalsPerIteration, highPriorityRequestCh, requestCh, signalCh)
 	}
 	pumpResult := pump.Run()
 	metricsClient.AddCounter(metrics.ArchiverArchivalWorkflowScope, metrics.ArchiverNumPumpedRequestsCount, int64(len(pumpResult.PumpedHashes)))
@@ -280,6 +280,12 @@ func ColorEvent(e *s.HistoryEvent) string {
 	case s.EventTypeMarkerRecorded:
 		data = e.EventType.String()
 
+	case s.EventTypeUpsertWorkflowMemo:
+		data = e.EventType.String()
+
+	case s.EventTypeWorkflowExecutionTimeoutExtended:
+		data = e.EventType.String()
+
 	case s.EventTypeWorkflowExecutionSignaled:
 		data = e.EventType.String()
 
@@ -409,6 +415,12 @@ func getEventAttributes(e *s.HistoryEvent) interface{} {
 	case s.EventTypeMarkerRecorded:
 		data = e.MarkerRecordedEventAttributes
 
+	case s.EventTypeUpsertWorkflowMemo:
+		data = e.UpsertWorkflowMemoEventAttributes
+
+	case s.EventTypeWorkflowExecutionTimeoutExtended:
+		data = e.WorkflowExecutionTimeoutExtendedEventAttributes
+
 	case s.EventTypeWorkflowExecutionSignaled:
 		data = e.WorkflowExecutionSignaledEventAttributes
 